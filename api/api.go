@@ -0,0 +1,65 @@
+// Package api holds the published descriptions of the service: the AsyncAPI
+// document of the Lambda, the protobuf contract, and the OpenAPI document
+// and JSON Schemas of the HTTP API, generated from the Go types by
+// internal/openapi. Run
+// "go generate ./api" (make generate) after changing the request or response.
+//
+// The request schema of every contract version (domain.SchemaVersions) is
+// kept as request.<version>.schema.json. Generation only rewrites the one
+// of the current version; earlier ones are frozen when the version changes.
+package api
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:generate go run ../cmd/openapi
+
+// Generated document file names.
+const (
+	OpenAPIFile        = "openapi.json"
+	RequestSchemaFile  = "request.schema.json"
+	ResponseSchemaFile = "response.schema.json"
+)
+
+// OpenAPI is the OpenAPI document of the HTTP API.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// RequestSchema is the JSON Schema of a request.
+//
+//go:embed request.schema.json
+var RequestSchema []byte
+
+// ResponseSchema is the JSON Schema of a response.
+//
+//go:embed response.schema.json
+var ResponseSchema []byte
+
+// ProtoFile is the file name of the protobuf contract, written by hand.
+const ProtoFile = "translation.proto"
+
+// Proto is the protobuf contract of requests and responses.
+//
+//go:embed translation.proto
+var Proto []byte
+
+// requestSchemas holds the request schema of every contract version.
+//
+//go:embed request.v*.schema.json
+var requestSchemas embed.FS
+
+// RequestSchemaVersionFile is the file name of the request schema of a
+// contract version, e.g. "request.v1.schema.json".
+func RequestSchemaVersionFile(version string) string {
+	return "request." + version + ".schema.json"
+}
+
+// RequestSchemaVersion returns the request schema of a contract version, and
+// false when there is none.
+func RequestSchemaVersion(version string) ([]byte, bool) {
+	data, err := fs.ReadFile(requestSchemas, RequestSchemaVersionFile(version))
+	return data, err == nil
+}
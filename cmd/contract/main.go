@@ -0,0 +1,54 @@
+// Package main runs the translator contract suite against a translator
+// Lambda, so translator teams can validate a function before it is routed
+// to. With -endpoint it targets a local Lambda runtime emulator instead.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/contract"
+)
+
+func main() {
+	function := flag.String("function", "", "translator function name, ARN or alias (required)")
+	targetLang := flag.String("target-lang", "", "target_lang sent with every request (required by en-romance)")
+	endpoint := flag.String("endpoint", "", "Lambda endpoint override, e.g. http://localhost:9000 for the runtime emulator")
+	flag.Parse()
+	if *function == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatalf("failed to load AWS config: %v", err)
+	}
+	client := lambda.NewFromConfig(cfg, func(o *lambda.Options) {
+		if *endpoint != "" {
+			o.BaseEndpoint = endpoint
+		}
+	})
+
+	suite := &contract.Suite{Invoker: client, Function: *function, TargetLang: *targetLang}
+	failed := 0
+	for _, r := range suite.Run(ctx) {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", r.Name, r.Err)
+			continue
+		}
+		fmt.Printf("PASS  %s\n", r.Name)
+	}
+	if failed > 0 {
+		fmt.Printf("%d contract case(s) failed for %s\n", failed, *function)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// HealthcheckSource identifies health check events, e.g. from an uptime
+// monitor
+const HealthcheckSource = "healthcheck"
+
+// HealthcheckEvent is the payload of a health check. Deep checks also
+// probe every translator function.
+type HealthcheckEvent struct {
+	Source string `json:"source"`
+	Deep   bool   `json:"deep"`
+}
+
+// IsHealthcheckEvent checks if the event is a health check
+func IsHealthcheckEvent(event json.RawMessage) (*HealthcheckEvent, bool) {
+	var check HealthcheckEvent
+	if err := json.Unmarshal(event, &check); err != nil || check.Source != HealthcheckSource {
+		return nil, false
+	}
+	return &check, true
+}
+
+// HandleHealthcheck runs a health check, answering 200 when the container
+// is healthy and 503 otherwise, with the outcome of every check.
+func HandleHealthcheck(ctx context.Context, h *handler.Handler, check *HealthcheckEvent) (interface{}, error) {
+	health := h.Healthcheck(ctx, check.Deep)
+	statusCode := 200
+	if health.Status != handler.HealthHealthy {
+		statusCode = 503
+	}
+	return map[string]interface{}{
+		"statusCode": statusCode,
+		"body":       health,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// IsJobRunEvent checks if the event is the background run of an async job
+// and returns its request
+func IsJobRunEvent(event json.RawMessage) (json.RawMessage, bool) {
+	var run struct {
+		Source  string          `json:"source"`
+		Request json.RawMessage `json:"request"`
+	}
+	if err := json.Unmarshal(event, &run); err != nil || run.Source != handler.JobRunSource {
+		return nil, false
+	}
+	return run.Request, true
+}
+
+// HandleJobRun runs the job of an async submission in this invocation.
+func HandleJobRun(ctx context.Context, h *handler.Handler, payload json.RawMessage) (interface{}, error) {
+	req, err := handler.ParseRequest(payload)
+	if err != nil {
+		return handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()), nil
+	}
+	return h.RunJob(ctx, req)
+}
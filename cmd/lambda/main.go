@@ -4,26 +4,78 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"log"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/logging"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/stepfn"
 )
 
 func main() {
-	lambda.Start(handleRequest)
+	if err := logging.Setup(); err != nil {
+		log.Fatal(err)
+	}
+
+	// One router and handler serve every invocation of the container
+	r, err := router.New(context.Background())
+	if err != nil {
+		log.Fatalf("failed to create router: %v", err)
+	}
+	h, err := handler.FromEnv(context.Background(), r)
+	if err != nil {
+		log.Fatalf("failed to create handler: %v", err)
+	}
+	// and one Step Functions client reports the outcome of pipeline tasks
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("failed to load AWS config: %v", err)
+	}
+	stepFunctions := stepfn.New(cfg)
+
+	lambda.Start(func(ctx context.Context, event json.RawMessage) (interface{}, error) {
+		return handleRequest(ctx, h, stepFunctions, event)
+	})
 }
 
-func handleRequest(ctx context.Context, event json.RawMessage) (interface{}, error) {
+func handleRequest(ctx context.Context, h *handler.Handler, stepFunctions *stepfn.Client, event json.RawMessage) (interface{}, error) {
 	// Warmup detection (MUST be first - before any other processing)
 	if warmup, ok := IsWarmupEvent(event); ok {
 		return HandleWarmup(ctx, warmup)
 	}
 
+	// Health checks of uptime monitors
+	if check, ok := IsHealthcheckEvent(event); ok {
+		return HandleHealthcheck(ctx, h, check)
+	}
+
+	// Pipeline stages run by Step Functions
+	if stage, ok := IsPipelineEvent(event); ok {
+		return HandlePipeline(ctx, h, stepFunctions, stage)
+	}
+
+	// Background runs of submitted async jobs
+	if payload, ok := IsJobRunEvent(event); ok {
+		return HandleJobRun(ctx, h, payload)
+	}
+
+	// Async jobs consumed from SQS
+	if sqsEvent, ok := IsSQSEvent(event); ok {
+		return HandleSQS(ctx, h, sqsEvent), nil
+	}
+
+	// Typed callers send protobuf in an envelope
+	if envelope, ok := IsProtobufEvent(event); ok {
+		return HandleProtobuf(ctx, h, envelope)
+	}
+
 	// Parse the request and delegate to the handler
-	var req handler.Request
-	if err := json.Unmarshal(event, &req); err != nil {
-		return nil, err
+	req, err := handler.ParseRequest(event)
+	if err != nil {
+		return handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()), nil
 	}
 
-	return handler.Handle(ctx, req)
+	return h.Handle(ctx, req)
 }
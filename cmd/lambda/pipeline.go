@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/stepfn"
+)
+
+// PipelineEvent runs one stage of a pipeline from a Step Functions state
+// machine. With a TaskToken (the .waitForTaskToken integration) the outcome
+// is sent to Step Functions; otherwise it is the result of the invocation.
+type PipelineEvent struct {
+	Stage     string                `json:"pipelineStage"`
+	State     handler.PipelineState `json:"state"`
+	TaskToken string                `json:"taskToken,omitempty"`
+}
+
+// IsPipelineEvent checks if the event is a pipeline stage
+func IsPipelineEvent(event json.RawMessage) (*PipelineEvent, bool) {
+	var stage PipelineEvent
+	if err := json.Unmarshal(event, &stage); err != nil || stage.Stage == "" {
+		return nil, false
+	}
+	return &stage, true
+}
+
+// HandlePipeline runs a pipeline stage, reporting the outcome of task
+// token stages to stepFunctions. Failures are named after their error code
+// (e.g. TRANSLATION_FAILED), for the Retry and Catch rules of the state
+// machine.
+func HandlePipeline(ctx context.Context, h *handler.Handler, stepFunctions *stepfn.Client, event *PipelineEvent) (interface{}, error) {
+	state, errInfo := h.RunStage(ctx, event.Stage, event.State)
+	if event.TaskToken == "" {
+		if errInfo != nil {
+			return nil, messages.InvokeResponse_Error{Type: errInfo.Code, Message: errInfo.Message}
+		}
+		return state, nil
+	}
+
+	if errInfo != nil {
+		return nil, stepFunctions.SendTaskFailure(ctx, event.TaskToken, errInfo.Code, errInfo.Message)
+	}
+	return nil, stepFunctions.SendTaskSuccess(ctx, event.TaskToken, state)
+}
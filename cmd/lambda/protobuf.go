@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/protoapi"
+)
+
+// ProtobufEvent carries a protobuf TranslateRequest through Lambda, which
+// only accepts JSON payloads; the response is a TranslateResponse in the
+// same envelope.
+type ProtobufEvent struct {
+	Format  string `json:"format"`
+	Payload []byte `json:"payload"`
+}
+
+// IsProtobufEvent checks if the event is a protobuf request envelope
+func IsProtobufEvent(event json.RawMessage) (*ProtobufEvent, bool) {
+	var envelope ProtobufEvent
+	if err := json.Unmarshal(event, &envelope); err != nil || envelope.Format != protoapi.Format {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// HandleProtobuf decodes the request into the same handler path as JSON
+// requests and encodes the response.
+func HandleProtobuf(ctx context.Context, h *handler.Handler, event *ProtobufEvent) (interface{}, error) {
+	var resp *handler.Response
+	req, err := handler.ParseProtobufRequest(event.Payload)
+	if err != nil {
+		resp = handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error())
+	} else if resp, err = h.Handle(ctx, req); err != nil {
+		return nil, err
+	}
+	payload, err := handler.EncodeProtobufResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return ProtobufEvent{Format: protoapi.Format, Payload: payload}, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// sqsSource is the eventSource of SQS records.
+const sqsSource = "aws:sqs"
+
+// IsSQSEvent checks if the event is a batch of SQS messages
+func IsSQSEvent(event json.RawMessage) (*events.SQSEvent, bool) {
+	var sqsEvent events.SQSEvent
+	if err := json.Unmarshal(event, &sqsEvent); err != nil || len(sqsEvent.Records) == 0 {
+		return nil, false
+	}
+	if sqsEvent.Records[0].EventSource != sqsSource {
+		return nil, false
+	}
+	return &sqsEvent, true
+}
+
+// JobConcurrencyEnv bounds the messages of an SQS batch processed at once
+// (default 1, one after another).
+const JobConcurrencyEnv = "SQS_JOB_CONCURRENCY"
+
+// HandleSQS processes each message as an async job. The message body is a
+// translation request; its jobId defaults to the message ID, so producers
+// should set jobId to keep redriven messages idempotent, or idempotencyKey
+// to also recognise the same job sent twice. Messages that fail, or whose
+// processing panics, are reported as batch item failures so only they are
+// redelivered; invalid requests are logged and dropped since they can never
+// succeed.
+func HandleSQS(ctx context.Context, h *handler.Handler, event *events.SQSEvent) events.SQSEventResponse {
+	err := workpool.Pool{Limit: jobConcurrency()}.Run(ctx, len(event.Records), func(ctx context.Context, i int) error {
+		return handleMessage(ctx, h, event.Records[i])
+	})
+
+	var resp events.SQSEventResponse
+	for _, f := range workpool.Failures(err) {
+		msg := event.Records[f.Index]
+		slog.ErrorContext(ctx, "job message failed", slog.String("messageId", msg.MessageId), slog.Any("error", f.Err))
+		resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+	}
+	return resp
+}
+
+// handleMessage runs the job of one message.
+func handleMessage(ctx context.Context, h *handler.Handler, msg events.SQSMessage) error {
+	req, err := handler.ParseRequest([]byte(msg.Body))
+	if err != nil {
+		slog.WarnContext(ctx, "dropping invalid job message", slog.String("messageId", msg.MessageId), slog.Any("error", err))
+		return nil
+	}
+	if req.JobID == "" {
+		req.JobID = msg.MessageId
+	}
+	_, err = h.RunJob(ctx, req)
+	return err
+}
+
+// jobConcurrency reads JobConcurrencyEnv.
+func jobConcurrency() int {
+	v := os.Getenv(JobConcurrencyEnv)
+	if v == "" {
+		return 1
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		slog.Warn("ignoring invalid job concurrency: want a positive count", slog.String(JobConcurrencyEnv, v))
+		return 1
+	}
+	return n
+}
@@ -6,13 +6,14 @@ import (
 	"context"
 	"encoding/json"
 	"os"
-	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	lambdasdk "github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/pricofy/translation-manager/internal/workpool"
 )
 
 const (
@@ -104,31 +105,16 @@ func selfInvoke(ctx context.Context, count int) error {
 	}
 
 	// Invoke in parallel
-	var wg sync.WaitGroup
-	var invokeErr error
-	var errMu sync.Mutex
-
-	for i := 0; i < count; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			_, err := client.Invoke(ctx, &lambdasdk.InvokeInput{
-				FunctionName:   aws.String(functionName),
-				InvocationType: types.InvocationTypeEvent, // Async invocation
-				Payload:        payload,
-			})
-
-			if err != nil {
-				errMu.Lock()
-				if invokeErr == nil {
-					invokeErr = err
-				}
-				errMu.Unlock()
-			}
-		}()
+	err = workpool.Pool{}.Run(ctx, count, func(ctx context.Context, _ int) error {
+		_, err := client.Invoke(ctx, &lambdasdk.InvokeInput{
+			FunctionName:   aws.String(functionName),
+			InvocationType: types.InvocationTypeEvent, // Async invocation
+			Payload:        payload,
+		})
+		return err
+	})
+	if first := workpool.First(err); first != nil {
+		return first.Err
 	}
-
-	wg.Wait()
-	return invokeErr
+	return nil
 }
@@ -0,0 +1,27 @@
+// Package main writes the generated OpenAPI document and JSON Schemas of the
+// HTTP API (see internal/openapi). It runs from go generate.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pricofy/translation-manager/internal/openapi"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory to write the documents to")
+	flag.Parse()
+
+	files, err := openapi.Generate()
+	if err != nil {
+		log.Fatalf("failed to generate documents: %v", err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(*dir, name), data, 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package main replays recorded translation requests through a deployment
+// of the manager for incident recovery: the translations captured in a time
+// range, or the messages of a dead-letter queue, are sent again with
+// idempotency keys and a JSONL report of their outcomes is written.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pricofy/translation-manager/internal/capture"
+	"github.com/pricofy/translation-manager/internal/replay"
+)
+
+func main() {
+	function := flag.String("function", "", "manager function name, ARN or alias to replay through (required)")
+	bucket := flag.String("capture-bucket", "", "S3 bucket of replay capture files")
+	prefix := flag.String("capture-prefix", capture.DefaultPrefix, "S3 key prefix of replay capture files")
+	from := flag.String("from", "", "start of the capture time range, RFC 3339 or YYYY-MM-DD")
+	to := flag.String("to", "", "end (exclusive) of the capture time range, RFC 3339 or YYYY-MM-DD (default now)")
+	requests := flag.String("requests", "", "JSONL file of requests or exported SQS messages, e.g. a dead-letter queue")
+	batch := flag.Int("batch", 50, "most captured texts of one pair per replayed request")
+	concurrency := flag.Int("concurrency", 4, "requests in flight")
+	idempotent := flag.Bool("idempotent", true, "replay as journaled job runs keyed by idempotency key (needs async jobs)")
+	out := flag.String("out", "", "report file (default stdout)")
+	flag.Parse()
+	if *function == "" || (*bucket == "") == (*requests == "") {
+		fmt.Fprintln(os.Stderr, "replay needs -function and exactly one of -capture-bucket or -requests")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatalf("failed to load AWS config: %v", err)
+	}
+
+	var cases []replay.Case
+	if *requests != "" {
+		cases, err = readRequests(*requests)
+	} else {
+		cases, err = readCapture(ctx, &replay.Capture{Objects: s3.NewFromConfig(cfg), Bucket: *bucket, Prefix: *prefix, BatchSize: *batch}, *from, *to)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := &replay.Replayer{
+		Invoker:     lambda.NewFromConfig(cfg),
+		Function:    *function,
+		Idempotent:  *idempotent,
+		Concurrency: *concurrency,
+	}
+	outcomes := r.Run(ctx, cases)
+
+	if err := writeReport(*out, outcomes); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+
+	summary := replay.Summary(outcomes)
+	statuses := make([]string, 0, len(summary))
+	for status := range summary {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	fmt.Fprintf(os.Stderr, "replayed %d case(s) through %s:", len(outcomes), *function)
+	for _, status := range statuses {
+		fmt.Fprintf(os.Stderr, " %s=%d", status, summary[status])
+	}
+	fmt.Fprintln(os.Stderr)
+	if summary[replay.StatusFailed] > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeReport writes the report to path, or to stdout when path is empty.
+func writeReport(path string, outcomes []replay.Outcome) error {
+	if path == "" {
+		return replay.WriteReport(os.Stdout, outcomes)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := replay.WriteReport(f, outcomes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readRequests reads the cases of a requests file.
+func readRequests(path string) ([]replay.Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return replay.Requests(f)
+}
+
+// readCapture reads the captured cases of a time range.
+func readCapture(ctx context.Context, c *replay.Capture, from, to string) ([]replay.Case, error) {
+	if from == "" {
+		return nil, fmt.Errorf("-from is required with -capture-bucket")
+	}
+	start, err := parseTime(from)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	if to != "" {
+		if end, err = parseTime(to); err != nil {
+			return nil, err
+		}
+	}
+	return c.Cases(ctx, start, end)
+}
+
+// parseTime parses an RFC 3339 time or a UTC date.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: want RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,88 @@
+// Package main runs the translation manager as a long-lived HTTP server,
+// an alternative to the Lambda entry point for containers and local use.
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/logging"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/server"
+)
+
+const (
+	defaultAddr     = ":8080"
+	shutdownTimeout = 30 * time.Second
+)
+
+func main() {
+	if err := logging.Setup(); err != nil {
+		log.Fatal(err)
+	}
+
+	addr := os.Getenv("SERVER_ADDR")
+	if addr == "" {
+		addr = defaultAddr
+	}
+
+	concurrency := 0
+	if v := os.Getenv("TRANSLATOR_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid TRANSLATOR_CONCURRENCY %q", v)
+		}
+		concurrency = n
+	}
+
+	var batchWindow time.Duration
+	if v := os.Getenv("BATCH_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatalf("invalid BATCH_WINDOW %q", v)
+		}
+		batchWindow = d
+	}
+
+	// One router and handler serve every request
+	r, err := router.New(context.Background())
+	if err != nil {
+		log.Fatalf("failed to create router: %v", err)
+	}
+	h, err := handler.FromEnv(context.Background(), r)
+	if err != nil {
+		log.Fatalf("failed to create handler: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.New(server.Options{Translate: h.Handle, Concurrency: concurrency, BatchWindow: batchWindow}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		slog.Info("translation manager listening", slog.String("addr", addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.ErrorContext(shutdownCtx, "shutdown failed", slog.Any("error", err))
+	}
+}
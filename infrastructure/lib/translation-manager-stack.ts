@@ -2,11 +2,15 @@
  * Translation Manager Stack
  *
  * Deploys the Go Lambda that orchestrates translation requests.
- * Routes to 4 single-direction translator Lambdas:
+ * Routes to 8 single-direction translator Lambdas:
  * - translator-romance-en: ES/FR/IT/PT → EN
  * - translator-en-romance: EN → ES/FR/IT/PT
  * - translator-de-en: DE → EN
  * - translator-en-de: EN → DE
+ * - translator-sla-en: PL/CS/UK/RU/... → EN
+ * - translator-en-sla: EN → PL/CS/UK/RU/...
+ * - translator-gmq-en: SV/DA/NO/IS → EN
+ * - translator-en-gmq: EN → SV/DA/NO/IS
  */
 
 import * as cdk from 'aws-cdk-lib';
@@ -15,6 +19,8 @@ import * as logs from 'aws-cdk-lib/aws-logs';
 import * as iam from 'aws-cdk-lib/aws-iam';
 import * as events from 'aws-cdk-lib/aws-events';
 import * as targets from 'aws-cdk-lib/aws-events-targets';
+import * as lambdaEventSources from 'aws-cdk-lib/aws-lambda-event-sources';
+import * as sqs from 'aws-cdk-lib/aws-sqs';
 import { Construct } from 'constructs';
 import * as path from 'path';
 
@@ -22,12 +28,16 @@ export interface TranslationManagerStackProps extends cdk.StackProps {
   environment: 'dev' | 'prod';
 }
 
-// The 4 translator Lambdas
+// The 8 translator Lambdas
 const TRANSLATORS = [
   'translator-romance-en',
   'translator-en-romance',
   'translator-de-en',
   'translator-en-de',
+  'translator-sla-en',
+  'translator-en-sla',
+  'translator-gmq-en',
+  'translator-en-gmq',
 ];
 
 export class TranslationManagerStack extends cdk.Stack {
@@ -47,21 +57,436 @@ export class TranslationManagerStack extends cdk.Stack {
       code: lambda.Code.fromAsset(path.join(__dirname, '../../dist')),
       timeout: cdk.Duration.seconds(120),
       memorySize: 128,
+      // X-Ray: the manager's subsegments and translator calls form one trace
+      tracing: lambda.Tracing.ACTIVE,
       environment: {
         ENVIRONMENT: environment,
+        // JSON logs: debug, info, warn or error
+        LOG_LEVEL: this.node.tryGetContext('logLevel') ?? 'info',
       },
       description: `Translation orchestrator - routes to translator Lambdas (${environment})`,
     });
 
-    // Grant invoke permissions on all 4 translator Lambdas
+    // Default translator function names (opt-in pattern), e.g.
+    // "pricofy-translator-{translator}-{env}" so each stage invokes its own
+    // copies, and the alias or version invoked on them, e.g. "live"
+    const functionPattern: string | undefined = this.node.tryGetContext('translatorFunctionPattern');
+    if (functionPattern) {
+      this.managerFunction.addEnvironment('TRANSLATOR_FUNCTION_PATTERN', functionPattern);
+    }
+    const qualifier: string | undefined = this.node.tryGetContext('translatorQualifier');
+    if (qualifier) {
+      this.managerFunction.addEnvironment('TRANSLATOR_QUALIFIER', qualifier);
+    }
+    const defaultFunctionArn = (translator: string) => {
+      const name = (functionPattern ?? 'pricofy-translator-{translator}')
+        .split('{translator}')
+        .join(translator)
+        .split('{env}')
+        .join(environment);
+      return `arn:aws:lambda:${this.region}:${this.account}:function:${name}`;
+    };
+
+    // Grant invoke permissions on all 8 translator Lambdas
     for (const translator of TRANSLATORS) {
-      const functionArn = `arn:aws:lambda:${this.region}:${this.account}:function:pricofy-${translator}`;
+      const functionArn = defaultFunctionArn(translator.replace(/^translator-/, ''));
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['lambda:InvokeFunction'],
+          resources: qualifier ? [functionArn, `${functionArn}:*`] : [functionArn],
+        })
+      );
+    }
+
+    // Translator mapping (opt-in): serve language groups from other
+    // functions or accounts, e.g. {"romance-en": "translator-romance-en-{env}"}
+    const translatorFunctions = this.node.tryGetContext('translatorFunctions');
+    if (translatorFunctions) {
+      this.managerFunction.addEnvironment('TRANSLATOR_FUNCTIONS', translatorFunctions);
+      const mapped: string[] = Object.values(JSON.parse(translatorFunctions)).map((f) =>
+        String(f).split('{env}').join(environment)
+      );
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['lambda:InvokeFunction'],
+          resources: mapped.flatMap((f) => {
+            const arn = f.startsWith('arn:') ? f : `arn:aws:lambda:${this.region}:${this.account}:function:${f}`;
+            return [arn, `${arn}:*`];
+          }),
+        })
+      );
+    }
+
+    // Direct pair translators (opt-in) replacing the English pivot, e.g.
+    // ["es-it", "pt-es"] for pricofy-translator-es-it and -pt-es
+    const directPairs = this.node.tryGetContext('directPairs');
+    if (directPairs) {
+      this.managerFunction.addEnvironment('DIRECT_PAIRS', directPairs);
       this.managerFunction.addToRolePolicy(
         new iam.PolicyStatement({
           actions: ['lambda:InvokeFunction'],
-          resources: [functionArn],
+          resources: (JSON.parse(directPairs) as string[]).flatMap((pair) =>
+            qualifier ? [defaultFunctionArn(pair), `${defaultFunctionArn(pair)}:*`] : [defaultFunctionArn(pair)]
+          ),
+        })
+      );
+    }
+
+    // Pivot languages other than English per pair, e.g. {"ca-gl": "es"} or
+    // {"gl-oc": ["es", "ca"]}; the hops' direct translators are mapped in
+    // translatorFunctions
+    const pivotLanguages = this.node.tryGetContext('pivotLanguages');
+    if (pivotLanguages) {
+      this.managerFunction.addEnvironment('PIVOT_LANGUAGES', pivotLanguages);
+    }
+
+    // Route selection weights per translator, e.g.
+    // {"es-it": {"qualityPenalty": 1.5}, "gmq-en": {"latencyMs": 800}}
+    const routeWeights = this.node.tryGetContext('routeWeights');
+    if (routeWeights) {
+      this.managerFunction.addEnvironment('ROUTE_WEIGHTS', routeWeights);
+    }
+
+    // Load limits per translator function, e.g.
+    // {"pricofy-translator-en-romance-{env}": {"maxConcurrent": 20}}
+    const translatorLimits = this.node.tryGetContext('translatorLimits');
+    if (translatorLimits) {
+      this.managerFunction.addEnvironment('TRANSLATOR_LIMITS', translatorLimits);
+    }
+
+    // Chunk token budgets per translator function, for translators with
+    // more memory, e.g. {"pricofy-translator-en-de-{env}": 8000}
+    const translatorChunkTokens = this.node.tryGetContext('translatorChunkTokens');
+    if (translatorChunkTokens) {
+      this.managerFunction.addEnvironment('TRANSLATOR_CHUNK_TOKENS', translatorChunkTokens);
+    }
+
+    // Translation backends (opt-in): Amazon Translate or DeepL per pair and
+    // as fallback for pairs without translator Lambdas, e.g.
+    // {"fallback": "aws-translate"}
+    const translationBackends = this.node.tryGetContext('translationBackends');
+    if (translationBackends) {
+      this.managerFunction.addEnvironment('TRANSLATION_BACKENDS', translationBackends);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['translate:TranslateText'],
+          resources: ['*'],
+        })
+      );
+    }
+    // DeepL API key from Secrets Manager, e.g. for premium translations
+    const deeplApiKeySecret = this.node.tryGetContext('deeplApiKeySecret');
+    if (deeplApiKeySecret) {
+      this.managerFunction.addEnvironment('DEEPL_API_KEY_SECRET', deeplApiKeySecret);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['secretsmanager:GetSecretValue'],
+          resources: [
+            deeplApiKeySecret.startsWith('arn:')
+              ? deeplApiKeySecret
+              : `arn:aws:secretsmanager:${this.region}:${this.account}:secret:${deeplApiKeySecret}-*`,
+          ],
+        })
+      );
+    }
+
+    // PII masking: external (default), all or off
+    const piiMasking = this.node.tryGetContext('piiMasking');
+    if (piiMasking) {
+      this.managerFunction.addEnvironment('PII_MASKING', piiMasking);
+    }
+
+    // Regional variant wording beyond the built-in dictionaries, e.g.
+    // {"es_AR": {"coche": "auto"}}
+    const variantSubstitutions = this.node.tryGetContext('variantSubstitutions');
+    if (variantSubstitutions) {
+      this.managerFunction.addEnvironment('VARIANT_SUBSTITUTIONS', variantSubstitutions);
+    }
+
+    // Bedrock backend (opt-in): a foundation model translating as the
+    // `bedrock` backend, e.g. {"modelId": "anthropic.claude-3-haiku-20240307-v1:0"}
+    const bedrockTranslator = this.node.tryGetContext('bedrockTranslator');
+    if (bedrockTranslator) {
+      this.managerFunction.addEnvironment('BEDROCK_TRANSLATOR', bedrockTranslator);
+      const modelId = String(JSON.parse(bedrockTranslator).modelId ?? '');
+      if (!modelId) {
+        throw new Error('bedrockTranslator: modelId is required');
+      }
+      // Inference profiles ("eu.anthropic...") route to their model in the
+      // regions of the profile; plain model IDs run in the stack's region
+      const [, profileModel] = modelId.match(/^[a-z]+\.([a-z0-9-]+\..+)$/) ?? [];
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['bedrock:InvokeModel'],
+          resources: modelId.startsWith('arn:')
+            ? [modelId]
+            : profileModel
+              ? [
+                  `arn:aws:bedrock:${this.region}:${this.account}:inference-profile/${modelId}`,
+                  `arn:aws:bedrock:*::foundation-model/${profileModel}`,
+                ]
+              : [`arn:aws:bedrock:${this.region}::foundation-model/${modelId}`],
+        })
+      );
+    }
+
+    // Replay capture (opt-in): samples anonymized translations into S3
+    const captureBucket = this.node.tryGetContext('captureBucket');
+    if (captureBucket) {
+      this.managerFunction.addEnvironment('CAPTURE_BUCKET', captureBucket);
+      this.managerFunction.addEnvironment(
+        'CAPTURE_SAMPLE_RATE',
+        String(this.node.tryGetContext('captureSampleRate') ?? '0.01')
+      );
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['s3:PutObject'],
+          resources: [`arn:aws:s3:::${captureBucket}/capture/*`],
+        })
+      );
+    }
+
+    // Async jobs (opt-in): job results and event-mode translator results in S3
+    const asyncBucket = this.node.tryGetContext('asyncBucket');
+    if (asyncBucket) {
+      this.managerFunction.addEnvironment('ASYNC_BUCKET', asyncBucket);
+      const translatorInvocation = this.node.tryGetContext('translatorInvocation');
+      if (translatorInvocation) {
+        this.managerFunction.addEnvironment('TRANSLATOR_INVOCATION', translatorInvocation);
+      }
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['s3:GetObject', 's3:PutObject'],
+          resources: [
+            `arn:aws:s3:::${asyncBucket}/jobs/*`,
+            `arn:aws:s3:::${asyncBucket}/results/*`,
+          ],
+        })
+      );
+      // ListBucket makes missing (not yet written) results 404 instead of 403
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['s3:ListBucket'],
+          resources: [`arn:aws:s3:::${asyncBucket}`],
+        })
+      );
+    }
+
+    // Async job results in DynamoDB (opt-in): takes precedence over the
+    // bucket for job results. Enable TTL on the table's expiresAt attribute.
+    const jobsResultTable = this.node.tryGetContext('jobsResultTable');
+    if (jobsResultTable) {
+      this.managerFunction.addEnvironment('JOBS_RESULT_TABLE', jobsResultTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${jobsResultTable}`],
+        })
+      );
+    }
+
+    // Job completion fan-out (opt-in): consumers subscribe to this SNS topic
+    const jobsTopicArn = this.node.tryGetContext('jobsTopicArn');
+    if (jobsTopicArn) {
+      this.managerFunction.addEnvironment('JOBS_TOPIC_ARN', jobsTopicArn);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['sns:Publish'],
+          resources: [jobsTopicArn],
+        })
+      );
+    }
+
+    // Per-tenant default options (opt-in): profiles are read from this table
+    const tenantProfilesTable = this.node.tryGetContext('tenantProfilesTable');
+    if (tenantProfilesTable) {
+      this.managerFunction.addEnvironment('TENANT_PROFILES_TABLE', tenantProfilesTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${tenantProfilesTable}`],
+        })
+      );
+    }
+
+    // Runtime routing table (opt-in): admins route pairs to translator
+    // functions and aliases without a deploy. Table routes may target any
+    // pricofy-translator-* function or alias.
+    const routingTable = this.node.tryGetContext('routingTable');
+    if (routingTable) {
+      this.managerFunction.addEnvironment('ROUTING_TABLE', routingTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:Scan', 'dynamodb:GetItem', 'dynamodb:PutItem', 'dynamodb:DeleteItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${routingTable}`],
+        })
+      );
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['lambda:InvokeFunction'],
+          resources: [
+            `arn:aws:lambda:${this.region}:${this.account}:function:pricofy-translator-*`,
+            `arn:aws:lambda:${this.region}:${this.account}:function:pricofy-translator-*:*`,
+          ],
+        })
+      );
+    }
+    // Canary rollback alarms (opt-in): on-call subscribes to this SNS topic
+    const alarmTopicArn = this.node.tryGetContext('alarmTopicArn');
+    if (alarmTopicArn) {
+      this.managerFunction.addEnvironment('ALARM_TOPIC_ARN', alarmTopicArn);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['sns:Publish'],
+          resources: [alarmTopicArn],
+        })
+      );
+    }
+    const adminTokens = this.node.tryGetContext('adminTokens');
+    if (adminTokens) {
+      this.managerFunction.addEnvironment('ADMIN_TOKENS', adminTokens);
+    }
+
+    // Job journal (opt-in): each async job is processed exactly once across
+    // retries and redrives. Enable TTL on the table's expiresAt attribute.
+    const journalTable = this.node.tryGetContext('journalTable');
+    if (journalTable) {
+      this.managerFunction.addEnvironment('JOURNAL_TABLE', journalTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${journalTable}`],
+        })
+      );
+    }
+
+    // Job states (opt-in): async and batch jobs record their state, chunk
+    // progress and output location for status lookups. Enable TTL on expiresAt.
+    const jobsTable = this.node.tryGetContext('jobsTable');
+    if (jobsTable) {
+      this.managerFunction.addEnvironment('JOBS_TABLE', jobsTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${jobsTable}`],
+        })
+      );
+    }
+
+    // Dead letters (opt-in): failed chunks of jobs are written to error
+    // manifests under dead-letters/ for redrives instead of failing the job.
+    const deadLetterBucket = this.node.tryGetContext('deadLetterBucket');
+    if (deadLetterBucket) {
+      this.managerFunction.addEnvironment('DEAD_LETTER_BUCKET', deadLetterBucket);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['s3:GetObject', 's3:PutObject'],
+          resources: [`arn:aws:s3:::${deadLetterBucket}/dead-letters/*`],
+        })
+      );
+      // ListBucket makes missing manifests 404 instead of 403
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['s3:ListBucket'],
+          resources: [`arn:aws:s3:::${deadLetterBucket}`],
+        })
+      );
+    }
+
+    // Batch mode (opt-in): JSONL files in the listed buckets are translated
+    // part by part, with progress in DynamoDB. Enable TTL on expiresAt.
+    const batchProgressTable = this.node.tryGetContext('batchProgressTable');
+    if (batchProgressTable) {
+      this.managerFunction.addEnvironment('BATCH_PROGRESS_TABLE', batchProgressTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${batchProgressTable}`],
+        })
+      );
+      const batchBuckets: string[] = String(this.node.tryGetContext('batchBuckets') ?? '')
+        .split(',')
+        .map((bucket) => bucket.trim())
+        .filter((bucket) => bucket !== '');
+      if (batchBuckets.length > 0) {
+        this.managerFunction.addToRolePolicy(
+          new iam.PolicyStatement({
+            actions: ['s3:GetObject', 's3:PutObject'],
+            resources: batchBuckets.map((bucket) => `arn:aws:s3:::${bucket}/*`),
+          })
+        );
+      }
+    }
+
+    // Idempotency keys (opt-in): requests with an idempotencyKey store their
+    // response in DynamoDB. Enable TTL on the table's expiresAt attribute.
+    const idempotencyTable = this.node.tryGetContext('idempotencyTable');
+    if (idempotencyTable) {
+      this.managerFunction.addEnvironment('IDEMPOTENCY_TABLE', idempotencyTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${idempotencyTable}`],
+        })
+      );
+    }
+
+    // Translation cache (opt-in): repeated texts are answered from DynamoDB
+    // instead of the translators. Enable TTL on the table's expiresAt attribute.
+    const translationCacheTable = this.node.tryGetContext('translationCacheTable');
+    if (translationCacheTable) {
+      this.managerFunction.addEnvironment('TRANSLATION_CACHE_TABLE', translationCacheTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:BatchGetItem', 'dynamodb:BatchWriteItem'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${translationCacheTable}`],
+        })
+      );
+    }
+
+    // Usage accounting (opt-in): translations with a callerId add their
+    // usage per caller and day to DynamoDB for chargeback
+    const usageTable = this.node.tryGetContext('usageTable');
+    if (usageTable) {
+      this.managerFunction.addEnvironment('USAGE_TABLE', usageTable);
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['dynamodb:UpdateItem', 'dynamodb:Query', 'dynamodb:Scan'],
+          resources: [`arn:aws:dynamodb:${this.region}:${this.account}:table/${usageTable}`],
+        })
+      );
+    }
+
+    // Step Functions pipelines (opt-in): report the outcome of stages run
+    // with a task token (.waitForTaskToken) to the state machines named, e.g.
+    // ["catalog-translation-{env}"] or their ARNs
+    const pipelineStateMachines = this.node.tryGetContext('pipelineStateMachines');
+    if (pipelineStateMachines) {
+      this.managerFunction.addToRolePolicy(
+        new iam.PolicyStatement({
+          actions: ['states:SendTaskSuccess', 'states:SendTaskFailure'],
+          resources: (JSON.parse(pipelineStateMachines) as string[]).map((m) => {
+            const name = m.split('{env}').join(environment);
+            return name.startsWith('arn:') ? name : `arn:aws:states:${this.region}:${this.account}:stateMachine:${name}`;
+          }),
+        })
+      );
+    }
+
+    // Job queue (opt-in): consume async jobs from SQS, retrying only the
+    // failed messages of a batch
+    const jobsQueueArn = this.node.tryGetContext('jobsQueueArn');
+    if (jobsQueueArn) {
+      this.managerFunction.addEventSource(
+        new lambdaEventSources.SqsEventSource(sqs.Queue.fromQueueArn(this, 'JobsQueue', jobsQueueArn), {
+          batchSize: 10,
+          reportBatchItemFailures: true,
         })
       );
+      const jobConcurrency = this.node.tryGetContext('jobConcurrency');
+      if (jobConcurrency) {
+        this.managerFunction.addEnvironment('SQS_JOB_CONCURRENCY', String(jobConcurrency));
+      }
     }
 
     // Log group
@@ -0,0 +1,175 @@
+// Package accounting records the translation work done for each caller
+// per day, so translation compute can be charged back to the teams that
+// use it.
+//
+// Usage is kept in a DynamoDB table keyed by the string attributes
+// "callerId" (partition key) and "day" (sort key, "2006-01-02" in UTC).
+// Every translation adds its counters to the item of its caller and day
+// with an atomic update, so concurrent containers never lose counts.
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table holding the usage.
+const TableEnv = "USAGE_TABLE"
+
+// DayLayout is the format of days.
+const DayLayout = "2006-01-02"
+
+// CallerPattern matches valid caller IDs: 1-64 letters, digits, '.', '_'
+// or '-', such as "catalog-sync".
+var CallerPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Table is the subset of the DynamoDB client used by the ledger.
+type Table interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// Usage is the work done for a caller on a day.
+type Usage struct {
+	CallerID string `json:"callerId"`
+	Day      string `json:"day"`
+	// Requests and Texts count the translations and their texts.
+	Requests int64 `json:"requests"`
+	Texts    int64 `json:"texts"`
+	// Characters and Tokens measure the texts sent to the translators;
+	// texts answered without them cost no compute and are not counted.
+	Characters int64 `json:"characters"`
+	Tokens     int64 `json:"tokens"`
+}
+
+// counters are the item attributes of the Usage counters.
+var counters = []string{"requests", "texts", "characters", "tokens"}
+
+// values returns the counters of u in the order of counters.
+func (u Usage) values() []int64 {
+	return []int64{u.Requests, u.Texts, u.Characters, u.Tokens}
+}
+
+// Ledger reads and writes the usage table.
+type Ledger struct {
+	table Table
+	name  string
+	now   func() time.Time
+}
+
+// New creates a Ledger of the named table.
+func New(table Table, name string) *Ledger {
+	return &Ledger{table: table, name: name, now: time.Now}
+}
+
+// Record adds the counters of u to the usage of u.CallerID today.
+func (l *Ledger) Record(ctx context.Context, u Usage) error {
+	expr := "ADD"
+	values := map[string]types.AttributeValue{}
+	for i, v := range u.values() {
+		if i > 0 {
+			expr += ","
+		}
+		expr += fmt.Sprintf(" %s :%s", counters[i], counters[i])
+		values[":"+counters[i]] = &types.AttributeValueMemberN{Value: strconv.FormatInt(v, 10)}
+	}
+	_, err := l.table.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(l.name),
+		Key: map[string]types.AttributeValue{
+			"callerId": &types.AttributeValueMemberS{Value: u.CallerID},
+			"day":      &types.AttributeValueMemberS{Value: l.now().UTC().Format(DayLayout)},
+		},
+		UpdateExpression:          aws.String(expr),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record usage of %s: %w", u.CallerID, err)
+	}
+	return nil
+}
+
+// Query returns the usage from day from to day to, both included, of
+// callerID, or of every caller when callerID is "", by caller and day.
+func (l *Ledger) Query(ctx context.Context, callerID, from, to string) ([]Usage, error) {
+	names := map[string]string{"#day": "day"}
+	values := map[string]types.AttributeValue{
+		":from": &types.AttributeValueMemberS{Value: from},
+		":to":   &types.AttributeValueMemberS{Value: to},
+	}
+	var items []map[string]types.AttributeValue
+	var start map[string]types.AttributeValue
+	for {
+		var page []map[string]types.AttributeValue
+		var err error
+		if callerID != "" {
+			values[":caller"] = &types.AttributeValueMemberS{Value: callerID}
+			var out *dynamodb.QueryOutput
+			out, err = l.table.Query(ctx, &dynamodb.QueryInput{
+				TableName:                 aws.String(l.name),
+				KeyConditionExpression:    aws.String("callerId = :caller AND #day BETWEEN :from AND :to"),
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+				ExclusiveStartKey:         start,
+			})
+			if out != nil {
+				page, start = out.Items, out.LastEvaluatedKey
+			}
+		} else {
+			var out *dynamodb.ScanOutput
+			out, err = l.table.Scan(ctx, &dynamodb.ScanInput{
+				TableName:                 aws.String(l.name),
+				FilterExpression:          aws.String("#day BETWEEN :from AND :to"),
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+				ExclusiveStartKey:         start,
+			})
+			if out != nil {
+				page, start = out.Items, out.LastEvaluatedKey
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read usage: %w", err)
+		}
+		items = append(items, page...)
+		if len(start) == 0 {
+			break
+		}
+	}
+	return fromItems(items), nil
+}
+
+// fromItems converts usage items, skipping malformed ones, sorted by
+// caller and day.
+func fromItems(items []map[string]types.AttributeValue) []Usage {
+	usage := make([]Usage, 0, len(items))
+	for _, it := range items {
+		caller, okCaller := it["callerId"].(*types.AttributeValueMemberS)
+		day, okDay := it["day"].(*types.AttributeValueMemberS)
+		if !okCaller || !okDay {
+			continue
+		}
+		u := Usage{CallerID: caller.Value, Day: day.Value}
+		for i, dst := range []*int64{&u.Requests, &u.Texts, &u.Characters, &u.Tokens} {
+			if n, ok := it[counters[i]].(*types.AttributeValueMemberN); ok {
+				*dst, _ = strconv.ParseInt(n.Value, 10, 64)
+			}
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].CallerID != usage[j].CallerID {
+			return usage[i].CallerID < usage[j].CallerID
+		}
+		return usage[i].Day < usage[j].Day
+	})
+	return usage
+}
@@ -0,0 +1,148 @@
+package accounting
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "callerId" and "day" that
+// applies ADD updates and returns one item per page.
+type memoryTable struct {
+	items map[[2]string]map[string]types.AttributeValue
+	keys  [][2]string
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{items: map[[2]string]map[string]types.AttributeValue{}}
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func (m *memoryTable) UpdateItem(_ context.Context, in *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	key := [2]string{stringAttr(in.Key, "callerId"), stringAttr(in.Key, "day")}
+	item, ok := m.items[key]
+	if !ok {
+		item = map[string]types.AttributeValue{"callerId": in.Key["callerId"], "day": in.Key["day"]}
+		m.items[key] = item
+		m.keys = append(m.keys, key)
+	}
+	for _, add := range strings.Split(strings.TrimPrefix(*in.UpdateExpression, "ADD "), ", ") {
+		name, value, _ := strings.Cut(add, " ")
+		n, _ := strconv.ParseInt(in.ExpressionAttributeValues[value].(*types.AttributeValueMemberN).Value, 10, 64)
+		if old, ok := item[name].(*types.AttributeValueMemberN); ok {
+			v, _ := strconv.ParseInt(old.Value, 10, 64)
+			n += v
+		}
+		item[name] = &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// page returns the item after start matching the caller ("" for any) and
+// days, and the key to continue from.
+func (m *memoryTable) page(caller string, values, start map[string]types.AttributeValue) ([]map[string]types.AttributeValue, map[string]types.AttributeValue) {
+	from, to := stringAttr(values, ":from"), stringAttr(values, ":to")
+	i := 0
+	if start != nil {
+		for i < len(m.keys) && m.keys[i] != [2]string{stringAttr(start, "callerId"), stringAttr(start, "day")} {
+			i++
+		}
+		i++
+	}
+	for ; i < len(m.keys); i++ {
+		item := m.items[m.keys[i]]
+		day := stringAttr(item, "day")
+		if (caller == "" || stringAttr(item, "callerId") == caller) && day >= from && day <= to {
+			var next map[string]types.AttributeValue
+			if i < len(m.keys)-1 {
+				next = map[string]types.AttributeValue{"callerId": item["callerId"], "day": item["day"]}
+			}
+			return []map[string]types.AttributeValue{item}, next
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTable) Query(_ context.Context, in *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	items, next := m.page(stringAttr(in.ExpressionAttributeValues, ":caller"), in.ExpressionAttributeValues, in.ExclusiveStartKey)
+	return &dynamodb.QueryOutput{Items: items, LastEvaluatedKey: next}, nil
+}
+
+func (m *memoryTable) Scan(_ context.Context, in *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	items, next := m.page("", in.ExpressionAttributeValues, in.ExclusiveStartKey)
+	return &dynamodb.ScanOutput{Items: items, LastEvaluatedKey: next}, nil
+}
+
+func TestLedger(t *testing.T) {
+	ctx := context.Background()
+	l := New(newMemoryTable(), "usage")
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	record := func(caller string, chars int64) {
+		t.Helper()
+		if err := l.Record(ctx, Usage{CallerID: caller, Requests: 1, Texts: 2, Characters: chars, Tokens: chars / 4}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	record("search", 400)
+	record("catalog", 100)
+	record("catalog", 200)
+	now = now.Add(2 * time.Hour)
+	record("catalog", 40)
+
+	got, err := l.Query(ctx, "catalog", "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want := []Usage{
+		{CallerID: "catalog", Day: "2026-03-01", Requests: 2, Texts: 4, Characters: 300, Tokens: 75},
+		{CallerID: "catalog", Day: "2026-03-02", Requests: 1, Texts: 2, Characters: 40, Tokens: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Query(catalog) = %+v, want %+v", got, want)
+	}
+
+	got, err = l.Query(ctx, "", "2026-03-01", "2026-03-01")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	want = []Usage{
+		{CallerID: "catalog", Day: "2026-03-01", Requests: 2, Texts: 4, Characters: 300, Tokens: 75},
+		{CallerID: "search", Day: "2026-03-01", Requests: 1, Texts: 2, Characters: 400, Tokens: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Query(all callers) = %+v, want %+v", got, want)
+	}
+}
+
+func TestCallerPattern(t *testing.T) {
+	tests := []struct {
+		caller string
+		want   bool
+	}{
+		{"catalog-sync", true},
+		{"team_a.search", true},
+		{"", false},
+		{"has space", false},
+		{strings.Repeat("a", 65), false},
+	}
+
+	for _, tt := range tests {
+		if got := CallerPattern.MatchString(tt.caller); got != tt.want {
+			t.Errorf("CallerPattern.MatchString(%q) = %v, want %v", tt.caller, got, tt.want)
+		}
+	}
+}
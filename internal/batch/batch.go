@@ -0,0 +1,289 @@
+// Package batch translates JSONL files in S3 too large for a request
+// payload, such as a nightly catalog sync.
+//
+// The input is read as a stream and translated in parts of PartSize lines;
+// every part is written as its own JSONL object under the output prefix,
+// and the progress of the job is saved to DynamoDB after each part. A run
+// stops before its invocation deadline and a later run of the same job
+// resumes from the saved progress, so a file may take several invocations.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// PartSize is the number of input lines translated and written together.
+const PartSize = 500
+
+// MaxLineSize bounds an input line.
+const MaxLineSize = 1 << 20
+
+// pauseMargin is kept free before the invocation deadline on top of the
+// time of the slowest part so far, to save progress and respond.
+const pauseMargin = 5 * time.Second
+
+// ErrPaused is returned by Run when it stopped before the invocation
+// deadline; the job should be run again to resume.
+var ErrPaused = errors.New("batch paused before the invocation deadline")
+
+// Objects is the subset of the S3 client used to read and write batches.
+type Objects interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Location is an S3 object or prefix.
+type Location struct {
+	Bucket string
+	Key    string
+}
+
+// ParseURI parses an s3://bucket/key URI.
+func ParseURI(uri string) (Location, error) {
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok {
+		return Location{}, fmt.Errorf("%q is not an s3:// URI", uri)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return Location{}, fmt.Errorf("%q has no bucket", uri)
+	}
+	return Location{Bucket: bucket, Key: key}, nil
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("s3://%s/%s", l.Bucket, l.Key)
+}
+
+// Line is an input line: a JSON string, or an object with the text and an
+// optional caller ID copied to its output line.
+type Line struct {
+	ID   string `json:"id,omitempty"`
+	Text string `json:"text"`
+}
+
+// parseLine decodes an input line.
+func parseLine(data []byte) (Line, error) {
+	var line Line
+	if data[0] == '"' {
+		err := json.Unmarshal(data, &line.Text)
+		return line, err
+	}
+	err := json.Unmarshal(data, &line)
+	return line, err
+}
+
+// Output is an output line. Index counts the non-blank input lines from 0.
+type Output struct {
+	Index       int    `json:"index"`
+	ID          string `json:"id,omitempty"`
+	Translation string `json:"translation"`
+}
+
+// Job is a batch to translate.
+type Job struct {
+	ID     string
+	Input  Location
+	Output Location
+}
+
+// PartKey returns the output key of part n.
+func (j Job) PartKey(n int) string {
+	return fmt.Sprintf("%spart-%05d.jsonl", j.Output.Key, n)
+}
+
+// Translator translates the texts of one part, in order.
+type Translator func(ctx context.Context, texts []string) ([]string, error)
+
+// Runner runs batch jobs.
+type Runner struct {
+	objects  Objects
+	progress *ProgressStore
+	now      func() time.Time
+}
+
+// NewRunner creates a Runner reading and writing objects and saving
+// progress to progress.
+func NewRunner(objects Objects, progress *ProgressStore) *Runner {
+	return &Runner{objects: objects, progress: progress, now: time.Now}
+}
+
+// Progress returns the saved progress of a job, or nil before it started.
+func (r *Runner) Progress(ctx context.Context, id string) (*Progress, error) {
+	return r.progress.Get(ctx, id)
+}
+
+// Run translates the parts of job not translated yet. It returns the
+// progress when the input is done, ErrPaused when the deadline of ctx is
+// too close for another part, and the error of a failed part otherwise;
+// the progress saved up to then stays valid for a retry.
+func (r *Runner) Run(ctx context.Context, job Job, translate Translator) (*Progress, error) {
+	p, err := r.progress.Get(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &Progress{JobID: job.ID, Input: job.Input.String(), Output: job.Output.String()}
+	}
+	if p.Status == StatusCompleted {
+		return p, nil
+	}
+	p.Status, p.Error = StatusRunning, ""
+
+	body, err := r.open(ctx, job.Input, p)
+	if err != nil {
+		return p, r.fail(ctx, p, err)
+	}
+	defer body.Close()
+	input := bufio.NewReaderSize(body, 64<<10)
+
+	var slowest time.Duration
+	for {
+		if r.pausing(ctx, slowest) {
+			return p, errors.Join(ErrPaused, r.save(ctx, p))
+		}
+		start := r.now()
+		lines, consumed, err := readPart(input)
+		if err != nil {
+			return p, r.fail(ctx, p, err)
+		}
+		if len(lines) == 0 {
+			break
+		}
+		if err := r.translatePart(ctx, job, p, lines, translate); err != nil {
+			return p, errors.Join(err, r.save(ctx, p))
+		}
+		p.Bytes += consumed
+		if err := r.save(ctx, p); err != nil {
+			return p, err
+		}
+		slowest = max(slowest, r.now().Sub(start))
+	}
+	p.Status = StatusCompleted
+	return p, r.save(ctx, p)
+}
+
+// Fail records that job failed for good with err.
+func (r *Runner) Fail(ctx context.Context, p *Progress, err error) error {
+	p.Status, p.Error = StatusFailed, err.Error()
+	return r.save(ctx, p)
+}
+
+// fail records a failure to read the input, which retries cannot fix.
+func (r *Runner) fail(ctx context.Context, p *Progress, err error) error {
+	if saveErr := r.Fail(ctx, p, err); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+// open opens the input at the offset p has reached.
+func (r *Runner) open(ctx context.Context, input Location, p *Progress) (io.ReadCloser, error) {
+	if p.Size > 0 && p.Bytes >= p.Size {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	params := &s3.GetObjectInput{Bucket: &input.Bucket, Key: &input.Key}
+	if p.Bytes > 0 {
+		rng := fmt.Sprintf("bytes=%d-", p.Bytes)
+		params.Range = &rng
+	}
+	out, err := r.objects.GetObject(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	if p.Bytes == 0 && out.ContentLength != nil {
+		p.Size = *out.ContentLength
+	}
+	return out.Body, nil
+}
+
+// pausing reports whether the deadline of ctx is too close for a part as
+// slow as the slowest so far.
+func (r *Runner) pausing(ctx context.Context, slowest time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && deadline.Sub(r.now()) < slowest+pauseMargin
+}
+
+// readPart reads up to PartSize non-blank lines and the number of input
+// bytes they took. It returns no lines at the end of the input.
+func readPart(input *bufio.Reader) ([]Line, int64, error) {
+	var lines []Line
+	var consumed int64
+	for len(lines) < PartSize {
+		data, err := input.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(data) == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, fmt.Errorf("failed to read input: %w", err)
+		}
+		if len(data) > MaxLineSize {
+			return nil, 0, fmt.Errorf("input line %d is longer than %d bytes", len(lines)+1, MaxLineSize)
+		}
+		consumed += int64(len(data))
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		line, err := parseLine(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid input line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, consumed, nil
+}
+
+// translatePart translates lines and writes them as the next part of job.
+func (r *Runner) translatePart(ctx context.Context, job Job, p *Progress, lines []Line, translate Translator) error {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	translations, err := translate(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(translations) != len(texts) {
+		return fmt.Errorf("got %d translations for %d texts", len(translations), len(texts))
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	for i, line := range lines {
+		if err := enc.Encode(Output{Index: p.Lines + i, ID: line.ID, Translation: translations[i]}); err != nil {
+			return err
+		}
+	}
+	key := job.PartKey(p.Parts)
+	contentType := "application/x-ndjson"
+	_, err = r.objects.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &job.Output.Bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(out.Bytes()),
+		ContentType: &contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write s3://%s/%s: %w", job.Output.Bucket, key, err)
+	}
+	p.Lines += len(lines)
+	p.Parts++
+	return nil
+}
+
+// save writes p with the current time.
+func (r *Runner) save(ctx context.Context, p *Progress) error {
+	p.UpdatedAt = r.now().UTC()
+	return r.progress.Put(ctx, p)
+}
@@ -0,0 +1,233 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// memoryObjects is an in-memory S3 keyed by "bucket/key" that honours
+// "bytes=N-" ranges.
+type memoryObjects map[string][]byte
+
+func (m memoryObjects) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	m[*params.Bucket+"/"+*params.Key] = data
+	return &s3.PutObjectOutput{}, err
+}
+
+func (m memoryObjects) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := m[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	size := int64(len(data))
+	if params.Range != nil {
+		from, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(*params.Range, "bytes="), "-"))
+		if err != nil {
+			return nil, err
+		}
+		data = data[from:]
+	}
+	length := int64(len(data))
+	if params.Range == nil {
+		length = size
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data)), ContentLength: &length}, nil
+}
+
+// memoryTable is an in-memory progress table keyed by "id".
+type memoryTable map[string]map[string]types.AttributeValue
+
+func (m memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	id := in.Key["id"].(*types.AttributeValueMemberS).Value
+	return &dynamodb.GetItemOutput{Item: m[id]}, nil
+}
+
+func (m memoryTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m[in.Item["id"].(*types.AttributeValueMemberS).Value] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// upper translates texts to upper case.
+func upper(_ context.Context, texts []string) ([]string, error) {
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		out[i] = strings.ToUpper(text)
+	}
+	return out, nil
+}
+
+// inputFile returns n input lines alternating between strings and objects
+// with IDs, with a blank line after the first.
+func inputFile(n int) []byte {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "%q\n", fmt.Sprintf("text %d", i))
+		} else {
+			fmt.Fprintf(&b, `{"id": "sku-%d", "text": "text %d"}`+"\n", i, i)
+		}
+		if i == 0 {
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// outputLines reads the output parts of job.
+func outputLines(t *testing.T, objects memoryObjects, job Job, parts int) []Output {
+	t.Helper()
+	var out []Output
+	for n := 0; n < parts; n++ {
+		data, ok := objects[job.Output.Bucket+"/"+job.PartKey(n)]
+		if !ok {
+			t.Fatalf("part %d was not written", n)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var line Output
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func newTestRunner(input []byte) (*Runner, memoryObjects, Job) {
+	objects := memoryObjects{"in/catalog.jsonl": input}
+	job := Job{ID: "job-1", Input: Location{Bucket: "in", Key: "catalog.jsonl"}, Output: Location{Bucket: "out", Key: "sync/"}}
+	return NewRunner(objects, NewProgressStore(memoryTable{}, "progress")), objects, job
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    Location
+		wantErr bool
+	}{
+		{"s3://bucket/path/in.jsonl", Location{Bucket: "bucket", Key: "path/in.jsonl"}, false},
+		{"s3://bucket/", Location{Bucket: "bucket"}, false},
+		{"s3://bucket", Location{Bucket: "bucket"}, false},
+		{"s3:///key", Location{}, true},
+		{"https://bucket/key", Location{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := ParseURI(tt.uri)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseURI() = %+v, %v, want %+v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	r, objects, job := newTestRunner(inputFile(2*PartSize + 10))
+
+	p, err := r.Run(context.Background(), job, upper)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if p.Status != StatusCompleted || p.Lines != 2*PartSize+10 || p.Parts != 3 || p.Bytes != p.Size {
+		t.Errorf("Run() progress = %+v", p)
+	}
+	out := outputLines(t, objects, job, p.Parts)
+	if len(out) != p.Lines {
+		t.Fatalf("output has %d lines, want %d", len(out), p.Lines)
+	}
+	for i, line := range out {
+		if line.Index != i || line.Translation != fmt.Sprintf("TEXT %d", i) {
+			t.Fatalf("output line %d = %+v", i, line)
+		}
+	}
+	if out[1].ID != "sku-1" || out[0].ID != "" {
+		t.Errorf("output IDs = %q, %q, want \"\", \"sku-1\"", out[0].ID, out[1].ID)
+	}
+
+	saved, err := r.Progress(context.Background(), job.ID)
+	if err != nil || saved.Status != StatusCompleted || saved.Lines != p.Lines || saved.Input != "s3://in/catalog.jsonl" {
+		t.Errorf("Progress() = %+v, %v", saved, err)
+	}
+}
+
+func TestRunner_Resume(t *testing.T) {
+	r, objects, job := newTestRunner(inputFile(3 * PartSize))
+	ctx := context.Background()
+
+	calls := 0
+	flaky := func(ctx context.Context, texts []string) ([]string, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("throttled")
+		}
+		return upper(ctx, texts)
+	}
+	p, err := r.Run(ctx, job, flaky)
+	if err == nil || p.Status != StatusRunning || p.Parts != 1 || p.Lines != PartSize {
+		t.Fatalf("Run() with a failing part = %+v, %v", p, err)
+	}
+
+	p, err = r.Run(ctx, job, flaky)
+	if err != nil || p.Status != StatusCompleted || p.Parts != 3 {
+		t.Fatalf("Run() resumed = %+v, %v", p, err)
+	}
+	if calls != 4 {
+		t.Errorf("translator called %d times, want 4 (one failed part retried)", calls)
+	}
+	out := outputLines(t, objects, job, p.Parts)
+	if len(out) != 3*PartSize || out[PartSize].Index != PartSize || out[PartSize].Translation != fmt.Sprintf("TEXT %d", PartSize) {
+		t.Errorf("resumed output = %d lines, line %d = %+v", len(out), PartSize, out[PartSize])
+	}
+
+	// A completed job is not run again
+	if p, err := r.Run(ctx, job, flaky); err != nil || p.Status != StatusCompleted || calls != 4 {
+		t.Errorf("Run() completed job = %+v, %v, %d calls", p, err, calls)
+	}
+}
+
+func TestRunner_Pause(t *testing.T) {
+	r, _, job := newTestRunner(inputFile(2 * PartSize))
+	ctx, cancel := context.WithTimeout(context.Background(), pauseMargin/2)
+	defer cancel()
+
+	p, err := r.Run(ctx, job, upper)
+	if !errors.Is(err, ErrPaused) || p.Status != StatusRunning || p.Parts != 0 {
+		t.Fatalf("Run() near the deadline = %+v, %v, want ErrPaused", p, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if p, err := r.Run(ctx, job, upper); err != nil || p.Status != StatusCompleted || p.Parts != 2 {
+		t.Errorf("Run() resumed = %+v, %v", p, err)
+	}
+}
+
+func TestRunner_InvalidInput(t *testing.T) {
+	r, _, job := newTestRunner([]byte("\"ok\"\nnot json\n"))
+
+	p, err := r.Run(context.Background(), job, upper)
+	if err == nil || p.Status != StatusFailed || p.Error == "" {
+		t.Errorf("Run() invalid input = %+v, %v, want failed", p, err)
+	}
+	job.Input.Key = "missing.jsonl"
+	job.ID = "job-2"
+	if p, err := r.Run(context.Background(), job, upper); err == nil || p.Status != StatusFailed {
+		t.Errorf("Run() missing input = %+v, %v, want failed", p, err)
+	}
+}
@@ -0,0 +1,140 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ProgressTableEnv names the DynamoDB table holding batch progress.
+const ProgressTableEnv = "BATCH_PROGRESS_TABLE"
+
+// Retention is how long progress items are kept.
+const Retention = 14 * 24 * time.Hour
+
+// Batch statuses.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Progress is how far a batch job got.
+type Progress struct {
+	JobID  string `json:"-"`
+	Status string `json:"status"`
+	// Input and Output are the input object and output prefix URIs.
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	// Lines and Parts count the input lines translated and the output
+	// parts written.
+	Lines int `json:"lines"`
+	Parts int `json:"parts"`
+	// Bytes of Size input bytes are done; a resumed run reads from Bytes.
+	Bytes     int64     `json:"bytes"`
+	Size      int64     `json:"size,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Table is the subset of the DynamoDB client used by the progress store.
+type Table interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// ProgressStore keeps batch progress in a DynamoDB table keyed by the
+// string attribute "id", the job ID. Items expire through the "expiresAt"
+// TTL attribute after Retention.
+type ProgressStore struct {
+	table Table
+	name  string
+}
+
+// NewProgressStore creates a ProgressStore for the named table.
+func NewProgressStore(table Table, name string) *ProgressStore {
+	return &ProgressStore{table: table, name: name}
+}
+
+// Get returns the progress of a job, or nil when it has none.
+func (s *ProgressStore) Get(ctx context.Context, id string) (*Progress, error) {
+	consistent := true
+	out, err := s.table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.name,
+		Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+		ConsistentRead: &consistent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress of batch %s: %w", id, err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	return fromItem(out.Item)
+}
+
+// Put saves p. Only the worker holding the job writes its progress.
+func (s *ProgressStore) Put(ctx context.Context, p *Progress) error {
+	_, err := s.table.PutItem(ctx, &dynamodb.PutItemInput{TableName: &s.name, Item: item(p)})
+	if err != nil {
+		return fmt.Errorf("failed to write progress of batch %s: %w", p.JobID, err)
+	}
+	return nil
+}
+
+// item converts progress to a DynamoDB item. Times are Unix seconds.
+func item(p *Progress) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"id":        &types.AttributeValueMemberS{Value: p.JobID},
+		"status":    &types.AttributeValueMemberS{Value: p.Status},
+		"input":     &types.AttributeValueMemberS{Value: p.Input},
+		"output":    &types.AttributeValueMemberS{Value: p.Output},
+		"lines":     number(int64(p.Lines)),
+		"parts":     number(int64(p.Parts)),
+		"bytes":     number(p.Bytes),
+		"size":      number(p.Size),
+		"updatedAt": number(p.UpdatedAt.Unix()),
+		"expiresAt": number(p.UpdatedAt.Add(Retention).Unix()),
+	}
+	if p.Error != "" {
+		item["error"] = &types.AttributeValueMemberS{Value: p.Error}
+	}
+	return item
+}
+
+// fromItem converts a DynamoDB item to progress.
+func fromItem(item map[string]types.AttributeValue) (*Progress, error) {
+	p := &Progress{}
+	texts := map[string]*string{"id": &p.JobID, "status": &p.Status, "input": &p.Input, "output": &p.Output, "error": &p.Error}
+	for name, dst := range texts {
+		if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+			*dst = v.Value
+		}
+	}
+	var lines, parts, updated int64
+	numbers := map[string]*int64{"lines": &lines, "parts": &parts, "bytes": &p.Bytes, "size": &p.Size, "updatedAt": &updated}
+	for name, dst := range numbers {
+		v, ok := item[name].(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("batch progress attribute %s: %w", name, err)
+		}
+		*dst = n
+	}
+	p.Lines, p.Parts = int(lines), int(parts)
+	if updated != 0 {
+		p.UpdatedAt = time.Unix(updated, 0).UTC()
+	}
+	return p, nil
+}
+
+func number(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
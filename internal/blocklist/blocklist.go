@@ -0,0 +1,158 @@
+// Package blocklist enforces per-tenant lists of terms that must never
+// appear in published translations (legal and compliance wording).
+package blocklist
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ConfigEnv names the environment variable holding the blocklists as JSON
+// (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "BLOCKLIST"
+
+// AnyLanguage lists terms forbidden in every target language.
+const AnyLanguage = "*"
+
+// Action is what happens to a translation containing a forbidden term.
+type Action string
+
+// Supported actions.
+const (
+	// Replace substitutes every forbidden term with the policy replacement.
+	Replace Action = "replace"
+	// Reject withholds the translation.
+	Reject Action = "reject"
+	// Review keeps the translation but flags it for the review queue.
+	Review Action = "review"
+)
+
+// DefaultReplacement is used by Replace when the policy sets none.
+const DefaultReplacement = "***"
+
+// Policy is a tenant's forbidden-term configuration.
+type Policy struct {
+	Action      Action `json:"action"`
+	Replacement string `json:"replacement,omitempty"`
+	// Terms maps a target language (or "*") to its forbidden terms.
+	Terms map[string][]string `json:"terms"`
+}
+
+// Match reports the forbidden terms found in one translation.
+type Match struct {
+	Index  int      `json:"index"`
+	Terms  []string `json:"terms"`
+	Action Action   `json:"action"`
+}
+
+// List holds compiled policies by tenant ID.
+type List struct {
+	tenants map[string]*compiled
+}
+
+type compiled struct {
+	policy   Policy
+	patterns map[string][]*regexp.Regexp // by target language
+}
+
+// New compiles tenant policies into a List.
+func New(policies map[string]Policy) (*List, error) {
+	list := &List{tenants: map[string]*compiled{}}
+	for tenant, policy := range policies {
+		switch policy.Action {
+		case Replace, Reject, Review:
+		default:
+			return nil, fmt.Errorf("tenant %q: unknown action %q", tenant, policy.Action)
+		}
+		if policy.Action == Replace && policy.Replacement == "" {
+			policy.Replacement = DefaultReplacement
+		}
+
+		c := &compiled{policy: policy, patterns: map[string][]*regexp.Regexp{}}
+		for lang, terms := range policy.Terms {
+			for _, term := range terms {
+				c.patterns[lang] = append(c.patterns[lang], termPattern(term))
+			}
+		}
+		list.tenants[tenant] = c
+	}
+	return list, nil
+}
+
+// Load builds a List from the BLOCKLIST config. Without config it returns
+// an empty List.
+func Load() (*List, error) {
+	var policies map[string]Policy
+	if _, err := config.LoadJSON(ConfigEnv, &policies); err != nil {
+		return nil, err
+	}
+	return New(policies)
+}
+
+// termPattern matches a term case-insensitively on word boundaries.
+func termPattern(term string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN])(` + regexp.QuoteMeta(term) + `)($|[^\pL\pN])`)
+}
+
+// Apply checks translations into target for the tenant's forbidden terms,
+// applies the tenant's action in place and returns one Match per affected
+// translation. Tenants without a policy are not checked.
+func (l *List) Apply(tenant, target string, translations []string) []Match {
+	if l == nil {
+		return nil
+	}
+	c, ok := l.tenants[tenant]
+	if !ok {
+		return nil
+	}
+
+	var patterns []*regexp.Regexp
+	patterns = append(patterns, c.patterns[AnyLanguage]...)
+	patterns = append(patterns, c.patterns[target]...)
+	if base, _, found := strings.Cut(target, "_"); found {
+		patterns = append(patterns, c.patterns[base]...)
+	}
+
+	var matches []Match
+	for i, text := range translations {
+		found := map[string]bool{}
+		for _, re := range patterns {
+			for _, m := range re.FindAllStringSubmatch(text, -1) {
+				found[strings.ToLower(m[2])] = true
+			}
+			if c.policy.Action == Replace {
+				text = re.ReplaceAllString(text, "${1}"+escapeReplacement(c.policy.Replacement)+"${3}")
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		switch c.policy.Action {
+		case Replace:
+			translations[i] = text
+		case Reject:
+			translations[i] = ""
+		}
+		matches = append(matches, Match{Index: i, Terms: sortedKeys(found), Action: c.policy.Action})
+	}
+	return matches
+}
+
+// escapeReplacement escapes "$" so replacements are inserted literally.
+func escapeReplacement(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
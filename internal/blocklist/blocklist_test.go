@@ -0,0 +1,116 @@
+package blocklist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestList(t *testing.T) *List {
+	t.Helper()
+	list, err := New(map[string]Policy{
+		"replacer": {Action: Replace, Terms: map[string][]string{
+			"fr":        {"garantie à vie"},
+			AnyLanguage: {"100% safe"},
+		}},
+		"rejecter": {Action: Reject, Terms: map[string][]string{"fr": {"miracle"}}},
+		"reviewer": {Action: Review, Terms: map[string][]string{"fr": {"miracle"}}},
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return list
+}
+
+func TestList_Apply(t *testing.T) {
+	list := newTestList(t)
+
+	tests := []struct {
+		name        string
+		tenant      string
+		target      string
+		texts       []string
+		want        []string
+		wantMatches []Match
+	}{
+		{
+			name:   "replace",
+			tenant: "replacer",
+			target: "fr",
+			texts:  []string{"Avec Garantie à vie, 100% safe.", "Sac en cuir"},
+			want:   []string{"Avec ***, ***.", "Sac en cuir"},
+			wantMatches: []Match{
+				{Index: 0, Terms: []string{"100% safe", "garantie à vie"}, Action: Replace},
+			},
+		},
+		{
+			name:        "base language terms apply to variants",
+			tenant:      "replacer",
+			target:      "fr_CA",
+			texts:       []string{"garantie à vie"},
+			want:        []string{"***"},
+			wantMatches: []Match{{Index: 0, Terms: []string{"garantie à vie"}, Action: Replace}},
+		},
+		{
+			name:        "word boundaries",
+			tenant:      "rejecter",
+			target:      "fr",
+			texts:       []string{"miracles", "Un produit miracle !"},
+			want:        []string{"miracles", ""},
+			wantMatches: []Match{{Index: 1, Terms: []string{"miracle"}, Action: Reject}},
+		},
+		{
+			name:        "review keeps translation",
+			tenant:      "reviewer",
+			target:      "fr",
+			texts:       []string{"miracle"},
+			want:        []string{"miracle"},
+			wantMatches: []Match{{Index: 0, Terms: []string{"miracle"}, Action: Review}},
+		},
+		{
+			name:   "unknown tenant",
+			tenant: "other",
+			target: "fr",
+			texts:  []string{"miracle"},
+			want:   []string{"miracle"},
+		},
+		{
+			name:   "other language",
+			tenant: "rejecter",
+			target: "es",
+			texts:  []string{"miracle"},
+			want:   []string{"miracle"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := list.Apply(tt.tenant, tt.target, tt.texts)
+			if !reflect.DeepEqual(tt.texts, tt.want) {
+				t.Errorf("Apply() texts = %q, want %q", tt.texts, tt.want)
+			}
+			if !reflect.DeepEqual(matches, tt.wantMatches) {
+				t.Errorf("Apply() matches = %+v, want %+v", matches, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestNew_UnknownAction(t *testing.T) {
+	if _, err := New(map[string]Policy{"t": {Action: "delete"}}); err == nil {
+		t.Error("New() should reject unknown actions")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(ConfigEnv, `{"acme": {"action": "replace", "replacement": "[removed]", "terms": {"*": ["cure"]}}}`)
+
+	list, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	texts := []string{"A cure for $5"}
+	list.Apply("acme", "en", texts)
+	if texts[0] != "A [removed] for $5" {
+		t.Errorf("Apply() = %q", texts[0])
+	}
+}
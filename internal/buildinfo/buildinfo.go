@@ -0,0 +1,54 @@
+// Package buildinfo describes the running binary: the commit it was built
+// from and when. The Makefile stamps both with -ldflags; otherwise they come
+// from the version control information the Go toolchain embeds.
+package buildinfo
+
+import (
+	"runtime/debug"
+)
+
+// Commit and Time are set at build time with
+// -ldflags "-X github.com/pricofy/translation-manager/internal/buildinfo.Commit=..."
+// (Time in RFC 3339).
+var (
+	Commit string
+	Time   string
+)
+
+// Info is the build metadata of the binary.
+type Info struct {
+	// Commit is the git SHA built, "unknown" without stamp or VCS info.
+	Commit string `json:"commit"`
+	// Time is when the binary was built, or the commit time from the VCS
+	// information.
+	Time string `json:"time,omitempty"`
+	// Modified reports uncommitted changes in the build tree.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// readBuildInfo is replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// Get returns the build metadata, preferring the values stamped by the
+// Makefile.
+func Get() Info {
+	info := Info{Commit: Commit, Time: Time}
+	if bi, ok := readBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Time == "":
+				info.Time = s.Value
+			case s.Key == "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
@@ -0,0 +1,37 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	vcs := &debug.BuildInfo{GoVersion: "go1.21.5", Settings: []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0fd5c3b"},
+		{Key: "vcs.time", Value: "2026-10-01T08:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}}
+
+	tests := []struct {
+		name         string
+		commit, time string
+		build        *debug.BuildInfo
+		want         Info
+	}{
+		{"stamped", "931f45d", "2026-10-02T09:30:00Z", vcs, Info{Commit: "931f45d", Time: "2026-10-02T09:30:00Z", Modified: true, GoVersion: "go1.21.5"}},
+		{"from vcs", "", "", vcs, Info{Commit: "0fd5c3b", Time: "2026-10-01T08:00:00Z", Modified: true, GoVersion: "go1.21.5"}},
+		{"no information", "", "", nil, Info{Commit: "unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Commit, Time = tt.commit, tt.time
+			readBuildInfo = func() (*debug.BuildInfo, bool) { return tt.build, tt.build != nil }
+			t.Cleanup(func() { Commit, Time, readBuildInfo = "", "", debug.ReadBuildInfo })
+
+			if got := Get(); got != tt.want {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
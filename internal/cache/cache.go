@@ -0,0 +1,222 @@
+// Package cache is a translation memory in DynamoDB. It remembers what the
+// translators returned for a text so repeated texts (most product titles
+// of a catalog) are answered without invoking them again.
+//
+// The table is keyed by the string attribute "key", a SHA-256 hash of the
+// language pair and the text. Items carry the "translation", an optional
+// "score" (the route score of router.Result.Scores), the optional
+// "modelVersion" and "translatedAt" (Unix seconds) of the translation, and
+// expire through the "expiresAt" TTL attribute.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table holding the translation memory.
+const TableEnv = "TRANSLATION_CACHE_TABLE"
+
+// TTLEnv sets how long translations are kept (Go duration, default DefaultTTL).
+const TTLEnv = "TRANSLATION_CACHE_TTL"
+
+// DefaultTTL is how long translations are kept by default, so model
+// upgrades reach repeated texts within a month.
+const DefaultTTL = 30 * 24 * time.Hour
+
+// DynamoDB batch limits.
+const (
+	maxBatchGet   = 100
+	maxBatchWrite = 25
+)
+
+// Table is the subset of the DynamoDB client used by the cache.
+type Table interface {
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// Entry is a remembered translation.
+type Entry struct {
+	Translation string
+	// Score is the log-probability of the translation, nil when the
+	// translators did not return one.
+	Score *float64
+	// ModelVersion is the model version of the route that made the
+	// translation, "" if unknown.
+	ModelVersion string
+	// TranslatedAt is when the translators made it, zero for items cached
+	// before it was recorded.
+	TranslatedAt time.Time
+}
+
+// Cache reads and writes the translation memory.
+type Cache struct {
+	table Table
+	name  string
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// New creates a Cache of the named table keeping translations for ttl.
+func New(table Table, name string, ttl time.Duration) *Cache {
+	return &Cache{table: table, name: name, ttl: ttl, now: time.Now}
+}
+
+// TTL reads TTLEnv.
+func TTL() (time.Duration, error) {
+	v := os.Getenv(TTLEnv)
+	if v == "" {
+		return DefaultTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a positive duration such as 720h", TTLEnv, v)
+	}
+	return d, nil
+}
+
+// Key returns the item key of a text translated from source to target.
+func Key(source, target, text string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + target + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the remembered translations of texts by text. Texts that are
+// missing, expired or left unprocessed by DynamoDB are absent.
+func (c *Cache) Get(ctx context.Context, source, target string, texts []string) (map[string]Entry, error) {
+	found, _, err := c.Lookup(ctx, source, target, texts)
+	return found, err
+}
+
+// Lookup is Get that also reports the texts whose translation has expired
+// but was not yet deleted by DynamoDB, which are stale rather than missing.
+func (c *Cache) Lookup(ctx context.Context, source, target string, texts []string) (found map[string]Entry, stale map[string]bool, err error) {
+	byKey := map[string]string{}
+	keys := make([]string, 0, len(texts))
+	for _, text := range texts {
+		k := Key(source, target, text)
+		if _, ok := byKey[k]; !ok {
+			byKey[k] = text
+			keys = append(keys, k)
+		}
+	}
+
+	found, stale = map[string]Entry{}, map[string]bool{}
+	now := c.now().Unix()
+	for start := 0; start < len(keys); start += maxBatchGet {
+		batch := keys[start:min(start+maxBatchGet, len(keys))]
+		requestKeys := make([]map[string]types.AttributeValue, len(batch))
+		for i, k := range batch {
+			requestKeys[i] = map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: k}}
+		}
+		out, err := c.table.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{c.name: {Keys: requestKeys}},
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read translation cache: %w", err)
+		}
+		for _, item := range out.Responses[c.name] {
+			k, e, expires, ok := fromItem(item)
+			// DynamoDB deletes expired items lazily, so check the TTL here
+			switch {
+			case !ok:
+			case expires > now:
+				found[byKey[k]] = e
+			default:
+				stale[byKey[k]] = true
+			}
+		}
+	}
+	return found, stale, nil
+}
+
+// Put remembers the translations of texts. Items DynamoDB leaves
+// unprocessed are dropped; they are written again the next time.
+func (c *Cache) Put(ctx context.Context, source, target string, texts []string, entries []Entry) error {
+	if len(texts) != len(entries) {
+		return fmt.Errorf("%d cache entries for %d texts", len(entries), len(texts))
+	}
+	expires := c.now().Add(c.ttl).Unix()
+	seen := map[string]bool{}
+	var writes []types.WriteRequest
+	for i, text := range texts {
+		k := Key(source, target, text)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: item(k, entries[i], expires)}})
+	}
+
+	for start := 0; start < len(writes); start += maxBatchWrite {
+		_, err := c.table.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{c.name: writes[start:min(start+maxBatchWrite, len(writes))]},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write translation cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// item converts an entry to a DynamoDB item.
+func item(key string, e Entry, expires int64) map[string]types.AttributeValue {
+	it := map[string]types.AttributeValue{
+		"key":         &types.AttributeValueMemberS{Value: key},
+		"translation": &types.AttributeValueMemberS{Value: e.Translation},
+		"expiresAt":   &types.AttributeValueMemberN{Value: strconv.FormatInt(expires, 10)},
+	}
+	if e.Score != nil {
+		it["score"] = &types.AttributeValueMemberN{Value: strconv.FormatFloat(*e.Score, 'g', -1, 64)}
+	}
+	if e.ModelVersion != "" {
+		it["modelVersion"] = &types.AttributeValueMemberS{Value: e.ModelVersion}
+	}
+	if !e.TranslatedAt.IsZero() {
+		it["translatedAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(e.TranslatedAt.Unix(), 10)}
+	}
+	return it
+}
+
+// fromItem converts a DynamoDB item to its key, entry and expiry. ok is
+// false for malformed items.
+func fromItem(it map[string]types.AttributeValue) (key string, e Entry, expires int64, ok bool) {
+	k, okKey := it["key"].(*types.AttributeValueMemberS)
+	t, okText := it["translation"].(*types.AttributeValueMemberS)
+	exp, okExp := it["expiresAt"].(*types.AttributeValueMemberN)
+	if !okKey || !okText || !okExp {
+		return "", Entry{}, 0, false
+	}
+	expires, err := strconv.ParseInt(exp.Value, 10, 64)
+	if err != nil {
+		return "", Entry{}, 0, false
+	}
+	e.Translation = t.Value
+	if s, okScore := it["score"].(*types.AttributeValueMemberN); okScore {
+		score, err := strconv.ParseFloat(s.Value, 64)
+		if err != nil {
+			return "", Entry{}, 0, false
+		}
+		e.Score = &score
+	}
+	if v, okVersion := it["modelVersion"].(*types.AttributeValueMemberS); okVersion {
+		e.ModelVersion = v.Value
+	}
+	if at, okAt := it["translatedAt"].(*types.AttributeValueMemberN); okAt {
+		sec, err := strconv.ParseInt(at.Value, 10, 64)
+		if err != nil {
+			return "", Entry{}, 0, false
+		}
+		e.TranslatedAt = time.Unix(sec, 0).UTC()
+	}
+	return k.Value, e, expires, true
+}
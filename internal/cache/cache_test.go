@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "key" that records its calls.
+type memoryTable struct {
+	items  map[string]map[string]types.AttributeValue
+	gets   int
+	writes int
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func (m *memoryTable) BatchGetItem(_ context.Context, in *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	m.gets++
+	out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+	for name, req := range in.RequestItems {
+		if len(req.Keys) > maxBatchGet {
+			return nil, fmt.Errorf("%d keys in one batch", len(req.Keys))
+		}
+		seen := map[string]bool{}
+		for _, key := range req.Keys {
+			k := key["key"].(*types.AttributeValueMemberS).Value
+			if seen[k] {
+				return nil, fmt.Errorf("duplicate key %s", k)
+			}
+			seen[k] = true
+			if item, ok := m.items[k]; ok {
+				out.Responses[name] = append(out.Responses[name], item)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryTable) BatchWriteItem(_ context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	m.writes++
+	for _, writes := range in.RequestItems {
+		if len(writes) > maxBatchWrite {
+			return nil, fmt.Errorf("%d writes in one batch", len(writes))
+		}
+		for _, w := range writes {
+			k := w.PutRequest.Item["key"].(*types.AttributeValueMemberS).Value
+			m.items[k] = w.PutRequest.Item
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestCache_PutGet(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	table := newMemoryTable()
+	c := New(table, "cache", time.Hour)
+	c.now = func() time.Time { return now }
+
+	score := -0.25
+	texts := []string{"Camiseta roja", "Pantalón", "Camiseta roja"}
+	entries := []Entry{{Translation: "Red T-shirt", Score: &score}, {Translation: "Trousers"}, {Translation: "Red T-shirt", Score: &score}}
+	if err := c.Put(ctx, "es", "en", texts, entries); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(table.items) != 2 {
+		t.Errorf("Put() wrote %d items, want 2", len(table.items))
+	}
+
+	found, err := c.Get(ctx, "es", "en", []string{"Camiseta roja", "Pantalón", "Zapato", "Pantalón"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if e := found["Camiseta roja"]; e.Translation != "Red T-shirt" || e.Score == nil || *e.Score != score {
+		t.Errorf("Get() title = %+v, want the translation with score %v", e, score)
+	}
+	if e := found["Pantalón"]; e.Translation != "Trousers" || e.Score != nil {
+		t.Errorf("Get() = %+v, want the translation without score", e)
+	}
+	if _, ok := found["Zapato"]; ok {
+		t.Error("Get() found a text that was never stored")
+	}
+
+	// Another pair is another key
+	if found, _ := c.Get(ctx, "es", "fr", []string{"Pantalón"}); len(found) != 0 {
+		t.Errorf("Get() of another pair = %v, want nothing", found)
+	}
+
+	// Expired items may still be returned by DynamoDB
+	now = now.Add(2 * time.Hour)
+	if found, _ := c.Get(ctx, "es", "en", []string{"Pantalón"}); len(found) != 0 {
+		t.Errorf("Get() after expiry = %v, want nothing", found)
+	}
+	found, stale, err := c.Lookup(ctx, "es", "en", []string{"Pantalón", "Zapato"})
+	if err != nil || len(found) != 0 || !stale["Pantalón"] || stale["Zapato"] {
+		t.Errorf("Lookup() after expiry = %v, stale %v, %v; want Pantalón stale only", found, stale, err)
+	}
+}
+
+func TestCache_Versions(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable()
+	c := New(table, "cache", time.Hour)
+
+	translatedAt := time.Unix(1700000000, 0).UTC()
+	entries := []Entry{{Translation: "Red T-shirt", ModelVersion: "opus-mt-es-en@3", TranslatedAt: translatedAt}, {Translation: "Trousers"}}
+	if err := c.Put(ctx, "es", "en", []string{"Camiseta roja", "Pantalón"}, entries); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	found, err := c.Get(ctx, "es", "en", []string{"Camiseta roja", "Pantalón"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if e := found["Camiseta roja"]; e.ModelVersion != "opus-mt-es-en@3" || !e.TranslatedAt.Equal(translatedAt) {
+		t.Errorf("Get() = %+v, want model version and time", e)
+	}
+	if e := found["Pantalón"]; e.ModelVersion != "" || !e.TranslatedAt.IsZero() {
+		t.Errorf("Get() = %+v, want no version", e)
+	}
+
+	// Malformed times are not trusted
+	table.items[Key("es", "en", "Pantalón")]["translatedAt"] = &types.AttributeValueMemberN{Value: "soon"}
+	if found, _ := c.Get(ctx, "es", "en", []string{"Pantalón"}); len(found) != 0 {
+		t.Errorf("Get() of a malformed item = %v, want nothing", found)
+	}
+}
+
+func TestCache_Batches(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable()
+	c := New(table, "cache", time.Hour)
+
+	texts := make([]string, 130)
+	entries := make([]Entry, len(texts))
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+		entries[i] = Entry{Translation: fmt.Sprintf("translation %d", i)}
+	}
+	if err := c.Put(ctx, "es", "en", texts, entries); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if table.writes != 6 {
+		t.Errorf("Put() made %d batch writes, want 6", table.writes)
+	}
+	found, err := c.Get(ctx, "es", "en", texts)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(found) != len(texts) || table.gets != 2 {
+		t.Errorf("Get() found %d texts in %d batches, want %d in 2", len(found), table.gets, len(texts))
+	}
+}
+
+func TestCache_PutMismatch(t *testing.T) {
+	c := New(newMemoryTable(), "cache", time.Hour)
+	if err := c.Put(context.Background(), "es", "en", []string{"a", "b"}, []Entry{{}}); err == nil {
+		t.Error("Put() expected an error for mismatched entries")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultTTL, false},
+		{"48h", 48 * time.Hour, false},
+		{"-1h", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(TTLEnv, tt.value)
+			got, err := TTL()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("TTL() = %v, %v, want %v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
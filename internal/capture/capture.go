@@ -0,0 +1,176 @@
+// Package capture samples anonymized translation tuples into S3 as JSONL,
+// building evaluation and fine-tuning corpora without log scraping.
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Environment variables controlling capture. Capture is off unless both
+// CAPTURE_BUCKET and a positive CAPTURE_SAMPLE_RATE are set.
+const (
+	BucketEnv     = "CAPTURE_BUCKET"
+	PrefixEnv     = "CAPTURE_PREFIX"
+	SampleRateEnv = "CAPTURE_SAMPLE_RATE"
+)
+
+// DefaultPrefix is the S3 key prefix used when CAPTURE_PREFIX is unset.
+const DefaultPrefix = "capture/"
+
+// Record is one line of the capture JSONL format.
+type Record struct {
+	Source       string    `json:"source"`
+	Translation  string    `json:"translation"`
+	SourceLang   string    `json:"sourceLang"`
+	TargetLang   string    `json:"targetLang"`
+	Route        []string  `json:"route"`
+	ModelVersion string    `json:"modelVersion,omitempty"`
+	CapturedAt   time.Time `json:"capturedAt"`
+}
+
+// Uploader is the subset of the S3 client used for capture.
+type Uploader interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// Sampler selects and uploads capture records.
+type Sampler struct {
+	uploader Uploader
+	bucket   string
+	prefix   string
+	rate     float64
+	random   func() float64
+	now      func() time.Time
+}
+
+// New creates a Sampler capturing a fraction rate (0-1) of translations.
+func New(uploader Uploader, bucket, prefix string, rate float64) *Sampler {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Sampler{
+		uploader: uploader,
+		bucket:   bucket,
+		prefix:   prefix,
+		rate:     rate,
+		random:   rand.Float64, // #nosec G404 -- sampling does not need crypto randomness
+		now:      time.Now,
+	}
+}
+
+// FromEnv returns a Sampler configured from the environment, or nil when
+// capture is disabled.
+func FromEnv(uploader Uploader) (*Sampler, error) {
+	bucket := os.Getenv(BucketEnv)
+	rateStr := os.Getenv(SampleRateEnv)
+	if bucket == "" || rateStr == "" {
+		return nil, nil
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("%s must be a number between 0 and 1, got %q", SampleRateEnv, rateStr)
+	}
+	if rate == 0 {
+		return nil, nil
+	}
+	return New(uploader, bucket, os.Getenv(PrefixEnv), rate), nil
+}
+
+// Sample picks translations to capture and anonymizes them. Empty
+// translations (withheld or blocked) are never captured.
+func (s *Sampler) Sample(sourceLang, targetLang string, sources, translations, route []string, modelVersion string) []Record {
+	if s == nil {
+		return nil
+	}
+	var records []Record
+	now := s.now().UTC()
+	for i := range sources {
+		if i >= len(translations) || translations[i] == "" || s.random() >= s.rate {
+			continue
+		}
+		records = append(records, Record{
+			Source:       Anonymize(sources[i]),
+			Translation:  Anonymize(translations[i]),
+			SourceLang:   sourceLang,
+			TargetLang:   targetLang,
+			Route:        route,
+			ModelVersion: modelVersion,
+			CapturedAt:   now,
+		})
+	}
+	return records
+}
+
+// Upload writes records as one JSONL object under
+// {prefix}{source}-{target}/dt={date}/{timestamp}.jsonl.
+func (s *Sampler) Upload(ctx context.Context, records []Record) error {
+	if s == nil || len(records) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("failed to encode capture record: %w", err)
+		}
+	}
+
+	first := records[0]
+	key := fmt.Sprintf("%s%s-%s/dt=%s/%d.jsonl", s.prefix, first.SourceLang, first.TargetLang,
+		first.CapturedAt.Format("2006-01-02"), first.CapturedAt.UnixNano())
+
+	_, err := s.uploader.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload capture to s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+// Personal data patterns removed from captured texts.
+var (
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d .-]{7,}\d`)
+	urlPattern   = regexp.MustCompile(`https?://\S+`)
+)
+
+// minPhoneDigits separates phone numbers from prices like "1.250.000".
+const minPhoneDigits = 9
+
+// Anonymize replaces emails, URLs and phone numbers with tags.
+func Anonymize(text string) string {
+	text = emailPattern.ReplaceAllString(text, "<EMAIL>")
+	text = urlPattern.ReplaceAllString(text, "<URL>")
+	return phonePattern.ReplaceAllStringFunc(text, func(m string) string {
+		if countDigits(m) < minPhoneDigits {
+			return m
+		}
+		return "<PHONE>"
+	})
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type fakeUploader struct {
+	input *s3.PutObjectInput
+	body  string
+}
+
+func (f *fakeUploader) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	f.input = params
+	data, err := io.ReadAll(params.Body)
+	f.body = string(data)
+	return &s3.PutObjectOutput{}, err
+}
+
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Escríbeme a ana.perez+shop@example.com", "Escríbeme a <EMAIL>"},
+		{"Llama al +34 612 345 678 ya", "Llama al <PHONE> ya"},
+		{"Precio 1.250.000 euros", "Precio 1.250.000 euros"},
+		{"Ver https://example.com/item?id=1 hoy", "Ver <URL> hoy"},
+		{"iPhone 12 Pro", "iPhone 12 Pro"},
+	}
+	for _, tt := range tests {
+		if got := Anonymize(tt.in); got != tt.want {
+			t.Errorf("Anonymize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSampler(t *testing.T) {
+	uploader := &fakeUploader{}
+	s := New(uploader, "corpus", "", 0.5)
+	s.now = func() time.Time { return time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC) }
+	draws := []float64{0.1, 0.9, 0.2}
+	s.random = func() float64 {
+		d := draws[0]
+		draws = draws[1:]
+		return d
+	}
+
+	records := s.Sample("es", "fr", []string{"uno", "dos", "tres"}, []string{"un", "deux", ""},
+		[]string{"pricofy-translator-romance-en", "pricofy-translator-en-romance"}, "opus-mt-1")
+	if len(records) != 1 || records[0].Source != "uno" {
+		t.Fatalf("Sample() = %+v, want only the first text (second not drawn, third empty)", records)
+	}
+
+	if err := s.Upload(context.Background(), records); err != nil {
+		t.Fatalf("Upload() unexpected error: %v", err)
+	}
+	if key := *uploader.input.Key; !strings.HasPrefix(key, "capture/es-fr/dt=2024-12-01/") || !strings.HasSuffix(key, ".jsonl") {
+		t.Errorf("Upload() key = %q", key)
+	}
+
+	var rec Record
+	if err := json.Unmarshal([]byte(strings.TrimSpace(uploader.body)), &rec); err != nil {
+		t.Fatalf("uploaded body is not JSONL: %v", err)
+	}
+	if rec.Translation != "un" || rec.ModelVersion != "opus-mt-1" || len(rec.Route) != 2 {
+		t.Errorf("uploaded record = %+v", rec)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		s, err := FromEnv(&fakeUploader{})
+		if s != nil || err != nil {
+			t.Errorf("FromEnv() = %v, %v; want nil, nil", s, err)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv(BucketEnv, "corpus")
+		t.Setenv(SampleRateEnv, "0.01")
+		s, err := FromEnv(&fakeUploader{})
+		if s == nil || err != nil || s.rate != 0.01 {
+			t.Errorf("FromEnv() = %+v, %v", s, err)
+		}
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		t.Setenv(BucketEnv, "corpus")
+		t.Setenv(SampleRateEnv, "2")
+		if _, err := FromEnv(&fakeUploader{}); err == nil {
+			t.Error("FromEnv() should reject rates above 1")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+// Package casing applies marketplace style rules to translated texts:
+// sentence case, title case and trailing punctuation of titles.
+//
+// Rules only ever raise the case of lowercase words, so brand names and
+// acronyms the models kept ("iPhone", "USB-C") are never damaged.
+package casing
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Sentence capitalizes the first word of s unless it has capitals of its
+// own ("iPhone", "eBay").
+func Sentence(s string) string {
+	start := strings.IndexFunc(s, unicode.IsLetter)
+	if start < 0 {
+		return s
+	}
+	if prev := s[:start]; prev != "" && !startsWord(prev) {
+		return s // "2x cable" or "v2 cable": the text starts with a number
+	}
+	end := wordEnd(s, start)
+	return s[:start] + capitalize(s[start:end]) + s[end:]
+}
+
+// Title capitalizes every lowercase word of s except the minor words of
+// lang (articles, conjunctions, short prepositions), which stay lowercase
+// unless they are the first or last word.
+func Title(s, lang string) string {
+	minor := minorWords[lang]
+	var b strings.Builder
+	words := wordSpans(s)
+	last := 0
+	for i, w := range words {
+		word := s[w[0]:w[1]]
+		b.WriteString(s[last:w[0]])
+		if i == 0 || i == len(words)-1 || !minor[word] {
+			word = capitalize(word)
+		}
+		b.WriteString(word)
+		last = w[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// TrimTrailingPunctuation drops a final period, comma, colon or semicolon,
+// which marketplaces reject in titles. Ellipses, question and exclamation
+// marks are kept.
+func TrimTrailingPunctuation(s string) string {
+	trimmed := strings.TrimRightFunc(s, unicode.IsSpace)
+	for trimmed != "" {
+		r, size := utf8.DecodeLastRuneInString(trimmed)
+		if !strings.ContainsRune(".,;:", r) || strings.HasSuffix(trimmed, "..") {
+			break
+		}
+		trimmed = trimmed[:len(trimmed)-size]
+	}
+	return strings.TrimRightFunc(trimmed, unicode.IsSpace)
+}
+
+// capitalize uppercases the first letter of an all-lowercase word.
+func capitalize(word string) string {
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			return word
+		}
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToTitle(r)) + word[size:]
+}
+
+// startsWord reports whether the text before the first letter is only
+// spaces and opening punctuation.
+func startsWord(prefix string) bool {
+	return strings.TrimLeftFunc(prefix, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.Is(unicode.Ps, r) || unicode.Is(unicode.Pi, r) ||
+			r == '"' || r == '\'' || r == '¿' || r == '¡' || r == '-' || r == '•'
+	}) == ""
+}
+
+// wordEnd returns the end of the word starting at start.
+func wordEnd(s string, start int) int {
+	if i := strings.IndexFunc(s[start:], func(r rune) bool { return !isWordRune(r) }); i >= 0 {
+		return start + i
+	}
+	return len(s)
+}
+
+// wordSpans returns the byte ranges of the words of s that start with a
+// letter; numbers and codes ("42", "4k") are left out.
+func wordSpans(s string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !isWordRune(r) {
+			i += size
+			continue
+		}
+		end := wordEnd(s, i)
+		if unicode.IsLetter(r) {
+			spans = append(spans, [2]int{i, end})
+		}
+		i = end
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '\''
+}
+
+// IsMinorWord reports whether word is an article, conjunction or short
+// preposition of lang, in any case.
+func IsMinorWord(word, lang string) bool {
+	return minorWords[lang][strings.ToLower(word)]
+}
+
+// minorWords stay lowercase inside title-cased titles.
+var minorWords = map[string]map[string]bool{
+	"en": set("a", "an", "the", "and", "but", "or", "nor", "for", "of", "in", "on", "at", "to", "by", "with", "from", "as", "vs"),
+	"es": set("el", "la", "los", "las", "un", "una", "y", "e", "o", "u", "de", "del", "en", "con", "sin", "para", "por", "a", "al"),
+	"pt": set("o", "a", "os", "as", "um", "uma", "e", "ou", "de", "do", "da", "dos", "das", "em", "no", "na", "com", "sem", "para", "por"),
+	"it": set("il", "lo", "la", "i", "gli", "le", "un", "uno", "una", "e", "o", "di", "del", "della", "in", "con", "senza", "per", "da", "a"),
+	"fr": set("le", "la", "les", "un", "une", "des", "et", "ou", "de", "du", "en", "avec", "sans", "pour", "par", "à", "au", "aux"),
+	"de": set("der", "die", "das", "ein", "eine", "und", "oder", "von", "mit", "ohne", "für", "in", "zu", "im", "am"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
@@ -0,0 +1,63 @@
+package casing
+
+import "testing"
+
+func TestSentence(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"zapatillas de running", "Zapatillas de running"},
+		{"¿tienes dudas? escríbenos", "¿Tienes dudas? escríbenos"},
+		{"\"nuevo\" con etiqueta", "\"Nuevo\" con etiqueta"},
+		{"iPhone 13 como nuevo", "iPhone 13 como nuevo"},
+		{"2 pares de calcetines", "2 pares de calcetines"},
+		{"élégant et pratique", "Élégant et pratique"},
+		{"", ""},
+		{"123", "123"},
+	}
+
+	for _, tt := range tests {
+		if got := Sentence(tt.in); got != tt.want {
+			t.Errorf("Sentence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		in, lang, want string
+	}{
+		{"running shoes for men", "en", "Running Shoes for Men"},
+		{"the lord of the rings box set", "en", "The Lord of the Rings Box Set"},
+		{"case for iPhone 13 with USB-C cable", "en", "Case for iPhone 13 with USB-C Cable"},
+		{"what it's made of", "en", "What It's Made Of"},
+		{"funda de silicona para el móvil", "es", "Funda de Silicona para el Móvil"},
+		{"4k monitor 27 inches", "en", "4k Monitor 27 Inches"},
+	}
+
+	for _, tt := range tests {
+		if got := Title(tt.in, tt.lang); got != tt.want {
+			t.Errorf("Title(%q, %q) = %q, want %q", tt.in, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTrimTrailingPunctuation(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Running shoes.", "Running shoes"},
+		{"Running shoes, ", "Running shoes"},
+		{"Running shoes:;", "Running shoes"},
+		{"Last units!", "Last units!"},
+		{"Why buy new?", "Why buy new?"},
+		{"And more...", "And more..."},
+		{"No punctuation", "No punctuation"},
+	}
+
+	for _, tt := range tests {
+		if got := TrimTrailingPunctuation(tt.in); got != tt.want {
+			t.Errorf("TrimTrailingPunctuation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 // Package chunker provides text chunking for translation batches.
 package chunker
 
+import (
+	"sort"
+)
+
 // DefaultMaxTextsPerChunk limits texts per chunk.
 // 50 texts is optimal for 512MB Lambda with CTranslate2 beam search.
 const DefaultMaxTextsPerChunk = 50
@@ -29,3 +33,116 @@ func ChunkTexts(texts []string, maxTexts int) [][]string {
 
 	return chunks
 }
+
+// DefaultMaxTokensPerChunk is the token budget of a balanced chunk, leaving
+// the translator's model enough memory headroom.
+const DefaultMaxTokensPerChunk = 3000
+
+// ChunkBalanced splits texts into chunks of at most maxTexts texts whose
+// estimated token counts are as even as possible, so one long description
+// does not end up next to hundreds of titles. Texts are binned largest
+// first into the lightest chunk. It returns the chunks and, for each chunk,
+// the original index of every text; use Restore to put results back in order.
+// Tokens are estimated for source language lang.
+func ChunkBalanced(texts []string, lang string, maxTexts, maxTokens int) ([][]string, [][]int) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if maxTexts <= 0 {
+		maxTexts = DefaultMaxTextsPerChunk
+	}
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokensPerChunk
+	}
+
+	tokens := make([]int, len(texts))
+	total := 0
+	for i, text := range texts {
+		tokens[i] = EstimateTokensIn(text, lang)
+		total += tokens[i]
+	}
+
+	n := ceilDiv(len(texts), maxTexts)
+	if byTokens := ceilDiv(total, maxTokens); byTokens > n {
+		n = byTokens
+	}
+	if n > len(texts) {
+		n = len(texts)
+	}
+
+	bySize := make([]int, len(texts))
+	for i := range bySize {
+		bySize[i] = i
+	}
+	sort.SliceStable(bySize, func(a, b int) bool { return tokens[bySize[a]] > tokens[bySize[b]] })
+
+	order := make([][]int, n)
+	load := make([]int, n)
+	for _, i := range bySize {
+		lightest := -1
+		for c := range order {
+			if len(order[c]) < maxTexts && (lightest < 0 || load[c] < load[lightest]) {
+				lightest = c
+			}
+		}
+		order[lightest] = append(order[lightest], i)
+		load[lightest] += tokens[i]
+	}
+
+	chunks := make([][]string, n)
+	for c, indices := range order {
+		sort.Ints(indices)
+		chunks[c] = make([]string, len(indices))
+		for j, i := range indices {
+			chunks[c][j] = texts[i]
+		}
+	}
+	return chunks, order
+}
+
+// ChunkByTokens splits texts, in order, into chunks of at most maxTexts
+// texts and maxTokens estimated tokens; a text over the budget by itself
+// gets a chunk of its own. Tokens are estimated for source language lang.
+func ChunkByTokens(texts []string, lang string, maxTexts, maxTokens int) [][]string {
+	if maxTexts <= 0 {
+		maxTexts = DefaultMaxTextsPerChunk
+	}
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokensPerChunk
+	}
+
+	var chunks [][]string
+	start, load := 0, 0
+	for i, text := range texts {
+		tokens := EstimateTokensIn(text, lang)
+		if i > start && (i-start == maxTexts || load+tokens > maxTokens) {
+			chunks = append(chunks, texts[start:i])
+			start, load = i, 0
+		}
+		load += tokens
+	}
+	if start < len(texts) {
+		chunks = append(chunks, texts[start:])
+	}
+	return chunks
+}
+
+// Restore flattens per-chunk results back into the original text order,
+// given the order returned by ChunkBalanced.
+func Restore[T any](results [][]T, order [][]int) []T {
+	n := 0
+	for _, indices := range order {
+		n += len(indices)
+	}
+	restored := make([]T, n)
+	for c, indices := range order {
+		for j, i := range indices {
+			restored[i] = results[c][j]
+		}
+	}
+	return restored
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
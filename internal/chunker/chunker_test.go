@@ -1,6 +1,8 @@
 package chunker
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -119,6 +121,78 @@ func TestChunkTexts_PreservesOrder(t *testing.T) {
 	}
 }
 
+func TestChunkBalanced(t *testing.T) {
+	long := strings.Repeat("descripción larga ", 400) // ~2000 tokens
+	texts := append([]string{long}, makeTexts(119)...)
+	texts = append(texts, long)
+
+	chunks, order := ChunkBalanced(texts, "es", 50, 3000)
+
+	if len(chunks) != 3 {
+		t.Fatalf("ChunkBalanced() made %d chunks, want 3", len(chunks))
+	}
+
+	longChunks := map[int]bool{}
+	for c, chunk := range chunks {
+		if len(chunk) > 50 {
+			t.Errorf("chunk %d has %d texts, max 50", c, len(chunk))
+		}
+		for _, text := range chunk {
+			if text == long {
+				longChunks[c] = true
+			}
+		}
+	}
+	if len(longChunks) != 2 {
+		t.Errorf("the two long texts should land in different chunks, got %v", longChunks)
+	}
+
+	restored := Restore(chunks, order)
+	for i, text := range texts {
+		if restored[i] != text {
+			t.Fatalf("Restore() position %d = %.20q, want %.20q", i, restored[i], text)
+		}
+	}
+}
+
+func TestChunkBalanced_TokenBudget(t *testing.T) {
+	texts := []string{strings.Repeat("a", 8000), strings.Repeat("b", 8000), "c", "d"}
+
+	chunks, _ := ChunkBalanced(texts, "es", 50, 3000)
+	if len(chunks) != 2 {
+		t.Errorf("ChunkBalanced() made %d chunks, want 2 to respect the token budget", len(chunks))
+	}
+}
+
+func TestChunkBalanced_Empty(t *testing.T) {
+	if chunks, order := ChunkBalanced(nil, "es", 50, 3000); chunks != nil || order != nil {
+		t.Errorf("ChunkBalanced(nil) = %v, %v", chunks, order)
+	}
+}
+
+func TestChunkByTokens(t *testing.T) {
+	long := strings.Repeat("a", 8000)
+	texts := []string{"a", "b", long, "c", long, long, "d", "e", "f"}
+
+	chunks := ChunkByTokens(texts, "es", 3, 3000)
+	want := [][]string{{"a", "b", long}, {"c", long}, {long, "d", "e"}, {"f"}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("ChunkByTokens() = %d chunks of %v, want %d", len(chunks), chunkLens(chunks), len(want))
+	}
+	if chunks := ChunkByTokens(nil, "es", 3, 3000); chunks != nil {
+		t.Errorf("ChunkByTokens(nil) = %v", chunks)
+	}
+}
+
+// chunkLens returns the number of texts of every chunk.
+func chunkLens(chunks [][]string) []int {
+	lens := make([]int, len(chunks))
+	for i, c := range chunks {
+		lens[i] = len(c)
+	}
+	return lens
+}
+
 // Helper to create N texts
 func makeTexts(n int) []string {
 	texts := make([]string, n)
@@ -0,0 +1,194 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pricofy/translation-manager/internal/segment"
+)
+
+// DefaultMaxTokensPerText is the largest text, in estimated tokens, sent to
+// the translators whole. The opus-mt models read at most 512 subword tokens
+// per input and pad a batch to its longest input, so one long description
+// makes its whole chunk heavy enough to exhaust the translator's memory.
+const DefaultMaxTokensPerText = 400
+
+// Split holds texts with the oversized ones broken into pieces on sentence
+// boundaries; Stitch puts the translations of the pieces back together.
+type Split struct {
+	// Texts holds the texts within budget unchanged and, in place of each
+	// oversized text, its pieces.
+	Texts []string
+	// docs holds the pieces of every oversized text, by original index.
+	docs map[int]*segment.Document
+	n    int
+}
+
+// SplitOversized breaks every text over maxTokens estimated tokens into
+// pieces of whole consecutive sentences within the budget. A sentence over
+// the budget by itself is broken between words. Tokens are estimated for
+// source language lang.
+func SplitOversized(texts []string, lang string, maxTokens int) *Split {
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokensPerText
+	}
+	s := &Split{n: len(texts)}
+	for i, text := range texts {
+		var doc *segment.Document
+		if EstimateTokensIn(text, lang) > maxTokens {
+			doc = segment.Split(text)
+		}
+		if doc == nil || len(doc.Segments) == 0 {
+			s.Texts = append(s.Texts, text)
+			continue
+		}
+		doc = packSentences(doc, lang, maxTokens)
+		if s.docs == nil {
+			s.docs = map[int]*segment.Document{}
+		}
+		s.docs[i] = doc
+		s.Texts = append(s.Texts, doc.Texts()...)
+	}
+	return s
+}
+
+// Oversized reports whether any text was split.
+func (s *Split) Oversized() bool {
+	return len(s.docs) > 0
+}
+
+// Origins returns the index of the original text of every entry of
+// s.Texts.
+func (s *Split) Origins() []int {
+	origins := make([]int, 0, len(s.Texts))
+	if !s.Oversized() {
+		for i := range s.Texts {
+			origins = append(origins, i)
+		}
+		return origins
+	}
+	for i := 0; i < s.n; i++ {
+		pieces := 1
+		if doc, ok := s.docs[i]; ok {
+			pieces = len(doc.Segments)
+		}
+		for ; pieces > 0; pieces-- {
+			origins = append(origins, i)
+		}
+	}
+	return origins
+}
+
+// packSentences merges consecutive sentences of doc into pieces of at most
+// maxTokens, keeping the separators between them inside the piece.
+func packSentences(doc *segment.Document, lang string, maxTokens int) *segment.Document {
+	packed := &segment.Document{Lead: doc.Lead}
+	var piece strings.Builder
+	var last segment.Segment
+	flush := func() {
+		if piece.Len() > 0 {
+			packed.Segments = append(packed.Segments, segment.Segment{Text: piece.String(), Sep: last.Sep, Joiner: last.Joiner})
+			piece.Reset()
+		}
+	}
+	for _, sentence := range doc.Segments {
+		if EstimateTokensIn(sentence.Text, lang) > maxTokens {
+			flush()
+			packed.Segments = append(packed.Segments, splitWords(sentence, lang, maxTokens)...)
+			continue
+		}
+		if piece.Len() > 0 && EstimateTokensIn(piece.String()+last.Sep+sentence.Text, lang) > maxTokens {
+			flush()
+		}
+		if piece.Len() > 0 {
+			piece.WriteString(last.Sep)
+		}
+		piece.WriteString(sentence.Text)
+		last = sentence
+	}
+	flush()
+	return packed
+}
+
+// splitWords breaks an oversized sentence between words into pieces of at
+// most maxTokens. A single word over the budget stays whole.
+func splitWords(sentence segment.Segment, lang string, maxTokens int) []segment.Segment {
+	var pieces []segment.Segment
+	rest := sentence.Text
+	for EstimateTokensIn(rest, lang) > maxTokens {
+		cut := wordBreak(rest, lang, maxTokens)
+		if cut < 0 {
+			break
+		}
+		word := strings.TrimRightFunc(rest[:cut], unicode.IsSpace)
+		pieces = append(pieces, segment.Segment{Text: word, Sep: rest[len(word):cut], Joiner: segment.JoinSpace})
+		rest = rest[cut:]
+	}
+	return append(pieces, segment.Segment{Text: rest, Sep: sentence.Sep, Joiner: sentence.Joiner})
+}
+
+// wordBreak returns the end of the whitespace after the last word of text
+// that keeps the text before it within maxTokens, or of the first word when
+// none does; -1 when text is a single word.
+func wordBreak(text, lang string, maxTokens int) int {
+	best := -1
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if inSpace && !space {
+			if best >= 0 && EstimateTokensIn(text[:i], lang) > maxTokens {
+				return best
+			}
+			best = i
+		}
+		inSpace = space
+	}
+	return best
+}
+
+// Stitch joins the translations of s.Texts into one translation per
+// original text. The pieces of a split text are joined with the sentence
+// spacing of lang and the layout of the original; a split text whose
+// pieces all came back empty stays empty.
+func (s *Split) Stitch(translations []string, lang string) []string {
+	if !s.Oversized() {
+		return translations
+	}
+	stitched := make([]string, s.n)
+	next := 0
+	for i := range stitched {
+		doc, ok := s.docs[i]
+		if !ok {
+			stitched[i] = translations[next]
+			next++
+			continue
+		}
+		pieces := translations[next : next+len(doc.Segments)]
+		next += len(doc.Segments)
+		if strings.Join(pieces, "") != "" {
+			stitched[i] = doc.JoinIn(pieces, lang)
+		}
+	}
+	return stitched
+}
+
+// StitchScores returns one score per original text: the average score of
+// the pieces of a split text, as scores are length-normalized.
+func (s *Split) StitchScores(scores []float64) []float64 {
+	if !s.Oversized() {
+		return scores
+	}
+	stitched := make([]float64, s.n)
+	next := 0
+	for i := range stitched {
+		pieces := 1
+		if doc, ok := s.docs[i]; ok {
+			pieces = len(doc.Segments)
+		}
+		for _, score := range scores[next : next+pieces] {
+			stitched[i] += score / float64(pieces)
+		}
+		next += pieces
+	}
+	return stitched
+}
@@ -0,0 +1,95 @@
+package chunker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sentence returns a sentence of about tokens estimated English tokens.
+func sentence(word string, tokens int) string {
+	words := tokens / (EstimateTokensIn(word, "en") - 1)
+	return strings.TrimSpace(strings.Repeat(word+" ", words)) + "."
+}
+
+func TestSplitOversized(t *testing.T) {
+	long := sentence("alpha", 30) + " " + sentence("beta", 30) + "\n\n" + sentence("gamma", 30)
+	texts := []string{"short", long, "tail"}
+
+	s := SplitOversized(texts, "en", 70)
+	if !s.Oversized() {
+		t.Fatal("Oversized() = false, want true")
+	}
+	want := []string{"short", sentence("alpha", 30) + " " + sentence("beta", 30), sentence("gamma", 30), "tail"}
+	if !reflect.DeepEqual(s.Texts, want) {
+		t.Fatalf("Texts = %q, want %q", s.Texts, want)
+	}
+	for _, piece := range s.Texts {
+		if EstimateTokensIn(piece, "en") > 70 {
+			t.Errorf("piece of %d tokens exceeds the budget: %q", EstimateTokensIn(piece, "en"), piece)
+		}
+	}
+
+	if got, want := s.Origins(), []int{0, 1, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Origins() = %v, want %v", got, want)
+	}
+
+	// Translating every piece as itself reproduces the original texts
+	if got := s.Stitch(s.Texts, "en"); !reflect.DeepEqual(got, texts) {
+		t.Errorf("Stitch() = %q, want %q", got, texts)
+	}
+}
+
+func TestSplitOversized_WithinBudget(t *testing.T) {
+	texts := []string{"Camiseta roja", "Zapatos"}
+	s := SplitOversized(texts, "en", 0)
+	if s.Oversized() || !reflect.DeepEqual(s.Texts, texts) {
+		t.Errorf("SplitOversized() = %q (oversized %v), want the texts unchanged", s.Texts, s.Oversized())
+	}
+	if got := s.Origins(); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("Origins() = %v, want [0 1]", got)
+	}
+	if got := s.Stitch([]string{"Red T-shirt", "Shoes"}, "en"); !reflect.DeepEqual(got, []string{"Red T-shirt", "Shoes"}) {
+		t.Errorf("Stitch() = %q", got)
+	}
+}
+
+func TestSplitOversized_LongSentence(t *testing.T) {
+	text := strings.TrimSuffix(sentence("palabra", 100), ".")
+	s := SplitOversized([]string{text}, "en", 30)
+
+	if len(s.Texts) < 4 {
+		t.Fatalf("Texts = %d pieces, want the sentence broken between words", len(s.Texts))
+	}
+	for _, piece := range s.Texts {
+		if EstimateTokensIn(piece, "en") > 30 || strings.TrimSpace(piece) != piece {
+			t.Errorf("bad piece %q (%d tokens)", piece, EstimateTokensIn(piece, "en"))
+		}
+	}
+	if got := s.Stitch(s.Texts, "es"); got[0] != text {
+		t.Errorf("Stitch() = %q, want the original sentence", got[0])
+	}
+}
+
+func TestSplit_StitchTargetSpacing(t *testing.T) {
+	text := sentence("uno", 30) + " " + sentence("dos", 30)
+	s := SplitOversized([]string{text}, "en", 40)
+	if len(s.Texts) != 2 {
+		t.Fatalf("Texts = %q, want 2 pieces", s.Texts)
+	}
+	if got := s.Stitch([]string{"一。", " 二。"}, "ja"); got[0] != "一。二。" {
+		t.Errorf("Stitch() in Japanese = %q, want the pieces run together", got[0])
+	}
+	if got := s.Stitch([]string{"", ""}, "en"); got[0] != "" {
+		t.Errorf("Stitch() of empty pieces = %q, want empty", got[0])
+	}
+}
+
+func TestSplit_StitchScores(t *testing.T) {
+	text := sentence("uno", 30) + " " + sentence("dos", 30)
+	s := SplitOversized([]string{"a", text}, "en", 40)
+	got := s.StitchScores([]float64{-0.5, -1, -2})
+	if want := []float64{-0.5, -1.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StitchScores() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package chunker
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/pricofy/translation-manager/internal/grapheme"
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// charsPerPiece is the average length, in characters, of the SentencePiece
+// pieces the opus-mt vocabularies split a word of each source language
+// into. Frequent short words are a single piece everywhere, but accented
+// Romance words, German and Nordic compounds and inflected Slavic words
+// break into more, shorter pieces than English.
+var charsPerPiece = map[string]float64{
+	"en": 5.0,
+	"es": 4.5, "it": 4.5, "pt": 4.3, "fr": 4.3,
+	"ca": 4.0, "gl": 4.0, "ro": 4.0, "oc": 3.8,
+	"de": 4.0,
+	"sv": 4.0, "da": 4.0, "no": 4.0, "nb": 4.0, "nn": 3.8, "is": 3.4,
+	"pl": 3.5, "hr": 3.5, "bs": 3.5, "cs": 3.4, "sk": 3.4, "sl": 3.4, "ru": 3.4,
+	"sr": 3.3, "bg": 3.3, "uk": 3.2, "mk": 3.2, "be": 3.0,
+}
+
+// defaultCharsPerPiece applies to languages without an entry: minor
+// languages are rare in the vocabularies and split finely.
+const defaultCharsPerPiece = 3.5
+
+// digitsPerPiece is the average length of the pieces of a number.
+const digitsPerPiece = 2
+
+// EstimateTokens approximates the number of model tokens in text of an
+// unknown language; see EstimateTokensIn.
+func EstimateTokens(text string) int {
+	return EstimateTokensIn(text, "")
+}
+
+// EstimateTokensIn approximates the number of SentencePiece tokens the
+// translators see for text in lang, end-of-sentence token included. Words
+// split into pieces of the language's average length (half as long in
+// all-caps words, which the vocabularies rarely hold whole), numbers into
+// pairs of digits, and every other symbol is a token. Lengths count
+// grapheme clusters, so accents and emoji count once.
+func EstimateTokensIn(text, lang string) int {
+	ratio, ok := charsPerPiece[locale.Base(lang)]
+	if !ok {
+		ratio = defaultCharsPerPiece
+	}
+
+	tokens := 1
+	var word, digits, upper int
+	flush := func() {
+		if word > 0 {
+			r := ratio
+			if upper == word && word > 1 {
+				r /= 2
+			}
+			tokens += int(math.Ceil(float64(word) / r))
+		}
+		if digits > 0 {
+			tokens += (digits + digitsPerPiece - 1) / digitsPerPiece
+		}
+		word, digits, upper = 0, 0, 0
+	}
+	for i := 0; i < len(text); {
+		size := grapheme.Next(text[i:])
+		r := firstRune(text[i : i+size])
+		switch {
+		case unicode.IsLetter(r):
+			if digits > 0 {
+				flush()
+			}
+			word++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		case unicode.IsDigit(r):
+			if word > 0 {
+				flush()
+			}
+			digits++
+		default:
+			flush()
+			if !unicode.IsSpace(r) {
+				tokens++
+			}
+		}
+		i += size
+	}
+	flush()
+	return tokens
+}
+
+// firstRune returns the first rune of a grapheme cluster.
+func firstRune(cluster string) rune {
+	for _, r := range cluster {
+		return r
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokensIn(t *testing.T) {
+	tests := []struct {
+		text string
+		lang string
+		want int
+	}{
+		{"", "", 1},
+		{"abc", "", 2},
+		{"iPhone 12 Pro en buen estado", "es", 9},
+		{"Camión añil ñandú", "es", 6},
+		{"Waschmaschinenzubehör", "de", 7},
+		{"Waschmaschinenzubehör", "en", 6},
+		{"NUEVO", "es", 4},
+		{"Smartphone", "pt_BR", 4},
+		{"Стиральная машина", "ru", 6},
+		{"Preis: 1.299,00 €", "de", 11},
+		{"🇪🇸🇪🇸🇪🇸🇪🇸", "", 5},
+		{strings.Repeat("a", 8000), "", 2287},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokensIn(tt.text, tt.lang); got != tt.want {
+			t.Errorf("EstimateTokensIn(%.20q, %q) = %d, want %d", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTokens_UnknownLanguage(t *testing.T) {
+	text := "Lavadora en perfecto estado"
+	if got, want := EstimateTokens(text), EstimateTokensIn(text, "xx"); got != want {
+		t.Errorf("EstimateTokens() = %d, want the default ratio's %d", got, want)
+	}
+	if EstimateTokensIn(text, "es") >= EstimateTokensIn(text, "ru") {
+		t.Error("Spanish text should estimate fewer tokens than with the Russian ratio")
+	}
+}
@@ -0,0 +1,44 @@
+// Package config loads optional JSON configuration from the environment.
+//
+// Every configurable subsystem follows the same convention: the JSON document
+// is read inline from an environment variable (e.g. POSTEDIT_RULES) or, when
+// that is unset, from the file named by the matching *_FILE variable (e.g.
+// POSTEDIT_RULES_FILE), which lets large configs ship inside the artifact.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadJSON decodes the JSON config named by envVar into v. It returns false
+// when neither envVar nor envVar+"_FILE" is set.
+func LoadJSON(envVar string, v interface{}) (bool, error) {
+	data, source, err := read(envVar)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	return true, nil
+}
+
+// read returns the raw config and a description of where it came from.
+func read(envVar string) ([]byte, string, error) {
+	if inline := os.Getenv(envVar); inline != "" {
+		return []byte(inline), envVar, nil
+	}
+
+	fileVar := envVar + "_FILE"
+	path := os.Getenv(fileVar)
+	if path == "" {
+		return nil, "", nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from deployment config
+	if err != nil {
+		return nil, fileVar, fmt.Errorf("failed to read %s: %w", fileVar, err)
+	}
+	return data, fileVar + " (" + path + ")", nil
+}
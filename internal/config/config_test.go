@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type sample struct {
+	Name string `json:"name"`
+}
+
+func TestLoadJSON(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		var v sample
+		found, err := LoadJSON("TEST_CONFIG_UNSET", &v)
+		if found || err != nil {
+			t.Errorf("LoadJSON() = %v, %v; want false, nil", found, err)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		t.Setenv("TEST_CONFIG", `{"name": "inline"}`)
+		var v sample
+		found, err := LoadJSON("TEST_CONFIG", &v)
+		if !found || err != nil || v.Name != "inline" {
+			t.Errorf("LoadJSON() = %v, %v, %+v", found, err, v)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"name": "file"}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("TEST_CONFIG_FILE", path)
+		var v sample
+		found, err := LoadJSON("TEST_CONFIG", &v)
+		if !found || err != nil || v.Name != "file" {
+			t.Errorf("LoadJSON() = %v, %v, %+v", found, err, v)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		t.Setenv("TEST_CONFIG", `{"name": `)
+		var v sample
+		if _, err := LoadJSON("TEST_CONFIG", &v); err == nil {
+			t.Error("LoadJSON() should fail on invalid JSON")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("TEST_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.json"))
+		var v sample
+		if _, err := LoadJSON("TEST_CONFIG", &v); err == nil {
+			t.Error("LoadJSON() should fail on a missing file")
+		}
+	})
+}
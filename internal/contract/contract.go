@@ -0,0 +1,200 @@
+// Package contract checks that a translator Lambda honours the chunked
+// protocol the router relies on: snake_case field names, one translation per
+// input text in every chunk, empty chunks and texts kept in place, optional
+// scores of the same shape, and failures reported as an "error" field rather
+// than a raised Lambda error.
+//
+// The suite runs against anything implementing router.Invoker: a deployed
+// translator, a local Lambda runtime emulator or an in-process stub.
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// responseFields are the top-level fields a translator may return.
+var responseFields = map[string]bool{
+	"translations":  true,
+	"scores":        true,
+	"model_version": true,
+	"formats":       true,
+	"error":         true,
+}
+
+// sampleTexts are short texts accepted by every translator direction.
+var sampleTexts = []string{"Hola mundo", "Bonjour", "Ciao", "Olá", "Hallo", "Hello"}
+
+// Result is the outcome of one contract case; Err is nil when it passed.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Suite runs the contract cases against one translator function.
+type Suite struct {
+	Invoker  router.Invoker
+	Function string
+	// TargetLang is sent as target_lang; en-romance translators require it.
+	TargetLang string
+}
+
+// testCase sends payload and checks the decoded response.
+type testCase struct {
+	name    string
+	payload map[string]any
+	check   func(resp *response) error
+}
+
+// response is a decoded translator reply.
+type response struct {
+	fields map[string]json.RawMessage
+	router.TranslatorResponse
+}
+
+// Run executes every case, in order, and returns their results.
+func (s *Suite) Run(ctx context.Context) []Result {
+	cases := []testCase{
+		{"field names", s.request([][]string{sampleTexts[:1]}), checkFields},
+		{"single chunk", s.request([][]string{sampleTexts[:1]}), shape(1)},
+		{"count parity", s.request([][]string{sampleTexts[:3], sampleTexts[3:4], sampleTexts[4:6]}), shape(3, 1, 2)},
+		{"empty chunk", s.request([][]string{sampleTexts[:1], {}, sampleTexts[1:2]}), shape(1, 0, 1)},
+		{"empty text", s.request([][]string{{"", sampleTexts[0]}}), shape(2)},
+		{"no chunks", s.request([][]string{}), shape()},
+		{"scores", s.scoresRequest(), checkScores},
+		{"error shape", map[string]any{"chunks": "not a list of chunks"}, checkError},
+	}
+
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		resp, err := s.invoke(ctx, c.payload)
+		if err == nil {
+			err = c.check(resp)
+		}
+		results = append(results, Result{Name: c.name, Err: err})
+	}
+	return results
+}
+
+// request builds a translation payload for chunks.
+func (s *Suite) request(chunks [][]string) map[string]any {
+	payload := map[string]any{"chunks": chunks}
+	if s.TargetLang != "" {
+		payload["target_lang"] = s.TargetLang
+	}
+	return payload
+}
+
+func (s *Suite) scoresRequest() map[string]any {
+	payload := s.request([][]string{sampleTexts[:2], sampleTexts[2:3]})
+	payload["return_scores"] = true
+	return payload
+}
+
+// invoke sends payload synchronously and decodes the reply.
+func (s *Suite) invoke(ctx context.Context, payload map[string]any) (*response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	out, err := s.Invoker.Invoke(ctx, &lambda.InvokeInput{FunctionName: &s.Function, Payload: data})
+	if err != nil {
+		return nil, fmt.Errorf("invoke failed: %w", err)
+	}
+	if out.FunctionError != nil {
+		return nil, fmt.Errorf("translator raised %s; report failures in the error field instead: %s", *out.FunctionError, out.Payload)
+	}
+
+	resp := &response{}
+	if err := json.Unmarshal(out.Payload, &resp.fields); err != nil {
+		return nil, fmt.Errorf("response is not a JSON object: %w", err)
+	}
+	if err := json.Unmarshal(out.Payload, &resp.TranslatorResponse); err != nil {
+		return nil, fmt.Errorf("response does not match the protocol types: %w", err)
+	}
+	return resp, nil
+}
+
+// checkFields rejects unknown top-level fields, which are usually misspelt
+// or camelCased protocol fields the router would silently ignore.
+func checkFields(resp *response) error {
+	var unknown []string
+	for name := range resp.fields {
+		if !responseFields[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown response fields %s", strings.Join(unknown, ", "))
+	}
+	return shape(1)(resp)
+}
+
+// shape expects a successful response with one chunk per size, each holding
+// that many translations.
+func shape(sizes ...int) func(*response) error {
+	return func(resp *response) error {
+		if resp.Error != "" {
+			return fmt.Errorf("unexpected translator error: %s", resp.Error)
+		}
+		if _, ok := resp.fields["translations"]; !ok || resp.Translations == nil {
+			return errors.New("translations is missing or null")
+		}
+		if len(resp.Translations) != len(sizes) {
+			return fmt.Errorf("got %d chunks, want %d", len(resp.Translations), len(sizes))
+		}
+		for i, size := range sizes {
+			if len(resp.Translations[i]) != size {
+				return fmt.Errorf("chunk %d has %d translations, want %d", i, len(resp.Translations[i]), size)
+			}
+		}
+		return nil
+	}
+}
+
+// checkScores accepts a response without scores, but scores that are
+// returned must line up with the translations and be log-probabilities.
+func checkScores(resp *response) error {
+	if err := shape(2, 1)(resp); err != nil {
+		return err
+	}
+	if resp.Scores == nil {
+		return nil
+	}
+	if len(resp.Scores) != len(resp.Translations) {
+		return fmt.Errorf("got %d score chunks, want %d", len(resp.Scores), len(resp.Translations))
+	}
+	for i, chunk := range resp.Scores {
+		if len(chunk) != len(resp.Translations[i]) {
+			return fmt.Errorf("score chunk %d has %d scores, want %d", i, len(chunk), len(resp.Translations[i]))
+		}
+		for j, score := range chunk {
+			if math.IsNaN(score) || math.IsInf(score, 0) || score > 0 {
+				return fmt.Errorf("scores[%d][%d] = %v is not a log-probability", i, j, score)
+			}
+		}
+	}
+	return nil
+}
+
+// checkError expects an invalid request to be answered with a non-empty
+// error string and no translations.
+func checkError(resp *response) error {
+	if resp.Error == "" {
+		return errors.New("invalid request was not answered with an error field")
+	}
+	if len(resp.Translations) > 0 {
+		return errors.New("error response should not carry translations")
+	}
+	return nil
+}
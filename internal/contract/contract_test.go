@@ -0,0 +1,127 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// stubTranslator implements the protocol by upper-casing texts; reply
+// post-processes each successful response to break the contract.
+type stubTranslator struct {
+	reply func(map[string]any) map[string]any
+	raise bool
+}
+
+func (s stubTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var req router.TranslatorRequest
+	resp := map[string]any{}
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		if s.raise {
+			errType := "Unhandled"
+			return &lambda.InvokeOutput{FunctionError: &errType, Payload: []byte(`{"errorMessage": "bad input"}`)}, nil
+		}
+		resp["error"] = "chunks must be a list of lists of strings"
+	} else {
+		translations := make([][]string, len(req.Chunks))
+		scores := make([][]float64, len(req.Chunks))
+		for i, chunk := range req.Chunks {
+			translations[i] = make([]string, len(chunk))
+			scores[i] = make([]float64, len(chunk))
+			for j, text := range chunk {
+				translations[i][j] = strings.ToUpper(text)
+				scores[i][j] = -0.5
+			}
+		}
+		resp["translations"] = translations
+		resp["model_version"] = "stub-1"
+		if req.ReturnScores {
+			resp["scores"] = scores
+		}
+		if s.reply != nil {
+			resp = s.reply(resp)
+		}
+	}
+	payload, err := json.Marshal(resp)
+	return &lambda.InvokeOutput{StatusCode: 200, Payload: payload}, err
+}
+
+func TestSuite_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		translator stubTranslator
+		wantFailed []string
+	}{
+		{name: "conforming translator", translator: stubTranslator{}},
+		{
+			name: "camelCase field",
+			translator: stubTranslator{reply: func(resp map[string]any) map[string]any {
+				resp["modelVersion"] = resp["model_version"]
+				delete(resp, "model_version")
+				return resp
+			}},
+			wantFailed: []string{"field names"},
+		},
+		{
+			name: "drops empty texts",
+			translator: stubTranslator{reply: func(resp map[string]any) map[string]any {
+				for i, chunk := range resp["translations"].([][]string) {
+					kept := []string{}
+					for _, text := range chunk {
+						if text != "" {
+							kept = append(kept, text)
+						}
+					}
+					resp["translations"].([][]string)[i] = kept
+				}
+				return resp
+			}},
+			wantFailed: []string{"empty text"},
+		},
+		{
+			name: "null translations for no chunks",
+			translator: stubTranslator{reply: func(resp map[string]any) map[string]any {
+				if len(resp["translations"].([][]string)) == 0 {
+					resp["translations"] = nil
+				}
+				return resp
+			}},
+			wantFailed: []string{"no chunks"},
+		},
+		{
+			name: "probabilities instead of log-probabilities",
+			translator: stubTranslator{reply: func(resp map[string]any) map[string]any {
+				if resp["scores"] != nil {
+					resp["scores"] = [][]float64{{0.9, 0.8}, {0.7}}
+				}
+				return resp
+			}},
+			wantFailed: []string{"scores"},
+		},
+		{
+			name:       "raises on invalid input",
+			translator: stubTranslator{raise: true},
+			wantFailed: []string{"error shape"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Suite{Invoker: tt.translator, Function: "pricofy-translator-stub", TargetLang: "es"}
+			var failed []string
+			for _, r := range s.Run(context.Background()) {
+				if r.Err != nil {
+					failed = append(failed, r.Name)
+				}
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("failed cases = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
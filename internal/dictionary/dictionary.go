@@ -0,0 +1,124 @@
+// Package dictionary translates single-word attribute values ("rojo", "neu",
+// "usado") from an embedded multilingual word list, so the most common
+// marketplace inputs skip machine translation and always get the same
+// answer.
+package dictionary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// concepts lists one meaning per row. Each language maps to its accepted
+// spellings separated by "|"; the first is the one produced when the
+// language is the target. Only words without a competing everyday meaning
+// are listed; a language without a safe word is left out of the row.
+var concepts = []map[string]string{
+	// Colours
+	{"en": "red", "es": "rojo|roja|rojos|rojas", "fr": "rouge|rouges", "it": "rosso|rossa|rossi|rosse", "pt": "vermelho|vermelha|vermelhos|vermelhas", "ca": "vermell|vermella|vermells|vermelles", "ro": "roșu|roșie|rosu|rosie", "de": "rot|rote|roter|rotes"},
+	{"en": "blue", "es": "azul|azules", "fr": "bleu|bleue|bleus|bleues", "it": "blu", "pt": "azul|azuis", "ca": "blau|blava|blaus|blaves", "ro": "albastru|albastră|albastra", "de": "blau|blaue|blauer|blaues"},
+	{"en": "green", "es": "verde|verdes", "fr": "vert|verte|verts|vertes", "it": "verde|verdi", "pt": "verde|verdes", "ca": "verd|verda|verds|verdes", "ro": "verde", "de": "grün|grüne|grüner|grünes"},
+	{"en": "yellow", "es": "amarillo|amarilla|amarillos|amarillas", "fr": "jaune|jaunes", "it": "giallo|gialla|gialli|gialle", "pt": "amarelo|amarela|amarelos|amarelas", "ca": "groc|groga|grocs|grogues", "ro": "galben|galbenă|galbena", "de": "gelb|gelbe|gelber|gelbes"},
+	{"en": "black", "es": "negro|negra|negros|negras", "fr": "noir|noire|noirs|noires", "it": "nero|nera|neri|nere", "pt": "preto|preta|pretos|pretas", "ca": "negre|negra|negres", "ro": "negru|neagră|neagra", "de": "schwarz|schwarze|schwarzer|schwarzes"},
+	{"en": "white", "es": "blanco|blanca|blancos|blancas", "fr": "blanc|blanche|blancs|blanches", "it": "bianco|bianca|bianchi|bianche", "pt": "branco|branca|brancos|brancas", "ca": "blanc|blanca|blancs|blanques", "ro": "alb|albă|alba", "de": "weiß|weiss|weiße|weißer|weißes"},
+	{"en": "grey|gray", "es": "gris|grises", "fr": "gris|grise|grises", "it": "grigio|grigia|grigi|grigie", "pt": "cinza|cinzento|cinzenta", "ca": "gris|grisa|grisos|grises", "ro": "gri", "de": "grau|graue|grauer|graues"},
+	{"en": "pink", "es": "rosa", "fr": "rose|roses", "it": "rosa", "pt": "rosa", "ca": "rosa", "ro": "roz", "de": "rosa|pink"},
+	{"en": "orange", "es": "naranja", "fr": "orange", "it": "arancione", "pt": "laranja", "ca": "taronja", "ro": "portocaliu", "de": "orange"},
+	{"en": "purple", "es": "morado|morada|morados|moradas", "fr": "violet|violette", "it": "viola", "pt": "roxo|roxa", "ca": "morat|morada", "ro": "mov", "de": "lila"},
+	{"en": "brown", "es": "marrón|marrones", "fr": "marron", "it": "marrone", "pt": "marrom|castanho", "ca": "marró", "ro": "maro", "de": "braun|braune|brauner|braunes"},
+	{"en": "beige", "es": "beige|beis", "fr": "beige", "it": "beige", "pt": "bege", "ca": "beix", "ro": "bej", "de": "beige"},
+
+	// Condition
+	{"en": "new", "es": "nuevo|nueva|nuevos|nuevas", "fr": "neuf|neuve|neufs|neuves", "it": "nuovo|nuova|nuovi|nuove", "pt": "novo|nova|novos|novas", "ca": "nou|nova|nous|noves", "ro": "nou|nouă", "de": "neu|neue|neuer|neues"},
+	{"en": "used", "es": "usado|usada|usados|usadas", "fr": "d'occasion|occasion|usagé|usagée", "it": "usato|usata|usati|usate", "pt": "usado|usada|usados|usadas", "ca": "usat|usada|usats|usades", "ro": "folosit|folosită|folosita", "de": "gebraucht|gebrauchte|gebrauchter|gebrauchtes"},
+	{"en": "refurbished", "es": "reacondicionado|reacondicionada", "fr": "reconditionné|reconditionnée", "it": "ricondizionato|ricondizionata", "pt": "recondicionado|recondicionada", "ca": "reacondicionat|reacondicionada", "ro": "recondiționat|reconditionat", "de": "generalüberholt"},
+
+	// Sizes
+	{"en": "small", "es": "pequeño|pequeña|pequeños|pequeñas", "fr": "petit|petite|petits|petites", "it": "piccolo|piccola|piccoli|piccole", "pt": "pequeno|pequena|pequenos|pequenas", "ca": "petit|petita|petits|petites", "ro": "mic|mică|mica", "de": "klein|kleine|kleiner|kleines"},
+	{"en": "medium", "es": "mediano|mediana", "fr": "moyen|moyenne", "ca": "mitjà|mitjana", "ro": "mediu|medie", "de": "mittel|mittelgroß"},
+	{"en": "large", "es": "grande|grandes", "fr": "grand|grande|grands|grandes", "it": "grande|grandi", "pt": "grande|grandes", "ca": "gran|grans", "de": "groß|große|großer|großes|gross"},
+
+	// Materials
+	{"en": "wood|wooden", "es": "madera", "fr": "bois", "it": "legno", "pt": "madeira", "ca": "fusta", "ro": "lemn", "de": "Holz"},
+	{"en": "leather", "es": "cuero", "fr": "cuir", "it": "pelle", "pt": "couro", "ca": "cuir", "ro": "piele", "de": "Leder"},
+	{"en": "cotton", "es": "algodón", "fr": "coton", "it": "cotone", "pt": "algodão", "ca": "cotó", "ro": "bumbac", "de": "Baumwolle"},
+	{"en": "metal", "es": "metal", "fr": "métal", "it": "metallo", "pt": "metal", "ca": "metall", "ro": "metal", "de": "Metall"},
+	{"en": "plastic", "es": "plástico", "fr": "plastique", "it": "plastica", "pt": "plástico", "ca": "plàstic", "ro": "plastic", "de": "Kunststoff|Plastik"},
+	{"en": "steel", "es": "acero", "fr": "acier", "it": "acciaio", "pt": "aço", "ca": "acer", "ro": "oțel|otel", "de": "Stahl"},
+	{"en": "wool", "es": "lana", "fr": "laine", "it": "lana", "pt": "lã", "ca": "llana", "ro": "lână", "de": "Wolle"},
+
+	// Answers
+	{"en": "yes", "es": "sí", "fr": "oui", "it": "sì", "pt": "sim", "ca": "sí", "ro": "da", "de": "ja"},
+	{"en": "no", "es": "no", "fr": "non", "it": "no", "pt": "não", "ca": "no", "ro": "nu", "de": "nein"},
+}
+
+// entry is a concept split into its spellings per language.
+type entry map[string][]string
+
+var (
+	entries []entry
+	// index maps a language and a lowercased spelling to its entry; -1
+	// marks spellings shared by several concepts, which are never looked up.
+	index = map[string]map[string]int{}
+)
+
+func init() {
+	for i, concept := range concepts {
+		e := entry{}
+		for lang, words := range concept {
+			e[lang] = strings.Split(words, "|")
+			if index[lang] == nil {
+				index[lang] = map[string]int{}
+			}
+			for _, w := range e[lang] {
+				key := strings.ToLower(w)
+				if j, ok := index[lang][key]; ok && j != i {
+					index[lang][key] = -1
+					continue
+				}
+				index[lang][key] = i
+			}
+		}
+		entries = append(entries, e)
+	}
+}
+
+// Lookup translates text when it is a single listed word of the source
+// language that the target language also lists. Languages are base codes
+// ("pt", not "pt_BR"). The result follows the capitalisation of the input
+// unless the input is written exactly as listed, so German "Holz" gives
+// "wood" while "ROJO" gives "RED".
+func Lookup(text, source, target string) (string, bool) {
+	word := strings.TrimSpace(text)
+	if word == "" || strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return "", false
+	}
+	i, ok := index[source][strings.ToLower(word)]
+	if !ok || i < 0 {
+		return "", false
+	}
+	forms, ok := entries[i][target]
+	if !ok {
+		return "", false
+	}
+	return matchCase(word, entries[i][source], forms[0]), true
+}
+
+// matchCase carries the capitalisation of word over to translation.
+func matchCase(word string, listed []string, translation string) string {
+	for _, l := range listed {
+		if l == word {
+			return translation
+		}
+	}
+	if utf8.RuneCountInString(word) > 1 && strings.ToUpper(word) == word {
+		return strings.ToUpper(translation)
+	}
+	first, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(first) {
+		return translation
+	}
+	r, size := utf8.DecodeRuneInString(translation)
+	return string(unicode.ToUpper(r)) + translation[size:]
+}
@@ -0,0 +1,61 @@
+package dictionary
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		source string
+		target string
+		want   string
+		wantOK bool
+	}{
+		{"colour", "rojo", "es", "en", "red", true},
+		{"inflected form", "rojas", "es", "fr", "rouge", true},
+		{"condition", "neu", "de", "es", "nuevo", true},
+		{"romance to romance", "usado", "pt", "it", "usato", true},
+		{"capitalised input", "Rojo", "es", "en", "Red", true},
+		{"upper-case input", "ROJO", "es", "en", "RED", true},
+		{"listed capitalisation", "Holz", "de", "en", "wood", true},
+		{"german noun target", "wood", "en", "de", "Holz", true},
+		{"surrounding whitespace", " azul ", "es", "en", "blue", true},
+		{"not listed", "zapato", "es", "en", "", false},
+		{"several words", "rojo oscuro", "es", "en", "", false},
+		{"missing in target", "medium", "en", "it", "", false},
+		{"unknown language", "red", "en", "xx", "", false},
+		{"empty", "", "es", "en", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.text, tt.source, tt.target)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Lookup(%q, %q, %q) = %q, %v, want %q, %v", tt.text, tt.source, tt.target, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestConcepts_Unambiguous(t *testing.T) {
+	for lang, words := range index {
+		for word, i := range words {
+			if i < 0 {
+				t.Errorf("%s %q is listed under several concepts", lang, word)
+			}
+		}
+	}
+	for _, concept := range concepts {
+		if _, ok := concept["en"]; !ok {
+			t.Errorf("concept %v has no English word", concept)
+		}
+		for lang, words := range concept {
+			if strings.TrimSpace(words) != words || strings.Contains(words, "||") {
+				t.Errorf("concept %v has malformed %s spellings %q", concept, lang, words)
+			}
+		}
+	}
+}
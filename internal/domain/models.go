@@ -1,27 +1,263 @@
-// Package domain contains the core domain types for the translation manager.
+// Package domain holds the request contracts of the translation manager:
+// the Request callers send and the TranslatorRequest and TranslatorResponse
+// exchanged with translator Lambdas. The handler and router packages alias
+// these types rather than declaring their own, so integrators have a single
+// canonical definition. The Response is assembled in the handler package
+// from the results of the other packages and is declared there.
 package domain
 
+import "github.com/pricofy/translation-manager/internal/routing"
+
+// SchemaVersion is the current version of the request contract. It changes
+// when a field is removed or changes meaning; added optional fields keep it.
+// The JSON Schema of every version is published under api/ (see
+// api.RequestSchemaVersion).
+const SchemaVersion = "v1"
+
+// SchemaVersions lists the request contract versions that are accepted,
+// oldest first.
+var SchemaVersions = []string{SchemaVersion}
+
 // Request is the input to the translation manager.
 type Request struct {
 	Texts      []string `json:"texts"`
 	SourceLang string   `json:"sourceLang"`
 	TargetLang string   `json:"targetLang"`
+
+	// SchemaVersion is the version of this contract the caller was built
+	// against, one of SchemaVersions; empty means SchemaVersion.
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
+	// Action is "translate" (default), "validate", "status", "languages"
+	// or "routes".
+	Action string `json:"action,omitempty"`
+
+	// AdminToken authenticates admin actions ("routes", "usage").
+	AdminToken string `json:"adminToken,omitempty"`
+	// RouteOp is the "routes" operation: list, add, update, disable or delete.
+	RouteOp string `json:"routeOp,omitempty"`
+	// RouteEntry is the routing table entry to add or update; disable and
+	// delete only use its id.
+	RouteEntry *routing.Entry `json:"routeEntry,omitempty"`
+	// UsageFrom and UsageTo are the first and last day ("2006-01-02", UTC)
+	// of a "usage" query, the current month by default.
+	UsageFrom string `json:"usageFrom,omitempty"`
+	UsageTo   string `json:"usageTo,omitempty"`
+
+	// Async runs the request as a background job; poll it with the
+	// "status" action and the returned JobID.
+	Async bool   `json:"async,omitempty"`
+	JobID string `json:"jobId,omitempty"`
+
+	// Mode "batch" translates the JSONL file at InputURI (s3://bucket/key)
+	// to JSONL parts under OutputPrefix as an async job, for inputs too
+	// large for a request payload. Mode "status" looks up the job JobID
+	// like the "status" action, and mode "redrive" translates the chunks
+	// of job JobID that failed and were dead-lettered again.
+	Mode         string `json:"mode,omitempty"`
+	InputURI     string `json:"inputUri,omitempty"`
+	OutputPrefix string `json:"outputPrefix,omitempty"`
+
+	// IdempotencyKey makes retries of the request return the response of
+	// the first successful attempt instead of translating again
+	// (IDEMPOTENCY_TABLE). Keys are scoped to the tenant.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+
+	// CacheOnly answers from the dictionary and translation cache alone and
+	// reports the other texts as CACHE_MISS warnings instead of invoking translators
+	// (also SERVE_FROM_CACHE_ONLY=true).
+	CacheOnly bool `json:"cacheOnly,omitempty"`
+
+	// StrictLanguages rejects languages that do not map to a supported code
+	// instead of falling back to the base language with a warning.
+	StrictLanguages bool `json:"strictLanguages,omitempty"`
+
+	// InvertedPairAction is "warn" (default) or "correct" when the texts look
+	// like the target language.
+	InvertedPairAction string `json:"invertedPairAction,omitempty"`
+
+	// TenantID identifies the calling tenant for per-tenant policies and
+	// default options (see handler/profiles.go).
+	TenantID string `json:"tenantId,omitempty"`
+
+	// CallerID identifies the calling service or team, such as
+	// "catalog-sync", to account its translation usage per day for
+	// chargeback (see handler/accounting.go). The "usage" action filters
+	// by it.
+	CallerID string `json:"callerId,omitempty"`
+
+	// Text is a single document to segment, translate and reassemble.
+	// It is an alternative to Texts.
+	Text string `json:"text,omitempty"`
+
+	// PO is a gettext PO catalog whose untranslated and fuzzy entries are
+	// translated, plural forms included, into Response.PO. It is an
+	// alternative to Texts and Text.
+	PO string `json:"po,omitempty"`
+
+	// IncludeIntermediate also returns the translations into every pivot
+	// language of multi-step routes, e.g. the English of es→en→de.
+	IncludeIntermediate bool `json:"includeIntermediate,omitempty"`
+
+	// IncludeConfidence returns per-text confidences when translators provide scores.
+	IncludeConfidence bool `json:"includeConfidence,omitempty"`
+	// MinConfidence (0-1) flags or withholds translations scoring below it.
+	MinConfidence *float64 `json:"minConfidence,omitempty"`
+	// LowConfidenceAction is "flag" (default) or "withhold".
+	LowConfidenceAction string `json:"lowConfidenceAction,omitempty"`
+
+	// LongTokenPolicy is "passthrough" (default) or "truncate" for
+	// unbreakable tokens too long to translate (URLs, blobs, SKUs).
+	LongTokenPolicy string `json:"longTokenPolicy,omitempty"`
+
+	// Placeholders is "on" or "off" (default). When on, interpolation
+	// tokens of UI strings ("{count}", "%s", "{{name}}", ICU arguments) are
+	// kept out of the translation, and translations that dropped one are
+	// flagged with a PLACEHOLDER_DROPPED warning.
+	Placeholders string `json:"placeholders,omitempty"`
+
+	// MeasurementPolicy is "preserve", "localize" or "convert" for
+	// measurement expressions such as "2.5 kg"; unset, they are translated
+	// as text. MeasurementSystem ("metric" or "imperial") overrides the
+	// target market's system for "convert".
+	MeasurementPolicy string `json:"measurementPolicy,omitempty"`
+	MeasurementSystem string `json:"measurementSystem,omitempty"`
+
+	// Markup names the tag syntax of the texts, "bbcode" or a grammar of
+	// the tenant; its tags are kept out of the translation.
+	Markup string `json:"markup,omitempty"`
+
+	// ContentType is "title", "description" or "bullet" and styles every
+	// translation for that marketplace field; ContentTypes sets it per text
+	// (same length as Texts, "" falls back to ContentType). TitleCasing is
+	// "title", "sentence" or "preserve" for titles; by default English
+	// titles are title-cased and others sentence-cased.
+	ContentType  string   `json:"contentType,omitempty"`
+	ContentTypes []string `json:"contentTypes,omitempty"`
+	TitleCasing  string   `json:"titleCasing,omitempty"`
+
+	// Tags marks texts (same length as Texts, "" for none) with a tag such
+	// as "legal" that the routing table may send to specialized translators;
+	// the rest of the batch keeps the default route.
+	Tags []string `json:"tags,omitempty"`
+
+	// Hashes are content hashes of Texts (same length, "" to have the
+	// manager fingerprint the text), such as the caller's hash of a
+	// listing. When set, Response.Versions says per translation which
+	// content and model version it was made from and when.
+	Hashes []string `json:"hashes,omitempty"`
+
+	// Dictionary is "on" (default) or "off". When on, texts that are a single
+	// common attribute word ("rojo", "neu") are answered from the embedded
+	// dictionary instead of the translators.
+	Dictionary string `json:"dictionary,omitempty"`
+
+	// Passthrough is "on" (default) or "off". When on, texts with nothing to
+	// translate (numbers, SKUs, URLs, email addresses, emoji) are returned
+	// as they are instead of going to the translators.
+	Passthrough string `json:"passthrough,omitempty"`
+
+	// ChunkStrategy is "sequential" (default), "balanced" (even token load
+	// per chunk; results still come back in input order) or "html" (texts
+	// are HTML; only their text is translated and the markup is kept).
+	ChunkStrategy string `json:"chunkStrategy,omitempty"`
+	// ChunkMaxTokens is the most estimated tokens a chunk may hold, for
+	// translators with more memory than the default budget (3000 tokens)
+	// is tuned for. Defaults to the smallest budget configured for the route
+	// (TRANSLATOR_CHUNK_TOKENS); sequential chunks then only cap texts.
+	ChunkMaxTokens int `json:"chunkMaxTokens,omitempty"`
+	// HTMLAttributes are the attributes translated in html chunking, e.g.
+	// ["alt", "title"] (default alt, title, placeholder and aria-label), and
+	// HTMLMeta the <meta> names or properties whose content is translated,
+	// e.g. ["description", "og:title"] (default description). An empty list
+	// translates none; data-* attributes are never translated.
+	HTMLAttributes []string `json:"htmlAttributes,omitempty"`
+	HTMLMeta       []string `json:"htmlMeta,omitempty"`
+
+	// Slugs returns a URL slug of every translation in Response.Slugs, cut to
+	// SlugMaxLength bytes (default 80).
+	Slugs         bool `json:"slugs,omitempty"`
+	SlugMaxLength int  `json:"slugMaxLength,omitempty"`
+
+	// TruncatedTo returns every translation cut to at most this many
+	// characters, ellipsis included, in Response.Truncated.
+	TruncatedTo int `json:"truncatedTo,omitempty"`
+
+	// MaxCost is the most the translation may cost under the translator
+	// cost model (TRANSLATOR_COSTS). Over budget, cheaper draft translators
+	// are used if they fit; otherwise the request fails with COST_EXCEEDED.
+	// 0 means no limit.
+	MaxCost float64 `json:"maxCost,omitempty"`
+
+	// TimeoutMs is the time budget of the translation in milliseconds,
+	// shared evenly among the route steps; over budget the request fails
+	// with TIMEOUT. Defaults to the time left in the invocation.
+	TimeoutMs int `json:"timeoutMs,omitempty"`
+
+	// Quality is "standard" (default) or "premium": premium listings are
+	// translated by the premium backend (DeepL by default), falling back
+	// to the translator Lambdas when it fails.
+	Quality string `json:"quality,omitempty"`
+	// Formality is "formal" or "informal" for backends that support it,
+	// such as DeepL and Bedrock; others translate as usual.
+	Formality string `json:"formality,omitempty"`
+	// MaskPII masks email addresses, phone numbers and IBANs before
+	// translation and restores them in the output. Requests that may reach
+	// an external backend such as DeepL are masked regardless (PII_MASKING).
+	MaskPII bool `json:"maskPii,omitempty"`
+
+	// Backend forces a translation backend: "lambda", "aws-translate",
+	// "deepl", "bedrock" or "dry-run". Defaults to the one TRANSLATION_BACKENDS
+	// configures for the pair, else the translator Lambdas.
+	Backend string `json:"backend,omitempty"`
+
+	// DryRun translates with the "dry-run" backend: deterministic
+	// pseudo-translations, without invoking any translator.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ErrorLocale is the language of error messages, e.g. "es" or "pt-BR".
+	// Defaults to English; Response.ErrorCode does not change with it.
+	ErrorLocale string `json:"errorLocale,omitempty"`
+
+	// Fields selects optional response groups: translations, pivot, debug, quality.
+	// Defaults to translations and quality.
+	Fields []string `json:"fields,omitempty"`
+
+	// Deprecations are the deprecated fields handler.ParseRequest mapped to their
+	// replacements; they are reported as DEPRECATED warnings.
+	Deprecations []Deprecation `json:"-"`
 }
 
-// Response is the output from the translation manager.
-type Response struct {
-	Translations    []string `json:"translations,omitempty"`
-	ChunksProcessed int      `json:"chunksProcessed,omitempty"`
-	Error           string   `json:"error,omitempty"`
+// Deprecation is a deprecated request field that handler.ParseRequest accepted and
+// rewrote to its current name.
+type Deprecation struct {
+	Field       string
+	Replacement string
 }
 
-// TranslatorRequest is the request format for translator Lambdas.
+// TranslatorRequest is the request format for translator Lambdas (chunked mode).
 type TranslatorRequest struct {
-	Texts []string `json:"texts"`
+	Chunks       [][]string `json:"chunks"`
+	TargetLang   string     `json:"target_lang,omitempty"`   // Required for en-romance
+	ReturnScores bool       `json:"return_scores,omitempty"` // Ask for per-text model scores
+	// ChunkIDs has a deterministic ID per chunk, the same on every retry of
+	// the call, for translators to deduplicate repeated work (see chunkIDs).
+	ChunkIDs []string `json:"chunk_ids,omitempty"`
+	// ResultBucket and ResultKey are set in event invocation mode: the
+	// translator writes its TranslatorResponse to s3://ResultBucket/ResultKey.
+	ResultBucket string `json:"result_bucket,omitempty"`
+	ResultKey    string `json:"result_key,omitempty"`
 }
 
-// TranslatorResponse is the response format from translator Lambdas.
+// TranslatorResponse is the response format from translator Lambdas (chunked mode).
+// Scores is optional: translators that support it return one length-normalized
+// log-probability per text (the mean log-probability of its output tokens),
+// with the same shape as Translations.
 type TranslatorResponse struct {
-	Translations []string `json:"translations"`
-	Error        string   `json:"error,omitempty"`
+	Translations [][]string  `json:"translations"`
+	Scores       [][]float64 `json:"scores,omitempty"`
+	ModelVersion string      `json:"model_version,omitempty"`
+	// Formats lists the payload formats the translator accepts besides JSON.
+	Formats []string `json:"formats,omitempty"`
+	Error   string   `json:"error,omitempty"`
 }
@@ -0,0 +1,148 @@
+// Package experiment assigns requests to translation strategy variants for
+// A/B testing, with consistent bucketing and kill switches.
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ConfigEnv names the environment variable holding the experiments as JSON
+// (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "EXPERIMENTS"
+
+// KillSwitchEnv disables every experiment when set to "true", without
+// touching the experiment definitions.
+const KillSwitchEnv = "EXPERIMENTS_KILL_SWITCH"
+
+// Variant is one strategy arm of an experiment.
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+
+	// MaxTextsPerChunk overrides the chunk size (0 keeps the default).
+	MaxTextsPerChunk int `json:"maxTextsPerChunk,omitempty"`
+	// FunctionOverrides replaces translator functions in the route, e.g. to
+	// send a step to another model, alias or provider.
+	FunctionOverrides map[string]string `json:"functionOverrides,omitempty"`
+}
+
+// Experiment splits eligible traffic between variants by weight.
+type Experiment struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Pairs and Tenants restrict eligibility ("es-fr", "acme"); empty means all.
+	Pairs    []string  `json:"pairs,omitempty"`
+	Tenants  []string  `json:"tenants,omitempty"`
+	Variants []Variant `json:"variants"`
+}
+
+// Config is the full experiments configuration.
+type Config struct {
+	// KillSwitch disables all experiments.
+	KillSwitch  bool         `json:"killSwitch,omitempty"`
+	Experiments []Experiment `json:"experiments"`
+}
+
+// Assignment is the variant a request was bucketed into.
+type Assignment struct {
+	Experiment string   `json:"experiment"`
+	Variant    string   `json:"variant"`
+	Settings   *Variant `json:"-"`
+}
+
+// Set holds validated experiments.
+type Set struct {
+	config Config
+}
+
+// New validates cfg into a Set.
+func New(cfg Config) (*Set, error) {
+	for _, e := range cfg.Experiments {
+		if e.Name == "" {
+			return nil, fmt.Errorf("experiment name is required")
+		}
+		if len(e.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %q: at least one variant is required", e.Name)
+		}
+		for _, v := range e.Variants {
+			if v.Name == "" || v.Weight < 0 || v.MaxTextsPerChunk < 0 {
+				return nil, fmt.Errorf("experiment %q: variants need a name and non-negative weight and chunk size", e.Name)
+			}
+		}
+		if totalWeight(e.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %q: variant weights must not all be zero", e.Name)
+		}
+	}
+	return &Set{config: cfg}, nil
+}
+
+// Load builds a Set from the EXPERIMENTS config and EXPERIMENTS_KILL_SWITCH.
+func Load() (*Set, error) {
+	var cfg Config
+	if _, err := config.LoadJSON(ConfigEnv, &cfg); err != nil {
+		return nil, err
+	}
+	if os.Getenv(KillSwitchEnv) == "true" {
+		cfg.KillSwitch = true
+	}
+	return New(cfg)
+}
+
+// Assign buckets a request into the first enabled experiment it is eligible
+// for. unit is the bucketing key (tenant ID, or a hash of the request), so
+// the same unit always gets the same variant. It returns nil when no
+// experiment applies.
+func (s *Set) Assign(pair, tenant, unit string) *Assignment {
+	if s == nil || s.config.KillSwitch {
+		return nil
+	}
+	for i := range s.config.Experiments {
+		e := &s.config.Experiments[i]
+		if !e.Enabled || !contains(e.Pairs, pair) || !contains(e.Tenants, tenant) {
+			continue
+		}
+		v := pick(e, unit)
+		return &Assignment{Experiment: e.Name, Variant: v.Name, Settings: v}
+	}
+	return nil
+}
+
+// pick chooses a variant by hashing the unit into the weight space.
+func pick(e *Experiment, unit string) *Variant {
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + ":" + unit)) //nolint:errcheck // hash writes never fail
+
+	bucket := int(h.Sum32() % uint32(totalWeight(e.Variants))) // #nosec G115 -- weights are small positive ints
+	for i := range e.Variants {
+		bucket -= e.Variants[i].Weight
+		if bucket < 0 {
+			return &e.Variants[i]
+		}
+	}
+	return &e.Variants[len(e.Variants)-1]
+}
+
+func totalWeight(variants []Variant) int {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	return total
+}
+
+// contains reports whether value is in list; an empty list matches anything.
+func contains(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
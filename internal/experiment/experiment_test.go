@@ -0,0 +1,110 @@
+package experiment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestSet(t *testing.T, cfg Config) *Set {
+	t.Helper()
+	set, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return set
+}
+
+var chunkExperiment = Experiment{
+	Name:    "chunk-size",
+	Enabled: true,
+	Pairs:   []string{"es-fr"},
+	Variants: []Variant{
+		{Name: "control", Weight: 50},
+		{Name: "small", Weight: 50, MaxTextsPerChunk: 25},
+	},
+}
+
+func TestAssign_Consistent(t *testing.T) {
+	set := newTestSet(t, Config{Experiments: []Experiment{chunkExperiment}})
+
+	first := set.Assign("es-fr", "acme", "acme")
+	for i := 0; i < 10; i++ {
+		if got := set.Assign("es-fr", "acme", "acme"); got.Variant != first.Variant {
+			t.Fatalf("Assign() not consistent: %q then %q", first.Variant, got.Variant)
+		}
+	}
+}
+
+func TestAssign_Distribution(t *testing.T) {
+	set := newTestSet(t, Config{Experiments: []Experiment{chunkExperiment}})
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		a := set.Assign("es-fr", "", fmt.Sprintf("unit-%d", i))
+		counts[a.Variant]++
+	}
+	for _, v := range []string{"control", "small"} {
+		if counts[v] < 400 || counts[v] > 600 {
+			t.Errorf("variant %q got %d of 1000 assignments, want ~500", v, counts[v])
+		}
+	}
+}
+
+func TestAssign_Eligibility(t *testing.T) {
+	disabled := chunkExperiment
+	disabled.Enabled = false
+	tenantOnly := chunkExperiment
+	tenantOnly.Pairs = nil
+	tenantOnly.Tenants = []string{"acme"}
+
+	tests := []struct {
+		name   string
+		cfg    Config
+		pair   string
+		tenant string
+		want   bool
+	}{
+		{"eligible", Config{Experiments: []Experiment{chunkExperiment}}, "es-fr", "", true},
+		{"other pair", Config{Experiments: []Experiment{chunkExperiment}}, "es-it", "", false},
+		{"disabled", Config{Experiments: []Experiment{disabled}}, "es-fr", "", false},
+		{"kill switch", Config{KillSwitch: true, Experiments: []Experiment{chunkExperiment}}, "es-fr", "", false},
+		{"tenant match", Config{Experiments: []Experiment{tenantOnly}}, "de-en", "acme", true},
+		{"tenant mismatch", Config{Experiments: []Experiment{tenantOnly}}, "de-en", "other", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTestSet(t, tt.cfg).Assign(tt.pair, tt.tenant, "unit")
+			if (got != nil) != tt.want {
+				t.Errorf("Assign() = %+v, want assigned=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_KillSwitchEnv(t *testing.T) {
+	t.Setenv(ConfigEnv, `{"experiments": [{"name": "e", "enabled": true, "variants": [{"name": "a", "weight": 1}]}]}`)
+	t.Setenv(KillSwitchEnv, "true")
+
+	set, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got := set.Assign("es-fr", "", "unit"); got != nil {
+		t.Errorf("kill switch should disable experiments, got %+v", got)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []Config{
+		{Experiments: []Experiment{{Name: "", Variants: []Variant{{Name: "a", Weight: 1}}}}},
+		{Experiments: []Experiment{{Name: "e"}}},
+		{Experiments: []Experiment{{Name: "e", Variants: []Variant{{Name: "a", Weight: 0}}}}},
+		{Experiments: []Experiment{{Name: "e", Variants: []Variant{{Name: "a", Weight: -1}}}}},
+	}
+	for i, cfg := range tests {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("config %d: New() should fail", i)
+		}
+	}
+}
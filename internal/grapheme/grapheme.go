@@ -0,0 +1,114 @@
+// Package grapheme measures text in user-perceived characters (grapheme
+// clusters), so that "é" written with a combining accent, a flag or a family
+// emoji count as one character each, as they do on screen.
+//
+// Cluster boundaries follow the main rules of Unicode UAX #29: combining
+// marks, variation selectors, emoji modifiers and tags extend the previous
+// character; zero-width joiners glue emoji together; regional indicators
+// pair into flags; CRLF and decomposed Hangul syllables stay whole.
+package grapheme
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const zwj = '\u200d' // zero-width joiner
+
+// Next returns the byte length of the first grapheme cluster of s, 0 when s
+// is empty. Invalid UTF-8 bytes are clusters of their own.
+func Next(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || r == utf8.RuneError {
+		return size
+	}
+	if r == '\r' && size < len(s) && s[size] == '\n' {
+		return size + 1
+	}
+	if isControl(r) {
+		return size
+	}
+
+	end := size
+	if isRegionalIndicator(r) {
+		if next, n := utf8.DecodeRuneInString(s[end:]); isRegionalIndicator(next) {
+			end += n
+		}
+	}
+
+	prev := r
+	for end < len(s) {
+		next, n := utf8.DecodeRuneInString(s[end:])
+		switch {
+		case next == zwj:
+			end += n
+			if after, m := utf8.DecodeRuneInString(s[end:]); isPictographic(after) {
+				end += m
+				next = after
+			}
+		case isExtend(next), isHangulJamo(prev) && isHangulVowelOrTrail(next):
+			end += n
+		default:
+			return end
+		}
+		prev = next
+	}
+	return end
+}
+
+// Count returns the number of grapheme clusters in s.
+func Count(s string) int {
+	n := 0
+	for s != "" {
+		s = s[Next(s):]
+		n++
+	}
+	return n
+}
+
+// Truncate returns the first n grapheme clusters of s, never cutting a
+// character in half.
+func Truncate(s string, n int) string {
+	end := 0
+	for i := 0; i < n && end < len(s); i++ {
+		end += Next(s[end:])
+	}
+	return s[:end]
+}
+
+// isExtend reports whether r attaches to the preceding character.
+func isExtend(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // tags of subdivision flags
+		return true
+	}
+	return false
+}
+
+func isControl(r rune) bool {
+	return r == '\n' || r == '\r' || unicode.IsControl(r)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isPictographic approximates Extended_Pictographic: symbols and the emoji
+// blocks.
+func isPictographic(r rune) bool {
+	return unicode.Is(unicode.So, r) || r >= 0x1f000 && r <= 0x1faff
+}
+
+// isHangulJamo reports whether r is a conjoining Hangul jamo or a
+// precomposed syllable that a trailing jamo may extend.
+func isHangulJamo(r rune) bool {
+	return r >= 0x1100 && r <= 0x11ff || r >= 0xac00 && r <= 0xd7a3
+}
+
+func isHangulVowelOrTrail(r rune) bool {
+	return r >= 0x1160 && r <= 0x11ff
+}
@@ -0,0 +1,59 @@
+package grapheme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "iPhone 12", 9},
+		{"precomposed accents", "Camión añil", 11},
+		{"combining accent", "Cami\u0301on", 6},
+		{"emoji", "¡Oferta! 🔥", 10},
+		{"skin tone", "👍🏽", 1},
+		{"family", "👨\u200d👩\u200d👧", 1},
+		{"flags", "🇪🇸🇫🇷", 2},
+		{"odd regional indicator", "🇪🇸🇫", 2},
+		{"subdivision flag", "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", 1},
+		{"variation selector", "❤\ufe0f", 1},
+		{"crlf", "a\r\nb", 3},
+		{"decomposed hangul", "\u1100\u1161\u11a8", 1},
+		{"invalid utf-8", "a\xffb", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.text); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"Camión", 4, "Cami"},
+		{"Cami\u0301on", 4, "Cami\u0301"},
+		{"🇪🇸🇫🇷", 1, "🇪🇸"},
+		{"👍🏽 ok", 1, "👍🏽"},
+		{"short", 10, "short"},
+		{"abc", 0, ""},
+		{strings.Repeat("ñ", 300), 200, strings.Repeat("ñ", 200)},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.text, tt.n); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
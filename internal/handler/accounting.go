@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/pricofy/translation-manager/internal/accounting"
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// ActionUsage reports the translation usage per caller and day, for
+// chargeback (admin only).
+const ActionUsage = "usage"
+
+// maxUsageDays bounds the days of a usage query.
+const maxUsageDays = 366
+
+// loadLedger opens the usage ledger, nil when USAGE_TABLE is not set.
+func loadLedger(cfg aws.Config) *accounting.Ledger {
+	table := os.Getenv(accounting.TableEnv)
+	if table == "" {
+		return nil
+	}
+	return accounting.New(dynamodb.NewFromConfig(cfg), table)
+}
+
+// callerUsage measures the work of a translation of req: its texts, and
+// the characters and estimated tokens of the chunks sent to translators.
+func callerUsage(req Request, chunks [][]string) accounting.Usage {
+	u := accounting.Usage{CallerID: req.CallerID, Requests: 1, Texts: int64(len(req.Texts))}
+	for _, chunk := range chunks {
+		for _, text := range chunk {
+			u.Characters += int64(utf8.RuneCountInString(text))
+			u.Tokens += int64(chunker.EstimateTokensIn(text, req.SourceLang))
+		}
+	}
+	return u
+}
+
+// recordCallerUsage reports the usage of a translation with a callerId as
+// metrics per caller and adds it to the usage table when enabled. Dry runs
+// translate nothing and are not accounted. Accounting is best effort: a
+// failure is logged and never fails the translation.
+func (h *Handler) recordCallerUsage(ctx context.Context, rec *metrics.Recorder, req Request, chunks [][]string, result *router.Result) {
+	if req.CallerID == "" || len(result.Steps) > 0 && result.Steps[0] == router.BackendDryRun {
+		return
+	}
+	u := callerUsage(req, chunks)
+	dims := metrics.Dimensions{"Caller": req.CallerID}
+	rec.Add("CallerCharacters", metrics.Count, float64(u.Characters), dims)
+	rec.Add("CallerTokens", metrics.Count, float64(u.Tokens), dims)
+
+	if h.ledger == nil {
+		return
+	}
+	if err := h.ledger.Record(ctx, u); err != nil {
+		slog.WarnContext(ctx, "usage accounting failed", slog.String("callerId", req.CallerID), slog.Any("error", err))
+	}
+}
+
+// handleUsage serves the usage action for authenticated admins.
+func (h *Handler) handleUsage(ctx context.Context, req Request) *Response {
+	if _, ok := h.authenticateAdmin(req.AdminToken); !ok {
+		return errorResponse(ErrorUnauthorized)
+	}
+	if h.ledger == nil {
+		return errorResponse(ErrorUnavailable, fmt.Sprintf("usage accounting is not enabled (%s is not set)", accounting.TableEnv))
+	}
+	from, to := usageRange(req, time.Now())
+	usage, err := h.ledger.Query(ctx, req.CallerID, from, to)
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+	return &Response{Translations: []string{}, Usage: usage}
+}
+
+// usageRange returns the days of a usage query: UsageFrom and UsageTo,
+// by default the first day of the month of UsageTo and today.
+func usageRange(req Request, now time.Time) (from, to string) {
+	to = req.UsageTo
+	if to == "" {
+		to = now.UTC().Format(accounting.DayLayout)
+	}
+	from = req.UsageFrom
+	if from == "" {
+		from = to[:len("2006-01")] + "-01"
+	}
+	return from, to
+}
+
+// validateCallerID checks Request.CallerID.
+func validateCallerID(req Request) error {
+	if req.CallerID != "" && !accounting.CallerPattern.MatchString(req.CallerID) {
+		return fmt.Errorf("callerId %q is not 1-64 letters, digits, '.', '_' or '-'", req.CallerID)
+	}
+	return nil
+}
+
+// validateUsageRequest checks the caller and days of a usage query.
+func validateUsageRequest(req Request) error {
+	if err := validateCallerID(req); err != nil {
+		return err
+	}
+	for name, day := range map[string]string{"usageFrom": req.UsageFrom, "usageTo": req.UsageTo} {
+		if _, err := time.Parse(accounting.DayLayout, day); day != "" && err != nil {
+			return fmt.Errorf("%s %q is not a date (YYYY-MM-DD)", name, day)
+		}
+	}
+	from, to := usageRange(req, time.Now())
+	first, _ := time.Parse(accounting.DayLayout, from)
+	last, _ := time.Parse(accounting.DayLayout, to)
+	switch {
+	case last.Before(first):
+		return fmt.Errorf("usageFrom %s is after usageTo %s", from, to)
+	case last.Sub(first) >= maxUsageDays*24*time.Hour:
+		return fmt.Errorf("usage queries cover at most %d days", maxUsageDays)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/accounting"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestCallerUsage(t *testing.T) {
+	req := Request{CallerID: "catalog-sync", SourceLang: "es", Texts: []string{"Buen estado", "rojo", "Nuevo"}}
+	got := callerUsage(req, [][]string{{"Buen estado", "Nuevo"}})
+	if got.CallerID != "catalog-sync" || got.Requests != 1 || got.Texts != 3 || got.Characters != 16 || got.Tokens <= 0 {
+		t.Errorf("callerUsage() = %+v, want 1 request, 3 texts and 16 characters of tokens", got)
+	}
+}
+
+func TestRecordCallerUsage(t *testing.T) {
+	h := &Handler{}
+	tests := []struct {
+		name   string
+		req    Request
+		result *router.Result
+		want   bool
+	}{
+		{"caller", Request{CallerID: "search", SourceLang: "es"}, &router.Result{Steps: []string{"romance-en"}}, true},
+		{"no caller", Request{SourceLang: "es"}, &router.Result{Steps: []string{"romance-en"}}, false},
+		{"dry run", Request{CallerID: "search", SourceLang: "es"}, &router.Result{Steps: []string{router.BackendDryRun}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rec := metrics.New(&buf)
+			h.recordCallerUsage(context.Background(), rec, tt.req, [][]string{{"Buen estado"}}, tt.result)
+			if err := rec.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Contains(buf.String(), `"CallerCharacters":11`) && strings.Contains(buf.String(), `"Caller":"search"`)
+			if got != tt.want {
+				t.Errorf("caller metrics recorded = %v, want %v: %s", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestUsageRange(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		req      Request
+		from, to string
+	}{
+		{"month to date", Request{}, "2026-03-01", "2026-03-14"},
+		{"month of usageTo", Request{UsageTo: "2026-02-10"}, "2026-02-01", "2026-02-10"},
+		{"explicit", Request{UsageFrom: "2026-01-05", UsageTo: "2026-01-06"}, "2026-01-05", "2026-01-06"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if from, to := usageRange(tt.req, now); from != tt.from || to != tt.to {
+				t.Errorf("usageRange() = %s, %s, want %s, %s", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestValidateUsageRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr string
+	}{
+		{"defaults", Request{Action: ActionUsage}, ""},
+		{"caller and days", Request{Action: ActionUsage, CallerID: "search", UsageFrom: "2026-01-01", UsageTo: "2026-01-31"}, ""},
+		{"invalid caller", Request{Action: ActionUsage, CallerID: "a b"}, "callerId"},
+		{"invalid day", Request{Action: ActionUsage, UsageFrom: "01/02/2026"}, "not a date"},
+		{"reversed", Request{Action: ActionUsage, UsageFrom: "2026-02-01", UsageTo: "2026-01-01"}, "is after"},
+		{"too long", Request{Action: ActionUsage, UsageFrom: "2024-01-01", UsageTo: "2026-01-01"}, "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRequest(tt.req)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateRequest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleUsage(t *testing.T) {
+	h := &Handler{}
+	useAdmin(t, h)
+	ctx := context.Background()
+
+	if resp := h.handleUsage(ctx, Request{Action: ActionUsage, AdminToken: "wrong"}); resp.ErrorCode != ErrorUnauthorized {
+		t.Fatalf("handleUsage() with a wrong token error code = %q, want %s", resp.ErrorCode, ErrorUnauthorized)
+	}
+	resp := h.handleUsage(ctx, Request{Action: ActionUsage, AdminToken: "s3cret"})
+	if resp.ErrorCode != ErrorUnavailable || !strings.Contains(resp.Error.Message, accounting.TableEnv) {
+		t.Errorf("handleUsage() without a usage table = %+v, want %s", resp.Error, ErrorUnavailable)
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// ActionRoutes manages the runtime routing table (admin only).
+const ActionRoutes = "routes"
+
+// Routing table operations, Request.RouteOp.
+const (
+	RouteList    = "list"
+	RouteAdd     = "add"
+	RouteUpdate  = "update"
+	RouteDisable = "disable"
+	RouteDelete  = "delete"
+)
+
+// AdminTokensEnv holds the admin tokens as a JSON object of name → SHA-256
+// hex digest of the token, so plaintext tokens never sit in configuration.
+// The name identifies the admin in audit logs.
+const AdminTokensEnv = "ADMIN_TOKENS"
+
+// auditLog receives one JSON line per admin action, allowed or not.
+var auditLog io.Writer = os.Stdout
+
+// auditRecord is an audit log line.
+type auditRecord struct {
+	Audit    string         `json:"audit"`
+	Op       string         `json:"op"`
+	Actor    string         `json:"actor,omitempty"`
+	Allowed  bool           `json:"allowed"`
+	Entry    *routing.Entry `json:"entry,omitempty"`
+	Previous *routing.Entry `json:"previous,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Time     time.Time      `json:"time"`
+}
+
+// loadAdminTokens reads the configured admin token digests.
+func loadAdminTokens() (map[string]string, error) {
+	var tokens map[string]string
+	if _, err := appconfig.LoadJSON(AdminTokensEnv, &tokens); err != nil {
+		return nil, fmt.Errorf("invalid admin tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// authenticateAdmin returns the name of the admin owning token.
+func (h *Handler) authenticateAdmin(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	digest := sha256.Sum256([]byte(token))
+	given := hex.EncodeToString(digest[:])
+	for name, want := range h.adminTokens {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(want)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// loadRoutes opens the routing table, nil when ROUTING_TABLE is not set.
+func loadRoutes(cfg aws.Config) *routing.Store {
+	table := os.Getenv(routing.TableEnv)
+	if table == "" {
+		return nil
+	}
+	return routing.NewStore(dynamodb.NewFromConfig(cfg), table)
+}
+
+// routingTable returns the routing table store.
+func (h *Handler) routingTable() (*routing.Store, error) {
+	if h.routes == nil {
+		return nil, fmt.Errorf("the routing table is not enabled (%s is not set)", routing.TableEnv)
+	}
+	return h.routes, nil
+}
+
+// handleRoutes serves the routes action for authenticated admins.
+func (h *Handler) handleRoutes(ctx context.Context, req Request) *Response {
+	actor, ok := h.authenticateAdmin(req.AdminToken)
+	if !ok {
+		audit(ctx, auditRecord{Op: req.RouteOp, Error: "unauthorized"})
+		return errorResponse(ErrorUnauthorized)
+	}
+
+	store, err := h.routingTable()
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+
+	if req.RouteOp == RouteList {
+		entries, err := store.List(ctx)
+		if err != nil {
+			return errorResponse(ErrorUnavailable, err.Error())
+		}
+		return &Response{Translations: []string{}, Routes: entries}
+	}
+
+	entry, previous, err := changeRoute(ctx, store, req, actor)
+	audit(ctx, auditRecord{Op: req.RouteOp, Actor: actor, Allowed: true, Entry: entry, Previous: previous, Error: errorString(err)})
+	switch {
+	case errors.Is(err, routing.ErrNotFound), errors.Is(err, routing.ErrExists):
+		return errorResponse(ErrorInvalidRequest, err.Error())
+	case err != nil:
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+
+	router.InvalidateRoutes()
+	resp := &Response{Translations: []string{}}
+	if entry != nil {
+		resp.Routes = []routing.Entry{*entry}
+	}
+	return resp
+}
+
+// changeRoute applies an add, update, disable or delete operation. It
+// returns the entry as written (nil for deletes) and as it was before.
+func changeRoute(ctx context.Context, store *routing.Store, req Request, actor string) (*routing.Entry, *routing.Entry, error) {
+	id := req.RouteEntry.ID
+	if req.RouteOp == RouteDelete {
+		previous, err := store.Delete(ctx, id)
+		return nil, &previous, err
+	}
+
+	var previous *routing.Entry
+	entry := *req.RouteEntry
+	if req.RouteOp != RouteAdd {
+		current, err := store.Get(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		previous = &current
+		if req.RouteOp == RouteDisable {
+			entry = current
+			entry.Enabled = false
+		}
+	}
+	entry.UpdatedAt = time.Now().UTC()
+	entry.UpdatedBy = actor
+
+	if req.RouteOp == RouteAdd {
+		return &entry, nil, store.Add(ctx, entry)
+	}
+	return &entry, previous, store.Update(ctx, entry)
+}
+
+// audit writes an audit log line.
+func audit(ctx context.Context, r auditRecord) {
+	r.Audit = ActionRoutes
+	r.Time = time.Now().UTC()
+	if err := json.NewEncoder(auditLog).Encode(r); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit log", slog.String("op", r.Op), slog.Any("error", err))
+	}
+}
+
+// errorString returns err's message, or "".
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// validateRoutesRequest checks a routes action before authentication.
+func validateRoutesRequest(req Request) error {
+	switch req.RouteOp {
+	case RouteList:
+		return nil
+	case RouteDisable, RouteDelete:
+		if req.RouteEntry == nil || req.RouteEntry.ID == "" {
+			return fmt.Errorf("routeEntry.id is required for %s", req.RouteOp)
+		}
+		return nil
+	case RouteAdd, RouteUpdate:
+		if req.RouteEntry == nil {
+			return fmt.Errorf("routeEntry is required for %s", req.RouteOp)
+		}
+		return validateRouteEntry(req.RouteEntry)
+	default:
+		return fmt.Errorf("routeOp must be one of: %s, %s, %s, %s, %s", RouteList, RouteAdd, RouteUpdate, RouteDisable, RouteDelete)
+	}
+}
+
+// validateRouteEntry checks an entry to be written.
+func validateRouteEntry(e *routing.Entry) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("routeEntry: %w", err)
+	}
+	for _, lang := range []string{e.SourceLang, e.TargetLang} {
+		if !router.IsSupportedLanguage(lang) {
+			return fmt.Errorf("routeEntry: unsupported language %q", lang)
+		}
+	}
+	if e.Backend != "" && (e.Backend == router.BackendLambda || !router.IsBackend(e.Backend)) {
+		return fmt.Errorf("routeEntry: unknown backend %q", e.Backend)
+	}
+	return nil
+}
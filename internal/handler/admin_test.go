@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// routeTable is a minimal in-memory routing table without condition checks
+// beyond the existence tests the store relies on.
+type routeTable map[string]map[string]types.AttributeValue
+
+func (m routeTable) Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	out := &dynamodb.ScanOutput{}
+	for _, item := range m {
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+func (m routeTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m[in.Key["id"].(*types.AttributeValueMemberS).Value]}, nil
+}
+
+func (m routeTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	id := in.Item["id"].(*types.AttributeValueMemberS).Value
+	if _, exists := m[id]; exists == (*in.ConditionExpression == "attribute_not_exists(id)") {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	m[id] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m routeTable) DeleteItem(_ context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	id := in.Key["id"].(*types.AttributeValueMemberS).Value
+	old, ok := m[id]
+	if !ok {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	delete(m, id)
+	return &dynamodb.DeleteItemOutput{Attributes: old}, nil
+}
+
+// useAdmin gives h one admin token and an in-memory routing table, and
+// captures the audit log.
+func useAdmin(t *testing.T, h *Handler) *bytes.Buffer {
+	t.Helper()
+	digest := sha256.Sum256([]byte("s3cret"))
+	h.adminTokens = map[string]string{"oncall": hex.EncodeToString(digest[:])}
+	h.routes = routing.NewStore(routeTable{}, "routes")
+
+	var logs bytes.Buffer
+	previous := auditLog
+	auditLog = &logs
+	t.Cleanup(func() { auditLog = previous })
+	return &logs
+}
+
+func TestValidateRoutesRequest(t *testing.T) {
+	entry := &routing.Entry{ID: "es-en", SourceLang: "es", TargetLang: "en", Function: "pricofy-translator-es-en", Weight: 1}
+
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"list", Request{RouteOp: RouteList}, false},
+		{"add", Request{RouteOp: RouteAdd, RouteEntry: entry}, false},
+		{"disable by id", Request{RouteOp: RouteDisable, RouteEntry: &routing.Entry{ID: "es-en"}}, false},
+		{"unknown op", Request{RouteOp: "purge"}, true},
+		{"add without entry", Request{RouteOp: RouteAdd}, true},
+		{"delete without id", Request{RouteOp: RouteDelete, RouteEntry: &routing.Entry{}}, true},
+		{"unsupported language", Request{RouteOp: RouteUpdate, RouteEntry: &routing.Entry{
+			ID: "zh-en", SourceLang: "zh", TargetLang: "en", Function: "f", Weight: 1,
+		}}, true},
+		{"tagged backend", Request{RouteOp: RouteAdd, RouteEntry: &routing.Entry{
+			ID: "es-en-legal", SourceLang: "es", TargetLang: "en", Backend: router.BackendDeepL, Tag: "legal", Weight: 1,
+		}}, false},
+		{"unknown backend", Request{RouteOp: RouteAdd, RouteEntry: &routing.Entry{
+			ID: "es-en-legal", SourceLang: "es", TargetLang: "en", Backend: "babelfish", Weight: 1,
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Action = ActionRoutes
+			if err := validateRequest(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleRoutes(t *testing.T) {
+	h := &Handler{}
+	logs := useAdmin(t, h)
+	ctx := context.Background()
+	entry := &routing.Entry{ID: "es-en", SourceLang: "es", TargetLang: "en", Function: "pricofy-translator-es-en", Qualifier: "live", Weight: 1, Enabled: true}
+
+	resp := h.handleRoutes(ctx, Request{Action: ActionRoutes, RouteOp: RouteAdd, RouteEntry: entry, AdminToken: "wrong"})
+	if resp.ErrorCode != ErrorUnauthorized {
+		t.Fatalf("bad token: ErrorCode = %q, want %s", resp.ErrorCode, ErrorUnauthorized)
+	}
+
+	steps := []struct {
+		op       string
+		wantCode string
+		check    func(*Response) bool
+	}{
+		{RouteAdd, "", func(r *Response) bool { return r.Routes[0].UpdatedBy == "oncall" }},
+		{RouteAdd, ErrorInvalidRequest, nil},
+		{RouteDisable, "", func(r *Response) bool { return !r.Routes[0].Enabled && r.Routes[0].Qualifier == "live" }},
+		{RouteList, "", func(r *Response) bool { return len(r.Routes) == 1 && !r.Routes[0].Enabled }},
+		{RouteDelete, "", func(r *Response) bool { return len(r.Routes) == 0 }},
+		{RouteDelete, ErrorInvalidRequest, nil},
+	}
+	for _, s := range steps {
+		resp := h.handleRoutes(ctx, Request{Action: ActionRoutes, RouteOp: s.op, RouteEntry: entry, AdminToken: "s3cret"})
+		if resp.ErrorCode != s.wantCode {
+			t.Fatalf("%s: ErrorCode = %q (%s), want %q", s.op, resp.ErrorCode, resp.Error, s.wantCode)
+		}
+		if s.check != nil && !s.check(resp) {
+			t.Errorf("%s: unexpected response %+v", s.op, resp.Routes)
+		}
+	}
+
+	// Every change and the rejected attempt are audited
+	dec := json.NewDecoder(logs)
+	var records []auditRecord
+	for dec.More() {
+		var r auditRecord
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 6 {
+		t.Fatalf("got %d audit records, want 6", len(records))
+	}
+	if records[0].Allowed || records[1].Actor != "oncall" || records[3].Previous == nil || !records[3].Previous.Enabled {
+		t.Errorf("audit records = %+v", records)
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pricofy/translation-manager/internal/batch"
+)
+
+// ModeBatch translates a JSONL file in S3 (Request.InputURI) to JSONL
+// parts under Request.OutputPrefix, as an async job whose progress the
+// "status" action reports.
+const ModeBatch = "batch"
+
+// loadBatches creates the batch runner, which needs BATCH_PROGRESS_TABLE to
+// resume jobs across invocations: it is nil when the table is not set.
+func loadBatches(cfg aws.Config) *batch.Runner {
+	table := os.Getenv(batch.ProgressTableEnv)
+	if table == "" {
+		return nil
+	}
+	return batch.NewRunner(s3.NewFromConfig(cfg), batch.NewProgressStore(dynamodb.NewFromConfig(cfg), table))
+}
+
+// batchRunner returns the batch runner.
+func (h *Handler) batchRunner() (*batch.Runner, error) {
+	if h.batches == nil {
+		return nil, fmt.Errorf("batch mode is not enabled (%s is not set)", batch.ProgressTableEnv)
+	}
+	return h.batches, nil
+}
+
+// validateBatch checks Request.Mode and the batch fields.
+func validateBatch(req Request) error {
+	if req.Mode != "" && req.Mode != ModeBatch {
+		return fmt.Errorf("unknown mode %q", req.Mode)
+	}
+	if req.Mode != ModeBatch {
+		if req.InputURI != "" || req.OutputPrefix != "" {
+			return fmt.Errorf("inputUri and outputPrefix require mode %q", ModeBatch)
+		}
+		return nil
+	}
+	switch {
+	case req.Texts != nil || req.Text != "":
+		return fmt.Errorf("mode %q reads its texts from inputUri, not texts or text", ModeBatch)
+	case len(req.ContentTypes) > 0 || len(req.Tags) > 0 || len(req.Hashes) > 0:
+		return fmt.Errorf("mode %q does not support per-text contentTypes, tags or hashes", ModeBatch)
+	case req.Action != "" && req.Action != ActionTranslate:
+		return fmt.Errorf("mode %q only supports the translate action", ModeBatch)
+	}
+	if _, err := batch.ParseURI(req.InputURI); err != nil {
+		return fmt.Errorf("inputUri: %w", err)
+	}
+	if _, err := batch.ParseURI(req.OutputPrefix); err != nil {
+		return fmt.Errorf("outputPrefix: %w", err)
+	}
+	return nil
+}
+
+// batchJob returns the batch of a job run. Validation checked the URIs.
+func batchJob(req Request) batch.Job {
+	input, _ := batch.ParseURI(req.InputURI)      //nolint:errcheck // validated
+	output, _ := batch.ParseURI(req.OutputPrefix) //nolint:errcheck // validated
+	return batch.Job{ID: req.JobID, Input: input, Output: output}
+}
+
+// translateBatch runs a batch job: every part of the input is translated
+// as a request with req's options. A run that pauses before the deadline,
+// or whose part failed in a way a retry may get past, returns an error so
+// the job is delivered again and resumes; other failures are stored as the
+// result of the job.
+func (h *Handler) translateBatch(ctx context.Context, req Request, _ time.Time) (*Response, error) {
+	r, err := h.batchRunner()
+	if err != nil {
+		return h.batchResult(ctx, req, errorResponse(ErrorUnavailable, err.Error()))
+	}
+
+	part := req
+	part.Mode, part.InputURI, part.OutputPrefix = "", "", ""
+	part.Async, part.JobID, part.IdempotencyKey = false, "", ""
+	progress, err := r.Run(ctx, batchJob(req), func(ctx context.Context, texts []string) ([]string, error) {
+		part.Texts = texts
+		resp, err := h.translate(ctx, part, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Translations, nil
+	})
+
+	var failure *ErrorInfo
+	switch {
+	case err == nil:
+		return h.batchResult(ctx, req, &Response{Translations: []string{}, Batch: progress})
+	case errors.Is(err, batch.ErrPaused):
+		slog.InfoContext(ctx, "batch paused", slog.String("jobId", req.JobID), slog.Int("lines", progress.Lines))
+		return nil, err
+	case errors.As(err, &failure) && failure.Retryable:
+		return nil, err
+	case progress == nil:
+		return nil, err
+	}
+	if progress.Status != batch.StatusFailed {
+		if err := r.Fail(ctx, progress, err); err != nil {
+			return nil, err
+		}
+	}
+	resp := errorResponse(ErrorTranslationFailed, err.Error())
+	if failure != nil {
+		resp = &Response{Error: failure, ErrorCode: failure.Code}
+	}
+	resp.Batch = progress
+	return h.batchResult(ctx, req, resp)
+}
+
+// batchResult stores resp as the result of the batch job.
+func (h *Handler) batchResult(ctx context.Context, req Request, resp *Response) (*Response, error) {
+	if err := h.finishJob(ctx, req, resp); err != nil {
+		return nil, fmt.Errorf("failed to store job %s: %w", req.JobID, err)
+	}
+	return resp, nil
+}
+
+// batchProgress returns the saved progress of a batch job, or nil when
+// batch mode is off or the job is not a batch.
+func (j *jobRunner) batchProgress(ctx context.Context, id string) *batch.Progress {
+	if j.batches == nil {
+		return nil
+	}
+	p, err := j.batches.Progress(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "batch progress lookup failed", slog.String("jobId", id), slog.Any("error", err))
+		return nil
+	}
+	return p
+}
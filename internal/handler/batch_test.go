@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/batch"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+)
+
+// progressTable is an in-memory batch progress table.
+type progressTable map[string]map[string]dynamotypes.AttributeValue
+
+func (t progressTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: t[attrString(in.Key, "id")]}, nil
+}
+
+func (t progressTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	t[attrString(in.Item, "id")] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestValidateBatch(t *testing.T) {
+	base := Request{SourceLang: "es", TargetLang: "en", Mode: ModeBatch, InputURI: "s3://in/catalog.jsonl", OutputPrefix: "s3://out/sync/"}
+
+	tests := []struct {
+		name    string
+		edit    func(*Request)
+		wantErr bool
+	}{
+		{"batch", func(*Request) {}, false},
+		{"unknown mode", func(r *Request) { r.Mode = "bulk" }, true},
+		{"texts", func(r *Request) { r.Texts = []string{"Hola"} }, true},
+		{"tags", func(r *Request) { r.Tags = []string{"legal"} }, true},
+		{"validate action", func(r *Request) { r.Action = ActionValidate }, true},
+		{"input not in s3", func(r *Request) { r.InputURI = "https://example.com/in.jsonl" }, true},
+		{"missing output", func(r *Request) { r.OutputPrefix = "" }, true},
+		{"uris without batch", func(r *Request) { r.Mode = ""; r.Texts = []string{"Hola"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			tt.edit(&req)
+			if err := validateRequest(req); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJobStatus_BatchProgress(t *testing.T) {
+	table := progressTable{}
+	store := batch.NewProgressStore(table, "progress")
+	jobs := &jobRunner{store: resultstore.New(memoryBucket{}, "bucket", jobsPrefix), batches: batch.NewRunner(nil, store)}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, &batch.Progress{JobID: "job-1", Status: batch.StatusRunning, Lines: 1500, Parts: 3}); err != nil {
+		t.Fatal(err)
+	}
+	resp := jobs.status(ctx, "job-1")
+	if resp.Status != JobPending || resp.Batch == nil || resp.Batch.Lines != 1500 || resp.Batch.Parts != 3 {
+		t.Errorf("status() = %+v, batch %+v, want the saved progress", resp, resp.Batch)
+	}
+	if resp := jobs.status(ctx, "job-2"); resp.Batch != nil {
+		t.Errorf("status() of a plain job has batch %+v", resp.Batch)
+	}
+}
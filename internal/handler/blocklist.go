@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// applyBlocklist enforces the tenant's forbidden terms on translations and
+// records a BlockedTranslations metric per action.
+func applyBlocklist(list *blocklist.List, req Request, translations []string, rec *metrics.Recorder) []blocklist.Match {
+	if req.TenantID == "" {
+		return nil
+	}
+	matches := list.Apply(req.TenantID, req.TargetLang, translations)
+	for _, m := range matches {
+		rec.Add("BlockedTranslations", metrics.Count, 1, metrics.Dimensions{
+			"Tenant": req.TenantID,
+			"Action": string(m.Action),
+		})
+	}
+	return matches
+}
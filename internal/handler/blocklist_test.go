@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+func TestApplyBlocklist(t *testing.T) {
+	list, err := blocklist.New(map[string]blocklist.Policy{
+		"acme": {Action: blocklist.Reject, Terms: map[string][]string{"fr": {"miracle"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+
+	translations := []string{"produit miracle"}
+	if matches := applyBlocklist(list, Request{TargetLang: "fr"}, translations, rec); matches != nil {
+		t.Errorf("requests without tenantId should not be checked, got %+v", matches)
+	}
+
+	matches := applyBlocklist(list, Request{TenantID: "acme", TargetLang: "fr"}, translations, rec)
+	if len(matches) != 1 || translations[0] != "" {
+		t.Errorf("matches = %+v, translations = %q", matches, translations)
+	}
+
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"BlockedTranslations":1`) {
+		t.Errorf("missing BlockedTranslations metric: %s", buf.String())
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// ServeFromCacheOnlyEnv set to "true" puts every request in cache-only
+// mode, e.g. while the translator Lambdas are down.
+const ServeFromCacheOnlyEnv = "SERVE_FROM_CACHE_ONLY"
+
+// cacheOnly reports whether req is answered from the dictionary and the
+// translation cache alone, without invoking translators.
+func cacheOnly(req Request) bool {
+	return req.CacheOnly || os.Getenv(ServeFromCacheOnlyEnv) == "true"
+}
+
+// translateChunks runs the translators. In cache-only mode it answers every
+// chunk with empty translations instead; cacheMisses reports them.
+func translateChunks(ctx context.Context, r Translator, req Request, chunks [][]string, opts router.Options) (*router.Result, error) {
+	if !cacheOnly(req) {
+		return r.TranslateChunksWithOptions(ctx, req.SourceLang, req.TargetLang, chunks, opts)
+	}
+	translations := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		translations[i] = make([]string, len(chunk))
+	}
+	return &router.Result{Translations: translations}, nil
+}
+
+// cacheMisses returns a CACHE_MISS warning for every text a cache-only
+// request could not answer. Their translations are empty.
+func cacheMisses(req Request, known knownTexts) []Warning {
+	if !cacheOnly(req) {
+		return nil
+	}
+	var warnings []Warning
+	for i, text := range req.Texts {
+		if _, ok := known.hits[i]; ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		index := i
+		warnings = append(warnings, Warning{
+			Code:    WarningCacheMiss,
+			Message: fmt.Sprintf("text %d is not in the dictionary or translation cache and translators are not used in cache-only mode", i),
+			Index:   &index,
+		})
+	}
+	return warnings
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestTranslateChunks_CacheOnly(t *testing.T) {
+	chunks := [][]string{{"Camiseta", "Pantalón"}, {"Zapato"}}
+	result, err := translateChunks(context.Background(), nil, Request{CacheOnly: true}, chunks, router.Options{})
+	if err != nil {
+		t.Fatalf("translateChunks() error = %v", err)
+	}
+	want := [][]string{{"", ""}, {""}}
+	if !reflect.DeepEqual(result.Translations, want) {
+		t.Errorf("translateChunks() = %q, want %q", result.Translations, want)
+	}
+	if len(result.Steps) != 0 {
+		t.Errorf("translateChunks() steps = %v, want none", result.Steps)
+	}
+}
+
+func TestCacheMisses(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         Request
+		env         string
+		wantIndices []int
+	}{
+		{
+			name:        "request flag",
+			req:         Request{Texts: []string{"rojo", "Camiseta", "", "usado"}, SourceLang: "es", TargetLang: "en", CacheOnly: true},
+			wantIndices: []int{1},
+		},
+		{
+			name:        "environment",
+			req:         Request{Texts: []string{"Camiseta", "azul"}, SourceLang: "es", TargetLang: "en"},
+			env:         "true",
+			wantIndices: []int{0},
+		},
+		{
+			name: "normal mode",
+			req:  Request{Texts: []string{"Camiseta"}, SourceLang: "es", TargetLang: "en"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ServeFromCacheOnlyEnv, tt.env)
+			req := tt.req
+			known := (&Handler{}).takeKnownTexts(context.Background(), &req, nil, nil)
+			known.merge(&req, make([]string, len(req.Texts)))
+
+			var got []int
+			for _, w := range cacheMisses(req, known) {
+				if w.Code != WarningCacheMiss || w.Index == nil {
+					t.Fatalf("cacheMisses() warning = %+v, want an indexed %s", w, WarningCacheMiss)
+				}
+				got = append(got, *w.Index)
+			}
+			if !reflect.DeepEqual(got, tt.wantIndices) {
+				t.Errorf("cacheMisses() indices = %v, want %v", got, tt.wantIndices)
+			}
+		})
+	}
+}
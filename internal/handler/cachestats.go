@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// ActionCacheStats reports the translation cache statistics of the
+// container serving the request (admin only).
+const ActionCacheStats = "cacheStats"
+
+// Outcomes of a translation cache lookup, per text.
+const (
+	// CacheHit is a text answered from the cache.
+	CacheHit = "hit"
+	// CacheMiss is a text the cache does not know, or knows without the
+	// score the request needs.
+	CacheMiss = "miss"
+	// CacheStale is a text whose cached translation has expired.
+	CacheStale = "stale"
+	// CacheBypass is a text not looked up, as for experiment variants.
+	CacheBypass = "bypass"
+)
+
+// lengthBuckets are the upper bounds (characters) of the text length
+// buckets of cache metrics; longer texts are "500+".
+var lengthBuckets = []struct {
+	max  int
+	name string
+}{
+	{20, "0-20"},
+	{100, "21-100"},
+	{500, "101-500"},
+}
+
+// lengthBucket returns the length bucket of text.
+func lengthBucket(text string) string {
+	n := utf8.RuneCountInString(text)
+	for _, b := range lengthBuckets {
+		if n <= b.max {
+			return b.name
+		}
+	}
+	return "500+"
+}
+
+// CacheCounts counts translation cache lookups by outcome.
+type CacheCounts struct {
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+	Stale    int64 `json:"stale"`
+	Bypassed int64 `json:"bypassed"`
+	// HitRate is the share of hits among the texts looked up.
+	HitRate float64 `json:"hitRate"`
+}
+
+// add counts n lookups with outcome.
+func (c *CacheCounts) add(outcome string, n int64) {
+	switch outcome {
+	case CacheHit:
+		c.Hits += n
+	case CacheMiss:
+		c.Misses += n
+	case CacheStale:
+		c.Stale += n
+	case CacheBypass:
+		c.Bypassed += n
+	}
+	if looked := c.Hits + c.Misses + c.Stale; looked > 0 {
+		c.HitRate = float64(c.Hits) / float64(looked)
+	}
+}
+
+// CacheStats are the translation cache lookups of one container since it
+// started, in total and by language pair, tenant and text length bucket.
+type CacheStats struct {
+	// Enabled reports whether TRANSLATION_CACHE_TABLE is set.
+	Enabled bool                   `json:"enabled"`
+	Since   time.Time              `json:"since"`
+	Total   CacheCounts            `json:"total"`
+	Pairs   map[string]CacheCounts `json:"pairs,omitempty"`
+	Tenants map[string]CacheCounts `json:"tenants,omitempty"`
+	Lengths map[string]CacheCounts `json:"lengths,omitempty"`
+}
+
+// cacheStatsCollector accumulates the statistics of the container.
+type cacheStatsCollector struct {
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+var containerCacheStats = newCacheStatsCollector(time.Now())
+
+func newCacheStatsCollector(since time.Time) *cacheStatsCollector {
+	return &cacheStatsCollector{stats: CacheStats{
+		Since:   since.UTC(),
+		Pairs:   map[string]CacheCounts{},
+		Tenants: map[string]CacheCounts{},
+		Lengths: map[string]CacheCounts{},
+	}}
+}
+
+// observe counts n lookups of one pair, tenant and length bucket.
+func (c *cacheStatsCollector) observe(pair, tenant, length, outcome string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Total.add(outcome, n)
+	for _, group := range []struct {
+		counts map[string]CacheCounts
+		key    string
+	}{
+		{c.stats.Pairs, pair},
+		{c.stats.Tenants, tenant},
+		{c.stats.Lengths, length},
+	} {
+		counts := group.counts[group.key]
+		counts.add(outcome, n)
+		group.counts[group.key] = counts
+	}
+}
+
+// snapshot returns a copy of the statistics.
+func (c *cacheStatsCollector) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Pairs, s.Tenants, s.Lengths = copyCounts(s.Pairs), copyCounts(s.Tenants), copyCounts(s.Lengths)
+	return s
+}
+
+func copyCounts(m map[string]CacheCounts) map[string]CacheCounts {
+	out := make(map[string]CacheCounts, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// recordCacheLookups records the outcome of every text looked up in the
+// translation cache as CacheLookups metrics, by language pair, tenant,
+// length bucket and outcome, and in the container statistics.
+func recordCacheLookups(rec *metrics.Recorder, req Request, texts []string, outcomes []string) {
+	type group struct{ length, outcome string }
+	counts := map[group]int64{}
+	for i, outcome := range outcomes {
+		if outcome != "" {
+			counts[group{lengthBucket(texts[i]), outcome}]++
+		}
+	}
+	pair, tenant := languagePair(req), req.TenantID
+	if tenant == "" {
+		tenant = "none"
+	}
+	for g, n := range counts {
+		rec.Add("CacheLookups", metrics.Count, float64(n), metrics.Dimensions{
+			"LanguagePair": pair,
+			"Tenant":       tenant,
+			"Length":       g.length,
+			"Outcome":      g.outcome,
+		})
+		containerCacheStats.observe(pair, tenant, g.length, g.outcome, n)
+	}
+}
+
+// handleCacheStats serves the cacheStats action for authenticated admins.
+func (h *Handler) handleCacheStats(req Request) *Response {
+	if _, ok := h.authenticateAdmin(req.AdminToken); !ok {
+		return errorResponse(ErrorUnauthorized)
+	}
+	stats := containerCacheStats.snapshot()
+	stats.Enabled = os.Getenv(cache.TableEnv) != ""
+	return &Response{Translations: []string{}, CacheStats: &stats}
+}
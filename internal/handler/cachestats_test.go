@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+func TestLengthBucket(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: "0-20"},
+		{text: strings.Repeat("a", 20), want: "0-20"},
+		{text: strings.Repeat("ñ", 21), want: "21-100"},
+		{text: strings.Repeat("a", 500), want: "101-500"},
+		{text: strings.Repeat("a", 501), want: "500+"},
+	}
+
+	for _, tt := range tests {
+		if got := lengthBucket(tt.text); got != tt.want {
+			t.Errorf("lengthBucket(%d chars) = %q, want %q", len(tt.text), got, tt.want)
+		}
+	}
+}
+
+func TestCacheOutcome(t *testing.T) {
+	tests := []struct {
+		name                  string
+		usable, stale, bypass bool
+		want                  string
+	}{
+		{name: "hit", usable: true, want: CacheHit},
+		{name: "miss", want: CacheMiss},
+		{name: "stale", stale: true, want: CacheStale},
+		{name: "bypass wins", usable: true, bypass: true, want: CacheBypass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheOutcome(tt.usable, tt.stale, tt.bypass); got != tt.want {
+				t.Errorf("cacheOutcome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheStatsCollector(t *testing.T) {
+	c := newCacheStatsCollector(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	c.observe("es-en", "acme", "0-20", CacheHit, 3)
+	c.observe("es-en", "none", "21-100", CacheMiss, 1)
+	c.observe("es-fr", "acme", "0-20", CacheBypass, 2)
+
+	s := c.snapshot()
+	want := CacheCounts{Hits: 3, Misses: 1, Bypassed: 2, HitRate: 0.75}
+	if s.Total != want {
+		t.Errorf("total = %+v, want %+v", s.Total, want)
+	}
+	if got := s.Pairs["es-en"]; got.Hits != 3 || got.Misses != 1 {
+		t.Errorf("pairs[es-en] = %+v, want 3 hits and 1 miss", got)
+	}
+	if got := s.Tenants["acme"]; got.Hits != 3 || got.Bypassed != 2 {
+		t.Errorf("tenants[acme] = %+v, want 3 hits and 2 bypassed", got)
+	}
+	if got := s.Pairs["es-fr"].HitRate; got != 0 {
+		t.Errorf("bypassed pair hit rate = %v, want 0", got)
+	}
+
+	// Snapshots do not share maps with the collector
+	s.Pairs["es-en"] = CacheCounts{}
+	if c.snapshot().Pairs["es-en"].Hits != 3 {
+		t.Error("snapshot() shares its maps with the collector")
+	}
+}
+
+func TestRecordCacheLookups(t *testing.T) {
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+	req := Request{SourceLang: "es", TargetLang: "en"}
+	texts := []string{"rojo", "azul", "Camiseta de algodón orgánico con cuello redondo"}
+
+	recordCacheLookups(rec, req, texts, []string{CacheHit, "", CacheStale})
+
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"CacheLookups":1`, `"Tenant":"none"`, `"Outcome":"stale"`, `"Length":"21-100"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %s: %s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `"Outcome":""`) {
+		t.Errorf("texts that were not looked up were recorded: %s", buf.String())
+	}
+}
+
+func TestHandleCacheStats(t *testing.T) {
+	h := &Handler{}
+	useAdmin(t, h)
+
+	if resp := h.handleCacheStats(Request{Action: ActionCacheStats, AdminToken: "wrong"}); resp.ErrorCode != ErrorUnauthorized {
+		t.Fatalf("handleCacheStats() with a wrong token error code = %q, want %s", resp.ErrorCode, ErrorUnauthorized)
+	}
+
+	t.Setenv(cache.TableEnv, "")
+	resp := h.handleCacheStats(Request{Action: ActionCacheStats, AdminToken: "s3cret"})
+	if resp.Error != nil {
+		t.Fatalf("handleCacheStats() error = %s", resp.Error)
+	}
+	if resp.CacheStats == nil || resp.CacheStats.Enabled {
+		t.Errorf("handleCacheStats() stats = %+v, want disabled stats", resp.CacheStats)
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/notify"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// canaryActor is the audit identity of automatic canary rollbacks.
+const canaryActor = "canary-monitor"
+
+// canaryRollback is the audit operation and alarm type of a rollback.
+const canaryRollback = "canaryRollback"
+
+// canaries watches the canary routes served by this container.
+var canaries = routing.NewMonitor()
+
+// loadAlarms creates the alarm notifier, nil when ALARM_TOPIC_ARN is not
+// set.
+func loadAlarms(cfg aws.Config) *notify.Notifier {
+	topic := os.Getenv(notify.AlarmTopicEnv)
+	if topic == "" {
+		return nil
+	}
+	return notify.New(sns.NewFromConfig(cfg), topic)
+}
+
+// observeRoute records the outcome of a translation served by a routing
+// table entry, rolling the entry back when it is a canary out of bounds.
+func (h *Handler) observeRoute(ctx context.Context, result *router.Result, err error, confidence []float64, rec *metrics.Recorder) {
+	entry, outcome := routeOutcome(result, err, confidence)
+	if v := canaries.Observe(entry, outcome); v != nil {
+		h.rollbackCanary(ctx, entry, v, rec)
+	}
+}
+
+// routeOutcome returns the routing entry behind a translation and its outcome.
+func routeOutcome(result *router.Result, err error, confidence []float64) (*routing.Entry, routing.Outcome) {
+	var entryErr *router.EntryError
+	if errors.As(err, &entryErr) {
+		return entryErr.Entry, routing.Outcome{Failed: true}
+	}
+	if result == nil {
+		return nil, routing.Outcome{}
+	}
+	return result.Entry, routing.Outcome{Confidence: confidence}
+}
+
+// rollbackCanary disables a canary entry, then audits and announces it.
+// Failures are logged: the canary keeps its traffic until the next verdict.
+func (h *Handler) rollbackCanary(ctx context.Context, entry *routing.Entry, v *routing.Verdict, rec *metrics.Recorder) {
+	slog.WarnContext(ctx, "canary tripped", slog.String("routeEntry", entry.ID), slog.String("reason", v.Reason))
+	rec.Add("CanaryRollbacks", metrics.Count, 1, metrics.Dimensions{"RouteEntry": entry.ID})
+
+	store, err := h.routingTable()
+	if err != nil {
+		slog.ErrorContext(ctx, "canary rollback failed", slog.String("routeEntry", entry.ID), slog.Any("error", err))
+		return
+	}
+	current, err := store.Get(ctx, entry.ID)
+	if err != nil || !current.Enabled {
+		// Already rolled back (e.g. by another container) or gone
+		return
+	}
+
+	disabled := current
+	disabled.Enabled = false
+	disabled.UpdatedAt = time.Now().UTC()
+	disabled.UpdatedBy = canaryActor
+	err = store.Update(ctx, disabled)
+	audit(ctx, auditRecord{Op: canaryRollback, Actor: canaryActor, Allowed: true, Entry: &disabled, Previous: &current, Reason: v.Reason, Error: errorString(err)})
+	if err != nil {
+		slog.ErrorContext(ctx, "canary rollback failed", slog.String("routeEntry", entry.ID), slog.Any("error", err))
+		return
+	}
+	router.InvalidateRoutes()
+
+	err = h.alarms.PublishAlarm(ctx, notify.Alarm{
+		Type:       canaryRollback,
+		SourceLang: entry.SourceLang,
+		TargetLang: entry.TargetLang,
+		Subject:    entry.ID,
+		Reason:     v.Reason,
+		Details:    v,
+		Time:       disabled.UpdatedAt,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "canary alarm failed", slog.String("routeEntry", entry.ID), slog.Any("error", err))
+	}
+}
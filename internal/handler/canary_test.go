@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+func TestRouteOutcome(t *testing.T) {
+	entry := &routing.Entry{ID: "canary"}
+
+	got, outcome := routeOutcome(nil, &router.EntryError{Entry: entry, Err: errors.New("boom")}, nil)
+	if got != entry || !outcome.Failed {
+		t.Errorf("entry failure = %v, %+v", got, outcome)
+	}
+	got, outcome = routeOutcome(&router.Result{Entry: entry}, nil, []float64{0.9})
+	if got != entry || outcome.Failed || len(outcome.Confidence) != 1 {
+		t.Errorf("entry success = %v, %+v", got, outcome)
+	}
+	if got, _ := routeOutcome(nil, errors.New("built-in route failed"), nil); got != nil {
+		t.Errorf("built-in failure attributed to %v", got)
+	}
+}
+
+func TestObserveRoute_Rollback(t *testing.T) {
+	h := &Handler{}
+	logs := useAdmin(t, h)
+	ctx := context.Background()
+	entry := routing.Entry{
+		ID: "es-en-v2", SourceLang: "es", TargetLang: "en", Function: "pricofy-translator-es-en", Qualifier: "v2",
+		Weight: 10, Enabled: true, Canary: &routing.Canary{MaxErrorRate: 0.5, MinRequests: 2},
+	}
+	if err := h.routes.Add(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := metrics.New(io.Discard)
+	failure := &router.EntryError{Entry: &entry, Err: errors.New("model crashed")}
+	h.observeRoute(ctx, nil, failure, nil, rec)
+	h.observeRoute(ctx, nil, failure, nil, rec)
+
+	got, err := h.routes.Get(ctx, entry.ID)
+	if err != nil || got.Enabled || got.UpdatedBy != canaryActor {
+		t.Fatalf("after rollback: %+v, %v", got, err)
+	}
+
+	var record auditRecord
+	if err := json.NewDecoder(logs).Decode(&record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Op != canaryRollback || record.Reason == "" || !record.Previous.Enabled || record.Entry.Enabled {
+		t.Errorf("audit record = %+v", record)
+	}
+}
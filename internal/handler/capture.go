@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pricofy/translation-manager/internal/capture"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// loadCapture creates the capture sampler, nil when capture is disabled.
+// The S3 client is only created when capture is on.
+func loadCapture(cfg aws.Config) (*capture.Sampler, error) {
+	if os.Getenv(capture.BucketEnv) == "" {
+		return nil, nil
+	}
+	sampler, err := capture.FromEnv(s3.NewFromConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay capture: %w", err)
+	}
+	return sampler, nil
+}
+
+// captureTranslations samples the request into the evaluation corpus.
+// Capture never fails the request; problems are logged.
+func (h *Handler) captureTranslations(ctx context.Context, req Request, translations []string, result *router.Result) {
+	// Nothing to replay when no translator ran (dictionary or cache-only answers)
+	if len(result.Steps) == 0 {
+		return
+	}
+	records := h.sampler.Sample(req.SourceLang, req.TargetLang, req.Texts, translations,
+		result.Steps, routeModelVersion(result))
+	if err := h.sampler.Upload(ctx, records); err != nil {
+		slog.WarnContext(ctx, "capture failed", slog.String("languagePair", languagePair(req)), slog.Any("error", err))
+	}
+}
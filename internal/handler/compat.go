@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pricofy/translation-manager/internal/domain"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// Deprecation is a deprecated request field ParseRequest rewrote (see
+// domain.Deprecation).
+type Deprecation = domain.Deprecation
+
+// renamedFields lists the request fields renamed since callers adopted
+// them, oldest first. Entries stay until the metrics show no caller still
+// sends the old name.
+var renamedFields = []Deprecation{
+	{Field: "sourceLanguage", Replacement: "sourceLang"},
+	{Field: "targetLanguage", Replacement: "targetLang"},
+	{Field: "includeScores", Replacement: "includeConfidence"},
+}
+
+// upgradeRequest rewrites the deprecated fields of a raw request to their
+// current names, so the rest of the handler only sees the current model.
+// Sending both the old and the new name is an error.
+func upgradeRequest(event json.RawMessage) (json.RawMessage, []Deprecation, error) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(event, &obj) != nil {
+		return event, nil, nil // the schema reports the problem
+	}
+
+	var found []Deprecation
+	for _, d := range renamedFields {
+		value, ok := obj[d.Field]
+		if !ok {
+			continue
+		}
+		if _, ok := obj[d.Replacement]; ok {
+			return nil, nil, fmt.Errorf("%s: deprecated name of %s, send only %s", d.Field, d.Replacement, d.Replacement)
+		}
+		obj[d.Replacement] = value
+		delete(obj, d.Field)
+		found = append(found, d)
+	}
+	if found == nil {
+		return event, nil, nil
+	}
+	upgraded, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return upgraded, found, nil
+}
+
+// deprecationWarnings reports the deprecated fields of req as DEPRECATED
+// warnings and counts them in the DeprecatedFields metric per field, so
+// the callers still using them can be found before the names are removed.
+func deprecationWarnings(req Request) []Warning {
+	if len(req.Deprecations) == 0 {
+		return nil
+	}
+	rec := metrics.New(os.Stdout)
+	defer rec.Flush() //nolint:errcheck // metrics are best effort
+
+	warnings := make([]Warning, len(req.Deprecations))
+	for i, d := range req.Deprecations {
+		rec.Add("DeprecatedFields", metrics.Count, 1, metrics.Dimensions{"Field": d.Field})
+		warnings[i] = Warning{
+			Code:    WarningDeprecated,
+			Message: fmt.Sprintf("%s is deprecated and will be removed; use %s", d.Field, d.Replacement),
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRequest_Deprecated(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      string
+		wantFields []string
+		wantErr    string
+	}{
+		{
+			name:       "renamed languages",
+			event:      `{"texts": ["Hola"], "sourceLanguage": "es", "targetLanguage": "fr"}`,
+			wantFields: []string{"sourceLanguage", "targetLanguage"},
+		},
+		{
+			name:       "renamed option",
+			event:      `{"texts": ["Hola"], "sourceLang": "es", "targetLang": "fr", "includeScores": true}`,
+			wantFields: []string{"includeScores"},
+		},
+		{
+			name:  "current shape",
+			event: `{"texts": ["Hola"], "sourceLang": "es", "targetLang": "fr"}`,
+		},
+		{
+			name:    "old and new name",
+			event:   `{"texts": ["Hola"], "sourceLanguage": "es", "sourceLang": "es", "targetLang": "fr"}`,
+			wantErr: "sourceLanguage: deprecated name of sourceLang, send only sourceLang",
+		},
+		{
+			name:    "renamed field validated under its new name",
+			event:   `{"texts": ["Hola"], "sourceLang": "es", "targetLang": "fr", "includeScores": "yes"}`,
+			wantErr: "includeConfidence: must be a boolean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRequest([]byte(tt.event))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseRequest() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRequest() unexpected error: %v", err)
+			}
+			if req.SourceLang != "es" || req.TargetLang != "fr" {
+				t.Errorf("ParseRequest() pair = %s-%s, want es-fr", req.SourceLang, req.TargetLang)
+			}
+			var fields []string
+			for _, d := range req.Deprecations {
+				fields = append(fields, d.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("ParseRequest() deprecations = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+
+	req, _ := ParseRequest([]byte(`{"texts": ["Hola"], "sourceLang": "es", "targetLang": "fr", "includeScores": true}`))
+	if !req.IncludeConfidence {
+		t.Error("ParseRequest() did not map includeScores to includeConfidence")
+	}
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	req := Request{Deprecations: []Deprecation{{Field: "sourceLanguage", Replacement: "sourceLang"}}}
+	warnings := deprecationWarnings(req)
+	if len(warnings) != 1 || warnings[0].Code != WarningDeprecated {
+		t.Fatalf("deprecationWarnings() = %+v, want one %s warning", warnings, WarningDeprecated)
+	}
+	if !strings.Contains(warnings[0].Message, "use sourceLang") {
+		t.Errorf("deprecationWarnings() message = %q, want the replacement", warnings[0].Message)
+	}
+	if warnings := deprecationWarnings(Request{}); warnings != nil {
+		t.Errorf("deprecationWarnings() = %+v for a current request, want none", warnings)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+)
+
+// Low-confidence actions accepted in Request.LowConfidenceAction.
+const (
+	// LowConfidenceFlag keeps the translation and lists its index in LowConfidence.
+	LowConfidenceFlag = "flag"
+	// LowConfidenceWithhold replaces the translation with an empty string.
+	LowConfidenceWithhold = "withhold"
+)
+
+// validateConfidenceOptions checks the confidence-related request options.
+func validateConfidenceOptions(req Request) error {
+	if req.MinConfidence != nil && (*req.MinConfidence < 0 || *req.MinConfidence > 1) {
+		return fmt.Errorf("minConfidence must be between 0 and 1")
+	}
+	switch req.LowConfidenceAction {
+	case "", LowConfidenceFlag, LowConfidenceWithhold:
+		return nil
+	default:
+		return fmt.Errorf("lowConfidenceAction must be %q or %q", LowConfidenceFlag, LowConfidenceWithhold)
+	}
+}
+
+// wantsScores reports whether translator scores should be requested.
+func wantsScores(req Request) bool {
+	return req.IncludeConfidence || req.MinConfidence != nil
+}
+
+// requestsScores reports whether translator scores are needed, for
+// confidences or an explicit quality field group.
+func requestsScores(req Request) bool {
+	return wantsScores(req) || (len(req.Fields) > 0 && fieldSet(req.Fields)[FieldQuality])
+}
+
+// toConfidence converts route scores (router.Result.Scores, the sum of the
+// steps' length-normalized log-probabilities) into 0-1 confidences.
+func toConfidence(logProbs []float64) []float64 {
+	confidence := make([]float64, len(logProbs))
+	for i, lp := range logProbs {
+		confidence[i] = math.Exp(math.Min(lp, 0))
+	}
+	return confidence
+}
+
+// applyConfidence attaches confidences to the response and enforces
+// MinConfidence. Without scores from the translators nothing is flagged.
+func applyConfidence(resp *Response, req Request, logProbs []float64) {
+	if logProbs == nil || len(logProbs) != len(resp.Translations) {
+		return
+	}
+
+	resp.Confidence = toConfidence(logProbs)
+
+	if req.MinConfidence == nil {
+		return
+	}
+	for i, c := range resp.Confidence {
+		if c >= *req.MinConfidence {
+			continue
+		}
+		resp.LowConfidence = append(resp.LowConfidence, i)
+		if req.LowConfidenceAction == LowConfidenceWithhold {
+			resp.Translations[i] = ""
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"math"
+	"testing"
+)
+
+func float64Ptr(f float64) *float64 { return &f }
+
+func TestApplyConfidence(t *testing.T) {
+	logProbs := []float64{math.Log(0.9), math.Log(0.2), math.Log(0.6)}
+
+	tests := []struct {
+		name             string
+		req              Request
+		wantLow          []int
+		wantTranslations []string
+	}{
+		{
+			name:             "no threshold only exposes confidence",
+			req:              Request{IncludeConfidence: true},
+			wantLow:          nil,
+			wantTranslations: []string{"a", "b", "c"},
+		},
+		{
+			name:             "flag below threshold",
+			req:              Request{MinConfidence: float64Ptr(0.5)},
+			wantLow:          []int{1},
+			wantTranslations: []string{"a", "b", "c"},
+		},
+		{
+			name:             "withhold below threshold",
+			req:              Request{MinConfidence: float64Ptr(0.7), LowConfidenceAction: LowConfidenceWithhold},
+			wantLow:          []int{1, 2},
+			wantTranslations: []string{"a", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{Translations: []string{"a", "b", "c"}}
+			applyConfidence(resp, tt.req, logProbs)
+
+			if len(resp.Confidence) != 3 || math.Abs(resp.Confidence[0]-0.9) > 1e-9 {
+				t.Errorf("Confidence = %v, want [0.9 0.2 0.6]", resp.Confidence)
+			}
+			if len(resp.LowConfidence) != len(tt.wantLow) {
+				t.Fatalf("LowConfidence = %v, want %v", resp.LowConfidence, tt.wantLow)
+			}
+			for i := range tt.wantLow {
+				if resp.LowConfidence[i] != tt.wantLow[i] {
+					t.Errorf("LowConfidence = %v, want %v", resp.LowConfidence, tt.wantLow)
+				}
+			}
+			for i := range tt.wantTranslations {
+				if resp.Translations[i] != tt.wantTranslations[i] {
+					t.Errorf("Translations = %v, want %v", resp.Translations, tt.wantTranslations)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyConfidence_NoScores(t *testing.T) {
+	resp := &Response{Translations: []string{"a"}}
+	applyConfidence(resp, Request{MinConfidence: float64Ptr(0.9)}, nil)
+
+	if resp.Confidence != nil || resp.LowConfidence != nil {
+		t.Errorf("without scores nothing should be flagged, got %+v", resp)
+	}
+}
+
+func TestValidateConfidenceOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"defaults", Request{}, false},
+		{"valid threshold", Request{MinConfidence: float64Ptr(0.5), LowConfidenceAction: LowConfidenceFlag}, false},
+		{"threshold above 1", Request{MinConfidence: float64Ptr(1.5)}, true},
+		{"negative threshold", Request{MinConfidence: float64Ptr(-0.1)}, true},
+		{"unknown action", Request{LowConfidenceAction: "drop"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfidenceOptions(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateConfidenceOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
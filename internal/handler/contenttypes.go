@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/casing"
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// Content types, Request.ContentType and Request.ContentTypes. They drive
+// the style rules applied to translations.
+const (
+	// ContentTitle is a listing title: title or sentence case, no trailing
+	// period.
+	ContentTitle = "title"
+	// ContentDescription is running text: sentence case.
+	ContentDescription = "description"
+	// ContentBullet is a bullet point: sentence case, no trailing period.
+	ContentBullet = "bullet"
+)
+
+// Title casings, Request.TitleCasing. By default titles are title-cased in
+// English and sentence-cased in every other language.
+const (
+	CasingTitle    = "title"
+	CasingSentence = "sentence"
+	CasingPreserve = "preserve"
+)
+
+// applyContentTypes applies the style rules of each text's content type to
+// its translation. Texts without a content type are left as translated.
+func applyContentTypes(req Request, translations []string) {
+	if req.ContentType == "" && len(req.ContentTypes) == 0 {
+		return
+	}
+	lang := locale.Base(req.TargetLang)
+	for i, t := range translations {
+		if t == "" {
+			continue // withheld or empty
+		}
+		switch contentType(req, i) {
+		case ContentTitle:
+			translations[i] = styleTitle(casing.TrimTrailingPunctuation(t), lang, req.TitleCasing)
+		case ContentDescription:
+			translations[i] = casing.Sentence(t)
+		case ContentBullet:
+			translations[i] = casing.Sentence(casing.TrimTrailingPunctuation(t))
+		}
+	}
+}
+
+// contentType returns the content type of text i.
+func contentType(req Request, i int) string {
+	if i < len(req.ContentTypes) && req.ContentTypes[i] != "" {
+		return req.ContentTypes[i]
+	}
+	return req.ContentType
+}
+
+// styleTitle applies the title casing of the request or, by default, of lang.
+func styleTitle(title, lang, policy string) string {
+	if policy == "" {
+		policy = CasingSentence
+		if lang == "en" {
+			policy = CasingTitle
+		}
+	}
+	switch policy {
+	case CasingTitle:
+		return casing.Title(title, lang)
+	case CasingSentence:
+		return casing.Sentence(title)
+	default:
+		return title
+	}
+}
+
+// validateContentTypes checks the content type options.
+func validateContentTypes(req Request) error {
+	for _, ct := range append([]string{req.ContentType}, req.ContentTypes...) {
+		switch ct {
+		case "", ContentTitle, ContentDescription, ContentBullet:
+		default:
+			return fmt.Errorf("unknown contentType %q", ct)
+		}
+	}
+	if len(req.ContentTypes) > 0 {
+		if req.Text != "" {
+			return fmt.Errorf("contentTypes is not supported with text; use contentType")
+		}
+		if len(req.ContentTypes) != len(req.Texts) {
+			return fmt.Errorf("contentTypes has %d entries for %d texts", len(req.ContentTypes), len(req.Texts))
+		}
+	}
+	switch req.TitleCasing {
+	case "", CasingTitle, CasingSentence, CasingPreserve:
+		return nil
+	default:
+		return fmt.Errorf("unknown titleCasing %q", req.TitleCasing)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyContentTypes(t *testing.T) {
+	translations := []string{"running shoes for men.", "comfortable and light, ideal for training", "breathable mesh.", "", "as translated."}
+
+	tests := []struct {
+		name string
+		req  Request
+		want []string
+	}{
+		{
+			name: "per text",
+			req: Request{
+				TargetLang:   "en_US",
+				ContentTypes: []string{ContentTitle, ContentDescription, ContentBullet, ContentTitle, ""},
+			},
+			want: []string{"Running Shoes for Men", "Comfortable and light, ideal for training", "Breathable mesh", "", "as translated."},
+		},
+		{
+			name: "default for all texts",
+			req:  Request{TargetLang: "en", ContentType: ContentBullet},
+			want: []string{"Running shoes for men", "Comfortable and light, ideal for training", "Breathable mesh", "", "As translated"},
+		},
+		{
+			name: "sentence-cased titles outside English",
+			req:  Request{TargetLang: "es", ContentType: ContentTitle},
+			want: []string{"Running shoes for men", "Comfortable and light, ideal for training", "Breathable mesh", "", "As translated"},
+		},
+		{
+			name: "preserved title casing",
+			req:  Request{TargetLang: "en", ContentType: ContentTitle, TitleCasing: CasingPreserve},
+			want: []string{"running shoes for men", "comfortable and light, ideal for training", "breathable mesh", "", "as translated"},
+		},
+		{
+			name: "no content type",
+			req:  Request{TargetLang: "en"},
+			want: translations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := append([]string(nil), translations...)
+			applyContentTypes(tt.req, got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyContentTypes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateContentTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"unset", Request{Texts: []string{"a"}}, false},
+		{"per text", Request{Texts: []string{"a", "b"}, ContentTypes: []string{ContentTitle, ""}}, false},
+		{"unknown type", Request{Texts: []string{"a"}, ContentType: "headline"}, true},
+		{"unknown per-text type", Request{Texts: []string{"a"}, ContentTypes: []string{"headline"}}, true},
+		{"length mismatch", Request{Texts: []string{"a", "b"}, ContentTypes: []string{ContentTitle}}, true},
+		{"per text with document", Request{Text: "a", ContentTypes: []string{ContentTitle}}, true},
+		{"document", Request{Text: "a", ContentType: ContentDescription}, false},
+		{"unknown casing", Request{Texts: []string{"a"}, TitleCasing: "upper"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateContentTypes(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateContentTypes() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// validateMaxCost checks Request.MaxCost.
+func validateMaxCost(req Request) error {
+	if req.MaxCost < 0 || math.IsNaN(req.MaxCost) {
+		return fmt.Errorf("maxCost must not be negative")
+	}
+	return nil
+}
+
+// costExceeded returns the response to a translation over budget, carrying
+// the estimate so callers can raise maxCost or split the request, or nil
+// when err is not a budget failure.
+func costExceeded(err error) *Response {
+	var costErr *router.CostError
+	if !errors.As(err, &costErr) {
+		return nil
+	}
+	resp := errorResponse(ErrorCostExceeded, costErr.Estimate, costErr.MaxCost)
+	resp.CostEstimate = costErr.Estimate
+	return resp
+}
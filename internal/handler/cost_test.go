@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestValidateMaxCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxCost float64
+		wantErr bool
+	}{
+		{"unset", 0, false},
+		{"budget", 0.05, false},
+		{"negative", -1, true},
+		{"not a number", math.NaN(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMaxCost(Request{MaxCost: tt.maxCost}); (err != nil) != tt.wantErr {
+				t.Errorf("validateMaxCost() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCostExceeded(t *testing.T) {
+	resp := costExceeded(&router.CostError{Estimate: 0.25, MaxCost: 0.1})
+	if resp == nil {
+		t.Fatal("costExceeded() = nil, want a response")
+	}
+	if resp.ErrorCode != ErrorCostExceeded || resp.CostEstimate != 0.25 {
+		t.Errorf("costExceeded() = %q with estimate %g, want %q with 0.25", resp.ErrorCode, resp.CostEstimate, ErrorCostExceeded)
+	}
+	if want := "estimated cost 0.25 exceeds maxCost 0.1"; resp.Error.Message != want {
+		t.Errorf("costExceeded() error = %q, want %q", resp.Error, want)
+	}
+
+	if resp := costExceeded(errors.New("timeout")); resp != nil {
+		t.Errorf("costExceeded(timeout) = %+v, want nil", resp)
+	}
+}
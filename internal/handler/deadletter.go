@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// ModeRedrive translates the dead-lettered chunks of a job again and
+// patches its result: {"mode": "redrive", "jobId": "..."}.
+const ModeRedrive = "redrive"
+
+// DeadLetterBucketEnv names the S3 bucket of the error manifests of jobs
+// whose chunks failed; dead-lettering is off when it is unset.
+const DeadLetterBucketEnv = "DEAD_LETTER_BUCKET"
+
+const deadLettersPrefix = "dead-letters/"
+
+// deadLetterManifest lists the chunks of a job that failed their route,
+// with what a redrive needs to translate them again.
+type deadLetterManifest struct {
+	JobID string `json:"jobId"`
+	// Request is the job request, whose texts the chunks index.
+	Request   Request     `json:"request"`
+	Chunks    []deadChunk `json:"chunks"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// deadChunk is a chunk that failed its route.
+type deadChunk struct {
+	Chunk int `json:"chunk"`
+	// Texts are the indices of the job texts in the chunk, and Payload the
+	// chunk as sent to the translators.
+	Texts      []int       `json:"texts"`
+	Payload    []string    `json:"payload"`
+	SourceLang string      `json:"sourceLang"`
+	TargetLang string      `json:"targetLang"`
+	FailedStep *FailedStep `json:"failedStep,omitempty"`
+	Error      string      `json:"error"`
+}
+
+// deadLetterJob is a background job run whose failed chunks are written to
+// its manifest instead of failing the job.
+type deadLetterJob struct {
+	store jobResults
+	req   Request
+}
+
+type deadLetterKey struct{}
+
+// withDeadLetters returns ctx carrying the dead-letter job of req's run
+// when DEAD_LETTER_BUCKET is set. Only plain texts are dead-lettered: the
+// texts of documents, PO catalogs, HTML and batches are not translated one
+// to one, and tagged texts go through several routes.
+func (h *Handler) withDeadLetters(ctx context.Context, req Request) context.Context {
+	if req.Mode != "" || req.Text != "" || req.PO != "" || req.ChunkStrategy == ChunkHTML || tagged(req) {
+		return ctx
+	}
+	j, err := h.asyncJobs()
+	if err != nil || j.deadLetters == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, deadLetterKey{}, &deadLetterJob{store: j.deadLetters, req: req})
+}
+
+// deadLetterJobFrom returns the dead-letter job in ctx, or nil.
+func deadLetterJobFrom(ctx context.Context) *deadLetterJob {
+	d, _ := ctx.Value(deadLetterKey{}).(*deadLetterJob)
+	return d
+}
+
+// failedTexts returns, per request text, the failed chunk of result that
+// held it (or a piece of it), or -1; nil when no chunk failed.
+func failedTexts(result *router.Result, chunks [][]string, order [][]int, split *chunker.Split, dups duplicates, known knownTexts) []int {
+	if len(result.Failed) == 0 {
+		return nil
+	}
+	ids := make([][]int, len(chunks))
+	for c, chunk := range chunks {
+		ids[c] = make([]int, len(chunk))
+		for i := range ids[c] {
+			ids[c][i] = -1
+		}
+	}
+	for _, f := range result.Failed {
+		for i := range ids[f.Chunk] {
+			ids[f.Chunk][i] = f.Chunk
+		}
+	}
+	texts := unchunk(ids, order)
+	if split.Oversized() {
+		pieces := texts
+		texts = nil
+		for p, origin := range split.Origins() {
+			if origin == len(texts) {
+				texts = append(texts, -1)
+			}
+			if texts[origin] < 0 {
+				texts[origin] = pieces[p]
+			}
+		}
+	}
+	return spreadHits(known, spreadDuplicates(dups, texts), func(int) int { return -1 })
+}
+
+// record writes the manifest of the chunks of result that failed, blanks
+// the translations of their texts (failed, from failedTexts) and returns
+// the indices of those texts with a warning. Without a manifest the chunks
+// would be lost, so a failed write fails the run.
+func (d *deadLetterJob) record(ctx context.Context, rec *metrics.Recorder, result *router.Result, chunks [][]string, failed []int, translations []string) ([]int, []Warning, error) {
+	if failed == nil {
+		return nil, nil, nil
+	}
+	var indices []int
+	byChunk := map[int][]int{}
+	for i, c := range failed {
+		if c >= 0 {
+			indices = append(indices, i)
+			byChunk[c] = append(byChunk[c], i)
+			translations[i] = ""
+		}
+	}
+	m := deadLetterManifest{JobID: d.req.JobID, Request: d.req, CreatedAt: time.Now().UTC()}
+	for _, f := range result.Failed {
+		chunk := deadChunk{
+			Chunk:      f.Chunk,
+			Texts:      byChunk[f.Chunk],
+			Payload:    chunks[f.Chunk],
+			SourceLang: d.req.SourceLang,
+			TargetLang: d.req.TargetLang,
+			Error:      f.Err.Error(),
+		}
+		var stepErr *router.StepError
+		if errors.As(f.Err, &stepErr) {
+			chunk.FailedStep = &FailedStep{Step: stepErr.Step, Steps: stepErr.Steps, Function: stepErr.Function}
+		}
+		m.Chunks = append(m.Chunks, chunk)
+	}
+	if err := putManifest(ctx, d.store, m); err != nil {
+		return nil, nil, err
+	}
+	rec.Add("DeadLetteredChunks", metrics.Count, float64(len(m.Chunks)), metrics.Dimensions{"LanguagePair": languagePair(d.req)})
+	warning := Warning{
+		Code:    WarningChunksDeadLettered,
+		Message: fmt.Sprintf("%d chunks failed and their %d texts are left untranslated; redrive job %s to translate them", len(m.Chunks), len(indices), d.req.JobID),
+	}
+	return indices, []Warning{warning}, nil
+}
+
+// putManifest stores the manifest m under its job ID.
+func putManifest(ctx context.Context, store jobResults, m deadLetterManifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter manifest: %w", err)
+	}
+	if err := store.Put(ctx, m.JobID+".json", data); err != nil {
+		return fmt.Errorf("failed to store dead-letter manifest: %w", err)
+	}
+	return nil
+}
+
+// redrive translates the texts of the dead-lettered chunks of a job again
+// as one request with the job's options, patches them into the stored job
+// result and empties the manifest. A redrive that fails leaves both as
+// they were, so it can be repeated.
+func (h *Handler) redrive(ctx context.Context, req Request) (*Response, error) {
+	j, err := h.asyncJobs()
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error()), nil
+	}
+	if j.deadLetters == nil {
+		return errorResponse(ErrorUnavailable, fmt.Sprintf("dead-lettering is not enabled (%s is not set)", DeadLetterBucketEnv)), nil
+	}
+	m, err := j.manifest(ctx, req.JobID)
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error()), nil
+	}
+	indices := m.texts()
+	if len(indices) == 0 {
+		return errorResponse(ErrorInvalidRequest, fmt.Sprintf("job %s has no dead-lettered chunks", req.JobID)), nil
+	}
+	job := j.result(ctx, req.JobID)
+	if job.Status != JobCompleted {
+		return errorResponse(ErrorInvalidRequest, fmt.Sprintf("job %s has not completed", req.JobID)), nil
+	}
+
+	redriven, err := h.Handle(ctx, redriveRequest(m.Request, indices))
+	if err != nil || redriven.Error != nil {
+		return redriven, err
+	}
+	patchTexts(job, indices, redriven)
+	if err := j.complete(ctx, m.Request, job); err != nil {
+		return errorResponse(ErrorUnavailable, err.Error()), nil
+	}
+	m.Chunks = nil
+	if err := putManifest(ctx, j.deadLetters, *m); err != nil {
+		return errorResponse(ErrorUnavailable, err.Error()), nil
+	}
+	return job, nil
+}
+
+// manifest returns the dead-letter manifest of job id; a job without one
+// has an empty manifest.
+func (j *jobRunner) manifest(ctx context.Context, id string) (*deadLetterManifest, error) {
+	data, ok, err := j.deadLetters.Get(ctx, id+".json")
+	if err != nil || !ok {
+		return &deadLetterManifest{JobID: id}, err
+	}
+	var m deadLetterManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("corrupt dead-letter manifest: %v", err)
+	}
+	return &m, nil
+}
+
+// texts returns the indices of the job texts in the dead-lettered chunks,
+// in order.
+func (m *deadLetterManifest) texts() []int {
+	seen := map[int]bool{}
+	for _, chunk := range m.Chunks {
+		for _, i := range chunk.Texts {
+			seen[i] = true
+		}
+	}
+	var indices []int
+	for i := range m.Request.Texts {
+		if seen[i] {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// redriveRequest returns the job request narrowed to the texts at indices,
+// run in this invocation.
+func redriveRequest(req Request, indices []int) Request {
+	req.Texts = pick(req.Texts, indices)
+	req.ContentTypes = pick(req.ContentTypes, indices)
+	req.Hashes = pick(req.Hashes, indices)
+	req.Async, req.JobID, req.IdempotencyKey = false, "", ""
+	return req
+}
+
+// pick returns the values at indices, or nil for no values.
+func pick[T any](values []T, indices []int) []T {
+	if len(values) == 0 {
+		return values
+	}
+	out := make([]T, len(indices))
+	for k, i := range indices {
+		out[k] = values[i]
+	}
+	return out
+}
+
+// patchTexts puts the per-text results of a redrive of the texts at
+// indices into the job response and drops its dead-letter warning. The
+// redriven text k is the job text indices[k].
+func patchTexts(job *Response, indices []int, redriven *Response) {
+	for k, i := range indices {
+		patch(job.Translations, i, redriven.Translations, k)
+		patch(job.Confidence, i, redriven.Confidence, k)
+		patch(job.Slugs, i, redriven.Slugs, k)
+		patch(job.Truncated, i, redriven.Truncated, k)
+		patch(job.Versions, i, redriven.Versions, k)
+		patch(job.Keywords, i, redriven.Keywords, k)
+		for _, pivot := range redriven.Intermediate {
+			if into := intermediateInto(job.Intermediate, pivot.Lang); into != nil {
+				patch(into.Translations, i, pivot.Translations, k)
+			}
+		}
+	}
+	// and moves the per-index results to the positions of the job
+	job.LowConfidence = patchIndices(job.LowConfidence, indices, redriven.LowConfidence,
+		func(i int) int { return i },
+		func(_, i int) int { return i })
+	job.Blocked = patchIndices(job.Blocked, indices, redriven.Blocked,
+		func(m blocklist.Match) int { return m.Index },
+		func(m blocklist.Match, i int) blocklist.Match {
+			m.Index = i
+			return m
+		})
+	job.DeadLettered = nil
+	warnings := job.Warnings[:0]
+	for _, w := range job.Warnings {
+		if w.Code != WarningChunksDeadLettered {
+			warnings = append(warnings, w)
+		}
+	}
+	job.Warnings = warnings
+}
+
+// patch sets dst[i] to src[k] when both exist.
+func patch[T any](dst []T, i int, src []T, k int) {
+	if i < len(dst) && k < len(src) {
+		dst[i] = src[k]
+	}
+}
+
+// intermediateInto returns the intermediate translations into lang, or nil.
+func intermediateInto(intermediate []IntermediateTexts, lang string) *IntermediateTexts {
+	for i := range intermediate {
+		if intermediate[i].Lang == lang {
+			return &intermediate[i]
+		}
+	}
+	return nil
+}
+
+// patchIndices replaces the entries of dst about the texts at indices, by
+// the index of, with those of src moved from redrive positions to job
+// positions by at, keeping them ordered by text.
+func patchIndices[T any](dst []T, indices []int, src []T, index func(T) int, at func(T, int) T) []T {
+	redriven := map[int]bool{}
+	for _, i := range indices {
+		redriven[i] = true
+	}
+	var out []T
+	for _, v := range dst {
+		if !redriven[index(v)] {
+			out = append(out, v)
+		}
+	}
+	for _, v := range src {
+		if k := index(v); k >= 0 && k < len(indices) {
+			out = append(out, at(v, indices[k]))
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return index(out[a]) < index(out[b]) })
+	return out
+}
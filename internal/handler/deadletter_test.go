@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// failingChunkTranslator fails the chunks holding the text fail, which are
+// dead-lettered when the options allow it.
+type failingChunkTranslator struct {
+	stubTranslator
+	fail string
+}
+
+func (t *failingChunkTranslator) TranslateChunksWithOptions(ctx context.Context, source, target string, chunks [][]string, opts router.Options) (*router.Result, error) {
+	result, err := t.stubTranslator.TranslateChunksWithOptions(ctx, source, target, chunks, opts)
+	for c, chunk := range chunks {
+		if t.fail == "" || !slices.Contains(chunk, t.fail) {
+			continue
+		}
+		stepErr := &router.StepError{Step: 1, Steps: 1, Function: "translator-es-en", Err: errors.New("model crashed")}
+		if !opts.DeadLetter {
+			return nil, stepErr
+		}
+		result.Translations[c] = make([]string, len(chunk))
+		result.Failed = append(result.Failed, router.ChunkFailure{Chunk: c, Err: stepErr})
+	}
+	return result, err
+}
+
+func TestFailedTexts(t *testing.T) {
+	result := &router.Result{Failed: []router.ChunkFailure{{Chunk: 1}}}
+	chunks := [][]string{{"a"}, {"b"}}
+	split := chunker.SplitOversized([]string{"a", "b"}, "es", 0)
+	dups := duplicates{distinct: []int{0, 1, 1}}
+	known := knownTexts{texts: []string{"ok", "a", "b", "b"}, hits: map[int]string{0: "ok"}}
+
+	got := failedTexts(result, chunks, nil, split, dups, known)
+	if want := []int{-1, -1, 1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failedTexts() = %v, want %v", got, want)
+	}
+	if got := failedTexts(&router.Result{}, chunks, nil, split, dups, known); got != nil {
+		t.Errorf("failedTexts() without failures = %v, want nil", got)
+	}
+}
+
+func TestDeadLetter_Redrive(t *testing.T) {
+	results, deadLetters := memoryBucket{}, memoryBucket{}
+	j := &jobRunner{
+		store:       resultstore.New(results, "bucket", jobsPrefix),
+		deadLetters: resultstore.New(deadLetters, "bucket", deadLettersPrefix),
+	}
+	tr := &failingChunkTranslator{fail: "boom"}
+	h := NewHandler(tr)
+	h.jobs = j
+	ctx := context.Background()
+
+	var texts []string
+	for i := 0; i < 51; i++ {
+		texts = append(texts, fmt.Sprintf("texto %d", i))
+	}
+	texts = append(texts, "boom")
+	job := Request{Texts: texts, SourceLang: "es", TargetLang: "en", Async: true, JobID: "job-1"}
+	resp, err := h.RunJob(ctx, job)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("RunJob() = %+v, %v", resp, err)
+	}
+	if !reflect.DeepEqual(resp.DeadLettered, []int{50, 51}) || resp.Translations[51] != "" || resp.Translations[0] != "en:texto 0" {
+		t.Errorf("job response = %v, translations %q, want texts 50 and 51 dead-lettered", resp.DeadLettered, resp.Translations[49:])
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Code != WarningChunksDeadLettered {
+		t.Errorf("warnings = %+v, want %s", resp.Warnings, WarningChunksDeadLettered)
+	}
+
+	var m deadLetterManifest
+	if err := json.Unmarshal(deadLetters["dead-letters/job-1.json"], &m); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	chunk := m.Chunks[0]
+	if len(m.Chunks) != 1 || !reflect.DeepEqual(chunk.Texts, []int{50, 51}) || !reflect.DeepEqual(chunk.Payload, []string{"texto 50", "boom"}) ||
+		chunk.FailedStep == nil || chunk.FailedStep.Function != "translator-es-en" || chunk.SourceLang != "es" {
+		t.Errorf("manifest chunks = %+v", m.Chunks)
+	}
+
+	tr.fail = ""
+	resp, err = h.Handle(ctx, Request{Mode: ModeRedrive, JobID: "job-1"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle(redrive) = %+v, %v", resp, err)
+	}
+	if resp.Translations[51] != "en:boom" || resp.DeadLettered != nil || len(resp.Warnings) != 0 || resp.Status != JobCompleted {
+		t.Errorf("redrive = %+v, want the patched job", resp)
+	}
+	if stored := j.status(ctx, "job-1"); stored.Translations[50] != "en:texto 50" || stored.DeadLettered != nil {
+		t.Errorf("stored job = %+v, want the patched translations", stored)
+	}
+
+	resp, _ = h.Handle(ctx, Request{Mode: ModeRedrive, JobID: "job-1"})
+	if resp.ErrorCode != ErrorInvalidRequest {
+		t.Errorf("second redrive = %+v, want %s", resp, ErrorInvalidRequest)
+	}
+}
+
+func TestDeadLetter_Off(t *testing.T) {
+	h := NewHandler(&failingChunkTranslator{fail: "boom"})
+	h.jobs = &jobRunner{store: resultstore.New(memoryBucket{}, "bucket", jobsPrefix)}
+
+	resp, err := h.RunJob(context.Background(), Request{Texts: []string{"boom"}, SourceLang: "es", TargetLang: "en", JobID: "job-1"})
+	if err != nil || resp.ErrorCode != ErrorTranslationFailed {
+		t.Errorf("RunJob() without dead-lettering = %+v, %v, want %s", resp, err, ErrorTranslationFailed)
+	}
+	resp, _ = h.Handle(context.Background(), Request{Mode: ModeRedrive, JobID: "job-1"})
+	if resp.ErrorCode != ErrorUnavailable {
+		t.Errorf("Handle(redrive) = %+v, want %s", resp, ErrorUnavailable)
+	}
+}
+
+func TestParseRequest_RedriveMode(t *testing.T) {
+	req, err := ParseRequest(json.RawMessage(`{"mode": "redrive", "jobId": "job-1"}`))
+	if err != nil || req.Mode != ModeRedrive || req.JobID != "job-1" {
+		t.Fatalf("ParseRequest() = %+v, %v", req, err)
+	}
+	if _, err := ParseRequest(json.RawMessage(`{"mode": "redrive"}`)); err == nil {
+		t.Error("ParseRequest() without jobId error = nil")
+	}
+}
+
+func TestPatchTexts(t *testing.T) {
+	job := &Response{
+		Translations:  []string{"en:a", "", "en:c", ""},
+		Keywords:      [][]string{{"a"}, nil, {"c"}, nil},
+		Intermediate:  []IntermediateTexts{{Lang: "en", Translations: []string{"a", "", "c", ""}}},
+		LowConfidence: []int{0, 2},
+		Blocked:       []blocklist.Match{{Index: 2, Terms: []string{"c"}, Action: blocklist.Review}},
+		DeadLettered:  []int{1, 3},
+		Warnings:      []Warning{{Code: WarningChunksDeadLettered}},
+	}
+	redriven := &Response{
+		Translations:  []string{"fr:b", "fr:miracle"},
+		Keywords:      [][]string{{"b"}, {"miracle"}},
+		Intermediate:  []IntermediateTexts{{Lang: "en", Translations: []string{"b", "miracle"}}},
+		LowConfidence: []int{0},
+		Blocked:       []blocklist.Match{{Index: 1, Terms: []string{"miracle"}, Action: blocklist.Review}},
+	}
+
+	patchTexts(job, []int{1, 3}, redriven)
+	want := &Response{
+		Translations:  []string{"en:a", "fr:b", "en:c", "fr:miracle"},
+		Keywords:      [][]string{{"a"}, {"b"}, {"c"}, {"miracle"}},
+		Intermediate:  []IntermediateTexts{{Lang: "en", Translations: []string{"a", "b", "c", "miracle"}}},
+		LowConfidence: []int{0, 1, 2},
+		Blocked: []blocklist.Match{
+			{Index: 2, Terms: []string{"c"}, Action: blocklist.Review},
+			{Index: 3, Terms: []string{"miracle"}, Action: blocklist.Review},
+		},
+		Warnings: []Warning{},
+	}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("patchTexts() = %+v, want %+v", job, want)
+	}
+}
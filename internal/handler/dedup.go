@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// duplicates maps the texts left to translate to their distinct texts, so
+// a text repeated across a batch ("Buen estado" a thousand times) is
+// chunked and translated once and its translation fanned back out.
+type duplicates struct {
+	// texts and tags are the texts before deduplication and their tags.
+	texts []string
+	tags  []string
+	// distinct maps every text to the index of its first occurrence among
+	// the distinct texts; nil when there are no duplicates.
+	distinct []int
+}
+
+// takeDuplicates leaves one of each repeated text in req.Texts; expand puts
+// them back. Texts only count as repeated under the same tag, since tags
+// may route them to different translators.
+func takeDuplicates(req *Request, rec *metrics.Recorder) duplicates {
+	d := duplicates{texts: req.Texts, tags: req.Tags}
+	type key struct{ text, tag string }
+	first := make(map[key]int, len(req.Texts))
+	distinct := make([]int, len(req.Texts))
+	var texts, tags []string
+	for i, text := range req.Texts {
+		k := key{text: text}
+		if req.Tags != nil {
+			k.tag = req.Tags[i]
+		}
+		j, ok := first[k]
+		if !ok {
+			j = len(texts)
+			first[k] = j
+			texts = append(texts, text)
+			if req.Tags != nil {
+				tags = append(tags, k.tag)
+			}
+		}
+		distinct[i] = j
+	}
+	if len(texts) == len(req.Texts) {
+		return d
+	}
+	d.distinct = distinct
+	req.Texts, req.Tags = texts, tags
+	rec.Add("DuplicateTexts", metrics.Count, float64(len(d.texts)-len(texts)), metrics.Dimensions{"LanguagePair": languagePair(*req)})
+	return d
+}
+
+// expand restores the texts in req and returns the translations of the
+// distinct texts spread to every occurrence.
+func (d duplicates) expand(req *Request, translations []string) []string {
+	req.Texts, req.Tags = d.texts, d.tags
+	return spreadDuplicates(d, translations)
+}
+
+// spreadDuplicates returns one value per text from the values of the
+// distinct texts; nil stays nil.
+func spreadDuplicates[T any](d duplicates, values []T) []T {
+	if d.distinct == nil || values == nil {
+		return values
+	}
+	out := make([]T, len(d.distinct))
+	for i, j := range d.distinct {
+		out[i] = values[j]
+	}
+	return out
+}
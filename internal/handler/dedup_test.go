@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTakeDuplicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		texts     []string
+		tags      []string
+		wantTexts []string
+		wantTags  []string
+	}{
+		{
+			name:      "no duplicates",
+			texts:     []string{"Buen estado", "Nuevo"},
+			wantTexts: []string{"Buen estado", "Nuevo"},
+		},
+		{
+			name:      "repeated texts",
+			texts:     []string{"Buen estado", "Nuevo", "Buen estado", "Buen estado", "Nuevo"},
+			wantTexts: []string{"Buen estado", "Nuevo"},
+		},
+		{
+			name:      "same text under different tags",
+			texts:     []string{"Garantía", "Garantía", "Garantía"},
+			tags:      []string{"legal", "", "legal"},
+			wantTexts: []string{"Garantía", "Garantía"},
+			wantTags:  []string{"legal", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Texts: tt.texts, Tags: tt.tags, SourceLang: "es", TargetLang: "en"}
+			dups := takeDuplicates(&req, nil)
+			if !reflect.DeepEqual(req.Texts, tt.wantTexts) || !reflect.DeepEqual(req.Tags, tt.wantTags) {
+				t.Fatalf("takeDuplicates() left %q %q, want %q %q", req.Texts, req.Tags, tt.wantTexts, tt.wantTags)
+			}
+
+			translations := make([]string, len(req.Texts))
+			for i, text := range req.Texts {
+				translations[i] = "en:" + text
+			}
+			got := dups.expand(&req, translations)
+			for i, text := range tt.texts {
+				if got[i] != "en:"+text {
+					t.Errorf("expand()[%d] = %q, want %q", i, got[i], "en:"+text)
+				}
+			}
+			if !reflect.DeepEqual(req.Texts, tt.texts) || !reflect.DeepEqual(req.Tags, tt.tags) {
+				t.Errorf("expand() left %q %q, want %q %q", req.Texts, req.Tags, tt.texts, tt.tags)
+			}
+		})
+	}
+}
+
+func TestSpreadDuplicates(t *testing.T) {
+	req := Request{Texts: []string{"a", "b", "a"}}
+	dups := takeDuplicates(&req, nil)
+	if got, want := spreadDuplicates(dups, []float64{-0.1, -0.2}), []float64{-0.1, -0.2, -0.1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("spreadDuplicates() = %v, want %v", got, want)
+	}
+	if got := spreadDuplicates[float64](dups, nil); got != nil {
+		t.Errorf("spreadDuplicates(nil) = %v, want nil", got)
+	}
+}
+
+func TestHandle_Duplicates(t *testing.T) {
+	texts := make([]string, 0, 1002)
+	for i := 0; i < 500; i++ {
+		texts = append(texts, "Buen estado", "Como nuevo")
+	}
+	texts = append(texts, "Sin caja", "Buen estado")
+
+	translator := &stubTranslator{}
+	resp, err := NewHandler(translator).Handle(context.Background(), Request{Texts: texts, SourceLang: "es", TargetLang: "fr"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	if got, want := flatten(translator.chunks), []string{"Buen estado", "Como nuevo", "Sin caja"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("translator got %q, want each text once %q", got, want)
+	}
+	if len(resp.Translations) != len(texts) {
+		t.Fatalf("Handle() returned %d translations for %d texts", len(resp.Translations), len(texts))
+	}
+	for i, text := range texts {
+		if resp.Translations[i] != "fr:"+text {
+			t.Errorf("translation %d = %q, want %q", i, resp.Translations[i], "fr:"+text)
+		}
+	}
+}
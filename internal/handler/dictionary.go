@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/dictionary"
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// Dictionary modes accepted in Request.Dictionary.
+const (
+	// DictionaryOn answers listed single words from the embedded dictionary
+	// (the default).
+	DictionaryOn = "on"
+	// DictionaryOff sends every text to the translators.
+	DictionaryOff = "off"
+)
+
+// lookupDictionary answers the single-word texts of req listed in the
+// embedded dictionary. Dictionary answers are certain (log-probability 0).
+func lookupDictionary(req Request, known *knownTexts) {
+	if req.Dictionary == DictionaryOff {
+		return
+	}
+	source, target := locale.Base(req.SourceLang), locale.Base(req.TargetLang)
+	for i, text := range req.Texts {
+		if translation, ok := dictionary.Lookup(text, source, target); ok {
+			known.add(i, translation, 0)
+		}
+	}
+}
+
+// validateDictionary checks Request.Dictionary.
+func validateDictionary(mode string) error {
+	switch mode {
+	case "", DictionaryOn, DictionaryOff:
+		return nil
+	default:
+		return fmt.Errorf("unknown dictionary %q", mode)
+	}
+}
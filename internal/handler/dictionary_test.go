@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupDictionary(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		wantHits map[int]string
+	}{
+		{
+			name:     "mixed texts",
+			req:      Request{Texts: []string{"rojo", "Camiseta de algodón", "usado"}, SourceLang: "es", TargetLang: "en"},
+			wantHits: map[int]string{0: "red", 2: "used"},
+		},
+		{
+			name:     "regional codes",
+			req:      Request{Texts: []string{"vermelho"}, SourceLang: "pt_BR", TargetLang: "es_MX"},
+			wantHits: map[int]string{0: "rojo"},
+		},
+		{
+			name: "dictionary off",
+			req:  Request{Texts: []string{"rojo", "usado"}, SourceLang: "es", TargetLang: "en", Dictionary: DictionaryOff},
+		},
+		{
+			name: "no hits",
+			req:  Request{Texts: []string{"Camiseta"}, SourceLang: "es", TargetLang: "en"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var known knownTexts
+			lookupDictionary(tt.req, &known)
+			if !reflect.DeepEqual(known.hits, tt.wantHits) {
+				t.Errorf("hits = %q, want %q", known.hits, tt.wantHits)
+			}
+			for i := range known.hits {
+				if known.scores[i] != 0 {
+					t.Errorf("score of hit %d = %v, want 0", i, known.scores[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDictionary(t *testing.T) {
+	for _, mode := range []string{"", DictionaryOn, DictionaryOff} {
+		if err := validateDictionary(mode); err != nil {
+			t.Errorf("validateDictionary(%q) unexpected error: %v", mode, err)
+		}
+	}
+	if err := validateDictionary("always"); err == nil {
+		t.Error("validateDictionary(\"always\") expected an error")
+	}
+}
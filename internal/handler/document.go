@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/segment"
+)
+
+// prepareDocument splits Request.Text into sentence segments and uses them
+// as the texts to translate, honouring the sentence-boundary exceptions of
+// the source language. It returns nil for regular batch requests.
+func prepareDocument(req *Request) *segment.Document {
+	if req.Text == "" {
+		return nil
+	}
+	exceptions := segment.DefaultExceptions
+	// A policy load error fails the request in handleTexts
+	if pol, err := containerPolicies(); err == nil {
+		exceptions = pol.segments
+	}
+	doc := exceptions.Split(req.Text, locale.Base(req.SourceLang))
+	req.Texts = doc.Texts()
+	return doc
+}
+
+// finishDocument reassembles the translated document into the response,
+// spacing its sentences the way the target language does.
+func finishDocument(resp *Response, doc *segment.Document, targetLang string) {
+	if doc == nil || resp.Error != nil || len(resp.Translations) != len(doc.Segments) {
+		return
+	}
+	resp.Segments = doc.Texts()
+	resp.Document = doc.JoinIn(resp.Translations, targetLang)
+}
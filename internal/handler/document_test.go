@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocumentMode(t *testing.T) {
+	req := Request{Text: "Hola mundo. ¿Qué tal?\n\nAdiós.", SourceLang: "es", TargetLang: "en"}
+
+	doc := prepareDocument(&req)
+	if doc == nil {
+		t.Fatal("prepareDocument() returned nil for a text request")
+	}
+	wantTexts := []string{"Hola mundo.", "¿Qué tal?", "Adiós."}
+	if !reflect.DeepEqual(req.Texts, wantTexts) {
+		t.Fatalf("prepareDocument() texts = %q, want %q", req.Texts, wantTexts)
+	}
+
+	resp := &Response{Translations: []string{"Hello world.", "How are you?", "Goodbye."}}
+	finishDocument(resp, doc, req.TargetLang)
+
+	if want := "Hello world. How are you?\n\nGoodbye."; resp.Document != want {
+		t.Errorf("Document = %q, want %q", resp.Document, want)
+	}
+	if !reflect.DeepEqual(resp.Segments, wantTexts) {
+		t.Errorf("Segments = %q, want %q", resp.Segments, wantTexts)
+	}
+}
+
+func TestFinishDocument_Joiners(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		target       string
+		translations []string
+		want         string
+	}{
+		{
+			name:         "translator whitespace is dropped",
+			text:         "Hola mundo. ¿Qué tal?",
+			target:       "en",
+			translations: []string{"Hello world. ", " How are you?"},
+			want:         "Hello world. How are you?",
+		},
+		{
+			name:         "unspaced target",
+			text:         "Hola mundo. ¿Qué tal?\nAdiós.",
+			target:       "ja",
+			translations: []string{"こんにちは。", "お元気ですか？", "さようなら。"},
+			want:         "こんにちは。お元気ですか？\nさようなら。",
+		},
+		{
+			name:         "unspaced source",
+			text:         "你好。再见。",
+			target:       "en",
+			translations: []string{"Hello.", "Goodbye."},
+			want:         "Hello. Goodbye.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Text: tt.text, SourceLang: "es", TargetLang: tt.target}
+			doc := prepareDocument(&req)
+			resp := &Response{Translations: tt.translations}
+			finishDocument(resp, doc, tt.target)
+			if resp.Document != tt.want {
+				t.Errorf("Document = %q, want %q", resp.Document, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareDocument_Abbreviations(t *testing.T) {
+	req := Request{Text: "Pesa aprox. 2 kg. Envío gratis.", SourceLang: "es_MX", TargetLang: "en"}
+	prepareDocument(&req)
+
+	want := []string{"Pesa aprox. 2 kg.", "Envío gratis."}
+	if !reflect.DeepEqual(req.Texts, want) {
+		t.Errorf("prepareDocument() texts = %q, want %q", req.Texts, want)
+	}
+}
+
+func TestPrepareDocument_BatchRequest(t *testing.T) {
+	req := Request{Texts: []string{"Hola"}}
+	if doc := prepareDocument(&req); doc != nil {
+		t.Error("prepareDocument() should ignore batch requests")
+	}
+}
+
+func TestValidateRequest_TextAndTexts(t *testing.T) {
+	err := validateRequest(Request{Texts: []string{"a"}, Text: "b", SourceLang: "es", TargetLang: "fr"})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("validateRequest() error = %v, want mutually exclusive error", err)
+	}
+
+	if err := validateRequest(Request{Text: "Hola.", SourceLang: "es", TargetLang: "fr"}); err != nil {
+		t.Errorf("validateRequest() unexpected error for text request: %v", err)
+	}
+}
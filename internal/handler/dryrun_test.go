@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestHandle_DryRun(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		req  Request
+	}{
+		{name: "dryRun flag", env: "dev", req: Request{DryRun: true}},
+		{name: "local environment", env: router.EnvLocal},
+		{name: "forced dry-run backend", env: "dev", req: Request{Backend: router.BackendDryRun}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENVIRONMENT", tt.env)
+			req := tt.req
+			req.SourceLang, req.TargetLang = "es", "fr"
+			req.Texts = []string{"Hola mundo", "iPhone 12 en perfecto estado", strings.Repeat("palabra ", 60)}
+
+			resp, err := NewHandler(newRouter(t)).Handle(context.Background(), req)
+			if err != nil || resp.Error != nil {
+				t.Fatalf("Handle() = %+v, %v", resp, err)
+			}
+			want := []string{"[fr] aloH odnum", "[fr] enohPi 12 ne otcefrep odatse"}
+			if !reflect.DeepEqual(resp.Translations[:2], want) {
+				t.Errorf("Translations = %q, want %q", resp.Translations[:2], want)
+			}
+			if len(resp.Translations) != 3 || !strings.HasPrefix(resp.Translations[2], "[fr] arbalap") {
+				t.Errorf("long text translation = %q", resp.Translations[2:])
+			}
+		})
+	}
+}
+
+func TestValidateRequest_DryRun(t *testing.T) {
+	base := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "fr", DryRun: true}
+	if err := validateRequest(base); err != nil {
+		t.Errorf("validateRequest(dryRun) error = %v", err)
+	}
+	conflicting := base
+	conflicting.Backend = router.BackendDeepL
+	if err := validateRequest(conflicting); err == nil {
+		t.Error("validateRequest(dryRun with backend deepl) error = nil, want error")
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// Error codes reported in Response.Error.Code and Response.ErrorCode. Codes
+// are stable, so callers branch on them; Response.Error.Message is the
+// human-facing message, which follows Request.ErrorLocale.
+const (
+	// ErrorInvalidRequest means the request is malformed or inconsistent.
+	ErrorInvalidRequest = "INVALID_REQUEST"
+	// ErrorUnsupportedLanguage means a language is not recognized.
+	ErrorUnsupportedLanguage = "UNSUPPORTED_LANGUAGE"
+	// ErrorUnsupportedPair means no route translates between the languages.
+	ErrorUnsupportedPair = "UNSUPPORTED_PAIR"
+	// ErrorTranslationFailed means a translator failed.
+	ErrorTranslationFailed = "TRANSLATION_FAILED"
+	// ErrorCostExceeded means the cheapest route is estimated to cost more
+	// than maxCost; Response.CostEstimate has the estimate.
+	ErrorCostExceeded = "COST_EXCEEDED"
+	// ErrorTimeout means the translation ran out of its time budget;
+	// Response.Timeout tells how far it got.
+	ErrorTimeout = "TIMEOUT"
+	// ErrorInProgress means a request with the same idempotencyKey is still
+	// running; retry later to get its response.
+	ErrorInProgress = "IN_PROGRESS"
+	// ErrorUnauthorized means an admin action without a valid admin token.
+	ErrorUnauthorized = "UNAUTHORIZED"
+	// ErrorUnavailable means a dependency (AWS, configuration) is unavailable.
+	ErrorUnavailable = "SERVICE_UNAVAILABLE"
+	// ErrorInternal is an unexpected failure.
+	ErrorInternal = "INTERNAL_ERROR"
+)
+
+// retryableErrors are the codes of failures a retry of the same request may
+// get past: translator and dependency outages, timeouts and requests still
+// in progress.
+var retryableErrors = map[string]bool{
+	ErrorTranslationFailed: true,
+	ErrorTimeout:           true,
+	ErrorInProgress:        true,
+	ErrorUnavailable:       true,
+}
+
+// ErrorInfo describes a failed request.
+type ErrorInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Retryable tells whether the same request may succeed later.
+	Retryable bool `json:"retryable"`
+	// FailedStep is the route step a translation failed at.
+	FailedStep *FailedStep `json:"failedStep,omitempty"`
+	// LanguagePair is the pair of the failed request, once its languages
+	// are known.
+	LanguagePair *LanguagePair `json:"languagePair,omitempty"`
+}
+
+// FailedStep is the route step (from 1) of Steps that failed, run by
+// Function.
+type FailedStep struct {
+	Step     int    `json:"step"`
+	Steps    int    `json:"steps"`
+	Function string `json:"function"`
+}
+
+func (e *ErrorInfo) Error() string {
+	return e.Message
+}
+
+// UnmarshalJSON also reads the plain message strings that job results and
+// idempotency keys stored before errors were structured.
+func (e *ErrorInfo) UnmarshalJSON(data []byte) error {
+	var message string
+	if json.Unmarshal(data, &message) == nil {
+		*e = ErrorInfo{Message: message}
+		return nil
+	}
+	type plain ErrorInfo
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// NewErrorResponse returns a failed response with a stable code and an
+// English message, for errors found before the handler runs.
+func NewErrorResponse(code, message string) *Response {
+	return &Response{
+		Error:     &ErrorInfo{Code: code, Message: message, Retryable: retryableErrors[code]},
+		ErrorCode: code,
+	}
+}
+
+// defaultErrorLocale is the language of error messages unless the request
+// sets errorLocale, and the fallback for untranslated locales.
+const defaultErrorLocale = "en"
+
+// errorMessages are the message formats per code and language. The English
+// formats keep the technical detail as the whole message; the translations
+// prefix it with a sentence in the caller's language.
+var errorMessages = map[string]map[string]string{
+	ErrorInvalidRequest: {
+		"en": "%s",
+		"es": "Solicitud no válida: %s",
+		"fr": "Requête non valide : %s",
+		"it": "Richiesta non valida: %s",
+		"pt": "Pedido inválido: %s",
+		"de": "Ungültige Anfrage: %s",
+	},
+	ErrorUnsupportedLanguage: {
+		"en": "%s",
+		"es": "Idioma no admitido: %s",
+		"fr": "Langue non prise en charge : %s",
+		"it": "Lingua non supportata: %s",
+		"pt": "Idioma não suportado: %s",
+		"de": "Nicht unterstützte Sprache: %s",
+	},
+	ErrorUnsupportedPair: {
+		"en": "unsupported language pair: %s→%s",
+		"es": "No se puede traducir de %s a %s",
+		"fr": "Impossible de traduire de %s vers %s",
+		"it": "Impossibile tradurre da %s a %s",
+		"pt": "Não é possível traduzir de %s para %s",
+		"de": "Übersetzung von %s nach %s wird nicht unterstützt",
+	},
+	ErrorTranslationFailed: {
+		"en": "translation failed: %s",
+		"es": "La traducción ha fallado: %s",
+		"fr": "La traduction a échoué : %s",
+		"it": "La traduzione non è riuscita: %s",
+		"pt": "A tradução falhou: %s",
+		"de": "Die Übersetzung ist fehlgeschlagen: %s",
+	},
+	ErrorCostExceeded: {
+		"en": "estimated cost %g exceeds maxCost %g",
+		"es": "El coste estimado (%g) supera maxCost (%g)",
+		"fr": "Le coût estimé (%g) dépasse maxCost (%g)",
+		"it": "Il costo stimato (%g) supera maxCost (%g)",
+		"pt": "O custo estimado (%g) excede maxCost (%g)",
+		"de": "Die geschätzten Kosten (%g) überschreiten maxCost (%g)",
+	},
+	ErrorTimeout: {
+		"en": "timed out after %d of %d route steps",
+		"es": "Se agotó el tiempo tras completar %d de %d pasos de traducción",
+		"fr": "Délai dépassé après %d étapes de traduction sur %d",
+		"it": "Tempo scaduto dopo %d passaggi di traduzione su %d",
+		"pt": "Tempo esgotado após %d de %d passos de tradução",
+		"de": "Zeitüberschreitung nach %d von %d Übersetzungsschritten",
+	},
+	ErrorInProgress: {
+		"en": "a request with this idempotencyKey is still in progress",
+		"es": "Una solicitud con esta idempotencyKey todavía está en curso",
+		"fr": "Une requête avec cette idempotencyKey est encore en cours",
+		"it": "Una richiesta con questa idempotencyKey è ancora in corso",
+		"pt": "Um pedido com esta idempotencyKey ainda está em curso",
+		"de": "Eine Anfrage mit diesem idempotencyKey wird noch verarbeitet",
+	},
+	ErrorUnauthorized: {
+		"en": "a valid adminToken is required",
+		"es": "Se necesita un adminToken válido",
+		"fr": "Un adminToken valide est requis",
+		"it": "È necessario un adminToken valido",
+		"pt": "É necessário um adminToken válido",
+		"de": "Ein gültiges adminToken ist erforderlich",
+	},
+	ErrorUnavailable: {
+		"en": "%s",
+		"es": "Servicio no disponible temporalmente: %s",
+		"fr": "Service temporairement indisponible : %s",
+		"it": "Servizio temporaneamente non disponibile: %s",
+		"pt": "Serviço temporariamente indisponível: %s",
+		"de": "Dienst vorübergehend nicht verfügbar: %s",
+	},
+	ErrorInternal: {
+		"en": "%s",
+		"es": "Error interno: %s",
+		"fr": "Erreur interne : %s",
+		"it": "Errore interno: %s",
+		"pt": "Erro interno: %s",
+		"de": "Interner Fehler: %s",
+	},
+}
+
+// errorResponse returns a failed response with a stable code. The message
+// is in English until localizeError translates it.
+func errorResponse(code string, args ...any) *Response {
+	if args == nil {
+		args = []any{}
+	}
+	resp := NewErrorResponse(code, errorMessage(code, defaultErrorLocale, args))
+	resp.errorArgs = args
+	return resp
+}
+
+// finishError completes the error of resp with the language pair of req
+// and localizes its message.
+func finishError(resp *Response, req Request) {
+	if resp == nil || resp.Error == nil {
+		return
+	}
+	if resp.Error.LanguagePair == nil && req.SourceLang != "" && req.TargetLang != "" {
+		resp.Error.LanguagePair = &LanguagePair{SourceLang: req.SourceLang, TargetLang: req.TargetLang}
+	}
+	localizeError(resp, req.ErrorLocale)
+}
+
+// localizeError rewrites the error message of resp in lang. Responses
+// restored from a job result keep the language they were stored in.
+func localizeError(resp *Response, lang string) {
+	if resp == nil || resp.Error == nil || resp.errorArgs == nil || lang == "" {
+		return
+	}
+	resp.Error.Message = errorMessage(resp.ErrorCode, lang, resp.errorArgs)
+}
+
+// errorMessage formats the message of code in lang, falling back to its
+// base language and then to English.
+func errorMessage(code, lang string, args []any) string {
+	messages := errorMessages[code]
+	if canonical, ok := locale.Canonicalize(lang); ok {
+		lang = canonical
+	}
+	format, ok := messages[lang]
+	if !ok {
+		format, ok = messages[locale.Base(lang)]
+	}
+	if !ok {
+		format = messages[defaultErrorLocale]
+	}
+	return fmt.Sprintf(format, args...)
+}
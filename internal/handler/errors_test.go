@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"", "unsupported language pair: es→xx"},
+		{"en", "unsupported language pair: es→xx"},
+		{"es", "No se puede traducir de es a xx"},
+		{"pt-BR", "Não é possível traduzir de es para xx"},
+		{"german", "Übersetzung von es nach xx wird nicht unterstützt"},
+		{"ja", "unsupported language pair: es→xx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			resp := errorResponse(ErrorUnsupportedPair, "es", "xx")
+			localizeError(resp, tt.lang)
+			if resp.Error.Message != tt.want || resp.ErrorCode != ErrorUnsupportedPair {
+				t.Errorf("localized error = %q (%s), want %q", resp.Error, resp.ErrorCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorMessages_AllLocales(t *testing.T) {
+	for code, messages := range errorMessages {
+		if _, ok := messages[defaultErrorLocale]; !ok {
+			t.Errorf("%s has no %s message", code, defaultErrorLocale)
+		}
+		for _, lang := range []string{"es", "fr", "it", "pt", "de"} {
+			if _, ok := messages[lang]; !ok {
+				t.Errorf("%s has no %s message", code, lang)
+			}
+		}
+	}
+}
+
+func TestLocalizeError_StoredResult(t *testing.T) {
+	// Job results decoded from JSON have no message arguments
+	resp := NewErrorResponse(ErrorTranslationFailed, "translation failed: timeout")
+	localizeError(resp, "es")
+	if resp.Error.Message != "translation failed: timeout" {
+		t.Errorf("Error = %q, want the stored message", resp.Error)
+	}
+}
+
+func TestHandle_LocalizedError(t *testing.T) {
+	resp, err := NewHandler(&stubTranslator{}).Handle(context.Background(), Request{
+		Texts:       []string{"Hola"},
+		SourceLang:  "es",
+		TargetLang:  "es",
+		ErrorLocale: "fr",
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	want := "Requête non valide : sourceLang and targetLang must be different"
+	if resp.Error.Message != want || resp.ErrorCode != ErrorInvalidRequest {
+		t.Errorf("Handle() error = %q (%s), want %q", resp.Error, resp.ErrorCode, want)
+	}
+	if pair := resp.Error.LanguagePair; pair == nil || pair.SourceLang != "es" || pair.TargetLang != "es" || resp.Error.Retryable {
+		t.Errorf("Handle() error = %+v, want the es→es pair and not retryable", resp.Error)
+	}
+}
+
+func TestErrorInfo_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ErrorInfo
+	}{
+		{"structured", `{"code":"TIMEOUT","message":"timed out","retryable":true}`, ErrorInfo{Code: ErrorTimeout, Message: "timed out", Retryable: true}},
+		{"stored message", `"translation failed: timeout"`, ErrorInfo{Message: "translation failed: timeout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ErrorInfo
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil || got != tt.want {
+				t.Errorf("Unmarshal() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
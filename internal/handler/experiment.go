@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/experiment"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// assignExperiment buckets the request by tenant, or by a hash of its texts
+// when no tenant is given, so identical requests get the same variant.
+func assignExperiment(set *experiment.Set, req Request) *experiment.Assignment {
+	unit := req.TenantID
+	if unit == "" {
+		sum := sha256.Sum256([]byte(strings.Join(req.Texts, "\x00")))
+		unit = hex.EncodeToString(sum[:8])
+	}
+	return set.Assign(languagePair(req), req.TenantID, unit)
+}
+
+// experimentSettings returns the chunk size and function overrides of the
+// assigned variant, falling back to the defaults.
+func experimentSettings(a *experiment.Assignment) (maxTexts int, overrides map[string]string) {
+	maxTexts = chunker.DefaultMaxTextsPerChunk
+	if a == nil {
+		return maxTexts, nil
+	}
+	if a.Settings.MaxTextsPerChunk > 0 {
+		maxTexts = a.Settings.MaxTextsPerChunk
+	}
+	return maxTexts, a.Settings.FunctionOverrides
+}
+
+// recordExperiment tags request count and latency metrics with the variant.
+func recordExperiment(rec *metrics.Recorder, a *experiment.Assignment, req Request, duration time.Duration, failed bool) {
+	if a == nil {
+		return
+	}
+	dims := metrics.Dimensions{
+		"Experiment":   a.Experiment,
+		"Variant":      a.Variant,
+		"LanguagePair": languagePair(req),
+	}
+	rec.Add("ExperimentRequests", metrics.Count, 1, dims)
+	rec.Add("ExperimentLatency", metrics.Milliseconds, float64(duration.Milliseconds()), dims)
+	if failed {
+		rec.Add("ExperimentErrors", metrics.Count, 1, dims)
+	}
+}
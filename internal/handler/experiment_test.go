@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/experiment"
+)
+
+func TestExperimentSettings(t *testing.T) {
+	set, err := experiment.New(experiment.Config{Experiments: []experiment.Experiment{{
+		Name:    "small-chunks",
+		Enabled: true,
+		Variants: []experiment.Variant{{
+			Name:              "small",
+			Weight:            1,
+			MaxTextsPerChunk:  10,
+			FunctionOverrides: map[string]string{"pricofy-translator-en-romance": "pricofy-translator-en-romance-v2"},
+		}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "fr"}
+	a := assignExperiment(set, req)
+	if a == nil || a.Variant != "small" {
+		t.Fatalf("assignExperiment() = %+v, want variant small", a)
+	}
+
+	maxTexts, overrides := experimentSettings(a)
+	if maxTexts != 10 || overrides["pricofy-translator-en-romance"] != "pricofy-translator-en-romance-v2" {
+		t.Errorf("experimentSettings() = %d, %v", maxTexts, overrides)
+	}
+
+	maxTexts, overrides = experimentSettings(nil)
+	if maxTexts != chunker.DefaultMaxTextsPerChunk || overrides != nil {
+		t.Errorf("experimentSettings(nil) = %d, %v; want defaults", maxTexts, overrides)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field groups accepted in Request.Fields.
+const (
+	// FieldTranslations is the core contract: translations and chunksProcessed.
+	FieldTranslations = "translations"
+	// FieldPivot adds the route taken (translator steps and pivot language).
+	FieldPivot = "pivot"
+	// FieldDebug adds chunk sizes and timings.
+	FieldDebug = "debug"
+	// FieldQuality adds confidence and lowConfidence.
+	FieldQuality = "quality"
+	// FieldLocale adds the rendering metadata of the target locale.
+	FieldLocale = "locale"
+)
+
+// defaultFields is the projection used when Request.Fields is empty.
+var defaultFields = []string{FieldTranslations, FieldQuality}
+
+var knownFields = map[string]bool{
+	FieldTranslations: true,
+	FieldPivot:        true,
+	FieldDebug:        true,
+	FieldQuality:      true,
+	FieldLocale:       true,
+}
+
+// RouteInfo describes how a request was routed (FieldPivot).
+type RouteInfo struct {
+	Steps []string `json:"steps"`
+	// PivotLang is the first intermediate language of a multi-step route,
+	// and Pivots lists all of them in order.
+	PivotLang string   `json:"pivotLang,omitempty"`
+	Pivots    []string `json:"pivots,omitempty"`
+	// Tagged lists the steps of the texts of each tag routed apart.
+	Tagged map[string][]string `json:"tagged,omitempty"`
+}
+
+// DebugInfo carries diagnostics for a request (FieldDebug).
+type DebugInfo struct {
+	ChunkSizes   []int          `json:"chunkSizes"`
+	DurationMs   int64          `json:"durationMs"`
+	PostEditHits map[string]int `json:"postEditHits,omitempty"`
+	// Substitutions counts the terms rewritten for the regional variant of
+	// the target language.
+	Substitutions int `json:"substitutions,omitempty"`
+	// Dispatch is the dispatch strategy of each route step, "single", "fanout"
+	// or "pipelined".
+	Dispatch []string `json:"dispatch,omitempty"`
+	// Cost is the estimated cost of the route under the cost model.
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// validateFields checks every requested field group is known.
+func validateFields(fields []string) error {
+	for _, f := range fields {
+		if !knownFields[f] {
+			return fmt.Errorf("unknown field %q (expected one of: %s)", f,
+				strings.Join([]string{FieldTranslations, FieldPivot, FieldDebug, FieldQuality, FieldLocale}, ", "))
+		}
+	}
+	return nil
+}
+
+// fieldSet resolves the requested projection. Translations are always included.
+func fieldSet(fields []string) map[string]bool {
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	set := map[string]bool{FieldTranslations: true}
+	for _, f := range fields {
+		set[f] = true
+	}
+	return set
+}
+
+// shapeResponse drops every optional group that was not requested.
+func shapeResponse(resp *Response, fields map[string]bool) {
+	if !fields[FieldPivot] {
+		resp.Route = nil
+	}
+	if !fields[FieldDebug] {
+		resp.Debug = nil
+	}
+	if !fields[FieldQuality] {
+		resp.Confidence = nil
+		resp.LowConfidence = nil
+	}
+	if !fields[FieldLocale] {
+		resp.Locale = nil
+	}
+}
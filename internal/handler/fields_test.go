@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+func TestValidateFields(t *testing.T) {
+	if err := validateFields([]string{FieldTranslations, FieldPivot, FieldDebug, FieldQuality, FieldLocale}); err != nil {
+		t.Errorf("validateFields() unexpected error: %v", err)
+	}
+	if err := validateFields([]string{"everything"}); err == nil {
+		t.Error("validateFields() should reject unknown fields")
+	}
+}
+
+func TestShapeResponse(t *testing.T) {
+	newResponse := func() *Response {
+		return &Response{
+			Translations:  []string{"hola"},
+			Confidence:    []float64{0.9},
+			LowConfidence: []int{0},
+			Route:         &RouteInfo{Steps: []string{"a", "b"}, PivotLang: "en"},
+			Debug:         &DebugInfo{ChunkSizes: []int{1}},
+			Locale:        &locale.Info{Locale: "es", Direction: locale.LeftToRight},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		fields      []string
+		wantRoute   bool
+		wantDebug   bool
+		wantQuality bool
+		wantLocale  bool
+	}{
+		{"default", nil, false, false, true, false},
+		{"translations only", []string{FieldTranslations}, false, false, false, false},
+		{"pivot", []string{FieldPivot}, true, false, false, false},
+		{"locale", []string{FieldLocale}, false, false, false, true},
+		{"everything", []string{FieldPivot, FieldDebug, FieldQuality, FieldLocale}, true, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newResponse()
+			shapeResponse(resp, fieldSet(tt.fields))
+
+			if resp.Translations == nil {
+				t.Error("translations must always be included")
+			}
+			if (resp.Route != nil) != tt.wantRoute {
+				t.Errorf("Route present = %v, want %v", resp.Route != nil, tt.wantRoute)
+			}
+			if (resp.Debug != nil) != tt.wantDebug {
+				t.Errorf("Debug present = %v, want %v", resp.Debug != nil, tt.wantDebug)
+			}
+			if (resp.Confidence != nil) != tt.wantQuality {
+				t.Errorf("Confidence present = %v, want %v", resp.Confidence != nil, tt.wantQuality)
+			}
+			if (resp.Locale != nil) != tt.wantLocale {
+				t.Errorf("Locale present = %v, want %v", resp.Locale != nil, tt.wantLocale)
+			}
+		})
+	}
+}
@@ -4,76 +4,520 @@ package handler
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
-	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/pricofy/translation-manager/internal/accounting"
+	"github.com/pricofy/translation-manager/internal/batch"
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/capture"
+	"github.com/pricofy/translation-manager/internal/domain"
+	"github.com/pricofy/translation-manager/internal/experiment"
+	"github.com/pricofy/translation-manager/internal/idempotency"
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/notify"
+	"github.com/pricofy/translation-manager/internal/profile"
 	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+	"github.com/pricofy/translation-manager/internal/tracing"
 )
 
-// Request is the input to the translation manager.
-type Request struct {
-	Texts      []string `json:"texts"`
-	SourceLang string   `json:"sourceLang"`
-	TargetLang string   `json:"targetLang"`
-}
+// Request is the input to the translation manager (see domain.Request).
+type Request = domain.Request
 
 // Response is the output from the translation manager.
 type Response struct {
-	Translations    []string `json:"translations"`
-	ChunksProcessed int      `json:"chunksProcessed"`
-	Error           string   `json:"error,omitempty"`
+	Translations    []string   `json:"translations"`
+	ChunksProcessed int        `json:"chunksProcessed"`
+	Confidence      []float64  `json:"confidence,omitempty"`
+	LowConfidence   []int      `json:"lowConfidence,omitempty"`
+	Route           *RouteInfo `json:"route,omitempty"`
+	Debug           *DebugInfo `json:"debug,omitempty"`
+	// Document and Segments are set for single-document (Text) requests:
+	// the reassembled translation and the source segments behind Translations.
+	Document string   `json:"document,omitempty"`
+	Segments []string `json:"segments,omitempty"`
+	// PO is the updated catalog of a Request.PO request; Segments are then
+	// the source texts behind Translations.
+	PO string `json:"po,omitempty"`
+	// Slugs are the URL slugs of Translations (Request.Slugs).
+	Slugs []string `json:"slugs,omitempty"`
+	// Truncated are Translations cut at a word boundary (Request.TruncatedTo).
+	Truncated []string `json:"truncated,omitempty"`
+	// Versions say what each translation was made from, by which model
+	// and when (Request.Hashes).
+	Versions []TranslationVersion `json:"versions,omitempty"`
+	// Intermediate are the translations into each pivot language of the
+	// route (Request.IncludeIntermediate).
+	Intermediate []IntermediateTexts `json:"intermediate,omitempty"`
+	// Keywords are the search keywords of each translation ("keywords" action).
+	Keywords [][]string `json:"keywords,omitempty"`
+	// Locale describes the target locale every translation is written in:
+	// script, text direction and HTML lang value (FieldLocale).
+	Locale *locale.Info `json:"locale,omitempty"`
+	// Experiment is the A/B variant that served the request, if any.
+	Experiment *experiment.Assignment `json:"experiment,omitempty"`
+	// Validation is the report of a "validate" action.
+	Validation *ValidationReport `json:"validation,omitempty"`
+	// Blocked lists translations containing tenant-forbidden terms.
+	Blocked []blocklist.Match `json:"blocked,omitempty"`
+	// JobID and Status describe async jobs.
+	JobID  string `json:"jobId,omitempty"`
+	Status string `json:"status,omitempty"`
+	// Batch is the progress of a batch job (Request.Mode "batch").
+	Batch *batch.Progress `json:"batch,omitempty"`
+	// Progress counts the chunks of an async job and Output is where its
+	// result is written, in status lookups with JOBS_TABLE.
+	Progress *JobProgress `json:"progress,omitempty"`
+	Output   string       `json:"output,omitempty"`
+	// DeadLettered are the texts of a job whose chunks failed, left "" in
+	// Translations until a redrive (mode "redrive") translates them.
+	DeadLettered []int `json:"deadLettered,omitempty"`
+	// IdempotentReplay marks the stored response of an earlier request
+	// with the same idempotencyKey.
+	IdempotentReplay bool `json:"idempotentReplay,omitempty"`
+	// Languages is the canonical pair used when the request named its
+	// languages with aliases such as "spanish" or "pt-br".
+	Languages *LanguagePair `json:"languages,omitempty"`
+	// Catalog is the supported languages and pairs ("languages" action).
+	Catalog *router.Catalog `json:"catalog,omitempty"`
+	// Routes are the routing table entries listed or written ("routes" action).
+	Routes []routing.Entry `json:"routes,omitempty"`
+	// CacheStats are the translation cache lookups of the serving container
+	// ("cacheStats" action).
+	CacheStats *CacheStats `json:"cacheStats,omitempty"`
+	// Usage is the translation usage per caller and day ("usage" action).
+	Usage []accounting.Usage `json:"usage,omitempty"`
+	// Version describes the serving deployment ("version" action).
+	Version *VersionInfo `json:"version,omitempty"`
+	// Warnings are non-fatal problems, e.g. a risk of timing out.
+	Warnings []Warning `json:"warnings,omitempty"`
+	// Error describes a failed request: its code, message, whether a retry
+	// may succeed and, when known, the failed route step and language pair.
+	Error *ErrorInfo `json:"error,omitempty"`
+	// ErrorCode repeats Error.Code for callers that predate Error.
+	ErrorCode string `json:"errorCode,omitempty"`
+	// CostEstimate is the estimated cost of a request that failed with
+	// COST_EXCEEDED.
+	CostEstimate float64 `json:"costEstimate,omitempty"`
+	// Timeout tells how far a request that failed with TIMEOUT got.
+	Timeout *TimeoutInfo `json:"timeout,omitempty"`
+
+	// errorArgs are the message arguments of ErrorCode, for localizeError.
+	errorArgs []any
+}
+
+// Translator routes and translates chunks of texts; *router.Router
+// implements it.
+type Translator interface {
+	CanTranslate(source, target, backend string) bool
+	Plan(source, target, backend string) (*router.RoutePlan, error)
+	// Backends lists the backends a pair may be translated by with opts,
+	// routing table entries included.
+	Backends(ctx context.Context, source, target string, opts router.Options) []string
+	TranslateChunksWithOptions(ctx context.Context, source, target string, chunks [][]string, opts router.Options) (*router.Result, error)
+	// Functions and RoutesVersion describe the routing for the version
+	// action.
+	Functions() map[string]string
+	RoutesVersion(ctx context.Context) string
+	// Hops and TranslateHop run a route one hop at a time, for pipelines.
+	Hops(ctx context.Context, source, target string, opts router.Options) ([]router.Hop, error)
+	TranslateHop(ctx context.Context, hop router.Hop, step, steps int, chunks [][]string) (*router.Result, error)
+	// Probe pings every translator function for a deep health check.
+	Probe(ctx context.Context) []router.TargetHealth
+}
+
+var _ Translator = (*router.Router)(nil)
+
+// Handler processes requests with a Translator shared by all of them. The
+// entry points create one per container, around a single Router.
+type Handler struct {
+	translator Translator
+
+	// The stores the environment enables (see FromEnv); nil when disabled
+	ledger      *accounting.Ledger
+	adminTokens map[string]string
+	routes      *routing.Store
+	batches     *batch.Runner
+	alarms      *notify.Notifier
+	sampler     *capture.Sampler
+	keys        *idempotency.Store
+	jobs        *jobRunner
+	profiles    *profile.Store
+	cache       *cache.Cache
+}
+
+// NewHandler creates a Handler translating with t, without any store.
+func NewHandler(t Translator) *Handler {
+	return &Handler{translator: t}
+}
+
+// FromEnv creates a Handler translating with t and opens the stores its
+// environment enables. An error fails the container's initialization, so
+// the next container tries again instead of serving without the store.
+func FromEnv(ctx context.Context, t Translator) (*Handler, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	h := &Handler{
+		translator: t,
+		ledger:     loadLedger(cfg),
+		routes:     loadRoutes(cfg),
+		batches:    loadBatches(cfg),
+		alarms:     loadAlarms(cfg),
+		keys:       loadKeys(cfg),
+		profiles:   loadProfiles(cfg),
+	}
+	h.jobs = loadJobs(cfg, h.batches)
+	if h.adminTokens, err = loadAdminTokens(); err != nil {
+		return nil, err
+	}
+	if h.sampler, err = loadCapture(cfg); err != nil {
+		return nil, err
+	}
+	if h.cache, err = loadCache(cfg); err != nil {
+		return nil, err
+	}
+	return h, nil
 }
 
 // Handle processes a translation request.
-// It chunks the input texts and sends ALL chunks in a single Lambda invocation.
-// The translator Lambda processes each chunk sequentially internally.
-func Handle(ctx context.Context, req Request) (*Response, error) {
+// It chunks the input texts and sends them to each translator of the route in
+// a single invocation or fanned out across parallel invocations, whichever
+// that translator's recorded latencies predict is faster.
+func (h *Handler) Handle(ctx context.Context, req Request) (*Response, error) {
+	// The status mode is the status action
+	if req.Mode == ModeStatus {
+		req.Action, req.Mode = ActionStatus, ""
+	}
+
+	// Only background job runs write to a job they name
+	if err := checkJobID(ctx, req); err != nil {
+		resp := errorResponse(ErrorInvalidRequest, err.Error())
+		finishError(resp, req)
+		return resp, nil
+	}
+
+	// Redrives translate the dead-lettered chunks of a job again
+	if req.Mode == ModeRedrive {
+		resp, err := h.redrive(ctx, req)
+		finishError(resp, req)
+		return resp, err
+	}
+
+	// Tenant defaults for the options the caller left unset
+	if err := h.applyProfile(ctx, &req); err != nil {
+		resp := errorResponse(ErrorUnavailable, err.Error())
+		finishError(resp, req)
+		return resp, nil
+	}
+
+	// Map language names and tags to canonical codes
+	pair, warnings, err := resolveLanguages(&req)
+	if err != nil {
+		resp := errorResponse(ErrorUnsupportedLanguage, err.Error())
+		finishError(resp, req)
+		return resp, nil
+	}
+
+	// Catch swapped sourceLang/targetLang
+	if w := checkInversion(&req); w != nil {
+		warnings = append(warnings, *w)
+		if w.Code == WarningPairInvertedCorrected {
+			pair = &LanguagePair{SourceLang: req.SourceLang, TargetLang: req.TargetLang}
+		}
+	}
+
+	resp, err := h.handle(ctx, req)
+	if resp != nil {
+		resp.Languages = pair
+		warnings = append(deprecationWarnings(req), warnings...)
+		resp.Warnings = append(warnings, resp.Warnings...)
+	}
+	finishError(resp, req)
+	return resp, err
+}
+
+// handle processes a request with canonical languages.
+func (h *Handler) handle(ctx context.Context, req Request) (*Response, error) {
+	start := time.Now()
+
 	// Validate request
 	if err := validateRequest(req); err != nil {
-		return &Response{Error: err.Error()}, nil
+		return errorResponse(ErrorInvalidRequest, err.Error()), nil
+	}
+
+	// Dry runs are a forced backend from here on
+	if req.DryRun {
+		req.Backend = router.BackendDryRun
+	}
+
+	// Language catalog for client SDKs
+	if req.Action == ActionLanguages {
+		return &Response{Translations: []string{}, Catalog: router.GetCatalog()}, nil
+	}
+
+	// Runtime routing table changes
+	if req.Action == ActionRoutes {
+		return h.handleRoutes(ctx, req), nil
+	}
+
+	// Translation cache effectiveness of this container
+	if req.Action == ActionCacheStats {
+		return h.handleCacheStats(req), nil
+	}
+
+	// Translation usage per caller, for chargeback
+	if req.Action == ActionUsage {
+		return h.handleUsage(ctx, req), nil
+	}
+
+	// Build and configuration of this deployment
+	if req.Action == ActionVersion {
+		return h.handleVersion(ctx, req), nil
 	}
 
+	// Batches read their texts from S3 and always run as async jobs
+	if req.Mode == ModeBatch {
+		if _, err := h.batchRunner(); err != nil {
+			return errorResponse(ErrorUnavailable, err.Error()), nil
+		}
+		req.Async = true
+	}
+
+	// Retries with an idempotency key get the response of the first attempt
+	return h.idempotent(ctx, req, func() (*Response, error) { return h.process(ctx, req, start) })
+}
+
+// process runs a translation request, or submits it as an async job.
+func (h *Handler) process(ctx context.Context, req Request, start time.Time) (*Response, error) {
+	// Async jobs: status lookups and submissions return immediately
+	if resp := h.handleJob(ctx, req); resp != nil {
+		return resp, nil
+	}
+
+	// Background job runs are journaled so each job is processed once
+	run := h.translate
+	if req.Mode == ModeBatch {
+		run = h.translateBatch
+	}
+	if req.Async {
+		ctx = h.trackJob(ctx, req)
+		ctx = h.withDeadLetters(ctx, req)
+		return h.runJob(ctx, req, func() (*Response, error) { return run(ctx, req, start) })
+	}
+	return run(ctx, req, start)
+}
+
+// translate runs a translation request in this invocation, traced as an
+// X-Ray subsegment enclosing its translator calls.
+func (h *Handler) translate(ctx context.Context, req Request, start time.Time) (resp *Response, err error) {
+	rec := metrics.New(os.Stdout)
+	defer rec.Flush() //nolint:errcheck // metrics are best effort
+	defer recordUsage(rec, start)
+	ctx, seg := tracing.Start(ctx, "translate")
+	seg.Annotate("LanguagePair", languagePair(req))
+	defer func() { endTrace(seg, resp, err) }()
+
+	// Single-document mode: translate the document sentence by sentence
+	doc := prepareDocument(&req)
+	// HTML texts: translate their text nodes and attributes only
+	pages := prepareHTML(&req)
+	// PO catalogs: translate their untranslated and fuzzy entries
+	catalog := preparePO(&req)
+	resp, err = h.handleTexts(ctx, req, start, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrdering(resp, len(req.Texts)); err != nil {
+		resp = orderingViolation(err, rec)
+	}
+	recordOutcome(rec, req, resp, time.Since(start))
+	finishDocument(resp, doc, req.TargetLang)
+	finishHTML(resp, req, pages)
+	finishPO(resp, catalog)
+
+	if err := h.finishJob(ctx, req, resp); err != nil {
+		return nil, fmt.Errorf("failed to store job %s: %w", req.JobID, err)
+	}
+
+	return resp, nil
+}
+
+// handleTexts translates req.Texts.
+func (h *Handler) handleTexts(ctx context.Context, req Request, start time.Time, rec *metrics.Recorder) (*Response, error) {
 	// Empty input - return immediately
-	if len(req.Texts) == 0 {
+	if len(req.Texts) == 0 && req.Action != ActionValidate {
 		return &Response{Translations: []string{}, ChunksProcessed: 0}, nil
 	}
+	ctx, cancel := withTimeBudget(ctx, req)
+	defer cancel()
 
-	// Create router
-	r, err := router.New(ctx)
+	pol, err := containerPolicies()
 	if err != nil {
-		return &Response{Error: fmt.Sprintf("failed to create router: %v", err)}, nil
+		return errorResponse(ErrorUnavailable, err.Error()), nil
 	}
 
+	r := h.translator
+	ctx, retries := router.CountRetries(ctx)
+	defer func() { recordRetries(rec, req, retries()) }()
+
 	// Check if translation is possible (direct or via pivoting)
-	if !r.IsValidPair(req.SourceLang, req.TargetLang) {
-		return &Response{
-			Error: fmt.Sprintf("unsupported language pair: %s→%s", req.SourceLang, req.TargetLang),
-		}, nil
+	if !r.CanTranslate(req.SourceLang, req.TargetLang, req.Backend) {
+		return errorResponse(ErrorUnsupportedPair, req.SourceLang, req.TargetLang), nil
+	}
+
+	// A/B experiments may change chunk size and translator functions
+	assignment := assignExperiment(pol.experiments, req)
+	maxTexts, overrides := experimentSettings(assignment)
+
+	// Dry run: report what would happen without translating
+	if req.Action == ActionValidate {
+		return handleValidate(req, r, maxTexts), nil
+	}
+
+	grammar, err := markupGrammar(pol.markup, req)
+	if err != nil {
+		return errorResponse(ErrorInvalidRequest, err.Error()), nil
 	}
 
-	// Chunk texts (max 50 per chunk for optimal Lambda memory usage)
-	chunks := chunker.ChunkTexts(req.Texts, chunker.DefaultMaxTextsPerChunk)
+	// Hide unbreakable tokens and markup from the models and the token
+	// budgets, and personal data from external backends
+	req.MaskPII = masksPII(ctx, req, r)
+	masks, warnings := protectTexts(&req, grammar)
+	// Single attribute words and repeated texts need no model
+	known := h.takeKnownTexts(ctx, &req, overrides, rec)
+	// and a text repeated in the batch is translated once
+	dups := takeDuplicates(&req, rec)
+
+	// Texts too long for the models are translated in pieces of whole
+	// sentences, then chunked (max 50 per chunk for optimal Lambda memory
+	// usage) within the token budget of the route's translators
+	split := splitTexts(req)
+	plan, planErr := r.Plan(req.SourceLang, req.TargetLang, req.Backend)
+	pieces := req
+	pieces.Texts = split.Texts
+	pieces.ChunkMaxTokens = chunkTokens(req, plan)
+	chunks, order := scheduleChunks(pieces, maxTexts)
+	job, dead := jobTrackerFrom(ctx), deadLetterJobFrom(ctx)
+	job.addChunks(ctx, len(chunks))
+	if planErr == nil {
+		if w := checkDeadline(ctx, len(chunks), len(plan.Steps), rec); w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
 
-	// Send ALL chunks in a single Lambda invocation
-	// The translator processes them sequentially internally
-	chunkResults, err := r.TranslateChunks(ctx, req.SourceLang, req.TargetLang, chunks)
+	// Each route step sends the chunks in one invocation or fans them out,
+	// whichever its translator's recorded latencies say is faster
+	fields := fieldSet(req.Fields)
+	result, err := translateTagged(ctx, r, req, chunks, chunkTags(req, split, chunks, order), router.Options{
+		ReturnScores:      requestsScores(req),
+		FunctionOverrides: overrides,
+		PrewarmNextHop:    wantsPrewarm(req, len(chunks)),
+		MaxCost:           req.MaxCost,
+		Backend:           req.Backend,
+		Premium:           req.Quality == QualityPremium,
+		Formality:         req.Formality,
+		Progress:          job.progress(ctx),
+		DeadLetter:        dead != nil,
+	})
 	if err != nil {
-		return &Response{Error: fmt.Sprintf("translation failed: %v", err)}, nil
+		h.observeRoute(ctx, nil, err, nil, rec)
+		recordExperiment(rec, assignment, req, time.Since(start), true)
+		resp := translationFailure(err, time.Since(start), rec)
+		resp.Experiment = assignment
+		logTranslation(ctx, req, len(chunks), nil, time.Since(start), resp.ErrorCode)
+		return resp, nil
 	}
 
 	// Flatten results back to single list
-	allTranslations := make([]string, 0, len(req.Texts))
-	for _, chunkResult := range chunkResults {
-		allTranslations = append(allTranslations, chunkResult...)
+	intermediate := intermediateTexts(req, result, split, order, dups, known, masks)
+	failed := failedTexts(result, chunks, order, split, dups, known)
+	result, order = stitchPieces(split, req.TargetLang, result, order)
+	allTranslations := unchunk(result.Translations, order)
+	if len(allTranslations) != len(req.Texts) {
+		return orderingViolation(fmt.Errorf("%d translations for %d texts", len(allTranslations), len(req.Texts)), rec), nil
+	}
+	scores := translatorScores(result, order)
+	translatedAt := time.Now().UTC()
+	known.store(ctx, req, allTranslations, result, scores, translatedAt)
+	allTranslations = known.merge(&req, dups.expand(&req, allTranslations))
+	warnings = append(warnings, cacheMisses(req, known)...)
+	warnings = append(warnings, premiumFallback(result)...)
+	warnings = append(warnings, checkPlaceholders(req, allTranslations, masks)...)
+	restoreTexts(allTranslations, masks)
+
+	// Fix recurring model mistakes before quality checks
+	postEditHits := applyPostEdits(pol.postEdit, req, allTranslations, rec)
+	substitutions := applyVariants(pol.variants, req, allTranslations, rec)
+	applyContentTypes(req, allTranslations)
+	deadLettered, deadWarnings, err := dead.record(ctx, rec, result, chunks, failed, allTranslations)
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error()), nil
 	}
+	warnings = append(warnings, deadWarnings...)
 
-	return &Response{
+	resp := &Response{
 		Translations:    allTranslations,
 		ChunksProcessed: len(chunks),
-	}, nil
+		Intermediate:    intermediate,
+		Versions:        textVersions(req, known, result, translatedAt),
+		Warnings:        warnings,
+		DeadLettered:    deadLettered,
+	}
+
+	// Compliance check on the final wording
+	resp.Blocked = applyBlocklist(pol.blocklist, req, allTranslations, rec)
+	applyConfidence(resp, req, known.mergeScores(req, spreadDuplicates(dups, scores)))
+	applySlugs(resp, req)
+	applyTruncation(resp, req)
+	applyKeywords(resp, req, pol.keywords)
+	h.observeRoute(ctx, result, nil, resp.Confidence, rec)
+	recordSteps(rec, result)
+	recordWork(rec, req, chunks, result)
+	h.recordCallerUsage(ctx, rec, req, chunks, result)
+	recordBackendUsage(rec, req, chunks, result)
+	h.captureTranslations(ctx, req, resp.Translations, result)
+
+	resp.Route = &RouteInfo{Steps: result.Steps, PivotLang: result.PivotLang, Pivots: result.Pivots, Tagged: result.TagSteps}
+	info := locale.Describe(req.TargetLang)
+	resp.Locale = &info
+	resp.Debug = &DebugInfo{
+		ChunkSizes:    chunkSizes(chunks),
+		DurationMs:    time.Since(start).Milliseconds(),
+		PostEditHits:  postEditHits,
+		Substitutions: substitutions,
+		Dispatch:      result.Dispatches,
+		Cost:          result.Cost,
+	}
+	resp.Experiment = assignment
+	logTranslation(ctx, req, len(chunks), result.Steps, time.Since(start), "")
+	shapeResponse(resp, fields)
+	recordExperiment(rec, assignment, req, time.Since(start), false)
+
+	return resp, nil
 }
 
 // validateRequest checks the request is valid.
 func validateRequest(req Request) error {
+	switch req.Action {
+	case ActionLanguages, ActionCacheStats, ActionVersion:
+		return nil
+	case ActionRoutes:
+		return validateRoutesRequest(req)
+	case ActionUsage:
+		return validateUsageRequest(req)
+	case ActionStatus:
+		if req.JobID == "" {
+			return fmt.Errorf("jobId is required for the status action")
+		}
+		return nil
+	}
 	if req.SourceLang == "" {
 		return fmt.Errorf("sourceLang is required")
 	}
@@ -83,8 +527,72 @@ func validateRequest(req Request) error {
 	if req.SourceLang == req.TargetLang {
 		return fmt.Errorf("sourceLang and targetLang must be different")
 	}
-	if req.Texts == nil {
+	if req.Texts == nil && req.Text == "" && req.PO == "" && req.Mode != ModeBatch {
 		return fmt.Errorf("texts is required")
 	}
+	if len(req.Texts) > 0 && req.Text != "" {
+		return fmt.Errorf("texts and text are mutually exclusive")
+	}
+	return validateOptions(req)
+}
+
+// validateOptions checks the optional request fields, reporting the first problem.
+func validateOptions(req Request) error {
+	for _, err := range []error{
+		validateAction(req.Action),
+		validateInvertedPairAction(req.InvertedPairAction),
+		validateLongTokenPolicy(req.LongTokenPolicy),
+		validatePlaceholders(req.Placeholders),
+		validateMeasurementOptions(req),
+		validateChunkStrategy(req.ChunkStrategy),
+		validateChunkMaxTokens(req),
+		validateHTML(req),
+		validateDictionary(req.Dictionary),
+		validatePassthrough(req.Passthrough),
+		validateJob(req),
+		validateIdempotencyKey(req),
+		validateBatch(req),
+		validateFields(req.Fields),
+		validateConfidenceOptions(req),
+		validateSlugOptions(req),
+		validateTruncation(req),
+		validateMaxCost(req),
+		validateTimeout(req),
+		validateBackend(req.Backend, req.DryRun),
+		validateQuality(req),
+		validateKeywords(req),
+		validateContentTypes(req),
+		validateTags(req),
+		validateIntermediate(req),
+		validateHashes(req),
+		validateCallerID(req),
+		validatePO(req),
+	} {
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// flatten concatenates per-chunk results back into a single list.
+func flatten[T any](chunks [][]T) []T {
+	n := 0
+	for _, c := range chunks {
+		n += len(c)
+	}
+	all := make([]T, 0, n)
+	for _, c := range chunks {
+		all = append(all, c...)
+	}
+	return all
+}
+
+// chunkSizes returns the number of texts in each chunk.
+func chunkSizes(chunks [][]string) []int {
+	sizes := make([]int, len(chunks))
+	for i, c := range chunks {
+		sizes[i] = len(c)
+	}
+	return sizes
+}
@@ -1,9 +1,71 @@
 package handler
 
 import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
 )
 
+// stubTranslator translates every pair but es→es in one step, prefixing
+// the texts with the target language, and records the chunks it got.
+type stubTranslator struct {
+	chunks [][]string
+}
+
+func (s *stubTranslator) CanTranslate(source, target, _ string) bool { return source != target }
+
+func (s *stubTranslator) Plan(source, target, _ string) (*router.RoutePlan, error) {
+	return &router.RoutePlan{Steps: []string{"translator-" + source + "-" + target}}, nil
+}
+
+func (s *stubTranslator) Backends(context.Context, string, string, router.Options) []string {
+	return []string{router.BackendLambda}
+}
+
+func (s *stubTranslator) TranslateChunksWithOptions(_ context.Context, source, target string, chunks [][]string, _ router.Options) (*router.Result, error) {
+	s.chunks = append(s.chunks, chunks...)
+	out := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		out[i] = make([]string, len(chunk))
+		for j, text := range chunk {
+			out[i][j] = target + ":" + text
+		}
+	}
+	return &router.Result{Translations: out, Steps: []string{"translator-" + source + "-" + target}}, nil
+}
+
+func (s *stubTranslator) Functions() map[string]string {
+	return map[string]string{router.TranslatorRomanceEn: "translator-romance-en"}
+}
+
+func (s *stubTranslator) RoutesVersion(context.Context) string { return "" }
+
+func (s *stubTranslator) Hops(_ context.Context, source, target string, _ router.Options) ([]router.Hop, error) {
+	return []router.Hop{{Function: "translator-" + source + "-" + target, SourceLang: source, TargetLang: target}}, nil
+}
+
+func (s *stubTranslator) TranslateHop(ctx context.Context, hop router.Hop, _, _ int, chunks [][]string) (*router.Result, error) {
+	return s.TranslateChunksWithOptions(ctx, hop.SourceLang, hop.TargetLang, chunks, router.Options{})
+}
+
+func (s *stubTranslator) Probe(context.Context) []router.TargetHealth {
+	return []router.TargetHealth{{Translator: router.TranslatorRomanceEn, Function: "translator-romance-en", Status: router.HealthOK}}
+}
+
+// newRouter returns a Router configured from the test environment.
+func newRouter(t *testing.T) *router.Router {
+	t.Helper()
+	r, err := router.New(context.Background())
+	if err != nil {
+		t.Fatalf("router.New() error = %v", err)
+	}
+	return r
+}
+
 func TestValidateRequest(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -69,6 +131,27 @@ func TestValidateRequest(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "forced backend",
+			request: Request{
+				Texts:      []string{"Hola"},
+				SourceLang: "es",
+				TargetLang: "ja",
+				Backend:    "aws-translate",
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown backend",
+			request: Request{
+				Texts:      []string{"Hola"},
+				SourceLang: "es",
+				TargetLang: "en",
+				Backend:    "google",
+			},
+			expectError: true,
+			errorMsg:    `unknown backend "google"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -98,10 +181,49 @@ func TestHandle_EmptyTexts(t *testing.T) {
 		TargetLang: "fr",
 	}
 
-	// This test verifies the early return for empty input
-	// The actual Handle function would need a mock router for full testing
-	err := validateRequest(req)
+	translator := &stubTranslator{}
+	resp, err := NewHandler(translator).Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() with empty texts = %+v, %v", resp, err)
+	}
+	if len(resp.Translations) != 0 || len(translator.chunks) != 0 {
+		t.Errorf("Handle() with empty texts = %q, translated %d chunks", resp.Translations, len(translator.chunks))
+	}
+}
+
+func TestHandler_Handle(t *testing.T) {
+	texts := make([]string, 120)
+	want := make([]string, len(texts))
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i%7+1) + strconv.Itoa(i)
+		want[i] = "fr:" + texts[i]
+	}
+	translator := &stubTranslator{}
+	h := NewHandler(translator)
+
+	resp, err := h.Handle(context.Background(), Request{Texts: texts, SourceLang: "es", TargetLang: "fr"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	if !reflect.DeepEqual(resp.Translations, want) {
+		t.Errorf("Handle() translations out of order: %q", resp.Translations)
+	}
+	if len(translator.chunks) != resp.ChunksProcessed || len(translator.chunks) < 3 {
+		t.Errorf("translator got %d chunks, response reports %d", len(translator.chunks), resp.ChunksProcessed)
+	}
+}
+
+func TestHandle_Languages(t *testing.T) {
+	req, err := ParseRequest([]byte(`{"action": "languages"}`))
 	if err != nil {
-		t.Errorf("Empty texts should be valid: %v", err)
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	resp, err := NewHandler(&stubTranslator{}).Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	if resp.Catalog == nil || resp.Catalog.Version == "" || resp.Catalog.Pairs["es"]["fr"] != 2 {
+		t.Errorf("Catalog = %+v", resp.Catalog)
 	}
 }
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Statuses of a health check.
+const (
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// Checks of every health check.
+const (
+	CheckAWSConfig = "awsConfig"
+	CheckRoutes    = "routes"
+)
+
+// HealthCheck is the outcome of one check, router.HealthOK or
+// router.HealthFailed.
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Health is the outcome of a health check.
+type Health struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+	// Targets are the probed translator functions of a deep check.
+	Targets []router.TargetHealth `json:"targets,omitempty"`
+}
+
+// Healthcheck verifies the container can serve translations: the AWS
+// config loads and the routing table has translators. A deep check also
+// probes every translator function (see router.Router.Probe). Any failed
+// check or probe makes the container unhealthy.
+func (h *Handler) Healthcheck(ctx context.Context, deep bool) *Health {
+	health := &Health{Status: HealthHealthy}
+	check := func(name, detail string, err error) {
+		c := HealthCheck{Name: name, Status: router.HealthOK, Detail: detail}
+		if err != nil {
+			c.Status, c.Detail = router.HealthFailed, err.Error()
+			health.Status = HealthUnhealthy
+		}
+		health.Checks = append(health.Checks, c)
+	}
+
+	_, err := config.LoadDefaultConfig(ctx)
+	check(CheckAWSConfig, "", err)
+
+	functions := h.translator.Functions()
+	err = nil
+	if len(functions) == 0 {
+		err = fmt.Errorf("no translators configured")
+	}
+	check(CheckRoutes, fmt.Sprintf("%d translators", len(functions)), err)
+
+	if deep {
+		health.Targets = h.translator.Probe(ctx)
+		for _, target := range health.Targets {
+			if target.Status == router.HealthFailed {
+				health.Status = HealthUnhealthy
+			}
+		}
+	}
+	return health
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// downTranslator is a stubTranslator whose translator functions fail their
+// probes.
+type downTranslator struct {
+	stubTranslator
+}
+
+func (d *downTranslator) Probe(context.Context) []router.TargetHealth {
+	return []router.TargetHealth{{Translator: router.TranslatorRomanceEn, Status: router.HealthFailed, Error: "function not found"}}
+}
+
+func TestHealthcheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		translator  Translator
+		deep        bool
+		profile     string
+		wantStatus  string
+		wantTargets int
+	}{
+		{"shallow", &stubTranslator{}, false, "", HealthHealthy, 0},
+		{"deep", &stubTranslator{}, true, "", HealthHealthy, 1},
+		{"shallow with a translator down", &downTranslator{}, false, "", HealthHealthy, 0},
+		{"deep with a translator down", &downTranslator{}, true, "", HealthUnhealthy, 1},
+		{"broken AWS config", &stubTranslator{}, false, "missing-profile", HealthUnhealthy, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", tt.profile)
+			health := NewHandler(tt.translator).Healthcheck(context.Background(), tt.deep)
+			if health.Status != tt.wantStatus || len(health.Targets) != tt.wantTargets {
+				t.Errorf("Healthcheck() = %+v, want %s with %d targets", health, tt.wantStatus, tt.wantTargets)
+			}
+			if len(health.Checks) != 2 || health.Checks[0].Name != CheckAWSConfig || health.Checks[1].Name != CheckRoutes {
+				t.Errorf("checks = %+v, want %s and %s", health.Checks, CheckAWSConfig, CheckRoutes)
+			}
+		})
+	}
+}
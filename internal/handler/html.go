@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/htmltext"
+)
+
+// prepareHTML parses the texts of a ChunkHTML request as HTML and uses their
+// translatable units as the texts to translate, so markup never reaches the
+// models. Per-text content types apply to every unit of their text. It
+// returns nil for other requests.
+func prepareHTML(req *Request) []*htmltext.Page {
+	if req.ChunkStrategy != ChunkHTML {
+		return nil
+	}
+	pages := make([]*htmltext.Page, len(req.Texts))
+	var units, contentTypes []string
+	for i, text := range req.Texts {
+		pages[i] = htmltext.ParseWith(text, htmlOptions(*req))
+		texts := pages[i].Texts()
+		units = append(units, texts...)
+		for range texts {
+			if len(req.ContentTypes) > 0 {
+				contentTypes = append(contentTypes, req.ContentTypes[i])
+			}
+		}
+	}
+	req.Texts = units
+	if len(req.ContentTypes) > 0 {
+		req.ContentTypes = contentTypes
+	}
+	return pages
+}
+
+// finishHTML reassembles every page from the translated units, and maps the
+// per-unit confidence, warnings and blocklist matches to the page of each
+// unit. A page is withheld when any of its units is.
+func finishHTML(resp *Response, req Request, pages []*htmltext.Page) {
+	if pages == nil || resp.Error != nil {
+		return
+	}
+	owner := make([]int, 0, len(resp.Translations))
+	translations := make([]string, len(pages))
+	next := 0
+	for i, page := range pages {
+		n := len(page.Texts())
+		translations[i] = page.Render(resp.Translations[next : next+n])
+		for j := 0; j < n; j++ {
+			owner = append(owner, i)
+		}
+		next += n
+	}
+	resp.Translations = translations
+
+	resp.Confidence = pageConfidence(resp.Confidence, owner, len(pages))
+	resp.LowConfidence = pageIndices(resp.LowConfidence, owner)
+	if req.LowConfidenceAction == LowConfidenceWithhold {
+		for _, i := range resp.LowConfidence {
+			resp.Translations[i] = ""
+		}
+	}
+	for i, w := range resp.Warnings {
+		if w.Index != nil {
+			page := owner[*w.Index]
+			resp.Warnings[i].Index = &page
+		}
+	}
+	resp.Blocked = pageMatches(resp.Blocked, owner)
+	for _, m := range resp.Blocked {
+		if m.Action == blocklist.Reject {
+			resp.Translations[m.Index] = ""
+		}
+	}
+}
+
+// pageConfidence is the lowest unit confidence of every page; a page without
+// units is certain.
+func pageConfidence(confidence []float64, owner []int, pages int) []float64 {
+	if confidence == nil {
+		return nil
+	}
+	out := make([]float64, pages)
+	for i := range out {
+		out[i] = 1
+	}
+	for i, c := range confidence {
+		out[owner[i]] = min(out[owner[i]], c)
+	}
+	return out
+}
+
+// pageIndices maps sorted unit indices to their distinct pages.
+func pageIndices(units []int, owner []int) []int {
+	var pages []int
+	for _, u := range units {
+		if n := len(pages); n == 0 || pages[n-1] != owner[u] {
+			pages = append(pages, owner[u])
+		}
+	}
+	return pages
+}
+
+// pageMatches maps blocklist matches to pages, merging the terms of the
+// units of a page.
+func pageMatches(matches []blocklist.Match, owner []int) []blocklist.Match {
+	var pages []blocklist.Match
+	for _, m := range matches {
+		m.Index = owner[m.Index]
+		if n := len(pages); n > 0 && pages[n-1].Index == m.Index {
+			pages[n-1].Terms = append(pages[n-1].Terms, m.Terms...)
+			continue
+		}
+		pages = append(pages, m)
+	}
+	return pages
+}
+
+// htmlTagSpans returns the inline tags of a unit of a ChunkHTML request.
+func htmlTagSpans(req *Request, text string) []protectedSpan {
+	if req.ChunkStrategy != ChunkHTML {
+		return nil
+	}
+	var spans []protectedSpan
+	for _, s := range htmltext.Tags(text) {
+		spans = append(spans, protectedSpan{Span: s})
+	}
+	return spans
+}
+
+// htmlOptions returns the attributes and <meta> names req translates.
+func htmlOptions(req Request) htmltext.Options {
+	return htmltext.Options{Attributes: req.HTMLAttributes, Meta: req.HTMLMeta}
+}
+
+// validateHTML checks that a ChunkHTML request only asks for what applies to
+// whole HTML texts and translates only human-readable attributes. Outside
+// html chunking, htmlAttributes and htmlMeta are ignored, so a tenant
+// profile may set them for every request.
+func validateHTML(req Request) error {
+	if req.ChunkStrategy != ChunkHTML {
+		return nil
+	}
+	if err := htmlOptions(req).Validate(); err != nil {
+		return err
+	}
+	switch {
+	case req.Text != "":
+		return fmt.Errorf("chunkStrategy html is not supported with text; send HTML in texts")
+	case req.Slugs:
+		return fmt.Errorf("slugs are not supported with chunkStrategy html")
+	case req.TruncatedTo > 0:
+		return fmt.Errorf("truncatedTo is not supported with chunkStrategy html")
+	case req.Action == ActionKeywords:
+		return fmt.Errorf("the keywords action does not support chunkStrategy html")
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+)
+
+func TestHTMLMode(t *testing.T) {
+	req := Request{
+		Texts:         []string{"<p>Camisa <b>roja</b></p><p>Nueva</p>", "<img alt=\"Foto\">", "Pantalón"},
+		ContentTypes:  []string{ContentDescription, ContentDescription, ContentTitle},
+		ChunkStrategy: ChunkHTML,
+	}
+
+	pages := prepareHTML(&req)
+	if pages == nil {
+		t.Fatal("prepareHTML() returned nil for an html request")
+	}
+	wantTexts := []string{"Camisa <b>roja</b>", "Nueva", "Foto", "Pantalón"}
+	if !reflect.DeepEqual(req.Texts, wantTexts) {
+		t.Fatalf("prepareHTML() texts = %q, want %q", req.Texts, wantTexts)
+	}
+	wantTypes := []string{ContentDescription, ContentDescription, ContentDescription, ContentTitle}
+	if !reflect.DeepEqual(req.ContentTypes, wantTypes) {
+		t.Errorf("prepareHTML() contentTypes = %q, want %q", req.ContentTypes, wantTypes)
+	}
+
+	index := 1
+	resp := &Response{
+		Translations:  []string{"<b>Red</b> shirt", "New", "Photo", "Trousers"},
+		Confidence:    []float64{0.9, 0.4, 0.8, 0.7},
+		LowConfidence: []int{1},
+		Warnings:      []Warning{{Code: WarningCacheMiss, Index: &index}},
+		Blocked:       []blocklist.Match{{Index: 0, Terms: []string{"a"}}, {Index: 1, Terms: []string{"b"}}},
+	}
+	finishHTML(resp, req, pages)
+
+	want := []string{"<p><b>Red</b> shirt</p><p>New</p>", `<img alt="Photo">`, "Trousers"}
+	if !reflect.DeepEqual(resp.Translations, want) {
+		t.Errorf("Translations = %q, want %q", resp.Translations, want)
+	}
+	if want := []float64{0.4, 0.8, 0.7}; !reflect.DeepEqual(resp.Confidence, want) {
+		t.Errorf("Confidence = %v, want %v", resp.Confidence, want)
+	}
+	if want := []int{0}; !reflect.DeepEqual(resp.LowConfidence, want) {
+		t.Errorf("LowConfidence = %v, want %v", resp.LowConfidence, want)
+	}
+	if got := *resp.Warnings[0].Index; got != 0 {
+		t.Errorf("warning index = %d, want 0", got)
+	}
+	if want := []blocklist.Match{{Index: 0, Terms: []string{"a", "b"}}}; !reflect.DeepEqual(resp.Blocked, want) {
+		t.Errorf("Blocked = %+v, want %+v", resp.Blocked, want)
+	}
+}
+
+func TestFinishHTML_Withheld(t *testing.T) {
+	req := Request{Texts: []string{"<p>Uno</p><p>Dos</p>", "<p>Tres</p>"}, ChunkStrategy: ChunkHTML, LowConfidenceAction: LowConfidenceWithhold}
+	pages := prepareHTML(&req)
+
+	resp := &Response{Translations: []string{"One", "", "Three"}, LowConfidence: []int{1}}
+	finishHTML(resp, req, pages)
+
+	if want := []string{"", "<p>Three</p>"}; !reflect.DeepEqual(resp.Translations, want) {
+		t.Errorf("Translations = %q, want %q", resp.Translations, want)
+	}
+}
+
+func TestPrepareHTML_Attributes(t *testing.T) {
+	req := Request{
+		Texts:          []string{`<meta property="og:title" content="Camisa"><img alt="Foto" title="Detalle">`},
+		ChunkStrategy:  ChunkHTML,
+		HTMLAttributes: []string{"title"},
+		HTMLMeta:       []string{"og:title"},
+	}
+	prepareHTML(&req)
+	if want := []string{"Camisa", "Detalle"}; !reflect.DeepEqual(req.Texts, want) {
+		t.Errorf("prepareHTML() texts = %q, want %q", req.Texts, want)
+	}
+}
+
+func TestPrepareHTML_OtherStrategies(t *testing.T) {
+	req := Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkBalanced}
+	if pages := prepareHTML(&req); pages != nil {
+		t.Error("prepareHTML() should ignore non-html requests")
+	}
+	if req.Texts[0] != "<p>Hola</p>" {
+		t.Errorf("texts = %q, want them untouched", req.Texts)
+	}
+}
+
+func TestProtectTexts_HTMLTags(t *testing.T) {
+	req := Request{Texts: []string{`Ver <a href="https://example.com/a-very-long-product-url-that-is-a-long-token">más</a>`}, ChunkStrategy: ChunkHTML}
+	masks, warnings := protectTexts(&req, nil)
+
+	if want := "Ver __0__más__1__"; req.Texts[0] != want {
+		t.Errorf("masked text = %q, want %q", req.Texts[0], want)
+	}
+	if len(masks[0]) != 2 || len(warnings) != 0 {
+		t.Errorf("masks = %q, warnings = %v; want the two tags and no long-token warning", masks[0], warnings)
+	}
+}
+
+func TestValidateHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr string
+	}{
+		{"html texts", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML}, ""},
+		{"other strategy", Request{Text: "Hola", Slugs: true}, ""},
+		{"text", Request{Text: "<p>Hola</p>", ChunkStrategy: ChunkHTML}, "not supported with text"},
+		{"slugs", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, Slugs: true}, "slugs"},
+		{"keywords", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, Action: ActionKeywords}, "keywords"},
+		{"attributes", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, HTMLAttributes: []string{"alt"}, HTMLMeta: []string{"og:title"}}, ""},
+		{"data attribute", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, HTMLAttributes: []string{"data-name"}}, "never translated"},
+		{"unknown meta", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, HTMLMeta: []string{"viewport"}}, "cannot be translated"},
+		{"attributes outside html", Request{Texts: []string{"Hola"}, HTMLAttributes: []string{"href"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTML(tt.req)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateHTML() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateHTML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
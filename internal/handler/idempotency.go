@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/pricofy/translation-manager/internal/idempotency"
+)
+
+// Request.IdempotencyKey makes retries safe: the first request with a key
+// claims it in IDEMPOTENCY_TABLE and stores its response, and later
+// requests with the same key, from client retries or duplicate queue
+// messages, get that response back instead of invoking the translators.
+// Keys are scoped to the tenant and only successful responses are stored,
+// so a retry after an error runs again.
+
+// idempotencyKeyPattern is 1-128 printable ASCII characters without spaces,
+// which fits UUIDs and most client-generated keys.
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7e]{1,128}$`)
+
+// loadKeys opens the idempotency store, nil when IDEMPOTENCY_TABLE is not
+// set.
+func loadKeys(cfg aws.Config) *idempotency.Store {
+	table := os.Getenv(idempotency.TableEnv)
+	if table == "" {
+		return nil
+	}
+	return idempotency.New(dynamodb.NewFromConfig(cfg), table)
+}
+
+// idempotencyKeys returns the idempotency store.
+func (h *Handler) idempotencyKeys() (*idempotency.Store, error) {
+	if h.keys == nil {
+		return nil, fmt.Errorf("idempotency keys are not enabled (%s is not set)", idempotency.TableEnv)
+	}
+	return h.keys, nil
+}
+
+// validateIdempotencyKey checks Request.IdempotencyKey.
+func validateIdempotencyKey(req Request) error {
+	if req.IdempotencyKey != "" && !idempotencyKeyPattern.MatchString(req.IdempotencyKey) {
+		return fmt.Errorf("idempotencyKey must be 1-128 printable ASCII characters without spaces")
+	}
+	return nil
+}
+
+// idempotent runs process once per idempotency key of the tenant and
+// returns the stored response to requests repeating the key. Requests
+// without a key, status lookups and the background runs of async
+// submissions (which the job journal covers) run process directly.
+func (h *Handler) idempotent(ctx context.Context, req Request, process func() (*Response, error)) (*Response, error) {
+	if req.IdempotencyKey == "" || req.Action == ActionStatus {
+		return process()
+	}
+	store, err := h.idempotencyKeys()
+	if err != nil {
+		return idempotencyFailure(ctx, errorResponse(ErrorUnavailable, err.Error()))
+	}
+
+	key := req.TenantID + "#" + req.IdempotencyKey
+	claim, err := store.Begin(ctx, key, fingerprint(req))
+	switch {
+	case errors.Is(err, idempotency.ErrMismatch):
+		return errorResponse(ErrorInvalidRequest, fmt.Sprintf("idempotencyKey %q was already used for a different request", req.IdempotencyKey)), nil
+	case errors.Is(err, idempotency.ErrInFlight):
+		return idempotencyFailure(ctx, errorResponse(ErrorInProgress))
+	case err != nil:
+		return idempotencyFailure(ctx, errorResponse(ErrorUnavailable, err.Error()))
+	case claim.Status == idempotency.StatusCompleted:
+		return h.replay(ctx, req, claim.Response)
+	}
+
+	resp, err := process()
+	if err != nil || resp.ErrorCode != "" {
+		if releaseErr := store.Release(ctx, claim); releaseErr != nil {
+			slog.WarnContext(ctx, "idempotency key release failed", slog.String("idempotencyKey", key), slog.Any("error", releaseErr))
+		}
+		return resp, err
+	}
+	// The response is already computed; if it cannot be stored a retry
+	// translates the texts again.
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = store.Complete(ctx, claim, data)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "idempotency key completion failed", slog.String("idempotencyKey", key), slog.Any("error", err))
+		if releaseErr := store.Release(ctx, claim); releaseErr != nil {
+			slog.WarnContext(ctx, "idempotency key release failed", slog.String("idempotencyKey", key), slog.Any("error", releaseErr))
+		}
+	}
+	return resp, nil
+}
+
+// idempotencyFailure returns resp, or its message as an error for job runs
+// so the queue redelivers the message once the key is free again.
+func idempotencyFailure(ctx context.Context, resp *Response) (*Response, error) {
+	if isJobRun(ctx) {
+		return nil, resp.Error
+	}
+	return resp, nil
+}
+
+// replay returns the stored response of an earlier request with the same
+// key. A job run also stores it as the result of its own job.
+func (h *Handler) replay(ctx context.Context, req Request, stored []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(stored, &resp); err != nil {
+		return errorResponse(ErrorInternal, fmt.Sprintf("failed to decode stored response: %v", err)), nil
+	}
+	resp.IdempotentReplay = true
+	if err := h.finishJob(ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to store job %s: %w", req.JobID, err)
+	}
+	return &resp, nil
+}
+
+// fingerprint identifies the request an idempotency key is used with, so a
+// key reused for different texts or options is rejected. The key, the
+// credentials and the job ID, which differs between queue messages, are
+// left out.
+func fingerprint(req Request) string {
+	req.IdempotencyKey = ""
+	req.AdminToken = ""
+	req.JobID = ""
+	data, _ := json.Marshal(req) //nolint:errcheck // Request always marshals
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/idempotency"
+)
+
+// keysTable is an in-memory idempotency table honouring its conditions.
+type keysTable map[string]map[string]dynamotypes.AttributeValue
+
+func (t keysTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: t[attrString(in.Key, "key")]}, nil
+}
+
+func (t keysTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key := attrString(in.Item, "key")
+	old, exists := t[key]
+	owner := attrString(in.ExpressionAttributeValues, ":owner")
+	if (exists && owner == "") || (owner != "" && attrString(old, "owner") != owner) {
+		return nil, &dynamotypes.ConditionalCheckFailedException{}
+	}
+	t[key] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// useIdempotency gives h an in-memory idempotency store.
+func useIdempotency(h *Handler) *idempotency.Store {
+	h.keys = idempotency.New(keysTable{}, "keys")
+	return h.keys
+}
+
+func TestIdempotent(t *testing.T) {
+	h := &Handler{}
+	useIdempotency(h)
+	ctx := context.Background()
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", TenantID: "t1", IdempotencyKey: "order-1"}
+
+	runs := 0
+	process := func(resp *Response) func() (*Response, error) {
+		return func() (*Response, error) {
+			runs++
+			return resp, nil
+		}
+	}
+
+	failed, err := h.idempotent(ctx, req, process(errorResponse(ErrorTranslationFailed, "translator down")))
+	if err != nil || failed.ErrorCode != ErrorTranslationFailed {
+		t.Fatalf("idempotent() failing attempt = %+v, %v", failed, err)
+	}
+	first, err := h.idempotent(ctx, req, process(&Response{Translations: []string{"Hello"}}))
+	if err != nil || first.IdempotentReplay || first.Translations[0] != "Hello" {
+		t.Fatalf("idempotent() retry after failure = %+v, %v", first, err)
+	}
+	again, err := h.idempotent(ctx, req, process(&Response{Translations: []string{"Hi"}}))
+	if err != nil || !again.IdempotentReplay || again.Translations[0] != "Hello" {
+		t.Errorf("idempotent() repeat = %+v, %v, want the stored response", again, err)
+	}
+	if runs != 2 {
+		t.Errorf("request processed %d times, want 2 (one failure, one success)", runs)
+	}
+
+	other := req
+	other.Texts = []string{"Adiós"}
+	resp, err := h.idempotent(ctx, other, process(&Response{Translations: []string{"Bye"}}))
+	if err != nil || resp.ErrorCode != ErrorInvalidRequest {
+		t.Errorf("idempotent() key reused for other texts = %+v, %v, want %s", resp, err, ErrorInvalidRequest)
+	}
+
+	otherTenant := req
+	otherTenant.TenantID = "t2"
+	resp, err = h.idempotent(ctx, otherTenant, process(&Response{Translations: []string{"Hello"}}))
+	if err != nil || resp.IdempotentReplay {
+		t.Errorf("idempotent() same key of another tenant = %+v, %v, want a fresh run", resp, err)
+	}
+}
+
+func TestIdempotent_InFlight(t *testing.T) {
+	h := &Handler{}
+	store := useIdempotency(h)
+	ctx := context.Background()
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", IdempotencyKey: "order-1", Async: true, JobID: "msg-1"}
+	if _, err := store.Begin(ctx, "#order-1", fingerprint(req)); err != nil {
+		t.Fatal(err)
+	}
+	never := func() (*Response, error) {
+		t.Fatal("process ran while the key was claimed")
+		return nil, nil
+	}
+
+	// A duplicate message gets a different job ID and must be redelivered
+	duplicate := req
+	duplicate.JobID = "msg-2"
+	if _, err := h.idempotent(withJobRun(ctx), duplicate, never); err == nil {
+		t.Error("idempotent() job run while claimed should return an error")
+	}
+
+	direct := req
+	direct.Async, direct.JobID = false, ""
+	direct.IdempotencyKey = "order-2"
+	if _, err := store.Begin(ctx, "#order-2", fingerprint(direct)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := h.idempotent(ctx, direct, never)
+	if err != nil || resp.ErrorCode != ErrorInProgress {
+		t.Errorf("idempotent() while claimed = %+v, %v, want %s", resp, err, ErrorInProgress)
+	}
+}
+
+func TestIdempotent_Passthrough(t *testing.T) {
+	ran := false
+	process := func() (*Response, error) {
+		ran = true
+		return &Response{}, nil
+	}
+	// Status lookups never claim the key
+	h := &Handler{}
+	if _, err := h.idempotent(context.Background(), Request{Action: ActionStatus, JobID: "job-1", IdempotencyKey: "k"}, process); err != nil || !ran {
+		t.Errorf("idempotent() status lookup ran = %v, err = %v", ran, err)
+	}
+}
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"", false},
+		{"3f2c9a1e-7b4d-4c55-9a0e-1d2f3b4c5d6e", false},
+		{"order:42/retry", false},
+		{"has space", true},
+		{strings.Repeat("k", 129), true},
+		{"clé", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := validateIdempotencyKey(Request{IdempotencyKey: tt.key}); (err != nil) != tt.wantErr {
+				t.Errorf("validateIdempotencyKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// IntermediateTexts are the translations of the request texts into a pivot
+// language of the route (Request.IncludeIntermediate).
+type IntermediateTexts struct {
+	Lang         string   `json:"lang"`
+	Translations []string `json:"translations"`
+}
+
+// validateIntermediate checks Request.IncludeIntermediate.
+func validateIntermediate(req Request) error {
+	if !req.IncludeIntermediate {
+		return nil
+	}
+	switch {
+	case req.Text != "":
+		return fmt.Errorf("includeIntermediate is not supported with text")
+	case req.PO != "":
+		return fmt.Errorf("includeIntermediate is not supported with po")
+	case req.ChunkStrategy == ChunkHTML:
+		return fmt.Errorf("includeIntermediate is not supported with chunkStrategy %q", ChunkHTML)
+	case tagged(req):
+		return fmt.Errorf("includeIntermediate is not supported with tags")
+	}
+	return nil
+}
+
+// intermediateTexts returns the translations into every pivot of result,
+// one per request text like the final ones: texts split into pieces are
+// stitched, duplicates spread, protected tokens restored, and texts that
+// never reached the route, e.g. dictionary or cache hits, are "". It is
+// nil unless the request includes them.
+func intermediateTexts(req Request, result *router.Result, split *chunker.Split, order [][]int, dups duplicates, known knownTexts, masks [][]string) []IntermediateTexts {
+	if !req.IncludeIntermediate || len(result.Intermediate) != len(result.Pivots) {
+		return nil
+	}
+	intermediate := make([]IntermediateTexts, len(result.Pivots))
+	for i, pivot := range result.Pivots {
+		texts := unchunk(result.Intermediate[i], order)
+		if split.Oversized() {
+			texts = split.Stitch(texts, pivot)
+		}
+		texts = spreadHits(known, spreadDuplicates(dups, texts), func(int) string { return "" })
+		restoreTexts(texts, masks)
+		intermediate[i] = IntermediateTexts{Lang: pivot, Translations: texts}
+	}
+	return intermediate
+}
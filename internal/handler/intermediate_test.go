@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// englishPivotTranslator is a stubTranslator routing every pair through
+// English in one call, returning the English translations too.
+type englishPivotTranslator struct {
+	stubTranslator
+}
+
+func (e *englishPivotTranslator) TranslateChunksWithOptions(ctx context.Context, source, target string, chunks [][]string, opts router.Options) (*router.Result, error) {
+	english, _ := e.stubTranslator.TranslateChunksWithOptions(ctx, source, "en", chunks, opts)
+	result, _ := e.stubTranslator.TranslateChunksWithOptions(ctx, "en", target, english.Translations, opts)
+	result.Steps = append(english.Steps, result.Steps...)
+	result.PivotLang, result.Pivots = "en", []string{"en"}
+	result.Intermediate = [][][]string{english.Translations}
+	return result, nil
+}
+
+func TestHandle_IncludeIntermediate(t *testing.T) {
+	h := NewHandler(&englishPivotTranslator{})
+	req := Request{
+		Texts:               []string{"Hola {name}", "Adiós", "Hola {name}", "12345"},
+		SourceLang:          "es",
+		TargetLang:          "de",
+		Placeholders:        PlaceholdersOn,
+		IncludeIntermediate: true,
+	}
+
+	resp, err := h.Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp.Error, err)
+	}
+	want := []IntermediateTexts{{Lang: "en", Translations: []string{"en:Hola {name}", "en:Adiós", "en:Hola {name}", ""}}}
+	if !reflect.DeepEqual(resp.Intermediate, want) {
+		t.Errorf("Intermediate = %+v, want %+v", resp.Intermediate, want)
+	}
+	if resp.Translations[0] != "de:en:Hola {name}" {
+		t.Errorf("Translations = %q", resp.Translations)
+	}
+
+	req.IncludeIntermediate = false
+	if resp, _ := h.Handle(context.Background(), req); resp.Intermediate != nil {
+		t.Errorf("Intermediate = %+v without includeIntermediate", resp.Intermediate)
+	}
+}
+
+func TestValidateIntermediate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"off", Request{Text: "Hola. Adiós."}, false},
+		{"texts", Request{Texts: []string{"Hola"}, IncludeIntermediate: true}, false},
+		{"text", Request{Text: "Hola. Adiós.", IncludeIntermediate: true}, true},
+		{"po", Request{PO: storefrontPO, IncludeIntermediate: true}, true},
+		{"html", Request{Texts: []string{"<p>Hola</p>"}, ChunkStrategy: ChunkHTML, IncludeIntermediate: true}, true},
+		{"tags", Request{Texts: []string{"Hola"}, Tags: []string{"legal"}, IncludeIntermediate: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateIntermediate(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateIntermediate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/langid"
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// Inverted pair handling, Request.InvertedPairAction.
+const (
+	// InvertedPairWarn keeps the pair and warns (the default).
+	InvertedPairWarn = "warn"
+	// InvertedPairCorrect swaps sourceLang and targetLang and warns.
+	InvertedPairCorrect = "correct"
+)
+
+const (
+	// inversionSampleTexts is how many texts are inspected.
+	inversionSampleTexts = 20
+	// minInversionEvidence is the langid score needed to call a pair inverted.
+	minInversionEvidence = 4
+)
+
+// checkInversion detects requests whose texts are clearly in the target
+// language, a common integration bug that otherwise yields garbage silently.
+// It warns, or swaps the pair when the request asks for correction.
+func checkInversion(req *Request) *Warning {
+	source, target := locale.Base(req.SourceLang), locale.Base(req.TargetLang)
+	if source == "" || target == "" || source == target {
+		return nil
+	}
+
+	detected := langid.Detect(inversionSample(req))
+	if detected.Lang != target || !detected.Confident(minInversionEvidence) {
+		return nil
+	}
+
+	if req.InvertedPairAction == InvertedPairCorrect {
+		req.SourceLang, req.TargetLang = req.TargetLang, req.SourceLang
+		return &Warning{
+			Code: WarningPairInvertedCorrected,
+			Message: fmt.Sprintf("texts look like %s, translated %s→%s instead",
+				target, req.SourceLang, req.TargetLang),
+		}
+	}
+	return &Warning{
+		Code: WarningPairLikelyInverted,
+		Message: fmt.Sprintf("texts look like %s, the targetLang: sourceLang and targetLang may be swapped",
+			target),
+	}
+}
+
+// inversionSample joins the first texts of the request.
+func inversionSample(req *Request) string {
+	if req.Text != "" {
+		return req.Text
+	}
+	texts := req.Texts
+	if len(texts) > inversionSampleTexts {
+		texts = texts[:inversionSampleTexts]
+	}
+	return strings.Join(texts, "\n")
+}
+
+// validateInvertedPairAction checks Request.InvertedPairAction.
+func validateInvertedPairAction(action string) error {
+	switch action {
+	case "", InvertedPairWarn, InvertedPairCorrect:
+		return nil
+	default:
+		return fmt.Errorf("unknown invertedPairAction %q", action)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import "testing"
+
+func TestCheckInversion(t *testing.T) {
+	french := []string{"Chaussures de course en très bon état avec la boîte", "Livraison offerte pour les membres"}
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantCode   string
+		wantSource string
+	}{
+		{
+			name:       "correct pair",
+			req:        Request{SourceLang: "fr", TargetLang: "es", Texts: french},
+			wantSource: "fr",
+		},
+		{
+			name:       "inverted pair warns",
+			req:        Request{SourceLang: "es", TargetLang: "fr", Texts: french},
+			wantCode:   WarningPairLikelyInverted,
+			wantSource: "es",
+		},
+		{
+			name:       "inverted pair corrected",
+			req:        Request{SourceLang: "es", TargetLang: "fr_CA", Texts: french, InvertedPairAction: InvertedPairCorrect},
+			wantCode:   WarningPairInvertedCorrected,
+			wantSource: "fr_CA",
+		},
+		{
+			name:       "too little evidence",
+			req:        Request{SourceLang: "es", TargetLang: "fr", Texts: []string{"iPhone 12 Pro", "la boîte"}},
+			wantSource: "es",
+		},
+		{
+			name:       "document mode",
+			req:        Request{SourceLang: "es", TargetLang: "fr", Text: french[0] + ". " + french[1]},
+			wantCode:   WarningPairLikelyInverted,
+			wantSource: "es",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			w := checkInversion(&req)
+			code := ""
+			if w != nil {
+				code = w.Code
+			}
+			if code != tt.wantCode {
+				t.Errorf("checkInversion() = %+v, want code %q", w, tt.wantCode)
+			}
+			if req.SourceLang != tt.wantSource {
+				t.Errorf("SourceLang = %s, want %s", req.SourceLang, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestValidateInvertedPairAction(t *testing.T) {
+	for _, a := range []string{"", InvertedPairWarn, InvertedPairCorrect} {
+		if err := validateInvertedPairAction(a); err != nil {
+			t.Errorf("validateInvertedPairAction(%q) = %v", a, err)
+		}
+	}
+	if err := validateInvertedPairAction("swap"); err == nil {
+		t.Error("validateInvertedPairAction(swap) should fail")
+	}
+}
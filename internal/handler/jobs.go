@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/pricofy/translation-manager/internal/batch"
+	"github.com/pricofy/translation-manager/internal/jobstore"
+	"github.com/pricofy/translation-manager/internal/journal"
+	"github.com/pricofy/translation-manager/internal/notify"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// ActionStatus looks up an async job by Request.JobID.
+const ActionStatus = "status"
+
+// Job statuses reported in Response.Status.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+const jobsPrefix = "jobs/"
+
+// JobRunSource identifies the events of background job runs.
+const JobRunSource = "job"
+
+// JobRun is the event an async submission invokes this function with to
+// run its job in the background. Only job runs, from these events or from
+// SQS messages (see Handler.RunJob), write to the job they name: a plain
+// request with a jobId cannot overwrite the result of someone else's job.
+type JobRun struct {
+	Source  string  `json:"source"`
+	Request Request `json:"request"`
+}
+
+// jobIDPattern matches the job IDs this service generates, SQS message
+// IDs and producer-chosen IDs, which name result objects and keys.
+var jobIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// jobRunKey marks the context of a background job run.
+type jobRunKey struct{}
+
+// withJobRun returns ctx marked as the context of a background job run.
+func withJobRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jobRunKey{}, true)
+}
+
+// isJobRun reports whether ctx is the context of a background job run.
+func isJobRun(ctx context.Context) bool {
+	run, _ := ctx.Value(jobRunKey{}).(bool)
+	return run
+}
+
+// RunJob runs req in this invocation as the background run of job
+// req.JobID, storing its result and announcing its completion. It serves
+// JobRun events and SQS messages, never caller requests.
+func (h *Handler) RunJob(ctx context.Context, req Request) (*Response, error) {
+	req.Async = true
+	return h.Handle(withJobRun(ctx), req)
+}
+
+// checkJobID rejects malformed job IDs and, outside background job runs,
+// async requests naming the job they write to: the service assigns the ID
+// of a submitted job.
+func checkJobID(ctx context.Context, req Request) error {
+	if req.JobID == "" {
+		return nil
+	}
+	if !jobIDPattern.MatchString(req.JobID) {
+		return fmt.Errorf("jobId must be 1 to 128 letters, digits, '-' or '_'")
+	}
+	if (req.Async || req.Mode == ModeBatch) && !isJobRun(ctx) {
+		return fmt.Errorf("jobId is assigned when a job is submitted; leave it out and look the job up with the status action")
+	}
+	return nil
+}
+
+// jobResults stores the responses of async jobs: a resultstore.Store in
+// ASYNC_BUCKET or a resultstore.Table in JOBS_RESULT_TABLE.
+type jobResults interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, bool, error)
+	Location(name string) string
+}
+
+// jobRunner submits async jobs, stores their results and announces them.
+type jobRunner struct {
+	store        jobResults
+	invoker      router.Invoker
+	functionName string
+	// notifier is nil unless JOBS_TOPIC_ARN is set.
+	notifier *notify.Notifier
+	// journal is nil unless JOURNAL_TABLE is set.
+	journal *journal.Journal
+	// states is nil unless JOBS_TABLE is set.
+	states *jobstore.Store
+	// deadLetters holds the manifests of failed chunks; nil unless
+	// DEAD_LETTER_BUCKET is set.
+	deadLetters jobResults
+	// batches reports the progress of batch jobs; nil unless
+	// BATCH_PROGRESS_TABLE is set.
+	batches *batch.Runner
+}
+
+// loadJobs creates the job runner, reporting the progress of batches. Async
+// jobs need a result destination, JOBS_RESULT_TABLE or ASYNC_BUCKET: it is
+// nil when neither is set.
+func loadJobs(cfg aws.Config, batches *batch.Runner) *jobRunner {
+	bucket, table := os.Getenv(resultstore.BucketEnv), os.Getenv(resultstore.TableEnv)
+	if bucket == "" && table == "" {
+		return nil
+	}
+	j := &jobRunner{
+		invoker:      lambda.NewFromConfig(cfg),
+		functionName: os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		batches:      batches,
+	}
+	if table != "" {
+		j.store = resultstore.NewTable(dynamodb.NewFromConfig(cfg), table)
+	} else {
+		j.store = resultstore.New(s3.NewFromConfig(cfg), bucket, jobsPrefix)
+	}
+	if topic := os.Getenv(notify.TopicEnv); topic != "" {
+		j.notifier = notify.New(sns.NewFromConfig(cfg), topic)
+	}
+	if table := os.Getenv(journal.TableEnv); table != "" {
+		j.journal = journal.New(dynamodb.NewFromConfig(cfg), table)
+	}
+	if table := os.Getenv(jobstore.TableEnv); table != "" {
+		j.states = jobstore.New(dynamodb.NewFromConfig(cfg), table)
+	}
+	if bucket := os.Getenv(DeadLetterBucketEnv); bucket != "" {
+		j.deadLetters = resultstore.New(s3.NewFromConfig(cfg), bucket, deadLettersPrefix)
+	}
+	return j
+}
+
+// asyncJobs returns the job runner.
+func (h *Handler) asyncJobs() (*jobRunner, error) {
+	if h.jobs == nil {
+		return nil, fmt.Errorf("async jobs are not enabled (neither %s nor %s is set)", resultstore.BucketEnv, resultstore.TableEnv)
+	}
+	return h.jobs, nil
+}
+
+// submit hands req to a background invocation of this function and returns
+// the pending job. With a job table, the job is recorded there first.
+func (j *jobRunner) submit(ctx context.Context, req Request) *Response {
+	id, err := resultstore.NewID()
+	if err != nil {
+		return errorResponse(ErrorInternal, err.Error())
+	}
+	req.JobID = id
+	// The submission holds the idempotency key; the job journal keeps the
+	// background run to one
+	req.IdempotencyKey = ""
+
+	payload, err := json.Marshal(JobRun{Source: JobRunSource, Request: req})
+	if err != nil {
+		return errorResponse(ErrorInternal, fmt.Sprintf("failed to marshal job: %v", err))
+	}
+	state := &jobstore.Job{ID: id, State: jobstore.StatePending, Output: j.output(req)}
+	if err := j.putState(ctx, state); err != nil {
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+	_, err = j.invoker.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   &j.functionName,
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	})
+	if err != nil {
+		resp := errorResponse(ErrorUnavailable, fmt.Sprintf("failed to submit job: %v", err))
+		resp.JobID = id
+		state.State, state.ErrorCode, state.Error = jobstore.StateFailed, resp.ErrorCode, resp.Error.Message
+		if err := j.putState(ctx, state); err != nil {
+			slog.ErrorContext(ctx, "job state write failed", slog.String("jobId", id), slog.Any("error", err))
+		}
+		return resp
+	}
+
+	return &Response{Translations: []string{}, JobID: id, Status: JobPending}
+}
+
+// complete stores the response of a finished job, completed or failed,
+// and publishes its completion. A failed notification is logged rather
+// than retrying the job.
+func (j *jobRunner) complete(ctx context.Context, req Request, resp *Response) error {
+	name := req.JobID + ".json"
+	resp.JobID = req.JobID
+	resp.Status = JobCompleted
+	if resp.Error != nil {
+		resp.Status = JobFailed
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job result: %w", err)
+	}
+	if err := j.store.Put(ctx, name, data); err != nil {
+		return err
+	}
+	jobTrackerFrom(ctx).finish(ctx, resp, j.output(req))
+
+	var message string
+	if resp.Error != nil {
+		message = resp.Error.Message
+	}
+	err = j.notifier.Publish(ctx, notify.Event{
+		JobID:      req.JobID,
+		Status:     resp.Status,
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+		TenantID:   req.TenantID,
+		Result:     j.store.Location(name),
+		Texts:      len(resp.Translations),
+		Error:      message,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "job notification failed", slog.String("jobId", req.JobID), slog.Any("error", err))
+	}
+	return nil
+}
+
+// output returns where the result of req's job is written.
+func (j *jobRunner) output(req Request) string {
+	if req.Mode == ModeBatch {
+		return req.OutputPrefix
+	}
+	return j.store.Location(req.JobID + ".json")
+}
+
+// putState saves the state of a job when the job table is enabled.
+func (j *jobRunner) putState(ctx context.Context, job *jobstore.Job) error {
+	if j.states == nil {
+		return nil
+	}
+	return j.states.Put(ctx, job)
+}
+
+// status returns the stored response of a job, or a pending response while
+// it has not finished. With a journal, a job a worker is processing is
+// reported as running. With a job table, its state there adds the chunk
+// progress, the output location and submission failures.
+func (j *jobRunner) status(ctx context.Context, id string) *Response {
+	resp := j.result(ctx, id)
+	if j.states == nil || resp.Status == "" {
+		return resp
+	}
+	job, err := j.states.Get(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "job state lookup failed", slog.String("jobId", id), slog.Any("error", err))
+		return resp
+	}
+	return jobStatus(resp, job)
+}
+
+// result returns the stored response of a job, or its progress while it
+// has not finished.
+func (j *jobRunner) result(ctx context.Context, id string) *Response {
+	data, ok, err := j.store.Get(ctx, id+".json")
+	if err != nil {
+		resp := errorResponse(ErrorUnavailable, err.Error())
+		resp.JobID = id
+		return resp
+	}
+	if !ok {
+		return j.progress(ctx, id)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		resp := errorResponse(ErrorInternal, fmt.Sprintf("corrupt job result: %v", err))
+		resp.JobID = id
+		return resp
+	}
+	return &resp
+}
+
+// progress reports an unfinished job as pending or running.
+func (j *jobRunner) progress(ctx context.Context, id string) *Response {
+	resp := &Response{Translations: []string{}, JobID: id, Status: JobPending, Batch: j.batchProgress(ctx, id)}
+	if j.journal == nil {
+		return resp
+	}
+	rec, err := j.journal.Get(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "job journal lookup failed", slog.String("jobId", id), slog.Any("error", err))
+		return resp
+	}
+	if rec != nil && rec.InFlight(time.Now()) {
+		resp.Status = JobRunning
+	}
+	return resp
+}
+
+// runJob runs process for a background job run. With a journal, the job is
+// claimed first so a job delivered again is processed exactly once: a
+// completed job returns its stored response, and a job another worker holds
+// fails the invocation so the delivery is retried later.
+func (h *Handler) runJob(ctx context.Context, req Request, process func() (*Response, error)) (*Response, error) {
+	j, err := h.asyncJobs()
+	if err != nil || j.journal == nil {
+		jobTrackerFrom(ctx).start(ctx)
+		return process()
+	}
+
+	claim, err := j.journal.Begin(ctx, req.JobID)
+	switch {
+	case errors.Is(err, journal.ErrCompleted):
+		slog.InfoContext(ctx, "job already completed, skipping duplicate delivery", slog.String("jobId", req.JobID))
+		return j.status(ctx, req.JobID), nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to claim job %s: %w", req.JobID, err)
+	}
+	jobTrackerFrom(ctx).start(ctx)
+
+	resp, err := process()
+	if err != nil {
+		if releaseErr := j.journal.Release(ctx, claim); releaseErr != nil {
+			slog.WarnContext(ctx, "job release failed", slog.String("jobId", req.JobID), slog.Any("error", releaseErr))
+		}
+		return nil, err
+	}
+	// The result is already stored; if the claim cannot be completed a
+	// later delivery reprocesses the job and stores the same result again.
+	if err := j.journal.Complete(ctx, claim); err != nil {
+		slog.WarnContext(ctx, "job completion failed", slog.String("jobId", req.JobID), slog.Any("error", err))
+	}
+	return resp, nil
+}
+
+// handleJob serves status lookups and async submissions. It returns nil for
+// requests that run in this invocation, including background job runs.
+func (h *Handler) handleJob(ctx context.Context, req Request) *Response {
+	if req.Action != ActionStatus && (!req.Async || isJobRun(ctx)) {
+		return nil
+	}
+
+	j, err := h.asyncJobs()
+	if err != nil {
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+	if req.Action == ActionStatus {
+		return j.status(ctx, req.JobID)
+	}
+	return j.submit(ctx, req)
+}
+
+// finishJob stores the response of a background job run.
+func (h *Handler) finishJob(ctx context.Context, req Request, resp *Response) error {
+	if !req.Async {
+		return nil
+	}
+	j, err := h.asyncJobs()
+	if err != nil {
+		return err
+	}
+	return j.complete(ctx, req, resp)
+}
+
+// prewarmBacklog is the chunk count from which an async job pre-warms the
+// second hop of a pivot route. Smaller jobs finish the first hop before a
+// ping would save anything.
+const prewarmBacklog = 4
+
+// wantsPrewarm reports whether a request is a background job large enough
+// to pre-warm its later route steps.
+func wantsPrewarm(req Request, chunks int) bool {
+	return req.Async && chunks >= prewarmBacklog
+}
+
+// validateJob checks the async job options.
+func validateJob(req Request) error {
+	if req.Async && req.Action == ActionValidate {
+		return fmt.Errorf("async is not supported with the validate action")
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/pricofy/translation-manager/internal/journal"
+	"github.com/pricofy/translation-manager/internal/notify"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+)
+
+// memoryBucket is an in-memory S3 bucket.
+type memoryBucket map[string][]byte
+
+func (b memoryBucket) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	b[*params.Key] = data
+	return &s3.PutObjectOutput{}, err
+}
+
+func (b memoryBucket) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := b[*params.Key]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// recordingInvoker records Lambda invocations.
+type recordingInvoker struct {
+	inputs []*lambda.InvokeInput
+}
+
+func (r *recordingInvoker) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	r.inputs = append(r.inputs, params)
+	return &lambda.InvokeOutput{StatusCode: 202}, nil
+}
+
+// recordingPublisher records SNS publishes.
+type recordingPublisher struct {
+	inputs []*sns.PublishInput
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	r.inputs = append(r.inputs, params)
+	return &sns.PublishOutput{}, nil
+}
+
+// journalTable is an in-memory journal table honouring its conditions.
+type journalTable map[string]map[string]dynamotypes.AttributeValue
+
+func attrString(item map[string]dynamotypes.AttributeValue, name string) string {
+	if v, ok := item[name].(*dynamotypes.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func (t journalTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: t[attrString(in.Key, "id")]}, nil
+}
+
+func (t journalTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	id := attrString(in.Item, "id")
+	old, exists := t[id]
+	owner := attrString(in.ExpressionAttributeValues, ":owner")
+	if exists && (owner == "" || attrString(old, "owner") != owner) {
+		return nil, &dynamotypes.ConditionalCheckFailedException{}
+	}
+	t[id] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestRunJob_Journal(t *testing.T) {
+	bucket := memoryBucket{}
+	jobs := &jobRunner{
+		store:   resultstore.New(bucket, "bucket", jobsPrefix),
+		journal: journal.New(journalTable{}, "journal"),
+	}
+	h := &Handler{jobs: jobs}
+	ctx := context.Background()
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", Async: true, JobID: "job-1"}
+
+	runs := 0
+	process := func(fail error) func() (*Response, error) {
+		return func() (*Response, error) {
+			runs++
+			if got := jobs.status(ctx, req.JobID); got.Status != JobRunning {
+				t.Errorf("status() while processing = %+v, want running", got)
+			}
+			if fail != nil {
+				return nil, fail
+			}
+			resp := &Response{Translations: []string{"Hello"}}
+			return resp, jobs.complete(ctx, req, resp)
+		}
+	}
+
+	if _, err := h.runJob(ctx, req, process(errors.New("translator down"))); err == nil {
+		t.Fatal("runJob() should return the processing error")
+	}
+	if got := jobs.status(ctx, req.JobID); got.Status != JobPending {
+		t.Errorf("status() after a failed attempt = %+v, want pending", got)
+	}
+
+	resp, err := h.runJob(ctx, req, process(nil))
+	if err != nil || resp.Translations[0] != "Hello" {
+		t.Fatalf("runJob() retry = %+v, %v", resp, err)
+	}
+
+	resp, err = h.runJob(ctx, req, process(nil))
+	if err != nil || resp.Status != JobCompleted || resp.Translations[0] != "Hello" {
+		t.Errorf("runJob() duplicate delivery = %+v, %v", resp, err)
+	}
+	if runs != 2 {
+		t.Errorf("job processed %d times, want 2 (one failure, one success)", runs)
+	}
+}
+
+func TestJobRunner_Lifecycle(t *testing.T) {
+	bucket := memoryBucket{}
+	invoker := &recordingInvoker{}
+	publisher := &recordingPublisher{}
+	j := &jobRunner{
+		store:        resultstore.New(bucket, "bucket", jobsPrefix),
+		invoker:      invoker,
+		functionName: "pricofy-translation-manager",
+		notifier:     notify.New(publisher, "arn:aws:sns:eu-west-1:1:jobs"),
+	}
+	ctx := context.Background()
+
+	submitted := j.submit(ctx, Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", Async: true})
+	if submitted.Error != nil || submitted.Status != JobPending || submitted.JobID == "" {
+		t.Fatalf("submit() = %+v", submitted)
+	}
+
+	if len(invoker.inputs) != 1 || invoker.inputs[0].InvocationType != lambdatypes.InvocationTypeEvent {
+		t.Fatalf("submit() should self-invoke once as an event, got %+v", invoker.inputs)
+	}
+	var run JobRun
+	if err := json.Unmarshal(invoker.inputs[0].Payload, &run); err != nil {
+		t.Fatal(err)
+	}
+	worker := run.Request
+	if run.Source != JobRunSource || !worker.Async || worker.JobID != submitted.JobID || worker.Texts[0] != "Hola" {
+		t.Errorf("job run = %+v", run)
+	}
+
+	if got := j.status(ctx, submitted.JobID); got.Status != JobPending {
+		t.Errorf("status() before completion = %+v, want pending", got)
+	}
+
+	if err := j.complete(ctx, worker, &Response{Translations: []string{"Hello"}, ChunksProcessed: 1}); err != nil {
+		t.Fatal(err)
+	}
+	got := j.status(ctx, submitted.JobID)
+	if got.Status != JobCompleted || got.JobID != submitted.JobID || got.Translations[0] != "Hello" {
+		t.Errorf("status() after completion = %+v", got)
+	}
+
+	if len(publisher.inputs) != 1 {
+		t.Fatalf("complete() published %d notifications, want 1", len(publisher.inputs))
+	}
+	var event notify.Event
+	if err := json.Unmarshal([]byte(*publisher.inputs[0].Message), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.JobID != submitted.JobID || event.Texts != 1 || event.Result != "s3://bucket/jobs/"+submitted.JobID+".json" {
+		t.Errorf("notification = %+v", event)
+	}
+}
+
+func TestJobRunner_TableResults(t *testing.T) {
+	publisher := &recordingPublisher{}
+	j := &jobRunner{
+		store:    resultstore.NewTable(journalTable{}, "job-results"),
+		notifier: notify.New(publisher, "arn:aws:sns:eu-west-1:1:jobs"),
+	}
+	ctx := context.Background()
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", Async: true, JobID: "job-1"}
+
+	if got := j.status(ctx, req.JobID); got.Status != JobPending {
+		t.Errorf("status() before completion = %+v, want pending", got)
+	}
+	if err := j.complete(ctx, req, &Response{Translations: []string{"Hello"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := j.status(ctx, req.JobID); got.Status != JobCompleted || got.Translations[0] != "Hello" {
+		t.Errorf("status() after completion = %+v", got)
+	}
+
+	var event notify.Event
+	if err := json.Unmarshal([]byte(*publisher.inputs[0].Message), &event); err != nil {
+		t.Fatal(err)
+	}
+	if want := "dynamodb://job-results/job-1.json"; event.Result != want {
+		t.Errorf("notification result = %q, want %q", event.Result, want)
+	}
+}
+
+func TestHandleJob_Passthrough(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  Request
+	}{
+		{"synchronous request", context.Background(), Request{Texts: []string{"a"}}},
+		{"background job run", withJobRun(context.Background()), Request{Texts: []string{"a"}, Async: true, JobID: "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := (&Handler{}).handleJob(tt.ctx, tt.req); resp != nil {
+				t.Errorf("handleJob() = %+v, want nil so the request runs here", resp)
+			}
+		})
+	}
+}
+
+func TestCheckJobID(t *testing.T) {
+	run := withJobRun(context.Background())
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     Request
+		wantErr bool
+	}{
+		{"no job id", context.Background(), Request{Async: true}, false},
+		{"status lookup", context.Background(), Request{Action: ActionStatus, JobID: "9f2c"}, false},
+		{"redrive", context.Background(), Request{Mode: ModeRedrive, JobID: "job-1"}, false},
+		{"job run", run, Request{Async: true, JobID: "0f6d4c1e-8a2b-4f3c-9d1e-2b7a6c5d4e3f"}, false},
+		{"caller-named async job", context.Background(), Request{Async: true, JobID: "job-1"}, true},
+		{"caller-named batch", context.Background(), Request{Mode: ModeBatch, JobID: "job-1"}, true},
+		{"path in job id", run, Request{Async: true, JobID: "../jobs/job-1"}, true},
+		{"path in status lookup", context.Background(), Request{Action: ActionStatus, JobID: "a/b"}, true},
+		{"too long", run, Request{Async: true, JobID: strings.Repeat("a", 129)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkJobID(tt.ctx, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("checkJobID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandle_CallerNamedJob(t *testing.T) {
+	bucket := memoryBucket{}
+	publisher := &recordingPublisher{}
+	h := NewHandler(&stubTranslator{})
+	h.jobs = &jobRunner{
+		store:    resultstore.New(bucket, "bucket", jobsPrefix),
+		invoker:  &recordingInvoker{},
+		notifier: notify.New(publisher, "arn:aws:sns:eu-west-1:1:jobs"),
+	}
+
+	resp, err := h.Handle(context.Background(), Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", Async: true, JobID: "job-1"})
+	if err != nil || resp.ErrorCode != ErrorInvalidRequest {
+		t.Errorf("Handle() = %+v, %v, want %s", resp, err, ErrorInvalidRequest)
+	}
+	if len(bucket) != 0 || len(publisher.inputs) != 0 {
+		t.Errorf("stored %d results and published %d notifications, want none", len(bucket), len(publisher.inputs))
+	}
+
+	resp, err = h.RunJob(context.Background(), Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", JobID: "job-1"})
+	if err != nil || resp.Error != nil || bucket["jobs/job-1.json"] == nil || len(publisher.inputs) != 1 {
+		t.Errorf("RunJob() = %+v, %v, want the result stored and announced", resp, err)
+	}
+}
+
+func TestValidateRequest_Jobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"status with job id", Request{Action: ActionStatus, JobID: "abc"}, false},
+		{"status without job id", Request{Action: ActionStatus}, true},
+		{"async translate", Request{Texts: []string{"a"}, SourceLang: "es", TargetLang: "en", Async: true}, false},
+		{"async validate", Request{Texts: []string{"a"}, SourceLang: "es", TargetLang: "en", Async: true, Action: ActionValidate}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRequest(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRequest_Status(t *testing.T) {
+	req, err := ParseRequest([]byte(`{"action": "status", "jobId": "abc"}`))
+	if err != nil || req.JobID != "abc" {
+		t.Errorf("ParseRequest() = %+v, %v", req, err)
+	}
+	if _, err := ParseRequest([]byte(`{"action": "status"}`)); err == nil {
+		t.Error("ParseRequest() should require jobId on status lookups")
+	}
+}
+
+func TestWantsPrewarm(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    Request
+		chunks int
+		want   bool
+	}{
+		{"large async job", Request{Async: true}, prewarmBacklog, true},
+		{"small async job", Request{Async: true}, prewarmBacklog - 1, false},
+		{"large synchronous request", Request{}, prewarmBacklog * 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wantsPrewarm(tt.req, tt.chunks); got != tt.want {
+				t.Errorf("wantsPrewarm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/jobstore"
+)
+
+// ModeStatus looks up a job like the status action: {"mode": "status",
+// "jobId": "..."}.
+const ModeStatus = "status"
+
+// JobProgress counts the chunks of an async job.
+type JobProgress struct {
+	ChunksDone  int `json:"chunksDone"`
+	ChunksTotal int `json:"chunksTotal"`
+}
+
+// progressInterval is how often a running job writes its chunk progress.
+const progressInterval = 2 * time.Second
+
+// jobTracker writes the state of the job a background run processes to the
+// job table. A nil tracker, without JOBS_TABLE, records nothing.
+type jobTracker struct {
+	states *jobstore.Store
+	// chunks is false for batches, whose parts report their own progress.
+	chunks bool
+
+	mu      sync.Mutex
+	job     jobstore.Job
+	written time.Time
+}
+
+type jobTrackerKey struct{}
+
+// trackJob returns ctx carrying the tracker of req's background run when
+// the job table is enabled.
+func (h *Handler) trackJob(ctx context.Context, req Request) context.Context {
+	j, err := h.asyncJobs()
+	if err != nil || j.states == nil {
+		return ctx
+	}
+	t := &jobTracker{states: j.states, chunks: req.Mode != ModeBatch, job: jobstore.Job{ID: req.JobID}}
+	return context.WithValue(ctx, jobTrackerKey{}, t)
+}
+
+// jobTrackerFrom returns the tracker in ctx, or nil.
+func jobTrackerFrom(ctx context.Context) *jobTracker {
+	t, _ := ctx.Value(jobTrackerKey{}).(*jobTracker)
+	return t
+}
+
+// start marks the job running, keeping what its submission recorded and
+// resetting the progress of an earlier attempt.
+func (t *jobTracker) start(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	prev, err := t.states.Get(ctx, t.job.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "job state lookup failed", slog.String("jobId", t.job.ID), slog.Any("error", err))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev != nil {
+		t.job.CreatedAt, t.job.Output = prev.CreatedAt, prev.Output
+	}
+	t.job.State = jobstore.StateRunning
+	t.job.ChunksDone, t.job.ChunksTotal = 0, 0
+	t.job.ErrorCode, t.job.Error = "", ""
+	t.write(ctx)
+}
+
+// addChunks counts chunks the job is about to translate.
+func (t *jobTracker) addChunks(ctx context.Context, chunks int) {
+	if t == nil || !t.chunks {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.job.ChunksTotal += chunks
+	t.write(ctx)
+}
+
+// progress returns the router.Options.Progress callback counting translated
+// chunks, written at most every progressInterval; nil without a tracker.
+func (t *jobTracker) progress(ctx context.Context) func(chunks int) {
+	if t == nil || !t.chunks {
+		return nil
+	}
+	return func(chunks int) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.job.ChunksDone += chunks
+		if time.Since(t.written) >= progressInterval || t.job.ChunksDone >= t.job.ChunksTotal {
+			t.write(ctx)
+		}
+	}
+}
+
+// finish records the outcome of the job from its response.
+func (t *jobTracker) finish(ctx context.Context, resp *Response, output string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.job.State = jobstore.StateCompleted
+	if resp.Error != nil {
+		t.job.State = jobstore.StateFailed
+		t.job.ErrorCode, t.job.Error = resp.Error.Code, resp.Error.Message
+	}
+	if t.job.ChunksTotal == 0 {
+		t.job.ChunksTotal = resp.ChunksProcessed
+	}
+	if resp.Error == nil {
+		t.job.ChunksDone = t.job.ChunksTotal
+	}
+	if t.job.Output == "" {
+		t.job.Output = output
+	}
+	t.write(ctx)
+}
+
+// write saves the job; a failed write is logged rather than failing the
+// job, whose result is stored apart.
+func (t *jobTracker) write(ctx context.Context) {
+	if err := t.states.Put(ctx, &t.job); err != nil {
+		slog.WarnContext(ctx, "job state write failed", slog.String("jobId", t.job.ID), slog.Any("error", err))
+	}
+	t.written = time.Now()
+}
+
+// jobStatus overlays the job table state on a status response: the state
+// of an unfinished or failed job, its chunk progress and its output.
+func jobStatus(resp *Response, job *jobstore.Job) *Response {
+	if job == nil {
+		return resp
+	}
+	switch {
+	case job.State == jobstore.StateRunning && resp.Status == JobPending:
+		resp.Status = JobRunning
+	case job.State == jobstore.StateFailed:
+		resp.Status = JobFailed
+		if resp.Error == nil {
+			failed := NewErrorResponse(job.ErrorCode, job.Error)
+			resp.Error, resp.ErrorCode = failed.Error, failed.ErrorCode
+		}
+	}
+	if job.ChunksTotal > 0 {
+		resp.Progress = &JobProgress{ChunksDone: job.ChunksDone, ChunksTotal: job.ChunksTotal}
+	}
+	resp.Output = job.Output
+	return resp
+}
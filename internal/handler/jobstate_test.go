@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/jobstore"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+)
+
+// stateTable is an in-memory job table.
+type stateTable map[string]map[string]dynamotypes.AttributeValue
+
+func (t stateTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: t[attrString(in.Key, "id")]}, nil
+}
+
+func (t stateTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	t[attrString(in.Item, "id")] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// failingInvoker fails every invocation.
+type failingInvoker struct{}
+
+func (failingInvoker) Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	return nil, errors.New("throttled")
+}
+
+func TestJobRunner_States(t *testing.T) {
+	invoker := &recordingInvoker{}
+	j := &jobRunner{
+		store:   resultstore.New(memoryBucket{}, "bucket", jobsPrefix),
+		invoker: invoker,
+		states:  jobstore.New(stateTable{}, "jobs"),
+	}
+	h := &Handler{jobs: j}
+	ctx := context.Background()
+
+	submitted := j.submit(ctx, Request{Texts: []string{"Hola", "Adiós"}, SourceLang: "es", TargetLang: "en", Async: true})
+	got := j.status(ctx, submitted.JobID)
+	if want := "s3://bucket/jobs/" + submitted.JobID + ".json"; got.Status != JobPending || got.Output != want || got.Progress != nil {
+		t.Errorf("status() after submission = %+v, want pending with output %s", got, want)
+	}
+
+	var worker Request
+	if err := json.Unmarshal(invoker.inputs[0].Payload, &worker); err != nil {
+		t.Fatal(err)
+	}
+	ctx = h.trackJob(ctx, worker)
+	resp, err := h.runJob(ctx, worker, func() (*Response, error) {
+		tracker := jobTrackerFrom(ctx)
+		tracker.addChunks(ctx, 3)
+		tracker.progress(ctx)(3)
+		got := j.status(ctx, worker.JobID)
+		if got.Status != JobRunning || got.Progress == nil || *got.Progress != (JobProgress{ChunksDone: 3, ChunksTotal: 3}) {
+			t.Errorf("status() while running = %+v, want running with 3 of 3 chunks", got)
+		}
+		resp := &Response{Translations: []string{"Hello", "Bye"}, ChunksProcessed: 3}
+		return resp, j.complete(ctx, worker, resp)
+	})
+	if err != nil || resp.Status != JobCompleted {
+		t.Fatalf("runJob() = %+v, %v", resp, err)
+	}
+	got = j.status(ctx, worker.JobID)
+	if got.Status != JobCompleted || got.Translations[1] != "Bye" || got.Progress.ChunksDone != 3 || got.Output == "" {
+		t.Errorf("status() after completion = %+v", got)
+	}
+}
+
+func TestJobRunner_FailedStates(t *testing.T) {
+	states := jobstore.New(stateTable{}, "jobs")
+	j := &jobRunner{store: resultstore.New(memoryBucket{}, "bucket", jobsPrefix), invoker: failingInvoker{}, states: states}
+	h := &Handler{jobs: j}
+	ctx := context.Background()
+
+	submitted := j.submit(ctx, Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "en", Async: true})
+	if submitted.ErrorCode != ErrorUnavailable {
+		t.Fatalf("submit() = %+v, want %s", submitted, ErrorUnavailable)
+	}
+	job, err := states.Get(ctx, submitted.JobID)
+	if err != nil || job == nil {
+		t.Fatalf("job state = %+v, %v", job, err)
+	}
+	got := j.status(ctx, job.ID)
+	if got.Status != JobFailed || got.ErrorCode != ErrorUnavailable {
+		t.Errorf("status() of an unsubmitted job = %+v, want failed with %s", got, ErrorUnavailable)
+	}
+
+	req := Request{SourceLang: "es", TargetLang: "en", Async: true, JobID: "job-2", Mode: ModeBatch, OutputPrefix: "s3://out/job-2/"}
+	ctx = h.trackJob(ctx, req)
+	jobTrackerFrom(ctx).start(ctx)
+	if err := j.complete(ctx, req, errorResponse(ErrorTranslationFailed, "translator down")); err != nil {
+		t.Fatal(err)
+	}
+	got = j.status(ctx, req.JobID)
+	if got.Status != JobFailed || got.ErrorCode != ErrorTranslationFailed || got.Output != req.OutputPrefix {
+		t.Errorf("status() of a failed batch = %+v, want failed with output %s", got, req.OutputPrefix)
+	}
+}
+
+func TestParseRequest_StatusMode(t *testing.T) {
+	req, err := ParseRequest(json.RawMessage(`{"mode": "status", "jobId": "job-1"}`))
+	if err != nil || req.Mode != ModeStatus || req.JobID != "job-1" {
+		t.Fatalf("ParseRequest() = %+v, %v", req, err)
+	}
+	if _, err := ParseRequest(json.RawMessage(`{"mode": "status"}`)); err == nil {
+		t.Error("ParseRequest() without jobId error = nil")
+	}
+
+	h := NewHandler(&stubTranslator{})
+	h.jobs = &jobRunner{store: resultstore.New(memoryBucket{}, "bucket", jobsPrefix)}
+	resp, err := h.Handle(context.Background(), req)
+	if err != nil || resp.Error != nil || resp.Status != JobPending || resp.JobID != "job-1" {
+		t.Errorf("Handle(status mode) = %+v, %v, want a pending job", resp, err)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/keywords"
+)
+
+// ActionKeywords translates the texts and also expands every translation
+// into search keywords (Response.Keywords). The translations themselves are
+// the literal ones of ActionTranslate.
+const ActionKeywords = "keywords"
+
+// applyKeywords adds the search keywords of every translation for the
+// keywords action. Withheld translations get no keywords.
+func applyKeywords(resp *Response, req Request, e *keywords.Expander) {
+	if req.Action != ActionKeywords {
+		return
+	}
+	resp.Keywords = make([][]string, len(resp.Translations))
+	for i, t := range resp.Translations {
+		resp.Keywords[i] = e.Expand(req.TargetLang, t)
+		if resp.Keywords[i] == nil {
+			resp.Keywords[i] = []string{}
+		}
+	}
+}
+
+// validateKeywords checks the keywords action is used with titles.
+func validateKeywords(req Request) error {
+	if req.Action == ActionKeywords && req.Text != "" {
+		return fmt.Errorf("the keywords action does not support text; send titles in texts")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/keywords"
+)
+
+func TestApplyKeywords(t *testing.T) {
+	e, err := keywords.New(keywords.Glossary{"fr": {{"vélo", "bicyclette"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		want [][]string
+	}{
+		{"translate action", Request{TargetLang: "fr"}, nil},
+		{"keywords action", Request{Action: ActionKeywords, TargetLang: "fr"}, [][]string{{"vélo", "vélos", "bicyclette"}, {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{Translations: []string{"Vélo", ""}}
+			applyKeywords(resp, tt.req, e)
+			if !reflect.DeepEqual(resp.Keywords, tt.want) {
+				t.Errorf("Keywords = %q, want %q", resp.Keywords, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_Keywords(t *testing.T) {
+	valid := Request{Action: ActionKeywords, Texts: []string{"Bicicleta"}, SourceLang: "es", TargetLang: "fr"}
+	if err := validateRequest(valid); err != nil {
+		t.Errorf("validateRequest() error = %v", err)
+	}
+	doc := Request{Action: ActionKeywords, Text: "Bicicleta.", SourceLang: "es", TargetLang: "fr"}
+	if err := validateRequest(doc); err == nil {
+		t.Error("validateRequest() should reject the keywords action with text")
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// knownTexts are the texts of a request answered without the translators,
+// from the dictionary or the translation cache, while the others go to
+// the translators.
+type knownTexts struct {
+	// texts and tags are all texts of the request and their tags.
+	texts []string
+	tags  []string
+	// hits are the known translations by text index.
+	hits map[int]string
+	// scores are the route scores of the hits (router.Result.Scores).
+	scores map[int]float64
+	// cached are the translation cache entries of the hits from the cache.
+	cached map[int]cache.Entry
+	// cache is the translation cache, nil when it is disabled.
+	cache *cache.Cache
+}
+
+// add records the translation of text i.
+func (k *knownTexts) add(i int, translation string, score float64) {
+	if k.hits == nil {
+		k.hits = map[int]string{}
+		k.scores = map[int]float64{}
+	}
+	k.hits[i] = translation
+	k.scores[i] = score
+}
+
+// addCached records the translation cache entry e of text i.
+func (k *knownTexts) addCached(i int, e cache.Entry) {
+	var score float64
+	if e.Score != nil {
+		score = *e.Score
+	}
+	k.add(i, e.Translation, score)
+	if k.cached == nil {
+		k.cached = map[int]cache.Entry{}
+	}
+	k.cached[i] = e
+}
+
+// takeKnownTexts answers what it can without translating, then from the
+// dictionary, then the translation cache, and leaves only the other texts
+// in req.Texts; merge puts them back. The cache is skipped for experiment
+// variants, forced backends, tagged texts and premium or formal requests,
+// whose translators must actually run.
+func (h *Handler) takeKnownTexts(ctx context.Context, req *Request, overrides map[string]string, rec *metrics.Recorder) knownTexts {
+	known := knownTexts{texts: req.Texts, tags: req.Tags, cache: h.cache}
+	lookupPassthrough(*req, &known)
+	lookupDictionary(*req, &known)
+	lookupCache(ctx, *req, &known, len(overrides) > 0 || req.Backend != "" || tagged(*req) || styled(*req), rec)
+	if known.hits == nil {
+		return known
+	}
+	req.Texts = withoutHits(known, req.Texts)
+	if req.Tags != nil {
+		req.Tags = withoutHits(known, req.Tags)
+	}
+	return known
+}
+
+// withoutHits returns the values of the texts that are not known.
+func withoutHits[T any](k knownTexts, values []T) []T {
+	rest := make([]T, 0, len(values)-len(k.hits))
+	for i, v := range values {
+		if _, ok := k.hits[i]; !ok {
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
+
+// store remembers the translations of the texts left in req in the
+// translation cache, made by the route of result at translatedAt. Only
+// translator output is stored, and none of a request with tagged texts,
+// which may come from specialized translators, of a premium or formal
+// request, of a dry run, or of a job with dead-lettered chunks, whose
+// texts are left "".
+func (k knownTexts) store(ctx context.Context, req Request, translations []string, result *router.Result, scores []float64, translatedAt time.Time) {
+	if len(result.Steps) == 0 || result.Steps[0] == router.BackendDryRun || tagged(req) || styled(req) || len(result.Failed) > 0 {
+		return
+	}
+	storeCache(ctx, k.cache, req, translations, scores, routeModelVersion(result), translatedAt)
+}
+
+// merge restores all texts in req and interleaves the known translations
+// with the translations of the other texts.
+func (k knownTexts) merge(req *Request, translations []string) []string {
+	req.Texts, req.Tags = k.texts, k.tags
+	return spreadHits(k, translations, func(i int) string { return k.hits[i] })
+}
+
+// mergeScores interleaves the scores of the known translations with the
+// model scores of the other texts (see translatorScores). It is nil when
+// the request needs no scores or a translator returned none.
+func (k knownTexts) mergeScores(req Request, scores []float64) []float64 {
+	if !requestsScores(req) {
+		return nil
+	}
+	return spreadHits(k, scores, func(i int) float64 { return k.scores[i] })
+}
+
+// translatorScores returns the per-text scores of result, or nil.
+func translatorScores(result *router.Result, order [][]int) []float64 {
+	if result.Scores == nil {
+		return nil
+	}
+	return unchunk(result.Scores, order)
+}
+
+// spreadHits returns one value per request text: hit(i) for known texts
+// and the next value of rest for the others.
+func spreadHits[T any](k knownTexts, rest []T, hit func(int) T) []T {
+	if k.hits == nil {
+		return rest
+	}
+	out := make([]T, 0, len(k.texts))
+	for i := range k.texts {
+		if _, ok := k.hits[i]; ok {
+			out = append(out, hit(i))
+			continue
+		}
+		if len(rest) == 0 {
+			return nil
+		}
+		out = append(out, rest[0])
+		rest = rest[1:]
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// cacheTable is an in-memory translation cache table.
+type cacheTable struct {
+	items map[string]map[string]types.AttributeValue
+}
+
+func (c *cacheTable) BatchGetItem(_ context.Context, in *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+	for name, req := range in.RequestItems {
+		for _, key := range req.Keys {
+			if item, ok := c.items[key["key"].(*types.AttributeValueMemberS).Value]; ok {
+				out.Responses[name] = append(out.Responses[name], item)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (c *cacheTable) BatchWriteItem(_ context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, writes := range in.RequestItems {
+		for _, w := range writes {
+			c.items[w.PutRequest.Item["key"].(*types.AttributeValueMemberS).Value] = w.PutRequest.Item
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+// useTranslationCache gives h an in-memory translation cache.
+func useTranslationCache(h *Handler) *cacheTable {
+	table := &cacheTable{items: map[string]map[string]types.AttributeValue{}}
+	h.cache = cache.New(table, "cache", time.Hour)
+	return table
+}
+
+func TestKnownTexts_Merge(t *testing.T) {
+	texts := []string{"rojo", "Camiseta de algodón", "usado", "Pantalón"}
+	req := Request{Texts: texts, SourceLang: "es", TargetLang: "en"}
+	known := (&Handler{}).takeKnownTexts(context.Background(), &req, nil, nil)
+	if want := []string{"Camiseta de algodón", "Pantalón"}; !reflect.DeepEqual(req.Texts, want) {
+		t.Fatalf("texts left = %q, want %q", req.Texts, want)
+	}
+
+	got := known.merge(&req, []string{"Cotton T-shirt", "Trousers"})
+	want := []string{"red", "Cotton T-shirt", "used", "Trousers"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merge() = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(req.Texts, texts) {
+		t.Errorf("merge() left texts %q, want %q", req.Texts, texts)
+	}
+
+	req.IncludeConfidence = true
+	result := &router.Result{Scores: [][]float64{{-0.5}, {-1.5}}}
+	scores := known.mergeScores(req, translatorScores(result, nil))
+	if wantScores := []float64{0, -0.5, 0, -1.5}; !reflect.DeepEqual(scores, wantScores) {
+		t.Errorf("mergeScores() = %v, want %v", scores, wantScores)
+	}
+	if scores := known.mergeScores(req, translatorScores(&router.Result{}, nil)); scores != nil {
+		t.Errorf("mergeScores() without translator scores = %v, want nil", scores)
+	}
+	req.IncludeConfidence = false
+	if scores := known.mergeScores(req, translatorScores(result, nil)); scores != nil {
+		t.Errorf("mergeScores() without confidence requested = %v, want nil", scores)
+	}
+}
+
+func TestKnownTexts_Cache(t *testing.T) {
+	h := &Handler{}
+	table := useTranslationCache(h)
+	ctx := context.Background()
+
+	// First request: nothing is cached, the translations are stored
+	req := Request{Texts: []string{"Camiseta", "rojo", "Pantalón"}, SourceLang: "es", TargetLang: "en"}
+	known := h.takeKnownTexts(ctx, &req, nil, nil)
+	result := &router.Result{
+		Translations: [][]string{{"T-shirt", "Trousers"}},
+		Scores:       [][]float64{{-0.1, -0.2}},
+		Steps:        []string{"pricofy-translator-romance-en"},
+	}
+	known.store(ctx, req, flatten(result.Translations), result, translatorScores(result, nil), time.Now())
+	if len(table.items) != 2 {
+		t.Fatalf("store() wrote %d items, want 2 (dictionary answers are not cached)", len(table.items))
+	}
+
+	// Second request: everything is known
+	req = Request{Texts: []string{"Pantalón", "Camiseta", "rojo"}, SourceLang: "es", TargetLang: "en", IncludeConfidence: true}
+	known = h.takeKnownTexts(ctx, &req, nil, nil)
+	if len(req.Texts) != 0 {
+		t.Errorf("texts left = %q, want none", req.Texts)
+	}
+	if got, want := known.merge(&req, nil), []string{"Trousers", "T-shirt", "red"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merge() = %q, want %q", got, want)
+	}
+	if got, want := known.mergeScores(req, translatorScores(&router.Result{}, nil)), []float64{-0.2, -0.1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeScores() = %v, want %v", got, want)
+	}
+
+	// Experiment variants always run their translators
+	req = Request{Texts: []string{"Camiseta"}, SourceLang: "es", TargetLang: "en"}
+	h.takeKnownTexts(ctx, &req, map[string]string{"pricofy-translator-romance-en": "candidate"}, nil)
+	if len(req.Texts) != 1 {
+		t.Errorf("texts left with overrides = %q, want the text", req.Texts)
+	}
+
+	// Cache-only answers are never stored
+	req = Request{Texts: []string{"Zapato"}, SourceLang: "es", TargetLang: "en"}
+	known = h.takeKnownTexts(ctx, &req, nil, nil)
+	known.store(ctx, req, []string{""}, &router.Result{Translations: [][]string{{""}}}, nil, time.Now())
+	if len(table.items) != 2 {
+		t.Errorf("store() without translators wrote %d items, want 2", len(table.items))
+	}
+}
+
+func TestKnownTexts_CacheWithoutScores(t *testing.T) {
+	h := &Handler{}
+	useTranslationCache(h)
+	ctx := context.Background()
+
+	req := Request{Texts: []string{"Camiseta"}, SourceLang: "es", TargetLang: "en"}
+	known := h.takeKnownTexts(ctx, &req, nil, nil)
+	result := &router.Result{Translations: [][]string{{"T-shirt"}}, Steps: []string{"pricofy-translator-romance-en"}}
+	known.store(ctx, req, []string{"T-shirt"}, result, nil, time.Now())
+
+	req = Request{Texts: []string{"Camiseta"}, SourceLang: "es", TargetLang: "en", IncludeConfidence: true}
+	h.takeKnownTexts(ctx, &req, nil, nil)
+	if len(req.Texts) != 1 {
+		t.Errorf("texts left = %q, want the text: its cached translation has no score", req.Texts)
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// LanguagePair is the canonical pair a request was translated with.
+type LanguagePair struct {
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+}
+
+// resolveLanguages rewrites the request languages to canonical codes, so
+// callers may send names like "spanish" or tags like "pt-br". Unsupported
+// regional variants fall back to their base language, unless the request is
+// strict, in which case any language that does not map to a supported code is
+// an error. It returns the canonical pair when either language was rewritten.
+func resolveLanguages(req *Request) (*LanguagePair, []Warning, error) {
+	var warnings []Warning
+	source, w, err := resolveLanguage("sourceLang", req.SourceLang, req.StrictLanguages)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings = appendWarning(warnings, w)
+
+	target, w, err := resolveLanguage("targetLang", req.TargetLang, req.StrictLanguages)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings = appendWarning(warnings, w)
+
+	if source == req.SourceLang && target == req.TargetLang {
+		return nil, warnings, nil
+	}
+	req.SourceLang, req.TargetLang = source, target
+	return &LanguagePair{SourceLang: source, TargetLang: target}, warnings, nil
+}
+
+// resolveLanguage canonicalizes one request language.
+func resolveLanguage(field, value string, strict bool) (string, *Warning, error) {
+	if value == "" {
+		return value, nil, nil
+	}
+
+	code, ok := locale.Canonicalize(value)
+	switch {
+	case ok && router.IsSupportedLanguage(code):
+		if code == value {
+			return code, nil, nil
+		}
+		return code, &Warning{
+			Code:    WarningLanguageNormalized,
+			Message: fmt.Sprintf("%s %q was normalized to %q", field, value, code),
+		}, nil
+	case ok && !strict && router.IsSupportedLanguage(locale.Base(code)):
+		base := locale.Base(code)
+		return base, &Warning{
+			Code:    WarningLanguageFallback,
+			Message: fmt.Sprintf("%s %q is not supported, falling back to %q", field, value, base),
+		}, nil
+	case strict:
+		return "", nil, fmt.Errorf("unknown %s %q", field, value)
+	default:
+		return value, nil, nil
+	}
+}
+
+// appendWarning appends w unless it is nil.
+func appendWarning(warnings []Warning, w *Warning) []Warning {
+	if w == nil {
+		return warnings
+	}
+	return append(warnings, *w)
+}
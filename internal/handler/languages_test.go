@@ -0,0 +1,80 @@
+package handler
+
+import "testing"
+
+func TestResolveLanguages(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          Request
+		wantPair     *LanguagePair
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name: "canonical codes",
+			req:  Request{SourceLang: "es", TargetLang: "fr"},
+		},
+		{
+			name:         "names",
+			req:          Request{SourceLang: "spanish", TargetLang: "Deutsch"},
+			wantPair:     &LanguagePair{SourceLang: "es", TargetLang: "de"},
+			wantWarnings: []string{WarningLanguageNormalized, WarningLanguageNormalized},
+		},
+		{
+			name:         "tag casing",
+			req:          Request{SourceLang: "en", TargetLang: "pt-br"},
+			wantPair:     &LanguagePair{SourceLang: "en", TargetLang: "pt_BR"},
+			wantWarnings: []string{WarningLanguageNormalized},
+		},
+		{
+			name:         "unsupported region falls back",
+			req:          Request{SourceLang: "es-419", TargetLang: "en"},
+			wantPair:     &LanguagePair{SourceLang: "es", TargetLang: "en"},
+			wantWarnings: []string{WarningLanguageFallback},
+		},
+		{
+			name: "unknown names are left for validation",
+			req:  Request{SourceLang: "klingon", TargetLang: "en"},
+		},
+		{
+			name:         "strict accepts aliases",
+			req:          Request{SourceLang: "spanish", TargetLang: "en", StrictLanguages: true},
+			wantPair:     &LanguagePair{SourceLang: "es", TargetLang: "en"},
+			wantWarnings: []string{WarningLanguageNormalized},
+		},
+		{
+			name:    "strict rejects fallback",
+			req:     Request{SourceLang: "es-419", TargetLang: "en", StrictLanguages: true},
+			wantErr: true,
+		},
+		{
+			name:    "strict rejects unknown",
+			req:     Request{SourceLang: "es", TargetLang: "klingon", StrictLanguages: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			pair, warnings, err := resolveLanguages(&req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLanguages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (pair == nil) != (tt.wantPair == nil) || (pair != nil && *pair != *tt.wantPair) {
+				t.Errorf("resolveLanguages() pair = %+v, want %+v", pair, tt.wantPair)
+			}
+			if pair != nil && (req.SourceLang != pair.SourceLang || req.TargetLang != pair.TargetLang) {
+				t.Errorf("request not rewritten to %+v: %s→%s", pair, req.SourceLang, req.TargetLang)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %+v, want codes %v", warnings, tt.wantWarnings)
+			}
+			for i, w := range warnings {
+				if w.Code != tt.wantWarnings[i] {
+					t.Errorf("warnings[%d].Code = %s, want %s", i, w.Code, tt.wantWarnings[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logTranslation logs the outcome of translating req: a record per
+// request with its pair, size, route steps and duration, at warn level
+// when it failed with errorCode.
+func logTranslation(ctx context.Context, req Request, chunkCount int, steps []string, duration time.Duration, errorCode string) {
+	attrs := []any{
+		slog.String("languagePair", languagePair(req)),
+		slog.Int("textCount", len(req.Texts)),
+		slog.Int("chunkCount", chunkCount),
+		slog.Any("routeSteps", steps),
+		slog.Int64("durationMs", duration.Milliseconds()),
+	}
+	if errorCode != "" {
+		slog.WarnContext(ctx, "translation failed", append(attrs, slog.String("errorCode", errorCode))...)
+		return
+	}
+	slog.InfoContext(ctx, "translation", attrs...)
+}
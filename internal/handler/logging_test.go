@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/logging"
+)
+
+func TestHandle_LogsTranslation(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logging.New(&out, slog.LevelInfo))
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	resp, err := NewHandler(&stubTranslator{}).Handle(ctx, Request{Texts: []string{"Buen estado", "Nuevo"}, SourceLang: "es", TargetLang: "fr"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("want one JSON record, got %q: %v", out.String(), err)
+	}
+	want := map[string]any{
+		"msg":          "translation",
+		"requestId":    "req-1",
+		"languagePair": "es-fr",
+		"textCount":    2.0,
+		"chunkCount":   1.0,
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(record[key], value) {
+			t.Errorf("record[%q] = %v, want %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["routeSteps"].([]any); !ok {
+		t.Errorf("record has no routeSteps: %v", record)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/grapheme"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// Long token policies, Request.LongTokenPolicy.
+const (
+	// LongTokenPassthrough copies long tokens to the translation unchanged (the default).
+	LongTokenPassthrough = "passthrough"
+	// LongTokenTruncate keeps the first maxTokenLength characters of long tokens.
+	LongTokenTruncate = "truncate"
+)
+
+// maxTokenLength is the longest unbreakable token, in characters, sent to
+// the models. Longer ones (URLs, base64 blobs, concatenated SKUs) count
+// toward token budgets for nothing and make the models choke.
+const maxTokenLength = 200
+
+// longTokenSpans returns the long tokens of text to protect, rendered as
+// the request's policy says.
+func longTokenSpans(req *Request, text string) []protectedSpan {
+	spans := longTokens(text)
+	if len(spans) == 0 {
+		return nil
+	}
+	var render func(string) string
+	if req.LongTokenPolicy == LongTokenTruncate {
+		render = func(token string) string { return grapheme.Truncate(token, maxTokenLength) + "…" }
+	}
+	protected := make([]protectedSpan, len(spans))
+	for i, s := range spans {
+		protected[i] = protectedSpan{Span: s, render: render}
+	}
+	return protected
+}
+
+// longTokenWarning reports the long tokens of the text at index.
+func longTokenWarning(req *Request, index, n int) Warning {
+	return Warning{
+		Code:  WarningLongToken,
+		Index: &index,
+		Message: fmt.Sprintf("%d token(s) longer than %d characters were not translated (%s)",
+			n, maxTokenLength, longTokenPolicy(req)),
+	}
+}
+
+// longTokens returns the spans of whitespace-free runs longer than
+// maxTokenLength characters (grapheme clusters).
+func longTokens(text string) []protect.Span {
+	var spans []protect.Span
+	start, length := -1, 0
+	for i := 0; i < len(text); {
+		size := grapheme.Next(text[i:])
+		if r, _ := utf8.DecodeRuneInString(text[i:]); unicode.IsSpace(r) {
+			if length > maxTokenLength {
+				spans = append(spans, protect.Span{Start: start, End: i})
+			}
+			start, length = -1, 0
+		} else {
+			if start < 0 {
+				start = i
+			}
+			length++
+		}
+		i += size
+	}
+	if length > maxTokenLength {
+		spans = append(spans, protect.Span{Start: start, End: len(text)})
+	}
+	return spans
+}
+
+// longTokenPolicy returns the request's policy, defaulting to passthrough.
+func longTokenPolicy(req *Request) string {
+	if req.LongTokenPolicy == "" {
+		return LongTokenPassthrough
+	}
+	return req.LongTokenPolicy
+}
+
+// validateLongTokenPolicy checks Request.LongTokenPolicy.
+func validateLongTokenPolicy(policy string) error {
+	switch policy {
+	case "", LongTokenPassthrough, LongTokenTruncate:
+		return nil
+	default:
+		return fmt.Errorf("unknown longTokenPolicy %q", policy)
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLongTokens(t *testing.T) {
+	blob := strings.Repeat("A", maxTokenLength+1)
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"short tokens", "Zapatillas talla 42 https://example.com/p/1", nil},
+		{"long token", "ver " + blob + " gracias", []string{blob}},
+		{"at end", "ver " + blob, []string{blob}},
+		{"exactly the limit", strings.Repeat("a", maxTokenLength), nil},
+		{"multibyte", strings.Repeat("ñ", maxTokenLength+1), []string{strings.Repeat("ñ", maxTokenLength+1)}},
+		{"combining marks", strings.Repeat("n\u0303", maxTokenLength), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := longTokens(tt.text)
+			if len(spans) != len(tt.want) {
+				t.Fatalf("longTokens() = %v, want %d spans", spans, len(tt.want))
+			}
+			for i, s := range spans {
+				if got := tt.text[s.Start:s.End]; got != tt.want[i] {
+					t.Errorf("span %d = %.20q…, want %.20q…", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProtectTexts_LongTokens(t *testing.T) {
+	blob := strings.Repeat("x", 3000)
+	texts := []string{"Hola", "Código " + blob + " fin"}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{LongTokenPassthrough, "CODE " + blob + " END"},
+		{LongTokenTruncate, "CODE " + blob[:maxTokenLength] + "… END"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			req := Request{Texts: texts, LongTokenPolicy: tt.policy}
+			masks, warnings := protectTexts(&req, nil)
+
+			if texts[1] != "Código "+blob+" fin" {
+				t.Fatal("protectTexts() modified the caller's texts")
+			}
+			if req.Texts[1] != "Código __0__ fin" || req.Texts[0] != "Hola" {
+				t.Fatalf("masked texts = %.40q", req.Texts)
+			}
+			if len(warnings) != 1 || warnings[0].Code != WarningLongToken || *warnings[0].Index != 1 {
+				t.Errorf("warnings = %+v", warnings)
+			}
+
+			translations := []string{"Hello", "CODE __0__ END"}
+			restoreTexts(translations, masks)
+			if translations[1] != tt.want || translations[0] != "Hello" {
+				t.Errorf("restored = %.60q", translations)
+			}
+		})
+	}
+}
+
+func TestProtectTexts_None(t *testing.T) {
+	req := Request{Texts: []string{"Hola", "Adiós"}}
+	masks, warnings := protectTexts(&req, nil)
+	if masks != nil || warnings != nil {
+		t.Errorf("protectTexts() = %v, %v, want nothing", masks, warnings)
+	}
+}
+
+func TestProtectTexts_LiteralPlaceholders(t *testing.T) {
+	req := Request{Texts: []string{"Hola {name}, usa __0__", "Campo __0__"}, Placeholders: PlaceholdersOn}
+	masks, _ := protectTexts(&req, nil)
+	if req.Texts[0] != "Hola __0__, usa __1__" || req.Texts[1] != "Campo __0__" {
+		t.Fatalf("masked texts = %q", req.Texts)
+	}
+
+	translations := []string{"Hi __0__, use __1__", "Field __0__"}
+	restoreTexts(translations, masks)
+	if want := []string{"Hi {name}, use __0__", "Field __0__"}; !reflect.DeepEqual(translations, want) {
+		t.Errorf("restored = %q, want %q", translations, want)
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import "github.com/pricofy/translation-manager/internal/markup"
+
+// markupGrammar resolves Request.Markup for the calling tenant, nil when the
+// texts have no markup.
+func markupGrammar(set *markup.Set, req Request) (*markup.Compiled, error) {
+	if req.Markup == "" {
+		return nil, nil
+	}
+	return set.Lookup(req.TenantID, req.Markup)
+}
+
+// markupSpans returns the markup tags of text to keep verbatim, skipping
+// any inside the spans already taken.
+func markupSpans(grammar *markup.Compiled, text string, taken []protectedSpan) []protectedSpan {
+	if grammar == nil {
+		return nil
+	}
+	var spans []protectedSpan
+	for _, s := range grammar.Find(text) {
+		if !overlaps(s, taken) {
+			spans = append(spans, protectedSpan{Span: s})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/markup"
+)
+
+func TestProtectTexts_Markup(t *testing.T) {
+	set, err := markup.New(nil)
+	if err != nil {
+		t.Fatalf("markup.New() error = %v", err)
+	}
+	grammar, err := markupGrammar(set, Request{Markup: markup.BBCode})
+	if err != nil {
+		t.Fatalf("markupGrammar() error = %v", err)
+	}
+
+	blob := "[b]" + strings.Repeat("x", maxTokenLength) + "[/b]"
+	req := Request{
+		SourceLang: "es", TargetLang: "en", MeasurementPolicy: MeasurementPreserve,
+		Texts: []string{"[b]Envío gratis[/b] de [url=https://x.test]2 kg[/url]", "Ver " + blob},
+	}
+	masks, warnings := protectTexts(&req, grammar)
+
+	want := []string{"__0__Envío gratis__1__ de __2____3____4__", "Ver __0__"}
+	for i := range want {
+		if req.Texts[i] != want[i] {
+			t.Errorf("masked text %d = %.40q, want %q", i, req.Texts[i], want[i])
+		}
+	}
+	if len(warnings) != 1 || *warnings[0].Index != 1 {
+		t.Errorf("warnings = %+v, want one LONG_TOKEN for text 1", warnings)
+	}
+
+	translations := []string{"__0__Free shipping__1__ of __2____3____4__", "See __0__"}
+	restoreTexts(translations, masks)
+	if want := "[b]Free shipping[/b] of [url=https://x.test]2 kg[/url]"; translations[0] != want {
+		t.Errorf("restored = %q, want %q", translations[0], want)
+	}
+	if translations[1] != "See "+blob {
+		t.Errorf("restored = %.40q", translations[1])
+	}
+}
+
+func TestMarkupGrammar(t *testing.T) {
+	set, err := markup.New(map[string]map[string]markup.Grammar{"acme": {"wiki": {Open: "{{", Close: "}}"}}})
+	if err != nil {
+		t.Fatalf("markup.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		req     Request
+		wantNil bool
+		wantErr bool
+	}{
+		{"no markup", Request{}, true, false},
+		{"built-in", Request{Markup: markup.BBCode, TenantID: "other"}, false, false},
+		{"tenant grammar", Request{Markup: "wiki", TenantID: "acme"}, false, false},
+		{"other tenant's grammar", Request{Markup: "wiki", TenantID: "other"}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := markupGrammar(set, tt.req)
+			if (err != nil) != tt.wantErr || (g == nil) != tt.wantNil {
+				t.Errorf("markupGrammar() = %v, %v", g, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/measure"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// Measurement policies, Request.MeasurementPolicy. Without a policy,
+// measurements are translated like any other text.
+const (
+	// MeasurementPreserve copies measurement expressions verbatim.
+	MeasurementPreserve = "preserve"
+	// MeasurementLocalize rewrites their numbers in the target language's
+	// formatting ("2.5 kg" → "2,5 kg").
+	MeasurementLocalize = "localize"
+	// MeasurementConvert also converts them to the target market's system
+	// of units ("2.5 lb" → "1,1 kg").
+	MeasurementConvert = "convert"
+)
+
+// measurementRenderer returns how the request's policy renders a
+// measurement, or nil when measurements are not protected.
+func measurementRenderer(req *Request) func(measure.Measurement) string {
+	source, target := locale.Base(req.SourceLang), locale.Base(req.TargetLang)
+	switch req.MeasurementPolicy {
+	case MeasurementPreserve:
+		return func(m measure.Measurement) string { return m.Text }
+	case MeasurementLocalize:
+		return func(m measure.Measurement) string { return measure.Localize(m, source, target) }
+	case MeasurementConvert:
+		system := measure.System(req.MeasurementSystem)
+		if system == "" {
+			system = measure.MarketSystem(target)
+		}
+		return func(m measure.Measurement) string { return measure.Convert(m, system, source, target) }
+	default:
+		return nil
+	}
+}
+
+// measurementSpans returns the measurements of text to protect, skipping
+// any inside the spans already taken.
+func measurementSpans(req *Request, text string, taken []protectedSpan, render func(measure.Measurement) string) []protectedSpan {
+	if render == nil {
+		return nil
+	}
+	var spans []protectedSpan
+	for _, m := range measure.Find(text, locale.Base(req.SourceLang)) {
+		span := protect.Span{Start: m.Start, End: m.End}
+		if overlaps(span, taken) {
+			continue
+		}
+		m := m
+		spans = append(spans, protectedSpan{Span: span, render: func(string) string { return render(m) }})
+	}
+	return spans
+}
+
+// validateMeasurementOptions checks Request.MeasurementPolicy and
+// Request.MeasurementSystem.
+func validateMeasurementOptions(req Request) error {
+	switch req.MeasurementPolicy {
+	case "", MeasurementPreserve, MeasurementLocalize, MeasurementConvert:
+	default:
+		return fmt.Errorf("unknown measurementPolicy %q", req.MeasurementPolicy)
+	}
+	switch measure.System(req.MeasurementSystem) {
+	case "", measure.Metric, measure.Imperial:
+	default:
+		return fmt.Errorf("unknown measurementSystem %q", req.MeasurementSystem)
+	}
+	return nil
+}
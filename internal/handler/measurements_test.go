@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProtectTexts_Measurements(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    Request
+		masked string
+		want   string
+	}{
+		{
+			name:   "off by default",
+			req:    Request{SourceLang: "es", TargetLang: "en", Texts: []string{"Mochila 2,5 kg"}},
+			masked: "Mochila 2,5 kg",
+			want:   "Backpack 2,5 kg",
+		},
+		{
+			name:   "preserve",
+			req:    Request{SourceLang: "es", TargetLang: "en", Texts: []string{"Mochila 2,5 kg"}, MeasurementPolicy: MeasurementPreserve},
+			masked: "Mochila __0__",
+			want:   "Backpack 2,5 kg",
+		},
+		{
+			name:   "localize",
+			req:    Request{SourceLang: "es", TargetLang: "en", Texts: []string{"Mochila 2,5 kg"}, MeasurementPolicy: MeasurementLocalize},
+			masked: "Mochila __0__",
+			want:   "Backpack 2.5 kg",
+		},
+		{
+			name:   "convert to market system",
+			req:    Request{SourceLang: "es", TargetLang: "en", Texts: []string{"Mochila 2,5 kg"}, MeasurementPolicy: MeasurementConvert},
+			masked: "Mochila __0__",
+			want:   "Backpack 5.5 lb",
+		},
+		{
+			name: "convert to requested system",
+			req: Request{
+				SourceLang: "es", TargetLang: "en", Texts: []string{"Mochila 2,5 kg"},
+				MeasurementPolicy: MeasurementConvert, MeasurementSystem: "metric",
+			},
+			masked: "Mochila __0__",
+			want:   "Backpack 2.5 kg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masks, warnings := protectTexts(&tt.req, nil)
+			if tt.req.Texts[0] != tt.masked {
+				t.Fatalf("masked = %q, want %q", tt.req.Texts[0], tt.masked)
+			}
+			if warnings != nil {
+				t.Errorf("warnings = %+v, want none", warnings)
+			}
+
+			translations := []string{strings.Replace(tt.masked, "Mochila", "Backpack", 1)}
+			restoreTexts(translations, masks)
+			if translations[0] != tt.want {
+				t.Errorf("restored = %q, want %q", translations[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestProtectTexts_MeasurementInsideLongToken(t *testing.T) {
+	blob := strings.Repeat("x", maxTokenLength) + "5kg"
+	req := Request{SourceLang: "es", TargetLang: "en", Texts: []string{"Ver " + blob + " y 3 kg"}, MeasurementPolicy: MeasurementPreserve}
+	masks, warnings := protectTexts(&req, nil)
+
+	if req.Texts[0] != "Ver __0__ y __1__" {
+		t.Fatalf("masked = %.40q", req.Texts[0])
+	}
+	if len(warnings) != 1 || warnings[0].Code != WarningLongToken {
+		t.Errorf("warnings = %+v, want one LONG_TOKEN", warnings)
+	}
+	translations := []string{"See __0__ and __1__"}
+	restoreTexts(translations, masks)
+	if translations[0] != "See "+blob+" and 3 kg" {
+		t.Errorf("restored = %.40q", translations[0])
+	}
+}
+
+func TestValidateMeasurementOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"unset", Request{}, false},
+		{"convert imperial", Request{MeasurementPolicy: MeasurementConvert, MeasurementSystem: "imperial"}, false},
+		{"unknown policy", Request{MeasurementPolicy: "round"}, true},
+		{"unknown system", Request{MeasurementSystem: "us"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMeasurementOptions(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateMeasurementOptions() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// The ordering contract: a successful response has exactly one entry per
+// input text in every per-text field, and entry i answers texts[i]. Results
+// are never realigned; a violation, from a bug or a misbehaving translator,
+// fails the request with INTERNAL_ERROR.
+
+// checkOrdering verifies the ordering contract of resp for n input texts.
+func checkOrdering(resp *Response, n int) error {
+	if resp.Error != nil || resp.Validation != nil {
+		return nil
+	}
+	for _, f := range []struct {
+		name string
+		set  bool
+		len  int
+	}{
+		{"translations", true, len(resp.Translations)},
+		{"confidence", resp.Confidence != nil, len(resp.Confidence)},
+		{"slugs", resp.Slugs != nil, len(resp.Slugs)},
+		{"keywords", resp.Keywords != nil, len(resp.Keywords)},
+	} {
+		if f.set && f.len != n {
+			return fmt.Errorf("%d %s for %d texts", f.len, f.name, n)
+		}
+	}
+
+	indices := append([]int(nil), resp.LowConfidence...)
+	for _, b := range resp.Blocked {
+		indices = append(indices, b.Index)
+	}
+	for _, w := range resp.Warnings {
+		if w.Index != nil {
+			indices = append(indices, *w.Index)
+		}
+	}
+	for _, i := range indices {
+		if i < 0 || i >= n {
+			return fmt.Errorf("index %d out of range for %d texts", i, n)
+		}
+	}
+	return nil
+}
+
+// orderingViolation is the response to a broken ordering contract.
+func orderingViolation(err error, rec *metrics.Recorder) *Response {
+	rec.Add("OrderingViolations", metrics.Count, 1, nil)
+	return errorResponse(ErrorInternal, fmt.Sprintf("response ordering violated: %v", err))
+}
+
+// translationFailure is the response to a failed translation, naming the
+// route step that failed when there is one.
+func translationFailure(err error, elapsed time.Duration, rec *metrics.Recorder) *Response {
+	resp := failureResponse(err, elapsed, rec)
+	var stepErr *router.StepError
+	if errors.As(err, &stepErr) {
+		resp.Error.FailedStep = &FailedStep{Step: stepErr.Step, Steps: stepErr.Steps, Function: stepErr.Function}
+	}
+	return resp
+}
+
+// failureResponse returns the response to the kind of failure err is:
+// translator responses that do not line up with the texts break the
+// ordering contract, routes over the request's budget report their
+// estimated cost, and translations out of time how far they got after
+// elapsed.
+func failureResponse(err error, elapsed time.Duration, rec *metrics.Recorder) *Response {
+	var shapeErr *router.ShapeError
+	if errors.As(err, &shapeErr) {
+		return orderingViolation(err, rec)
+	}
+	if resp := costExceeded(err); resp != nil {
+		return resp
+	}
+	if resp := timedOut(err, elapsed); resp != nil {
+		return resp
+	}
+	return errorResponse(ErrorTranslationFailed, err.Error())
+}
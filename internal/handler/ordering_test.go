@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestCheckOrdering(t *testing.T) {
+	idx := func(i int) *int { return &i }
+
+	tests := []struct {
+		name    string
+		resp    *Response
+		wantErr bool
+	}{
+		{"aligned", &Response{
+			Translations: []string{"a", "b"}, Confidence: []float64{0.9, 0.1}, LowConfidence: []int{1},
+			Slugs: []string{"a", "b"}, Keywords: [][]string{{"a"}, {}},
+			Blocked:  []blocklist.Match{{Index: 0}},
+			Warnings: []Warning{{Code: WarningLongToken, Index: idx(1)}, {Code: WarningDeadlineRisk}},
+		}, false},
+		{"missing translation", &Response{Translations: []string{"a"}}, true},
+		{"extra translation", &Response{Translations: []string{"a", "b", "c"}}, true},
+		{"short confidence", &Response{Translations: []string{"a", "b"}, Confidence: []float64{0.9}}, true},
+		{"short slugs", &Response{Translations: []string{"a", "b"}, Slugs: []string{"a"}}, true},
+		{"short keywords", &Response{Translations: []string{"a", "b"}, Keywords: [][]string{{"a"}}}, true},
+		{"low confidence out of range", &Response{Translations: []string{"a", "b"}, LowConfidence: []int{2}}, true},
+		{"blocked out of range", &Response{Translations: []string{"a", "b"}, Blocked: []blocklist.Match{{Index: -1}}}, true},
+		{"warning out of range", &Response{Translations: []string{"a", "b"}, Warnings: []Warning{{Index: idx(5)}}}, true},
+		{"errors are exempt", errorResponse(ErrorTranslationFailed, "boom"), false},
+		{"validation is exempt", &Response{Translations: []string{}, Validation: &ValidationReport{}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkOrdering(tt.resp, 2); (err != nil) != tt.wantErr {
+				t.Errorf("checkOrdering() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranslationFailure(t *testing.T) {
+	shape := &router.ShapeError{Function: "translator", Chunk: 0, Want: 2, Got: 1}
+
+	step := func(err error) error {
+		return &router.StepError{Step: 2, Steps: 2, Function: "pricofy-translator-en-romance", Err: err}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		want          string
+		wantRetryable bool
+		wantStep      int
+	}{
+		{"misaligned translator", step(shape), ErrorInternal, false, 2},
+		{"misaligned routing entry", &router.EntryError{Err: shape}, ErrorInternal, false, 0},
+		{"over budget", fmt.Errorf("route: %w", &router.CostError{Estimate: 2, MaxCost: 1}), ErrorCostExceeded, false, 0},
+		{"out of time", &router.EntryError{Err: step(&router.TimeoutError{Step: 2, Steps: 2})}, ErrorTimeout, true, 2},
+		{"failed step", step(errors.New("throttled")), ErrorTranslationFailed, true, 2},
+		{"other failure", errors.New("timeout"), ErrorTranslationFailed, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := translationFailure(tt.err, 0, nil)
+			if resp.ErrorCode != tt.want || resp.Error.Code != tt.want || resp.Error.Retryable != tt.wantRetryable {
+				t.Errorf("translationFailure() = %q %+v, want %q retryable %v", resp.ErrorCode, resp.Error, tt.want, tt.wantRetryable)
+			}
+			var gotStep int
+			if resp.Error.FailedStep != nil {
+				gotStep = resp.Error.FailedStep.Step
+			}
+			if gotStep != tt.wantStep {
+				t.Errorf("translationFailure() failed step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Route kinds of the TranslatedChunks and TranslatedTokens metrics, besides
+// the names of external backends.
+const (
+	routeDirect = "direct"
+	routePivot  = "pivot"
+)
+
+// recordOutcome reports the latency and outcome of a translation request
+// per language pair, with failures counted by error code. Dry runs are not
+// translations and are left out.
+func recordOutcome(rec *metrics.Recorder, req Request, resp *Response, duration time.Duration) {
+	if req.Action == ActionValidate {
+		return
+	}
+	dims := metrics.Dimensions{"LanguagePair": languagePair(req)}
+	rec.Add("TranslationRequests", metrics.Count, 1, dims)
+	rec.Add("TranslationLatency", metrics.Milliseconds, float64(duration.Milliseconds()), dims)
+	if resp.ErrorCode != "" {
+		rec.Add("TranslationErrors", metrics.Count, 1, metrics.Dimensions{
+			"LanguagePair": languagePair(req),
+			"ErrorCode":    resp.ErrorCode,
+		})
+	}
+}
+
+// recordWork reports the chunks and estimated tokens translated per
+// language pair and route kind.
+func recordWork(rec *metrics.Recorder, req Request, chunks [][]string, result *router.Result) {
+	tokens := 0
+	for _, chunk := range chunks {
+		for _, text := range chunk {
+			tokens += chunker.EstimateTokensIn(text, req.SourceLang)
+		}
+	}
+	dims := metrics.Dimensions{"LanguagePair": languagePair(req), "Route": routeKind(result)}
+	rec.Add("TranslatedChunks", metrics.Count, float64(len(chunks)), dims)
+	rec.Add("TranslatedTokens", metrics.Count, float64(tokens), dims)
+}
+
+// recordRetries reports the translator calls retried for a request, per
+// language pair.
+func recordRetries(rec *metrics.Recorder, req Request, retries int) {
+	if retries > 0 {
+		rec.Add("TranslatorRetries", metrics.Count, float64(retries), metrics.Dimensions{"LanguagePair": languagePair(req)})
+	}
+}
+
+// routeKind classifies a route as direct, pivot or an external backend.
+func routeKind(result *router.Result) string {
+	if len(result.Steps) == 1 && result.Steps[0] != router.BackendLambda && router.IsBackend(result.Steps[0]) {
+		return result.Steps[0]
+	}
+	if result.PivotLang != "" {
+		return routePivot
+	}
+	return routeDirect
+}
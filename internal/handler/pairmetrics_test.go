@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestRecordOutcome(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		resp    *Response
+		want    []string
+		wantNot []string
+	}{
+		{
+			name:    "success",
+			req:     Request{SourceLang: "es", TargetLang: "en"},
+			resp:    &Response{Translations: []string{"red"}},
+			want:    []string{`"TranslationRequests":1`, `"TranslationLatency":1500`, `"LanguagePair":"es-en"`},
+			wantNot: []string{"TranslationErrors"},
+		},
+		{
+			name: "failure",
+			req:  Request{SourceLang: "es", TargetLang: "de"},
+			resp: errorResponse(ErrorTranslationFailed, "boom"),
+			want: []string{`"TranslationErrors":1`, `"ErrorCode":"TRANSLATION_FAILED"`, `"LanguagePair":"es-de"`},
+		},
+		{
+			name:    "dry run",
+			req:     Request{Action: ActionValidate, SourceLang: "es", TargetLang: "en"},
+			resp:    &Response{},
+			wantNot: []string{"TranslationRequests"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rec := metrics.New(&buf)
+			recordOutcome(rec, tt.req, tt.resp, 1500*time.Millisecond)
+			if err := rec.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("metrics missing %s: %s", want, buf.String())
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(buf.String(), unwanted) {
+					t.Errorf("metrics should not contain %s: %s", unwanted, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRecordWork(t *testing.T) {
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+	req := Request{SourceLang: "es", TargetLang: "de"}
+	chunks := [][]string{{"rojo", "azul"}, {"verde"}}
+
+	recordWork(rec, req, chunks, &router.Result{Steps: []string{"es-en", "en-de"}, PivotLang: "en"})
+	recordRetries(rec, req, 2)
+	recordRetries(rec, req, 0)
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"TranslatedChunks":2`, `"TranslatedTokens":7`, `"Route":"pivot"`, `"TranslatorRetries":2`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %s: %s", want, buf.String())
+		}
+	}
+}
+
+func TestRouteKind(t *testing.T) {
+	tests := []struct {
+		result *router.Result
+		want   string
+	}{
+		{&router.Result{Steps: []string{"translator-es-en"}}, routeDirect},
+		{&router.Result{Steps: []string{"es-en", "en-de"}, PivotLang: "en"}, routePivot},
+		{&router.Result{Steps: []string{router.BackendDeepL}}, router.BackendDeepL},
+	}
+	for _, tt := range tests {
+		if got := routeKind(tt.result); got != tt.want {
+			t.Errorf("routeKind(%v) = %q, want %q", tt.result.Steps, got, tt.want)
+		}
+	}
+}
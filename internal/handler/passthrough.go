@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/passthrough"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// Passthrough modes accepted in Request.Passthrough.
+const (
+	// PassthroughOn returns texts with nothing to translate as they are
+	// (the default).
+	PassthroughOn = "on"
+	// PassthroughOff sends every text to the translators.
+	PassthroughOff = "off"
+)
+
+// lookupPassthrough answers the texts of req that have nothing to
+// translate with themselves: numbers, SKUs, URLs, email addresses, emoji,
+// and texts left with only placeholders once protected spans are masked.
+// Passthrough answers are certain (log-probability 0).
+func lookupPassthrough(req Request, known *knownTexts) {
+	if req.Passthrough == PassthroughOff {
+		return
+	}
+	for i, text := range req.Texts {
+		if protect.OnlyPlaceholders(text) || passthrough.Detect(text) != "" {
+			known.add(i, text, 0)
+		}
+	}
+}
+
+// validatePassthrough checks Request.Passthrough.
+func validatePassthrough(mode string) error {
+	switch mode {
+	case "", PassthroughOn, PassthroughOff:
+		return nil
+	default:
+		return fmt.Errorf("unknown passthrough %q", mode)
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestLookupPassthrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		wantHits map[int]string
+	}{
+		{
+			name:     "mixed texts",
+			req:      Request{Texts: []string{"IPH-12-PRO-256GB", "Funda de silicona", "1.299,00", "🔥", "ventas@pricofy.com"}},
+			wantHits: map[int]string{0: "IPH-12-PRO-256GB", 2: "1.299,00", 3: "🔥", 4: "ventas@pricofy.com"},
+		},
+		{
+			name:     "only placeholders",
+			req:      Request{Texts: []string{"__0__", "Ver __0__"}},
+			wantHits: map[int]string{0: "__0__"},
+		},
+		{
+			name: "passthrough off",
+			req:  Request{Texts: []string{"IPH-12-PRO-256GB"}, Passthrough: PassthroughOff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var known knownTexts
+			lookupPassthrough(tt.req, &known)
+			if !reflect.DeepEqual(known.hits, tt.wantHits) {
+				t.Errorf("hits = %q, want %q", known.hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestHandle_Passthrough(t *testing.T) {
+	translator := &stubTranslator{}
+	req := Request{
+		Texts:      []string{"Funda", "IPH-12-PRO-256GB", "https://pricofy.com/p/1", "Cargador", "2024"},
+		SourceLang: "es",
+		TargetLang: "en",
+	}
+
+	resp, err := NewHandler(translator).Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	want := []string{"en:Funda", "IPH-12-PRO-256GB", "https://pricofy.com/p/1", "en:Cargador", "2024"}
+	if !reflect.DeepEqual(resp.Translations, want) {
+		t.Errorf("Translations = %q, want %q", resp.Translations, want)
+	}
+	if want := [][]string{{"Funda", "Cargador"}}; !reflect.DeepEqual(translator.chunks, want) {
+		t.Errorf("translator got %q, want %q", translator.chunks, want)
+	}
+}
+
+func TestValidatePassthrough(t *testing.T) {
+	for _, mode := range []string{"", PassthroughOn, PassthroughOff} {
+		if err := validatePassthrough(mode); err != nil {
+			t.Errorf("validatePassthrough(%q) error = %v", mode, err)
+		}
+	}
+	if err := validatePassthrough("sometimes"); err == nil {
+		t.Error("validatePassthrough(\"sometimes\") error = nil, want error")
+	}
+}
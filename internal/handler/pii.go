@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"os"
+
+	"github.com/pricofy/translation-manager/internal/pii"
+	"github.com/pricofy/translation-manager/internal/protect"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// PIIMaskingEnv names the environment variable choosing which requests get
+// their personal data masked besides those setting Request.MaskPII:
+// PIIMaskingExternal (default), PIIMaskingAll or PIIMaskingOff.
+const PIIMaskingEnv = "PII_MASKING"
+
+// PII_MASKING values.
+const (
+	// PIIMaskingExternal masks the texts of requests that may reach an
+	// external backend (router.IsExternal), e.g. DeepL.
+	PIIMaskingExternal = "external"
+	// PIIMaskingAll masks the texts of every request.
+	PIIMaskingAll = "all"
+	// PIIMaskingOff only masks the requests that ask for it.
+	PIIMaskingOff = "off"
+)
+
+// masksPII reports whether the email addresses, phone numbers and IBANs of
+// req are masked before translation. Unknown PII_MASKING values count as
+// the default, so a typo cannot send personal data out.
+func masksPII(ctx context.Context, req Request, r Translator) bool {
+	switch mode := os.Getenv(PIIMaskingEnv); {
+	case req.MaskPII || mode == PIIMaskingAll:
+		return true
+	case mode == PIIMaskingOff:
+		return false
+	}
+	return externalRoute(ctx, req, r)
+}
+
+// externalRoute reports whether req may be translated by an external
+// backend: one that the route of its untagged texts or of any of its tags
+// may use, routing table entries and the premium backend included.
+func externalRoute(ctx context.Context, req Request, r Translator) bool {
+	opts := router.Options{Backend: req.Backend, Premium: req.Quality == QualityPremium}
+	for _, tag := range append([]string{""}, req.Tags...) {
+		opts.Tag = tag
+		for _, backend := range r.Backends(ctx, req.SourceLang, req.TargetLang, opts) {
+			if router.IsExternal(backend) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// piiSpans returns the personal data of text to mask, skipping any inside
+// the spans already taken.
+func piiSpans(req *Request, text string, taken []protectedSpan) []protectedSpan {
+	if !req.MaskPII {
+		return nil
+	}
+	var spans []protectedSpan
+	for _, m := range pii.Find(text) {
+		span := protect.Span{Start: m.Start, End: m.End}
+		if !overlaps(span, taken) {
+			spans = append(spans, protectedSpan{Span: span})
+		}
+	}
+	return spans
+}
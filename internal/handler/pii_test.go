@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// routeTranslator is a stubTranslator whose pairs may be translated by
+// backends for each tag, and by premium for premium requests when set.
+type routeTranslator struct {
+	stubTranslator
+	backends map[string][]string
+	premium  string
+}
+
+func (t *routeTranslator) Backends(_ context.Context, _, _ string, opts router.Options) []string {
+	if backends, ok := t.backends[opts.Tag]; ok && opts.Tag != "" {
+		return backends
+	}
+	if opts.Premium && t.premium != "" {
+		return []string{t.premium}
+	}
+	if backends, ok := t.backends[""]; ok {
+		return backends
+	}
+	return []string{router.BackendLambda}
+}
+
+func TestMasksPII(t *testing.T) {
+	lambda := &routeTranslator{}
+	deepl := &routeTranslator{backends: map[string][]string{"": {router.BackendDeepL}}}
+	split := &routeTranslator{backends: map[string][]string{"": {router.BackendLambda, router.BackendDeepL}}}
+	taggedDeepL := &routeTranslator{backends: map[string][]string{"title": {router.BackendDeepL}}}
+	premium := &routeTranslator{premium: router.BackendDeepL}
+	tests := []struct {
+		name string
+		env  string
+		req  Request
+		r    *routeTranslator
+		want bool
+	}{
+		{name: "lambda route", r: lambda},
+		{name: "external route", r: deepl, want: true},
+		{name: "split with an external entry", r: split, want: true},
+		{name: "tag with an external entry", req: Request{Tags: []string{"", "title"}}, r: taggedDeepL, want: true},
+		{name: "untagged with an external tag entry", r: taggedDeepL},
+		{name: "premium through an external backend", req: Request{Quality: QualityPremium}, r: premium, want: true},
+		{name: "standard with an external premium backend", r: premium},
+		{name: "requested", req: Request{MaskPII: true}, r: lambda, want: true},
+		{name: "all", env: PIIMaskingAll, r: lambda, want: true},
+		{name: "off", env: PIIMaskingOff, r: deepl},
+		{name: "off but requested", env: PIIMaskingOff, req: Request{MaskPII: true}, r: lambda, want: true},
+		{name: "unknown mode", env: "sometimes", r: deepl, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PIIMaskingEnv, tt.env)
+			if got := masksPII(context.Background(), tt.req, tt.r); got != tt.want {
+				t.Errorf("masksPII() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandle_MaskPII(t *testing.T) {
+	tr := &routeTranslator{backends: map[string][]string{"": {router.BackendDeepL}}}
+	h := NewHandler(tr)
+	text := "Escríbeme a ana@correo.es o al +34 612 345 678"
+
+	resp, err := h.Handle(context.Background(), Request{Texts: []string{text, "Buen estado"}, SourceLang: "es", TargetLang: "en"})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	if len(tr.chunks) == 0 {
+		t.Fatal("no chunks translated")
+	}
+	for _, chunk := range tr.chunks {
+		for _, sent := range chunk {
+			if strings.Contains(sent, "ana@correo.es") || strings.Contains(sent, "612 345 678") {
+				t.Errorf("sent %q to an external backend", sent)
+			}
+		}
+	}
+	if want := "en:Escríbeme a ana@correo.es o al +34 612 345 678"; resp.Translations[0] != want {
+		t.Errorf("translation = %q, want %q", resp.Translations[0], want)
+	}
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Stages of a pipeline, each run by its own invocation from a Step
+// Functions state machine: validate → chunk → translate (once per hop, and
+// again while a hop has chunks left) → assemble.
+const (
+	StageValidate  = "validate"
+	StageChunk     = "chunk"
+	StageTranslate = "translate"
+	StageAssemble  = "assemble"
+)
+
+// pipelineBatch is the number of chunks a translate stage sends to the hop
+// at once.
+const pipelineBatch = 16
+
+// pipelineMargin is the time a translate stage keeps before the deadline of
+// its invocation to return its progress.
+const pipelineMargin = 30 * time.Second
+
+// PipelineState is the state a pipeline passes from stage to stage. Every
+// stage can be retried with the state it got.
+type PipelineState struct {
+	// Request is the request to translate, canonical once validated.
+	Request Request `json:"request"`
+	// Hops is the route of the request (validate).
+	Hops []router.Hop `json:"hops,omitempty"`
+	// Chunks are the texts in translation (chunk); the chunks of hop Hop
+	// before Chunk are translated by it.
+	Chunks [][]string `json:"chunks,omitempty"`
+	Hop    int        `json:"hop"`
+	Chunk  int        `json:"chunk"`
+	// Steps are the functions of the completed hops.
+	Steps []string `json:"steps,omitempty"`
+	// Done is set once every hop completed, for a Choice state to move on
+	// to assemble.
+	Done bool `json:"done"`
+	// Response is the translation (assemble).
+	Response *Response `json:"response,omitempty"`
+}
+
+// RunStage runs stage of a pipeline on state and returns the next state.
+// Failures have the error codes of Response.Error.
+func (h *Handler) RunStage(ctx context.Context, stage string, state PipelineState) (*PipelineState, *ErrorInfo) {
+	var resp *Response
+	switch stage {
+	case StageValidate:
+		resp = h.validateStage(ctx, &state)
+	case StageChunk:
+		resp = chunkStage(&state)
+	case StageTranslate:
+		resp = h.translateStage(ctx, &state)
+	case StageAssemble:
+		resp = assembleStage(&state)
+	default:
+		resp = errorResponse(ErrorInvalidRequest, fmt.Sprintf("unknown pipeline stage %q", stage))
+	}
+	if resp != nil {
+		finishError(resp, state.Request)
+		return nil, resp.Error
+	}
+	return &state, nil
+}
+
+// validateStage makes the request canonical, validates it and plans its
+// route.
+func (h *Handler) validateStage(ctx context.Context, state *PipelineState) *Response {
+	req := &state.Request
+	if err := h.applyProfile(ctx, req); err != nil {
+		return errorResponse(ErrorUnavailable, err.Error())
+	}
+	if _, _, err := resolveLanguages(req); err != nil {
+		return errorResponse(ErrorUnsupportedLanguage, err.Error())
+	}
+	if err := validateRequest(*req); err != nil {
+		return errorResponse(ErrorInvalidRequest, err.Error())
+	}
+	if err := validatePipeline(*req); err != nil {
+		return errorResponse(ErrorInvalidRequest, err.Error())
+	}
+	if req.DryRun {
+		req.Backend = router.BackendDryRun
+	}
+	if !h.translator.CanTranslate(req.SourceLang, req.TargetLang, req.Backend) {
+		return errorResponse(ErrorUnsupportedPair, req.SourceLang, req.TargetLang)
+	}
+	hops, err := h.translator.Hops(ctx, req.SourceLang, req.TargetLang, router.Options{Backend: req.Backend})
+	if err != nil {
+		return errorResponse(ErrorUnsupportedPair, req.SourceLang, req.TargetLang)
+	}
+	state.Hops = hops
+	// Every stage chunks with the token budget of the route's translators
+	if plan, err := h.translator.Plan(req.SourceLang, req.TargetLang, req.Backend); err == nil {
+		req.ChunkMaxTokens = chunkTokens(*req, plan)
+	}
+	// and masks the personal data the route needs masked
+	req.MaskPII = masksPII(ctx, *req, h.translator)
+	return nil
+}
+
+// chunkStage protects, splits and chunks the texts for the first hop.
+func chunkStage(state *PipelineState) *Response {
+	texts, resp := pipelineTexts(state.Request)
+	if resp != nil {
+		return resp
+	}
+	state.Chunks, state.Hop, state.Chunk, state.Steps = texts.chunks, 0, 0, nil
+	state.Done = len(texts.chunks) == 0
+	return nil
+}
+
+// translateStage translates the chunks left of the current hop, a batch
+// at a time, until they are done or the invocation is about to run out of
+// time. The hop moves on once all of its chunks are translated.
+func (h *Handler) translateStage(ctx context.Context, state *PipelineState) *Response {
+	if state.Done || state.Hop >= len(state.Hops) {
+		state.Done = true
+		return nil
+	}
+	start := time.Now()
+	hop := state.Hops[state.Hop]
+	for first := true; state.Chunk < len(state.Chunks) && (first || !nearDeadline(ctx)); first = false {
+		end := min(state.Chunk+pipelineBatch, len(state.Chunks))
+		result, err := h.translator.TranslateHop(ctx, hop, state.Hop+1, len(state.Hops), state.Chunks[state.Chunk:end])
+		if err != nil {
+			return translationFailure(err, time.Since(start), metrics.New(os.Stdout))
+		}
+		copy(state.Chunks[state.Chunk:end], result.Translations)
+		state.Chunk = end
+	}
+	if state.Chunk == len(state.Chunks) {
+		state.Steps = append(state.Steps, hop.Function)
+		state.Hop, state.Chunk = state.Hop+1, 0
+		state.Done = state.Hop == len(state.Hops)
+	}
+	return nil
+}
+
+// nearDeadline reports whether ctx is within pipelineMargin of its
+// deadline. The first batch of an invocation runs regardless, so every
+// invocation makes progress.
+func nearDeadline(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < pipelineMargin
+}
+
+// assembleStage puts the translated chunks back in text order, restores
+// what the chunk stage protected and applies the post-processing of
+// translate.
+func assembleStage(state *PipelineState) *Response {
+	if !state.Done {
+		return errorResponse(ErrorInvalidRequest, fmt.Sprintf("pipeline has hops left: %d of %d done", state.Hop, len(state.Hops)))
+	}
+	texts, resp := pipelineTexts(state.Request)
+	if resp != nil {
+		return resp
+	}
+	rec := metrics.New(os.Stdout)
+	defer rec.Flush() //nolint:errcheck // metrics are best effort
+
+	req := texts.req
+	result, order := stitchPieces(texts.split, req.TargetLang, &router.Result{Translations: state.Chunks}, texts.order)
+	translations := unchunk(result.Translations, order)
+	if len(translations) != len(req.Texts) {
+		return orderingViolation(fmt.Errorf("%d translations for %d texts", len(translations), len(req.Texts)), rec)
+	}
+	warnings := append(texts.warnings, checkPlaceholders(req, translations, texts.masks)...)
+	restoreTexts(translations, texts.masks)
+
+	req = state.Request
+	applyPostEdits(texts.policies.postEdit, req, translations, rec)
+	applyVariants(texts.policies.variants, req, translations, rec)
+	applyContentTypes(req, translations)
+	out := &Response{Translations: translations, ChunksProcessed: len(state.Chunks), Warnings: warnings}
+	out.Blocked = applyBlocklist(texts.policies.blocklist, req, translations, rec)
+	applySlugs(out, req)
+	applyTruncation(out, req)
+	applyKeywords(out, req, texts.policies.keywords)
+	out.Route = &RouteInfo{Steps: state.Steps}
+	for i := 1; i < len(state.Hops); i++ {
+		out.Route.Pivots = append(out.Route.Pivots, state.Hops[i].SourceLang)
+	}
+	if len(out.Route.Pivots) > 0 {
+		out.Route.PivotLang = out.Route.Pivots[0]
+	}
+	shapeResponse(out, fieldSet(req.Fields))
+	state.Response = out
+	return nil
+}
+
+// preparedTexts are the texts of a pipeline request as the chunk stage
+// sends them: protected, split and chunked. Preparing them is
+// deterministic, so assemble prepares them again rather than passing them
+// through the state.
+type preparedTexts struct {
+	policies *policies
+	req      Request // with protected texts
+	masks    [][]string
+	warnings []Warning
+	split    *chunker.Split
+	chunks   [][]string
+	order    [][]int
+}
+
+// pipelineTexts prepares the texts of req.
+func pipelineTexts(req Request) (*preparedTexts, *Response) {
+	pol, err := containerPolicies()
+	if err != nil {
+		return nil, errorResponse(ErrorUnavailable, err.Error())
+	}
+	grammar, err := markupGrammar(pol.markup, req)
+	if err != nil {
+		return nil, errorResponse(ErrorInvalidRequest, err.Error())
+	}
+	p := &preparedTexts{policies: pol}
+	p.masks, p.warnings = protectTexts(&req, grammar)
+	p.req = req
+	p.split = splitTexts(req)
+	pieces := req
+	pieces.Texts = p.split.Texts
+	p.chunks, p.order = scheduleChunks(pieces, chunker.DefaultMaxTextsPerChunk)
+	return p, nil
+}
+
+// validatePipeline rejects the options pipelines do not support: they
+// translate a list of texts with one route, without jobs, caches or
+// budgets of their own.
+func validatePipeline(req Request) error {
+	for _, option := range []struct {
+		set  bool
+		name string
+	}{
+		{req.Action != "", "action"},
+		{req.Async || req.JobID != "", "async"},
+		{req.Mode != "", "mode"},
+		{req.IdempotencyKey != "", "idempotencyKey"},
+		{req.CacheOnly, "cacheOnly"},
+		{req.Text != "", "text"},
+		{req.PO != "", "po"},
+		{req.ChunkStrategy == ChunkHTML, "chunkStrategy html"},
+		{req.Tags != nil, "tags"},
+		{req.Hashes != nil, "hashes"},
+		{req.IncludeConfidence || req.MinConfidence != nil, "confidence"},
+		{req.MaxCost > 0, "maxCost"},
+		{req.TimeoutMs > 0, "timeoutMs"},
+		{req.IncludeIntermediate, "includeIntermediate"},
+		{req.Quality == QualityPremium, "quality premium"},
+		{req.Formality != "", "formality"},
+	} {
+		if option.set {
+			return fmt.Errorf("pipelines do not support %s", option.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// pivotTranslator is a stubTranslator routing every pair through English.
+type pivotTranslator struct {
+	stubTranslator
+}
+
+func (p *pivotTranslator) Hops(_ context.Context, source, target string, _ router.Options) ([]router.Hop, error) {
+	return []router.Hop{
+		{Function: "translator-" + source + "-en", SourceLang: source, TargetLang: "en"},
+		{Function: "translator-en-" + target, SourceLang: "en", TargetLang: target},
+	}, nil
+}
+
+// runPipeline runs every stage of a pipeline the way a state machine
+// would, counting the translate invocations.
+func runPipeline(ctx context.Context, t *testing.T, h *Handler, req Request) (*Response, int) {
+	t.Helper()
+	state := &PipelineState{Request: req}
+	var errInfo *ErrorInfo
+	for _, stage := range []string{StageValidate, StageChunk} {
+		if state, errInfo = h.RunStage(ctx, stage, *state); errInfo != nil {
+			t.Fatalf("RunStage(%s) error = %v", stage, errInfo)
+		}
+	}
+	translates := 0
+	for !state.Done {
+		if state, errInfo = h.RunStage(ctx, StageTranslate, *state); errInfo != nil {
+			t.Fatalf("RunStage(%s) error = %v", StageTranslate, errInfo)
+		}
+		translates++
+	}
+	if state, errInfo = h.RunStage(ctx, StageAssemble, *state); errInfo != nil {
+		t.Fatalf("RunStage(%s) error = %v", StageAssemble, errInfo)
+	}
+	return state.Response, translates
+}
+
+func TestPipeline(t *testing.T) {
+	h := NewHandler(&pivotTranslator{})
+	req := Request{Texts: []string{"Hola {name}", "Adiós"}, SourceLang: "spanish", TargetLang: "de", Placeholders: PlaceholdersOn, Fields: []string{FieldPivot}}
+
+	resp, translates := runPipeline(context.Background(), t, h, req)
+	want := []string{"de:en:Hola {name}", "de:en:Adiós"}
+	if !reflect.DeepEqual(resp.Translations, want) {
+		t.Errorf("translations = %q, want %q", resp.Translations, want)
+	}
+	if translates != 2 {
+		t.Errorf("translate stages = %d, want one per hop", translates)
+	}
+	wantRoute := &RouteInfo{Steps: []string{"translator-es-en", "translator-en-de"}, PivotLang: "en", Pivots: []string{"en"}}
+	if !reflect.DeepEqual(resp.Route, wantRoute) {
+		t.Errorf("route = %+v, want %+v", resp.Route, wantRoute)
+	}
+}
+
+func TestPipeline_Resume(t *testing.T) {
+	texts := make([]string, 900)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("texto %d", i)
+	}
+	// Too little time left for more than one batch per invocation
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute-pipelineMargin-time.Second)
+	defer cancel()
+
+	resp, translates := runPipeline(ctx, t, NewHandler(&pivotTranslator{}), Request{Texts: texts, SourceLang: "es", TargetLang: "de"})
+	if translates != 4 {
+		t.Errorf("translate stages = %d, want 2 per hop", translates)
+	}
+	if resp.ChunksProcessed != 18 || resp.Translations[899] != "de:en:texto 899" {
+		t.Errorf("chunks = %d, last translation = %q", resp.ChunksProcessed, resp.Translations[899])
+	}
+}
+
+func TestRunStage_Errors(t *testing.T) {
+	h := NewHandler(&pivotTranslator{})
+	valid := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "de"}
+
+	tests := []struct {
+		name     string
+		stage    string
+		state    PipelineState
+		wantCode string
+	}{
+		{"unknown stage", "review", PipelineState{Request: valid}, ErrorInvalidRequest},
+		{"unknown language", StageValidate, PipelineState{Request: Request{Texts: []string{"Hola"}, SourceLang: "xx", TargetLang: "de", StrictLanguages: true}}, ErrorUnsupportedLanguage},
+		{"async", StageValidate, PipelineState{Request: Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "de", Async: true}}, ErrorInvalidRequest},
+		{"po", StageValidate, PipelineState{Request: Request{PO: storefrontPO, SourceLang: "es", TargetLang: "de"}}, ErrorInvalidRequest},
+		{"assemble before the hops are done", StageAssemble, PipelineState{Request: valid, Hops: []router.Hop{{}}}, ErrorInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, errInfo := h.RunStage(context.Background(), tt.stage, tt.state)
+			if state != nil || errInfo == nil || errInfo.Code != tt.wantCode {
+				t.Errorf("RunStage() = %+v, %+v, want error %s", state, errInfo, tt.wantCode)
+			}
+		})
+	}
+}
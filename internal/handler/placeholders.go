@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/placeholder"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// Placeholder modes, Request.Placeholders.
+const (
+	// PlaceholdersOn keeps interpolation tokens such as "{count}" or "%s"
+	// out of the translation and flags the texts whose translation lost one.
+	PlaceholdersOn = "on"
+	// PlaceholdersOff translates them as text (the default).
+	PlaceholdersOff = "off"
+)
+
+// placeholderSpans returns the placeholders of text to protect, skipping
+// any inside the spans already taken.
+func placeholderSpans(req *Request, text string, taken []protectedSpan) []protectedSpan {
+	if req.Placeholders != PlaceholdersOn {
+		return nil
+	}
+	var spans []protectedSpan
+	for _, s := range placeholder.Find(text) {
+		if !overlaps(s, taken) {
+			spans = append(spans, protectedSpan{Span: s})
+		}
+	}
+	return spans
+}
+
+// checkPlaceholders flags the translations, still masked, that dropped
+// placeholders of their text. Restoring appends the dropped ones at the
+// end, so nothing is lost but the wording needs review.
+func checkPlaceholders(req Request, translations []string, masks [][]string) []Warning {
+	if req.Placeholders != PlaceholdersOn {
+		return nil
+	}
+	var warnings []Warning
+	for i, originals := range masks {
+		var dropped []string
+		for _, n := range protect.Dropped(translations[i], len(originals)) {
+			if placeholder.Is(originals[n]) {
+				dropped = append(dropped, originals[n])
+			}
+		}
+		if len(dropped) > 0 {
+			index := i
+			warnings = append(warnings, Warning{
+				Code:    WarningPlaceholderDropped,
+				Index:   &index,
+				Message: fmt.Sprintf("the translation dropped %s, appended at the end", strings.Join(dropped, " ")),
+			})
+		}
+	}
+	return warnings
+}
+
+// validatePlaceholders checks Request.Placeholders.
+func validatePlaceholders(mode string) error {
+	switch mode {
+	case "", PlaceholdersOn, PlaceholdersOff:
+		return nil
+	default:
+		return fmt.Errorf("unknown placeholders mode %q", mode)
+	}
+}
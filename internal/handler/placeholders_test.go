@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProtectTexts_Placeholders(t *testing.T) {
+	texts := []string{"Hola {{name}}, tienes {count} mensajes", "%s vendido por %.2f €", "Sin marcadores"}
+
+	req := Request{Texts: texts, Placeholders: PlaceholdersOn}
+	masks, _ := protectTexts(&req, nil)
+	want := []string{"Hola __0__, tienes __1__ mensajes", "__0__ vendido por __1__ €", "Sin marcadores"}
+	for i := range want {
+		if req.Texts[i] != want[i] {
+			t.Errorf("masked text %d = %q, want %q", i, req.Texts[i], want[i])
+		}
+	}
+
+	translations := []string{"Hi __0__, you have __1__ messages", "__0__ sold for __1__ €", "No placeholders"}
+	if warnings := checkPlaceholders(req, translations, masks); len(warnings) != 0 {
+		t.Errorf("checkPlaceholders() = %+v, want no warnings", warnings)
+	}
+	restoreTexts(translations, masks)
+	if translations[0] != "Hi {{name}}, you have {count} messages" || translations[1] != "%s sold for %.2f €" {
+		t.Errorf("restored = %q", translations)
+	}
+
+	off := Request{Texts: texts}
+	if masks, _ := protectTexts(&off, nil); masks != nil {
+		t.Errorf("protectTexts() without placeholders = %q, want nothing masked", masks)
+	}
+}
+
+func TestCheckPlaceholders(t *testing.T) {
+	req := Request{Texts: []string{"<b>{count}</b> artículos"}, ChunkStrategy: ChunkHTML, Placeholders: PlaceholdersOn}
+	masks, _ := protectTexts(&req, nil)
+	if len(masks) != 1 || len(masks[0]) != 3 {
+		t.Fatalf("masks = %q, want the two tags and the placeholder", masks)
+	}
+
+	// The model dropped the placeholder and a tag: only the placeholder is flagged
+	translations := []string{"__0__ items"}
+	warnings := checkPlaceholders(req, translations, masks)
+	if len(warnings) != 1 || warnings[0].Code != WarningPlaceholderDropped || *warnings[0].Index != 0 || !strings.Contains(warnings[0].Message, "{count}") {
+		t.Fatalf("checkPlaceholders() = %+v, want a PLACEHOLDER_DROPPED warning for {count}", warnings)
+	}
+	restoreTexts(translations, masks)
+	if !strings.Contains(translations[0], "{count}") {
+		t.Errorf("restored = %q, want the dropped placeholder appended", translations[0])
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	for _, mode := range []string{"", PlaceholdersOn, PlaceholdersOff} {
+		if err := validatePlaceholders(mode); err != nil {
+			t.Errorf("validatePlaceholders(%q) error = %v", mode, err)
+		}
+	}
+	if err := validatePlaceholders("protect"); err == nil {
+		t.Error("validatePlaceholders(protect) error = nil, want an error")
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/po"
+)
+
+// preparePO parses Request.PO and uses the source texts of its untranslated
+// and fuzzy entries as the texts to translate. Placeholders are protected
+// unless the request says otherwise, since catalogs are full of format
+// strings. It returns nil for other requests.
+func preparePO(req *Request) *po.Catalog {
+	if req.PO == "" {
+		return nil
+	}
+	// validatePO rejected catalogs that do not parse
+	catalog, err := po.Parse(req.PO)
+	if err != nil {
+		return nil
+	}
+	req.Texts = append([]string{}, catalog.Texts()...)
+	if req.Placeholders == "" {
+		req.Placeholders = PlaceholdersOn
+	}
+	return catalog
+}
+
+// finishPO writes the translations into the catalog of the response.
+// Withheld and rejected translations leave their entry untranslated.
+func finishPO(resp *Response, catalog *po.Catalog) {
+	if catalog == nil || resp.Error != nil {
+		return
+	}
+	texts := catalog.Texts()
+	if len(resp.Translations) != len(texts) {
+		return
+	}
+	resp.Segments = texts
+	resp.PO = catalog.Render(resp.Translations)
+}
+
+// validatePO checks that a PO request parses and only asks for what applies
+// to catalogs.
+func validatePO(req Request) error {
+	if req.PO == "" {
+		return nil
+	}
+	switch {
+	case req.Texts != nil || req.Text != "":
+		return fmt.Errorf("po, texts and text are mutually exclusive")
+	case req.Mode == ModeBatch:
+		return fmt.Errorf("po is not supported with mode %q", ModeBatch)
+	case req.ChunkStrategy == ChunkHTML:
+		return fmt.Errorf("po is not supported with chunkStrategy %q", ChunkHTML)
+	case req.Slugs || req.TruncatedTo > 0:
+		return fmt.Errorf("slugs and truncatedTo are not supported with po")
+	case len(req.ContentTypes) > 0 || len(req.Tags) > 0 || len(req.Hashes) > 0:
+		return fmt.Errorf("per-text contentTypes, tags and hashes are not supported with po")
+	case req.Action == ActionKeywords:
+		return fmt.Errorf("the keywords action does not support po")
+	}
+	if _, err := po.Parse(req.PO); err != nil {
+		return fmt.Errorf("po: %w", err)
+	}
+	return nil
+}
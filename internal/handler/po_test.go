@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const storefrontPO = `msgid ""
+msgstr "Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+#: cart.html:12
+msgid "Hola mundo"
+msgstr ""
+
+#, c-format
+msgid "%d artículo"
+msgid_plural "%d artículos"
+msgstr[0] ""
+msgstr[1] ""
+
+msgid "Adiós"
+msgstr "Au revoir"
+`
+
+func TestPOMode(t *testing.T) {
+	req := Request{PO: storefrontPO, SourceLang: "es", TargetLang: "fr"}
+
+	catalog := preparePO(&req)
+	if catalog == nil {
+		t.Fatal("preparePO() returned nil for a po request")
+	}
+	wantTexts := []string{"Hola mundo", "%d artículo", "%d artículos"}
+	if !reflect.DeepEqual(req.Texts, wantTexts) || req.Placeholders != PlaceholdersOn {
+		t.Fatalf("preparePO() texts = %q, placeholders = %q, want %q and on", req.Texts, req.Placeholders, wantTexts)
+	}
+
+	resp := &Response{Translations: []string{"Bonjour le monde", "%d article", "%d articles"}}
+	finishPO(resp, catalog)
+	for _, want := range []string{"#: cart.html:12\nmsgid \"Hola mundo\"\nmsgstr \"Bonjour le monde\"", "msgstr[1] \"%d articles\"", "msgstr \"Au revoir\""} {
+		if !strings.Contains(resp.PO, want) {
+			t.Errorf("PO =\n%s\nwant it to contain %q", resp.PO, want)
+		}
+	}
+	if !reflect.DeepEqual(resp.Segments, wantTexts) {
+		t.Errorf("Segments = %q, want %q", resp.Segments, wantTexts)
+	}
+}
+
+func TestHandle_PO(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "dev")
+	req := Request{PO: storefrontPO, SourceLang: "es", TargetLang: "fr", DryRun: true}
+
+	resp, err := NewHandler(newRouter(t)).Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp, err)
+	}
+	// The format verb reaches the translation untouched
+	if !strings.Contains(resp.PO, "msgstr \"[fr] aloH odnum\"") || !strings.Contains(resp.PO, "msgstr[0] \"[fr] %d olucítra\"") {
+		t.Errorf("PO =\n%s", resp.PO)
+	}
+}
+
+func TestValidatePO(t *testing.T) {
+	base := Request{PO: storefrontPO, SourceLang: "es", TargetLang: "fr"}
+	if err := validateRequest(base); err != nil {
+		t.Fatalf("validateRequest() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Request)
+	}{
+		{"with texts", func(r *Request) { r.Texts = []string{"Hola"} }},
+		{"with text", func(r *Request) { r.Text = "Hola" }},
+		{"html", func(r *Request) { r.ChunkStrategy = ChunkHTML }},
+		{"slugs", func(r *Request) { r.Slugs = true }},
+		{"invalid catalog", func(r *Request) { r.PO = "msgid \"a\"\n" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			tt.modify(&req)
+			if err := validateRequest(req); err == nil {
+				t.Error("validateRequest() error = nil, want an error")
+			}
+		})
+	}
+}
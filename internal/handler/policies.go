@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/experiment"
+	"github.com/pricofy/translation-manager/internal/keywords"
+	"github.com/pricofy/translation-manager/internal/markup"
+	"github.com/pricofy/translation-manager/internal/postedit"
+	"github.com/pricofy/translation-manager/internal/segment"
+	"github.com/pricofy/translation-manager/internal/variant"
+)
+
+// policies bundles the configurable behaviour loaded from the environment.
+type policies struct {
+	postEdit    *postedit.Engine
+	blocklist   *blocklist.List
+	experiments *experiment.Set
+	keywords    *keywords.Expander
+	segments    *segment.Exceptions
+	markup      *markup.Set
+	variants    *variant.Substituter
+}
+
+// Policies are loaded once per Lambda container.
+var (
+	policiesOnce   sync.Once
+	loadedPolicies *policies
+	policiesErr    error
+)
+
+// containerPolicies returns the container-wide policies, loading them on first use.
+func containerPolicies() (*policies, error) {
+	policiesOnce.Do(func() {
+		loadedPolicies, policiesErr = loadPolicies()
+	})
+	return loadedPolicies, policiesErr
+}
+
+// loadPolicies reads every policy config from the environment.
+func loadPolicies() (*policies, error) {
+	p := &policies{}
+	var err error
+
+	if p.postEdit, err = postedit.Load(); err != nil {
+		return nil, fmt.Errorf("invalid post-edit rules: %w", err)
+	}
+	if p.blocklist, err = blocklist.Load(); err != nil {
+		return nil, fmt.Errorf("invalid blocklist: %w", err)
+	}
+	if p.experiments, err = experiment.Load(); err != nil {
+		return nil, fmt.Errorf("invalid experiments: %w", err)
+	}
+	if p.keywords, err = keywords.Load(); err != nil {
+		return nil, fmt.Errorf("invalid keyword glossary: %w", err)
+	}
+	if p.segments, err = segment.LoadExceptions(); err != nil {
+		return nil, fmt.Errorf("invalid segment exceptions: %w", err)
+	}
+	if p.markup, err = markup.Load(); err != nil {
+		return nil, fmt.Errorf("invalid markup grammars: %w", err)
+	}
+	if p.variants, err = variant.Load(); err != nil {
+		return nil, fmt.Errorf("invalid variant substitutions: %w", err)
+	}
+	return p, nil
+}
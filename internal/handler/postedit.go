@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/postedit"
+	"github.com/pricofy/translation-manager/internal/variant"
+)
+
+// applyPostEdits rewrites translations with the configured post-edit rules
+// and records a PostEditHits metric per rule.
+func applyPostEdits(engine *postedit.Engine, req Request, translations []string, rec *metrics.Recorder) map[string]int {
+	hits := engine.Apply(req.SourceLang, req.TargetLang, translations)
+	for rule, n := range hits {
+		rec.Add("PostEditHits", metrics.Count, float64(n), metrics.Dimensions{
+			"LanguagePair": languagePair(req),
+			"Rule":         rule,
+		})
+	}
+	return hits
+}
+
+// applyVariants rewrites translations into the wording of a regional
+// target such as es_MX and records a VariantSubstitutions metric.
+func applyVariants(s *variant.Substituter, req Request, translations []string, rec *metrics.Recorder) int {
+	n := s.Apply(req.TargetLang, translations)
+	if n > 0 {
+		rec.Add("VariantSubstitutions", metrics.Count, float64(n), metrics.Dimensions{"LanguagePair": languagePair(req)})
+	}
+	return n
+}
+
+// languagePair formats the request pair for metrics and logs, e.g. "es-fr".
+func languagePair(req Request) string {
+	return req.SourceLang + "-" + req.TargetLang
+}
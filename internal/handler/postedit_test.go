@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/postedit"
+	"github.com/pricofy/translation-manager/internal/variant"
+)
+
+func TestApplyPostEdits(t *testing.T) {
+	engine, err := postedit.New([]postedit.Rule{
+		{Name: "wallet", Source: "es", Target: "fr", Pattern: `portfolio`, Replacement: "portefeuille"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+	translations := []string{"portfolio noir", "sac"}
+
+	hits := applyPostEdits(engine, Request{SourceLang: "es", TargetLang: "fr"}, translations, rec)
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if translations[0] != "portefeuille noir" || translations[1] != "sac" {
+		t.Errorf("translations = %q", translations)
+	}
+	if hits["wallet"] != 1 {
+		t.Errorf("hits = %v, want wallet=1", hits)
+	}
+	if out := buf.String(); !strings.Contains(out, `"PostEditHits":1`) || !strings.Contains(out, `"LanguagePair":"es-fr"`) {
+		t.Errorf("metrics output missing PostEditHits: %s", out)
+	}
+}
+
+func TestApplyVariants(t *testing.T) {
+	s, err := variant.New(variant.Dictionaries{"es_MX": {"coche": "carro"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+	translations := []string{"Coche rojo", "moto"}
+
+	n := applyVariants(s, Request{SourceLang: "en", TargetLang: "es_MX"}, translations, rec)
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if translations[0] != "Carro rojo" || translations[1] != "moto" || n != 1 {
+		t.Errorf("translations = %q, substitutions = %d", translations, n)
+	}
+	if out := buf.String(); !strings.Contains(out, `"VariantSubstitutions":1`) || !strings.Contains(out, `"LanguagePair":"en-es_MX"`) {
+		t.Errorf("metrics output missing VariantSubstitutions: %s", out)
+	}
+	if n := applyVariants(s, Request{SourceLang: "en", TargetLang: "es"}, []string{"coche"}, rec); n != 0 {
+		t.Errorf("substitutions for the base language = %d, want 0", n)
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/pricofy/translation-manager/internal/profile"
+)
+
+// profileDefaults are the request options a tenant profile may set. Options
+// sent with the request win over the profile.
+type profileDefaults struct {
+	StrictLanguages     bool     `json:"strictLanguages"`
+	InvertedPairAction  string   `json:"invertedPairAction"`
+	IncludeConfidence   bool     `json:"includeConfidence"`
+	MinConfidence       *float64 `json:"minConfidence"`
+	LowConfidenceAction string   `json:"lowConfidenceAction"`
+	LongTokenPolicy     string   `json:"longTokenPolicy"`
+	Placeholders        string   `json:"placeholders"`
+	ChunkStrategy       string   `json:"chunkStrategy"`
+	Dictionary          string   `json:"dictionary"`
+	Passthrough         string   `json:"passthrough"`
+	MeasurementPolicy   string   `json:"measurementPolicy"`
+	MeasurementSystem   string   `json:"measurementSystem"`
+	Markup              string   `json:"markup"`
+	ContentType         string   `json:"contentType"`
+	TitleCasing         string   `json:"titleCasing"`
+	ErrorLocale         string   `json:"errorLocale"`
+	Slugs               bool     `json:"slugs"`
+	SlugMaxLength       int      `json:"slugMaxLength"`
+	Fields              []string `json:"fields"`
+	HTMLAttributes      []string `json:"htmlAttributes"`
+	HTMLMeta            []string `json:"htmlMeta"`
+}
+
+// loadProfiles opens the profile store, nil when TENANT_PROFILES_TABLE is
+// not set.
+func loadProfiles(cfg aws.Config) *profile.Store {
+	table := os.Getenv(profile.TableEnv)
+	if table == "" {
+		return nil
+	}
+	return profile.New(dynamodb.NewFromConfig(cfg), table)
+}
+
+// applyProfile fills the options req leaves unset from its tenant's profile.
+func (h *Handler) applyProfile(ctx context.Context, req *Request) error {
+	if req.TenantID == "" || h.profiles == nil {
+		return nil
+	}
+	raw, err := h.profiles.Defaults(ctx, req.TenantID)
+	if err != nil || raw == nil {
+		return err
+	}
+	var defaults profileDefaults
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&defaults); err != nil {
+		return fmt.Errorf("invalid profile of tenant %s: %w", req.TenantID, err)
+	}
+
+	mergeDefaults(req, defaults)
+	return nil
+}
+
+// mergeDefaults copies the profile options that req leaves unset. Boolean
+// options can only be turned on by a profile.
+func mergeDefaults(req *Request, d profileDefaults) {
+	req.StrictLanguages = req.StrictLanguages || d.StrictLanguages
+	req.IncludeConfidence = req.IncludeConfidence || d.IncludeConfidence
+	req.Slugs = req.Slugs || d.Slugs
+	if req.SlugMaxLength == 0 {
+		req.SlugMaxLength = d.SlugMaxLength
+	}
+	if req.MinConfidence == nil {
+		req.MinConfidence = d.MinConfidence
+	}
+	if req.Fields == nil {
+		req.Fields = d.Fields
+	}
+	if req.HTMLAttributes == nil {
+		req.HTMLAttributes = d.HTMLAttributes
+	}
+	if req.HTMLMeta == nil {
+		req.HTMLMeta = d.HTMLMeta
+	}
+	defaultString(&req.InvertedPairAction, d.InvertedPairAction)
+	defaultString(&req.LowConfidenceAction, d.LowConfidenceAction)
+	defaultString(&req.LongTokenPolicy, d.LongTokenPolicy)
+	defaultString(&req.Placeholders, d.Placeholders)
+	defaultString(&req.ChunkStrategy, d.ChunkStrategy)
+	defaultString(&req.Dictionary, d.Dictionary)
+	defaultString(&req.Passthrough, d.Passthrough)
+	defaultString(&req.MeasurementPolicy, d.MeasurementPolicy)
+	defaultString(&req.MeasurementSystem, d.MeasurementSystem)
+	defaultString(&req.Markup, d.Markup)
+	defaultString(&req.ContentType, d.ContentType)
+	defaultString(&req.TitleCasing, d.TitleCasing)
+	defaultString(&req.ErrorLocale, d.ErrorLocale)
+}
+
+// defaultString sets *field to value when it is empty.
+func defaultString(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
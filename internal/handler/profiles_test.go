@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/profile"
+)
+
+// profileTable serves one stored defaults document for every tenant but "none".
+type profileTable string
+
+func (p profileTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if in.Key["tenantId"].(*types.AttributeValueMemberS).Value == "none" {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"defaults": &types.AttributeValueMemberS{Value: string(p)},
+	}}, nil
+}
+
+func TestMergeDefaults(t *testing.T) {
+	half := 0.5
+	defaults := profileDefaults{
+		StrictLanguages: true,
+		MinConfidence:   &half,
+		ChunkStrategy:   ChunkBalanced,
+		ErrorLocale:     "es",
+		Fields:          []string{FieldTranslations},
+		HTMLAttributes:  []string{"alt"},
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		want Request
+	}{
+		{
+			name: "fills unset options",
+			req:  Request{},
+			want: Request{StrictLanguages: true, MinConfidence: &half, ChunkStrategy: ChunkBalanced, ErrorLocale: "es", Fields: []string{FieldTranslations}, HTMLAttributes: []string{"alt"}},
+		},
+		{
+			name: "request options win",
+			req:  Request{ChunkStrategy: ChunkSequential, ErrorLocale: "fr", Fields: []string{FieldDebug}, HTMLAttributes: []string{}},
+			want: Request{StrictLanguages: true, MinConfidence: &half, ChunkStrategy: ChunkSequential, ErrorLocale: "fr", Fields: []string{FieldDebug}, HTMLAttributes: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			mergeDefaults(&req, defaults)
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("mergeDefaults() = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		tenant   string
+		defaults string
+		want     string
+		wantErr  bool
+	}{
+		{"profile applied", "acme", `{"longTokenPolicy": "truncate"}`, LongTokenTruncate, false},
+		{"no tenant", "", `{"longTokenPolicy": "truncate"}`, "", false},
+		{"no profile", "none", `{"longTokenPolicy": "truncate"}`, "", false},
+		{"unknown option", "acme", `{"texts": ["injected"]}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{profiles: profile.New(profileTable(tt.defaults), "profiles")}
+			req := Request{TenantID: tt.tenant}
+			err := h.applyProfile(context.Background(), &req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if req.LongTokenPolicy != tt.want {
+				t.Errorf("LongTokenPolicy = %q, want %q", req.LongTokenPolicy, tt.want)
+			}
+		})
+	}
+}
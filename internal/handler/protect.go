@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"sort"
+
+	"github.com/pricofy/translation-manager/internal/markup"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// protectedSpan is a span of a text hidden from the models.
+type protectedSpan struct {
+	protect.Span
+	// render rewrites the original span for the translation; nil keeps it.
+	render func(string) string
+}
+
+// protectTexts masks the spans of every text that must not reach the models
+// (the inline tags of HTML units, personal data when masked, placeholders
+// when protected, long tokens, markup tags of grammar when set, and
+// measurements under a measurement policy), so they are neither translated
+// nor counted for chunking. Earlier kinds win where spans overlap. It
+// returns the rendered originals per text (nil for untouched texts) and a
+// warning per text with long tokens. The caller's texts are not modified.
+func protectTexts(req *Request, grammar *markup.Compiled) ([][]string, []Warning) {
+	var masks [][]string
+	var warnings []Warning
+	measurements := measurementRenderer(req)
+	for i, text := range req.Texts {
+		tags := htmlTagSpans(req, text)
+		spans := append(tags, piiSpans(req, text, tags)...)
+		spans = append(spans, placeholderSpans(req, text, spans)...)
+		long := withoutOverlaps(longTokenSpans(req, text), spans)
+		spans = append(spans, long...)
+		spans = append(spans, markupSpans(grammar, text, spans)...)
+		spans = append(spans, measurementSpans(req, text, spans, measurements)...)
+		if len(spans) == 0 {
+			continue
+		}
+		spans = append(spans, literalSpans(text, spans)...)
+		if masks == nil {
+			masks = make([][]string, len(req.Texts))
+			req.Texts = append([]string(nil), req.Texts...)
+		}
+
+		req.Texts[i], masks[i] = maskSpans(text, spans)
+		if len(long) > 0 {
+			warnings = append(warnings, longTokenWarning(req, i, len(long)))
+		}
+	}
+	return masks, warnings
+}
+
+// maskSpans masks spans of text and renders the originals.
+func maskSpans(text string, spans []protectedSpan) (string, []string) {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	plain := make([]protect.Span, len(spans))
+	for i, s := range spans {
+		plain[i] = s.Span
+	}
+	masked, originals := protect.Mask(text, plain)
+	for i, s := range spans {
+		if s.render != nil {
+			originals[i] = s.render(originals[i])
+		}
+	}
+	return masked, originals
+}
+
+// literalSpans returns the placeholder-shaped substrings of text, such as a
+// literal "__3__", skipping any inside the spans already taken. They are
+// masked with the other spans so restoring leaves them as they were.
+func literalSpans(text string, taken []protectedSpan) []protectedSpan {
+	var spans []protectedSpan
+	for _, s := range protect.Literals(text) {
+		if !overlaps(s, taken) {
+			spans = append(spans, protectedSpan{Span: s})
+		}
+	}
+	return spans
+}
+
+// restoreTexts puts the protected spans back into the translations.
+func restoreTexts(translations []string, masks [][]string) {
+	for i, originals := range masks {
+		if originals != nil {
+			translations[i], _ = protect.Restore(translations[i], originals)
+		}
+	}
+}
+
+// withoutOverlaps drops the spans overlapping any of taken.
+func withoutOverlaps(spans, taken []protectedSpan) []protectedSpan {
+	if len(taken) == 0 {
+		return spans
+	}
+	var kept []protectedSpan
+	for _, s := range spans {
+		if !overlaps(s.Span, taken) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// overlaps reports whether s overlaps any of spans.
+func overlaps(s protect.Span, spans []protectedSpan) bool {
+	for _, o := range spans {
+		if s.Start < o.End && o.Start < s.End {
+			return true
+		}
+	}
+	return false
+}
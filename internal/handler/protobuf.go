@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/protoapi"
+)
+
+// ParseProtobufRequest parses a TranslateRequest (api/translation.proto)
+// the way ParseRequest parses its JSON form.
+func ParseProtobufRequest(data []byte) (Request, error) {
+	event, err := protoapi.DecodeRequest(data)
+	if err != nil {
+		return Request{}, fmt.Errorf("invalid request: %w", err)
+	}
+	return ParseRequest(event)
+}
+
+// EncodeProtobufResponse returns resp as a TranslateResponse. Fields of
+// admin actions, which are JSON only, are left out.
+func EncodeProtobufResponse(resp *Response) ([]byte, error) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return protoapi.EncodeResponse(data)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoRequest builds a TranslateRequest of texts from source to target.
+func protoRequest(source, target string, texts ...string) []byte {
+	var b []byte
+	for _, text := range texts {
+		b = protowire.AppendString(protowire.AppendTag(b, 1, protowire.BytesType), text)
+	}
+	b = protowire.AppendString(protowire.AppendTag(b, 2, protowire.BytesType), source)
+	return protowire.AppendString(protowire.AppendTag(b, 3, protowire.BytesType), target)
+}
+
+func TestParseProtobufRequest(t *testing.T) {
+	req, err := ParseProtobufRequest(protoRequest("es", "fr", "Buen estado", "Nuevo"))
+	if err != nil {
+		t.Fatalf("ParseProtobufRequest() error = %v", err)
+	}
+	if req.SourceLang != "es" || req.TargetLang != "fr" || len(req.Texts) != 2 || req.Texts[1] != "Nuevo" {
+		t.Errorf("ParseProtobufRequest() = %+v", req)
+	}
+
+	// The JSON schema still applies
+	chunk := protowire.AppendString(protowire.AppendTag(nil, 31, protowire.BytesType), "diagonal")
+	if _, err := ParseProtobufRequest(append(protoRequest("es", "fr", "Nuevo"), chunk...)); err == nil {
+		t.Error("ParseProtobufRequest() with an unknown chunkStrategy: error = nil")
+	}
+	if _, err := ParseProtobufRequest([]byte{0xff}); err == nil {
+		t.Error("ParseProtobufRequest() of garbage: error = nil")
+	}
+}
+
+func TestEncodeProtobufResponse(t *testing.T) {
+	data, err := EncodeProtobufResponse(NewErrorResponse(ErrorUnsupportedPair, "no route"))
+	if err != nil {
+		t.Fatalf("EncodeProtobufResponse() error = %v", err)
+	}
+	// error (16) holds the code (1)
+	for len(data) > 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		data = data[n:]
+		if number == 16 {
+			body, _ := protowire.ConsumeBytes(data)
+			_, _, n = protowire.ConsumeTag(body)
+			if code, _ := protowire.ConsumeString(body[n:]); code != ErrorUnsupportedPair {
+				t.Errorf("error code = %q, want %q", code, ErrorUnsupportedPair)
+			}
+			return
+		}
+		data = data[protowire.ConsumeFieldValue(number, typ, data):]
+	}
+	t.Error("EncodeProtobufResponse() has no error field")
+}
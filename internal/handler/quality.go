@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Qualities accepted in Request.Quality.
+const (
+	// QualityStandard translates with the configured route (the default).
+	QualityStandard = "standard"
+	// QualityPremium translates with the premium backend, DeepL unless
+	// TRANSLATION_BACKENDS names another, and falls back to the translator
+	// Lambdas when it fails.
+	QualityPremium = "premium"
+)
+
+// validateQuality checks Request.Quality and Request.Formality.
+func validateQuality(req Request) error {
+	switch req.Quality {
+	case "", QualityStandard, QualityPremium:
+	default:
+		return fmt.Errorf("unknown quality %q (expected %s or %s)", req.Quality, QualityStandard, QualityPremium)
+	}
+	switch req.Formality {
+	case "", router.FormalityFormal, router.FormalityInformal:
+		return nil
+	default:
+		return fmt.Errorf("unknown formality %q (expected %s or %s)", req.Formality, router.FormalityFormal, router.FormalityInformal)
+	}
+}
+
+// styled reports whether req asks for translations other than the standard
+// ones of its pair, which the translation cache neither serves nor keeps.
+func styled(req Request) bool {
+	return req.Quality == QualityPremium || req.Formality != ""
+}
+
+// premiumFallback returns a PREMIUM_FALLBACK warning when the premium
+// backend failed and the translator Lambdas translated instead.
+func premiumFallback(result *router.Result) []Warning {
+	if result.Fallback == nil {
+		return nil
+	}
+	return []Warning{{
+		Code:    WarningPremiumFallback,
+		Message: fmt.Sprintf("premium backend %s failed, translated by the translator Lambdas: %v", result.Fallback.Backend, result.Fallback.Err),
+	}}
+}
+
+// recordBackendUsage meters the characters sent to an external backend
+// such as DeepL, which bills by character, per backend and, with a
+// callerId, per caller.
+func recordBackendUsage(rec *metrics.Recorder, req Request, chunks [][]string, result *router.Result) {
+	backend := routeKind(result)
+	if backend == routeDirect || backend == routePivot || backend == router.BackendDryRun {
+		return
+	}
+	chars := 0
+	for _, chunk := range chunks {
+		for _, text := range chunk {
+			chars += utf8.RuneCountInString(text)
+		}
+	}
+	rec.Add("BackendCharacters", metrics.Count, float64(chars), metrics.Dimensions{"Backend": backend})
+	if req.CallerID != "" {
+		rec.Add("BackendCharacters", metrics.Count, float64(chars), metrics.Dimensions{"Backend": backend, "Caller": req.CallerID})
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// premiumTranslator is a stubTranslator recording the options it got and
+// reporting a failed premium backend.
+type premiumTranslator struct {
+	stubTranslator
+	opts router.Options
+}
+
+func (p *premiumTranslator) TranslateChunksWithOptions(ctx context.Context, source, target string, chunks [][]string, opts router.Options) (*router.Result, error) {
+	p.opts = opts
+	result, _ := p.stubTranslator.TranslateChunksWithOptions(ctx, source, target, chunks, opts)
+	if opts.Premium {
+		result.Fallback = &router.BackendFallback{Backend: router.BackendDeepL, Err: errors.New("quota exceeded")}
+	}
+	return result, nil
+}
+
+func TestHandle_Quality(t *testing.T) {
+	tr := &premiumTranslator{}
+	req := Request{Texts: []string{"Hola"}, SourceLang: "es", TargetLang: "de", Quality: QualityPremium, Formality: router.FormalityFormal}
+
+	resp, err := NewHandler(tr).Handle(context.Background(), req)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Handle() = %+v, %v", resp.Error, err)
+	}
+	if !tr.opts.Premium || tr.opts.Formality != router.FormalityFormal {
+		t.Errorf("options = %+v, want premium and formal", tr.opts)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Code != WarningPremiumFallback {
+		t.Errorf("warnings = %+v, want one %s warning", resp.Warnings, WarningPremiumFallback)
+	}
+}
+
+func TestValidateQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{name: "default", req: Request{}},
+		{name: "premium formal", req: Request{Quality: QualityPremium, Formality: router.FormalityFormal}},
+		{name: "standard informal", req: Request{Quality: QualityStandard, Formality: router.FormalityInformal}},
+		{name: "unknown quality", req: Request{Quality: "best"}, wantErr: true},
+		{name: "unknown formality", req: Request{Formality: "polite"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateQuality(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateQuality() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecordBackendUsage(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    Request
+		result *router.Result
+		want   []string
+	}{
+		{"deepl", Request{}, &router.Result{Steps: []string{router.BackendDeepL}}, []string{`"BackendCharacters":11`, `"Backend":"deepl"`}},
+		{"deepl caller", Request{CallerID: "search"}, &router.Result{Steps: []string{router.BackendDeepL}}, []string{`"Caller":"search"`}},
+		{"lambda", Request{}, &router.Result{Steps: []string{"romance-en"}}, nil},
+		{"dry run", Request{}, &router.Result{Steps: []string{router.BackendDryRun}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rec := metrics.New(&buf)
+			recordBackendUsage(rec, tt.req, [][]string{{"Buen estado"}}, tt.result)
+			if err := rec.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil && strings.Contains(buf.String(), "BackendCharacters") {
+				t.Errorf("backend metrics recorded: %s", buf.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("metrics = %s, want %s", buf.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/metrics"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Chunk strategies accepted in Request.ChunkStrategy.
+const (
+	// ChunkSequential splits texts in input order (the default).
+	ChunkSequential = "sequential"
+	// ChunkBalanced bins texts by size into chunks of even token load.
+	ChunkBalanced = "balanced"
+	// ChunkHTML treats texts as HTML: only their text nodes and readable
+	// attributes are translated, in input order, and the markup is kept.
+	ChunkHTML = "html"
+)
+
+// scheduleChunks splits the request texts with its chunk strategy, within
+// its ChunkMaxTokens budget. order is nil for sequential chunks, otherwise
+// it maps chunk results back to the original positions (see unchunk).
+func scheduleChunks(req Request, maxTexts int) (chunks [][]string, order [][]int) {
+	if req.ChunkStrategy == ChunkBalanced {
+		return chunker.ChunkBalanced(req.Texts, req.SourceLang, maxTexts, req.ChunkMaxTokens)
+	}
+	if req.ChunkMaxTokens > 0 {
+		return chunker.ChunkByTokens(req.Texts, req.SourceLang, maxTexts, req.ChunkMaxTokens), nil
+	}
+	return chunker.ChunkTexts(req.Texts, maxTexts), nil
+}
+
+// chunkTokens returns the chunk token budget of req: its own, else the one
+// configured for the functions of its route, else 0 for the default.
+func chunkTokens(req Request, plan *router.RoutePlan) int {
+	if req.ChunkMaxTokens > 0 || plan == nil {
+		return req.ChunkMaxTokens
+	}
+	return plan.MaxTokens
+}
+
+// splitTexts breaks the request texts over the per-text token budget into
+// pieces of whole sentences. Cache-only requests never reach a translator,
+// so their texts are left whole.
+func splitTexts(req Request) *chunker.Split {
+	if cacheOnly(req) {
+		return &chunker.Split{Texts: req.Texts}
+	}
+	return chunker.SplitOversized(req.Texts, req.SourceLang, chunker.DefaultMaxTokensPerText)
+}
+
+// stitchPieces joins the translations and scores of split texts back into
+// one per request text, as a result of a single chunk in text order.
+func stitchPieces(split *chunker.Split, lang string, result *router.Result, order [][]int) (*router.Result, [][]int) {
+	if !split.Oversized() {
+		return result, order
+	}
+	stitched := *result
+	stitched.Translations = [][]string{split.Stitch(unchunk(result.Translations, order), lang)}
+	if result.Scores != nil {
+		stitched.Scores = [][]float64{split.StitchScores(unchunk(result.Scores, order))}
+	}
+	return &stitched, nil
+}
+
+// unchunk flattens per-chunk results into the original text order.
+func unchunk[T any](results [][]T, order [][]int) []T {
+	if order == nil {
+		return flatten(results)
+	}
+	return chunker.Restore(results, order)
+}
+
+// validateChunkStrategy checks Request.ChunkStrategy.
+func validateChunkStrategy(strategy string) error {
+	switch strategy {
+	case "", ChunkSequential, ChunkBalanced, ChunkHTML:
+		return nil
+	default:
+		return fmt.Errorf("unknown chunkStrategy %q", strategy)
+	}
+}
+
+// validateChunkMaxTokens checks Request.ChunkMaxTokens.
+func validateChunkMaxTokens(req Request) error {
+	if req.ChunkMaxTokens < 0 {
+		return fmt.Errorf("chunkMaxTokens must not be negative")
+	}
+	return nil
+}
+
+// recordSteps records a StepDuration metric per route step, by function and
+// dispatch strategy, to compare single invocations with fan-outs.
+func recordSteps(rec *metrics.Recorder, result *router.Result) {
+	for i, function := range result.Steps {
+		if i >= len(result.Dispatches) || i >= len(result.Durations) {
+			return
+		}
+		rec.Add("StepDuration", metrics.Milliseconds, float64(result.Durations[i].Milliseconds()), metrics.Dimensions{
+			"Function": function,
+			"Dispatch": result.Dispatches[i],
+		})
+	}
+}
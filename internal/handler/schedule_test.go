@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestScheduleChunks(t *testing.T) {
+	long := strings.Repeat("texto largo ", 500)
+	texts := append([]string{long, long}, make([]string, 60)...)
+
+	tests := []struct {
+		strategy  string
+		wantOrder bool
+	}{
+		{"", false},
+		{ChunkSequential, false},
+		{ChunkBalanced, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			chunks, order := scheduleChunks(Request{Texts: texts, ChunkStrategy: tt.strategy}, 50)
+			if (order != nil) != tt.wantOrder {
+				t.Fatalf("scheduleChunks() order = %v, want order %v", order, tt.wantOrder)
+			}
+
+			// Echo translations must come back in input order
+			restored := unchunk(chunks, order)
+			for i := range texts {
+				if restored[i] != texts[i] {
+					t.Fatalf("unchunk() position %d out of order", i)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleChunks_TokenBudget(t *testing.T) {
+	long := strings.Repeat("texto largo ", 200)
+	texts := []string{long, long, long, "corto"}
+
+	for _, strategy := range []string{ChunkSequential, ChunkBalanced} {
+		t.Run(strategy, func(t *testing.T) {
+			req := Request{Texts: texts, SourceLang: "es", ChunkStrategy: strategy}
+			if chunks, _ := scheduleChunks(req, 50); len(chunks) != 1 {
+				t.Errorf("scheduleChunks() = %d chunks, want 1 within the default budget", len(chunks))
+			}
+			req.ChunkMaxTokens = 1000
+			if chunks, _ := scheduleChunks(req, 50); len(chunks) < 3 {
+				t.Errorf("scheduleChunks() with chunkMaxTokens = %d chunks, want a long text per chunk", len(chunks))
+			}
+		})
+	}
+}
+
+func TestChunkTokens(t *testing.T) {
+	plan := &router.RoutePlan{MaxTokens: 8000}
+
+	tests := []struct {
+		name string
+		req  Request
+		plan *router.RoutePlan
+		want int
+	}{
+		{"request", Request{ChunkMaxTokens: 2000}, plan, 2000},
+		{"route", Request{}, plan, 8000},
+		{"unplanned", Request{}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkTokens(tt.req, tt.plan); got != tt.want {
+				t.Errorf("chunkTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStitchPieces(t *testing.T) {
+	sentence := strings.Repeat("texto largo ", 90) + "fin."
+	long := sentence + " " + sentence
+	req := Request{Texts: []string{"corto", long, "otro"}, SourceLang: "es", ChunkStrategy: ChunkBalanced}
+
+	split := splitTexts(req)
+	if len(split.Texts) != 4 {
+		t.Fatalf("splitTexts() = %d texts, want the long text in 2 pieces", len(split.Texts))
+	}
+	pieces := req
+	pieces.Texts = split.Texts
+	chunks, order := scheduleChunks(pieces, 2)
+
+	scores := make([][]float64, len(chunks))
+	for i, chunk := range chunks {
+		scores[i] = make([]float64, len(chunk))
+		for j := range chunk {
+			scores[i][j] = -1
+		}
+	}
+	result, order := stitchPieces(split, "es", &router.Result{Translations: chunks, Scores: scores, Steps: []string{"es-en"}}, order)
+
+	if got := unchunk(result.Translations, order); !reflect.DeepEqual(got, req.Texts) {
+		t.Errorf("stitched translations = %q, want %q", got, req.Texts)
+	}
+	if got := unchunk(result.Scores, order); !reflect.DeepEqual(got, []float64{-1, -1, -1}) {
+		t.Errorf("stitched scores = %v, want one per text", got)
+	}
+	if !reflect.DeepEqual(result.Steps, []string{"es-en"}) {
+		t.Errorf("steps = %v, want them kept", result.Steps)
+	}
+}
+
+func TestSplitTexts_CacheOnly(t *testing.T) {
+	long := strings.Repeat("texto largo. ", 300)
+	if split := splitTexts(Request{Texts: []string{long}, CacheOnly: true}); split.Oversized() {
+		t.Error("splitTexts() split the texts of a cache-only request")
+	}
+}
+
+func TestValidateChunkStrategy(t *testing.T) {
+	for _, s := range []string{"", ChunkSequential, ChunkBalanced} {
+		if err := validateChunkStrategy(s); err != nil {
+			t.Errorf("validateChunkStrategy(%q) = %v", s, err)
+		}
+	}
+	if err := validateChunkStrategy("random"); err == nil {
+		t.Error("validateChunkStrategy(random) should fail")
+	}
+}
+
+func TestValidateChunkMaxTokens(t *testing.T) {
+	if err := validateChunkMaxTokens(Request{ChunkMaxTokens: 8000}); err != nil {
+		t.Errorf("validateChunkMaxTokens(8000) = %v", err)
+	}
+	if err := validateChunkMaxTokens(Request{ChunkMaxTokens: -1}); err == nil {
+		t.Error("validateChunkMaxTokens(-1) should fail")
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/domain"
+	"github.com/pricofy/translation-manager/internal/measure"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+	"github.com/pricofy/translation-manager/internal/schema"
+	"github.com/pricofy/translation-manager/internal/slug"
+)
+
+// schemaVersionSchema accepts the supported request contract versions; every
+// action takes it.
+var schemaVersionSchema = &schema.Schema{Type: schema.String, Enum: domain.SchemaVersions}
+
+// requestSchema describes the accepted shape of a translation request.
+var requestSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"sourceLang", "targetLang"},
+	Properties: map[string]*schema.Schema{
+		"texts": {
+			Type:  schema.Array,
+			Items: &schema.Schema{Type: schema.String},
+			Hint:  `wrap a single text in an array: ["..."]`,
+		},
+		"schemaVersion":   schemaVersionSchema,
+		"action":          {Type: schema.String, Enum: []string{ActionTranslate, ActionValidate, ActionKeywords, ActionStatus, ActionLanguages, ActionRoutes, ActionCacheStats, ActionVersion, ActionUsage}},
+		"async":           {Type: schema.Boolean},
+		"jobId":           {Type: schema.String},
+		"mode":            {Type: schema.String, Enum: []string{ModeBatch, ModeStatus, ModeRedrive}},
+		"inputUri":        {Type: schema.String},
+		"outputPrefix":    {Type: schema.String},
+		"idempotencyKey":  {Type: schema.String},
+		"tenantId":        {Type: schema.String},
+		"callerId":        {Type: schema.String},
+		"text":            {Type: schema.String},
+		"po":              {Type: schema.String},
+		"sourceLang":      {Type: schema.String},
+		"targetLang":      {Type: schema.String},
+		"strictLanguages": {Type: schema.Boolean},
+		"invertedPairAction": {
+			Type: schema.String,
+			Enum: []string{InvertedPairWarn, InvertedPairCorrect},
+		},
+		"includeConfidence":   {Type: schema.Boolean},
+		"includeIntermediate": {Type: schema.Boolean},
+		"minConfidence":       {Type: schema.Number, Minimum: schema.Float(0), Maximum: schema.Float(1)},
+		"lowConfidenceAction": {
+			Type: schema.String,
+			Enum: []string{LowConfidenceFlag, LowConfidenceWithhold},
+		},
+		"errorLocale":     {Type: schema.String},
+		"slugs":           {Type: schema.Boolean},
+		"slugMaxLength":   {Type: schema.Integer, Minimum: schema.Float(1), Maximum: schema.Float(slug.MaxLength)},
+		"truncatedTo":     {Type: schema.Integer, Minimum: schema.Float(1)},
+		"maxCost":         {Type: schema.Number, Minimum: schema.Float(0)},
+		"timeoutMs":       {Type: schema.Integer, Minimum: schema.Float(0)},
+		"chunkMaxTokens":  {Type: schema.Integer, Minimum: schema.Float(0)},
+		"backend":         {Type: schema.String, Enum: []string{router.BackendLambda, router.BackendAWSTranslate, router.BackendDeepL, router.BackendBedrock, router.BackendDryRun}},
+		"dryRun":          {Type: schema.Boolean},
+		"quality":         {Type: schema.String, Enum: []string{QualityStandard, QualityPremium}},
+		"formality":       {Type: schema.String, Enum: []string{router.FormalityFormal, router.FormalityInformal}},
+		"maskPii":         {Type: schema.Boolean},
+		"longTokenPolicy": {Type: schema.String, Enum: []string{LongTokenPassthrough, LongTokenTruncate}},
+		"chunkStrategy":   {Type: schema.String, Enum: []string{ChunkSequential, ChunkBalanced, ChunkHTML}},
+		"dictionary":      {Type: schema.String, Enum: []string{DictionaryOn, DictionaryOff}},
+		"passthrough":     {Type: schema.String, Enum: []string{PassthroughOn, PassthroughOff}},
+		"placeholders":    {Type: schema.String, Enum: []string{PlaceholdersOn, PlaceholdersOff}},
+		"cacheOnly":       {Type: schema.Boolean},
+		"measurementPolicy": {
+			Type: schema.String,
+			Enum: []string{MeasurementPreserve, MeasurementLocalize, MeasurementConvert},
+		},
+		"markup":      {Type: schema.String},
+		"contentType": {Type: schema.String, Enum: []string{ContentTitle, ContentDescription, ContentBullet}},
+		"contentTypes": {
+			Type:  schema.Array,
+			Items: &schema.Schema{Type: schema.String, Enum: []string{ContentTitle, ContentDescription, ContentBullet, ""}},
+		},
+		"tags":              {Type: schema.Array, Items: &schema.Schema{Type: schema.String}},
+		"hashes":            {Type: schema.Array, Items: &schema.Schema{Type: schema.String}},
+		"htmlAttributes":    {Type: schema.Array, Items: &schema.Schema{Type: schema.String}},
+		"htmlMeta":          {Type: schema.Array, Items: &schema.Schema{Type: schema.String}},
+		"titleCasing":       {Type: schema.String, Enum: []string{CasingTitle, CasingSentence, CasingPreserve}},
+		"measurementSystem": {Type: schema.String, Enum: []string{string(measure.Metric), string(measure.Imperial)}},
+		"fields": {
+			Type:  schema.Array,
+			Items: &schema.Schema{Type: schema.String, Enum: []string{FieldTranslations, FieldPivot, FieldDebug, FieldQuality, FieldLocale}},
+			Hint:  `list field groups in an array: ["translations", "quality"]`,
+		},
+	},
+}
+
+// statusSchema describes a job status lookup, with the status action or
+// mode, which needs no languages.
+var statusSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"jobId"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionStatus}},
+		"mode":          {Type: schema.String, Enum: []string{ModeStatus}},
+		"schemaVersion": schemaVersionSchema,
+		"jobId":         {Type: schema.String},
+	},
+}
+
+// redriveSchema describes a redrive of the dead-lettered chunks of a job,
+// which takes its languages and options from the job.
+var redriveSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"mode", "jobId"},
+	Properties: map[string]*schema.Schema{
+		"mode":          {Type: schema.String, Enum: []string{ModeRedrive}},
+		"schemaVersion": schemaVersionSchema,
+		"jobId":         {Type: schema.String},
+		"errorLocale":   {Type: schema.String},
+	},
+}
+
+// languagesSchema describes a language catalog request.
+var languagesSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"action"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionLanguages}},
+		"schemaVersion": schemaVersionSchema,
+	},
+}
+
+// routesSchema describes a routing table admin request.
+var routesSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"action", "adminToken", "routeOp"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionRoutes}},
+		"schemaVersion": schemaVersionSchema,
+		"adminToken":    {Type: schema.String},
+		"routeOp":       {Type: schema.String, Enum: []string{RouteList, RouteAdd, RouteUpdate, RouteDisable, RouteDelete}},
+		"errorLocale":   {Type: schema.String},
+		"routeEntry": {
+			Type:     schema.Object,
+			Required: []string{"id"},
+			Properties: map[string]*schema.Schema{
+				"id":         {Type: schema.String},
+				"sourceLang": {Type: schema.String},
+				"targetLang": {Type: schema.String},
+				"function":   {Type: schema.String},
+				"qualifier":  {Type: schema.String},
+				"weight":     {Type: schema.Integer, Minimum: schema.Float(1), Maximum: schema.Float(routing.MaxWeight)},
+				"enabled":    {Type: schema.Boolean},
+				"canary": {
+					Type: schema.Object,
+					Properties: map[string]*schema.Schema{
+						"maxErrorRate":         {Type: schema.Number, Minimum: schema.Float(0), Maximum: schema.Float(1)},
+						"maxLowConfidenceRate": {Type: schema.Number, Minimum: schema.Float(0), Maximum: schema.Float(1)},
+						"minConfidence":        {Type: schema.Number, Minimum: schema.Float(0), Maximum: schema.Float(1)},
+						"windowSeconds":        {Type: schema.Integer, Minimum: schema.Float(0)},
+						"minRequests":          {Type: schema.Integer, Minimum: schema.Float(0)},
+					},
+				},
+			},
+		},
+	},
+}
+
+// cacheStatsSchema describes a translation cache statistics request.
+var cacheStatsSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"action", "adminToken"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionCacheStats}},
+		"schemaVersion": schemaVersionSchema,
+		"adminToken":    {Type: schema.String},
+		"errorLocale":   {Type: schema.String},
+	},
+}
+
+// versionSchema describes a deployment version request.
+var versionSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"action", "adminToken"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionVersion}},
+		"schemaVersion": schemaVersionSchema,
+		"adminToken":    {Type: schema.String},
+		"errorLocale":   {Type: schema.String},
+	},
+}
+
+// usageSchema describes a usage request.
+var usageSchema = &schema.Schema{
+	Type:     schema.Object,
+	Required: []string{"action", "adminToken"},
+	Properties: map[string]*schema.Schema{
+		"action":        {Type: schema.String, Enum: []string{ActionUsage}},
+		"schemaVersion": schemaVersionSchema,
+		"adminToken":    {Type: schema.String},
+		"callerId":      {Type: schema.String},
+		"usageFrom":     {Type: schema.String},
+		"usageTo":       {Type: schema.String},
+		"errorLocale":   {Type: schema.String},
+	},
+}
+
+// actionSchemas holds the schemas of actions that are not translations.
+var actionSchemas = map[string]*schema.Schema{
+	ActionStatus:     statusSchema,
+	ActionLanguages:  languagesSchema,
+	ActionRoutes:     routesSchema,
+	ActionCacheStats: cacheStatsSchema,
+	ActionVersion:    versionSchema,
+	ActionUsage:      usageSchema,
+}
+
+// PropertySchemas returns the schema of every top-level request property
+// across actions, e.g. for generating API documentation.
+func PropertySchemas() map[string]*schema.Schema {
+	props := map[string]*schema.Schema{}
+	for _, s := range []*schema.Schema{requestSchema, statusSchema, redriveSchema, languagesSchema, routesSchema, cacheStatsSchema, versionSchema, usageSchema} {
+		for name, p := range s.Properties {
+			if _, ok := props[name]; !ok {
+				props[name] = p
+			}
+		}
+	}
+	return props
+}
+
+// schemaFor picks the schema matching the event's action.
+func schemaFor(event json.RawMessage) *schema.Schema {
+	var probe struct {
+		Action string `json:"action"`
+		Mode   string `json:"mode"`
+	}
+	if json.Unmarshal(event, &probe) == nil {
+		if probe.Mode == ModeStatus && probe.Action == "" {
+			return statusSchema
+		}
+		if probe.Mode == ModeRedrive && probe.Action == "" {
+			return redriveSchema
+		}
+		if s, ok := actionSchemas[probe.Action]; ok {
+			return s
+		}
+	}
+	return requestSchema
+}
+
+// ParseRequest validates a raw event against the request schema and decodes it.
+// Validation errors name the exact field and problem, e.g. "texts[3]: must be a string".
+// Deprecated field names are mapped to their replacements first (see compat.go).
+func ParseRequest(event json.RawMessage) (Request, error) {
+	var req Request
+	event, deprecations, err := upgradeRequest(event)
+	if err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+	if err := schema.Validate(event, schemaFor(event)); err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+	if err := json.Unmarshal(event, &req); err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Deprecations = deprecations
+	return req, nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		wantErr string
+	}{
+		{
+			name:  "valid request",
+			event: `{"texts": ["Hola"], "sourceLang": "es", "targetLang": "fr", "fields": ["pivot"]}`,
+		},
+		{
+			name:  "current schema version",
+			event: `{"schemaVersion": "v1", "texts": ["Hola"], "sourceLang": "es", "targetLang": "fr"}`,
+		},
+		{
+			name:    "unknown schema version",
+			event:   `{"schemaVersion": "v9", "texts": ["Hola"], "sourceLang": "es", "targetLang": "fr"}`,
+			wantErr: "schemaVersion: must be one of",
+		},
+		{
+			name:    "unknown schema version on another action",
+			event:   `{"action": "languages", "schemaVersion": "2"}`,
+			wantErr: "schemaVersion: must be one of",
+		},
+		{
+			name:    "single string instead of array",
+			event:   `{"texts": "Hola", "sourceLang": "es", "targetLang": "fr"}`,
+			wantErr: "texts: must be an array of strings, got a string",
+		},
+		{
+			name:    "non-string element",
+			event:   `{"texts": ["a", "b", "c", 42], "sourceLang": "es", "targetLang": "fr"}`,
+			wantErr: "texts[3]: must be a string",
+		},
+		{
+			name:    "missing targetLang",
+			event:   `{"texts": [], "sourceLang": "es"}`,
+			wantErr: "targetLang: is required",
+		},
+		{
+			name:    "snake case field",
+			event:   `{"texts": [], "source_lang": "es", "sourceLang": "es", "targetLang": "fr"}`,
+			wantErr: `source_lang: unknown field, did you mean "sourceLang"?`,
+		},
+		{
+			name:    "minConfidence out of range",
+			event:   `{"texts": [], "sourceLang": "es", "targetLang": "fr", "minConfidence": 2}`,
+			wantErr: "minConfidence: must be <= 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRequest([]byte(tt.event))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseRequest() unexpected error: %v", err)
+				}
+				if req.SourceLang != "es" || len(req.Texts) != 1 {
+					t.Errorf("ParseRequest() decoded %+v", req)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRequest() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/slug"
+)
+
+// applySlugs adds the URL slug of every translation when the request asks
+// for them. Withheld translations get an empty slug.
+func applySlugs(resp *Response, req Request) {
+	if !req.Slugs {
+		return
+	}
+	lang := locale.Base(req.TargetLang)
+	resp.Slugs = make([]string, len(resp.Translations))
+	for i, t := range resp.Translations {
+		resp.Slugs[i] = slug.Make(t, lang, req.SlugMaxLength)
+	}
+}
+
+// validateSlugOptions checks Request.Slugs and Request.SlugMaxLength.
+func validateSlugOptions(req Request) error {
+	if req.SlugMaxLength < 0 || req.SlugMaxLength > slug.MaxLength {
+		return fmt.Errorf("slugMaxLength must be between 1 and %d", slug.MaxLength)
+	}
+	if req.Slugs && req.Text != "" {
+		return fmt.Errorf("slugs are not supported with text; send titles in texts")
+	}
+	return nil
+}
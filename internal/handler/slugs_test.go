@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplySlugs(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want []string
+	}{
+		{"not requested", Request{TargetLang: "de"}, nil},
+		{"target language transliteration", Request{TargetLang: "de", Slugs: true}, []string{"fahrraeder-fuer-kinder", ""}},
+		{"regional variant uses base language", Request{TargetLang: "de_AT", Slugs: true, SlugMaxLength: 10}, []string{"fahrraeder", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{Translations: []string{"Fahrräder für Kinder", ""}}
+			applySlugs(resp, tt.req)
+			if !reflect.DeepEqual(resp.Slugs, tt.want) {
+				t.Errorf("Slugs = %q, want %q", resp.Slugs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSlugOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"titles", Request{Texts: []string{"a"}, Slugs: true, SlugMaxLength: 60}, false},
+		{"length too long", Request{Texts: []string{"a"}, Slugs: true, SlugMaxLength: 500}, true},
+		{"document mode", Request{Text: "a. b.", Slugs: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSlugOptions(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateSlugOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
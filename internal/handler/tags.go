@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// Request.Tags marks texts such as "legal" or "medical-claims" that the
+// routing table may send to specialized translators. The texts of each tag
+// are translated apart from the rest of the batch, which keeps the default
+// route, and the results are put back in place.
+
+// validateTags checks Request.Tags.
+func validateTags(req Request) error {
+	if len(req.Tags) == 0 {
+		return nil
+	}
+	switch {
+	case req.Text != "":
+		return fmt.Errorf("tags is not supported with text")
+	case req.ChunkStrategy == ChunkHTML:
+		return fmt.Errorf("tags is not supported with chunkStrategy %q", ChunkHTML)
+	case len(req.Tags) != len(req.Texts):
+		return fmt.Errorf("tags has %d entries for %d texts", len(req.Tags), len(req.Texts))
+	}
+	for i, tag := range req.Tags {
+		if tag != "" && !routing.TagPattern.MatchString(tag) {
+			return fmt.Errorf("tags[%d]: %q is not 1-32 lowercase letters, digits or '-'", i, tag)
+		}
+	}
+	return nil
+}
+
+// tagged reports whether any text of req has a tag.
+func tagged(req Request) bool {
+	for _, tag := range req.Tags {
+		if tag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkTags returns the tag of every chunked text in the shape of chunks,
+// or nil when no text is tagged. The chunks hold the texts of split, in
+// order unless order maps them (see unchunk).
+func chunkTags(req Request, split *chunker.Split, chunks [][]string, order [][]int) [][]string {
+	if !tagged(req) {
+		return nil
+	}
+	origins := split.Origins()
+	tags := make([][]string, len(chunks))
+	next := 0
+	for c, chunk := range chunks {
+		tags[c] = make([]string, len(chunk))
+		for j := range chunk {
+			piece := next
+			if order != nil {
+				piece = order[c][j]
+			}
+			next++
+			tags[c][j] = req.Tags[origins[piece]]
+		}
+	}
+	return tags
+}
+
+// textAt is the position of a text in the request chunks.
+type textAt struct{ chunk, text int }
+
+// tagGroup holds the texts of one tag, chunked like the request.
+type tagGroup struct {
+	tag    string
+	chunks [][]string
+	at     [][]textAt
+}
+
+// groupByTag splits chunks by the tags of their texts. Each chunk adds at
+// most one chunk to every group, so no group chunk grows past its source.
+// Groups are in tag order, untagged texts first.
+func groupByTag(chunks, tags [][]string) []*tagGroup {
+	byTag := map[string]*tagGroup{}
+	var groups []*tagGroup
+	for c, chunk := range chunks {
+		opened := map[string]bool{}
+		for j, text := range chunk {
+			g := byTag[tags[c][j]]
+			if g == nil {
+				g = &tagGroup{tag: tags[c][j]}
+				byTag[g.tag] = g
+				groups = append(groups, g)
+			}
+			if !opened[g.tag] {
+				g.chunks = append(g.chunks, nil)
+				g.at = append(g.at, nil)
+				opened[g.tag] = true
+			}
+			last := len(g.chunks) - 1
+			g.chunks[last] = append(g.chunks[last], text)
+			g.at[last] = append(g.at[last], textAt{c, j})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].tag < groups[j].tag })
+	return groups
+}
+
+// translateTagged translates the texts of every tag in tags with the
+// routing table entries of that tag, in parallel, and the untagged texts
+// with the default route. tags is nil when no text is tagged.
+func translateTagged(ctx context.Context, r Translator, req Request, chunks, tags [][]string, opts router.Options) (*router.Result, error) {
+	if tags == nil {
+		return translateChunks(ctx, r, req, chunks, opts)
+	}
+	groups := groupByTag(chunks, tags)
+	results := make([]*router.Result, len(groups))
+	err := workpool.Pool{}.Run(ctx, len(groups), func(ctx context.Context, i int) error {
+		groupOpts := opts
+		groupOpts.Tag = groups[i].tag
+		var err error
+		results[i], err = translateChunks(ctx, r, req, groups[i].chunks, groupOpts)
+		return err
+	})
+	if first := workpool.First(err); first != nil {
+		return nil, first.Err
+	}
+	return mergeTagged(chunks, groups, results), nil
+}
+
+// mergeTagged puts the translations of every group back in the shape of
+// chunks. The route of the first group describes the result; the steps of
+// the tagged groups are in TagSteps and the costs add up. Scores are kept
+// only if every group has them, and intermediate translations not at all.
+func mergeTagged(chunks [][]string, groups []*tagGroup, results []*router.Result) *router.Result {
+	merged := *results[0]
+	merged.Translations = make([][]string, len(chunks))
+	scored := true
+	for _, res := range results {
+		scored = scored && res.Scores != nil
+	}
+	merged.Scores = nil
+	if scored {
+		merged.Scores = make([][]float64, len(chunks))
+	}
+	for c, chunk := range chunks {
+		merged.Translations[c] = make([]string, len(chunk))
+		if scored {
+			merged.Scores[c] = make([]float64, len(chunk))
+		}
+	}
+
+	merged.Intermediate = nil
+	merged.Cost = 0
+	merged.TagSteps = map[string][]string{}
+	for i, g := range groups {
+		res := results[i]
+		merged.Cost += res.Cost
+		if g.tag != "" {
+			merged.TagSteps[g.tag] = res.Steps
+		}
+		for k, positions := range g.at {
+			for m, at := range positions {
+				merged.Translations[at.chunk][at.text] = res.Translations[k][m]
+				if scored {
+					merged.Scores[at.chunk][at.text] = res.Scores[k][m]
+				}
+			}
+		}
+	}
+	return &merged
+}
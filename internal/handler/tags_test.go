@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestValidateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"none", Request{Texts: []string{"a"}}, false},
+		{"per text", Request{Texts: []string{"a", "b"}, Tags: []string{"legal", ""}}, false},
+		{"wrong length", Request{Texts: []string{"a", "b"}, Tags: []string{"legal"}}, true},
+		{"invalid tag", Request{Texts: []string{"a"}, Tags: []string{"Legal!"}}, true},
+		{"single text", Request{Text: "a", Tags: []string{"legal"}}, true},
+		{"html", Request{Texts: []string{"<p>a</p>"}, Tags: []string{"legal"}, ChunkStrategy: ChunkHTML}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTags(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateTags() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChunkTags(t *testing.T) {
+	long := strings.Repeat("Frase de prueba bastante larga. ", 60)
+	req := Request{SourceLang: "es", Texts: []string{"a", long, "b"}, Tags: []string{"", "legal", ""}}
+	split := chunker.SplitOversized(req.Texts, req.SourceLang, chunker.DefaultMaxTokensPerText)
+	if !split.Oversized() {
+		t.Fatal("test text was not split")
+	}
+	chunks := chunker.ChunkTexts(split.Texts, 2)
+
+	tags := chunkTags(req, split, chunks, nil)
+	var flat []string
+	for _, chunk := range tags {
+		flat = append(flat, chunk...)
+	}
+	want := make([]string, len(split.Texts))
+	for i := 1; i < len(want)-1; i++ {
+		want[i] = "legal"
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("chunkTags() = %q, want %q", flat, want)
+	}
+
+	req.Tags = []string{"", "", ""}
+	if tags := chunkTags(req, split, chunks, nil); tags != nil {
+		t.Errorf("chunkTags() without tagged texts = %q, want nil", tags)
+	}
+}
+
+func TestChunkTags_Balanced(t *testing.T) {
+	req := Request{Texts: []string{"x", "y", "z"}, Tags: []string{"a", "b", "c"}}
+	split := &chunker.Split{Texts: req.Texts}
+	chunks := [][]string{{"z", "x"}, {"y"}}
+	order := [][]int{{2, 0}, {1}}
+
+	want := [][]string{{"c", "a"}, {"b"}}
+	if got := chunkTags(req, split, chunks, order); !reflect.DeepEqual(got, want) {
+		t.Errorf("chunkTags() = %q, want %q", got, want)
+	}
+}
+
+func TestGroupByTag_Merge(t *testing.T) {
+	chunks := [][]string{{"uno", "dos", "tres"}, {"cuatro", "cinco"}}
+	tags := [][]string{{"", "legal", ""}, {"legal", "medical"}}
+
+	groups := groupByTag(chunks, tags)
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.tag)
+	}
+	if want := []string{"", "legal", "medical"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("groups = %q, want %q", got, want)
+	}
+	if want := [][]string{{"dos"}, {"cuatro"}}; !reflect.DeepEqual(groups[1].chunks, want) {
+		t.Errorf("legal chunks = %q, want %q", groups[1].chunks, want)
+	}
+
+	// Each group answers its texts upper-cased, with a score of -1
+	results := make([]*router.Result, len(groups))
+	for i, g := range groups {
+		res := &router.Result{Steps: []string{"translator-" + g.tag}, Cost: 0.5}
+		for _, chunk := range g.chunks {
+			var translations []string
+			var scores []float64
+			for _, text := range chunk {
+				translations = append(translations, strings.ToUpper(text))
+				scores = append(scores, -1)
+			}
+			res.Translations = append(res.Translations, translations)
+			res.Scores = append(res.Scores, scores)
+		}
+		results[i] = res
+	}
+
+	merged := mergeTagged(chunks, groups, results)
+	if want := [][]string{{"UNO", "DOS", "TRES"}, {"CUATRO", "CINCO"}}; !reflect.DeepEqual(merged.Translations, want) {
+		t.Errorf("translations = %q, want %q", merged.Translations, want)
+	}
+	if want := [][]float64{{-1, -1, -1}, {-1, -1}}; !reflect.DeepEqual(merged.Scores, want) {
+		t.Errorf("scores = %v, want %v", merged.Scores, want)
+	}
+	if want := []string{"translator-"}; !reflect.DeepEqual(merged.Steps, want) {
+		t.Errorf("steps = %q, want %q", merged.Steps, want)
+	}
+	wantTagged := map[string][]string{"legal": {"translator-legal"}, "medical": {"translator-medical"}}
+	if !reflect.DeepEqual(merged.TagSteps, wantTagged) || merged.Cost != 1.5 {
+		t.Errorf("tag steps = %q with cost %g, want %q with 1.5", merged.TagSteps, merged.Cost, wantTagged)
+	}
+
+	results[2].Scores = nil
+	if merged := mergeTagged(chunks, groups, results); merged.Scores != nil {
+		t.Errorf("scores with an unscored group = %v, want nil", merged.Scores)
+	}
+}
+
+func TestKnownTexts_Tags(t *testing.T) {
+	texts := []string{"rojo", "Cláusula de garantía", "usado"}
+	tags := []string{"legal", "legal", ""}
+	req := Request{Texts: texts, Tags: tags, SourceLang: "es", TargetLang: "en"}
+	known := (&Handler{}).takeKnownTexts(context.Background(), &req, nil, nil)
+	if want := []string{"legal"}; !reflect.DeepEqual(req.Tags, want) {
+		t.Fatalf("tags left = %q, want %q", req.Tags, want)
+	}
+	known.merge(&req, []string{"Warranty clause"})
+	if !reflect.DeepEqual(req.Tags, tags) {
+		t.Errorf("merge() left tags %q, want %q", req.Tags, tags)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// responseReserve is the part of the invocation time kept back from the
+// translation budget, so a TIMEOUT response is returned before the Lambda
+// runtime kills the invocation.
+const responseReserve = time.Second
+
+// TimeoutInfo tells how far a request that ran out of time got.
+type TimeoutInfo struct {
+	// StepsCompleted of Steps route steps finished; Function is the
+	// translator of the step that ran out of time.
+	StepsCompleted int    `json:"stepsCompleted"`
+	Steps          int    `json:"steps"`
+	Function       string `json:"function"`
+	// StepBudgetMs is the share of the time the step was given, and
+	// ElapsedMs the time the request had run.
+	StepBudgetMs int64 `json:"stepBudgetMs"`
+	ElapsedMs    int64 `json:"elapsedMs"`
+}
+
+// validateTimeout checks Request.TimeoutMs.
+func validateTimeout(req Request) error {
+	if req.TimeoutMs < 0 {
+		return fmt.Errorf("timeoutMs must not be negative")
+	}
+	return nil
+}
+
+// withTimeBudget returns ctx with the deadline of the request: TimeoutMs
+// from now, but never later than the invocation deadline less
+// responseReserve. The router shares it among the route steps.
+func withTimeBudget(ctx context.Context, req Request) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	if d, ok := ctx.Deadline(); ok && time.Until(d) > 2*responseReserve {
+		deadline = d.Add(-responseReserve)
+	}
+	if req.TimeoutMs > 0 {
+		budget := time.Now().Add(time.Duration(req.TimeoutMs) * time.Millisecond)
+		if deadline.IsZero() || budget.Before(deadline) {
+			deadline = budget
+		}
+	}
+	if deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// timedOut returns the response to a translation that ran out of time
+// after elapsed, or nil when err is not a timeout.
+func timedOut(err error, elapsed time.Duration) *Response {
+	var timeoutErr *router.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		return nil
+	}
+	completed := timeoutErr.Step - 1
+	resp := errorResponse(ErrorTimeout, completed, timeoutErr.Steps)
+	resp.Timeout = &TimeoutInfo{
+		StepsCompleted: completed,
+		Steps:          timeoutErr.Steps,
+		Function:       timeoutErr.Function,
+		StepBudgetMs:   timeoutErr.Budget.Milliseconds(),
+		ElapsedMs:      elapsed.Milliseconds(),
+	}
+	return resp
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestValidateTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeoutMs int
+		wantErr   bool
+	}{
+		{"unset", 0, false},
+		{"budget", 5000, false},
+		{"negative", -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTimeout(Request{TimeoutMs: tt.timeoutMs}); (err != nil) != tt.wantErr {
+				t.Errorf("validateTimeout() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTimeBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		invocation time.Duration // 0 for no invocation deadline
+		timeoutMs  int
+		want       time.Duration // 0 for no deadline
+	}{
+		{"no limits", 0, 0, 0},
+		{"request budget", 0, 2000, 2 * time.Second},
+		{"invocation less reserve", 30 * time.Second, 0, 29 * time.Second},
+		{"request budget is tighter", 30 * time.Second, 2000, 2 * time.Second},
+		{"invocation is tighter", 30 * time.Second, 60000, 29 * time.Second},
+		{"too little time to reserve", time.Second, 0, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.invocation > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.invocation)
+				defer cancel()
+			}
+			ctx, cancel := withTimeBudget(ctx, Request{TimeoutMs: tt.timeoutMs})
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tt.want == 0 {
+				if ok {
+					t.Errorf("deadline in %v, want none", time.Until(deadline))
+				}
+				return
+			}
+			if got := time.Until(deadline); !ok || got > tt.want || got < tt.want-time.Second/2 {
+				t.Errorf("deadline in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimedOut(t *testing.T) {
+	err := &router.TimeoutError{Step: 2, Steps: 2, Function: "pricofy-translator-en-romance", Budget: 1500 * time.Millisecond}
+	resp := timedOut(err, 3*time.Second)
+	if resp == nil {
+		t.Fatal("timedOut() = nil, want a response")
+	}
+	if want := "timed out after 1 of 2 route steps"; resp.ErrorCode != ErrorTimeout || resp.Error.Message != want {
+		t.Errorf("timedOut() = %q %q, want %q %q", resp.ErrorCode, resp.Error, ErrorTimeout, want)
+	}
+	want := &TimeoutInfo{StepsCompleted: 1, Steps: 2, Function: "pricofy-translator-en-romance", StepBudgetMs: 1500, ElapsedMs: 3000}
+	if !reflect.DeepEqual(resp.Timeout, want) {
+		t.Errorf("timedOut() timeout = %+v, want %+v", resp.Timeout, want)
+	}
+
+	if resp := timedOut(errors.New("boom"), time.Second); resp != nil {
+		t.Errorf("timedOut(boom) = %+v, want nil", resp)
+	}
+}
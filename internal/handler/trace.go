@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"github.com/pricofy/translation-manager/internal/tracing"
+)
+
+// endTrace closes the subsegment of a request, annotated with the error
+// code of an error response.
+func endTrace(seg *tracing.Subsegment, resp *Response, err error) {
+	if err == nil && resp != nil && resp.ErrorCode != "" {
+		seg.Annotate("ErrorCode", resp.ErrorCode)
+		err = resp.Error
+	}
+	seg.End(err)
+}
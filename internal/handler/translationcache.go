@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// loadCache opens the translation cache, nil when TRANSLATION_CACHE_TABLE
+// is not set.
+func loadCache(cfg aws.Config) (*cache.Cache, error) {
+	table := os.Getenv(cache.TableEnv)
+	if table == "" {
+		return nil, nil
+	}
+	ttl, err := cache.TTL()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dynamodb.NewFromConfig(cfg), table, ttl), nil
+}
+
+// lookupCache answers the texts of req remembered in known's translation cache,
+// or with bypass only records that the cache was skipped. Entries without a
+// score are misses when the request needs scores. The cache never fails a
+// request: problems are logged and the texts are translated.
+func lookupCache(ctx context.Context, req Request, known *knownTexts, bypass bool, rec *metrics.Recorder) {
+	if known.cache == nil {
+		return
+	}
+	var found map[string]cache.Entry
+	var stale map[string]bool
+	if !bypass {
+		var err error
+		if found, stale, err = known.cache.Lookup(ctx, req.SourceLang, req.TargetLang, req.Texts); err != nil {
+			logCacheError(ctx, err)
+			return
+		}
+	}
+
+	// Texts passed through or answered by the dictionary are not looked up
+	outcomes := make([]string, len(req.Texts))
+	needScores := requestsScores(req)
+	for i, text := range req.Texts {
+		if _, hit := known.hits[i]; hit {
+			continue
+		}
+		e, ok := found[text]
+		outcomes[i] = cacheOutcome(ok && (!needScores || e.Score != nil), stale[text], bypass)
+		if outcomes[i] == CacheHit {
+			known.addCached(i, e)
+		}
+	}
+	recordCacheLookups(rec, req, req.Texts, outcomes)
+}
+
+// cacheOutcome classifies the lookup of one text.
+func cacheOutcome(usable, stale, bypass bool) string {
+	switch {
+	case bypass:
+		return CacheBypass
+	case usable:
+		return CacheHit
+	case stale:
+		return CacheStale
+	default:
+		return CacheMiss
+	}
+}
+
+// storeCache remembers in c the translations of the texts of req, made by
+// modelVersion at translatedAt; scores is nil when the translators
+// returned none.
+func storeCache(ctx context.Context, c *cache.Cache, req Request, translations []string, scores []float64, modelVersion string, translatedAt time.Time) {
+	if c == nil {
+		return
+	}
+	entries := make([]cache.Entry, len(translations))
+	for i, t := range translations {
+		entries[i].Translation = t
+		entries[i].ModelVersion = modelVersion
+		entries[i].TranslatedAt = translatedAt
+		if scores != nil {
+			entries[i].Score = &scores[i]
+		}
+	}
+	logCacheError(ctx, c.Put(ctx, req.SourceLang, req.TargetLang, req.Texts, entries))
+}
+
+// logCacheError logs a translation cache problem, if any.
+func logCacheError(ctx context.Context, err error) {
+	if err != nil {
+		slog.WarnContext(ctx, "translation cache failed", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+	"github.com/pricofy/translation-manager/internal/truncate"
+)
+
+// applyTruncation adds every translation cut to Request.TruncatedTo
+// characters at a word boundary when the request asks for it.
+func applyTruncation(resp *Response, req Request) {
+	if req.TruncatedTo == 0 {
+		return
+	}
+	lang := locale.Base(req.TargetLang)
+	resp.Truncated = make([]string, len(resp.Translations))
+	for i, t := range resp.Translations {
+		resp.Truncated[i] = truncate.Text(t, lang, req.TruncatedTo)
+	}
+}
+
+// validateTruncation checks Request.TruncatedTo.
+func validateTruncation(req Request) error {
+	if req.TruncatedTo < 0 {
+		return fmt.Errorf("truncatedTo must be positive")
+	}
+	if req.TruncatedTo > 0 && req.Text != "" {
+		return fmt.Errorf("truncatedTo is not supported with text; send texts")
+	}
+	return nil
+}
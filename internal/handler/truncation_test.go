@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyTruncation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want []string
+	}{
+		{"not requested", Request{TargetLang: "es"}, nil},
+		{"cut at a word boundary", Request{TargetLang: "es", TruncatedTo: 16}, []string{"Camiseta roja…", "Bolso", ""}},
+		{"regional variant uses base language", Request{TargetLang: "es_MX", TruncatedTo: 13}, []string{"Camiseta…", "Bolso", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{Translations: []string{"Camiseta roja de algodón", "Bolso", ""}}
+			applyTruncation(resp, tt.req)
+			if !reflect.DeepEqual(resp.Truncated, tt.want) {
+				t.Errorf("Truncated = %q, want %q", resp.Truncated, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTruncation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"texts", Request{Texts: []string{"a"}, TruncatedTo: 80}, false},
+		{"unset", Request{Text: "a. b."}, false},
+		{"negative", Request{Texts: []string{"a"}, TruncatedTo: -1}, true},
+		{"document mode", Request{Text: "a. b.", TruncatedTo: 80}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTruncation(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateTruncation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+// estimatedChunkDuration is the typical translator time for one chunk on one
+// route step (~6s per 50 texts, see the README performance table).
+const estimatedChunkDuration = 6 * time.Second
+
+// recordUsage reports the manager's own duration and memory use, and its
+// memory headroom when the Lambda memory size is known.
+func recordUsage(rec *metrics.Recorder, start time.Time) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	rec.Add("ManagerDuration", metrics.Milliseconds, float64(time.Since(start).Milliseconds()), nil)
+	rec.Add("ManagerMemoryUsed", metrics.Bytes, float64(m.Sys), nil)
+	if limit := memoryLimit(); limit > 0 {
+		rec.Add("ManagerMemoryHeadroom", metrics.Bytes, float64(limit)-float64(m.Sys), nil)
+	}
+}
+
+// memoryLimit returns the configured Lambda memory in bytes, or 0 outside Lambda.
+func memoryLimit() uint64 {
+	mb, err := strconv.ParseUint(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return mb << 20
+}
+
+// checkDeadline warns when translating chunks over steps route steps is
+// expected to take longer than the time left before ctx's deadline.
+func checkDeadline(ctx context.Context, chunks, steps int, rec *metrics.Recorder) *Warning {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	remaining := time.Until(deadline)
+	estimated := time.Duration(chunks*steps) * estimatedChunkDuration
+	if estimated <= remaining {
+		return nil
+	}
+
+	rec.Add("DeadlineRisk", metrics.Count, 1, nil)
+	return &Warning{
+		Code: WarningDeadlineRisk,
+		Message: fmt.Sprintf("%d chunks over %d route steps are estimated to take %s but only %s remain; split the batch",
+			chunks, steps, estimated.Round(time.Second), remaining.Round(time.Second)),
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/metrics"
+)
+
+func TestRecordUsage(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "128")
+
+	var buf bytes.Buffer
+	rec := metrics.New(&buf)
+	recordUsage(rec, time.Now().Add(-50*time.Millisecond))
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, name := range []string{"ManagerDuration", "ManagerMemoryUsed", "ManagerMemoryHeadroom"} {
+		if !strings.Contains(out, `"`+name+`"`) {
+			t.Errorf("metrics output missing %s: %s", name, out)
+		}
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		env  string
+		want uint64
+	}{
+		{"128", 128 << 20},
+		{"", 0},
+		{"lots", 0},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", tt.env)
+		if got := memoryLimit(); got != tt.want {
+			t.Errorf("memoryLimit() with %q = %d, want %d", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		chunks   int
+		steps    int
+		wantWarn bool
+	}{
+		{"fits", 2 * time.Minute, 3, 2, false},
+		{"too many chunks", 2 * time.Minute, 12, 2, true},
+		{"direct route fits", 2 * time.Minute, 12, 1, false},
+		{"no deadline", 0, 100, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			w := checkDeadline(ctx, tt.chunks, tt.steps, metrics.New(&bytes.Buffer{}))
+			if (w != nil) != tt.wantWarn {
+				t.Fatalf("checkDeadline() = %+v, want warning %v", w, tt.wantWarn)
+			}
+			if w != nil && w.Code != WarningDeadlineRisk {
+				t.Errorf("Code = %q, want %q", w.Code, WarningDeadlineRisk)
+			}
+		})
+	}
+}
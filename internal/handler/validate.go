@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/grapheme"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// Actions accepted in Request.Action.
+const (
+	// ActionTranslate translates the texts (the default).
+	ActionTranslate = "translate"
+	// ActionValidate runs validation, routing and chunk estimation only.
+	ActionValidate = "validate"
+	// ActionLanguages returns the supported languages and pairs.
+	ActionLanguages = "languages"
+)
+
+// Verdict statuses for ActionValidate.
+const (
+	VerdictOK      = "ok"
+	VerdictWarning = "warning"
+	VerdictError   = "error"
+)
+
+// Text length limits checked by ActionValidate, in characters (grapheme
+// clusters).
+const (
+	longTextWarning = 5000
+	maxTextLength   = 50000
+)
+
+// Verdict is the validation outcome for one text.
+type Verdict struct {
+	Index    int      `json:"index"`
+	Status   string   `json:"status"`
+	Messages []string `json:"messages,omitempty"`
+}
+
+// ValidationReport is the result of ActionValidate.
+type ValidationReport struct {
+	// Valid is false when any text has an error verdict.
+	Valid    bool       `json:"valid"`
+	Verdicts []Verdict  `json:"verdicts"`
+	Route    *RouteInfo `json:"route"`
+	// ChunksEstimated is the number of chunks the batch would be sent as.
+	ChunksEstimated int `json:"chunksEstimated"`
+}
+
+// handleValidate checks a batch end to end without translating it.
+func handleValidate(req Request, r Translator, maxTexts int) *Response {
+	plan, err := r.Plan(req.SourceLang, req.TargetLang, req.Backend)
+	if err != nil {
+		return errorResponse(ErrorUnsupportedPair, req.SourceLang, req.TargetLang)
+	}
+
+	req.ChunkMaxTokens = chunkTokens(req, plan)
+	chunks, _ := scheduleChunks(req, maxTexts)
+	report := &ValidationReport{
+		Valid:           true,
+		Verdicts:        make([]Verdict, len(req.Texts)),
+		Route:           &RouteInfo{Steps: plan.Steps, PivotLang: plan.PivotLang, Pivots: plan.Pivots},
+		ChunksEstimated: len(chunks),
+	}
+
+	for i, text := range req.Texts {
+		report.Verdicts[i] = checkText(i, text)
+		if report.Verdicts[i].Status == VerdictError {
+			report.Valid = false
+		}
+	}
+
+	return &Response{Translations: []string{}, Validation: report}
+}
+
+// checkText returns the verdict for a single text.
+func checkText(index int, text string) Verdict {
+	v := Verdict{Index: index, Status: VerdictOK}
+	warn := func(msg string) {
+		v.Messages = append(v.Messages, msg)
+		if v.Status == VerdictOK {
+			v.Status = VerdictWarning
+		}
+	}
+
+	length := grapheme.Count(text)
+	switch {
+	case length > maxTextLength:
+		v.Status = VerdictError
+		v.Messages = append(v.Messages, fmt.Sprintf("text is %d characters, maximum is %d", length, maxTextLength))
+	case length > longTextWarning:
+		warn(fmt.Sprintf("long text (%d characters) may be slow or truncated by the model", length))
+	}
+
+	if strings.TrimSpace(text) == "" {
+		warn("empty text is returned as-is without translation")
+	}
+	if strings.ContainsRune(text, utf8.RuneError) {
+		warn("text contains invalid UTF-8 or replacement characters")
+	}
+	if strings.IndexFunc(text, isDisallowedControl) >= 0 {
+		warn("text contains control characters")
+	}
+
+	return v
+}
+
+// isDisallowedControl reports control characters other than common whitespace.
+func isDisallowedControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+}
+
+// validateAction checks Request.Action.
+func validateAction(action string) error {
+	switch action {
+	case "", ActionTranslate, ActionValidate, ActionKeywords, ActionStatus, ActionLanguages, ActionRoutes, ActionCacheStats, ActionVersion, ActionUsage:
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// validateBackend checks Request.Backend and Request.DryRun.
+func validateBackend(backend string, dryRun bool) error {
+	if backend != "" && !router.IsBackend(backend) {
+		return fmt.Errorf("unknown backend %q", backend)
+	}
+	if dryRun && backend != "" && backend != router.BackendDryRun {
+		return fmt.Errorf("dryRun cannot be combined with backend %q", backend)
+	}
+	return nil
+}
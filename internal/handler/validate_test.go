@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestCheckText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		status string
+	}{
+		{"plain text", "iPhone en buen estado", VerdictOK},
+		{"empty", "", VerdictWarning},
+		{"whitespace", "   ", VerdictWarning},
+		{"long text", strings.Repeat("a", longTextWarning+1), VerdictWarning},
+		{"too long", strings.Repeat("a", maxTextLength+1), VerdictError},
+		{"emoji counted as characters", strings.Repeat("👍🏽", longTextWarning), VerdictOK},
+		{"replacement character", "caf�", VerdictWarning},
+		{"control character", "a\x07b", VerdictWarning},
+		{"newlines are fine", "línea\nlínea", VerdictOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := checkText(0, tt.text)
+			if v.Status != tt.status {
+				t.Errorf("checkText() status = %q, want %q (messages %v)", v.Status, tt.status, v.Messages)
+			}
+			if tt.status != VerdictOK && len(v.Messages) == 0 {
+				t.Error("non-ok verdicts must explain the problem")
+			}
+		})
+	}
+}
+
+func TestHandleValidate(t *testing.T) {
+	texts := make([]string, 120)
+	for i := range texts {
+		texts[i] = "Producto"
+	}
+	texts[7] = strings.Repeat("x", maxTextLength+1)
+
+	resp := handleValidate(Request{Texts: texts, SourceLang: "es", TargetLang: "fr"}, &router.Router{}, 50)
+	if resp.Error != nil {
+		t.Fatalf("handleValidate() error = %s", resp.Error)
+	}
+
+	report := resp.Validation
+	if report.Valid {
+		t.Error("report should be invalid when a text has an error verdict")
+	}
+	if report.Verdicts[7].Status != VerdictError || report.Verdicts[0].Status != VerdictOK {
+		t.Errorf("unexpected verdicts: %+v, %+v", report.Verdicts[0], report.Verdicts[7])
+	}
+	if report.ChunksEstimated != 3 {
+		t.Errorf("ChunksEstimated = %d, want 3", report.ChunksEstimated)
+	}
+	if len(report.Route.Steps) != 2 || report.Route.PivotLang != "en" {
+		t.Errorf("Route = %+v, want 2-step pivot through en", report.Route)
+	}
+}
+
+func TestHandleValidate_UnsupportedPair(t *testing.T) {
+	resp := handleValidate(Request{Texts: []string{"a"}, SourceLang: "zh", TargetLang: "en"}, &router.Router{}, 50)
+	if resp.Error == nil {
+		t.Error("handleValidate() should report unsupported pairs")
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"github.com/pricofy/translation-manager/internal/accounting"
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/buildinfo"
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/capture"
+	"github.com/pricofy/translation-manager/internal/experiment"
+	"github.com/pricofy/translation-manager/internal/journal"
+	"github.com/pricofy/translation-manager/internal/postedit"
+	"github.com/pricofy/translation-manager/internal/profile"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/routing"
+	"github.com/pricofy/translation-manager/internal/variant"
+)
+
+// ActionVersion reports what the serving container runs: its build, routes,
+// translators and enabled features (admin only).
+const ActionVersion = "version"
+
+// VersionInfo describes the running deployment, to verify exactly what an
+// environment serves during incidents and rollouts.
+type VersionInfo struct {
+	Build       buildinfo.Info `json:"build"`
+	Environment string         `json:"environment"`
+	// CatalogVersion is the version of the language catalog, and
+	// RoutesVersion a content hash of the routing table entries ("" without
+	// a routing table).
+	CatalogVersion string `json:"catalogVersion"`
+	RoutesVersion  string `json:"routesVersion,omitempty"`
+	// Translators maps every translator to the function serving it, and
+	// ModelVersions every function to the model version it last reported
+	// to this container.
+	Translators   map[string]string `json:"translators"`
+	ModelVersions map[string]string `json:"modelVersions"`
+	// Features lists the opt-in features enabled by configuration.
+	Features []string `json:"features"`
+}
+
+// features maps the opt-in features to the environment variable enabling
+// them. JSON configs may also be given as a file (variable+"_FILE").
+var features = map[string]string{
+	"blocklist":            blocklist.ConfigEnv,
+	"capture":              capture.BucketEnv,
+	"directPairs":          router.DirectPairsEnv,
+	"experiments":          experiment.ConfigEnv,
+	"functionPattern":      router.FunctionPatternEnv,
+	"journal":              journal.TableEnv,
+	"pivotLanguages":       router.PivotsEnv,
+	"postEdit":             postedit.ConfigEnv,
+	"routingTable":         routing.TableEnv,
+	"tenantProfiles":       profile.TableEnv,
+	"translationBackends":  router.BackendsEnv,
+	"translationCache":     cache.TableEnv,
+	"translatorCosts":      router.CostsEnv,
+	"usageAccounting":      accounting.TableEnv,
+	"variantSubstitutions": variant.ConfigEnv,
+}
+
+// enabledFeatures returns the enabled features in name order. Switches
+// count as features when set to "true".
+func enabledFeatures() []string {
+	enabled := []string{}
+	for name, env := range features {
+		if os.Getenv(env) != "" || os.Getenv(env+"_FILE") != "" {
+			enabled = append(enabled, name)
+		}
+	}
+	for name, env := range map[string]string{
+		"experimentsKillSwitch": experiment.KillSwitchEnv,
+		"serveFromCacheOnly":    ServeFromCacheOnlyEnv,
+	} {
+		if os.Getenv(env) == "true" {
+			enabled = append(enabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled
+}
+
+// handleVersion serves the version action for authenticated admins.
+func (h *Handler) handleVersion(ctx context.Context, req Request) *Response {
+	if _, ok := h.authenticateAdmin(req.AdminToken); !ok {
+		return errorResponse(ErrorUnauthorized)
+	}
+	info := &VersionInfo{
+		Build:          buildinfo.Get(),
+		Environment:    os.Getenv("ENVIRONMENT"),
+		CatalogVersion: router.GetCatalog().Version,
+		RoutesVersion:  h.translator.RoutesVersion(ctx),
+		Translators:    h.translator.Functions(),
+		ModelVersions:  router.ModelVersions(),
+		Features:       enabledFeatures(),
+	}
+	return &Response{Translations: []string{}, Version: info}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestEnabledFeatures(t *testing.T) {
+	for _, env := range features {
+		t.Setenv(env, "")
+		t.Setenv(env+"_FILE", "")
+	}
+	t.Setenv(cache.TableEnv, "translation-cache")
+	t.Setenv(router.PivotsEnv+"_FILE", "/etc/pivots.json")
+	t.Setenv(ServeFromCacheOnlyEnv, "false")
+
+	want := []string{"pivotLanguages", "translationCache"}
+	if got := enabledFeatures(); !reflect.DeepEqual(got, want) {
+		t.Errorf("enabledFeatures() = %q, want %q", got, want)
+	}
+
+	t.Setenv(ServeFromCacheOnlyEnv, "true")
+	want = []string{"pivotLanguages", "serveFromCacheOnly", "translationCache"}
+	if got := enabledFeatures(); !reflect.DeepEqual(got, want) {
+		t.Errorf("enabledFeatures() = %q, want %q", got, want)
+	}
+}
+
+func TestHandleVersion(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "staging")
+	h := NewHandler(newRouter(t))
+	useAdmin(t, h)
+
+	if resp := h.handleVersion(context.Background(), Request{Action: ActionVersion, AdminToken: "wrong"}); resp.ErrorCode != ErrorUnauthorized {
+		t.Fatalf("handleVersion() with a wrong token error code = %q, want %s", resp.ErrorCode, ErrorUnauthorized)
+	}
+
+	resp := h.handleVersion(context.Background(), Request{Action: ActionVersion, AdminToken: "s3cret"})
+	if resp.Error != nil {
+		t.Fatalf("handleVersion() error = %s", resp.Error)
+	}
+	v := resp.Version
+	if v == nil || v.Environment != "staging" || v.Build.Commit == "" {
+		t.Fatalf("handleVersion() version = %+v, want the staging build", v)
+	}
+	if v.CatalogVersion != router.GetCatalog().Version {
+		t.Errorf("catalog version = %q, want %q", v.CatalogVersion, router.GetCatalog().Version)
+	}
+	if got := v.Translators[router.TranslatorRomanceEn]; got != "pricofy-translator-romance-en" {
+		t.Errorf("romance-en translator = %q, want the default function", got)
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// maxHashLength bounds the caller's content hashes.
+const maxHashLength = 128
+
+// TranslationVersion tells what a translation was made from, by which
+// model and when (Response.Versions). Callers store it with the
+// translation and translate a listing again only when its hash changes.
+type TranslationVersion struct {
+	// Hash is the content hash of the source text: Request.Hashes, or its
+	// Fingerprint when the caller sent "".
+	Hash string `json:"hash"`
+	// ModelVersion is the model version of each route step, joined with
+	// "+"; empty for dictionary and passthrough answers and for models
+	// that report none.
+	ModelVersion string `json:"modelVersion,omitempty"`
+	// TranslatedAt is when the translators made the translation, which is
+	// earlier for cached translations; absent when unknown.
+	TranslatedAt *time.Time `json:"translatedAt,omitempty"`
+}
+
+// Fingerprint returns the content hash of a source text: its hex SHA-256.
+func Fingerprint(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateHashes checks Request.Hashes.
+func validateHashes(req Request) error {
+	if len(req.Hashes) == 0 {
+		return nil
+	}
+	switch {
+	case req.Text != "":
+		return fmt.Errorf("hashes is not supported with text")
+	case req.ChunkStrategy == ChunkHTML:
+		return fmt.Errorf("hashes is not supported with chunkStrategy %q", ChunkHTML)
+	case len(req.Hashes) != len(req.Texts):
+		return fmt.Errorf("hashes has %d entries for %d texts", len(req.Hashes), len(req.Texts))
+	}
+	for i, hash := range req.Hashes {
+		if len(hash) > maxHashLength {
+			return fmt.Errorf("hashes[%d] is longer than %d characters", i, maxHashLength)
+		}
+	}
+	return nil
+}
+
+// routeModelVersion is the model version of the route of result.
+func routeModelVersion(result *router.Result) string {
+	return strings.Join(result.ModelVersions, "+")
+}
+
+// textVersions returns the version of every translation of req when it
+// has hashes: cache hits keep the version they were cached with, and the
+// translators' output was made at translatedAt. Texts a cache-only
+// request could not answer have no model version or time.
+func textVersions(req Request, known knownTexts, result *router.Result, translatedAt time.Time) []TranslationVersion {
+	if req.Hashes == nil {
+		return nil
+	}
+	versions := make([]TranslationVersion, len(req.Texts))
+	for i, text := range req.Texts {
+		v := TranslationVersion{Hash: req.Hashes[i]}
+		if v.Hash == "" {
+			v.Hash = Fingerprint(text)
+		}
+		at := translatedAt
+		_, hit := known.hits[i]
+		e, cached := known.cached[i]
+		switch {
+		case cached:
+			v.ModelVersion, at = e.ModelVersion, e.TranslatedAt
+		case hit:
+		case cacheOnly(req):
+			at = time.Time{}
+		default:
+			v.ModelVersion = routeModelVersion(result)
+		}
+		if !at.IsZero() {
+			v.TranslatedAt = &at
+		}
+		versions[i] = v
+	}
+	return versions
+}
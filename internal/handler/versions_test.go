@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/cache"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+func TestValidateHashes(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"no hashes", Request{Texts: []string{"a"}}, false},
+		{"one per text", Request{Texts: []string{"a", "b"}, Hashes: []string{"h1", ""}}, false},
+		{"count mismatch", Request{Texts: []string{"a", "b"}, Hashes: []string{"h1"}}, true},
+		{"too long", Request{Texts: []string{"a"}, Hashes: []string{strings.Repeat("f", maxHashLength+1)}}, true},
+		{"document", Request{Text: "a", Hashes: []string{"h1"}}, true},
+		{"html", Request{Texts: []string{"<p>a</p>"}, Hashes: []string{"h1"}, ChunkStrategy: ChunkHTML}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateHashes(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("validateHashes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTextVersions(t *testing.T) {
+	cachedAt := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	translatedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	req := Request{Texts: []string{"rojo", "Camiseta", "Pantalón"}, Hashes: []string{"h-rojo", "", "h-pantalon"}, SourceLang: "es", TargetLang: "en"}
+	known := (&Handler{}).takeKnownTexts(context.Background(), &req, nil, nil)
+	known.addCached(2, cache.Entry{Translation: "Trousers", ModelVersion: "v1", TranslatedAt: cachedAt})
+	req.Texts = known.texts
+	result := &router.Result{ModelVersions: []string{"v2", "v3"}}
+
+	got := textVersions(req, known, result, translatedAt)
+	want := []TranslationVersion{
+		{Hash: "h-rojo", TranslatedAt: &translatedAt},
+		{Hash: Fingerprint("Camiseta"), ModelVersion: "v2+v3", TranslatedAt: &translatedAt},
+		{Hash: "h-pantalon", ModelVersion: "v1", TranslatedAt: &cachedAt},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("textVersions() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Hash != want[i].Hash || got[i].ModelVersion != want[i].ModelVersion || !got[i].TranslatedAt.Equal(*want[i].TranslatedAt) {
+			t.Errorf("textVersions()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := textVersions(Request{Texts: req.Texts}, known, result, translatedAt); got != nil {
+		t.Errorf("textVersions() without hashes = %+v, want nil", got)
+	}
+}
+
+func TestHandle_Versions(t *testing.T) {
+	h := NewHandler(&stubTranslator{})
+	useTranslationCache(h)
+	req := Request{Texts: []string{"Camiseta", "Camiseta"}, Hashes: []string{"h1", "h1"}, SourceLang: "es", TargetLang: "fr"}
+
+	first, err := h.Handle(context.Background(), req)
+	if err != nil || first.Error != nil || len(first.Versions) != 2 {
+		t.Fatalf("Handle() = %+v, %v, want 2 versions", first, err)
+	}
+	if v := first.Versions[0]; v.Hash != "h1" || v.TranslatedAt == nil {
+		t.Errorf("Versions[0] = %+v, want hash h1 and a time", v)
+	}
+
+	// The cached translation keeps the time it was made
+	second, err := h.Handle(context.Background(), req)
+	if err != nil || len(second.Versions) != 2 {
+		t.Fatalf("Handle() = %+v, %v", second, err)
+	}
+	if got, want := second.Versions[1].TranslatedAt, first.Versions[1].TranslatedAt; got == nil || got.Unix() != want.Unix() {
+		t.Errorf("cached TranslatedAt = %v, want %v", got, want)
+	}
+}
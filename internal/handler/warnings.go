@@ -0,0 +1,46 @@
+package handler
+
+// Warning codes reported in Response.Warnings.
+const (
+	// WarningDeadlineRisk means the chunk fan-out may not finish before the
+	// invocation times out.
+	WarningDeadlineRisk = "DEADLINE_RISK"
+	// WarningLanguageNormalized means a language name or tag was rewritten
+	// to its canonical code.
+	WarningLanguageNormalized = "LANGUAGE_NORMALIZED"
+	// WarningLanguageFallback means an unsupported regional variant was
+	// replaced by its base language.
+	WarningLanguageFallback = "LANGUAGE_FALLBACK"
+	// WarningPairLikelyInverted means the texts look like the target
+	// language, so sourceLang and targetLang were probably swapped.
+	WarningPairLikelyInverted = "PAIR_LIKELY_INVERTED"
+	// WarningPairInvertedCorrected means the pair was swapped back
+	// (invertedPairAction "correct").
+	WarningPairInvertedCorrected = "PAIR_INVERTED_CORRECTED"
+	// WarningLongToken means a text had unbreakable tokens too long to
+	// translate, which were passed through or truncated.
+	WarningLongToken = "LONG_TOKEN"
+	// WarningPlaceholderDropped means a translation lost placeholders of
+	// its text, which were appended at the end (placeholders "on").
+	WarningPlaceholderDropped = "PLACEHOLDER_DROPPED"
+	// WarningCacheMiss means a cache-only request could not answer a text,
+	// which is left untranslated ("").
+	WarningCacheMiss = "CACHE_MISS"
+	// WarningDeprecated means the request used a deprecated field that was
+	// mapped to its replacement.
+	WarningDeprecated = "DEPRECATED"
+	// WarningPremiumFallback means the premium backend failed and the
+	// translator Lambdas translated instead (quality "premium").
+	WarningPremiumFallback = "PREMIUM_FALLBACK"
+	// WarningChunksDeadLettered means chunks of a job failed and their texts
+	// were left untranslated ("") for a redrive (Response.DeadLettered).
+	WarningChunksDeadLettered = "CHUNKS_DEAD_LETTERED"
+)
+
+// Warning is a non-fatal problem with a request.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Index is the text the warning is about, if it concerns a single text.
+	Index *int `json:"index,omitempty"`
+}
@@ -0,0 +1,329 @@
+// Package htmltext extracts the translatable text of HTML fragments, such as
+// product descriptions, and reassembles the fragment around the translated
+// text. Markup is kept verbatim: block elements split the text into units,
+// inline elements stay inside their unit as tags to protect, and only a few
+// human-readable attributes (alt, title, …) are translated.
+package htmltext
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// DefaultAttributes are the attributes whose values are translated on any
+// element unless Options choose others.
+var DefaultAttributes = []string{"alt", "title", "placeholder", "aria-label"}
+
+// DefaultMeta are the <meta> names whose content is translated unless
+// Options choose others.
+var DefaultMeta = []string{"description"}
+
+// attributes are the human-readable attributes Options may choose. Machine
+// values such as href, class or data-* are never translated.
+var attributes = map[string]bool{
+	"abbr": true, "alt": true, "aria-description": true, "aria-label": true,
+	"aria-placeholder": true, "aria-roledescription": true, "aria-valuetext": true,
+	"label": true, "placeholder": true, "title": true,
+}
+
+// metaNames are the <meta> names and properties Options may choose.
+var metaNames = map[string]bool{
+	"description": true, "keywords": true,
+	"og:title": true, "og:description": true, "og:site_name": true, "og:image:alt": true,
+	"twitter:title": true, "twitter:description": true, "twitter:image:alt": true,
+}
+
+// Options choose what is translated besides text nodes. A nil list keeps
+// its default; an empty one translates none.
+type Options struct {
+	// Attributes are translated on any element (DefaultAttributes).
+	Attributes []string
+	// Meta are the names or properties of the <meta> elements whose
+	// content is translated (DefaultMeta).
+	Meta []string
+}
+
+// Validate checks that every attribute and meta name may be translated.
+func (o Options) Validate() error {
+	for _, a := range o.Attributes {
+		a = strings.ToLower(a)
+		switch {
+		case strings.HasPrefix(a, "data-"):
+			return fmt.Errorf("attribute %q: data-* attributes are never translated", a)
+		case !attributes[a]:
+			return fmt.Errorf("attribute %q cannot be translated (expected one of: %s)", a, strings.Join(sortedKeys(attributes), ", "))
+		}
+	}
+	for _, m := range o.Meta {
+		if !metaNames[strings.ToLower(m)] {
+			return fmt.Errorf("meta %q cannot be translated (expected one of: %s)", m, strings.Join(sortedKeys(metaNames), ", "))
+		}
+	}
+	return nil
+}
+
+// set returns the lower-cased names of list, or of defaults when list is nil.
+func set(list, defaults []string) map[string]bool {
+	if list == nil {
+		list = defaults
+	}
+	s := make(map[string]bool, len(list))
+	for _, name := range list {
+		s[strings.ToLower(name)] = true
+	}
+	return s
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inline elements flow within a unit; any other element ends it.
+var inline = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "br": true,
+	"cite": true, "code": true, "data": true, "dfn": true, "em": true,
+	"font": true, "i": true, "img": true, "kbd": true, "mark": true, "q": true,
+	"s": true, "samp": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "time": true, "u": true, "var": true, "wbr": true,
+}
+
+// verbatim inline elements keep their content untranslated.
+var verbatim = map[string]bool{"code": true, "kbd": true, "samp": true, "var": true}
+
+// raw elements hold no markup and no translatable text.
+var raw = map[string]bool{"script": true, "style": true, "textarea": true}
+
+// Page is an HTML fragment split into literal markup and translatable units.
+type Page struct {
+	parts []part
+	units []string
+	// attrs and meta are the attributes and <meta> names translated.
+	attrs, meta map[string]bool
+}
+
+// part is literal markup, a text unit, or the quoted value of an attribute.
+type part struct {
+	literal string
+	unit    int // -1 for literal markup
+	attr    bool
+	// tags are the inline tags of a text unit with translated attributes.
+	tags []tag
+}
+
+// tag is an inline tag of a text unit whose attributes are translated.
+type tag struct {
+	raw   string
+	parts []part
+}
+
+// Parse splits src into markup and units, translating the default
+// attributes and <meta> names. Malformed markup is kept as it is; a "<" that
+// does not start a tag is text.
+func Parse(src string) *Page {
+	return ParseWith(src, Options{})
+}
+
+// ParseWith is Parse translating the attributes and <meta> names chosen by
+// opts, which must be valid.
+func ParseWith(src string, opts Options) *Page {
+	p := &Page{attrs: set(opts.Attributes, DefaultAttributes), meta: set(opts.Meta, DefaultMeta)}
+	var run strings.Builder // current text unit
+	var runTags []tag
+	flush := func() {
+		p.addText(run.String(), runTags)
+		run.Reset()
+		runTags = nil
+	}
+
+	for i := 0; i < len(src); {
+		t, ok := scanTag(src, i)
+		if !ok {
+			next := strings.IndexByte(src[i+1:], '<')
+			end := len(src)
+			if next >= 0 {
+				end = i + 1 + next
+			}
+			run.WriteString(decode(src[i:end]))
+			i = end
+			continue
+		}
+
+		switch {
+		case t.comment || inline[t.name]:
+			run.WriteString(src[i:t.end])
+			if parts, translated := p.tagParts(src[i:t.end], t); translated {
+				runTags = append(runTags, tag{raw: src[i:t.end], parts: parts})
+			}
+			i = t.end
+		case raw[t.name] && !t.closing:
+			flush()
+			end := closingTag(src, t.end, t.name)
+			p.literal(src[i:end])
+			i = end
+		default:
+			flush()
+			parts, _ := p.tagParts(src[i:t.end], t)
+			p.parts = append(p.parts, parts...)
+			i = t.end
+		}
+	}
+	flush()
+	return p
+}
+
+// Texts returns the units to translate, in order. Text units keep their
+// inline tags (see Tags) and the "&amp;", "&lt;" and "&gt;" escapes; other
+// character references are decoded.
+func (p *Page) Texts() []string {
+	return append([]string{}, p.units...)
+}
+
+// Render reassembles the fragment with translations in place of the units.
+// translations must have one entry per unit.
+func (p *Page) Render(translations []string) string {
+	var b strings.Builder
+	renderParts(&b, p.parts, translations)
+	return b.String()
+}
+
+func renderParts(b *strings.Builder, parts []part, translations []string) {
+	for _, pt := range parts {
+		switch {
+		case pt.unit < 0:
+			b.WriteString(pt.literal)
+		case pt.attr:
+			b.WriteString(`"` + strings.ReplaceAll(translations[pt.unit], `"`, "&quot;") + `"`)
+		default:
+			text := translations[pt.unit]
+			for _, t := range pt.tags {
+				var tb strings.Builder
+				renderParts(&tb, t.parts, translations)
+				text = strings.Replace(text, t.raw, tb.String(), 1)
+			}
+			b.WriteString(text)
+		}
+	}
+}
+
+// Tags returns the spans of the inline tags and comments of a unit, in
+// order. A verbatim element such as <code> is one span with its content.
+func Tags(text string) []protect.Span {
+	var spans []protect.Span
+	for i := 0; i < len(text); i++ {
+		if text[i] != '<' {
+			continue
+		}
+		t, ok := scanTag(text, i)
+		if !ok {
+			continue
+		}
+		end := t.end
+		if verbatim[t.name] && !t.closing {
+			end = closingTag(text, t.end, t.name)
+		}
+		spans = append(spans, protect.Span{Start: i, End: end})
+		i = end - 1
+	}
+	return spans
+}
+
+// addText adds a text unit, keeping its surrounding whitespace as literal
+// markup. Text without letters, e.g. "·" or "|", is not translated.
+func (p *Page) addText(text string, tags []tag) {
+	trimmed := strings.TrimSpace(text)
+	if !hasLetters(trimmed) {
+		// Only markup: keep it, but still translate its attributes
+		for _, t := range tags {
+			i := strings.Index(text, t.raw)
+			p.literal(text[:i])
+			p.parts = append(p.parts, t.parts...)
+			text = text[i+len(t.raw):]
+		}
+		p.literal(text)
+		return
+	}
+	start := strings.Index(text, trimmed)
+	p.literal(text[:start])
+	p.parts = append(p.parts, part{unit: len(p.units), tags: tags})
+	p.units = append(p.units, trimmed)
+	p.literal(text[start+len(trimmed):])
+}
+
+func (p *Page) literal(s string) {
+	if s != "" {
+		p.parts = append(p.parts, part{literal: s, unit: -1})
+	}
+}
+
+// tagParts splits the markup of a tag around the values of its translatable
+// attributes, adding a unit per value. translated reports any such value.
+func (p *Page) tagParts(markup string, t tagInfo) (parts []part, translated bool) {
+	last := 0
+	for _, a := range t.attrs {
+		value := strings.TrimSpace(html.UnescapeString(a.value))
+		if !p.translatable(t, a.name) || !hasLetters(value) {
+			continue
+		}
+		parts = append(parts,
+			part{literal: markup[last:a.start], unit: -1},
+			part{unit: len(p.units), attr: true})
+		p.units = append(p.units, escape(value))
+		last = a.end
+	}
+	return append(parts, part{literal: markup[last:], unit: -1}), last > 0
+}
+
+// translatable reports whether the attribute attr of t is translated.
+func (p *Page) translatable(t tagInfo, attr string) bool {
+	if t.name == "meta" {
+		name := t.attr("name")
+		if name == "" {
+			name = t.attr("property")
+		}
+		return attr == "content" && p.meta[strings.ToLower(name)]
+	}
+	return p.attrs[attr]
+}
+
+// hasLetters reports whether the text outside the tags of s has a letter.
+func hasLetters(s string) bool {
+	last := 0
+	for _, span := range append(Tags(s), protect.Span{Start: len(s), End: len(s)}) {
+		if strings.IndexFunc(s[last:span.Start], unicode.IsLetter) >= 0 {
+			return true
+		}
+		last = span.End
+	}
+	return false
+}
+
+// reference matches a character reference.
+var reference = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);?`)
+
+// decode decodes the character references of text, except the escapes of
+// "&", "<" and ">" that keep it valid HTML.
+func decode(text string) string {
+	return reference.ReplaceAllStringFunc(text, func(ref string) string {
+		switch strings.ToLower(strings.TrimSuffix(ref, ";")) {
+		case "&amp", "&lt", "&gt":
+			return ref
+		}
+		return escape(html.UnescapeString(ref))
+	})
+}
+
+// escape escapes the "&", "<" and ">" of plain text.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,191 @@
+package htmltext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+func TestParse_Texts(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		texts []string
+	}{
+		{"empty", "", []string{}},
+		{"plain text", "Camisa roja", []string{"Camisa roja"}},
+		{"blocks split units", "<h1>Camisa</h1><p>Roja y nueva</p>", []string{"Camisa", "Roja y nueva"}},
+		{"inline tags stay in the unit", "<p>Camisa <b>roja</b> nueva</p>", []string{"Camisa <b>roja</b> nueva"}},
+		{"surrounding whitespace", "<li>\n  Talla M \n</li>", []string{"Talla M"}},
+		{"no letters", "<p>10 €</p><p>·</p>", []string{}},
+		{"entities decoded", "<p>Algod&oacute;n&nbsp;100%</p>", []string{"Algodón 100%"}},
+		{"markup escapes kept", "<p>A &amp; B &lt; C</p>", []string{"A &amp; B &lt; C"}},
+		{"script and style", "<style>p{}</style><script>var t = '<b>Hola</b>';</script><p>Hola</p>", []string{"Hola"}},
+		{"comments", "<!-- Hola --><p>Adiós</p>", []string{"Adiós"}},
+		{"attributes", `<img src="a.jpg" alt="Foto de la camisa" data-label="Camisa">`, []string{"Foto de la camisa"}},
+		{"title attribute of a block", `<div title="Detalles">Camisa</div>`, []string{"Detalles", "Camisa"}},
+		{"meta description", `<meta name="description" content="Camisa roja"><meta name="author" content="Ana">`, []string{"Camisa roja"}},
+		{"stray angle bracket", "a < b", []string{"a < b"}},
+		{"unterminated tag", "Camisa <b", []string{"Camisa <b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.src).Texts(); !reflect.DeepEqual(got, tt.texts) {
+				t.Errorf("Parse(%q).Texts() = %q, want %q", tt.src, got, tt.texts)
+			}
+		})
+	}
+}
+
+func TestParseWith(t *testing.T) {
+	src := `<meta property="og:title" content="Camisa"><meta name="description" content="Roja">` +
+		`<img alt="Foto" title="Detalle" data-label="Etiqueta">`
+	tests := []struct {
+		name  string
+		opts  Options
+		texts []string
+	}{
+		{"defaults", Options{}, []string{"Roja", "Foto", "Detalle"}},
+		{"chosen attributes", Options{Attributes: []string{"TITLE"}}, []string{"Roja", "Detalle"}},
+		{"no attributes", Options{Attributes: []string{}}, []string{"Roja"}},
+		{"meta property", Options{Meta: []string{"og:title"}}, []string{"Camisa", "Foto", "Detalle"}},
+		{"no meta", Options{Meta: []string{}}, []string{"Foto", "Detalle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseWith(src, tt.opts)
+			if got := p.Texts(); !reflect.DeepEqual(got, tt.texts) {
+				t.Errorf("ParseWith(%+v).Texts() = %q, want %q", tt.opts, got, tt.texts)
+			}
+			if got := p.Render(p.Texts()); got != src {
+				t.Errorf("Render() = %q, want the source back", got)
+			}
+		})
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "chosen", opts: Options{Attributes: []string{"alt", "Aria-Description"}, Meta: []string{"og:description"}}},
+		{name: "data attribute", opts: Options{Attributes: []string{"data-title"}}, wantErr: "never translated"},
+		{name: "machine attribute", opts: Options{Attributes: []string{"href"}}, wantErr: "cannot be translated"},
+		{name: "machine meta", opts: Options{Meta: []string{"robots"}}, wantErr: "cannot be translated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err == nil) != (tt.wantErr == "") || err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender_RoundTrip(t *testing.T) {
+	srcs := []string{
+		"",
+		"Camisa roja",
+		"<h1>Camisa</h1>\n<p>Camisa <b>roja</b> de <a href=\"/lino\">lino</a>.</p>",
+		"<ul>\n  <li>Talla M</li>\n  <li>10 €</li>\n</ul>",
+		`<p title="Detalles">Precio: <code>A &amp; B</code></p><img alt="Foto" src=a.jpg>`,
+		"<script>if (a < b) {}</script><!-- nota --><br/>Fin",
+	}
+
+	for _, src := range srcs {
+		p := Parse(src)
+		if got := p.Render(p.Texts()); got != src {
+			t.Errorf("Render(Parse(%q)) = %q", src, got)
+		}
+	}
+}
+
+func TestRender_Translations(t *testing.T) {
+	tests := []struct {
+		name         string
+		src          string
+		translations []string
+		want         string
+	}{
+		{
+			name:         "text and inline tags",
+			src:          "<p>Camisa <b>roja</b></p>\n<p>Nueva</p>",
+			translations: []string{"<b>Red</b> shirt", "New"},
+			want:         "<p><b>Red</b> shirt</p>\n<p>New</p>",
+		},
+		{
+			name:         "attribute quoted and escaped",
+			src:          `<img alt='Foto' src=a.jpg>`,
+			translations: []string{`"Photo"`},
+			want:         `<img alt="&quot;Photo&quot;" src=a.jpg>`,
+		},
+		{
+			name:         "attribute of an inline tag",
+			src:          `<p>Ver <a title="Detalles" href="/d">más</a></p>`,
+			translations: []string{"Details", `See <a title="Detalles" href="/d">more</a>`},
+			want:         `<p>See <a title="Details" href="/d">more</a></p>`,
+		},
+		{
+			name:         "attribute of a tag without text",
+			src:          `<p><img alt="Foto"></p>`,
+			translations: []string{"Photo"},
+			want:         `<p><img alt="Photo"></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.src).Render(tt.translations); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		text string
+		want []protect.Span
+	}{
+		{"Camisa roja", nil},
+		{"Camisa <b>roja</b>", []protect.Span{{Start: 7, End: 10}, {Start: 14, End: 18}}},
+		{`<a href="/x?a>b">Ver</a>`, []protect.Span{{Start: 0, End: 17}, {Start: 20, End: 24}}},
+		{"Usa <code>git add</code> ya", []protect.Span{{Start: 4, End: 24}}},
+		{"Hola<br/>adiós<!-- x -->", []protect.Span{{Start: 4, End: 9}, {Start: 15, End: 25}}},
+		{"a < b", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Tags(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tags(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRender_ValidAfterMasking(t *testing.T) {
+	// The handler masks the tags of every unit; restoring them must rebuild
+	// the same markup around the translated text.
+	p := Parse(`<p>Camisa <i>muy</i> bonita</p>`)
+	text := p.Texts()[0]
+	spans := Tags(text)
+	masked, originals := protect.Mask(text, spans)
+	if strings.Contains(masked, "<") {
+		t.Fatalf("masked unit %q still has markup", masked)
+	}
+	restored, missing := protect.Restore(strings.Replace(masked, "Camisa", "Shirt", 1), originals)
+	if missing != 0 {
+		t.Fatalf("missing %d placeholders", missing)
+	}
+	if got, want := p.Render([]string{restored}), `<p>Shirt <i>muy</i> bonita</p>`; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
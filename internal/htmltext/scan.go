@@ -0,0 +1,164 @@
+package htmltext
+
+import (
+	"strings"
+)
+
+// tagInfo describes a tag found by scanTag.
+type tagInfo struct {
+	name    string // lower case; empty for comments and declarations
+	closing bool
+	comment bool // comment, doctype or processing instruction
+	end     int  // offset just past the tag in the scanned text
+	attrs   []attribute
+}
+
+// attribute is an attribute of a tag. start and end delimit its value,
+// quotes included, relative to the start of the tag.
+type attribute struct {
+	name       string
+	value      string
+	start, end int
+}
+
+// attr returns the value of the attribute called name.
+func (t tagInfo) attr(name string) string {
+	for _, a := range t.attrs {
+		if a.name == name {
+			return a.value
+		}
+	}
+	return ""
+}
+
+// scanTag scans the tag starting at s[i], reporting false when the "<" at
+// s[i] does not start a complete tag.
+func scanTag(s string, i int) (tagInfo, bool) {
+	rest := s[i:]
+	switch {
+	case rest == "" || rest[0] != '<':
+		return tagInfo{}, false
+	case strings.HasPrefix(rest, "<!--"):
+		end := strings.Index(rest[4:], "-->")
+		if end < 0 {
+			return tagInfo{}, false
+		}
+		return tagInfo{comment: true, end: i + 4 + end + 3}, true
+	case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return tagInfo{}, false
+		}
+		return tagInfo{comment: true, end: i + end + 1}, true
+	}
+
+	t := tagInfo{}
+	pos := 1
+	if strings.HasPrefix(rest, "</") {
+		t.closing = true
+		pos = 2
+	}
+	start := pos
+	for pos < len(rest) && isNameByte(rest[pos]) {
+		pos++
+	}
+	if pos == start || !isLetter(rest[start]) {
+		return tagInfo{}, false
+	}
+	t.name = strings.ToLower(rest[start:pos])
+
+	end, ok := scanAttributes(rest, pos, &t)
+	if !ok {
+		return tagInfo{}, false
+	}
+	t.end = i + end
+	return t, true
+}
+
+// scanAttributes scans the attributes of tag from rest[pos] up to the end of
+// the tag, returning the offset just past its ">".
+func scanAttributes(rest string, pos int, t *tagInfo) (int, bool) {
+	for pos < len(rest) {
+		switch c := rest[pos]; {
+		case c == '>':
+			return pos + 1, true
+		case c == '/' || isSpace(c):
+			pos++
+		default:
+			var a attribute
+			a, pos = scanAttribute(rest, pos)
+			if a.name != "" {
+				t.attrs = append(t.attrs, a)
+			}
+		}
+	}
+	return 0, false
+}
+
+// scanAttribute scans one attribute starting at rest[pos].
+func scanAttribute(rest string, pos int) (attribute, int) {
+	start := pos
+	for pos < len(rest) && !isSpace(rest[pos]) && !strings.ContainsRune("=>/", rune(rest[pos])) {
+		pos++
+	}
+	if pos == start {
+		// A stray "=": skip it
+		return attribute{}, pos + 1
+	}
+	a := attribute{name: strings.ToLower(rest[start:pos])}
+
+	eq := skipSpaces(rest, pos)
+	if eq >= len(rest) || rest[eq] != '=' {
+		return a, pos
+	}
+	pos = skipSpaces(rest, eq+1)
+	a.start = pos
+	if pos < len(rest) && (rest[pos] == '"' || rest[pos] == '\'') {
+		closing := strings.IndexByte(rest[pos+1:], rest[pos])
+		if closing < 0 {
+			return attribute{}, len(rest)
+		}
+		a.value = rest[pos+1 : pos+1+closing]
+		a.end = pos + closing + 2
+		return a, a.end
+	}
+	for pos < len(rest) && !isSpace(rest[pos]) && rest[pos] != '>' {
+		pos++
+	}
+	a.value = rest[a.start:pos]
+	a.end = pos
+	return a, pos
+}
+
+// closingTag returns the offset just past the closing tag called name found
+// from s[from], or len(s) when the element is never closed.
+func closingTag(s string, from int, name string) int {
+	for i := from; i < len(s); i++ {
+		if s[i] != '<' {
+			continue
+		}
+		if t, ok := scanTag(s, i); ok && t.closing && t.name == name {
+			return t.end
+		}
+	}
+	return len(s)
+}
+
+func skipSpaces(s string, pos int) int {
+	for pos < len(s) && isSpace(s[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameByte(c byte) bool {
+	return isLetter(c) || '0' <= c && c <= '9' || c == '-' || c == ':'
+}
@@ -0,0 +1,192 @@
+// Package idempotency stores the responses of requests carrying an
+// idempotency key, so a client retry or a duplicate queue message gets the
+// response of the first attempt instead of translating the texts again.
+//
+// Each key is an item of a DynamoDB table keyed by the string attribute
+// "key". The first request claims the key with a conditional write and
+// stores its response when it succeeds; a failed attempt releases the claim
+// so the next retry runs again. Like the job journal, a claim is held for a
+// lease that a retry takes over once it expires (see package lease). Items
+// expire through the "expiresAt" TTL attribute after Retention.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/lease"
+)
+
+// TableEnv names the DynamoDB table holding the idempotency keys.
+const TableEnv = "IDEMPOTENCY_TABLE"
+
+// Lease is how long a claim is held before a retry may take the key over.
+// It matches the maximum Lambda timeout, so a live request never loses its
+// claim.
+const Lease = 15 * time.Minute
+
+// Retention is how long keys and their responses are kept.
+const Retention = 24 * time.Hour
+
+// MaxResponseSize is the largest response stored, leaving room for the
+// other attributes under the 400 KB DynamoDB item limit.
+const MaxResponseSize = 350 << 10
+
+// Key statuses.
+const (
+	StatusInProgress = lease.StatusInProgress
+	StatusCompleted  = lease.StatusCompleted
+)
+
+// Errors of Begin.
+var (
+	ErrInFlight = errors.New("a request with this idempotency key is in progress")
+	ErrMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// ErrLeaseLost is returned when a claim was taken over before it was
+// completed or released.
+var ErrLeaseLost = errors.New("idempotency key lease lost to another request")
+
+// ErrTooLarge is returned by Complete for responses over MaxResponseSize.
+var ErrTooLarge = errors.New("response too large to store")
+
+// Table is the subset of the DynamoDB client used by the store.
+type Table = lease.Table
+
+// Record is the stored state of an idempotency key.
+type Record struct {
+	Key string
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	lease.Claim
+	// Response is the stored response of a completed request.
+	Response []byte
+}
+
+// Store claims idempotency keys and keeps their responses.
+type Store struct {
+	items *lease.Items
+}
+
+// New creates a Store for the named table.
+func New(table Table, name string) *Store {
+	return &Store{items: lease.New(table, name, "key", Lease, Retention)}
+}
+
+// Get returns the record of a key, or nil when it was never claimed.
+func (s *Store) Get(ctx context.Context, key string) (*Record, error) {
+	item, err := s.items.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency key %s: %w", key, err)
+	}
+	if item == nil {
+		return nil, nil
+	}
+	return fromItem(item)
+}
+
+// Begin claims key for the request with fingerprint. When a request with
+// the same fingerprint already completed, it returns that record, whose
+// Status is StatusCompleted, without claiming the key. It returns
+// ErrMismatch when the key is held or completed for another fingerprint and
+// ErrInFlight while another claim holds it.
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Record, error) {
+	prev, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && (prev.Status == StatusCompleted || prev.InFlight(s.items.Now())) {
+		switch {
+		case prev.Fingerprint != fingerprint:
+			return nil, ErrMismatch
+		case prev.Status == StatusCompleted:
+			return prev, nil
+		default:
+			return nil, ErrInFlight
+		}
+	}
+
+	claim, err := s.items.Claim()
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{Key: key, Fingerprint: fingerprint, Claim: claim}
+	expected := ""
+	if prev != nil {
+		// Take over the expired or released claim, unless another retry
+		// got there first
+		expected = prev.Owner
+	}
+	if err := s.put(ctx, rec, expected); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			return nil, ErrInFlight
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Complete stores the response of a claimed key.
+func (s *Store) Complete(ctx context.Context, rec *Record, response []byte) error {
+	if len(response) > MaxResponseSize {
+		return fmt.Errorf("%w: %d bytes", ErrTooLarge, len(response))
+	}
+	done := *rec
+	done.Claim = s.items.Completed(rec.Claim)
+	done.Response = response
+	if err := s.put(ctx, &done, rec.Owner); err != nil {
+		return err
+	}
+	*rec = done
+	return nil
+}
+
+// Release gives up a claim after a failed request, so a retry runs again
+// without waiting for the lease to expire.
+func (s *Store) Release(ctx context.Context, rec *Record) error {
+	released := *rec
+	released.Claim = s.items.Released(rec.Claim)
+	return s.put(ctx, &released, rec.Owner)
+}
+
+// put writes rec if owner holds the key, or if it is new for owner "".
+func (s *Store) put(ctx context.Context, rec *Record, owner string) error {
+	err := s.items.Put(ctx, item(rec), rec.Claim, owner)
+	if errors.Is(err, lease.ErrLost) {
+		return ErrLeaseLost
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write idempotency key %s: %w", rec.Key, err)
+	}
+	return nil
+}
+
+// item converts the key attributes of a record to a DynamoDB item.
+func item(rec *Record) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"key":         &types.AttributeValueMemberS{Value: rec.Key},
+		"fingerprint": &types.AttributeValueMemberS{Value: rec.Fingerprint},
+	}
+	if rec.Response != nil {
+		item["response"] = &types.AttributeValueMemberB{Value: rec.Response}
+	}
+	return item
+}
+
+// fromItem converts a DynamoDB item to a record.
+func fromItem(item map[string]types.AttributeValue) (*Record, error) {
+	rec := &Record{Key: lease.String(item, "key"), Fingerprint: lease.String(item, "fingerprint")}
+	var err error
+	if rec.Claim, err = lease.ClaimOf(item); err != nil {
+		return nil, fmt.Errorf("idempotency %w", err)
+	}
+	if v, ok := item["response"].(*types.AttributeValueMemberB); ok {
+		rec.Response = v.Value
+	}
+	return rec, nil
+}
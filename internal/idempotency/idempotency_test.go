@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "key" that honours the store
+// write conditions.
+type memoryTable struct {
+	items map[string]map[string]types.AttributeValue
+	// beforePut runs before each conditional write, to simulate races.
+	beforePut func()
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func (m *memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m.items[stringAttr(in.Key, "key")]}, nil
+}
+
+func (m *memoryTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if m.beforePut != nil {
+		m.beforePut()
+	}
+	key := stringAttr(in.Item, "key")
+	old, exists := m.items[key]
+	switch owner, ok := in.ExpressionAttributeValues[":owner"]; {
+	case !ok:
+		if exists {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	case !exists || stringAttr(old, "owner") != owner.(*types.AttributeValueMemberS).Value:
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	m.items[key] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestStore_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := New(newMemoryTable(), "keys")
+	s.items.Now = func() time.Time { return now }
+
+	rec, err := s.Begin(ctx, "t1#key-1", "fp")
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if rec.Status != StatusInProgress || rec.Owner == "" {
+		t.Errorf("Begin() = %+v", rec)
+	}
+
+	if _, err := s.Begin(ctx, "t1#key-1", "fp"); !errors.Is(err, ErrInFlight) {
+		t.Errorf("Begin() while claimed error = %v, want ErrInFlight", err)
+	}
+	if _, err := s.Begin(ctx, "t1#key-1", "other"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Begin() other request while claimed error = %v, want ErrMismatch", err)
+	}
+
+	response := []byte(`{"translations":["hola"]}`)
+	if err := s.Complete(ctx, rec, response); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	done, err := s.Begin(ctx, "t1#key-1", "fp")
+	if err != nil {
+		t.Fatalf("Begin() after completion error = %v", err)
+	}
+	if done.Status != StatusCompleted || !bytes.Equal(done.Response, response) {
+		t.Errorf("Begin() after completion = %+v", done)
+	}
+	if _, err := s.Begin(ctx, "t1#key-1", "other"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Begin() other request after completion error = %v, want ErrMismatch", err)
+	}
+	if missing, err := s.Get(ctx, "t1#key-2"); missing != nil || err != nil {
+		t.Errorf("Get() unknown key = %+v, %v", missing, err)
+	}
+}
+
+func TestStore_Retry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := New(newMemoryTable(), "keys")
+	s.items.Now = func() time.Time { return now }
+
+	tests := []struct {
+		name string
+		// fail ends the first attempt and returns the time of the retry
+		fail        func(*Record) time.Time
+		fingerprint string
+	}{
+		{"released", func(rec *Record) time.Time {
+			if err := s.Release(ctx, rec); err != nil {
+				t.Fatal(err)
+			}
+			return now
+		}, "fp"},
+		{"released then reused", func(rec *Record) time.Time {
+			if err := s.Release(ctx, rec); err != nil {
+				t.Fatal(err)
+			}
+			return now
+		}, "other"},
+		{"lease expired", func(*Record) time.Time { return now.Add(Lease + time.Second) }, "fp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := "key-" + tt.name
+			first, err := s.Begin(ctx, key, "fp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			retryAt := tt.fail(first)
+
+			s.items.Now = func() time.Time { return retryAt }
+			defer func() { s.items.Now = func() time.Time { return now } }()
+			second, err := s.Begin(ctx, key, tt.fingerprint)
+			if err != nil {
+				t.Fatalf("Begin() retry error = %v", err)
+			}
+			if second.Owner == first.Owner || second.Fingerprint != tt.fingerprint {
+				t.Errorf("Begin() retry = %+v", second)
+			}
+			if err := s.Complete(ctx, first, []byte("{}")); !errors.Is(err, ErrLeaseLost) {
+				t.Errorf("Complete() with a stale claim error = %v, want ErrLeaseLost", err)
+			}
+		})
+	}
+}
+
+func TestStore_CompleteTooLarge(t *testing.T) {
+	ctx := context.Background()
+	s := New(newMemoryTable(), "keys")
+	rec, err := s.Begin(ctx, "key-1", "fp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Complete(ctx, rec, make([]byte, MaxResponseSize+1)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Complete() error = %v, want ErrTooLarge", err)
+	}
+	if rec.Status != StatusInProgress {
+		t.Errorf("Complete() changed the claim to %q", rec.Status)
+	}
+}
+
+func TestStore_BeginRace(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable()
+	s := New(table, "keys")
+
+	// A concurrent retry claims the key between our read and our write
+	table.beforePut = func() {
+		table.beforePut = nil
+		if _, err := s.Begin(ctx, "key-1", "fp"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Begin(ctx, "key-1", "fp"); !errors.Is(err, ErrInFlight) {
+		t.Errorf("Begin() losing a race error = %v, want ErrInFlight", err)
+	}
+}
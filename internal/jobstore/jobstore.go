@@ -0,0 +1,150 @@
+// Package jobstore keeps the state of async and batch jobs in a DynamoDB
+// table, from submission to completion, for status lookups.
+//
+// Each job is an item keyed by the string attribute "id", the job ID. Only
+// the submitter and then the worker holding the job write it. Items expire
+// through the "expiresAt" TTL attribute after Retention.
+package jobstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table holding job states.
+const TableEnv = "JOBS_TABLE"
+
+// Retention is how long job items are kept after their last update.
+const Retention = 14 * 24 * time.Hour
+
+// Job states.
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// Job is the state of a job.
+type Job struct {
+	ID    string
+	State string
+	// ChunksDone of ChunksTotal chunks are translated; both are 0 until the
+	// job has chunked its texts.
+	ChunksDone  int
+	ChunksTotal int
+	// ErrorCode and Error describe why a job failed.
+	ErrorCode string
+	Error     string
+	// Output is where the result is written: the stored response of an
+	// async job, or the output prefix of a batch.
+	Output    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Table is the subset of the DynamoDB client used by the store.
+type Table interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// Store reads and writes job states.
+type Store struct {
+	table Table
+	name  string
+	now   func() time.Time
+}
+
+// New creates a Store for the named table.
+func New(table Table, name string) *Store {
+	return &Store{table: table, name: name, now: time.Now}
+}
+
+// Get returns the state of a job, or nil when it is unknown.
+func (s *Store) Get(ctx context.Context, id string) (*Job, error) {
+	consistent := true
+	out, err := s.table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.name,
+		Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+		ConsistentRead: &consistent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state of job %s: %w", id, err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	return fromItem(out.Item)
+}
+
+// Put saves job, stamping its update time and, for a new job, its creation
+// time.
+func (s *Store) Put(ctx context.Context, job *Job) error {
+	job.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = job.UpdatedAt
+	}
+	_, err := s.table.PutItem(ctx, &dynamodb.PutItemInput{TableName: &s.name, Item: item(job)})
+	if err != nil {
+		return fmt.Errorf("failed to write state of job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// item converts a job to a DynamoDB item. Times are Unix seconds.
+func item(job *Job) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"id":          &types.AttributeValueMemberS{Value: job.ID},
+		"state":       &types.AttributeValueMemberS{Value: job.State},
+		"chunksDone":  number(int64(job.ChunksDone)),
+		"chunksTotal": number(int64(job.ChunksTotal)),
+		"createdAt":   number(job.CreatedAt.Unix()),
+		"updatedAt":   number(job.UpdatedAt.Unix()),
+		"expiresAt":   number(job.UpdatedAt.Add(Retention).Unix()),
+	}
+	texts := map[string]string{"errorCode": job.ErrorCode, "error": job.Error, "output": job.Output}
+	for name, value := range texts {
+		if value != "" {
+			item[name] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+	return item
+}
+
+// fromItem converts a DynamoDB item to a job.
+func fromItem(item map[string]types.AttributeValue) (*Job, error) {
+	job := &Job{}
+	texts := map[string]*string{"id": &job.ID, "state": &job.State, "errorCode": &job.ErrorCode, "error": &job.Error, "output": &job.Output}
+	for name, dst := range texts {
+		if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+			*dst = v.Value
+		}
+	}
+	var done, total, created, updated int64
+	numbers := map[string]*int64{"chunksDone": &done, "chunksTotal": &total, "createdAt": &created, "updatedAt": &updated}
+	for name, dst := range numbers {
+		v, ok := item[name].(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("job attribute %s: %w", name, err)
+		}
+		*dst = n
+	}
+	job.ChunksDone, job.ChunksTotal = int(done), int(total)
+	job.CreatedAt = time.Unix(created, 0).UTC()
+	job.UpdatedAt = time.Unix(updated, 0).UTC()
+	return job, nil
+}
+
+func number(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
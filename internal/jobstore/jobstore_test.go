@@ -0,0 +1,63 @@
+package jobstore
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "id".
+type memoryTable map[string]map[string]types.AttributeValue
+
+func (m memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m[in.Key["id"].(*types.AttributeValueMemberS).Value]}, nil
+}
+
+func (m memoryTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m[in.Item["id"].(*types.AttributeValueMemberS).Value] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestStore(t *testing.T) {
+	table := memoryTable{}
+	s := New(table, "jobs")
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if job, err := s.Get(ctx, "job-1"); err != nil || job != nil {
+		t.Fatalf("Get() of an unknown job = %+v, %v, want nil", job, err)
+	}
+
+	job := &Job{ID: "job-1", State: StatePending, Output: "s3://bucket/jobs/job-1.json"}
+	if err := s.Put(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	job.State, job.ChunksDone, job.ChunksTotal = StateFailed, 2, 5
+	job.ErrorCode, job.Error = "TRANSLATION_FAILED", "translator down"
+	if err := s.Put(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get(ctx, "job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Job{
+		ID: "job-1", State: StateFailed, ChunksDone: 2, ChunksTotal: 5,
+		ErrorCode: "TRANSLATION_FAILED", Error: "translator down", Output: "s3://bucket/jobs/job-1.json",
+		CreatedAt: now.Add(-time.Minute), UpdatedAt: now,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if expires, want := table["job-1"]["expiresAt"].(*types.AttributeValueMemberN).Value, strconv.FormatInt(now.Add(Retention).Unix(), 10); expires != want {
+		t.Errorf("expiresAt = %s, want %s", expires, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Package journal records which async jobs have been processed, so a job
+// delivered more than once (Lambda event retries, SQS redeliveries and
+// redrives) is processed exactly once.
+//
+// Each job is an item of a DynamoDB table keyed by the string attribute
+// "id". A worker claims a job with a conditional write before processing it
+// and marks it completed afterwards. A claim is held for a lease; a worker
+// that dies mid-job leaves an expired lease that the next delivery takes
+// over (see package lease). Items expire through the "expiresAt" TTL
+// attribute after Retention.
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/lease"
+)
+
+// TableEnv names the DynamoDB table holding the journal.
+const TableEnv = "JOURNAL_TABLE"
+
+// Lease is how long a claim is held before another delivery may take the
+// job over. It matches the maximum Lambda timeout, so a live worker never
+// loses its claim.
+const Lease = 15 * time.Minute
+
+// Retention is how long journal items are kept, bounding how late a
+// redrive can still be recognised as a duplicate.
+const Retention = 14 * 24 * time.Hour
+
+// Job statuses.
+const (
+	StatusInProgress = lease.StatusInProgress
+	StatusCompleted  = lease.StatusCompleted
+)
+
+// Errors of Begin.
+var (
+	ErrCompleted = errors.New("job already completed")
+	ErrInFlight  = errors.New("job is being processed by another worker")
+)
+
+// ErrLeaseLost is returned when a claim was taken over before it was
+// completed or released.
+var ErrLeaseLost = errors.New("job lease lost to another worker")
+
+// Table is the subset of the DynamoDB client used by the journal.
+type Table = lease.Table
+
+// Record is the journal entry of a job.
+type Record struct {
+	ID string
+	lease.Claim
+	Attempts    int
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Journal claims and completes jobs.
+type Journal struct {
+	items *lease.Items
+}
+
+// New creates a Journal for the named table.
+func New(table Table, name string) *Journal {
+	return &Journal{items: lease.New(table, name, "id", Lease, Retention)}
+}
+
+// Get returns the record of a job, or nil when it was never claimed.
+func (j *Journal) Get(ctx context.Context, id string) (*Record, error) {
+	item, err := j.items.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal of job %s: %w", id, err)
+	}
+	if item == nil {
+		return nil, nil
+	}
+	return fromItem(item)
+}
+
+// Begin claims a job for processing. It returns ErrCompleted when the job
+// was already processed and ErrInFlight while another claim holds it.
+func (j *Journal) Begin(ctx context.Context, id string) (*Record, error) {
+	prev, err := j.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := j.items.Now()
+	if prev != nil && prev.Status == StatusCompleted {
+		return nil, ErrCompleted
+	}
+	if prev != nil && prev.InFlight(now) {
+		return nil, ErrInFlight
+	}
+
+	claim, err := j.items.Claim()
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{ID: id, Claim: claim, Attempts: 1, StartedAt: now}
+	expected := ""
+	if prev != nil {
+		// Take over the expired claim, unless another delivery got there first
+		rec.Attempts = prev.Attempts + 1
+		expected = prev.Owner
+	}
+	if err := j.put(ctx, rec, expected); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			return nil, ErrInFlight
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Complete marks a claimed job as processed.
+func (j *Journal) Complete(ctx context.Context, rec *Record) error {
+	done := *rec
+	done.Claim = j.items.Completed(rec.Claim)
+	done.CompletedAt = j.items.Now()
+	if err := j.put(ctx, &done, rec.Owner); err != nil {
+		return err
+	}
+	*rec = done
+	return nil
+}
+
+// Release gives up a claim after a failed attempt, so the next delivery can
+// retry the job without waiting for the lease to expire.
+func (j *Journal) Release(ctx context.Context, rec *Record) error {
+	released := *rec
+	released.Claim = j.items.Released(rec.Claim)
+	return j.put(ctx, &released, rec.Owner)
+}
+
+// put writes rec if owner holds the job, or if it is new for owner "".
+func (j *Journal) put(ctx context.Context, rec *Record, owner string) error {
+	err := j.items.Put(ctx, item(rec), rec.Claim, owner)
+	if errors.Is(err, lease.ErrLost) {
+		return ErrLeaseLost
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write journal of job %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+// item converts the job attributes of a record to a DynamoDB item. Times
+// are Unix seconds.
+func item(rec *Record) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"id":        &types.AttributeValueMemberS{Value: rec.ID},
+		"attempts":  lease.Number(int64(rec.Attempts)),
+		"startedAt": lease.Number(rec.StartedAt.Unix()),
+	}
+	if !rec.CompletedAt.IsZero() {
+		item["completedAt"] = lease.Number(rec.CompletedAt.Unix())
+	}
+	return item
+}
+
+// fromItem converts a DynamoDB item to a record.
+func fromItem(item map[string]types.AttributeValue) (*Record, error) {
+	rec := &Record{ID: lease.String(item, "id")}
+	var err error
+	if rec.Claim, err = lease.ClaimOf(item); err != nil {
+		return nil, fmt.Errorf("journal %w", err)
+	}
+	attempts, err := lease.Integer(item, "attempts")
+	if err != nil {
+		return nil, fmt.Errorf("journal %w", err)
+	}
+	rec.Attempts = int(attempts)
+	times := map[string]*time.Time{"startedAt": &rec.StartedAt, "completedAt": &rec.CompletedAt}
+	for name, dst := range times {
+		if *dst, err = lease.Time(item, name); err != nil {
+			return nil, fmt.Errorf("journal %w", err)
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "id" that honours the journal
+// write conditions.
+type memoryTable struct {
+	items map[string]map[string]types.AttributeValue
+	// beforePut runs before each conditional write, to simulate races.
+	beforePut func()
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func (m *memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m.items[stringAttr(in.Key, "id")]}, nil
+}
+
+func (m *memoryTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if m.beforePut != nil {
+		m.beforePut()
+	}
+	id := stringAttr(in.Item, "id")
+	old, exists := m.items[id]
+	switch owner, ok := in.ExpressionAttributeValues[":owner"]; {
+	case !ok:
+		if exists {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	case !exists || stringAttr(old, "owner") != owner.(*types.AttributeValueMemberS).Value:
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	m.items[id] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestJournal_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	j := New(newMemoryTable(), "journal")
+	j.items.Now = func() time.Time { return now }
+
+	rec, err := j.Begin(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if rec.Status != StatusInProgress || rec.Attempts != 1 || rec.Owner == "" {
+		t.Errorf("Begin() = %+v", rec)
+	}
+
+	if _, err := j.Begin(ctx, "job-1"); !errors.Is(err, ErrInFlight) {
+		t.Errorf("Begin() while claimed error = %v, want ErrInFlight", err)
+	}
+
+	if err := j.Complete(ctx, rec); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if _, err := j.Begin(ctx, "job-1"); !errors.Is(err, ErrCompleted) {
+		t.Errorf("Begin() after completion error = %v, want ErrCompleted", err)
+	}
+
+	got, err := j.Get(ctx, "job-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusCompleted || !got.CompletedAt.Equal(now) || got.InFlight(now) {
+		t.Errorf("Get() = %+v", got)
+	}
+	if missing, err := j.Get(ctx, "job-2"); missing != nil || err != nil {
+		t.Errorf("Get() unknown job = %+v, %v", missing, err)
+	}
+}
+
+func TestJournal_Retry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	j := New(newMemoryTable(), "journal")
+	j.items.Now = func() time.Time { return now }
+
+	tests := []struct {
+		name string
+		// fail ends the first attempt and returns the time of the retry
+		fail func(*Record) time.Time
+	}{
+		{"released", func(rec *Record) time.Time {
+			if err := j.Release(ctx, rec); err != nil {
+				t.Fatal(err)
+			}
+			return now
+		}},
+		{"lease expired", func(*Record) time.Time { return now.Add(Lease + time.Second) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := "job-" + tt.name
+			first, err := j.Begin(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			retryAt := tt.fail(first)
+
+			j.items.Now = func() time.Time { return retryAt }
+			defer func() { j.items.Now = func() time.Time { return now } }()
+			second, err := j.Begin(ctx, id)
+			if err != nil {
+				t.Fatalf("Begin() retry error = %v", err)
+			}
+			if second.Attempts != 2 || second.Owner == first.Owner {
+				t.Errorf("Begin() retry = %+v", second)
+			}
+			if err := j.Complete(ctx, first); !errors.Is(err, ErrLeaseLost) {
+				t.Errorf("Complete() with a stale claim error = %v, want ErrLeaseLost", err)
+			}
+		})
+	}
+}
+
+func TestJournal_BeginRace(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable()
+	j := New(table, "journal")
+
+	// Another delivery claims the job between our read and our write
+	table.beforePut = func() {
+		table.beforePut = nil
+		if _, err := j.Begin(ctx, "job-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := j.Begin(ctx, "job-1"); !errors.Is(err, ErrInFlight) {
+		t.Errorf("Begin() losing a race error = %v, want ErrInFlight", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package keywords expands translated product titles into search keywords
+// for the target language: the title's content words, their singular and
+// plural forms, and synonyms from a configurable glossary.
+package keywords
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ConfigEnv names the environment variable holding the glossary as JSON
+// (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "KEYWORD_GLOSSARY"
+
+// MaxKeywords caps the keywords returned for one title.
+const MaxKeywords = 50
+
+// Glossary maps a language to its synonym groups. Every term of a group
+// expands to the other terms; terms may be phrases such as "teléfono
+// móvil". Languages match exactly or by base language: groups for "es"
+// also apply to "es_MX".
+type Glossary map[string][][]string
+
+// Expander expands titles into keywords.
+type Expander struct {
+	groups map[string][][][]string // language → group → term → words
+}
+
+// New validates and indexes a glossary.
+func New(g Glossary) (*Expander, error) {
+	e := &Expander{groups: map[string][][][]string{}}
+	for lang, groups := range g {
+		for i, group := range groups {
+			if len(group) < 2 {
+				return nil, fmt.Errorf("%s group %d: needs at least two terms", lang, i)
+			}
+			terms := make([][]string, 0, len(group))
+			for _, term := range group {
+				words := tokenize(term)
+				if len(words) == 0 {
+					return nil, fmt.Errorf("%s group %d: empty term", lang, i)
+				}
+				terms = append(terms, words)
+			}
+			e.groups[lang] = append(e.groups[lang], terms)
+		}
+	}
+	return e, nil
+}
+
+// Load builds an Expander from the KEYWORD_GLOSSARY config. Without config
+// it returns an Expander with morphological expansion only.
+func Load() (*Expander, error) {
+	var g Glossary
+	if _, err := config.LoadJSON(ConfigEnv, &g); err != nil {
+		return nil, err
+	}
+	return New(g)
+}
+
+// Expand returns the keywords of title in lang, most specific first: the
+// title's content words with their inflections, then glossary synonyms.
+func (e *Expander) Expand(lang, title string) []string {
+	base, _, _ := strings.Cut(lang, "_")
+	words := tokenize(title)
+
+	var out keywordSet
+	forms := make([]map[string]bool, len(words))
+	for i, w := range words {
+		forms[i] = map[string]bool{w: true}
+		inflections := inflect(base, w)
+		for _, f := range inflections {
+			forms[i][f] = true
+		}
+		if stopwords[base][w] || len([]rune(w)) < 2 {
+			continue
+		}
+		out.add(w)
+		for _, f := range inflections {
+			out.add(f)
+		}
+	}
+
+	if e != nil {
+		for _, group := range e.groupsFor(lang, base) {
+			if !groupMatches(group, forms) {
+				continue
+			}
+			for _, term := range group {
+				out.add(strings.Join(term, " "))
+			}
+		}
+	}
+
+	if len(out.list) > MaxKeywords {
+		return out.list[:MaxKeywords]
+	}
+	return out.list
+}
+
+// groupsFor returns the synonym groups of lang and of its base language.
+func (e *Expander) groupsFor(lang, base string) [][][]string {
+	if base == lang {
+		return e.groups[lang]
+	}
+	return append(e.groups[lang][:len(e.groups[lang]):len(e.groups[lang])], e.groups[base]...)
+}
+
+// groupMatches reports whether a term of group occurs in the title, word
+// for word with each word in either number.
+func groupMatches(group [][]string, forms []map[string]bool) bool {
+	for _, term := range group {
+		if containsPhrase(forms, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPhrase reports whether consecutive title words have the forms of
+// the phrase words.
+func containsPhrase(forms []map[string]bool, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(forms); i++ {
+		match := true
+		for j, p := range phrase {
+			if !forms[i+j][p] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize lowercases s and splits it into words of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// keywordSet is an ordered set of keywords.
+type keywordSet struct {
+	list []string
+	seen map[string]bool
+}
+
+func (s *keywordSet) add(k string) {
+	if s.seen == nil {
+		s.seen = map[string]bool{}
+	}
+	if k == "" || s.seen[k] {
+		return
+	}
+	s.seen[k] = true
+	s.list = append(s.list, k)
+}
@@ -0,0 +1,101 @@
+package keywords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInflect(t *testing.T) {
+	tests := []struct {
+		lang, word string
+		want       []string
+	}{
+		{"es", "mesa", []string{"mesas"}},
+		{"es", "mesas", []string{"mesa"}},
+		{"es", "móvil", []string{"móviles"}},
+		{"es", "móviles", []string{"móvil"}},
+		{"es", "camión", []string{"camiones"}},
+		{"es", "luces", []string{"luz"}},
+		{"pt", "televisão", []string{"televisões"}},
+		{"pt", "anéis", []string{"anel"}},
+		{"it", "sedia", []string{"sedie"}},
+		{"it", "telefoni", []string{"telefono"}},
+		{"fr", "bateau", []string{"bateaux"}},
+		{"fr", "chevaux", []string{"cheval"}},
+		{"fr", "prix", nil},
+		{"en", "battery", []string{"batteries"}},
+		{"en", "boxes", []string{"box"}},
+		{"en", "glass", nil},
+		{"de", "Fahrrad", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.word, func(t *testing.T) {
+			if got := inflect(tt.lang, tt.word); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inflect(%q, %q) = %q, want %q", tt.lang, tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	e, err := New(Glossary{
+		"es":    {{"celular", "teléfono móvil"}, {"portátil", "laptop"}},
+		"es_MX": {{"computadora", "ordenador"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		lang  string
+		title string
+		want  []string
+	}{
+		{
+			name:  "inflections and synonyms",
+			lang:  "es",
+			title: "Funda para celulares Samsung",
+			want:  []string{"funda", "fundas", "celulares", "celular", "samsung", "teléfono móvil"},
+		},
+		{
+			name:  "phrase term in the plural",
+			lang:  "es",
+			title: "Teléfonos móviles",
+			want:  []string{"teléfonos", "teléfono", "móviles", "móvil", "celular", "teléfono móvil"},
+		},
+		{
+			name:  "regional groups come before base ones",
+			lang:  "es_MX",
+			title: "Computadora portátil",
+			want:  []string{"computadora", "computadoras", "portátil", "portátiles", "ordenador", "laptop"},
+		},
+		{
+			name:  "no glossary for language",
+			lang:  "de",
+			title: "Das Fahrrad für Kinder",
+			want:  []string{"fahrrad", "kinder"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.Expand(tt.lang, tt.title)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand(%q, %q) = %q, want %q", tt.lang, tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, g := range []Glossary{
+		{"es": {{"móvil"}}},
+		{"es": {{"móvil", "  "}}},
+	} {
+		if _, err := New(g); err == nil {
+			t.Errorf("New(%v) should fail", g)
+		}
+	}
+}
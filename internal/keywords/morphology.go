@@ -0,0 +1,106 @@
+package keywords
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// suffixRule rewrites a word ending in from (preceded by one of the letters
+// in after, when set) to end in to.
+type suffixRule struct {
+	from, to string
+	after    string
+}
+
+// number holds the singular and plural rules of a language. Rules are
+// heuristics for regular nouns and adjectives; the first match wins.
+type number struct {
+	singular []suffixRule
+	plural   []suffixRule
+}
+
+const (
+	vowels     = "aeiouáéíóúâêôãõà"
+	consonants = "bcdfghjklmnpqrstvwxyzç"
+)
+
+// minStem is the shortest inflected form worth returning, in runes.
+const minStem = 3
+
+// numbers are the inflection rules by base language. German plurals are too
+// irregular for suffix rules and rely on the glossary.
+var numbers = map[string]number{
+	"es": {
+		singular: []suffixRule{{"ces", "z", ""}, {"iones", "ión", ""}, {"ones", "ón", ""}, {"es", "", "dlnrjy"}, {"s", "", vowels}},
+		plural:   []suffixRule{{"z", "ces", ""}, {"ión", "iones", ""}, {"ón", "ones", ""}, {"", "es", "dlnrjy"}, {"", "s", vowels}},
+	},
+	"pt": {
+		singular: []suffixRule{{"ões", "ão", ""}, {"ns", "m", ""}, {"ais", "al", ""}, {"éis", "el", ""}, {"res", "r", ""}, {"zes", "z", ""}, {"s", "", vowels}},
+		plural:   []suffixRule{{"ão", "ões", ""}, {"m", "ns", ""}, {"al", "ais", ""}, {"el", "éis", ""}, {"r", "res", ""}, {"z", "zes", ""}, {"", "s", vowels}},
+	},
+	"it": {
+		singular: []suffixRule{{"ie", "ia", ""}, {"che", "ca", ""}, {"ghe", "ga", ""}, {"i", "o", ""}},
+		plural:   []suffixRule{{"ca", "che", ""}, {"ga", "ghe", ""}, {"a", "e", ""}, {"o", "i", ""}, {"e", "i", ""}},
+	},
+	"fr": {
+		singular: []suffixRule{{"eaux", "eau", ""}, {"aux", "al", ""}, {"s", "", vowels + "bcdfghklmnpqrtv"}},
+		plural:   []suffixRule{{"eau", "eaux", ""}, {"al", "aux", ""}, {"s", "s", ""}, {"x", "x", ""}, {"z", "z", ""}, {"", "s", ""}},
+	},
+	"en": {
+		singular: []suffixRule{{"ies", "y", ""}, {"sses", "ss", ""}, {"shes", "sh", ""}, {"ches", "ch", ""}, {"xes", "x", ""}, {"ss", "ss", ""}, {"s", "", ""}},
+		plural:   []suffixRule{{"y", "ies", consonants}, {"ss", "sses", ""}, {"sh", "shes", ""}, {"ch", "ches", ""}, {"x", "xes", ""}, {"", "s", ""}},
+	},
+}
+
+// inflect returns the other grammatical number of word: its singular when
+// it looks plural, its plural otherwise.
+func inflect(lang, word string) []string {
+	n, ok := numbers[lang]
+	if !ok {
+		return nil
+	}
+	form, matched := applyRules(n.singular, word)
+	if !matched {
+		form, _ = applyRules(n.plural, word)
+	}
+	if form == "" || form == word || utf8.RuneCountInString(form) < minStem {
+		return nil
+	}
+	return []string{form}
+}
+
+// applyRules rewrites word with the first matching rule.
+func applyRules(rules []suffixRule, word string) (string, bool) {
+	for _, r := range rules {
+		stem, ok := strings.CutSuffix(word, r.from)
+		if !ok || stem == "" {
+			continue
+		}
+		if r.after != "" {
+			last, _ := utf8.DecodeLastRuneInString(stem)
+			if !strings.ContainsRune(r.after, last) {
+				continue
+			}
+		}
+		return stem + r.to, true
+	}
+	return "", false
+}
+
+// stopwords are function words that make poor search keywords.
+var stopwords = map[string]map[string]bool{
+	"es": set("el", "la", "los", "las", "un", "una", "unos", "unas", "de", "del", "y", "o", "en", "con", "sin", "para", "por", "al", "a"),
+	"pt": set("o", "a", "os", "as", "um", "uma", "de", "do", "da", "dos", "das", "e", "ou", "em", "no", "na", "com", "sem", "para", "por"),
+	"it": set("il", "lo", "la", "i", "gli", "le", "un", "uno", "una", "di", "del", "della", "e", "o", "in", "con", "senza", "per", "da"),
+	"fr": set("le", "la", "les", "un", "une", "des", "de", "du", "et", "ou", "en", "avec", "sans", "pour", "par", "au", "aux", "à"),
+	"de": set("der", "die", "das", "den", "dem", "des", "ein", "eine", "einen", "und", "oder", "in", "mit", "ohne", "für", "von", "zu"),
+	"en": set("the", "a", "an", "of", "and", "or", "in", "with", "without", "for", "by", "to", "on"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
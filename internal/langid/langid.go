@@ -0,0 +1,91 @@
+// Package langid guesses the language of marketplace text from common
+// function words and characteristic letters. It only tells the core
+// languages apart (es, fr, it, pt, de, en) and is meant for sanity checks,
+// not for routing.
+package langid
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words are frequent words that are rare in the other core languages.
+var words = map[string][]string{
+	"en": {"the", "and", "with", "for", "is", "this", "of", "to", "in", "new", "used", "good", "condition", "size", "very", "free", "shipping"},
+	"es": {"el", "los", "las", "con", "por", "del", "una", "muy", "está", "buen", "estado", "nuevo", "nueva", "talla", "y", "envío", "gratis"},
+	"fr": {"le", "les", "des", "avec", "pour", "est", "une", "très", "dans", "du", "et", "neuf", "état", "taille", "au", "livraison", "bon"},
+	"it": {"il", "gli", "della", "delle", "per", "molto", "nuovo", "ottimo", "ottime", "condizioni", "taglia", "di", "è", "spedizione", "gratuita", "un"},
+	"pt": {"o", "os", "com", "uma", "muito", "não", "em", "do", "da", "ótimo", "tamanho", "bom", "estado", "novo", "frete", "grátis", "e"},
+	"de": {"der", "die", "das", "und", "mit", "für", "ist", "ein", "eine", "sehr", "neu", "zustand", "größe", "gut", "versand", "kostenlos"},
+}
+
+// letters are characters that point to one language.
+var letters = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ç': "fr", 'œ': "fr", 'è': "fr", 'ê': "fr", 'ë': "fr", 'û': "fr", 'î': "fr",
+	'ã': "pt", 'õ': "pt",
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ì': "it", 'ò': "it",
+}
+
+// letterWeight is how many word hits a characteristic letter counts for.
+const letterWeight = 2
+
+var wordIndex = func() map[string][]string {
+	idx := map[string][]string{}
+	for lang, list := range words {
+		for _, w := range list {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// Result is the outcome of Detect.
+type Result struct {
+	// Lang is the most likely language, "" when there is no evidence.
+	Lang string
+	// Score is the evidence for Lang; Margin how much it exceeds the runner-up.
+	Score, Margin int
+}
+
+// Detect scores text against the core languages.
+func Detect(text string) Result {
+	scores := map[string]int{}
+	for _, r := range strings.ToLower(text) {
+		if lang, ok := letters[r]; ok {
+			scores[lang] += letterWeight
+		}
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		for _, lang := range wordIndex[w] {
+			scores[lang]++
+		}
+	}
+
+	var best Result
+	second := 0
+	for lang, score := range scores {
+		switch {
+		case score > best.Score || (score == best.Score && lang < best.Lang):
+			if best.Score > second {
+				second = best.Score
+			}
+			best = Result{Lang: lang, Score: score}
+		case score > second:
+			second = score
+		}
+	}
+	best.Margin = best.Score - second
+	return best
+}
+
+// Confident reports whether r is strong evidence: at least minScore hits and
+// at least twice the runner-up's.
+func (r Result) Confident(minScore int) bool {
+	return r.Lang != "" && r.Score >= minScore && r.Margin*2 >= r.Score
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && r != '\''
+}
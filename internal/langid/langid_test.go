@@ -0,0 +1,45 @@
+package langid
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Zapatillas de running en muy buen estado, talla 42 y envío gratis", "es"},
+		{"Chaussures de course en très bon état avec la boîte, livraison offerte", "fr"},
+		{"Scarpe da corsa in ottime condizioni, taglia 42, spedizione gratuita", "it"},
+		{"Tênis de corrida em muito bom estado, tamanho 42, frete grátis", "pt"},
+		{"Laufschuhe in sehr gutem Zustand, Größe 42, kostenloser Versand und mit Karton", "de"},
+		{"Running shoes in very good condition with the original box, free shipping", "en"},
+		{"iPhone 12 Pro 128GB", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := Detect(tt.text)
+			if got.Lang != tt.want {
+				t.Errorf("Detect(%q) = %+v, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResult_Confident(t *testing.T) {
+	tests := []struct {
+		r    Result
+		want bool
+	}{
+		{Result{Lang: "es", Score: 6, Margin: 5}, true},
+		{Result{Lang: "es", Score: 6, Margin: 2}, false},
+		{Result{Lang: "es", Score: 2, Margin: 2}, false},
+		{Result{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.Confident(3); got != tt.want {
+			t.Errorf("%+v.Confident(3) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package lease claims items of a DynamoDB table for a lease with
+// conditional writes, the protocol shared by the job journal and the
+// idempotency keys.
+//
+// Each item is keyed by one string attribute and carries its claim: a
+// status, the random owner of the claim and when its lease expires. The
+// first writer of an item creates it only if it does not exist; later
+// writers replace it only while they own its claim, so a claim whose lease
+// expired is taken over by exactly one writer. Items expire through the
+// "expiresAt" TTL attribute after the table's retention.
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Claim statuses.
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+)
+
+// ErrLost is returned by Put when the item was created or taken over by
+// another claim.
+var ErrLost = errors.New("lease lost to another claim")
+
+// Conditions of the writes.
+const (
+	conditionNew   = "attribute_not_exists(#key)"
+	conditionOwner = "#owner = :owner"
+)
+
+// Table is the subset of the DynamoDB client used by Items.
+type Table interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// Claim is the lease state of an item.
+type Claim struct {
+	Status string
+	// Owner identifies the claim currently or last holding the item.
+	Owner        string
+	LeaseExpires time.Time
+}
+
+// InFlight reports whether a claim holds the item unexpired.
+func (c Claim) InFlight(now time.Time) bool {
+	return c.Status == StatusInProgress && now.Before(c.LeaseExpires)
+}
+
+// Items reads and claims the items of one table.
+type Items struct {
+	table Table
+	name  string
+	// key is the name of the key attribute.
+	key       string
+	lease     time.Duration
+	retention time.Duration
+	// Now is the clock of the leases, replaceable in tests.
+	Now func() time.Time
+}
+
+// New creates Items for the named table keyed by the string attribute key,
+// whose claims hold a lease and which are kept for retention.
+func New(table Table, name, key string, lease, retention time.Duration) *Items {
+	return &Items{table: table, name: name, key: key, lease: lease, retention: retention, Now: time.Now}
+}
+
+// Get returns the item with key id, or nil when it was never written.
+func (t *Items) Get(ctx context.Context, id string) (map[string]types.AttributeValue, error) {
+	consistent := true
+	out, err := t.table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &t.name,
+		Key:            map[string]types.AttributeValue{t.key: &types.AttributeValueMemberS{Value: id}},
+		ConsistentRead: &consistent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Item, nil
+}
+
+// Claim returns a new in-progress claim whose lease starts now.
+func (t *Items) Claim() (Claim, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return Claim{}, fmt.Errorf("failed to generate lease owner: %w", err)
+	}
+	return Claim{Status: StatusInProgress, Owner: hex.EncodeToString(b), LeaseExpires: t.Now().Add(t.lease)}, nil
+}
+
+// Completed returns c completed, without a lease.
+func (t *Items) Completed(c Claim) Claim {
+	c.Status = StatusCompleted
+	c.LeaseExpires = time.Time{}
+	return c
+}
+
+// Released returns c with its lease expired, so the next claim takes the
+// item over without waiting.
+func (t *Items) Released(c Claim) Claim {
+	c.LeaseExpires = t.Now()
+	return c
+}
+
+// Put writes item with the attributes of claim and the TTL if owner holds
+// it, or, for owner "", if it does not exist yet. It returns ErrLost when
+// the condition fails.
+func (t *Items) Put(ctx context.Context, item map[string]types.AttributeValue, claim Claim, owner string) error {
+	item["status"] = &types.AttributeValueMemberS{Value: claim.Status}
+	item["owner"] = &types.AttributeValueMemberS{Value: claim.Owner}
+	item["expiresAt"] = Number(t.Now().Add(t.retention).Unix())
+	if !claim.LeaseExpires.IsZero() {
+		item["leaseExpires"] = Number(claim.LeaseExpires.Unix())
+	}
+	input := &dynamodb.PutItemInput{TableName: &t.name, Item: item}
+	if owner == "" {
+		input.ConditionExpression = strPtr(conditionNew)
+		input.ExpressionAttributeNames = map[string]string{"#key": t.key}
+	} else {
+		input.ConditionExpression = strPtr(conditionOwner)
+		input.ExpressionAttributeNames = map[string]string{"#owner": "owner"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: owner},
+		}
+	}
+	_, err := t.table.PutItem(ctx, input)
+	var conditional *types.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		return ErrLost
+	}
+	return err
+}
+
+// ClaimOf reads the claim of an item.
+func ClaimOf(item map[string]types.AttributeValue) (Claim, error) {
+	c := Claim{Status: String(item, "status"), Owner: String(item, "owner")}
+	var err error
+	c.LeaseExpires, err = Time(item, "leaseExpires")
+	return c, err
+}
+
+// String reads a string attribute, returning "" when it is absent.
+func String(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+// Integer reads a numeric attribute, returning 0 when it is absent.
+func Integer(item map[string]types.AttributeValue, name string) (int64, error) {
+	v, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("attribute %s: %w", name, err)
+	}
+	return n, nil
+}
+
+// Time reads a time stored in Unix seconds, returning the zero time when
+// it is absent.
+func Time(item map[string]types.AttributeValue, name string) (time.Time, error) {
+	sec, err := Integer(item, name)
+	if err != nil || sec == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// Number returns a numeric attribute.
+func Number(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,81 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// conditionalTable is a Table holding one item that evaluates the write
+// conditions of Put.
+type conditionalTable struct {
+	Table
+	item map[string]types.AttributeValue
+	last *dynamodb.PutItemInput
+}
+
+func (c *conditionalTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	c.last = in
+	switch *in.ConditionExpression {
+	case conditionNew:
+		if c.item != nil {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	case conditionOwner:
+		if c.item == nil || String(c.item, "owner") != in.ExpressionAttributeValues[":owner"].(*types.AttributeValueMemberS).Value {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	}
+	c.item = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestItems_Put(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	table := &conditionalTable{}
+	items := New(table, "jobs", "id", time.Minute, time.Hour)
+	items.Now = func() time.Time { return now }
+
+	claim, err := items.Claim()
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claim.Owner == "" || !claim.InFlight(now) || claim.InFlight(now.Add(time.Minute)) {
+		t.Errorf("Claim() = %+v, want an owned one-minute lease", claim)
+	}
+	item := func() map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "job-1"}}
+	}
+	if err := items.Put(ctx, item(), claim, ""); err != nil {
+		t.Fatalf("Put(new) error = %v", err)
+	}
+	if names := table.last.ExpressionAttributeNames; names["#key"] != "id" {
+		t.Errorf("Put(new) names = %v, want #key for id", names)
+	}
+	if err := items.Put(ctx, item(), claim, ""); !errors.Is(err, ErrLost) {
+		t.Errorf("Put(new) over an item error = %v, want ErrLost", err)
+	}
+	if err := items.Put(ctx, item(), claim, "someone-else"); !errors.Is(err, ErrLost) {
+		t.Errorf("Put() by another owner error = %v, want ErrLost", err)
+	}
+
+	done := items.Completed(claim)
+	if err := items.Put(ctx, item(), done, claim.Owner); err != nil {
+		t.Fatalf("Put(completed) error = %v", err)
+	}
+	got, err := ClaimOf(table.item)
+	if err != nil || got != done {
+		t.Errorf("ClaimOf() = %+v, %v, want %+v", got, err, done)
+	}
+	if expires, _ := Time(table.item, "expiresAt"); !expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("expiresAt = %v, want %v", expires, now.Add(time.Hour))
+	}
+	if released := items.Released(claim); released.InFlight(now) {
+		t.Errorf("Released() = %+v, want an expired lease", released)
+	}
+}
@@ -0,0 +1,69 @@
+package locale
+
+import "strings"
+
+// Text directions.
+const (
+	LeftToRight = "ltr"
+	RightToLeft = "rtl"
+)
+
+// Info is the rendering metadata of a canonical code.
+type Info struct {
+	// Locale is the canonical code, e.g. "pt_BR".
+	Locale string `json:"locale"`
+	// Language is its base language, e.g. "pt".
+	Language string `json:"language"`
+	// Script is the ISO 15924 code of the script the language is written in.
+	Script string `json:"script"`
+	// Direction is "ltr" or "rtl".
+	Direction string `json:"direction"`
+	// HTMLLang is the BCP 47 tag for an HTML lang attribute, e.g. "pt-BR".
+	HTMLLang string `json:"htmlLang"`
+}
+
+// scripts maps base languages to their script when it is not Latin.
+var scripts = map[string]string{
+	"ar": "Arab", "fa": "Arab", "ur": "Arab", "ps": "Arab", "sd": "Arab", "ug": "Arab", "ckb": "Arab",
+	"he": "Hebr", "yi": "Hebr", "dv": "Thaa", "syr": "Syrc",
+	"ru": "Cyrl", "uk": "Cyrl", "bg": "Cyrl", "sr": "Cyrl", "mk": "Cyrl", "be": "Cyrl", "kk": "Cyrl",
+	"ky": "Cyrl", "tg": "Cyrl", "mn": "Cyrl",
+	"el": "Grek", "hy": "Armn", "ka": "Geor", "am": "Ethi", "ti": "Ethi",
+	"hi": "Deva", "mr": "Deva", "ne": "Deva", "sa": "Deva",
+	"bn": "Beng", "as": "Beng", "pa": "Guru", "gu": "Gujr", "or": "Orya",
+	"ta": "Taml", "te": "Telu", "kn": "Knda", "ml": "Mlym", "si": "Sinh",
+	"th": "Thai", "lo": "Laoo", "km": "Khmr", "my": "Mymr",
+	"zh": "Hans", "ja": "Jpan", "ko": "Kore",
+}
+
+// regionalScripts overrides scripts for regions writing another script.
+var regionalScripts = map[string]string{
+	"zh_TW": "Hant", "zh_HK": "Hant", "zh_MO": "Hant",
+}
+
+// rtlScripts are the scripts written right to left.
+var rtlScripts = map[string]bool{"Arab": true, "Hebr": true, "Thaa": true, "Syrc": true}
+
+// Describe returns the rendering metadata of a canonical code such as
+// "es" or "pt_BR". Unlisted languages are assumed to use the Latin script.
+func Describe(code string) Info {
+	base := Base(code)
+	script, ok := regionalScripts[code]
+	if !ok {
+		script = scripts[base]
+	}
+	if script == "" {
+		script = "Latn"
+	}
+	direction := LeftToRight
+	if rtlScripts[script] {
+		direction = RightToLeft
+	}
+	return Info{
+		Locale:    code,
+		Language:  base,
+		Script:    script,
+		Direction: direction,
+		HTMLLang:  strings.ReplaceAll(code, "_", "-"),
+	}
+}
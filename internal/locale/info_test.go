@@ -0,0 +1,25 @@
+package locale
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		code string
+		want Info
+	}{
+		{"es", Info{Locale: "es", Language: "es", Script: "Latn", Direction: "ltr", HTMLLang: "es"}},
+		{"pt_BR", Info{Locale: "pt_BR", Language: "pt", Script: "Latn", Direction: "ltr", HTMLLang: "pt-BR"}},
+		{"es_419", Info{Locale: "es_419", Language: "es", Script: "Latn", Direction: "ltr", HTMLLang: "es-419"}},
+		{"ar", Info{Locale: "ar", Language: "ar", Script: "Arab", Direction: "rtl", HTMLLang: "ar"}},
+		{"he", Info{Locale: "he", Language: "he", Script: "Hebr", Direction: "rtl", HTMLLang: "he"}},
+		{"ru", Info{Locale: "ru", Language: "ru", Script: "Cyrl", Direction: "ltr", HTMLLang: "ru"}},
+		{"zh_TW", Info{Locale: "zh_TW", Language: "zh", Script: "Hant", Direction: "ltr", HTMLLang: "zh-TW"}},
+		{"scn", Info{Locale: "scn", Language: "scn", Script: "Latn", Direction: "ltr", HTMLLang: "scn"}},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.code); got != tt.want {
+			t.Errorf("Describe(%q) = %+v, want %+v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package locale maps the language names and tags callers send to the
+// canonical codes used by the router, e.g. "Spanish" → "es", "pt-br" → "pt_BR".
+package locale
+
+import (
+	"regexp"
+	"strings"
+)
+
+// aliases maps human-friendly names (lower case, words joined by "-") to
+// canonical codes. Names are given in English, the language itself and the
+// other core languages where callers commonly use them.
+var aliases = map[string]string{
+	// English
+	"english": "en", "inglés": "en", "ingles": "en", "anglais": "en", "inglese": "en", "englisch": "en",
+	// Spanish
+	"spanish": "es", "español": "es", "espanol": "es", "castellano": "es", "castilian": "es",
+	"espagnol": "es", "spagnolo": "es", "espanhol": "es", "spanisch": "es",
+	"mexican-spanish": "es_MX", "argentinian-spanish": "es_AR", "argentine-spanish": "es_AR",
+	"colombian-spanish": "es_CO", "chilean-spanish": "es_CL", "peruvian-spanish": "es_PE",
+	"european-spanish": "es_ES", "spain-spanish": "es_ES",
+	// French
+	"french": "fr", "français": "fr", "francais": "fr", "francés": "fr", "frances": "fr",
+	"francese": "fr", "francês": "fr", "französisch": "fr", "franzosisch": "fr",
+	"canadian-french": "fr_CA", "quebec-french": "fr_CA", "québécois": "fr_CA",
+	"belgian-french": "fr_BE", "european-french": "fr_FR",
+	// Italian
+	"italian": "it", "italiano": "it", "italien": "it", "italienisch": "it",
+	// Portuguese
+	"portuguese": "pt", "português": "pt", "portugues": "pt", "portugais": "pt",
+	"portoghese": "pt", "portugiesisch": "pt",
+	"brazilian-portuguese": "pt_BR", "brazilian": "pt_BR", "portuguese-brazil": "pt_BR",
+	"european-portuguese": "pt_PT", "portugal-portuguese": "pt_PT",
+	// German
+	"german": "de", "deutsch": "de", "alemán": "de", "aleman": "de", "allemand": "de",
+	"tedesco": "de", "alemão": "de", "alemao": "de",
+	// Other Romance
+	"catalan": "ca", "català": "ca", "catala": "ca", "catalán": "ca",
+	"galician": "gl", "galego": "gl", "gallego": "gl",
+	"romanian": "ro", "română": "ro", "romana": "ro", "rumano": "ro", "roumain": "ro",
+	"latin": "la", "latín": "la", "latino": "la",
+	"occitan": "oc", "corsican": "co", "corse": "co", "corso": "co",
+	"sicilian": "scn", "siciliano": "scn", "venetian": "vec", "veneto": "vec",
+	"neapolitan": "nap", "napoletano": "nap", "walloon": "wa", "wallon": "wa",
+	"aragonese": "an", "aragonés": "an", "ladino": "lad", "judeo-spanish": "lad",
+	"romansh": "rm", "rumantsch": "rm", "ladin": "lld", "friulian": "fur", "furlan": "fur",
+	"ligurian": "lij", "lombard": "lmo", "lombardo": "lmo", "sardinian": "sc", "sardu": "sc",
+	"franco-provençal": "frp", "franco-provencal": "frp", "arpitan": "frp",
+	"mirandese": "mwl", "mirandés": "mwl",
+	// Slavic
+	"polish": "pl", "polski": "pl", "polaco": "pl", "polonais": "pl", "polacco": "pl", "polnisch": "pl",
+	"czech": "cs", "čeština": "cs", "cestina": "cs", "checo": "cs", "tchèque": "cs", "tcheque": "cs",
+	"ceco": "cs", "tschechisch": "cs",
+	"ukrainian": "uk", "українська": "uk", "ucraniano": "uk", "ukrainien": "uk", "ucraino": "uk",
+	"ukrainisch": "uk",
+	"russian":    "ru", "русский": "ru", "ruso": "ru", "russe": "ru", "russo": "ru", "russisch": "ru",
+	"slovak": "sk", "slovenčina": "sk", "slovencina": "sk", "bulgarian": "bg", "български": "bg",
+	"croatian": "hr", "hrvatski": "hr", "serbian": "sr", "српски": "sr", "slovenian": "sl",
+	"slovene": "sl", "slovenščina": "sl", "bosnian": "bs", "bosanski": "bs",
+	"macedonian": "mk", "македонски": "mk", "belarusian": "be", "беларуская": "be",
+	// Nordic
+	"swedish": "sv", "svenska": "sv", "sueco": "sv", "suédois": "sv", "suedois": "sv",
+	"svedese": "sv", "schwedisch": "sv",
+	"danish": "da", "dansk": "da", "danés": "da", "danes": "da", "danois": "da",
+	"danese": "da", "dänisch": "da", "danisch": "da",
+	"norwegian": "no", "norsk": "no", "noruego": "no", "norvégien": "no", "norvegien": "no",
+	"norvegese": "no", "norwegisch": "no",
+	"bokmål": "nb", "bokmal": "nb", "norwegian-bokmål": "nb", "norwegian-bokmal": "nb",
+	"nynorsk": "nn", "norwegian-nynorsk": "nn",
+	"icelandic": "is", "íslenska": "is", "islenska": "is", "islandés": "is", "islandais": "is",
+	"islandese": "is", "isländisch": "is",
+}
+
+// tagPattern matches BCP 47 style tags with an optional region: "es", "pt-br", "fr_CA", "es-419".
+var tagPattern = regexp.MustCompile(`^([a-z]{2,3})(?:-([a-z]{2}|[0-9]{3}))?$`)
+
+// Canonicalize returns the canonical code for a language name or tag.
+// ok is false when s is neither a known alias nor a well-formed tag, in which
+// case s is returned unchanged.
+func Canonicalize(s string) (code string, ok bool) {
+	key := normalizeKey(s)
+	if code, ok := aliases[key]; ok {
+		return code, true
+	}
+	if m := tagPattern.FindStringSubmatch(key); m != nil {
+		if m[2] == "" {
+			return m[1], true
+		}
+		return m[1] + "_" + strings.ToUpper(m[2]), true
+	}
+	return s, false
+}
+
+// normalizeKey lower-cases s and joins words with "-".
+func normalizeKey(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(strings.TrimSpace(s)), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '(' || r == ')'
+	})
+	return strings.Join(fields, "-")
+}
+
+// Base returns the language of a canonical code without its region: "pt_BR" → "pt".
+func Base(code string) string {
+	if i := strings.IndexByte(code, '_'); i >= 0 {
+		return code[:i]
+	}
+	return code
+}
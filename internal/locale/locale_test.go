@@ -0,0 +1,55 @@
+package locale
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"es", "es", true},
+		{"EN", "en", true},
+		{"pt-br", "pt_BR", true},
+		{"pt_BR", "pt_BR", true},
+		{"fr-CA", "fr_CA", true},
+		{"spanish", "es", true},
+		{"Spanish", "es", true},
+		{"brazilian-portuguese", "pt_BR", true},
+		{"Brazilian Portuguese", "pt_BR", true},
+		{"Portuguese (Brazil)", "pt_BR", true},
+		{"Deutsch", "de", true},
+		{"Español", "es", true},
+		{"  french ", "fr", true},
+		{"Canadian French", "fr_CA", true},
+		{"Polish", "pl", true},
+		{"Čeština", "cs", true},
+		{"Українська", "uk", true},
+		{"Svenska", "sv", true},
+		{"Norwegian Bokmål", "nb", true},
+		{"dänisch", "da", true},
+		{"nap", "nap", true},
+		{"es-419", "es_419", true},
+		{"klingon", "klingon", false},
+		{"es-419-x", "es-419-x", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := Canonicalize(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Canonicalize(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBase(t *testing.T) {
+	tests := map[string]string{"pt_BR": "pt", "es_419": "es", "de": "de", "": ""}
+	for in, want := range tests {
+		if got := Base(in); got != want {
+			t.Errorf("Base(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
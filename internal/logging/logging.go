@@ -0,0 +1,102 @@
+// Package logging configures the structured logs of the entry points: one
+// JSON object per line on stdout, filtered by LOG_LEVEL, tagged with the
+// ID of the request being served. It is built on log/slog, and the log
+// package of the standard library writes through it once Setup has run.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// LevelEnv names the environment variable holding the minimum level
+// logged: debug, info (the default), warn or error.
+const LevelEnv = "LOG_LEVEL"
+
+// RequestIDKey is the attribute holding the request ID in every record
+// logged with a context that carries one.
+const RequestIDKey = "requestId"
+
+// Level returns the level chosen by LOG_LEVEL.
+func Level() (slog.Level, error) {
+	var level slog.Level
+	v := strings.TrimSpace(os.Getenv(LevelEnv))
+	if v == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(v)); err != nil {
+		return 0, fmt.Errorf("invalid %s %q: want debug, info, warn or error", LevelEnv, v)
+	}
+	return level, nil
+}
+
+// New returns a logger writing JSON records of level and above to out.
+func New(out io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(requestHandler{slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level})})
+}
+
+// Setup makes a JSON logger on stdout at the LOG_LEVEL level the default
+// of slog and of the log package.
+func Setup() error {
+	level, err := Level()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(New(os.Stdout, level))
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying id as the request ID of its logs; an
+// empty id leaves ctx as it is.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx: the one set by WithRequestID,
+// else the AWS request ID of a Lambda invocation, else "".
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return ""
+}
+
+// requestHandler adds the request ID of the context to records.
+type requestHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h requestHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"WARN", slog.LevelWarn, false},
+		{" error ", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(LevelEnv, tt.value)
+			got, err := Level()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Level() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		wantID string
+	}{
+		{"no request", context.Background(), ""},
+		{"request ID", WithRequestID(context.Background(), "req-1"), "req-1"},
+		{"lambda invocation", lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "aws-1"}), "aws-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := New(&out, slog.LevelInfo).With("languagePair", "es-en")
+			logger.DebugContext(tt.ctx, "hidden")
+			logger.InfoContext(tt.ctx, "translation", "chunkCount", 2)
+
+			var record map[string]any
+			if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+				t.Fatalf("want one JSON record, got %q: %v", out.String(), err)
+			}
+			if record["msg"] != "translation" || record["languagePair"] != "es-en" || record["chunkCount"] != 2.0 {
+				t.Errorf("record = %v", record)
+			}
+			if id, _ := record[RequestIDKey].(string); id != tt.wantID {
+				t.Errorf("requestId = %q, want %q", id, tt.wantID)
+			}
+		})
+	}
+}
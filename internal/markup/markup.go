@@ -0,0 +1,167 @@
+// Package markup protects the tags of lightweight markup syntaxes, such as
+// the BBCode of seller descriptions ("[b]", "[url=…]"), so they survive
+// translation intact. Tenants may define their own tag syntaxes.
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/config"
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+// ConfigEnv names the environment variable holding the tenant grammars as
+// JSON (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "MARKUP_GRAMMARS"
+
+// BBCode names the built-in BBCode grammar, available to every tenant.
+const BBCode = "bbcode"
+
+// Grammar describes a tag syntax: a tag is Open, an optional "/", a name,
+// an optional value or attributes ("=…" or " …"), and Close.
+type Grammar struct {
+	// Open and Close delimit tags; they default to "[" and "]".
+	Open  string `json:"open,omitempty"`
+	Close string `json:"close,omitempty"`
+	// Tags are the tag names, matched case-insensitively. Without tags, any
+	// name of letters and digits is a tag.
+	Tags []string `json:"tags,omitempty"`
+	// Verbatim tags keep their content untranslated too, e.g. "[code]…[/code]".
+	Verbatim []string `json:"verbatim,omitempty"`
+}
+
+var builtin = map[string]Grammar{
+	BBCode: {
+		Tags: []string{
+			"b", "i", "u", "s", "url", "img", "quote", "code", "color", "size", "font",
+			"list", "*", "center", "left", "right", "email", "youtube", "table", "tr", "td",
+		},
+		Verbatim: []string{"img", "code", "email", "youtube"},
+	},
+}
+
+// Compiled is a grammar ready to find tags.
+type Compiled struct {
+	tag      *regexp.Regexp
+	verbatim map[string]bool
+}
+
+// Set holds the built-in grammars and the grammars of each tenant.
+type Set struct {
+	builtin map[string]*Compiled
+	tenants map[string]map[string]*Compiled
+}
+
+// New compiles tenant grammars, keyed by tenant ID and then grammar name.
+// A tenant grammar named like a built-in one replaces it for the tenant.
+func New(tenants map[string]map[string]Grammar) (*Set, error) {
+	s := &Set{builtin: map[string]*Compiled{}, tenants: map[string]map[string]*Compiled{}}
+	for name, g := range builtin {
+		c, err := Compile(g)
+		if err != nil {
+			return nil, fmt.Errorf("built-in grammar %q: %w", name, err)
+		}
+		s.builtin[name] = c
+	}
+	for tenant, grammars := range tenants {
+		s.tenants[tenant] = map[string]*Compiled{}
+		for name, g := range grammars {
+			c, err := Compile(g)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q grammar %q: %w", tenant, name, err)
+			}
+			s.tenants[tenant][name] = c
+		}
+	}
+	return s, nil
+}
+
+// Load builds a Set from the MARKUP_GRAMMARS config. Without config only the
+// built-in grammars are available.
+func Load() (*Set, error) {
+	var tenants map[string]map[string]Grammar
+	if _, err := config.LoadJSON(ConfigEnv, &tenants); err != nil {
+		return nil, err
+	}
+	return New(tenants)
+}
+
+// Lookup returns the grammar called name for tenant.
+func (s *Set) Lookup(tenant, name string) (*Compiled, error) {
+	if c, ok := s.tenants[tenant][name]; ok {
+		return c, nil
+	}
+	if c, ok := s.builtin[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown markup %q", name)
+}
+
+// Compile validates a grammar and compiles its tag pattern.
+func Compile(g Grammar) (*Compiled, error) {
+	if g.Open == "" {
+		g.Open = "["
+	}
+	if g.Close == "" {
+		g.Close = "]"
+	}
+	name := `[\pL\d]+`
+	if len(g.Tags) > 0 {
+		names := make([]string, len(g.Tags))
+		for i, t := range g.Tags {
+			if t == "" || strings.ContainsAny(t, " \t\n=") {
+				return nil, fmt.Errorf("invalid tag name %q", t)
+			}
+			names[i] = regexp.QuoteMeta(t)
+		}
+		name = `(?i:` + strings.Join(names, "|") + `)`
+	}
+
+	// The value stops at the first Close on the same line: "[url=…]"
+	open, close := regexp.QuoteMeta(g.Open), regexp.QuoteMeta(g.Close)
+	pattern := open + `(/?)(` + name + `)(?:[= ][^\n]*?)?` + close
+	tag, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Compiled{tag: tag, verbatim: map[string]bool{}}
+	for _, v := range g.Verbatim {
+		c.verbatim[strings.ToLower(v)] = true
+	}
+	return c, nil
+}
+
+// Find returns the spans of text to protect, in order: every tag, and
+// verbatim tags with their content up to the matching closing tag.
+func (c *Compiled) Find(text string) []protect.Span {
+	matches := c.tag.FindAllStringSubmatchIndex(text, -1)
+	var spans []protect.Span
+	for i := 0; i < len(matches); i++ {
+		m := matches[i]
+		span := protect.Span{Start: m[0], End: m[1]}
+		closing := m[3] > m[2]
+		name := strings.ToLower(text[m[4]:m[5]])
+		if !closing && c.verbatim[name] {
+			if j := closingTag(text, matches[i+1:], name); j >= 0 {
+				i += j + 1
+				span.End = matches[i][1]
+			}
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// closingTag returns the index in matches of the first closing tag called
+// name, or -1.
+func closingTag(text string, matches [][]int, name string) int {
+	for j, m := range matches {
+		if m[3] > m[2] && strings.EqualFold(text[m[4]:m[5]], name) {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+package markup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name    string
+		grammar string
+		text    string
+		want    []string
+	}{
+		{"simple tags", BBCode, "Envío [b]gratis[/b] hoy", []string{"[b]", "[/b]"}},
+		{"case-insensitive", BBCode, "[B]Nuevo[/B]", []string{"[B]", "[/B]"}},
+		{"value", BBCode, "Ver [url=https://example.com/a?b=1]la tienda[/url].", []string{"[url=https://example.com/a?b=1]", "[/url]"}},
+		{"attributes", BBCode, "[quote author=Ana]Muy bueno[/quote]", []string{"[quote author=Ana]", "[/quote]"}},
+		{"list items", BBCode, "[list][*]Uno[*]Dos[/list]", []string{"[list]", "[*]", "[*]", "[/list]"}},
+		{"verbatim content", BBCode, "Foto: [img]https://x.test/a.jpg[/img] nueva", []string{"[img]https://x.test/a.jpg[/img]"}},
+		{"unclosed verbatim", BBCode, "[code]a = b", []string{"[code]"}},
+		{"unknown tag", BBCode, "Talla [M] y [b]rojo[/b]", []string{"[b]", "[/b]"}},
+		{"no markup", BBCode, "Zapatillas talla 42", nil},
+		{"custom delimiters", "custom", "{{negrita}}Hola{{/negrita}} [b]", []string{"{{negrita}}", "{{/negrita}}"}},
+		{"any name", "anytag", "<<x1>>Hola<</x1>> <<z>>", []string{"<<x1>>", "<</x1>>", "<<z>>"}},
+	}
+
+	set, err := New(map[string]map[string]Grammar{"acme": {
+		"custom": {Open: "{{", Close: "}}", Tags: []string{"negrita"}},
+		"anytag": {Open: "<<", Close: ">>"},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := set.Lookup("acme", tt.grammar)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			var got []string
+			for _, s := range g.Find(tt.text) {
+				got = append(got, tt.text[s.Start:s.End])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	set, err := New(map[string]map[string]Grammar{"acme": {BBCode: {Tags: []string{"neg"}}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := set.Lookup("other", BBCode); err != nil {
+		t.Errorf("built-in grammar unavailable to other tenants: %v", err)
+	}
+	if _, err := set.Lookup("other", "custom"); err == nil {
+		t.Error("Lookup() of an unknown grammar should fail")
+	}
+	g, _ := set.Lookup("acme", BBCode)
+	if spans := g.Find("[b]x[/b] [neg]y[/neg]"); len(spans) != 2 {
+		t.Errorf("tenant grammar should replace the built-in one, found %d tags", len(spans))
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(map[string]map[string]Grammar{"acme": {"x": {Tags: []string{"a b"}}}}); err == nil {
+		t.Error("New() should reject tag names with spaces")
+	}
+}
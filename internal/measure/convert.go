@@ -0,0 +1,69 @@
+package measure
+
+import "strings"
+
+// conversion converts a unit to the other system: value*factor+offset.
+type conversion struct {
+	to     string
+	factor float64
+	offset float64
+}
+
+// conversions are keyed by the system converted to, then canonical unit.
+var conversions = map[System]map[string]conversion{
+	Metric: {
+		"in":    {to: "cm", factor: 2.54},
+		"ft":    {to: "m", factor: 0.3048},
+		"yd":    {to: "m", factor: 0.9144},
+		"mi":    {to: "km", factor: 1.609344},
+		"oz":    {to: "g", factor: 28.349523},
+		"lb":    {to: "kg", factor: 0.45359237},
+		"fl oz": {to: "ml", factor: 29.573530},
+		"gal":   {to: "l", factor: 3.785412},
+		"°f":    {to: "°C", factor: 5.0 / 9, offset: -32 * 5.0 / 9},
+		"mph":   {to: "km/h", factor: 1.609344},
+	},
+	Imperial: {
+		"mm":   {to: "in", factor: 1 / 25.4},
+		"cm":   {to: "in", factor: 1 / 2.54},
+		"m":    {to: "ft", factor: 1 / 0.3048},
+		"km":   {to: "mi", factor: 1 / 1.609344},
+		"g":    {to: "oz", factor: 1 / 28.349523},
+		"kg":   {to: "lb", factor: 1 / 0.45359237},
+		"ml":   {to: "fl oz", factor: 1 / 29.573530},
+		"cl":   {to: "fl oz", factor: 10 / 29.573530},
+		"l":    {to: "gal", factor: 1 / 3.785412},
+		"°c":   {to: "°F", factor: 9.0 / 5, offset: 32},
+		"km/h": {to: "mph", factor: 1 / 1.609344},
+	},
+}
+
+// Convert renders m in system, in the number formatting of target (a base
+// language). Units that are already in system, or that both systems share
+// (GB, W, mAh), are only localized.
+func Convert(m Measurement, system System, source, target string) string {
+	c, ok := conversions[system][m.Unit]
+	if !ok {
+		return Localize(m, source, target)
+	}
+
+	parts := make([]string, len(m.Values))
+	for i, v := range m.Values {
+		r, decimals := round(v*c.factor + c.offset)
+		parts[i] = FormatNumber(r, decimals, target)
+	}
+	separator := " x "
+	if strings.Contains(m.Text, "×") {
+		separator = " × "
+	}
+	return strings.Join(parts, separator) + " " + c.to
+}
+
+// MarketSystem returns the system of units customary for a base language:
+// imperial for English, metric for every other supported language.
+func MarketSystem(lang string) System {
+	if lang == "en" {
+		return Imperial
+	}
+	return Metric
+}
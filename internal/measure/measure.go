@@ -0,0 +1,262 @@
+// Package measure recognises measurement expressions in product texts
+// ("2.5 kg", "32GB", "5 ft 4 in", "30 x 40 cm") and renders them for a
+// target locale: with localized number formatting, or converted to the
+// metric or imperial system.
+package measure
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// System is a system of units.
+type System string
+
+// Supported systems.
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// Measurement is a measurement expression found in a text.
+type Measurement struct {
+	// Start and End are the byte range of the expression.
+	Start, End int
+	// Text is the expression as written.
+	Text string
+	// Values are the quantities, several for dimensions such as "30 x 40 cm".
+	// Feet and inches ("5 ft 4 in") are folded into inches.
+	Values []float64
+	// Unit is the canonical unit, e.g. "kg" or "in", or the lowercased unit
+	// as written when it has no canonical form (e.g. "gb").
+	Unit string
+}
+
+const number = `-?\d+(?:[.,]\d+)*`
+
+// unitPattern lists unit spellings, longest first so "km/h" wins over "km".
+// It is case-sensitive, so "4G" is a network and not four grams.
+const unitPattern = `km/h|mph|fl\.? ?oz|kHz|KHz|MHz|GHz|Hz|mAh|Ah|kWh|kW|W|V|KB|kB|MB|GB|TB|Gb|Mbps|Gbps|` +
+	`mm|cm|km|m|inches|inch|in\.|pulgadas|pulgada|pouces|pouce|pollici|pollice|polegadas|polegada|Zoll|"|″|` +
+	`feet|foot|ft|'|′|yards|yard|yd|miles|mile|mi|` +
+	`mg|kg|Kg|KG|g|lbs|lb|oz|ml|mL|cl|l|L|gal|°C|°F|ºC|ºF`
+
+var (
+	// feetInches matches "5 ft 4 in" and "5'4\"".
+	feetInches = regexp.MustCompile(`(?i)(\d+)\s*(?:ft|feet|foot|'|′)\s*(` + number + `)\s*(?:in\b\.?|inches\b|inch\b|"|″)`)
+	// dimensions matches one or more values sharing a unit: "2.5 kg", "30 x 40 cm".
+	dimensions = regexp.MustCompile(`(` + number + `(?:\s*[x×]\s*` + number + `)*)\s?(` + unitPattern + `)`)
+	numbers    = regexp.MustCompile(number)
+)
+
+// units maps unit spellings to canonical units.
+var units = map[string]string{
+	"inches": "in", "inch": "in", "in.": "in", `"`: "in", "″": "in",
+	"pulgadas": "in", "pulgada": "in", "pouces": "in", "pouce": "in", "pollici": "in",
+	"pollice": "in", "polegadas": "in", "polegada": "in", "zoll": "in",
+	"feet": "ft", "foot": "ft", "'": "ft", "′": "ft",
+	"yards": "yd", "yard": "yd", "miles": "mi", "mile": "mi",
+	"lbs": "lb", "fl oz": "fl oz", "fl. oz": "fl oz", "floz": "fl oz", "fl.oz": "fl oz",
+	"ºc": "°c", "ºf": "°f",
+}
+
+// Find returns the measurement expressions of text, in order. lang is the
+// base language of the text; it decides whether "1,000" is a thousand or
+// one.
+func Find(text, lang string) []Measurement {
+	var found []Measurement
+	taken := func(start, end int) bool {
+		for _, m := range found {
+			if start < m.End && m.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, loc := range feetInches.FindAllStringSubmatchIndex(text, -1) {
+		if !numberStarts(text, loc[0]) {
+			continue
+		}
+		feet := parseNumber(text[loc[2]:loc[3]], lang)
+		inches := parseNumber(text[loc[4]:loc[5]], lang)
+		found = append(found, Measurement{
+			Start: loc[0], End: loc[1], Text: text[loc[0]:loc[1]],
+			Values: []float64{feet*12 + inches}, Unit: "in",
+		})
+	}
+
+	for _, loc := range dimensions.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if taken(start, end) || !numberStarts(text, start) || !unitEnds(text, end) {
+			continue
+		}
+		var values []float64
+		for _, n := range numbers.FindAllString(text[loc[2]:loc[3]], -1) {
+			values = append(values, parseNumber(n, lang))
+		}
+		found = append(found, Measurement{
+			Start: start, End: end, Text: text[start:end],
+			Values: values, Unit: canonicalUnit(text[loc[4]:loc[5]]),
+		})
+	}
+
+	sortByStart(found)
+	return found
+}
+
+// numberStarts reports whether a number starting at i is a whole word, so
+// the "13" of "iPhone13" or "1.5" of "v1.5" is not a quantity.
+func numberStarts(text string, i int) bool {
+	return i == 0 || !isWordByte(text[i-1]) && text[i-1] != '.' && text[i-1] != ','
+}
+
+// unitEnds reports whether a unit ending at i is a whole word, so "5 min"
+// is not read as five metres.
+func unitEnds(text string, i int) bool {
+	if i >= len(text) || strings.HasSuffix(text[:i], ".") {
+		return true
+	}
+	return !isWordByte(text[i])
+}
+
+// isWordByte reports whether c is an ASCII letter or digit, or part of a
+// non-ASCII character.
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func canonicalUnit(unit string) string {
+	u := strings.ToLower(unit)
+	if c, ok := units[u]; ok {
+		return c
+	}
+	return u
+}
+
+func sortByStart(ms []Measurement) {
+	for i := 1; i < len(ms); i++ {
+		for j := i; j > 0 && ms[j].Start < ms[j-1].Start; j-- {
+			ms[j], ms[j-1] = ms[j-1], ms[j]
+		}
+	}
+}
+
+// decimalComma reports whether lang writes decimals with a comma.
+func decimalComma(lang string) bool {
+	return lang != "en"
+}
+
+// parseNumber reads a number written with "." or "," separators. With both,
+// the last one is the decimal separator. A single separator followed by
+// other than three digits is decimal ("2.5", "2,50"); with exactly three
+// digits ("1.000") the language decides.
+func parseNumber(s, lang string) float64 {
+	s, _ = splitDecimal(s, lang)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// splitDecimal normalises s to "1234.5" form and returns its number of
+// decimals.
+func splitDecimal(s, lang string) (string, int) {
+	lastDot, lastComma := strings.LastIndexByte(s, '.'), strings.LastIndexByte(s, ',')
+	dec := -1
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		dec = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := max(lastDot, lastComma)
+		single := strings.Count(s, string(s[sep])) == 1
+		threeDigits := len(s)-sep-1 == 3
+		if single && (!threeDigits || (s[sep] == ',') == decimalComma(lang)) {
+			dec = sep
+		}
+	}
+
+	var b strings.Builder
+	decimals := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case i == dec:
+			b.WriteByte('.')
+		case s[i] == '.' || s[i] == ',':
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	if dec >= 0 {
+		decimals = len(s) - dec - 1
+	}
+	return b.String(), decimals
+}
+
+// Localize rewrites the numbers of m in the formatting of target (a base
+// language), keeping the units and layout as written.
+func Localize(m Measurement, source, target string) string {
+	return numbers.ReplaceAllStringFunc(m.Text, func(n string) string {
+		plain, decimals := splitDecimal(n, source)
+		v, err := strconv.ParseFloat(plain, 64)
+		if err != nil {
+			return n
+		}
+		return FormatNumber(v, decimals, target)
+	})
+}
+
+// FormatNumber formats v with the given decimals in the conventions of lang:
+// "1,234.5" in English, "1.234,5" in Spanish, "1 234,5" (with a no-break
+// space) in French.
+func FormatNumber(v float64, decimals int, lang string) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	decimalSep, groupSep, minGroup := ".", ",", 4
+	if decimalComma(lang) {
+		decimalSep, groupSep = ",", "."
+	}
+	switch lang {
+	case "fr":
+		groupSep = "\u00a0"
+	case "es":
+		minGroup = 5 // "1000" but "10.000"
+	}
+
+	if len(intPart) >= minGroup {
+		var b strings.Builder
+		for i, c := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				b.WriteString(groupSep)
+			}
+			b.WriteRune(c)
+		}
+		intPart = b.String()
+	}
+	if neg {
+		intPart = "-" + intPart
+	}
+	if frac == "" {
+		return intPart
+	}
+	return intPart + decimalSep + frac
+}
+
+// round returns v and the decimals to show for a converted value: one
+// decimal below 100, none above.
+func round(v float64) (float64, int) {
+	if math.Abs(v) >= 100 {
+		return math.Round(v), 0
+	}
+	r := math.Round(v*10) / 10
+	if r == math.Trunc(r) {
+		return r, 0
+	}
+	return r, 1
+}
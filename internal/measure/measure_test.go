@@ -0,0 +1,107 @@
+package measure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		lang   string
+		want   []string
+		values [][]float64
+		units  []string
+	}{
+		{"decimal weight", "Pesa 2.5 kg aprox", "es", []string{"2.5 kg"}, [][]float64{{2.5}}, []string{"kg"}},
+		{"attached unit", "iPhone 13 32GB negro", "es", []string{"32GB"}, [][]float64{{32}}, []string{"gb"}},
+		{"feet and inches", "Height 5 ft 4 in, slim", "en", []string{"5 ft 4 in"}, [][]float64{{64}}, []string{"in"}},
+		{"feet and inches symbols", `He is 5'4" tall`, "en", []string{`5'4"`}, [][]float64{{64}}, []string{"in"}},
+		{"dimensions", "Cuadro 30 x 40 cm", "es", []string{"30 x 40 cm"}, [][]float64{{30, 40}}, []string{"cm"}},
+		{"thousands by language", "Batería 1.000 mAh", "es", []string{"1.000 mAh"}, [][]float64{{1000}}, []string{"mah"}},
+		{"english thousands", "Battery 1,000 mAh", "en", []string{"1,000 mAh"}, [][]float64{{1000}}, []string{"mah"}},
+		{"decimal comma", "Cable de 1,5 m", "es", []string{"1,5 m"}, [][]float64{{1.5}}, []string{"m"}},
+		{"localized inch word", "Televisor 55 pulgadas", "es", []string{"55 pulgadas"}, [][]float64{{55}}, []string{"in"}},
+		{"unit must be a word", "Entrega en 5 min y 4G LTE", "es", nil, nil, nil},
+		{"number must be a word", "iPhone13 mini", "es", nil, nil, nil},
+		{"bare in is not an inch", "I have 2 in stock", "en", nil, nil, nil},
+		{"several", "Peso 3 kg, 20 L de capacidad", "es", []string{"3 kg", "20 L"}, [][]float64{{3}, {20}}, []string{"kg", "l"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var texts []string
+			var values [][]float64
+			var units []string
+			for _, m := range Find(tt.text, tt.lang) {
+				if tt.text[m.Start:m.End] != m.Text {
+					t.Errorf("span [%d,%d) does not match %q", m.Start, m.End, m.Text)
+				}
+				texts = append(texts, m.Text)
+				values = append(values, m.Values)
+				units = append(units, m.Unit)
+			}
+			if !reflect.DeepEqual(texts, tt.want) || !reflect.DeepEqual(values, tt.values) || !reflect.DeepEqual(units, tt.units) {
+				t.Errorf("Find(%q) = %q %v %q, want %q %v %q", tt.text, texts, values, units, tt.want, tt.values, tt.units)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		source, target string
+		system         System
+		localize       string
+		convert        string
+	}{
+		{"kilograms to english", "2,5 kg", "es", "en", Imperial, "2.5 kg", "5.5 lb"},
+		{"pounds to spanish", "2.5 lb", "en", "es", Metric, "2,5 lb", "1,1 kg"},
+		{"feet and inches", "5 ft 4 in", "en", "fr", Metric, "5 ft 4 in", "163 cm"},
+		{"dimensions", "30 × 40 cm", "es", "en", Imperial, "30 × 40 cm", "11.8 × 15.7 in"},
+		{"large values", "55 inches", "en", "de", Metric, "55 inches", "140 cm"},
+		{"temperature", "-4 °F", "en", "it", Metric, "-4 °F", "-20 °C"},
+		{"shared unit only localized", "1.000 mAh", "es", "en", Imperial, "1,000 mAh", "1,000 mAh"},
+		{"already in system", "1500 m", "en", "fr", Metric, "1\u00a0500 m", "1\u00a0500 m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := Find(tt.text, tt.source)
+			if len(found) != 1 {
+				t.Fatalf("Find(%q) = %+v, want one measurement", tt.text, found)
+			}
+			if got := Localize(found[0], tt.source, tt.target); got != tt.localize {
+				t.Errorf("Localize() = %q, want %q", got, tt.localize)
+			}
+			if got := Convert(found[0], tt.system, tt.source, tt.target); got != tt.convert {
+				t.Errorf("Convert() = %q, want %q", got, tt.convert)
+			}
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		lang     string
+		want     string
+	}{
+		{1234.5, 1, "en", "1,234.5"},
+		{1234.5, 1, "de", "1.234,5"},
+		{1234, 0, "es", "1234"},
+		{12345, 0, "es", "12.345"},
+		{1234567, 0, "fr", "1\u00a0234\u00a0567"},
+		{-0.5, 1, "pt", "-0,5"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNumber(tt.v, tt.decimals, tt.lang); got != tt.want {
+			t.Errorf("FormatNumber(%v, %d, %q) = %q, want %q", tt.v, tt.decimals, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package metrics emits CloudWatch metrics using the Embedded Metric Format
+// (EMF): structured log lines that CloudWatch turns into metrics, with no
+// API calls from the Lambda.
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNamespace is the CloudWatch namespace used when METRICS_NAMESPACE is unset.
+const DefaultNamespace = "Pricofy/TranslationManager"
+
+// Unit is a CloudWatch metric unit.
+type Unit string
+
+// Metric units used by the translation manager.
+const (
+	Count        Unit = "Count"
+	Milliseconds Unit = "Milliseconds"
+	Bytes        Unit = "Bytes"
+	None         Unit = "None"
+)
+
+// Dimensions are the CloudWatch dimensions of a metric, e.g. {"LanguagePair": "es-fr"}.
+type Dimensions map[string]string
+
+// Recorder buffers metrics for one invocation and writes them as EMF lines.
+// Metrics sharing the same dimensions are written in a single line.
+type Recorder struct {
+	namespace string
+	out       io.Writer
+	now       func() time.Time
+
+	mu      sync.Mutex
+	groups  map[string]*group
+	ordered []string
+}
+
+type group struct {
+	dims   Dimensions
+	names  []string
+	units  map[string]Unit
+	values map[string]float64
+}
+
+// New creates a Recorder writing to out. The namespace comes from
+// METRICS_NAMESPACE, defaulting to DefaultNamespace.
+func New(out io.Writer) *Recorder {
+	namespace := os.Getenv("METRICS_NAMESPACE")
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &Recorder{
+		namespace: namespace,
+		out:       out,
+		now:       time.Now,
+		groups:    map[string]*group{},
+	}
+}
+
+// Add adds value to the named metric. Repeated calls with the same name and
+// dimensions accumulate.
+func (r *Recorder) Add(name string, unit Unit, value float64, dims Dimensions) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := dimensionsKey(dims)
+	g, ok := r.groups[key]
+	if !ok {
+		g = &group{dims: dims, units: map[string]Unit{}, values: map[string]float64{}}
+		r.groups[key] = g
+		r.ordered = append(r.ordered, key)
+	}
+	if _, ok := g.values[name]; !ok {
+		g.names = append(g.names, name)
+	}
+	g.units[name] = unit
+	g.values[name] += value
+}
+
+// Flush writes all buffered metrics and resets the recorder.
+func (r *Recorder) Flush() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.ordered {
+		line, err := json.Marshal(r.document(r.groups[key]))
+		if err != nil {
+			return err
+		}
+		if _, err := r.out.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	r.groups = map[string]*group{}
+	r.ordered = nil
+	return nil
+}
+
+// document builds the EMF JSON object for one group of metrics.
+func (r *Recorder) document(g *group) map[string]interface{} {
+	dimNames := make([]string, 0, len(g.dims))
+	for name := range g.dims {
+		dimNames = append(dimNames, name)
+	}
+	sort.Strings(dimNames)
+
+	definitions := make([]map[string]string, 0, len(g.names))
+	for _, name := range g.names {
+		definitions = append(definitions, map[string]string{"Name": name, "Unit": string(g.units[name])})
+	}
+
+	doc := map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": r.now().UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{{
+				"Namespace":  r.namespace,
+				"Dimensions": [][]string{dimNames},
+				"Metrics":    definitions,
+			}},
+		},
+	}
+	for name, value := range g.dims {
+		doc[name] = value
+	}
+	for name, value := range g.values {
+		doc[name] = value
+	}
+	return doc
+}
+
+// dimensionsKey returns a stable key for a set of dimensions.
+func dimensionsKey(dims Dimensions) string {
+	parts := make([]string, 0, len(dims))
+	for name, value := range dims {
+		parts = append(parts, name+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
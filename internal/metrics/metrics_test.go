@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf)
+	r.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	r.Add("PostEditHits", Count, 2, Dimensions{"LanguagePair": "es-fr", "Rule": "wallet"})
+	r.Add("PostEditHits", Count, 1, Dimensions{"Rule": "wallet", "LanguagePair": "es-fr"})
+	r.Add("Requests", Count, 1, Dimensions{"LanguagePair": "es-fr"})
+
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Flush() wrote %d lines, want 2 (one per dimension set)", len(lines))
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &doc); err != nil {
+		t.Fatalf("invalid EMF line: %v", err)
+	}
+	if doc["PostEditHits"] != 3.0 {
+		t.Errorf("PostEditHits = %v, want 3 (accumulated)", doc["PostEditHits"])
+	}
+	if doc["Rule"] != "wallet" || doc["LanguagePair"] != "es-fr" {
+		t.Errorf("dimensions missing from EMF line: %v", doc)
+	}
+
+	aws := doc["_aws"].(map[string]interface{})
+	if aws["Timestamp"] != 1700000000000.0 {
+		t.Errorf("Timestamp = %v", aws["Timestamp"])
+	}
+	cw := aws["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	if cw["Namespace"] != DefaultNamespace {
+		t.Errorf("Namespace = %v, want %v", cw["Namespace"], DefaultNamespace)
+	}
+
+	buf.Reset()
+	if err := r.Flush(); err != nil || buf.Len() != 0 {
+		t.Errorf("second Flush() should write nothing, wrote %q", buf.String())
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	r.Add("Requests", Count, 1, nil)
+	if err := r.Flush(); err != nil {
+		t.Errorf("nil Recorder Flush() error = %v", err)
+	}
+}
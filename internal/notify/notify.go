@@ -0,0 +1,132 @@
+// Package notify publishes async job completions and operational alarms to
+// SNS topics, so any number of consumers (search, cache invalidation,
+// analytics, on-call paging) can subscribe without the manager knowing
+// about them.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// TopicEnv names the SNS topic ARN job completions are published to.
+const TopicEnv = "JOBS_TOPIC_ARN"
+
+// AlarmTopicEnv names the SNS topic ARN alarms are published to.
+const AlarmTopicEnv = "ALARM_TOPIC_ARN"
+
+// Publisher is the subset of the SNS client used by the notifier.
+type Publisher interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// Event is the message published when a job finishes.
+type Event struct {
+	JobID      string `json:"jobId"`
+	Status     string `json:"status"`
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+	TenantID   string `json:"tenantId,omitempty"`
+	// Result is the S3 or DynamoDB URI of the stored job response.
+	Result string `json:"result"`
+	// Texts is the number of translated texts.
+	Texts int    `json:"texts"`
+	Error string `json:"error,omitempty"`
+}
+
+// Alarm is the message published when the manager takes an automatic
+// action that operators must know about, such as a canary rollback.
+type Alarm struct {
+	// Type is e.g. "canaryRollback".
+	Type       string `json:"type"`
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+	// Subject identifies what the alarm is about, e.g. a routing entry ID.
+	Subject string `json:"subject"`
+	Reason  string `json:"reason"`
+	// Details holds alarm-specific data.
+	Details any       `json:"details,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Notifier publishes job events to one topic. A nil Notifier publishes nothing.
+type Notifier struct {
+	publisher Publisher
+	topicARN  string
+}
+
+// New creates a Notifier publishing to topicARN.
+func New(publisher Publisher, topicARN string) *Notifier {
+	return &Notifier{publisher: publisher, topicARN: topicARN}
+}
+
+// Publish sends e with message attributes for subscription filter policies:
+// languagePair (e.g. "es-fr"), sourceLang, targetLang, status and, when set,
+// tenantId.
+func (n *Notifier) Publish(ctx context.Context, e Event) error {
+	if n == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job event: %w", err)
+	}
+
+	attrs := map[string]types.MessageAttributeValue{
+		"languagePair": stringAttribute(e.SourceLang + "-" + e.TargetLang),
+		"sourceLang":   stringAttribute(e.SourceLang),
+		"targetLang":   stringAttribute(e.TargetLang),
+		"status":       stringAttribute(e.Status),
+	}
+	if e.TenantID != "" {
+		attrs["tenantId"] = stringAttribute(e.TenantID)
+	}
+
+	if err := n.publish(ctx, body, attrs); err != nil {
+		return fmt.Errorf("failed to publish job %s: %w", e.JobID, err)
+	}
+	return nil
+}
+
+// PublishAlarm sends a with the alarmType and languagePair message
+// attributes.
+func (n *Notifier) PublishAlarm(ctx context.Context, a Alarm) error {
+	if n == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alarm: %w", err)
+	}
+	attrs := map[string]types.MessageAttributeValue{
+		"alarmType":    stringAttribute(a.Type),
+		"languagePair": stringAttribute(a.SourceLang + "-" + a.TargetLang),
+	}
+	if err := n.publish(ctx, body, attrs); err != nil {
+		return fmt.Errorf("failed to publish %s alarm: %w", a.Type, err)
+	}
+	return nil
+}
+
+// publish sends one message to the topic.
+func (n *Notifier) publish(ctx context.Context, body []byte, attrs map[string]types.MessageAttributeValue) error {
+	message := string(body)
+	_, err := n.publisher.Publish(ctx, &sns.PublishInput{
+		TopicArn:          &n.topicARN,
+		Message:           &message,
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+func stringAttribute(value string) types.MessageAttributeValue {
+	dataType := "String"
+	return types.MessageAttributeValue{DataType: &dataType, StringValue: &value}
+}
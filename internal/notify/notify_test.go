@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+type fakePublisher struct {
+	input *sns.PublishInput
+	err   error
+}
+
+func (f *fakePublisher) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.input = params
+	return &sns.PublishOutput{}, f.err
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     Event
+		wantAttrs map[string]string
+	}{
+		{
+			name:  "with tenant",
+			event: Event{JobID: "j1", Status: "completed", SourceLang: "es", TargetLang: "fr", TenantID: "acme", Texts: 2},
+			wantAttrs: map[string]string{
+				"languagePair": "es-fr", "sourceLang": "es", "targetLang": "fr", "status": "completed", "tenantId": "acme",
+			},
+		},
+		{
+			name:  "without tenant",
+			event: Event{JobID: "j2", Status: "completed", SourceLang: "en", TargetLang: "de"},
+			wantAttrs: map[string]string{
+				"languagePair": "en-de", "sourceLang": "en", "targetLang": "de", "status": "completed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			if err := New(pub, "arn:aws:sns:eu-west-1:1:jobs").Publish(context.Background(), tt.event); err != nil {
+				t.Fatal(err)
+			}
+
+			if *pub.input.TopicArn != "arn:aws:sns:eu-west-1:1:jobs" {
+				t.Errorf("TopicArn = %s", *pub.input.TopicArn)
+			}
+			if len(pub.input.MessageAttributes) != len(tt.wantAttrs) {
+				t.Errorf("MessageAttributes = %d, want %d", len(pub.input.MessageAttributes), len(tt.wantAttrs))
+			}
+			for name, want := range tt.wantAttrs {
+				if got := pub.input.MessageAttributes[name]; got.StringValue == nil || *got.StringValue != want {
+					t.Errorf("attribute %s = %v, want %q", name, got.StringValue, want)
+				}
+			}
+
+			var got Event
+			if err := json.Unmarshal([]byte(*pub.input.Message), &got); err != nil || got != tt.event {
+				t.Errorf("Message = %s, want %+v", *pub.input.Message, tt.event)
+			}
+		})
+	}
+}
+
+func TestPublish_Error(t *testing.T) {
+	pub := &fakePublisher{err: errors.New("throttled")}
+	if err := New(pub, "arn").Publish(context.Background(), Event{JobID: "j"}); err == nil {
+		t.Error("Publish() should return publisher errors")
+	}
+}
+
+func TestPublish_NilNotifier(t *testing.T) {
+	var n *Notifier
+	if err := n.Publish(context.Background(), Event{}); err != nil {
+		t.Errorf("nil Notifier Publish() = %v", err)
+	}
+}
+
+func TestPublishAlarm(t *testing.T) {
+	pub := &fakePublisher{}
+	alarm := Alarm{Type: "canaryRollback", SourceLang: "es", TargetLang: "en", Subject: "es-en-v2", Reason: "error rate 0.50 exceeds 0.10"}
+	if err := New(pub, "arn").PublishAlarm(context.Background(), alarm); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := *pub.input.MessageAttributes["alarmType"].StringValue; got != "canaryRollback" {
+		t.Errorf("alarmType = %q", got)
+	}
+	if got := *pub.input.MessageAttributes["languagePair"].StringValue; got != "es-en" {
+		t.Errorf("languagePair = %q", got)
+	}
+	var got Alarm
+	if err := json.Unmarshal([]byte(*pub.input.Message), &got); err != nil || got.Subject != "es-en-v2" {
+		t.Errorf("Message = %s", *pub.input.Message)
+	}
+
+	var n *Notifier
+	if err := n.PublishAlarm(context.Background(), alarm); err != nil {
+		t.Errorf("nil Notifier PublishAlarm() = %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package openapi generates the OpenAPI description of the HTTP API and the
+// JSON Schemas of its request and response from the Go types, so client
+// teams can generate typed SDKs. The generated documents are committed in
+// the api package, which embeds them for serving.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pricofy/translation-manager/api"
+	"github.com/pricofy/translation-manager/internal/domain"
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/router"
+	"github.com/pricofy/translation-manager/internal/schema"
+)
+
+// Version is the API version in the OpenAPI document.
+const Version = "1.0.0"
+
+// Generate builds the documents from the Go types, keyed by file name.
+func Generate() (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	g := newGenerator("#/components/schemas/")
+	spec := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "Translation Manager",
+			"version": Version,
+			"description": "Translates marketplace texts between languages, pivoting through English " +
+				"when no direct translator exists. Errors are reported in the response body with " +
+				"error and errorCode.",
+		},
+		"paths":      g.paths(),
+		"components": map[string]any{"schemas": g.defs},
+	}
+
+	standalone := newGenerator("#/$defs/")
+	standalone.root(reflect.TypeOf(handler.Request{}))
+	standalone.root(reflect.TypeOf(handler.Response{}))
+
+	docs := map[string]any{
+		api.OpenAPIFile:        spec,
+		api.RequestSchemaFile:  standalone.document("Request"),
+		api.ResponseSchemaFile: standalone.document("Response"),
+		// Earlier versions are frozen (see package api)
+		api.RequestSchemaVersionFile(domain.SchemaVersion): standalone.document("Request"),
+	}
+	for name, doc := range docs {
+		data, err := encode(doc)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// encode renders doc as indented JSON.
+func encode(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// paths describes the endpoints of the HTTP server.
+func (g *generator) paths() map[string]any {
+	request := g.root(reflect.TypeOf(handler.Request{}))
+	response := g.root(reflect.TypeOf(handler.Response{}))
+	catalog := g.root(reflect.TypeOf(router.Catalog{}))
+
+	errorResponse := func(description string) map[string]any {
+		return jsonContent(description, response)
+	}
+	return map[string]any{
+		"/translate": map[string]any{
+			"post": map[string]any{
+				"operationId": "translate",
+				"summary":     "Translate texts, or run another action",
+				"description": "Send application/x-ndjson instead to stream a large batch: the options " +
+					"line first, then one JSON string per text.",
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": request},
+					},
+				},
+				"responses": map[string]any{
+					"200": jsonContent("Translations, or an error the caller can act on", response),
+					"400": errorResponse("Invalid request"),
+					"405": errorResponse("Method not allowed"),
+					"413": errorResponse("Request body too large"),
+					"500": errorResponse("Internal error"),
+				},
+			},
+		},
+		"/languages": map[string]any{
+			"get": map[string]any{
+				"operationId": "languages",
+				"summary":     "Supported languages and pairs",
+				"description": "The ETag is the catalog version; revalidate with If-None-Match.",
+				"responses": map[string]any{
+					"200": jsonContent("Language catalog", catalog),
+					"304": map[string]any{"description": "Catalog unchanged"},
+				},
+			},
+		},
+		"/health": map[string]any{
+			"get": map[string]any{
+				"operationId": "health",
+				"summary":     "Liveness check",
+				"responses": map[string]any{
+					"200": jsonContent("Server is up", map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"status": map[string]any{"type": "string"}},
+					}),
+				},
+			},
+		},
+		"/openapi.json":          documentPath("openapi", "This OpenAPI document"),
+		"/schemas/request.json":  documentPath("requestSchema", "JSON Schema of a request"),
+		"/schemas/response.json": documentPath("responseSchema", "JSON Schema of a response"),
+		"/schemas/request/{version}.json": withVersionParameter(
+			documentPath("versionedRequestSchema", "JSON Schema of a request contract version")),
+	}
+}
+
+func jsonContent(description string, s any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": s},
+		},
+	}
+}
+
+func documentPath(operationID, summary string) map[string]any {
+	return map[string]any{
+		"get": map[string]any{
+			"operationId": operationID,
+			"summary":     summary,
+			"responses": map[string]any{
+				"200": jsonContent(summary, map[string]any{"type": "object"}),
+			},
+		},
+	}
+}
+
+// withVersionParameter adds the {version} path parameter to path.
+func withVersionParameter(path map[string]any) map[string]any {
+	path["parameters"] = []any{map[string]any{
+		"name":        "version",
+		"in":          "path",
+		"required":    true,
+		"description": "Request contract version, e.g. " + domain.SchemaVersion,
+		"schema":      map[string]any{"type": "string", "enum": domain.SchemaVersions},
+	}}
+	return path
+}
+
+// generator derives JSON Schemas from Go types, collecting every struct
+// type as a definition referenced by name.
+type generator struct {
+	prefix string
+	defs   map[string]any
+}
+
+func newGenerator(prefix string) *generator {
+	return &generator{prefix: prefix, defs: map[string]any{}}
+}
+
+// document is a standalone JSON Schema of the definition called name.
+func (g *generator) document(name string) map[string]any {
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   name,
+		"$ref":    g.prefix + name,
+		"$defs":   g.defs,
+	}
+}
+
+// root returns the schema of t. For the request, the value constraints of
+// the request validator are added, and no property is required: which ones
+// are depends on the action.
+func (g *generator) root(t reflect.Type) map[string]any {
+	s := g.schemaOf(t)
+	if t == reflect.TypeOf(handler.Request{}) {
+		def := g.def(s)
+		delete(def, "required")
+		for name, p := range handler.PropertySchemas() {
+			if prop, ok := def["properties"].(map[string]any)[name].(map[string]any); ok {
+				g.constrain(prop, p)
+			}
+		}
+	}
+	return s
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf returns the schema of values of type t.
+func (g *generator) schemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// ref returns a reference to the definition of struct type t, adding it.
+func (g *generator) ref(t reflect.Type) map[string]any {
+	name := defName(t)
+	if _, ok := g.defs[name]; !ok {
+		def := map[string]any{"type": "object"}
+		g.defs[name] = def // before the fields, for recursive types
+		props, required := map[string]any{}, []string{}
+		g.fields(t, props, &required)
+		def["properties"] = props
+		if len(required) > 0 {
+			def["required"] = required
+		}
+	}
+	return map[string]any{"$ref": g.prefix + name}
+}
+
+// fields adds the JSON properties of struct type t. Properties without
+// omitempty are always present, though nil slices, maps and pointers are null.
+func (g *generator) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.fields(f.Type, props, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := g.schemaOf(f.Type)
+		if strings.Contains(","+opts+",", ",omitempty,") {
+			props[name] = s
+			continue
+		}
+		*required = append(*required, name)
+		switch f.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			s = map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+		}
+		props[name] = s
+	}
+}
+
+// def returns the definition s refers to, or s itself.
+func (g *generator) def(s map[string]any) map[string]any {
+	if ref, ok := s["$ref"].(string); ok {
+		return g.defs[strings.TrimPrefix(ref, g.prefix)].(map[string]any)
+	}
+	return s
+}
+
+// constrain adds the enums and bounds of the request validator to s.
+func (g *generator) constrain(s map[string]any, v *schema.Schema) {
+	s = g.def(s)
+	if len(v.Enum) > 0 {
+		s["enum"] = v.Enum
+	}
+	if v.Minimum != nil {
+		s["minimum"] = *v.Minimum
+	}
+	if v.Maximum != nil {
+		s["maximum"] = *v.Maximum
+	}
+	if items, ok := s["items"].(map[string]any); ok && v.Items != nil {
+		g.constrain(items, v.Items)
+	}
+	props, _ := s["properties"].(map[string]any)
+	for name, p := range v.Properties {
+		if prop, ok := props[name].(map[string]any); ok {
+			g.constrain(prop, p)
+		}
+	}
+}
+
+// defName names the definition of t: handler and domain types by their own
+// name, others prefixed with their package, e.g. "RoutingEntry".
+func defName(t reflect.Type) string {
+	pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+	if pkg == "handler" || pkg == "domain" || strings.HasPrefix(strings.ToLower(t.Name()), pkg) {
+		return t.Name()
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pricofy/translation-manager/api"
+	"github.com/pricofy/translation-manager/internal/domain"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	files, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	embedded := map[string][]byte{
+		api.OpenAPIFile:        api.OpenAPI,
+		api.RequestSchemaFile:  api.RequestSchema,
+		api.ResponseSchemaFile: api.ResponseSchema,
+	}
+	current, ok := api.RequestSchemaVersion(domain.SchemaVersion)
+	if !ok {
+		t.Fatalf("no request schema of version %s; run go generate ./api", domain.SchemaVersion)
+	}
+	embedded[api.RequestSchemaVersionFile(domain.SchemaVersion)] = current
+	for name, want := range embedded {
+		if !bytes.Equal(files[name], want) {
+			t.Errorf("%s is stale; run go generate ./api", name)
+		}
+	}
+}
+
+func TestSpec(t *testing.T) {
+	var spec struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(api.OpenAPI, &spec); err != nil {
+		t.Fatalf("spec is not JSON: %v", err)
+	}
+
+	for _, path := range []string{"/translate", "/languages", "/health", "/openapi.json", "/schemas/request/{version}.json"} {
+		if spec.Paths[path] == nil {
+			t.Errorf("spec has no %s path", path)
+		}
+	}
+
+	schemas := spec.Components.Schemas
+	request := schemas["Request"]
+	if len(request.Required) != 0 {
+		t.Errorf("Request required = %q, want none", request.Required)
+	}
+	if _, ok := request.Properties["deprecations"]; ok {
+		t.Error("Request documents the internal deprecations field")
+	}
+	if got := request.Properties["chunkStrategy"]["enum"]; !reflect.DeepEqual(got, []any{"sequential", "balanced", "html"}) {
+		t.Errorf("chunkStrategy enum = %v", got)
+	}
+	if got := request.Properties["minConfidence"]; got["minimum"] != 0.0 || got["maximum"] != 1.0 {
+		t.Errorf("minConfidence = %v, want bounds 0 and 1", got)
+	}
+	if got := schemas["Response"].Required; !reflect.DeepEqual(got, []string{"translations", "chunksProcessed"}) {
+		t.Errorf("Response required = %q", got)
+	}
+	for _, name := range []string{"Warning", "RoutingEntry", "LocaleInfo", "RouterCatalog", "BlocklistMatch"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("spec has no %s schema", name)
+		}
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	for name, data := range map[string][]byte{api.RequestSchemaFile: api.RequestSchema, api.ResponseSchemaFile: api.ResponseSchema} {
+		var doc struct {
+			Ref  string         `json:"$ref"`
+			Defs map[string]any `json:"$defs"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("%s is not JSON: %v", name, err)
+		}
+		if doc.Defs[doc.Ref[len("#/$defs/"):]] == nil {
+			t.Errorf("%s refers to missing %s", name, doc.Ref)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package passthrough detects texts with nothing to translate: numbers,
+// SKUs and model codes, URLs, email addresses and emoji. They are returned
+// as they are instead of being sent to a model, which saves tokens and
+// keeps codes like "IPH-12-PRO-256GB" from being garbled.
+package passthrough
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of untranslatable texts.
+const (
+	KindNumber = "number"
+	KindSKU    = "sku"
+	KindURL    = "url"
+	KindEmail  = "email"
+	KindEmoji  = "emoji"
+)
+
+var (
+	// numberPattern matches amounts, prices and percentages such as
+	// "1.299,00", "-3", "12 345", "€12.99" or "15%".
+	numberPattern = regexp.MustCompile(`^[-+]?\p{Sc}?\s?\d[\d.,\s]*(\s?(\p{Sc}|%))?$`)
+	// skuPattern matches a single token of upper-case letters and digits,
+	// possibly joined by - _ . / or #, with at least one digit.
+	skuPattern   = regexp.MustCompile(`^#?[A-Z0-9]+([-_./#][A-Z0-9]+)*$`)
+	urlPattern   = regexp.MustCompile(`^(?i)(https?://|www\.)\S+$`)
+	emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+)
+
+// Detect returns the kind of an untranslatable text, or "" when text has
+// words to translate.
+func Detect(text string) string {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return ""
+	case numberPattern.MatchString(text):
+		return KindNumber
+	case skuPattern.MatchString(text) && strings.ContainsAny(text, "0123456789"):
+		return KindSKU
+	case urlPattern.MatchString(text):
+		return KindURL
+	case emailPattern.MatchString(text):
+		return KindEmail
+	case emojiOnly(text):
+		return KindEmoji
+	}
+	return ""
+}
+
+// emojiOnly reports whether text has only emoji: symbols with their
+// modifiers, joiners and variation selectors, and spaces.
+func emojiOnly(text string) bool {
+	symbols := 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbols++
+		case unicode.Is(unicode.Sk, r), unicode.Is(unicode.Me, r), unicode.IsSpace(r),
+			r == '\u200d', r == '\ufe0e', r == '\ufe0f':
+		default:
+			return false
+		}
+	}
+	return symbols > 0
+}
@@ -0,0 +1,41 @@
+package passthrough
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"42", KindNumber},
+		{"1.299,00", KindNumber},
+		{"12 345", KindNumber},
+		{"€12.99", KindNumber},
+		{"12,99 €", KindNumber},
+		{"-15%", KindNumber},
+		{"IPH-12-PRO-256GB", KindSKU},
+		{"SM-G991B", KindSKU},
+		{"#A1234", KindSKU},
+		{"256GB", KindSKU},
+		{"https://pricofy.com/p/123", KindURL},
+		{"www.example.es", KindURL},
+		{"ventas@pricofy.com", KindEmail},
+		{"🔥🔥", KindEmoji},
+		{"👍🏽 ❤️", KindEmoji},
+		{"👨‍👩‍👧", KindEmoji},
+		{"", ""},
+		{"   ", ""},
+		{"USB-C", ""},
+		{"OK", ""},
+		{"iPhone 12", ""},
+		{"12 kg", ""},
+		{"Envío gratis 🔥", ""},
+		{"visita https://pricofy.com", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
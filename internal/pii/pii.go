@@ -0,0 +1,118 @@
+// Package pii finds personal data in listing texts (email addresses, phone
+// numbers and IBANs) so it can be masked before the texts leave the
+// account.
+package pii
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of personal data.
+const (
+	KindEmail = "email"
+	KindPhone = "phone"
+	KindIBAN  = "iban"
+)
+
+// Match is personal data found in a text.
+type Match struct {
+	// Start and End are the byte range of the match.
+	Start, End int
+	Kind       string
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	// ibanPattern matches IBANs written whole or in groups of four.
+	ibanPattern = regexp.MustCompile(`[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?`)
+	// phonePattern matches digit runs with the usual separators, e.g.
+	// "+34 612 345 678", "(0221) 123-4567" or "0044 20 7946 0958".
+	phonePattern = regexp.MustCompile(`(?:\+|\(\+?)?\d[\d \-./()]{5,}\d`)
+)
+
+// Phone numbers have minPhoneDigits to maxPhoneDigits digits (E.164), so
+// prices, dates and sizes are not taken for one.
+const (
+	minPhoneDigits = 9
+	maxPhoneDigits = 15
+)
+
+// Find returns the personal data of text, in order. Email addresses win
+// over IBANs and IBANs over phone numbers where they overlap.
+func Find(text string) []Match {
+	var found []Match
+	add := func(start, end int, kind string) {
+		for _, m := range found {
+			if start < m.End && m.Start < end {
+				return
+			}
+		}
+		found = append(found, Match{Start: start, End: end, Kind: kind})
+	}
+
+	for _, loc := range emailPattern.FindAllStringIndex(text, -1) {
+		add(loc[0], loc[1], KindEmail)
+	}
+	for _, loc := range ibanPattern.FindAllStringIndex(text, -1) {
+		if wordBounded(text, loc[0], loc[1]) && validIBAN(text[loc[0]:loc[1]]) {
+			add(loc[0], loc[1], KindIBAN)
+		}
+	}
+	for _, loc := range phonePattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if wordBounded(text, start, end) && isPhone(text[start:end]) {
+			add(start, end, KindPhone)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	return found
+}
+
+// wordBounded reports whether text[start:end] is not part of a longer
+// word, so the digits of a model code such as "SM-G991B" are left alone.
+func wordBounded(text string, start, end int) bool {
+	return (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end]))
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isPhone reports whether a phone pattern match has the digits of a phone
+// number.
+func isPhone(s string) bool {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	return digits >= minPhoneDigits && digits <= maxPhoneDigits
+}
+
+// validIBAN checks the length and the ISO 13616 mod-97 checksum of an IBAN.
+func validIBAN(s string) bool {
+	iban := strings.ReplaceAll(s, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	// Move the country code and check digits to the end, then read the
+	// letters as numbers from 10 (A) to 35 (Z)
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for i := 0; i < len(rearranged); i++ {
+		c := rearranged[i]
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
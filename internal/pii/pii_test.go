@@ -0,0 +1,56 @@
+package pii
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "email", text: "Escríbeme a ana.garcia+venta@correo.es para más fotos", want: []string{"email:ana.garcia+venta@correo.es"}},
+		{name: "international phone", text: "Llama al +34 612 345 678 por las tardes", want: []string{"phone:+34 612 345 678"}},
+		{name: "local phone", text: "Tel. (0221) 123-4567", want: []string{"phone:(0221) 123-4567"}},
+		{name: "iban in groups", text: "Pago por transferencia: ES91 2100 0418 4502 0005 1332.", want: []string{"iban:ES91 2100 0418 4502 0005 1332"}},
+		{name: "iban whole", text: "IBAN DE89370400440532013000", want: []string{"iban:DE89370400440532013000"}},
+		{name: "bad iban checksum", text: "ES00 2100 0418 4502 0005 1332"},
+		{name: "several", text: "ana@correo.es o 612345678", want: []string{"email:ana@correo.es", "phone:612345678"}},
+		{name: "price", text: "Precio 1.250,00 €"},
+		{name: "date", text: "Comprado el 2024-10-17"},
+		{name: "model code", text: "Samsung SM-G991B 128GB"},
+		{name: "ean in a word", text: "EAN8412345678901"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range Find(tt.text) {
+				got = append(got, m.Kind+":"+tt.text[m.Start:m.End])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidIBAN(t *testing.T) {
+	tests := []struct {
+		iban string
+		want bool
+	}{
+		{iban: "GB82WEST12345698765432", want: true},
+		{iban: "FR1420041010050500013M02606", want: true},
+		{iban: "GB82WEST12345698765433"},
+		{iban: "GB82WEST"},
+	}
+
+	for _, tt := range tests {
+		if got := validIBAN(tt.iban); got != tt.want {
+			t.Errorf("validIBAN(%q) = %v, want %v", tt.iban, got, tt.want)
+		}
+	}
+}
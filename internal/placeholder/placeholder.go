@@ -0,0 +1,88 @@
+// Package placeholder finds the interpolation tokens of UI strings, which
+// must reach the translation exactly as written: "{count}", "{{name}}",
+// "${user}", printf verbs such as "%s" or "%1$d", and ICU MessageFormat
+// arguments such as "{n, plural, one {# item} other {# items}}".
+package placeholder
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/protect"
+)
+
+var (
+	// printfPattern matches C, Java, Objective-C and Python printf verbs,
+	// positional ("%1$s") and named ("%(name)s") ones included. Space flags
+	// are left out so "50% off" is not a verb.
+	printfPattern = regexp.MustCompile(`^%(?:\d+\$|\([A-Za-z_]\w*\))?[-+0#]*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|h|ll|l|L|q|j|z|t)?[diouxXeEfFgGaAcspr@%]`)
+	// mustachePattern matches "{{name}}" and "{{{name}}}" of Mustache,
+	// Handlebars, Angular and i18next.
+	mustachePattern = regexp.MustCompile(`^\{\{\{?\s*[^{}\n]+?\s*\}?\}\}`)
+	// argumentPattern matches the name of an ICU or format-string argument,
+	// empty for "{}".
+	argumentPattern = regexp.MustCompile(`^\s*[\w.]*\s*$`)
+)
+
+// Find returns the spans of the placeholders of text, sorted and not
+// overlapping.
+func Find(text string) []protect.Span {
+	var spans []protect.Span
+	for i := 0; i < len(text); {
+		if n := match(text[i:]); n > 0 {
+			spans = append(spans, protect.Span{Start: i, End: i + n})
+			i += n
+			continue
+		}
+		i++
+	}
+	return spans
+}
+
+// Is reports whether s is a single placeholder.
+func Is(s string) bool {
+	return s != "" && match(s) == len(s)
+}
+
+// match returns the length of the placeholder at the start of s, or 0.
+func match(s string) int {
+	switch {
+	case strings.HasPrefix(s, "{{"):
+		return len(mustachePattern.FindString(s))
+	case strings.HasPrefix(s, "${"):
+		if n := argument(s[1:]); n > 0 {
+			return n + 1
+		}
+	case strings.HasPrefix(s, "{"):
+		return argument(s)
+	case strings.HasPrefix(s, "%"):
+		return len(printfPattern.FindString(s))
+	}
+	return 0
+}
+
+// argument returns the length of the braced argument at the start of s,
+// nested ICU sub-messages included, or 0 when the braces are unbalanced or
+// do not start with an argument name.
+func argument(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			name, _, _ := strings.Cut(s[1:i], ",")
+			if !argumentPattern.MatchString(name) {
+				return 0
+			}
+			return i + 1
+		case '\n':
+			return 0
+		}
+	}
+	return 0
+}
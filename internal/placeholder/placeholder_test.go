@@ -0,0 +1,57 @@
+package placeholder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"brace argument", "Tienes {count} mensajes", []string{"{count}"}},
+		{"mustache", "Hola {{name}}, bienvenido a {{{ site }}}", []string{"{{name}}", "{{{ site }}}"}},
+		{"template literal", "Hola ${user.name}, ${id}", []string{"${user.name}", "${id}"}},
+		{"printf", "%s tiene %1$d artículos por %.2f € y %(name)s %%", []string{"%s", "%1$d", "%.2f", "%(name)s", "%%"}},
+		{"percent sign", "50% de descuento, 100 %", nil},
+		{"empty braces", "Precio: {} €", []string{"{}"}},
+		{"ICU plural", "{n, plural, one {# artículo} other {# artículos}} en tu carrito", []string{"{n, plural, one {# artículo} other {# artículos}}"}},
+		{"ICU number", "Total: {total, number, ::currency/EUR}", []string{"{total, number, ::currency/EUR}"}},
+		{"not an argument", "Talla {M o L} y {sin cerrar", nil},
+		{"JSON-like", `{"a": 1}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range Find(tt.text) {
+				got = append(got, tt.text[s.Start:s.End])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"{count}", true},
+		{"%s", true},
+		{"{{name}}", true},
+		{"{count} x", false},
+		{"<b>", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := Is(tt.s); got != tt.want {
+			t.Errorf("Is(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
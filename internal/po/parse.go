@@ -0,0 +1,155 @@
+package po
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sections of an entry, in the order they appear.
+const (
+	sectionComments = iota
+	sectionHead
+	sectionTail
+)
+
+// parser reads the lines of a catalog into entries.
+type parser struct {
+	c       *Catalog
+	e       *Entry
+	section int
+	// target is the string that continuation lines extend, and strs the
+	// msgstr strings of the entry.
+	target *string
+	strs   []*string
+}
+
+// flush adds the entry read so far to the catalog.
+func (p *parser) flush() error {
+	e := p.e
+	if e == nil {
+		return nil
+	}
+	if e.message && len(p.strs) == 0 {
+		return fmt.Errorf("msgid %q has no msgstr", e.ID)
+	}
+	for _, s := range p.strs {
+		e.Strings = append(e.Strings, *s)
+	}
+	p.c.blocks = append(p.c.blocks, e)
+	p.e, p.section, p.target, p.strs = nil, sectionComments, nil, nil
+	return nil
+}
+
+// entry returns the entry being read, first flushing the previous one when
+// a line of section cannot continue it.
+func (p *parser) entry(section int) (*Entry, error) {
+	if p.e != nil && p.section > section {
+		if err := p.flush(); err != nil {
+			return nil, err
+		}
+	}
+	if p.e == nil {
+		p.e = &Entry{}
+	}
+	return p.e, nil
+}
+
+// line reads a non-blank line.
+func (p *parser) line(line string) error {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "#~"):
+		// Obsolete entries are kept verbatim
+		e, err := p.entry(sectionComments)
+		if err != nil {
+			return err
+		}
+		e.comments = append(e.comments, line)
+		e.Obsolete = true
+		p.target = nil
+	case strings.HasPrefix(trimmed, "#"):
+		e, err := p.entry(sectionComments)
+		if err != nil {
+			return err
+		}
+		e.comments = append(e.comments, line)
+		if flags, ok := strings.CutPrefix(trimmed, "#,"); ok {
+			for _, f := range strings.Split(flags, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					e.Flags = append(e.Flags, f)
+				}
+			}
+		}
+		p.target = nil
+	case strings.HasPrefix(trimmed, `"`):
+		if p.target == nil {
+			return fmt.Errorf("string without a keyword")
+		}
+		s, err := unquote(trimmed)
+		if err != nil {
+			return err
+		}
+		*p.target += s
+		p.raw(line)
+	default:
+		return p.keyword(line)
+	}
+	return nil
+}
+
+// keyword reads a msgctxt, msgid, msgid_plural or msgstr line.
+func (p *parser) keyword(line string) error {
+	m := keywordPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return fmt.Errorf("unexpected %q", line)
+	}
+	s, err := unquote(m[3])
+	if err != nil {
+		return err
+	}
+	switch keyword := m[1]; {
+	case keyword == "msgctxt" || keyword == "msgid":
+		e, err := p.entry(sectionHead)
+		if err != nil {
+			return err
+		}
+		if keyword == "msgid" && e.message || keyword == "msgctxt" && (e.Context != nil || e.message) {
+			return fmt.Errorf("%s without msgstr", keyword)
+		}
+		if keyword == "msgctxt" {
+			e.Context = &s
+			p.target = e.Context
+		} else {
+			e.ID, e.message = s, true
+			p.target = &e.ID
+		}
+		p.section = sectionHead
+	case keyword == "msgid_plural":
+		if p.e == nil || !p.e.message || p.section != sectionHead || p.e.Plural != nil {
+			return fmt.Errorf("msgid_plural without msgid")
+		}
+		p.e.Plural = &s
+		p.target = p.e.Plural
+	default:
+		if p.e == nil || !p.e.message {
+			return fmt.Errorf("%s without msgid", keyword)
+		}
+		if (m[2] != "") != (p.e.Plural != nil) || m[2] != "" && m[2] != fmt.Sprint(len(p.strs)) {
+			return fmt.Errorf("%s does not match the entry's plural forms", keyword)
+		}
+		p.strs = append(p.strs, &s)
+		p.target = &s
+		p.section = sectionTail
+	}
+	p.raw(line)
+	return nil
+}
+
+// raw keeps a keyword or string line in the section being read.
+func (p *parser) raw(line string) {
+	if p.section == sectionTail {
+		p.e.tail = append(p.e.tail, line)
+	} else {
+		p.e.head = append(p.e.head, line)
+	}
+}
@@ -0,0 +1,317 @@
+// Package po reads and updates gettext PO catalogs. Parsing keeps every
+// line of an entry, so rendering a catalog changes nothing but the
+// translations written into it: comments, references, flags and obsolete
+// entries come out as they went in.
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultPlurals is the number of plural forms of catalogs whose header
+// has no Plural-Forms.
+const defaultPlurals = 2
+
+var (
+	keywordPattern  = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr|msgstr\[(\d+)\])\s+(".*)$`)
+	npluralsPattern = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+)
+
+// Entry is a message of a catalog.
+type Entry struct {
+	Context *string
+	ID      string
+	Plural  *string
+	// Strings are the translations: msgstr, or msgstr[n] per plural form.
+	Strings []string
+	Flags   []string
+	// Obsolete entries ("#~") are kept but never translated.
+	Obsolete bool
+
+	message  bool     // whether it has a msgid, unlike a comment block
+	comments []string // raw comment lines
+	head     []string // raw msgctxt, msgid and msgid_plural lines
+	tail     []string // raw msgstr lines
+}
+
+// Catalog is a parsed PO file.
+type Catalog struct {
+	// blocks are the entries and the blank lines between them, in order;
+	// a nil entry is a blank line.
+	blocks  []*Entry
+	plurals int
+}
+
+// Parse reads a PO catalog.
+func Parse(text string) (*Catalog, error) {
+	c := &Catalog{}
+	p := parser{c: c}
+	for n, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		var err error
+		if strings.TrimSpace(line) == "" {
+			err = p.flush()
+			c.blocks = append(c.blocks, nil)
+		} else {
+			err = p.line(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	if err := p.flush(); err != nil {
+		return nil, err
+	}
+	c.plurals = defaultPlurals
+	if h := c.header(); h != nil && len(h.Strings) > 0 {
+		if m := npluralsPattern.FindStringSubmatch(h.Strings[0]); m != nil {
+			if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+				c.plurals = n
+			}
+		}
+	}
+	return c, nil
+}
+
+// header returns the header entry (empty msgid, no context), or nil.
+func (c *Catalog) header() *Entry {
+	for _, e := range c.Entries() {
+		if e.message && e.ID == "" && e.Context == nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Entries returns the entries of the catalog, header included.
+func (c *Catalog) Entries() []*Entry {
+	var entries []*Entry
+	for _, e := range c.blocks {
+		if e != nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Plurals is the number of plural forms of the catalog's language.
+func (c *Catalog) Plurals() int {
+	return c.plurals
+}
+
+// Fuzzy reports whether the entry is flagged fuzzy.
+func (e *Entry) Fuzzy() bool {
+	for _, f := range e.Flags {
+		if f == "fuzzy" {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending reports whether the entry needs a translation: it is untranslated
+// or fuzzy, and neither the header nor obsolete.
+func (e *Entry) Pending() bool {
+	if !e.message || e.ID == "" && e.Context == nil {
+		return false
+	}
+	if e.Fuzzy() {
+		return true
+	}
+	for _, s := range e.Strings {
+		if s != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Pending returns the entries needing a translation, in catalog order.
+func (c *Catalog) Pending() []*Entry {
+	var pending []*Entry
+	for _, e := range c.Entries() {
+		if e.Pending() {
+			pending = append(pending, e)
+		}
+	}
+	return pending
+}
+
+// Texts returns the source texts of the pending entries: the msgid of
+// each, followed by its msgid_plural when it has one.
+func (c *Catalog) Texts() []string {
+	var texts []string
+	for _, e := range c.Pending() {
+		texts = append(texts, e.ID)
+		if e.Plural != nil {
+			texts = append(texts, *e.Plural)
+		}
+	}
+	return texts
+}
+
+// Render returns the catalog with the translations of Texts written into
+// the pending entries, which lose their fuzzy flag and previous-msgid
+// ("#|") comments. Plural entries get the singular translation in their
+// first form and the plural one in the others, or the plural one alone
+// when the language has a single form.
+func (c *Catalog) Render(translations []string) string {
+	translated := map[*Entry][]string{}
+	next := 0
+	for _, e := range c.Pending() {
+		if next >= len(translations) {
+			break
+		}
+		if e.Plural == nil {
+			translated[e] = []string{translations[next]}
+			next++
+			continue
+		}
+		if next+1 >= len(translations) {
+			break
+		}
+		translated[e] = c.pluralForms(translations[next], translations[next+1])
+		next += 2
+	}
+
+	var b strings.Builder
+	for _, e := range c.blocks {
+		if e == nil {
+			b.WriteString("\n")
+			continue
+		}
+		strs, ok := translated[e]
+		if !ok {
+			writeLines(&b, e.comments, e.head, e.tail)
+			continue
+		}
+		writeLines(&b, e.translatedComments(), e.head, msgstrLines(strs, e.Plural != nil))
+	}
+	return b.String()
+}
+
+// pluralForms spreads the singular and plural translations over the
+// plural forms of the catalog.
+func (c *Catalog) pluralForms(singular, plural string) []string {
+	if c.plurals == 1 {
+		return []string{plural}
+	}
+	forms := make([]string, c.plurals)
+	forms[0] = singular
+	for i := 1; i < len(forms); i++ {
+		forms[i] = plural
+	}
+	return forms
+}
+
+// translatedComments returns the comments of a newly translated entry.
+func (e *Entry) translatedComments() []string {
+	var comments []string
+	for _, line := range e.comments {
+		switch {
+		case strings.HasPrefix(line, "#|"):
+			continue
+		case strings.HasPrefix(line, "#,"):
+			var flags []string
+			for _, f := range e.Flags {
+				if f != "fuzzy" {
+					flags = append(flags, f)
+				}
+			}
+			if len(flags) == 0 {
+				continue
+			}
+			line = "#, " + strings.Join(flags, ", ")
+		}
+		comments = append(comments, line)
+	}
+	return comments
+}
+
+// writeLines writes groups of lines to b, one per line.
+func writeLines(b *strings.Builder, groups ...[]string) {
+	for _, lines := range groups {
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+}
+
+// msgstrLines formats translations as msgstr lines, indexed for plural
+// entries.
+func msgstrLines(strs []string, plural bool) []string {
+	var lines []string
+	for i, s := range strs {
+		keyword := "msgstr"
+		if plural {
+			keyword = fmt.Sprintf("msgstr[%d]", i)
+		}
+		lines = append(lines, quoteLines(keyword, s)...)
+	}
+	return lines
+}
+
+// quoteLines formats a keyword and its string, splitting multi-line
+// strings after each newline the way msgmerge does.
+func quoteLines(keyword, s string) []string {
+	if i := strings.Index(s, "\n"); i < 0 || i == len(s)-1 {
+		return []string{keyword + " " + quote(s)}
+	}
+	lines := []string{keyword + ` ""`}
+	for s != "" {
+		part := s
+		if i := strings.Index(s, "\n"); i >= 0 {
+			part = s[:i+1]
+		}
+		lines = append(lines, quote(part))
+		s = s[len(part):]
+	}
+	return lines
+}
+
+// quote returns s as a PO string literal.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// unquote reads a PO string literal.
+func unquote(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	var b strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		if ch == '"' {
+			return "", fmt.Errorf("unescaped quote in %s", s)
+		}
+		if ch != '\\' {
+			b.WriteByte(ch)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", fmt.Errorf("dangling escape in %s", s)
+		}
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(body[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(body[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,118 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const catalog = `# Storefront catalog.
+msgid ""
+msgstr ""
+"Language: fr\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+#. Button label
+#: templates/cart.html:12
+msgid "Add to cart"
+msgstr ""
+
+#: templates/cart.html:30
+#, c-format
+msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] ""
+msgstr[1] ""
+
+#, fuzzy, python-format
+#| msgid "Free shipping"
+msgctxt "banner"
+msgid "Free shipping over %(amount)s"
+msgstr "Livraison gratuite"
+
+msgid "Checkout"
+msgstr "Paiement"
+
+msgid ""
+"Thank you!\n"
+"See you soon."
+msgstr ""
+
+#~ msgid "Old"
+#~ msgstr "Ancien"
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(catalog)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(c.Entries()) != 7 || c.Plurals() != 2 {
+		t.Fatalf("Parse() = %d entries, %d plurals, want 7 and 2", len(c.Entries()), c.Plurals())
+	}
+	fuzzy := c.Entries()[3]
+	if *fuzzy.Context != "banner" || !fuzzy.Fuzzy() || !reflect.DeepEqual(fuzzy.Flags, []string{"fuzzy", "python-format"}) {
+		t.Errorf("fuzzy entry = %+v", fuzzy)
+	}
+
+	want := []string{"Add to cart", "%d item", "%d items", "Free shipping over %(amount)s", "Thank you!\nSee you soon."}
+	if got := c.Texts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Texts() = %q, want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Parse(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := c.Render([]string{"Ajouter au panier", "%d article", "%d articles", "Livraison gratuite dès %(amount)s", "Merci !\nÀ bientôt."})
+
+	want := strings.NewReplacer(
+		"msgid \"Add to cart\"\nmsgstr \"\"", "msgid \"Add to cart\"\nmsgstr \"Ajouter au panier\"",
+		"msgstr[0] \"\"\nmsgstr[1] \"\"", "msgstr[0] \"%d article\"\nmsgstr[1] \"%d articles\"",
+		"#, fuzzy, python-format\n#| msgid \"Free shipping\"\n", "#, python-format\n",
+		"msgstr \"Livraison gratuite\"", "msgstr \"Livraison gratuite dès %(amount)s\"",
+		"\"See you soon.\"\nmsgstr \"\"", "\"See you soon.\"\nmsgstr \"\"\n\"Merci !\\n\"\n\"À bientôt.\"",
+	).Replace(catalog)
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without translations the catalog comes out unchanged
+	if got := c.Render(nil); got != catalog {
+		t.Errorf("Render(nil) =\n%s\nwant the catalog unchanged", got)
+	}
+}
+
+func TestRender_SinglePluralForm(t *testing.T) {
+	c, err := Parse("msgid \"\"\nmsgstr \"Plural-Forms: nplurals=1; plural=0;\\n\"\n\nmsgid \"%d day\"\nmsgid_plural \"%d days\"\nmsgstr[0] \"\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Render([]string{"%d日", "%d日間"}); !strings.HasSuffix(got, "msgstr[0] \"%d日間\"\n") {
+		t.Errorf("Render() =\n%s\nwant the plural translation as the only form", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"no msgstr", "msgid \"a\"\n\nmsgid \"b\"\nmsgstr \"\"\n"},
+		{"string without keyword", "\"a\"\n"},
+		{"unknown keyword", "msgid \"a\"\nmsgtxt \"b\"\n"},
+		{"unterminated string", "msgid \"a\nmsgstr \"\"\n"},
+		{"plural forms mismatch", "msgid \"a\"\nmsgstr[0] \"\"\n"},
+		{"msgstr without msgid", "msgstr \"a\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.text); err == nil {
+				t.Error("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package postedit applies regex replacement rules to machine translations,
+// fixing recurring model mistakes for specific language pairs.
+package postedit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ConfigEnv names the environment variable holding the rules as JSON
+// (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "POSTEDIT_RULES"
+
+// Wildcard matches any language in Rule.Source or Rule.Target.
+const Wildcard = "*"
+
+// Rule replaces every match of Pattern in translations for a language pair.
+//
+// Languages match exactly or by base language: a rule for "fr" also applies
+// to "fr_CA", while a rule for "fr_CA" applies only to "fr_CA".
+type Rule struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Pattern     string `json:"pattern"`     // RE2 syntax
+	Replacement string `json:"replacement"` // May reference groups as $1
+
+	re *regexp.Regexp
+}
+
+// Engine holds compiled rules.
+type Engine struct {
+	rules []Rule
+}
+
+// New compiles rules into an Engine.
+func New(rules []Rule) (*Engine, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i)
+		}
+		if rule.Source == "" || rule.Target == "" {
+			return nil, fmt.Errorf("rule %q: source and target are required", rule.Name)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: invalid pattern: %w", rule.Name, err)
+		}
+		rule.re = re
+		compiled = append(compiled, rule)
+	}
+	return &Engine{rules: compiled}, nil
+}
+
+// Load builds an Engine from the POSTEDIT_RULES config. Without config it
+// returns an empty Engine.
+func Load() (*Engine, error) {
+	var rules []Rule
+	if _, err := config.LoadJSON(ConfigEnv, &rules); err != nil {
+		return nil, err
+	}
+	return New(rules)
+}
+
+// Apply rewrites texts in place with every rule matching source→target and
+// returns the number of replacements made per rule name.
+func (e *Engine) Apply(source, target string, texts []string) map[string]int {
+	hits := map[string]int{}
+	if e == nil {
+		return hits
+	}
+
+	for _, rule := range e.rules {
+		if !matchesLang(rule.Source, source) || !matchesLang(rule.Target, target) {
+			continue
+		}
+		for i, text := range texts {
+			n := len(rule.re.FindAllStringIndex(text, -1))
+			if n == 0 {
+				continue
+			}
+			texts[i] = rule.re.ReplaceAllString(text, rule.Replacement)
+			hits[rule.Name] += n
+		}
+	}
+	return hits
+}
+
+// matchesLang reports whether a rule language applies to lang.
+func matchesLang(ruleLang, lang string) bool {
+	if ruleLang == Wildcard || ruleLang == lang {
+		return true
+	}
+	base, _, found := strings.Cut(lang, "_")
+	return found && ruleLang == base
+}
@@ -0,0 +1,104 @@
+package postedit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEngine_Apply(t *testing.T) {
+	engine, err := New([]Rule{
+		{Name: "wallet", Source: "es", Target: "fr", Pattern: `(?i)\bportfolio\b`, Replacement: "portefeuille"},
+		{Name: "double-space", Source: Wildcard, Target: Wildcard, Pattern: ` {2,}`, Replacement: " "},
+		{Name: "brazil-only", Source: "en", Target: "pt_BR", Pattern: `trem`, Replacement: "coisa"},
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		target   string
+		texts    []string
+		want     []string
+		wantHits map[string]int
+	}{
+		{
+			name:     "pair rule and wildcard",
+			source:   "es",
+			target:   "fr",
+			texts:    []string{"Portfolio en cuir", "Un  portfolio  noir"},
+			want:     []string{"portefeuille en cuir", "Un portefeuille noir"},
+			wantHits: map[string]int{"wallet": 2, "double-space": 2},
+		},
+		{
+			name:     "base language rule applies to variant",
+			source:   "es_MX",
+			target:   "fr_CA",
+			texts:    []string{"portfolio"},
+			want:     []string{"portefeuille"},
+			wantHits: map[string]int{"wallet": 1},
+		},
+		{
+			name:     "other pair untouched",
+			source:   "es",
+			target:   "it",
+			texts:    []string{"portfolio"},
+			want:     []string{"portfolio"},
+			wantHits: map[string]int{},
+		},
+		{
+			name:     "variant rule does not apply to base",
+			source:   "en",
+			target:   "pt",
+			texts:    []string{"trem"},
+			want:     []string{"trem"},
+			wantHits: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := engine.Apply(tt.source, tt.target, tt.texts)
+			if !reflect.DeepEqual(tt.texts, tt.want) {
+				t.Errorf("Apply() texts = %q, want %q", tt.texts, tt.want)
+			}
+			if !reflect.DeepEqual(hits, tt.wantHits) {
+				t.Errorf("Apply() hits = %v, want %v", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"missing name", Rule{Source: "es", Target: "fr", Pattern: "a"}},
+		{"missing target", Rule{Name: "r", Source: "es", Pattern: "a"}},
+		{"bad pattern", Rule{Name: "r", Source: "es", Target: "fr", Pattern: "("}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]Rule{tt.rule}); err == nil {
+				t.Error("New() should reject invalid rule")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(ConfigEnv, `[{"name": "r", "source": "es", "target": "fr", "pattern": "a", "replacement": "b"}]`)
+
+	engine, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	texts := []string{"a"}
+	engine.Apply("es", "fr", texts)
+	if texts[0] != "b" {
+		t.Errorf("loaded rule not applied, got %q", texts[0])
+	}
+}
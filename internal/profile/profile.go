@@ -0,0 +1,104 @@
+// Package profile loads per-tenant default request options from DynamoDB,
+// so tenants do not have to repeat the same options on every request.
+//
+// The table is keyed by the string attribute "tenantId"; the "defaults"
+// attribute holds the options as a JSON object using the request field
+// names, e.g. {"chunkStrategy": "balanced", "errorLocale": "es"}.
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table holding tenant profiles.
+const TableEnv = "TENANT_PROFILES_TABLE"
+
+// CacheTTL is how long a profile, or its absence, is reused before it is
+// read again, so profile edits apply within minutes without a deploy.
+const CacheTTL = 5 * time.Minute
+
+// Table is the subset of the DynamoDB client used by the store.
+type Table interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+}
+
+// Store reads tenant profiles, caching them for CacheTTL.
+type Store struct {
+	table Table
+	name  string
+	now   func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// entry is a cached profile; defaults is nil for tenants without one.
+type entry struct {
+	defaults json.RawMessage
+	expires  time.Time
+}
+
+// New creates a Store reading the named table.
+func New(table Table, name string) *Store {
+	return &Store{table: table, name: name, now: time.Now, cache: map[string]entry{}}
+}
+
+// Defaults returns the default options of a tenant as a JSON object, or nil
+// when the tenant has no profile.
+func (s *Store) Defaults(ctx context.Context, tenantID string) (json.RawMessage, error) {
+	s.mu.Lock()
+	e, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && s.now().Before(e.expires) {
+		return e.defaults, nil
+	}
+
+	defaults, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[tenantID] = entry{defaults: defaults, expires: s.now().Add(CacheTTL)}
+	s.mu.Unlock()
+	return defaults, nil
+}
+
+// load reads the profile of a tenant from the table.
+func (s *Store) load(ctx context.Context, tenantID string) (json.RawMessage, error) {
+	out, err := s.table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.name,
+		Key: map[string]types.AttributeValue{
+			"tenantId": &types.AttributeValueMemberS{Value: tenantID},
+		},
+		ProjectionExpression: stringPtr("defaults"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile of tenant %s: %w", tenantID, err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	attr, ok := out.Item["defaults"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("profile of tenant %s: defaults must be a string attribute", tenantID)
+	}
+	defaults := json.RawMessage(attr.Value)
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(defaults, &object); err != nil {
+		return nil, fmt.Errorf("profile of tenant %s: defaults must be a JSON object: %w", tenantID, err)
+	}
+	return defaults, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table of tenantId → defaults attribute.
+type memoryTable struct {
+	items map[string]types.AttributeValue
+	reads int
+	err   error
+}
+
+func (m *memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	m.reads++
+	if m.err != nil {
+		return nil, m.err
+	}
+	id := in.Key["tenantId"].(*types.AttributeValueMemberS).Value
+	attr, ok := m.items[id]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{"defaults": attr}}, nil
+}
+
+func TestStore_Defaults(t *testing.T) {
+	table := &memoryTable{items: map[string]types.AttributeValue{
+		"acme":    &types.AttributeValueMemberS{Value: `{"chunkStrategy": "balanced"}`},
+		"broken":  &types.AttributeValueMemberS{Value: `["balanced"]`},
+		"numeric": &types.AttributeValueMemberN{Value: "1"},
+	}}
+
+	tests := []struct {
+		tenant  string
+		want    string
+		wantErr bool
+	}{
+		{"acme", `{"chunkStrategy": "balanced"}`, false},
+		{"nobody", "", false},
+		{"broken", "", true},
+		{"numeric", "", true},
+	}
+
+	s := New(table, "profiles")
+	for _, tt := range tests {
+		t.Run(tt.tenant, func(t *testing.T) {
+			got, err := s.Defaults(context.Background(), tt.tenant)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Defaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Defaults() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_Cache(t *testing.T) {
+	table := &memoryTable{items: map[string]types.AttributeValue{}}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := New(table, "profiles")
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Defaults(context.Background(), "acme"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if table.reads != 1 {
+		t.Errorf("reads = %d, want 1 while cached", table.reads)
+	}
+
+	now = now.Add(CacheTTL)
+	table.items["acme"] = &types.AttributeValueMemberS{Value: `{"errorLocale": "es"}`}
+	got, _ := s.Defaults(context.Background(), "acme")
+	if table.reads != 2 || string(got) != `{"errorLocale": "es"}` {
+		t.Errorf("after expiry: reads = %d, defaults = %s", table.reads, got)
+	}
+
+	// Failures are not cached
+	table.err = errors.New("throttled")
+	now = now.Add(CacheTTL)
+	if _, err := s.Defaults(context.Background(), "acme"); err == nil {
+		t.Error("Defaults() should return the table error")
+	}
+	if _, err := s.Defaults(context.Background(), "acme"); err == nil || table.reads != 4 {
+		t.Errorf("failed reads should be retried, reads = %d", table.reads)
+	}
+}
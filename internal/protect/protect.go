@@ -0,0 +1,116 @@
+// Package protect hides spans of text from the translation models (URLs,
+// blobs, markup) by replacing them with numbered placeholders, and puts the
+// original spans back into the translation.
+package protect
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Span is a byte range [Start, End) of a text.
+type Span struct {
+	Start, End int
+}
+
+// placeholder formats the placeholder of the i-th masked span.
+func placeholder(i int) string {
+	return "__" + strconv.Itoa(i) + "__"
+}
+
+// placeholderPattern also matches placeholders the model spaced out, e.g. "__ 0 __".
+var placeholderPattern = regexp.MustCompile(`__\s*(\d+)\s*__`)
+
+// Literals returns the spans of text that already look like placeholders.
+// Masking a text masks them too, so that Restore puts them back verbatim
+// instead of taking them for the placeholders of other spans.
+func Literals(text string) []Span {
+	var spans []Span
+	for _, m := range placeholderPattern.FindAllStringIndex(text, -1) {
+		spans = append(spans, Span{Start: m[0], End: m[1]})
+	}
+	return spans
+}
+
+// Mask replaces spans of text with placeholders. Spans must be sorted and
+// must not overlap. It returns the masked text and the original spans, in
+// placeholder order.
+func Mask(text string, spans []Span) (string, []string) {
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	var b strings.Builder
+	originals := make([]string, len(spans))
+	last := 0
+	for i, s := range spans {
+		b.WriteString(text[last:s.Start])
+		b.WriteString(placeholder(i))
+		originals[i] = text[s.Start:s.End]
+		last = s.End
+	}
+	b.WriteString(text[last:])
+	return b.String(), originals
+}
+
+// Restore replaces the placeholders of translated with originals. Spans
+// whose placeholder the model dropped are appended at the end, so nothing is
+// lost; missing is their number.
+func Restore(translated string, originals []string) (restored string, missing int) {
+	if len(originals) == 0 {
+		return translated, 0
+	}
+
+	seen := make([]bool, len(originals))
+	restored = placeholderPattern.ReplaceAllStringFunc(translated, func(m string) string {
+		i, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(m)[1])
+		if err != nil || i >= len(originals) {
+			return m
+		}
+		seen[i] = true
+		return originals[i]
+	})
+
+	for i, ok := range seen {
+		if !ok {
+			restored += " " + originals[i]
+			missing++
+		}
+	}
+	return restored, missing
+}
+
+// OnlyPlaceholders reports whether masked text has placeholders and nothing
+// but spaces and punctuation around them, i.e. nothing left to translate.
+func OnlyPlaceholders(masked string) bool {
+	rest := placeholderPattern.ReplaceAllString(masked, "")
+	if rest == masked {
+		return false
+	}
+	for _, r := range rest {
+		if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Dropped returns, in order, the numbers of the n placeholders of a masked
+// text that translated lacks, i.e. the spans Restore appends at the end.
+func Dropped(translated string, n int) []int {
+	seen := make([]bool, n)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(translated, -1) {
+		if i, err := strconv.Atoi(m[1]); err == nil && i < n {
+			seen[i] = true
+		}
+	}
+	var dropped []int
+	for i, ok := range seen {
+		if !ok {
+			dropped = append(dropped, i)
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,103 @@
+package protect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskRestore(t *testing.T) {
+	text := "Ver https://example.com/x y SKU ABC123"
+	spans := []Span{{Start: 4, End: 25}, {Start: 32, End: 38}}
+
+	masked, originals := Mask(text, spans)
+	if masked != "Ver __0__ y SKU __1__" {
+		t.Fatalf("Mask() = %q", masked)
+	}
+	if len(originals) != 2 || originals[0] != "https://example.com/x" || originals[1] != "ABC123" {
+		t.Fatalf("originals = %q", originals)
+	}
+
+	tests := []struct {
+		name        string
+		translated  string
+		want        string
+		wantMissing int
+	}{
+		{"kept", "See __0__ and SKU __1__", "See https://example.com/x and SKU ABC123", 0},
+		{"reordered", "SKU __1__, see __0__", "SKU ABC123, see https://example.com/x", 0},
+		{"spaced out", "See __ 0 __ and SKU __1__", "See https://example.com/x and SKU ABC123", 0},
+		{"dropped", "See and SKU __1__", "See and SKU ABC123 https://example.com/x", 1},
+		{"unknown placeholder", "See __0__ __7__ __1__", "See https://example.com/x __7__ ABC123", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missing := Restore(tt.translated, originals)
+			if got != tt.want || missing != tt.wantMissing {
+				t.Errorf("Restore() = %q, %d, want %q, %d", got, missing, tt.want, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	text := "Ver https://example.com/x o el campo __0__ y __ 1 __"
+	literals := Literals(text)
+	if want := []Span{{Start: 37, End: 42}, {Start: 45, End: 52}}; !reflect.DeepEqual(literals, want) {
+		t.Fatalf("Literals() = %v, want %v", literals, want)
+	}
+
+	masked, originals := Mask(text, append([]Span{{Start: 4, End: 25}}, literals...))
+	if masked != "Ver __0__ o el campo __1__ y __2__" {
+		t.Fatalf("Mask() = %q", masked)
+	}
+	if got, missing := Restore("See __0__ or the field __1__ and __2__", originals); got != "See https://example.com/x or the field __0__ and __ 1 __" || missing != 0 {
+		t.Errorf("Restore() = %q, %d", got, missing)
+	}
+}
+
+func TestMask_NoSpans(t *testing.T) {
+	if masked, originals := Mask("hola", nil); masked != "hola" || originals != nil {
+		t.Errorf("Mask() = %q, %v", masked, originals)
+	}
+	if restored, missing := Restore("hello", nil); restored != "hello" || missing != 0 {
+		t.Errorf("Restore() = %q, %d", restored, missing)
+	}
+}
+
+func TestOnlyPlaceholders(t *testing.T) {
+	tests := []struct {
+		masked string
+		want   bool
+	}{
+		{"__0__", true},
+		{"__0__, __1__ ", true},
+		{"(__0__)", true},
+		{"Visita __0__", false},
+		{"sin marcadores", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := OnlyPlaceholders(tt.masked); got != tt.want {
+			t.Errorf("OnlyPlaceholders(%q) = %v, want %v", tt.masked, got, tt.want)
+		}
+	}
+}
+
+func TestDropped(t *testing.T) {
+	tests := []struct {
+		translated string
+		want       []int
+	}{
+		{"__0__ y __1__ y __2__", nil},
+		{"__ 2 __ y __0__", []int{1}},
+		{"nada __9__", []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		if got := Dropped(tt.translated, 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Dropped(%q) = %v, want %v", tt.translated, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package protoapi converts requests and responses between the protobuf
+// contract (api/translation.proto) and their JSON form, so protobuf
+// invocations take the same parsing, validation and handler path as JSON
+// ones. The messages are read from the embedded .proto file, which stays
+// the single description of the wire format: there is no generated code.
+package protoapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/pricofy/translation-manager/api"
+)
+
+// Format is the envelope format of protobuf payloads, and ContentType
+// their HTTP content type.
+const (
+	Format      = "protobuf"
+	ContentType = "application/x-protobuf"
+)
+
+// Message types of the contract.
+const (
+	RequestMessage  = "TranslateRequest"
+	ResponseMessage = "TranslateResponse"
+)
+
+var (
+	schemaOnce sync.Once
+	contract   schema
+	schemaErr  error
+)
+
+// messageType returns the message type name of the embedded contract.
+func messageType(name string) (*message, error) {
+	schemaOnce.Do(func() { contract, schemaErr = parseSchema(api.Proto) })
+	if schemaErr != nil {
+		return nil, fmt.Errorf("invalid %s: %w", api.ProtoFile, schemaErr)
+	}
+	m, ok := contract[name]
+	if !ok {
+		return nil, fmt.Errorf("%s has no message %s", api.ProtoFile, name)
+	}
+	return m, nil
+}
+
+// DecodeRequest returns the JSON request of a TranslateRequest. Fields
+// absent from the wire are absent from the JSON; unknown fields are
+// skipped.
+func DecodeRequest(data []byte) (json.RawMessage, error) {
+	m, err := messageType(RequestMessage)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := contract.decode(m, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf request: %w", err)
+	}
+	return json.Marshal(obj)
+}
+
+// EncodeResponse returns the TranslateResponse of a JSON response.
+// Response fields outside the contract are dropped.
+func EncodeResponse(resp json.RawMessage) ([]byte, error) {
+	m, err := messageType(ResponseMessage)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(resp, &obj); err != nil {
+		return nil, err
+	}
+	return contract.encode(nil, m, obj)
+}
+
+// decode reads a message of type m as a JSON object.
+func (s schema) decode(m *message, data []byte) (map[string]any, error) {
+	obj := map[string]any{}
+	for len(data) > 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		f := m.byNumber[number]
+		if f == nil {
+			if n = protowire.ConsumeFieldValue(number, typ, data); n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		values, n, err := s.decodeField(f, typ, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", m.name, f.name, err)
+		}
+		data = data[n:]
+		if !f.repeated {
+			obj[f.name] = values[len(values)-1]
+			continue
+		}
+		list, _ := obj[f.name].([]any)
+		obj[f.name] = append(list, values...)
+	}
+	return obj, nil
+}
+
+// decodeField reads the value of f at the start of data, or its values
+// when it is a packed repeated scalar, and the bytes they took.
+func (s schema) decodeField(f *field, typ protowire.Type, data []byte) ([]any, int, error) {
+	if f.repeated && typ == protowire.BytesType && f.kind != kindString && f.kind != kindMessage {
+		packed, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		var values []any
+		for len(packed) > 0 {
+			v, m, err := s.decodeValue(f, f.wireType(), packed)
+			if err != nil {
+				return nil, 0, err
+			}
+			values, packed = append(values, v), packed[m:]
+		}
+		return values, n, nil
+	}
+	v, n, err := s.decodeValue(f, typ, data)
+	return []any{v}, n, err
+}
+
+// wireType is the wire type of a value of f.
+func (f *field) wireType() protowire.Type {
+	switch f.kind {
+	case kindBool, kindInt32, kindInt64:
+		return protowire.VarintType
+	case kindDouble:
+		return protowire.Fixed64Type
+	}
+	return protowire.BytesType
+}
+
+// decodeValue reads one value of f encoded as typ.
+func (s schema) decodeValue(f *field, typ protowire.Type, data []byte) (any, int, error) {
+	if typ != f.wireType() {
+		return nil, 0, fmt.Errorf("wire type %d, want %d", typ, f.wireType())
+	}
+	switch f.kind {
+	case kindBool, kindInt32, kindInt64:
+		v, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		if f.kind == kindBool {
+			return v != 0, n, nil
+		}
+		if f.kind == kindInt32 {
+			return int64(int32(v)), n, nil
+		}
+		return int64(v), n, nil
+	case kindDouble:
+		v, n := protowire.ConsumeFixed64(data)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		return math.Float64frombits(v), n, nil
+	}
+	b, n := protowire.ConsumeBytes(data)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+	if f.kind == kindMessage {
+		obj, err := s.decode(s[f.message], b)
+		return obj, n, err
+	}
+	if !utf8.Valid(b) {
+		return nil, 0, fmt.Errorf("string is not valid UTF-8")
+	}
+	return string(b), n, nil
+}
+
+// encode appends the message of type m holding the JSON object obj to b,
+// fields in number order.
+func (s schema) encode(b []byte, m *message, obj map[string]any) ([]byte, error) {
+	for _, f := range m.fields {
+		v, ok := obj[f.name]
+		if !ok || v == nil {
+			continue
+		}
+		values := []any{v}
+		if f.repeated {
+			list, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s: want an array", m.name, f.name)
+			}
+			values = list
+		}
+		var err error
+		if b, err = s.encodeField(b, f, values); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", m.name, f.name, err)
+		}
+	}
+	return b, nil
+}
+
+// encodeField appends the values of f to b, packed for repeated scalars.
+func (s schema) encodeField(b []byte, f *field, values []any) ([]byte, error) {
+	if f.repeated && f.wireType() != protowire.BytesType {
+		var packed []byte
+		for _, v := range values {
+			var err error
+			if packed, err = s.encodeValue(packed, f, v); err != nil {
+				return nil, err
+			}
+		}
+		if len(packed) == 0 {
+			return b, nil
+		}
+		b = protowire.AppendTag(b, f.number, protowire.BytesType)
+		return protowire.AppendBytes(b, packed), nil
+	}
+	for _, v := range values {
+		b = protowire.AppendTag(b, f.number, f.wireType())
+		var err error
+		if b, err = s.encodeValue(b, f, v); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// encodeValue appends the value v of f to b, without its tag.
+func (s schema) encodeValue(b []byte, f *field, v any) ([]byte, error) {
+	switch f.kind {
+	case kindString:
+		if str, ok := v.(string); ok {
+			return protowire.AppendString(b, str), nil
+		}
+	case kindBool:
+		if flag, ok := v.(bool); ok {
+			return protowire.AppendVarint(b, protowire.EncodeBool(flag)), nil
+		}
+	case kindInt32, kindInt64:
+		if num, ok := v.(float64); ok {
+			return protowire.AppendVarint(b, uint64(int64(num))), nil
+		}
+	case kindDouble:
+		if num, ok := v.(float64); ok {
+			return protowire.AppendFixed64(b, math.Float64bits(num)), nil
+		}
+	case kindMessage:
+		if obj, ok := v.(map[string]any); ok {
+			body, err := s.encode(nil, s[f.message], obj)
+			return protowire.AppendBytes(b, body), err
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v", v)
+}
@@ -0,0 +1,161 @@
+package protoapi
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/pricofy/translation-manager/api"
+)
+
+// request builds a TranslateRequest on the wire.
+func request() []byte {
+	var b []byte
+	for _, text := range []string{"Buen estado", "Nuevo"} {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, text)
+	}
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, "es")
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, "fr")
+	b = protowire.AppendTag(b, 17, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	b = protowire.AppendTag(b, 18, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(-1.5))
+	b = protowire.AppendTag(b, 36, protowire.VarintType)
+	b = protowire.AppendVarint(b, 5000)
+	// A field from a newer contract is skipped
+	b = protowire.AppendTag(b, 999, protowire.BytesType)
+	return protowire.AppendString(b, "later")
+}
+
+func TestDecodeRequest(t *testing.T) {
+	got, err := DecodeRequest(request())
+	if err != nil {
+		t.Fatalf("DecodeRequest() error = %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(got, &obj); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"texts":             []any{"Buen estado", "Nuevo"},
+		"sourceLang":        "es",
+		"targetLang":        "fr",
+		"includeConfidence": true,
+		"minConfidence":     -1.5,
+		"timeoutMs":         5000.0,
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("DecodeRequest() = %v, want %v", obj, want)
+	}
+}
+
+func TestDecodeRequest_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", request()[:5]},
+		{"wrong wire type", protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 1)},
+		{"invalid UTF-8", protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), []byte{0xff})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeRequest(tt.data); err == nil {
+				t.Error("DecodeRequest() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestEncodeResponse(t *testing.T) {
+	resp := `{"translations": ["Bon état", "Neuf"], "chunksProcessed": 1, "confidence": [0.9, 0.4],
+		"lowConfidence": [1], "route": {"steps": ["romance-en", "en-romance"], "pivotLang": "en"},
+		"warnings": [{"code": "LOW_CONFIDENCE", "message": "text 1", "index": 0}],
+		"catalog": {"languages": []}}`
+	data, err := EncodeResponse(json.RawMessage(resp))
+	if err != nil {
+		t.Fatalf("EncodeResponse() error = %v", err)
+	}
+
+	m, _ := messageType(ResponseMessage)
+	got, err := contract.decode(m, data)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	want := map[string]any{
+		"translations":    []any{"Bon état", "Neuf"},
+		"chunksProcessed": int64(1),
+		"confidence":      []any{0.9, 0.4},
+		"lowConfidence":   []any{int64(1)},
+		"route":           map[string]any{"steps": []any{"romance-en", "en-romance"}, "pivotLang": "en"},
+		"warnings":        []any{map[string]any{"code": "LOW_CONFIDENCE", "message": "text 1", "index": int64(0)}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeResponse() decodes to %v, want %v", got, want)
+	}
+}
+
+// TestContract checks every field of the protobuf contract is a field of
+// the JSON type of the same name.
+func TestContract(t *testing.T) {
+	var doc struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(api.ResponseSchema, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := messageType(RequestMessage); err != nil {
+		t.Fatal(err)
+	}
+	jsonTypes := map[string]string{RequestMessage: "Request", ResponseMessage: "Response"}
+	for name, m := range contract {
+		def := name
+		if jsonType, ok := jsonTypes[name]; ok {
+			def = jsonType
+		}
+		properties, ok := doc.Defs[def]
+		if !ok {
+			t.Errorf("%s has no JSON type %s", name, def)
+			continue
+		}
+		for _, f := range m.fields {
+			if _, ok := properties.Properties[f.name]; !ok {
+				t.Errorf("%s.%s is not a field of %s", name, f.name, def)
+			}
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		proto   string
+		wantErr bool
+	}{
+		{"fields", "message A {\n  repeated string a_b = 1;\n  B b = 2; // note\n}\nmessage B {\n  optional int32 c = 1;\n}", false},
+		{"duplicate number", "message A {\n  string a = 1;\n  string b = 1;\n}", true},
+		{"unknown type", "message A {\n  C c = 1;\n}", true},
+		{"unsupported declaration", "message A {\n  map<string, string> m = 1;\n}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchema([]byte(tt.proto))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s["A"].byNumber[1].name != "aB" {
+				t.Errorf("field 1 of A = %+v, want JSON name aB", s["A"].byNumber[1])
+			}
+		})
+	}
+}
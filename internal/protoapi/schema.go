@@ -0,0 +1,131 @@
+package protoapi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// kind is the protobuf type of a field.
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt32
+	kindInt64
+	kindDouble
+	kindMessage
+)
+
+var scalarKinds = map[string]kind{
+	"string": kindString,
+	"bool":   kindBool,
+	"int32":  kindInt32,
+	"int64":  kindInt64,
+	"double": kindDouble,
+}
+
+// field is a field of a message.
+type field struct {
+	number   protowire.Number
+	name     string // JSON name: the lowerCamelCase proto name
+	kind     kind
+	repeated bool
+	message  string // message type of a kindMessage field
+}
+
+// message is a message type, with its fields by number and JSON name.
+type message struct {
+	name     string
+	fields   []*field
+	byNumber map[protowire.Number]*field
+	byName   map[string]*field
+}
+
+// schema holds the message types of a .proto file.
+type schema map[string]*message
+
+var (
+	messagePattern = regexp.MustCompile(`^message\s+(\w+)\s*\{$`)
+	fieldPattern   = regexp.MustCompile(`^(repeated\s+|optional\s+)?(\w+)\s+(\w+)\s*=\s*(\d+);$`)
+)
+
+// parseSchema reads the messages of a .proto file: flat messages of
+// string, bool, int32, int64, double and message fields, which is all the
+// contract uses.
+func parseSchema(proto []byte) (schema, error) {
+	s := schema{}
+	var current *message
+	scanner := bufio.NewScanner(bytes.NewReader(proto))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		switch m := messagePattern.FindStringSubmatch(line); {
+		case line == "" || current == nil && m == nil:
+		case m != nil:
+			current = &message{name: m[1], byNumber: map[protowire.Number]*field{}, byName: map[string]*field{}}
+			s[current.name] = current
+		case line == "}":
+			current = nil
+		default:
+			if err := current.addField(line); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+		}
+	}
+	return s, s.check()
+}
+
+// addField adds the field declared by line.
+func (m *message) addField(line string) error {
+	d := fieldPattern.FindStringSubmatch(line)
+	if d == nil {
+		return fmt.Errorf("unsupported declaration %q in %s", line, m.name)
+	}
+	number, err := strconv.Atoi(d[4])
+	if err != nil {
+		return fmt.Errorf("invalid field number %q in %s", d[4], m.name)
+	}
+	f := &field{number: protowire.Number(number), name: jsonName(d[3]), repeated: strings.TrimSpace(d[1]) == "repeated"}
+	if k, ok := scalarKinds[d[2]]; ok {
+		f.kind = k
+	} else {
+		f.kind, f.message = kindMessage, d[2]
+	}
+	if !f.number.IsValid() || m.byNumber[f.number] != nil || m.byName[f.name] != nil {
+		return fmt.Errorf("duplicate or invalid field %s = %d in %s", d[3], number, m.name)
+	}
+	m.fields = append(m.fields, f)
+	m.byNumber[f.number], m.byName[f.name] = f, f
+	return nil
+}
+
+// check verifies every message field names a message of s.
+func (s schema) check() error {
+	for _, m := range s {
+		for _, f := range m.fields {
+			if f.kind == kindMessage && s[f.message] == nil {
+				return fmt.Errorf("%s.%s: unknown type %s", m.name, f.name, f.message)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonName returns the JSON name of a proto field name, as protoc derives
+// it: "source_lang" is "sourceLang".
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,196 @@
+// Package ratelimit bounds the load a manager instance sends to each
+// translator: a cap on its concurrent invocations and a token bucket on
+// its invocation rate. Invocations over the limit queue for up to a
+// maximum wait, and are shed with ErrShed past it, so a spike on one pair
+// cannot throttle a translator other pairs share.
+//
+// Limits hold per manager instance (Lambda container), not across them.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrShed is returned for invocations a limiter turned away.
+var ErrShed = errors.New("translator load limit reached")
+
+// Limit is the load allowed on one translator.
+type Limit struct {
+	// MaxConcurrent caps the invocations in flight; 0 means no cap.
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+	// Rate is the sustained invocations per second, with bursts of up to
+	// Burst (default 1) above it; 0 means no rate limit.
+	Rate  float64 `json:"rate,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+	// MaxWaitMs is how long an invocation may queue for a slot or a
+	// token before it is shed; 0 means as long as its context allows.
+	MaxWaitMs int `json:"maxWaitMs,omitempty"`
+}
+
+// Validate checks the limit.
+func (l Limit) Validate() error {
+	if l.MaxConcurrent < 0 || l.Rate < 0 || l.Burst < 0 || l.MaxWaitMs < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// Limiter enforces a Limit.
+type Limiter struct {
+	slots   chan struct{}
+	bucket  *bucket
+	maxWait time.Duration
+}
+
+// New creates a Limiter for l.
+func New(l Limit) *Limiter {
+	lim := &Limiter{maxWait: time.Duration(l.MaxWaitMs) * time.Millisecond}
+	if l.MaxConcurrent > 0 {
+		lim.slots = make(chan struct{}, l.MaxConcurrent)
+	}
+	if l.Rate > 0 {
+		lim.bucket = newBucket(l.Rate, max(l.Burst, 1), time.Now())
+	}
+	return lim
+}
+
+// Acquire waits for a concurrency slot and a rate token. It returns the
+// function releasing the slot, or an error wrapping ErrShed when the wait
+// would exceed the maximum wait or the deadline of ctx.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	ctx, cancel := l.waitContext(ctx)
+	defer cancel()
+
+	release = func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %d invocations in flight", ErrShed, cap(l.slots))
+		}
+	}
+	if l.bucket != nil {
+		if err := l.bucket.wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// Wait takes another rate token without a concurrency slot, for the retry
+// of an invocation that still holds its slot. It returns an error wrapping
+// ErrShed as Acquire does.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.bucket == nil {
+		return nil
+	}
+	ctx, cancel := l.waitContext(ctx)
+	defer cancel()
+	return l.bucket.wait(ctx)
+}
+
+// waitContext bounds ctx by the maximum wait.
+func (l *Limiter) waitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.maxWait <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, l.maxWait)
+}
+
+// bucket is a token bucket refilled at rate tokens per second up to burst.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// reserve takes a token at now, possibly ahead of its refill, and returns
+// how long to wait for it. It takes nothing when the wait would end after
+// deadline (if set).
+func (b *bucket) reserve(now, deadline time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	if !deadline.IsZero() && now.Add(wait).After(deadline) {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// refund gives back a token taken by reserve.
+func (b *bucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// wait takes a token, sleeping until it is due. A wait cut short by ctx
+// gives the token back.
+func (b *bucket) wait(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	wait, ok := b.reserve(time.Now(), deadline)
+	if !ok {
+		return fmt.Errorf("%w: %g invocations per second", ErrShed, b.rate)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.refund()
+		return fmt.Errorf("%w: %g invocations per second", ErrShed, b.rate)
+	}
+}
+
+// Set holds the limiters of the translators that have a limit.
+type Set struct {
+	limiters map[string]*Limiter
+}
+
+// NewSet creates limiters for limits, keyed by function name.
+func NewSet(limits map[string]Limit) *Set {
+	s := &Set{limiters: make(map[string]*Limiter, len(limits))}
+	for function, l := range limits {
+		s.limiters[function] = New(l)
+	}
+	return s
+}
+
+// Acquire acquires the limiter of function (see Limiter.Acquire).
+// Functions without a limit are not limited.
+func (s *Set) Acquire(ctx context.Context, function string) (func(), error) {
+	if s == nil || s.limiters[function] == nil {
+		return func() {}, nil
+	}
+	return s.limiters[function].Acquire(ctx)
+}
+
+// Wait takes another rate token of function (see Limiter.Wait).
+func (s *Set) Wait(ctx context.Context, function string) error {
+	if s == nil || s.limiters[function] == nil {
+		return nil
+	}
+	return s.limiters[function].Wait(ctx)
+}
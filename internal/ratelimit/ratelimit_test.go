@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l := New(Limit{MaxConcurrent: 2, MaxWaitMs: 20})
+	ctx := context.Background()
+
+	first, err := l.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := l.Acquire(ctx); !errors.Is(err, ErrShed) {
+		t.Fatalf("Acquire() over the cap error = %v, want ErrShed", err)
+	}
+
+	first()
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Errorf("Acquire() after a release error = %v", err)
+	}
+}
+
+func TestLimiter_Queue(t *testing.T) {
+	l := New(Limit{MaxConcurrent: 1, MaxWaitMs: 1000})
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A queued invocation gets the slot released while it waits
+	done := make(chan error)
+	go func() {
+		_, err := l.Acquire(context.Background())
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("queued Acquire() error = %v", err)
+	}
+}
+
+func TestLimiter_Deadline(t *testing.T) {
+	l := New(Limit{MaxConcurrent: 1})
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, ErrShed) {
+		t.Errorf("Acquire() past the deadline error = %v, want ErrShed", err)
+	}
+}
+
+func TestBucket_Reserve(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newBucket(10, 2, now)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		deadline time.Time
+		wantWait time.Duration
+		wantOK   bool
+	}{
+		{"burst 1", now, time.Time{}, 0, true},
+		{"burst 2", now, time.Time{}, 0, true},
+		{"past the deadline", now, now.Add(50 * time.Millisecond), 100 * time.Millisecond, false},
+		{"queued", now, time.Time{}, 100 * time.Millisecond, true},
+		{"refilled", now.Add(time.Second), time.Time{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		wait, ok := b.reserve(tt.at, tt.deadline)
+		if ok != tt.wantOK || (wait-tt.wantWait).Abs() > time.Millisecond {
+			t.Errorf("%s: reserve() = %v, %v, want %v, %v", tt.name, wait, ok, tt.wantWait, tt.wantOK)
+		}
+	}
+}
+
+func TestLimiter_RateShed(t *testing.T) {
+	l := New(Limit{Rate: 1, MaxWaitMs: 10})
+	ctx := context.Background()
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := l.Acquire(ctx); !errors.Is(err, ErrShed) {
+		t.Errorf("Acquire() over the rate error = %v, want ErrShed", err)
+	}
+}
+
+func TestBucket_WaitRefund(t *testing.T) {
+	b := newBucket(1, 1, time.Now())
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A canceled wait gives back the token it took ahead of its refill
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := b.wait(ctx); !errors.Is(err, ErrShed) {
+		t.Fatalf("wait() canceled error = %v, want ErrShed", err)
+	}
+	now := time.Now()
+	if wait, ok := b.reserve(now, now.Add(1500*time.Millisecond)); !ok {
+		t.Errorf("reserve() after a canceled wait = %v, want the next token within a second", wait)
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := New(Limit{MaxConcurrent: 1, Rate: 1, MaxWaitMs: 10})
+	ctx := context.Background()
+	if _, err := l.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Wait takes a token without a slot, so only the rate sheds it
+	if err := l.Wait(ctx); !errors.Is(err, ErrShed) {
+		t.Errorf("Wait() over the rate error = %v, want ErrShed", err)
+	}
+	if err := New(Limit{MaxConcurrent: 1}).Wait(ctx); err != nil {
+		t.Errorf("Wait() without a rate error = %v", err)
+	}
+}
+
+func TestSet_Acquire(t *testing.T) {
+	s := NewSet(map[string]Limit{"translator-romance-en": {MaxConcurrent: 1, MaxWaitMs: 1}})
+	ctx := context.Background()
+
+	if _, err := s.Acquire(ctx, "translator-romance-en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Acquire(ctx, "translator-romance-en"); !errors.Is(err, ErrShed) {
+		t.Errorf("Acquire() over the limit error = %v, want ErrShed", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.Acquire(ctx, "translator-en-de"); err != nil {
+			t.Errorf("Acquire() of an unlimited function error = %v", err)
+		}
+	}
+	var none *Set
+	if _, err := none.Acquire(ctx, "translator-romance-en"); err != nil {
+		t.Errorf("nil Set Acquire() error = %v", err)
+	}
+}
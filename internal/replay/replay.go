@@ -0,0 +1,197 @@
+// Package replay re-sends recorded translation requests through a
+// deployment of the manager and reports how their outcomes changed, to
+// recover from incidents: cases come from replay capture files in S3 or
+// from exported dead-letter queue messages.
+//
+// Every case carries an idempotency key. Replayed as a background job run
+// under that key, a case the job journal has already completed returns its
+// stored response instead of being translated again, so an interrupted
+// replay can simply be run again.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// Outcome statuses.
+const (
+	// StatusUnchanged is a case translated exactly as recorded.
+	StatusUnchanged = "unchanged"
+	// StatusChanged is a case with at least one translation that differs
+	// from the recorded one.
+	StatusChanged = "changed"
+	// StatusTranslated is a case without recorded translations, such as a
+	// dead-lettered job, that now succeeds.
+	StatusTranslated = "translated"
+	// StatusFailed is a case that fails again.
+	StatusFailed = "failed"
+)
+
+// Case is one request to replay.
+type Case struct {
+	// ID is the idempotency key of the case, sent as the jobId.
+	ID      string
+	Request handler.Request
+	// Expected are the recorded translations of Request.Texts, or nil when
+	// only the request was recorded.
+	Expected []string
+}
+
+// caseID derives a deterministic idempotency key from parts.
+func caseID(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return "replay-" + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Change is a text translated differently than recorded.
+type Change struct {
+	Index    int    `json:"index"`
+	Source   string `json:"source"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// Outcome is the result of replaying one case, one line of the report.
+type Outcome struct {
+	ID         string   `json:"id"`
+	SourceLang string   `json:"sourceLang"`
+	TargetLang string   `json:"targetLang"`
+	Texts      int      `json:"texts"`
+	Status     string   `json:"status"`
+	Changes    []Change `json:"changes,omitempty"`
+	ErrorCode  string   `json:"errorCode,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Invoker is the subset of the Lambda client used to replay cases.
+type Invoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// Replayer sends cases to a manager function.
+type Replayer struct {
+	Invoker  Invoker
+	Function string
+	// Idempotent replays every case as a background job run keyed by its
+	// ID, which needs async jobs (and, to skip completed cases, the job
+	// journal) in the deployment. Otherwise cases are plain requests.
+	Idempotent bool
+	// Concurrency bounds the cases in flight; values below 1 mean 1.
+	Concurrency int
+}
+
+// Run replays cases and returns their outcomes in the same order.
+func (r *Replayer) Run(ctx context.Context, cases []Case) []Outcome {
+	outcomes := make([]Outcome, len(cases))
+	err := workpool.Pool{Limit: max(r.Concurrency, 1)}.Run(ctx, len(cases), func(ctx context.Context, i int) error {
+		outcomes[i] = r.replay(ctx, cases[i])
+		return nil
+	})
+	// Only a panic fails a case here; report it like any other failure
+	for _, f := range workpool.Failures(err) {
+		outcomes[f.Index] = Outcome{ID: cases[f.Index].ID, Status: StatusFailed, Error: f.Err.Error()}
+	}
+	return outcomes
+}
+
+// replay sends one case and compares its response with the record.
+func (r *Replayer) replay(ctx context.Context, c Case) Outcome {
+	out := Outcome{ID: c.ID, SourceLang: c.Request.SourceLang, TargetLang: c.Request.TargetLang, Texts: len(c.Request.Texts)}
+	resp, err := r.invoke(ctx, c)
+	if err != nil {
+		out.Status, out.Error = StatusFailed, err.Error()
+		return out
+	}
+	if resp.Error != nil {
+		out.Status, out.ErrorCode, out.Error = StatusFailed, resp.ErrorCode, resp.Error.Message
+		return out
+	}
+	out.Changes = diff(c, resp.Translations)
+	switch {
+	case c.Expected == nil:
+		out.Status = StatusTranslated
+	case len(out.Changes) > 0:
+		out.Status = StatusChanged
+	default:
+		out.Status = StatusUnchanged
+	}
+	return out
+}
+
+// invoke sends the request of c to the manager synchronously.
+func (r *Replayer) invoke(ctx context.Context, c Case) (*handler.Response, error) {
+	var event interface{} = c.Request
+	if r.Idempotent {
+		req := c.Request
+		req.Async, req.JobID = true, c.ID
+		event = handler.JobRun{Source: handler.JobRunSource, Request: req}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	result, err := r.Invoker.Invoke(ctx, &lambda.InvokeInput{FunctionName: &r.Function, Payload: payload})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke %s: %w", r.Function, err)
+	}
+	if result.FunctionError != nil {
+		return nil, fmt.Errorf("lambda error: %s: %s", *result.FunctionError, result.Payload)
+	}
+	var resp handler.Response
+	if err := json.Unmarshal(result.Payload, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &resp, nil
+}
+
+// diff lists the texts of c translated differently than recorded.
+func diff(c Case, translations []string) []Change {
+	if c.Expected == nil {
+		return nil
+	}
+	var changes []Change
+	for i, expected := range c.Expected {
+		var actual string
+		if i < len(translations) {
+			actual = translations[i]
+		}
+		if actual != expected {
+			changes = append(changes, Change{Index: i, Source: c.Request.Texts[i], Expected: expected, Actual: actual})
+		}
+	}
+	return changes
+}
+
+// Summary counts outcomes by status.
+func Summary(outcomes []Outcome) map[string]int {
+	counts := map[string]int{}
+	for _, o := range outcomes {
+		counts[o.Status]++
+	}
+	return counts
+}
+
+// WriteReport writes outcomes as JSONL, one outcome per line.
+func WriteReport(w io.Writer, outcomes []Outcome) error {
+	enc := json.NewEncoder(w)
+	for _, o := range outcomes {
+		if err := enc.Encode(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
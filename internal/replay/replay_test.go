@@ -0,0 +1,111 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// fakeManager answers requests with the upper-cased texts, or with errs by
+// first text.
+type fakeManager struct {
+	mu       sync.Mutex
+	requests []handler.Request
+	errs     map[string]string
+}
+
+func (m *fakeManager) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var run handler.JobRun
+	if err := json.Unmarshal(params.Payload, &run); err != nil {
+		return nil, err
+	}
+	req := run.Request
+	if run.Source != handler.JobRunSource {
+		if err := json.Unmarshal(params.Payload, &req); err != nil {
+			return nil, err
+		}
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	m.mu.Unlock()
+
+	resp := handler.Response{Translations: []string{}}
+	if code, ok := m.errs[req.Texts[0]]; ok {
+		resp.Error, resp.ErrorCode = &handler.ErrorInfo{Code: code, Message: "boom"}, code
+	} else {
+		for _, text := range req.Texts {
+			resp.Translations = append(resp.Translations, strings.ToUpper(text))
+		}
+	}
+	payload, err := json.Marshal(resp)
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestReplayer_Run(t *testing.T) {
+	cases := []Case{
+		{ID: "a", Request: handler.Request{Texts: []string{"hola", "adiós"}, SourceLang: "es", TargetLang: "en"}, Expected: []string{"HOLA", "ADIÓS"}},
+		{ID: "b", Request: handler.Request{Texts: []string{"rojo"}, SourceLang: "es", TargetLang: "en"}, Expected: []string{"red"}},
+		{ID: "c", Request: handler.Request{Texts: []string{"azul"}, SourceLang: "es", TargetLang: "fr"}},
+		{ID: "d", Request: handler.Request{Texts: []string{"falla"}, SourceLang: "es", TargetLang: "en"}},
+	}
+	manager := &fakeManager{errs: map[string]string{"falla": "TRANSLATION_FAILED"}}
+	r := &Replayer{Invoker: manager, Function: "manager", Idempotent: true, Concurrency: 2}
+
+	outcomes := r.Run(context.Background(), cases)
+
+	var statuses []string
+	for _, o := range outcomes {
+		statuses = append(statuses, o.Status)
+	}
+	want := []string{StatusUnchanged, StatusChanged, StatusTranslated, StatusFailed}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	if got := outcomes[1].Changes; !reflect.DeepEqual(got, []Change{{Index: 0, Source: "rojo", Expected: "red", Actual: "ROJO"}}) {
+		t.Errorf("changes = %+v", got)
+	}
+	if outcomes[3].ErrorCode != "TRANSLATION_FAILED" {
+		t.Errorf("error code = %q, want TRANSLATION_FAILED", outcomes[3].ErrorCode)
+	}
+	for _, req := range manager.requests {
+		if !req.Async || req.JobID == "" {
+			t.Errorf("request %q sent without an idempotency key", req.Texts)
+		}
+	}
+	if got := Summary(outcomes); got[StatusFailed] != 1 || got[StatusUnchanged] != 1 {
+		t.Errorf("Summary() = %v", got)
+	}
+}
+
+func TestReplayer_InvokeError(t *testing.T) {
+	r := &Replayer{Invoker: failingInvoker{}, Function: "manager"}
+	outcomes := r.Run(context.Background(), []Case{{ID: "a", Request: handler.Request{Texts: []string{"hola"}}}})
+	if outcomes[0].Status != StatusFailed || !strings.Contains(outcomes[0].Error, "throttled") {
+		t.Errorf("outcome = %+v, want a failure with the invoke error", outcomes[0])
+	}
+}
+
+type failingInvoker struct{}
+
+func (failingInvoker) Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	return nil, errors.New("throttled")
+}
+
+func TestWriteReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, []Outcome{{ID: "a", Status: StatusUnchanged}, {ID: "b", Status: StatusFailed}}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("report has %d lines, want 2: %s", lines, buf.String())
+	}
+}
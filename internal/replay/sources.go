@@ -0,0 +1,175 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pricofy/translation-manager/internal/capture"
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// Objects is the subset of the S3 client used to read capture files.
+type Objects interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Capture reads replay capture files, {prefix}{pair}/dt={date}/{ts}.jsonl.
+type Capture struct {
+	Objects Objects
+	Bucket  string
+	Prefix  string
+	// BatchSize is the most texts of one pair per case; values below 1
+	// mean one text per case.
+	BatchSize int
+}
+
+// Cases returns the records captured in [from, to) as cases of one pair
+// each, in capture order.
+func (c *Capture) Cases(ctx context.Context, from, to time.Time) ([]Case, error) {
+	keys, err := c.keys(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var records []capture.Record
+	for _, key := range keys {
+		got, err := c.read(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range got {
+			if !rec.CapturedAt.Before(from) && rec.CapturedAt.Before(to) {
+				records = append(records, rec)
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CapturedAt.Before(records[j].CapturedAt) })
+	return batchRecords(records, max(c.BatchSize, 1)), nil
+}
+
+// keys lists the capture files whose date partition overlaps [from, to).
+func (c *Capture) keys(ctx context.Context, from, to time.Time) ([]string, error) {
+	first, last := from.UTC().Format("2006-01-02"), to.UTC().Format("2006-01-02")
+	var keys []string
+	var token *string
+	for {
+		out, err := c.Objects.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: &c.Bucket, Prefix: &c.Prefix, ContinuationToken: token})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", c.Bucket, c.Prefix, err)
+		}
+		for _, obj := range out.Contents {
+			key := *obj.Key
+			_, rest, ok := strings.Cut(key, "/dt=")
+			if !ok || len(rest) < len(first) {
+				continue
+			}
+			if date := rest[:len(first)]; date >= first && date <= last {
+				keys = append(keys, key)
+			}
+		}
+		if out.NextContinuationToken == nil {
+			return keys, nil
+		}
+		token = out.NextContinuationToken
+	}
+}
+
+// read decodes one capture file.
+func (c *Capture) read(ctx context.Context, key string) ([]capture.Record, error) {
+	out, err := c.Objects.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.Bucket, Key: &key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", c.Bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	var records []capture.Record
+	dec := json.NewDecoder(out.Body)
+	for {
+		var rec capture.Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid capture file s3://%s/%s: %w", c.Bucket, key, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// batchRecords groups consecutive records of a pair into cases of at most
+// size texts.
+func batchRecords(records []capture.Record, size int) []Case {
+	var cases []Case
+	open := map[string]int{} // pair → index of its case being filled
+	for _, rec := range records {
+		pair := rec.SourceLang + "-" + rec.TargetLang
+		i, ok := open[pair]
+		if !ok || len(cases[i].Request.Texts) >= size {
+			cases = append(cases, Case{Request: handler.Request{SourceLang: rec.SourceLang, TargetLang: rec.TargetLang}, Expected: []string{}})
+			i = len(cases) - 1
+			open[pair] = i
+		}
+		cases[i].Request.Texts = append(cases[i].Request.Texts, rec.Source)
+		cases[i].Expected = append(cases[i].Expected, rec.Translation)
+		cases[i].ID = caseID(cases[i].ID, rec.Source, rec.CapturedAt.Format(time.RFC3339Nano))
+	}
+	return cases
+}
+
+// sqsMessage is a dead-letter queue message as exported by
+// "aws sqs receive-message".
+type sqsMessage struct {
+	MessageID string `json:"MessageId"`
+	Body      string `json:"Body"`
+}
+
+// Requests reads cases from JSONL, one request per line, either bare or as
+// the Body of an exported SQS message. A case keeps the jobId of its
+// request, or else uses the message ID or a hash of the line, so replaying
+// a dead-lettered job completes that same job.
+func Requests(r io.Reader) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		c, err := requestCase(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
+
+// requestCase parses one line of Requests.
+func requestCase(line []byte) (Case, error) {
+	body, id := line, ""
+	var msg sqsMessage
+	if err := json.Unmarshal(line, &msg); err == nil && msg.Body != "" {
+		body, id = []byte(msg.Body), msg.MessageID
+	}
+	req, err := handler.ParseRequest(body)
+	if err != nil {
+		return Case{}, err
+	}
+	switch {
+	case req.JobID != "":
+		id = req.JobID
+	case id == "":
+		id = caseID(string(body))
+	}
+	req.Async, req.JobID = false, ""
+	return Case{ID: id, Request: req}, nil
+}
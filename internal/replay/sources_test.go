@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/pricofy/translation-manager/internal/capture"
+)
+
+func TestRequests(t *testing.T) {
+	input := strings.Join([]string{
+		`{"texts": ["hola"], "sourceLang": "es", "targetLang": "en", "jobId": "job-1", "async": true}`,
+		``,
+		`{"MessageId": "msg-2", "Body": "{\"texts\": [\"rojo\"], \"sourceLang\": \"es\", \"targetLang\": \"fr\"}"}`,
+		`{"texts": ["azul"], "sourceLang": "es", "targetLang": "it"}`,
+	}, "\n")
+
+	cases, err := Requests(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Requests() error = %v", err)
+	}
+	if len(cases) != 3 {
+		t.Fatalf("Requests() = %d cases, want 3", len(cases))
+	}
+	if cases[0].ID != "job-1" || cases[1].ID != "msg-2" || !strings.HasPrefix(cases[2].ID, "replay-") {
+		t.Errorf("IDs = %q, %q, %q", cases[0].ID, cases[1].ID, cases[2].ID)
+	}
+	if cases[0].Request.Async || cases[0].Request.JobID != "" {
+		t.Errorf("recorded async fields kept: %+v", cases[0].Request)
+	}
+	if cases[1].Request.TargetLang != "fr" || cases[1].Expected != nil {
+		t.Errorf("message case = %+v", cases[1])
+	}
+
+	if _, err := Requests(strings.NewReader(`{"texts": "hola"}`)); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("invalid request error = %v, want one naming line 1", err)
+	}
+}
+
+// captureBucket is an in-memory S3 bucket of capture files.
+type captureBucket map[string][]capture.Record
+
+func (b captureBucket) ListObjectsV2(_ context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	out := &s3.ListObjectsV2Output{}
+	for key := range b {
+		if strings.HasPrefix(key, *in.Prefix) {
+			key := key
+			out.Contents = append(out.Contents, types.Object{Key: &key})
+		}
+	}
+	return out, nil
+}
+
+func (b captureBucket) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range b[*in.Key] {
+		if err := enc.Encode(rec); err != nil {
+			return nil, err
+		}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(&buf)}, nil
+}
+
+func TestCapture_Cases(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC) }
+	record := func(source, translation, target string, capturedAt time.Time) capture.Record {
+		return capture.Record{Source: source, Translation: translation, SourceLang: "es", TargetLang: target, CapturedAt: capturedAt}
+	}
+	bucket := captureBucket{
+		"capture/es-en/dt=2026-10-14/1.jsonl": {record("viejo", "old", "en", at(14, 9))},
+		"capture/es-en/dt=2026-10-15/2.jsonl": {record("hola", "hello", "en", at(15, 9)), record("rojo", "red", "en", at(15, 10))},
+		"capture/es-en/dt=2026-10-16/3.jsonl": {record("azul", "blue", "en", at(16, 9)), record("tarde", "late", "en", at(16, 23))},
+		"capture/es-fr/dt=2026-10-15/4.jsonl": {record("verde", "vert", "fr", at(15, 11))},
+	}
+	c := &Capture{Objects: bucket, Bucket: "captures", Prefix: "capture/", BatchSize: 2}
+
+	cases, err := c.Cases(context.Background(), at(15, 0), at(16, 12))
+	if err != nil {
+		t.Fatalf("Cases() error = %v", err)
+	}
+	var got [][]string
+	for _, cs := range cases {
+		got = append(got, append([]string{cs.Request.TargetLang}, cs.Request.Texts...))
+		if len(cs.Expected) != len(cs.Request.Texts) || !strings.HasPrefix(cs.ID, "replay-") {
+			t.Errorf("case = %+v, want an expectation per text and an ID", cs)
+		}
+	}
+	want := [][]string{{"en", "hola", "rojo"}, {"fr", "verde"}, {"en", "azul"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cases() = %q, want %q", got, want)
+	}
+
+	again, err := c.Cases(context.Background(), at(15, 0), at(16, 12))
+	if err != nil || again[0].ID != cases[0].ID {
+		t.Errorf("case IDs are not stable across reads: %q, %q", cases[0].ID, again[0].ID)
+	}
+}
@@ -0,0 +1,118 @@
+// Package resultstore stores results of asynchronous work: translator
+// results of event-mode invocations and the responses of async jobs in S3,
+// or async job responses in DynamoDB (see Table).
+package resultstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// BucketEnv names the S3 bucket used for asynchronous results.
+const BucketEnv = "ASYNC_BUCKET"
+
+// Objects is the subset of the S3 client used by the store.
+type Objects interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Store reads and writes result objects under a key prefix of one bucket.
+type Store struct {
+	objects Objects
+	bucket  string
+	prefix  string
+}
+
+// New creates a Store writing to s3://bucket/prefix.
+func New(objects Objects, bucket, prefix string) *Store {
+	return &Store{objects: objects, bucket: bucket, prefix: prefix}
+}
+
+// Bucket returns the store's bucket.
+func (s *Store) Bucket() string {
+	return s.bucket
+}
+
+// Key returns the full object key for name.
+func (s *Store) Key(name string) string {
+	return s.prefix + name
+}
+
+// Location returns the URI of the result called name.
+func (s *Store) Location(name string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.Key(name))
+}
+
+// Put writes data under name.
+func (s *Store) Put(ctx context.Context, name string, data []byte) error {
+	key := s.Key(name)
+	contentType := "application/json"
+	_, err := s.objects.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: &contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+// Get reads name. It returns false, without error, when the object does not
+// exist yet.
+func (s *Store) Get(ctx context.Context, name string) ([]byte, bool, error) {
+	key := s.Key(name)
+	out, err := s.objects.GetObject(ctx, &s3.GetObjectInput{Bucket: &s.bucket, Key: &key})
+	if err != nil {
+		var missing *types.NoSuchKey
+		if errors.As(err, &missing) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read s3://%s/%s: %w", s.bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return data, true, nil
+}
+
+// Poll waits for name to be written, checking every interval until ctx is done.
+func (s *Store) Poll(ctx context.Context, name string, interval time.Duration) ([]byte, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, ok, err := s.Get(ctx, name)
+		if err != nil || ok {
+			return data, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for s3://%s/%s: %w", s.bucket, s.Key(name), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// NewID returns a random identifier for a result object or job.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,115 @@
+package resultstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeObjects is an in-memory bucket.
+type fakeObjects struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	gets    int
+	getErr  error
+}
+
+func (f *fakeObjects) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[*params.Bucket+"/"+*params.Key] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeObjects) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	data, ok := f.objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func TestStore_PutGet(t *testing.T) {
+	objects := &fakeObjects{objects: map[string][]byte{}}
+	store := New(objects, "bucket", "results/")
+	ctx := context.Background()
+
+	if _, ok, err := store.Get(ctx, "a.json"); ok || err != nil {
+		t.Fatalf("Get() missing object = %v, %v, want not found", ok, err)
+	}
+
+	if err := store.Put(ctx, "a.json", []byte(`{"x":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects.objects["bucket/results/a.json"]; !ok {
+		t.Errorf("object not written under prefix: %v", objects.objects)
+	}
+
+	data, ok, err := store.Get(ctx, "a.json")
+	if err != nil || !ok || string(data) != `{"x":1}` {
+		t.Errorf("Get() = %q, %v, %v", data, ok, err)
+	}
+}
+
+func TestStore_GetError(t *testing.T) {
+	store := New(&fakeObjects{getErr: errors.New("access denied")}, "bucket", "")
+	if _, _, err := store.Get(context.Background(), "a.json"); err == nil {
+		t.Error("Get() should return non-NoSuchKey errors")
+	}
+}
+
+func TestStore_Poll(t *testing.T) {
+	objects := &fakeObjects{objects: map[string][]byte{}}
+	store := New(objects, "bucket", "")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = store.Put(context.Background(), "late.json", []byte("done"))
+	}()
+
+	data, err := store.Poll(context.Background(), "late.json", 5*time.Millisecond)
+	if err != nil || string(data) != "done" {
+		t.Fatalf("Poll() = %q, %v", data, err)
+	}
+	if objects.gets < 2 {
+		t.Errorf("Poll() made %d reads, expected it to retry", objects.gets)
+	}
+}
+
+func TestStore_PollTimeout(t *testing.T) {
+	store := New(&fakeObjects{objects: map[string][]byte{}}, "bucket", "")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := store.Poll(ctx, "never.json", 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Poll() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	a, err := NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewID()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewID() = %q, %q, want distinct 32-char ids", a, b)
+	}
+}
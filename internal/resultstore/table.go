@@ -0,0 +1,90 @@
+package resultstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table that async job results are written to
+// instead of ASYNC_BUCKET.
+const TableEnv = "JOBS_RESULT_TABLE"
+
+// TableRetention is how long results are kept in a table, through the
+// "expiresAt" TTL attribute.
+const TableRetention = 14 * 24 * time.Hour
+
+// maxTableResult bounds the size of a result stored in a table, below the
+// 400 KB DynamoDB item limit.
+const maxTableResult = 390 * 1024
+
+// Items is the subset of the DynamoDB client used by a Table.
+type Items interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// Table reads and writes results as items of a DynamoDB table keyed by the
+// string attribute "id", holding the result in "result". It suits many
+// small results, such as nightly listing jobs, that are read back by key.
+type Table struct {
+	items Items
+	name  string
+	now   func() time.Time
+}
+
+// NewTable creates a Table for the named table.
+func NewTable(items Items, name string) *Table {
+	return &Table{items: items, name: name, now: time.Now}
+}
+
+// Location returns the URI of the result called name.
+func (t *Table) Location(name string) string {
+	return fmt.Sprintf("dynamodb://%s/%s", t.name, name)
+}
+
+// Put writes data under name.
+func (t *Table) Put(ctx context.Context, name string, data []byte) error {
+	if len(data) > maxTableResult {
+		return fmt.Errorf("result %s is %d bytes, too large for a DynamoDB item; use %s", name, len(data), BucketEnv)
+	}
+	expires := t.now().Add(TableRetention).Unix()
+	_, err := t.items.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &t.name,
+		Item: map[string]types.AttributeValue{
+			"id":        &types.AttributeValueMemberS{Value: name},
+			"result":    &types.AttributeValueMemberS{Value: string(data)},
+			"expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(expires, 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", t.Location(name), err)
+	}
+	return nil
+}
+
+// Get reads name. It returns false, without error, when the item does not
+// exist yet.
+func (t *Table) Get(ctx context.Context, name string) ([]byte, bool, error) {
+	consistent := true
+	out, err := t.items.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &t.name,
+		Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: name}},
+		ConsistentRead: &consistent,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", t.Location(name), err)
+	}
+	if out.Item == nil {
+		return nil, false, nil
+	}
+	result, ok := out.Item["result"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, false, fmt.Errorf("failed to read %s: no result attribute", t.Location(name))
+	}
+	return []byte(result.Value), true, nil
+}
@@ -0,0 +1,72 @@
+package resultstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeItems is an in-memory table keyed by "id".
+type fakeItems struct {
+	items  map[string]map[string]types.AttributeValue
+	getErr error
+}
+
+func (f *fakeItems) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	id := params.Key["id"].(*types.AttributeValueMemberS).Value
+	return &dynamodb.GetItemOutput{Item: f.items[id]}, nil
+}
+
+func (f *fakeItems) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.items[params.Item["id"].(*types.AttributeValueMemberS).Value] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestTable_PutGet(t *testing.T) {
+	items := &fakeItems{items: map[string]map[string]types.AttributeValue{}}
+	table := NewTable(items, "results")
+	now := time.Unix(1700000000, 0)
+	table.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, ok, err := table.Get(ctx, "job.json"); ok || err != nil {
+		t.Fatalf("Get() of a missing result = %v, %v; want not found", ok, err)
+	}
+	if err := table.Put(ctx, "job.json", []byte(`{"translations":["Hello"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, ok, err := table.Get(ctx, "job.json")
+	if err != nil || !ok || string(data) != `{"translations":["Hello"]}` {
+		t.Errorf("Get() = %q, %v, %v", data, ok, err)
+	}
+
+	expires := items.items["job.json"]["expiresAt"].(*types.AttributeValueMemberN).Value
+	if want := "1701209600"; expires != want {
+		t.Errorf("expiresAt = %s, want %s", expires, want)
+	}
+	if got, want := table.Location("job.json"), "dynamodb://results/job.json"; got != want {
+		t.Errorf("Location() = %q, want %q", got, want)
+	}
+}
+
+func TestTable_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	table := NewTable(&fakeItems{items: map[string]map[string]types.AttributeValue{}}, "results")
+	if err := table.Put(ctx, "big.json", []byte(strings.Repeat("x", maxTableResult+1))); err == nil {
+		t.Error("Put() of an oversized result should fail")
+	}
+
+	table = NewTable(&fakeItems{getErr: errors.New("access denied")}, "results")
+	if _, _, err := table.Get(ctx, "job.json"); err == nil {
+		t.Error("Get() should report the table error")
+	}
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/pricofy/translation-manager/internal/resultstore"
+)
+
+// Translator invocation modes, selected with TRANSLATOR_INVOCATION.
+const (
+	InvocationEnv = "TRANSLATOR_INVOCATION"
+	// InvocationSync waits on a RequestResponse invocation (the default).
+	InvocationSync = "sync"
+	// InvocationEvent invokes translators asynchronously and polls S3 for
+	// their result, so a translator may run longer than a synchronous
+	// invocation allows.
+	InvocationEvent = "event"
+)
+
+// PollIntervalEnv sets how often event-mode results are polled (Go duration).
+const PollIntervalEnv = "TRANSLATOR_POLL_INTERVAL"
+
+const (
+	defaultPollInterval = time.Second
+	resultsPrefix       = "results/"
+)
+
+// pollInterval reads PollIntervalEnv.
+func pollInterval() (time.Duration, error) {
+	v := os.Getenv(PollIntervalEnv)
+	if v == "" {
+		return defaultPollInterval, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a positive duration such as 500ms", PollIntervalEnv, v)
+	}
+	return d, nil
+}
+
+// invokeEvent invokes a translator asynchronously and waits for it to write
+// its response to the results store.
+func (r *Router) invokeEvent(ctx context.Context, functionName string, req TranslatorRequest) (*TranslatorResponse, error) {
+	id, err := resultstore.NewID()
+	if err != nil {
+		return nil, err
+	}
+	name := id + ".json"
+	req.ResultBucket = r.results.Bucket()
+	req.ResultKey = r.results.Key(name)
+
+	payload, err := r.encodeRequest(functionName, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := checkPayload(functionName, payload, maxEventPayload); err != nil {
+		return nil, err
+	}
+
+	_, err = r.invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   &functionName,
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	})
+	if err != nil {
+		if tooLarge := payloadExceeded(functionName, err, nil); tooLarge != nil {
+			return nil, tooLarge
+		}
+		return nil, fmt.Errorf("failed to invoke %s: %w", functionName, err)
+	}
+
+	result, err := r.results.Poll(ctx, name, r.pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parseTranslatorResponse(functionName, result)
+	if err != nil {
+		return nil, err
+	}
+	recordFormats(functionName, resp.Formats)
+	return resp, nil
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/pricofy/translation-manager/internal/resultstore"
+)
+
+// memoryBucket is an in-memory S3 bucket.
+type memoryBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (b *memoryBucket) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, _ := io.ReadAll(params.Body)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[*params.Key] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (b *memoryBucket) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[*params.Key]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// eventTranslator upper-cases texts and writes the response to the result
+// key in the background, like an asynchronously invoked translator.
+type eventTranslator struct {
+	bucket *memoryBucket
+	calls  []string
+}
+
+func (e *eventTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	if params.InvocationType != lambdatypes.InvocationTypeEvent {
+		return nil, io.ErrUnexpectedEOF
+	}
+	e.calls = append(e.calls, *params.FunctionName)
+
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	resp := TranslatorResponse{Translations: make([][]string, len(req.Chunks))}
+	for i, chunk := range req.Chunks {
+		for _, text := range chunk {
+			resp.Translations[i] = append(resp.Translations[i], strings.ToUpper(text))
+		}
+	}
+	payload, _ := json.Marshal(resp)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = e.bucket.PutObject(context.Background(), &s3.PutObjectInput{
+			Bucket: &req.ResultBucket,
+			Key:    &req.ResultKey,
+			Body:   bytes.NewReader(payload),
+		})
+	}()
+	return &lambda.InvokeOutput{StatusCode: 202}, nil
+}
+
+func TestTranslateChunks_EventInvocation(t *testing.T) {
+	bucket := &memoryBucket{objects: map[string][]byte{}}
+	translator := &eventTranslator{bucket: bucket}
+	r := &Router{
+		lambdaClient: translator,
+		results:      resultstore.New(bucket, "async-bucket", resultsPrefix),
+		pollInterval: 5 * time.Millisecond,
+	}
+
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", [][]string{{"hola", "adiós"}}, Options{})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+
+	if got := result.Translations[0]; len(got) != 2 || got[0] != "HOLA" {
+		t.Errorf("Translations = %q", result.Translations)
+	}
+	if len(translator.calls) != 2 {
+		t.Errorf("expected both pivot steps to be invoked as events, got %v", translator.calls)
+	}
+	for key := range bucket.objects {
+		if !strings.HasPrefix(key, resultsPrefix) {
+			t.Errorf("result key %q not under %q", key, resultsPrefix)
+		}
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", defaultPollInterval, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"soon", 0, true},
+		{"-1s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(PollIntervalEnv, tt.env)
+		got, err := pollInterval()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("pollInterval() with %q = %v, %v", tt.env, got, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/translate"
+
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// awsTranslateConcurrency bounds the TranslateText calls in flight.
+const awsTranslateConcurrency = 8
+
+// awsTranslateVariants are the regional variants Amazon Translate knows;
+// other variants are sent as their base language.
+var awsTranslateVariants = map[string]bool{"es_MX": true, "fr_CA": true, "pt_PT": true}
+
+// TextTranslator is the subset of the Amazon Translate client used by the
+// backend.
+type TextTranslator interface {
+	TranslateText(ctx context.Context, params *translate.TranslateTextInput, optFns ...func(*translate.Options)) (*translate.TranslateTextOutput, error)
+}
+
+// AWSTranslate translates with Amazon Translate, one TranslateText call per
+// text.
+type AWSTranslate struct {
+	client TextTranslator
+	retry  retryPolicy
+}
+
+// NewAWSTranslate creates an Amazon Translate backend with the region and
+// credentials of cfg.
+func NewAWSTranslate(cfg aws.Config, retry retryPolicy) *AWSTranslate {
+	return &AWSTranslate{
+		// Calls are retried by the router's own policy, as invocations are
+		client: translate.NewFromConfig(cfg, func(o *translate.Options) {
+			o.Retryer = aws.NopRetryer{}
+		}),
+		retry: retry,
+	}
+}
+
+// awsLanguageCode returns the Amazon Translate code of a language.
+func awsLanguageCode(lang string) string {
+	if awsTranslateVariants[lang] {
+		return strings.ReplaceAll(lang, "_", "-")
+	}
+	base, _, _ := strings.Cut(lang, "_")
+	return base
+}
+
+// TranslateChunks translates every non-empty text; empty texts stay empty.
+func (t *AWSTranslate) TranslateChunks(ctx context.Context, source, target string, chunks [][]string) ([][]string, error) {
+	type position struct{ chunk, text int }
+	out := make([][]string, len(chunks))
+	var texts []position
+	for i, chunk := range chunks {
+		out[i] = make([]string, len(chunk))
+		for j, text := range chunk {
+			if strings.TrimSpace(text) != "" {
+				texts = append(texts, position{i, j})
+			}
+		}
+	}
+	pool := workpool.Pool{Limit: awsTranslateConcurrency, FailFast: true}
+	err := pool.Run(ctx, len(texts), func(ctx context.Context, k int) error {
+		p := texts[k]
+		translation, err := t.translateText(ctx, source, target, chunks[p.chunk][p.text])
+		out[p.chunk][p.text] = translation
+		return err
+	})
+	if first := workpool.First(err); first != nil {
+		return nil, first.Err
+	}
+	return out, nil
+}
+
+// translateText calls TranslateText for one text under the retry policy.
+func (t *AWSTranslate) translateText(ctx context.Context, source, target, text string) (string, error) {
+	var out *translate.TranslateTextOutput
+	err := t.retry.do(ctx, func() error {
+		var err error
+		out, err = t.client.TranslateText(ctx, &translate.TranslateTextInput{
+			Text:               aws.String(text),
+			SourceLanguageCode: aws.String(awsLanguageCode(source)),
+			TargetLanguageCode: aws.String(awsLanguageCode(target)),
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("TranslateText: %w", err)
+	}
+	return aws.ToString(out.TranslatedText), nil
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestAWSLanguageCode(t *testing.T) {
+	tests := map[string]string{"es": "es", "es_MX": "es-MX", "es_AR": "es", "pt_BR": "pt", "pt_PT": "pt-PT", "fr_CA": "fr-CA"}
+	for lang, want := range tests {
+		if got := awsLanguageCode(lang); got != want {
+			t.Errorf("awsLanguageCode(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestAWSTranslate_TranslateChunks(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if got := r.Header.Get("X-Amz-Target"); got != "AWSShineFrontendService_20170701.TranslateText" {
+			t.Errorf("X-Amz-Target = %q", got)
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			t.Errorf("request is not signed: %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		if body["SourceLanguageCode"] != "es" || body["TargetLanguageCode"] != "ja" {
+			t.Errorf("languages = %s → %s", body["SourceLanguageCode"], body["TargetLanguageCode"])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"TranslatedText": "ja:" + body["Text"]})
+	}))
+	defer server.Close()
+
+	tr := NewAWSTranslate(aws.Config{
+		Region:       "eu-west-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}, retryPolicy{})
+
+	got, err := tr.TranslateChunks(context.Background(), "es", "ja", [][]string{{"hola", ""}, {"adiós"}})
+	if err != nil {
+		t.Fatalf("TranslateChunks() error = %v", err)
+	}
+	want := [][]string{{"ja:hola", ""}, {"ja:adiós"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TranslateChunks() = %q, want %q", got, want)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("TranslateText calls = %d, want 2 (empty texts are skipped)", n)
+	}
+}
+
+func TestAWSTranslate_Retry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, `{"__type":"ThrottlingException"}`, http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"TranslatedText":"hello"}`))
+	}))
+	defer server.Close()
+
+	tr := NewAWSTranslate(aws.Config{
+		Region:       "eu-west-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}, retryPolicy{attempts: 2, baseDelay: time.Millisecond, maxDelay: time.Millisecond})
+
+	got, err := tr.TranslateChunks(context.Background(), "es", "en", [][]string{{"hola"}})
+	if err != nil {
+		t.Fatalf("TranslateChunks() error = %v", err)
+	}
+	if got[0][0] != "hello" || requests.Load() != 2 {
+		t.Errorf("TranslateChunks() = %q after %d calls, want hello after 2", got, requests.Load())
+	}
+}
@@ -0,0 +1,244 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// Translator translates chunks of texts from source to target, returning
+// translations with the same shape as chunks. The Lambda fleet (Router),
+// Amazon Translate, DeepL, Bedrock and DryRun implement it.
+type Translator interface {
+	TranslateChunks(ctx context.Context, source, target string, chunks [][]string) ([][]string, error)
+}
+
+var _ Translator = (*Router)(nil)
+
+// formalityTranslator is a Translator that can also be asked for a
+// formality (Options.Formality); DeepL and Bedrock implement it.
+type formalityTranslator interface {
+	TranslateChunksWithFormality(ctx context.Context, source, target, formality string, chunks [][]string) ([][]string, error)
+}
+
+// Translation backends.
+const (
+	// BackendLambda is the fleet of opus-mt translator Lambdas.
+	BackendLambda = "lambda"
+	// BackendAWSTranslate is Amazon Translate.
+	BackendAWSTranslate = "aws-translate"
+	// BackendDeepL is the DeepL API, available when DeepLKeySecretEnv is set.
+	BackendDeepL = "deepl"
+	// BackendBedrock is a Bedrock foundation model, available when
+	// BedrockEnv is set.
+	BackendBedrock = "bedrock"
+	// BackendDryRun returns pseudo-translations in process (see DryRun).
+	BackendDryRun = "dry-run"
+)
+
+// IsBackend reports whether name is a translation backend.
+func IsBackend(name string) bool {
+	switch name {
+	case BackendLambda, BackendAWSTranslate, BackendDeepL, BackendBedrock, BackendDryRun:
+		return true
+	}
+	return false
+}
+
+// IsExternal reports whether backend sends texts outside our AWS account,
+// to a third-party API.
+func IsExternal(backend string) bool {
+	return backend == BackendDeepL
+}
+
+// BackendsEnv names the environment variable choosing backends per pair as
+// JSON (or BackendsEnv+"_FILE" pointing to a JSON file).
+const BackendsEnv = "TRANSLATION_BACKENDS"
+
+// backendConfig is the TRANSLATION_BACKENDS config.
+type backendConfig struct {
+	// Pairs maps "source-target" pairs to the backend translating them.
+	Pairs map[string]string `json:"pairs,omitempty"`
+	// Fallback translates the pairs the Lambda fleet has no route for.
+	Fallback string `json:"fallback,omitempty"`
+	// Premium translates the requests for premium quality
+	// (Options.Premium). Defaults to DeepL when it is configured.
+	Premium string `json:"premium,omitempty"`
+}
+
+// loadBackends reads the TRANSLATION_BACKENDS config and creates the
+// backends other than the Lambda fleet.
+func loadBackends(ctx context.Context, cfg aws.Config, retry retryPolicy) (backendConfig, map[string]Translator, error) {
+	var config backendConfig
+	if _, err := appconfig.LoadJSON(BackendsEnv, &config); err != nil {
+		return backendConfig{}, nil, err
+	}
+
+	backends := map[string]Translator{
+		BackendAWSTranslate: NewAWSTranslate(cfg, retry),
+		BackendDryRun:       DryRun{},
+	}
+	key, err := deepLKey(ctx, cfg)
+	if err != nil {
+		return backendConfig{}, nil, err
+	}
+	if key != "" {
+		backends[BackendDeepL] = NewDeepL(key, retry)
+	}
+	bedrock, err := loadBedrock(cfg, retry)
+	if err != nil {
+		return backendConfig{}, nil, err
+	}
+	if bedrock != nil {
+		backends[BackendBedrock] = bedrock
+	}
+
+	usable := func(name string) bool {
+		_, ok := backends[name]
+		return ok || name == BackendLambda
+	}
+	for pair, name := range config.Pairs {
+		if source, target, ok := strings.Cut(pair, "-"); !ok || source == "" || target == "" {
+			return backendConfig{}, nil, fmt.Errorf("invalid %s: pair %q is not source-target", BackendsEnv, pair)
+		}
+		if !usable(name) {
+			return backendConfig{}, nil, fmt.Errorf("invalid %s: backend %q of %s is unknown or not configured", BackendsEnv, name, pair)
+		}
+	}
+	if config.Fallback != "" && !usable(config.Fallback) {
+		return backendConfig{}, nil, fmt.Errorf("invalid %s: fallback %q is unknown or not configured", BackendsEnv, config.Fallback)
+	}
+	if config.Premium != "" && (!usable(config.Premium) || config.Premium == BackendLambda) {
+		return backendConfig{}, nil, fmt.Errorf("invalid %s: premium %q is unknown or not configured", BackendsEnv, config.Premium)
+	}
+	return config, backends, nil
+}
+
+// backend returns the backend translating source → target: the requested
+// one, else the dry run in the local environment, else the one configured
+// for the pair, else the Lambda fleet, or the fallback for pairs the fleet
+// has no route for.
+func (r *Router) backend(source, target, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if r.dryRun {
+		return BackendDryRun
+	}
+	if name, ok := r.backendConfig.Pairs[source+"-"+target]; ok {
+		return name
+	}
+	if r.backendConfig.Fallback != "" && r.getRoute(source, target) == nil {
+		return r.backendConfig.Fallback
+	}
+	return BackendLambda
+}
+
+// premiumBackend returns the backend translating premium requests, or ""
+// when there is none. The local environment has none.
+func (r *Router) premiumBackend() string {
+	if r.dryRun {
+		return ""
+	}
+	if r.backendConfig.Premium != "" {
+		return r.backendConfig.Premium
+	}
+	if _, ok := r.backends[BackendDeepL]; ok {
+		return BackendDeepL
+	}
+	return ""
+}
+
+// CanTranslate reports whether source → target can be translated with
+// backend ("" for the configured choice).
+func (r *Router) CanTranslate(source, target, backend string) bool {
+	name := r.backend(source, target, backend)
+	if name == BackendLambda {
+		return r.IsValidPair(source, target)
+	}
+	_, ok := r.backends[name]
+	return ok && source != "" && target != "" && source != target
+}
+
+// translateWith translates chunks with a backend other than the Lambda
+// fleet, as a single-step route named after the backend, under the cost
+// limit and formality of opts. entry is the routing table entry that chose
+// the backend, if any.
+func (r *Router) translateWith(ctx context.Context, name, source, target string, chunks [][]string, opts Options, entry *routing.Entry) (*Result, error) {
+	b, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("translation backend %q is not configured", name)
+	}
+	cost := r.stepCost(name, chunks)
+	if opts.MaxCost > 0 && cost > opts.MaxCost {
+		return nil, &CostError{Estimate: cost, MaxCost: opts.MaxCost}
+	}
+
+	start := time.Now()
+	stepCtx, budget, cancel := stepBudget(ctx, 1)
+	defer cancel()
+	var translations [][]string
+	var err error
+	if f, ok := b.(formalityTranslator); ok && opts.Formality != "" {
+		translations, err = f.TranslateChunksWithFormality(stepCtx, source, target, opts.Formality, chunks)
+	} else {
+		translations, err = b.TranslateChunks(stepCtx, source, target, chunks)
+	}
+	err = stepTimeout(stepCtx, err, 1, 1, name, budget)
+	if err == nil {
+		err = checkShape(name, chunks, &TranslatorResponse{Translations: translations})
+	}
+	if err != nil {
+		err = &StepError{Step: 1, Steps: 1, Function: name, Err: err}
+		if entry != nil {
+			return nil, &EntryError{Entry: entry, Err: err}
+		}
+		return nil, err
+	}
+	opts.progress(len(chunks))
+	return &Result{
+		Entry:         entry,
+		Translations:  translations,
+		Steps:         []string{name},
+		ModelVersions: []string{""},
+		Dispatches:    []string{DispatchSingle},
+		Durations:     []time.Duration{time.Since(start)},
+		Cost:          cost,
+	}, nil
+}
+
+// HTTPDoer is the subset of *http.Client used by HTTP backends.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// maxErrorBody bounds the part of an error response kept in errors.
+const maxErrorBody = 512
+
+// httpError is an HTTP backend failure. Its status makes throttling and
+// server errors retryable.
+type httpError struct {
+	code int
+	body string
+}
+
+func (e *httpError) Error() string       { return fmt.Sprintf("HTTP %d: %s", e.code, e.body) }
+func (e *httpError) HTTPStatusCode() int { return e.code }
+
+// readResponse returns the body of a successful response, or an *httpError.
+func readResponse(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &httpError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	return io.ReadAll(resp.Body)
+}
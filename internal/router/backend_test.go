@@ -0,0 +1,190 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// upperTranslator is a backend answering every text in brackets.
+type upperTranslator struct {
+	calls int
+	err   error
+}
+
+func (t *upperTranslator) TranslateChunks(_ context.Context, _, target string, chunks [][]string) ([][]string, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+	out := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		for _, text := range chunk {
+			out[i] = append(out[i], "["+target+"] "+text)
+		}
+	}
+	return out, nil
+}
+
+func TestRouter_Backend(t *testing.T) {
+	r := &Router{backendConfig: backendConfig{
+		Pairs:    map[string]string{"es-en": BackendDeepL},
+		Fallback: BackendAWSTranslate,
+	}}
+	tests := []struct {
+		name      string
+		source    string
+		target    string
+		requested string
+		want      string
+	}{
+		{name: "configured pair", source: "es", target: "en", want: BackendDeepL},
+		{name: "request wins", source: "es", target: "en", requested: BackendLambda, want: BackendLambda},
+		{name: "fleet route", source: "es", target: "fr", want: BackendLambda},
+		{name: "fallback without route", source: "es", target: "ja", want: BackendAWSTranslate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.backend(tt.source, tt.target, tt.requested); got != tt.want {
+				t.Errorf("backend(%s, %s, %q) = %q, want %q", tt.source, tt.target, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouter_CanTranslate(t *testing.T) {
+	r := &Router{
+		backendConfig: backendConfig{Fallback: BackendAWSTranslate},
+		backends:      map[string]Translator{BackendAWSTranslate: &upperTranslator{}},
+	}
+	tests := []struct {
+		source, target, backend string
+		want                    bool
+	}{
+		{source: "es", target: "fr", want: true},
+		{source: "es", target: "ja", want: true},
+		{source: "ja", target: "ja", want: false},
+		{source: "es", target: "ja", backend: BackendLambda, want: false},
+		{source: "es", target: "en", backend: BackendDeepL, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := r.CanTranslate(tt.source, tt.target, tt.backend); got != tt.want {
+			t.Errorf("CanTranslate(%s, %s, %q) = %v, want %v", tt.source, tt.target, tt.backend, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateChunks_Backend(t *testing.T) {
+	backend := &upperTranslator{}
+	lambdas := &recordingTranslator{}
+	r := &Router{
+		lambdaClient:  lambdas,
+		backendConfig: backendConfig{Fallback: BackendAWSTranslate},
+		backends:      map[string]Translator{BackendAWSTranslate: backend},
+		costs:         map[string]Cost{BackendAWSTranslate: {PerChar: 0.01}},
+	}
+
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "ja", [][]string{{"hola", "adiós"}}, Options{})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	want := [][]string{{"[ja] hola", "[ja] adiós"}}
+	if !reflect.DeepEqual(result.Translations, want) {
+		t.Errorf("translations = %q, want %q", result.Translations, want)
+	}
+	if !reflect.DeepEqual(result.Steps, []string{BackendAWSTranslate}) || result.Cost != 0.09 {
+		t.Errorf("steps = %v, cost = %g, want [%s] and 0.09", result.Steps, result.Cost, BackendAWSTranslate)
+	}
+	if len(lambdas.calls) != 0 {
+		t.Errorf("translator Lambdas invoked %d times, want 0", len(lambdas.calls))
+	}
+
+	var costErr *CostError
+	if _, err := r.TranslateChunksWithOptions(context.Background(), "es", "ja", [][]string{{"hola"}}, Options{MaxCost: 0.01}); !errors.As(err, &costErr) {
+		t.Errorf("over budget error = %v, want a *CostError", err)
+	}
+
+	if _, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", [][]string{{"hola"}}, Options{Backend: BackendDeepL}); err == nil {
+		t.Error("unconfigured backend error = nil")
+	}
+
+	backend.err = errors.New("throttled")
+	if _, err := r.TranslateChunksWithOptions(context.Background(), "es", "ja", [][]string{{"hola"}}, Options{}); err == nil {
+		t.Error("backend failure error = nil")
+	}
+}
+
+func TestRouter_PlanBackend(t *testing.T) {
+	r := &Router{
+		backendConfig: backendConfig{Fallback: BackendAWSTranslate},
+		backends:      map[string]Translator{BackendAWSTranslate: &upperTranslator{}},
+	}
+	plan, err := r.Plan("es", "ja", "")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if !reflect.DeepEqual(plan.Steps, []string{BackendAWSTranslate}) || plan.PivotLang != "" {
+		t.Errorf("Plan() = %+v, want a single %s step", plan, BackendAWSTranslate)
+	}
+	if _, err := r.Plan("es", "ja", BackendDeepL); err == nil {
+		t.Error("Plan() with an unconfigured backend error = nil")
+	}
+
+	r.backends[BackendDeepL] = &upperTranslator{}
+	if plan, err := r.Plan("es", "fr", ""); err != nil || plan.Premium != BackendDeepL {
+		t.Errorf("Plan() = %+v, %v, want DeepL as the premium backend", plan, err)
+	}
+
+	r.backends[BackendBedrock] = &upperTranslator{}
+	r.chunkBudgets = map[string]int{BackendBedrock: 2048}
+	if plan, err := r.Plan("es", "ja", BackendBedrock); err != nil || plan.MaxTokens != 2048 {
+		t.Errorf("Plan() with bedrock = %+v, %v, want a 2048-token chunk budget", plan, err)
+	}
+}
+
+func TestLoadBackends(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		secret    string
+		wantDeepL bool
+		wantErr   bool
+	}{
+		{name: "unset"},
+		{name: "fallback", config: `{"fallback": "aws-translate"}`},
+		{name: "deepl pair", config: `{"pairs": {"de-en": "deepl"}}`, secret: "deepl-api-key", wantDeepL: true},
+		{name: "deepl without key", config: `{"pairs": {"de-en": "deepl"}}`, wantErr: true},
+		{name: "unknown backend", config: `{"fallback": "google"}`, wantErr: true},
+		{name: "bad pair", config: `{"pairs": {"deen": "lambda"}}`, wantErr: true},
+		{name: "premium", config: `{"premium": "aws-translate"}`},
+		{name: "lambda premium", config: `{"premium": "lambda"}`, wantErr: true},
+		{name: "deepl premium without key", config: `{"premium": "deepl"}`, wantErr: true},
+		{name: "bedrock fallback without model", config: `{"fallback": "bedrock"}`, wantErr: true},
+	}
+
+	read := readSecret
+	defer func() { readSecret = read }()
+	readSecret = func(context.Context, aws.Config, string) (string, error) { return "secret-key", nil }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(BackendsEnv, tt.config)
+			t.Setenv(DeepLKeySecretEnv, tt.secret)
+			_, backends, err := loadBackends(context.Background(), aws.Config{Region: "eu-west-1"}, retryPolicy{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadBackends() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, ok := backends[BackendDeepL]; ok != tt.wantDeepL {
+				t.Errorf("deepl configured = %v, want %v", ok, tt.wantDeepL)
+			}
+		})
+	}
+}
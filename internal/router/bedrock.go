@@ -0,0 +1,255 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// BedrockEnv names the environment variable configuring the Bedrock backend
+// as JSON (or BedrockEnv+"_FILE" pointing to a JSON file).
+const BedrockEnv = "BEDROCK_TRANSLATOR"
+
+// bedrockConcurrency bounds the Converse calls in flight.
+const bedrockConcurrency = 4
+
+// bedrockPrompt is the default system prompt template.
+const bedrockPrompt = "You translate second-hand marketplace listings (titles, descriptions and attributes) " +
+	"from the language tagged {source} to the language tagged {target}. Keep the tone of the original " +
+	"and use the words buyers search for in the target market. Keep brand names, model numbers, sizes " +
+	"and placeholders such as __0__ unchanged. The user message is a JSON array of texts: reply with " +
+	"only a JSON array of their translations, one string per text, in the same order.{formality}"
+
+// bedrockFormalities are the instructions appended for formalities.
+var bedrockFormalities = map[string]string{
+	FormalityFormal:   " Address the reader formally.",
+	FormalityInformal: " Address the reader informally.",
+}
+
+// bedrockModelTokens are the output token limits of model families, by
+// model ID prefix (after any inference profile region); the first match
+// wins. Other models get defaultBedrockTokens.
+var bedrockModelTokens = []struct {
+	prefix string
+	tokens int
+}{
+	{"anthropic.claude-3-5", 8192},
+	{"anthropic.claude", 4096},
+	{"amazon.titan-text-express", 8192},
+	{"amazon.titan-text-lite", 4096},
+	{"amazon.titan-text-premier", 3072},
+}
+
+// defaultBedrockTokens is the output token limit of unknown models.
+const defaultBedrockTokens = 2048
+
+// bedrockConfig is the BEDROCK_TRANSLATOR config.
+type bedrockConfig struct {
+	// ModelID is the model or inference profile translating, e.g.
+	// "anthropic.claude-3-haiku-20240307-v1:0".
+	ModelID string `json:"modelId"`
+	// MaxTokens bounds the output tokens of a call. Default: the limit of
+	// the model (bedrockModelTokens).
+	MaxTokens int `json:"maxTokens,omitempty"`
+	// Prompt is the system prompt template; {source}, {target} and
+	// {formality} are replaced by the language tags and the formality
+	// instruction. Default: bedrockPrompt.
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Conversation is the subset of the Bedrock runtime client used by the
+// backend.
+type Conversation interface {
+	Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error)
+}
+
+// Bedrock translates with a Bedrock foundation model through the Converse
+// API, one call per chunk, the texts in a JSON array.
+type Bedrock struct {
+	client Conversation
+	retry  retryPolicy
+	config bedrockConfig
+}
+
+// loadBedrock reads the BEDROCK_TRANSLATOR config and creates the Bedrock
+// backend in the region of cfg, or returns nil when it is unset.
+func loadBedrock(cfg aws.Config, retry retryPolicy) (*Bedrock, error) {
+	var config bedrockConfig
+	found, err := appconfig.LoadJSON(BedrockEnv, &config)
+	if err != nil || !found {
+		return nil, err
+	}
+	if config.ModelID == "" {
+		return nil, fmt.Errorf("invalid %s: modelId is required", BedrockEnv)
+	}
+	if config.MaxTokens < 0 {
+		return nil, fmt.Errorf("invalid %s: maxTokens must not be negative", BedrockEnv)
+	}
+	if config.MaxTokens == 0 {
+		config.MaxTokens = bedrockTokens(config.ModelID)
+	}
+	if config.Prompt == "" {
+		config.Prompt = bedrockPrompt
+	}
+	return &Bedrock{
+		// Calls are retried by the router's own policy, as invocations are
+		client: bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+			o.Retryer = aws.NopRetryer{}
+		}),
+		retry:  retry,
+		config: config,
+	}, nil
+}
+
+// bedrockTokens returns the output token limit of a model.
+func bedrockTokens(modelID string) int {
+	// Inference profiles prefix the model with a region, e.g. "eu."
+	if _, model, ok := strings.Cut(modelID, "."); ok && strings.Contains(model, ".") {
+		modelID = model
+	}
+	for _, m := range bedrockModelTokens {
+		if strings.HasPrefix(modelID, m.prefix) {
+			return m.tokens
+		}
+	}
+	return defaultBedrockTokens
+}
+
+// ChunkTokens returns the token budget of a chunk: half the output tokens,
+// as translations run longer than their source in many languages and are
+// quoted in JSON.
+func (b *Bedrock) ChunkTokens() int {
+	return b.config.MaxTokens / 2
+}
+
+// TranslateChunks translates chunk by chunk.
+func (b *Bedrock) TranslateChunks(ctx context.Context, source, target string, chunks [][]string) ([][]string, error) {
+	return b.TranslateChunksWithFormality(ctx, source, target, "", chunks)
+}
+
+// TranslateChunksWithFormality translates chunk by chunk in formality
+// ("" for the default). Blank texts stay as they are.
+func (b *Bedrock) TranslateChunksWithFormality(ctx context.Context, source, target, formality string, chunks [][]string) ([][]string, error) {
+	system := strings.NewReplacer(
+		"{source}", bedrockLang(source),
+		"{target}", bedrockLang(target),
+		"{formality}", bedrockFormalities[formality],
+	).Replace(b.config.Prompt)
+
+	out := make([][]string, len(chunks))
+	pool := workpool.Pool{Limit: bedrockConcurrency, FailFast: true}
+	err := pool.Run(ctx, len(chunks), func(ctx context.Context, i int) error {
+		translations, err := b.translateChunk(ctx, system, chunks[i])
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+		out[i] = translations
+		return nil
+	})
+	if first := workpool.First(err); first != nil {
+		return nil, first.Err
+	}
+	return out, nil
+}
+
+// bedrockLang returns the BCP 47 tag of a language code, e.g. pt-BR.
+func bedrockLang(lang string) string {
+	return strings.ReplaceAll(lang, "_", "-")
+}
+
+// translateChunk translates the non-blank texts of a chunk in one call.
+func (b *Bedrock) translateChunk(ctx context.Context, system string, chunk []string) ([]string, error) {
+	out := make([]string, len(chunk))
+	var texts []string
+	var positions []int
+	for i, text := range chunk {
+		if strings.TrimSpace(text) == "" {
+			out[i] = text
+			continue
+		}
+		texts = append(texts, text)
+		positions = append(positions, i)
+	}
+	if len(texts) == 0 {
+		return out, nil
+	}
+
+	translations, err := b.converse(ctx, system, texts)
+	if err != nil {
+		return nil, err
+	}
+	for k, i := range positions {
+		out[i] = translations[k]
+	}
+	return out, nil
+}
+
+// converse sends texts in one Converse call under the retry policy and
+// parses the JSON array of their translations. Translations use
+// temperature 0 to be repeatable.
+func (b *Bedrock) converse(ctx context.Context, system string, texts []string) ([]string, error) {
+	input, err := json.Marshal(texts)
+	if err != nil {
+		return nil, err
+	}
+	params := &bedrockruntime.ConverseInput{
+		ModelId: aws.String(b.config.ModelID),
+		System:  []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: system}},
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: string(input)}},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens:   aws.Int32(int32(b.config.MaxTokens)), // #nosec G115 -- model limits are small
+			Temperature: aws.Float32(0),
+		},
+	}
+
+	var out *bedrockruntime.ConverseOutput
+	err = b.retry.do(ctx, func() error {
+		var err error
+		out, err = b.client.Converse(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Converse: %w", err)
+	}
+	if out.StopReason == types.StopReasonMaxTokens {
+		return nil, fmt.Errorf("Converse: output exceeded %d tokens", b.config.MaxTokens)
+	}
+	return parseBedrockOutput(out.Output, len(texts))
+}
+
+// parseBedrockOutput reads the JSON array of n translations in a reply,
+// ignoring any text around it such as code fences.
+func parseBedrockOutput(output types.ConverseOutput, n int) ([]string, error) {
+	var text strings.Builder
+	if message, ok := output.(*types.ConverseOutputMemberMessage); ok {
+		for _, c := range message.Value.Content {
+			if block, ok := c.(*types.ContentBlockMemberText); ok {
+				text.WriteString(block.Value)
+			}
+		}
+	}
+	reply := text.String()
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("Converse: reply is not a JSON array: %.100q", reply)
+	}
+	var translations []string
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &translations); err != nil {
+		return nil, fmt.Errorf("Converse: reply is not a JSON array of strings: %w", err)
+	}
+	if len(translations) != n {
+		return nil, fmt.Errorf("Converse: got %d translations for %d texts", len(translations), n)
+	}
+	return translations, nil
+}
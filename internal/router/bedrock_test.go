@@ -0,0 +1,154 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+func TestBedrockTokens(t *testing.T) {
+	tests := map[string]int{
+		"anthropic.claude-3-haiku-20240307-v1:0":      4096,
+		"eu.anthropic.claude-3-haiku-20240307-v1:0":   4096,
+		"anthropic.claude-3-5-sonnet-20240620-v1:0":   8192,
+		"amazon.titan-text-premier-v1:0":              3072,
+		"us.amazon.titan-text-express-v1":             8192,
+		"mistral.mistral-large-2402-v1:0":             defaultBedrockTokens,
+		"meta.llama3-70b-instruct-v1:0":               defaultBedrockTokens,
+		"us.anthropic.claude-3-5-haiku-20241022-v1:0": 8192,
+	}
+	for model, want := range tests {
+		if got := bedrockTokens(model); got != want {
+			t.Errorf("bedrockTokens(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestLoadBedrock(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantTokens int
+		wantNil    bool
+		wantErr    bool
+	}{
+		{name: "unset", wantNil: true},
+		{name: "model", config: `{"modelId": "anthropic.claude-3-haiku-20240307-v1:0"}`, wantTokens: 2048},
+		{name: "max tokens", config: `{"modelId": "amazon.titan-text-lite-v1", "maxTokens": 1000}`, wantTokens: 500},
+		{name: "no model", config: `{"maxTokens": 1000}`, wantErr: true},
+		{name: "negative max tokens", config: `{"modelId": "amazon.titan-text-lite-v1", "maxTokens": -1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(BedrockEnv, tt.config)
+			b, err := loadBedrock(aws.Config{Region: "eu-west-1"}, retryPolicy{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadBedrock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (b == nil) != tt.wantNil {
+				t.Fatalf("loadBedrock() = %v, want nil %v", b, tt.wantNil)
+			}
+			if b != nil && b.ChunkTokens() != tt.wantTokens {
+				t.Errorf("ChunkTokens() = %d, want %d", b.ChunkTokens(), tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestBedrock_TranslateChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/model/anthropic.claude-3-haiku-20240307-v1:0/converse"; r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "/bedrock/aws4_request") {
+			t.Errorf("request is not signed for bedrock: %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			System   []struct{ Text string }
+			Messages []struct {
+				Content []struct{ Text string }
+			}
+			InferenceConfig struct{ MaxTokens int }
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		system := body.System[0].Text
+		if !strings.Contains(system, "tagged es to the language tagged pt-BR") || !strings.HasSuffix(system, bedrockFormalities[FormalityFormal]) {
+			t.Errorf("system prompt = %q", system)
+		}
+		if body.InferenceConfig.MaxTokens != 4096 {
+			t.Errorf("maxTokens = %d, want 4096", body.InferenceConfig.MaxTokens)
+		}
+		var texts []string
+		_ = json.Unmarshal([]byte(body.Messages[0].Content[0].Text), &texts)
+		for i, text := range texts {
+			texts[i] = "pt:" + text
+		}
+		reply, _ := json.Marshal(texts)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"output":     map[string]any{"message": map[string]any{"role": "assistant", "content": []map[string]string{{"text": "```json\n" + string(reply) + "\n```"}}}},
+			"stopReason": "end_turn",
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv(BedrockEnv, `{"modelId": "anthropic.claude-3-haiku-20240307-v1:0"}`)
+	b, err := loadBedrock(aws.Config{
+		Region:       "eu-west-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}, retryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.TranslateChunksWithFormality(context.Background(), "es", "pt_BR", FormalityFormal, [][]string{{"hola", " "}, {"adiós"}})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithFormality() error = %v", err)
+	}
+	want := [][]string{{"pt:hola", " "}, {"pt:adiós"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TranslateChunksWithFormality() = %q, want %q", got, want)
+	}
+}
+
+func TestParseBedrockOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		n       int
+		want    []string
+		wantErr bool
+	}{
+		{name: "array", reply: `["a", "b"]`, n: 2, want: []string{"a", "b"}},
+		{name: "surrounding text", reply: "Here you go:\n[\"a\"]\n", n: 1, want: []string{"a"}},
+		{name: "not an array", reply: "Sorry, I cannot help.", n: 1, wantErr: true},
+		{name: "not strings", reply: `[1, 2]`, n: 2, wantErr: true},
+		{name: "wrong count", reply: `["a"]`, n: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBedrockOutput(&types.ConverseOutputMemberMessage{Value: types.Message{Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: tt.reply}}}}, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBedrockOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBedrockOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
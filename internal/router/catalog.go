@@ -0,0 +1,64 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Catalog is a versioned document of the supported languages and pairs,
+// generated from the routing table, for clients to cache instead of
+// hardcoding language lists.
+type Catalog struct {
+	// Version changes whenever the languages or routes change.
+	Version   string   `json:"version"`
+	Languages []string `json:"languages"`
+	// Pairs maps source → target → number of translator steps (1 direct, 2 or
+	// more through pivots).
+	Pairs map[string]map[string]int `json:"pairs"`
+}
+
+var (
+	catalogOnce sync.Once
+	catalog     *Catalog
+)
+
+// GetCatalog returns the catalog, built once from the routing table.
+func GetCatalog() *Catalog {
+	catalogOnce.Do(func() {
+		catalog = buildCatalog()
+	})
+	return catalog
+}
+
+// buildCatalog computes the catalog and its content version.
+func buildCatalog() *Catalog {
+	langs := GetSupportedLanguages()
+	sort.Strings(langs)
+
+	r := &Router{}
+	pairs := make(map[string]map[string]int, len(langs))
+	for _, source := range langs {
+		targets := map[string]int{}
+		for _, target := range langs {
+			if route := r.getRoute(source, target); route != nil && source != target {
+				targets[target] = len(route)
+			}
+		}
+		if len(targets) > 0 {
+			pairs[source] = targets
+		}
+	}
+
+	c := &Catalog{Languages: langs, Pairs: pairs}
+	// Map keys are marshaled sorted, so the hash is stable
+	content, err := json.Marshal(c)
+	if err != nil {
+		panic(err) // maps of strings and ints always marshal
+	}
+	sum := sha256.Sum256(content)
+	c.Version = hex.EncodeToString(sum[:8])
+	return c
+}
@@ -0,0 +1,42 @@
+package router
+
+import "testing"
+
+func TestGetCatalog(t *testing.T) {
+	c := GetCatalog()
+
+	if len(c.Languages) != len(supportedLanguages) {
+		t.Errorf("Languages = %d, want %d", len(c.Languages), len(supportedLanguages))
+	}
+	for i := 1; i < len(c.Languages); i++ {
+		if c.Languages[i-1] >= c.Languages[i] {
+			t.Fatalf("Languages not sorted at %d: %v", i, c.Languages[i-1:i+1])
+		}
+	}
+
+	tests := []struct {
+		source, target string
+		steps          int
+	}{
+		{"es", "en", 1},
+		{"en", "de", 1},
+		{"es", "fr", 2},
+		{"de", "pt_BR", 2},
+		{"es", "es", 0},
+	}
+	for _, tt := range tests {
+		if got := c.Pairs[tt.source][tt.target]; got != tt.steps {
+			t.Errorf("Pairs[%s][%s] = %d, want %d", tt.source, tt.target, got, tt.steps)
+		}
+	}
+
+	if len(c.Version) != 16 {
+		t.Errorf("Version = %q, want 16 hex characters", c.Version)
+	}
+	if again := buildCatalog(); again.Version != c.Version {
+		t.Errorf("Version is not stable: %s != %s", again.Version, c.Version)
+	}
+	if GetCatalog() != c {
+		t.Error("GetCatalog() should be cached")
+	}
+}
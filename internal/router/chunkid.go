@@ -0,0 +1,58 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"os"
+)
+
+// ChunkIDNamespaceEnv names the environment variable holding the namespace
+// mixed into chunk IDs. Changing it gives every chunk a new ID, e.g. so a
+// new deployment does not reuse the idempotency records of another one.
+const ChunkIDNamespaceEnv = "CHUNK_ID_NAMESPACE"
+
+// chunkIDLength is the length in hex digits of a chunk ID (128 bits).
+const chunkIDLength = 32
+
+// chunkIDs returns the ID of every chunk of req for functionName. An ID is a
+// hash of everything the translation depends on: the namespace, function,
+// target language, scores flag and the chunk's texts. Retried invocations
+// therefore carry the same IDs, and translators can recognize repeats.
+func chunkIDs(namespace, functionName string, req TranslatorRequest) []string {
+	if len(req.Chunks) == 0 {
+		return nil
+	}
+	h := sha256.New()
+	scores := "0"
+	if req.ReturnScores {
+		scores = "1"
+	}
+	prefix := []string{namespace, functionName, req.TargetLang, scores}
+
+	ids := make([]string, len(req.Chunks))
+	for i, chunk := range req.Chunks {
+		h.Reset()
+		for _, field := range prefix {
+			writeField(h, field)
+		}
+		for _, text := range chunk {
+			writeField(h, text)
+		}
+		ids[i] = hex.EncodeToString(h.Sum(nil))[:chunkIDLength]
+	}
+	return ids
+}
+
+// writeField writes s length-prefixed, so field boundaries are unambiguous.
+func writeField(h hash.Hash, s string) {
+	var size [binary.MaxVarintLen64]byte
+	h.Write(size[:binary.PutUvarint(size[:], uint64(len(s)))])
+	h.Write([]byte(s))
+}
+
+// chunkIDNamespace reads ChunkIDNamespaceEnv.
+func chunkIDNamespace() string {
+	return os.Getenv(ChunkIDNamespaceEnv)
+}
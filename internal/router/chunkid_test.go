@@ -0,0 +1,61 @@
+package router
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChunkIDs(t *testing.T) {
+	base := TranslatorRequest{Chunks: [][]string{{"Hola", "Adiós"}, {"Camisa"}}, TargetLang: "en"}
+	ids := chunkIDs("", "romance-en", base)
+	if len(ids) != 2 || len(ids[0]) != chunkIDLength || ids[0] == ids[1] {
+		t.Fatalf("chunkIDs() = %q, want two distinct %d-digit IDs", ids, chunkIDLength)
+	}
+
+	if again := chunkIDs("", "romance-en", base); again[0] != ids[0] || again[1] != ids[1] {
+		t.Errorf("chunkIDs() = %q on retry, want %q", again, ids)
+	}
+	reordered := TranslatorRequest{Chunks: [][]string{{"Camisa"}, {"Hola", "Adiós"}}, TargetLang: "en"}
+	if got := chunkIDs("", "romance-en", reordered); got[0] != ids[1] || got[1] != ids[0] {
+		t.Errorf("chunk IDs depend on the chunk position: %q vs %q", got, ids)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		function  string
+		req       TranslatorRequest
+	}{
+		{"namespace", "prod", "romance-en", base},
+		{"function", "", "en-romance", base},
+		{"target language", "", "romance-en", TranslatorRequest{Chunks: base.Chunks, TargetLang: "fr"}},
+		{"scores", "", "romance-en", TranslatorRequest{Chunks: base.Chunks, TargetLang: "en", ReturnScores: true}},
+		{"text", "", "romance-en", TranslatorRequest{Chunks: [][]string{{"Hola", "Adios"}, {"Camisa"}}, TargetLang: "en"}},
+		{"text boundaries", "", "romance-en", TranslatorRequest{Chunks: [][]string{{"HolaAdiós"}, {"Camisa"}}, TargetLang: "en"}},
+	}
+	for _, tt := range tests {
+		if got := chunkIDs(tt.namespace, tt.function, tt.req); got[0] == ids[0] {
+			t.Errorf("%s: chunk ID unchanged", tt.name)
+		}
+	}
+}
+
+func TestChunkIDs_Payload(t *testing.T) {
+	if ids := chunkIDs("", "romance-en", TranslatorRequest{}); ids != nil {
+		t.Errorf("chunkIDs() = %q for no chunks, want nil", ids)
+	}
+
+	req := TranslatorRequest{Chunks: [][]string{{"Hola"}}}
+	req.ChunkIDs = chunkIDs("", "romance-en", req)
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wire map[string]any
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatal(err)
+	}
+	if ids, ok := wire["chunk_ids"].([]any); !ok || len(ids) != 1 {
+		t.Errorf("payload %s has no chunk_ids", data)
+	}
+}
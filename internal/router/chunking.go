@@ -0,0 +1,43 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+)
+
+// ChunkTokensEnv names the environment variable holding the token budget of
+// a chunk per function name as JSON (or ChunkTokensEnv+"_FILE" pointing to
+// a JSON file), for translators with more memory than the 384MB the default
+// budget is tuned for.
+const ChunkTokensEnv = "TRANSLATOR_CHUNK_TOKENS"
+
+// loadChunkTokens reads the TRANSLATOR_CHUNK_TOKENS config, resolving {env}
+// in function names.
+func loadChunkTokens(env string) (map[string]int, error) {
+	var config map[string]int
+	if _, err := appconfig.LoadJSON(ChunkTokensEnv, &config); err != nil {
+		return nil, err
+	}
+	budgets := make(map[string]int, len(config))
+	for function, tokens := range config {
+		if tokens <= 0 {
+			return nil, fmt.Errorf("invalid %s for %q: budget must be positive", ChunkTokensEnv, function)
+		}
+		budgets[strings.ReplaceAll(function, envPlaceholder, env)] = tokens
+	}
+	return budgets, nil
+}
+
+// chunkTokens returns the smallest token budget configured for the steps,
+// as every chunk goes through all of them, or 0 when none has one.
+func (r *Router) chunkTokens(steps []string) int {
+	budget := 0
+	for _, function := range steps {
+		if tokens, ok := r.chunkBudgets[function]; ok && (budget == 0 || tokens < budget) {
+			budget = tokens
+		}
+	}
+	return budget
+}
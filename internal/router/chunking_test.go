@@ -0,0 +1,56 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadChunkTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "unset", want: map[string]int{}},
+		{name: "env placeholder", config: `{"translator-en-de-{env}": 8000}`, want: map[string]int{"translator-en-de-prod": 8000}},
+		{name: "zero", config: `{"translator-en-de": 0}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ChunkTokensEnv, tt.config)
+			got, err := loadChunkTokens("prod")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadChunkTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadChunkTokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkTokens(t *testing.T) {
+	r := &Router{chunkBudgets: map[string]int{"translator-en-de": 8000, "translator-es-en": 5000}}
+
+	tests := []struct {
+		name  string
+		steps []string
+		want  int
+	}{
+		{"configured", []string{"translator-en-de"}, 8000},
+		{"smallest of the route", []string{"translator-es-en", "translator-en-de"}, 5000},
+		{"unconfigured steps are ignored", []string{"translator-fr-en", "translator-en-de"}, 8000},
+		{"none configured", []string{"translator-fr-en"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.chunkTokens(tt.steps); got != tt.want {
+				t.Errorf("chunkTokens(%v) = %d, want %d", tt.steps, got, tt.want)
+			}
+		})
+	}
+}
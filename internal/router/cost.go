@@ -0,0 +1,116 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+)
+
+// CostsEnv names the environment variable holding the cost model as JSON
+// (or CostsEnv+"_FILE" pointing to a JSON file): a Cost per function name.
+const CostsEnv = "TRANSLATOR_COSTS"
+
+// Cost is the price of invoking a translator function, in any currency unit
+// as long as every function and maxCost use the same one. Functions without
+// a cost are free.
+type Cost struct {
+	// PerChar is the price of every input character.
+	PerChar float64 `json:"perChar,omitempty"`
+	// PerGBSecond is the price of a GB-second of compute, charged on
+	// MemoryMB for the predicted duration of the invocation.
+	PerGBSecond float64 `json:"perGbSecond,omitempty"`
+	MemoryMB    int     `json:"memoryMb,omitempty"`
+	// Draft is a cheaper function translating the same pair, e.g. a smaller
+	// model, used when the function would exceed a request's budget.
+	Draft string `json:"draft,omitempty"`
+}
+
+// CostError reports a translation whose cheapest route is estimated to cost
+// more than the request allows.
+type CostError struct {
+	// Estimate is the estimated cost of the cheapest route.
+	Estimate float64
+	MaxCost  float64
+}
+
+func (e *CostError) Error() string {
+	return fmt.Sprintf("estimated cost %g exceeds maxCost %g", e.Estimate, e.MaxCost)
+}
+
+// loadCosts reads the TRANSLATOR_COSTS config, resolving {env} in function
+// names.
+func loadCosts(env string) (map[string]Cost, error) {
+	var config map[string]Cost
+	if _, err := appconfig.LoadJSON(CostsEnv, &config); err != nil {
+		return nil, err
+	}
+	costs := make(map[string]Cost, len(config))
+	for function, c := range config {
+		if c.PerChar < 0 || c.PerGBSecond < 0 || c.MemoryMB < 0 {
+			return nil, fmt.Errorf("invalid %s: negative cost for %q", CostsEnv, function)
+		}
+		c.Draft = strings.ReplaceAll(c.Draft, envPlaceholder, env)
+		costs[strings.ReplaceAll(function, envPlaceholder, env)] = c
+	}
+	return costs, nil
+}
+
+// stepCost estimates the cost of sending chunks to function. The compute
+// part uses the latency the function's model predicts for a single
+// invocation, so it is only counted once the function has been sampled.
+func (r *Router) stepCost(function string, chunks [][]string) float64 {
+	c, ok := r.costs[function]
+	if !ok {
+		return 0
+	}
+	chars := 0
+	for _, chunk := range chunks {
+		for _, text := range chunk {
+			chars += utf8.RuneCountInString(text)
+		}
+	}
+	cost := c.PerChar * float64(chars)
+	if c.PerGBSecond > 0 && c.MemoryMB > 0 {
+		seconds := modelFor(function).estimate(len(chunks)) / 1000
+		cost += c.PerGBSecond * float64(c.MemoryMB) / 1024 * seconds
+	}
+	return cost
+}
+
+// budget picks the functions of route for a request that may spend at most
+// maxCost (0 for no limit). It returns the overrides to invoke, which swap
+// in draft functions when the route would exceed the budget, and the
+// estimated cost of the route.
+func (r *Router) budget(route []routeStep, chunks [][]string, overrides map[string]string, maxCost float64) (map[string]string, float64, error) {
+	cost := r.routeCost(route, chunks, overrides)
+	if maxCost <= 0 || cost <= maxCost {
+		return overrides, cost, nil
+	}
+
+	drafts := make(map[string]string, len(route)+len(overrides))
+	for name, function := range overrides {
+		drafts[name] = function
+	}
+	for _, step := range route {
+		if draft := r.costs[stepFunction(step, overrides)].Draft; draft != "" {
+			drafts[step.lambdaName] = draft
+		}
+	}
+	draftCost := r.routeCost(route, chunks, drafts)
+	if draftCost <= maxCost {
+		return drafts, draftCost, nil
+	}
+	return nil, 0, &CostError{Estimate: min(cost, draftCost), MaxCost: maxCost}
+}
+
+// routeCost estimates the cost of every step of route. Later steps of a
+// pivot route are assumed to receive as much text as the first.
+func (r *Router) routeCost(route []routeStep, chunks [][]string, overrides map[string]string) float64 {
+	total := 0.0
+	for _, step := range route {
+		total += r.stepCost(stepFunction(step, overrides), chunks)
+	}
+	return total
+}
@@ -0,0 +1,126 @@
+package router
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	route := []routeStep{
+		{lambdaName: "translator-es-en", targetLang: "en"},
+		{lambdaName: "translator-en-de", targetLang: "de"},
+	}
+	chunks := [][]string{{"hola mundo"}} // 10 characters
+	costs := map[string]Cost{
+		"translator-es-en":       {PerChar: 0.01, Draft: "translator-es-en-draft"},
+		"translator-es-en-draft": {PerChar: 0.001},
+		"translator-en-de":       {PerChar: 0.01},
+	}
+
+	tests := []struct {
+		name          string
+		maxCost       float64
+		overrides     map[string]string
+		wantOverrides map[string]string
+		wantCost      float64
+		wantErr       float64 // estimate of the CostError, 0 for none
+	}{
+		{name: "no limit", wantCost: 0.2},
+		{name: "within budget", maxCost: 0.5, wantCost: 0.2},
+		{
+			name:          "draft fits",
+			maxCost:       0.15,
+			wantOverrides: map[string]string{"translator-es-en": "translator-es-en-draft"},
+			wantCost:      0.11,
+		},
+		{name: "nothing fits", maxCost: 0.05, wantErr: 0.11},
+		{
+			name:          "override without cost is free",
+			maxCost:       0.15,
+			overrides:     map[string]string{"translator-es-en": "translator-es-en-canary"},
+			wantOverrides: map[string]string{"translator-es-en": "translator-es-en-canary"},
+			wantCost:      0.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{costs: costs}
+			overrides, cost, err := r.budget(route, chunks, tt.overrides, tt.maxCost)
+			if tt.wantErr > 0 {
+				var costErr *CostError
+				if !errors.As(err, &costErr) {
+					t.Fatalf("budget() error = %v, want a CostError", err)
+				}
+				if !approx(costErr.Estimate, tt.wantErr) || costErr.MaxCost != tt.maxCost {
+					t.Errorf("CostError = %+v, want estimate %g, maxCost %g", costErr, tt.wantErr, tt.maxCost)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("budget() error = %v", err)
+			}
+			if !approx(cost, tt.wantCost) {
+				t.Errorf("budget() cost = %g, want %g", cost, tt.wantCost)
+			}
+			if len(overrides) > 0 || len(tt.wantOverrides) > 0 {
+				if !reflect.DeepEqual(overrides, tt.wantOverrides) {
+					t.Errorf("budget() overrides = %v, want %v", overrides, tt.wantOverrides)
+				}
+			}
+		})
+	}
+}
+
+func TestStepCost_Compute(t *testing.T) {
+	function := "test-cost-compute"
+	for n := 1; n <= 3; n++ {
+		modelFor(function).observe(DispatchSingle, n, time.Duration(n)*time.Second)
+	}
+	r := &Router{costs: map[string]Cost{function: {PerGBSecond: 1, MemoryMB: 2048}}}
+
+	// 2 chunks ≈ 2s on 2 GB
+	if got := r.stepCost(function, [][]string{{"a"}, {"b"}}); !approx(got, 4) {
+		t.Errorf("stepCost() = %g, want 4", got)
+	}
+	if got := r.stepCost("test-cost-unsampled", [][]string{{"a"}}); got != 0 {
+		t.Errorf("stepCost() without a cost = %g, want 0", got)
+	}
+}
+
+func TestLoadCosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]Cost
+		wantErr bool
+	}{
+		{name: "unset", want: map[string]Cost{}},
+		{
+			name:   "env placeholder",
+			config: `{"translator-{env}": {"perChar": 0.00002, "draft": "draft-{env}"}}`,
+			want:   map[string]Cost{"translator-prod": {PerChar: 0.00002, Draft: "draft-prod"}},
+		},
+		{name: "negative", config: `{"translator": {"perChar": -1}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CostsEnv, tt.config)
+			got, err := loadCosts("prod")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCosts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadCosts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func approx(got, want float64) bool {
+	d := got - want
+	return d < 1e-9 && d > -1e-9
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/pricofy/translation-manager/internal/secrets"
+)
+
+// DeepLKeySecretEnv names the environment variable naming the Secrets
+// Manager secret holding the DeepL API key. DeepLKeyEnv, which held the
+// key in plaintext, is rejected so a key left there is not silently unused.
+const (
+	DeepLKeySecretEnv = "DEEPL_API_KEY_SECRET"
+	DeepLKeyEnv       = "DEEPL_API_KEY"
+)
+
+// Formalities accepted in Options.Formality. Backends without formality
+// control, and targets without it, translate as usual.
+const (
+	FormalityFormal   = "formal"
+	FormalityInformal = "informal"
+)
+
+// deepLFormalities maps formalities to DeepL's, which fall back to the
+// default formality for targets that have none.
+var deepLFormalities = map[string]string{
+	FormalityFormal:   "prefer_more",
+	FormalityInformal: "prefer_less",
+}
+
+// readSecret reads the string value of a Secrets Manager secret.
+var readSecret = func(ctx context.Context, cfg aws.Config, id string) (string, error) {
+	return secrets.New(cfg).String(ctx, id)
+}
+
+// deepLKey returns the DeepL API key from the secret DEEPL_API_KEY_SECRET
+// names, read once when the container starts, or "" when it is unset.
+func deepLKey(ctx context.Context, cfg aws.Config) (string, error) {
+	if os.Getenv(DeepLKeyEnv) != "" {
+		return "", fmt.Errorf("%s is not supported: store the DeepL API key in Secrets Manager and set %s", DeepLKeyEnv, DeepLKeySecretEnv)
+	}
+	id := os.Getenv(DeepLKeySecretEnv)
+	if id == "" {
+		return "", nil
+	}
+	key, err := readSecret(ctx, cfg, id)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", DeepLKeySecretEnv, err)
+	}
+	return strings.TrimSpace(key), nil
+}
+
+// DeepL API endpoints; keys of free accounts end in ":fx".
+const (
+	deepLEndpoint     = "https://api.deepl.com/v2/translate"
+	deepLFreeEndpoint = "https://api-free.deepl.com/v2/translate"
+)
+
+// DeepL translates with the DeepL API, one request per chunk.
+type DeepL struct {
+	client   HTTPDoer
+	endpoint string
+	key      string
+	retry    retryPolicy
+}
+
+// NewDeepL creates a DeepL backend authenticated with key.
+func NewDeepL(key string, retry retryPolicy) *DeepL {
+	endpoint := deepLEndpoint
+	if strings.HasSuffix(key, ":fx") {
+		endpoint = deepLFreeEndpoint
+	}
+	return &DeepL{client: http.DefaultClient, endpoint: endpoint, key: key, retry: retry}
+}
+
+// deepLRequest is the body of a DeepL translate request.
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang"`
+	TargetLang string   `json:"target_lang"`
+	Formality  string   `json:"formality,omitempty"`
+}
+
+// deepLResponse is the body of a DeepL translate response.
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+// deepLSource returns the DeepL source language code: the base language.
+func deepLSource(lang string) string {
+	base, _, _ := strings.Cut(lang, "_")
+	return strings.ToUpper(base)
+}
+
+// deepLTarget returns the DeepL target language code. English and
+// Portuguese targets need a variant.
+func deepLTarget(lang string) string {
+	switch lang {
+	case "en":
+		return "EN-US"
+	case "pt", "pt_PT":
+		return "PT-PT"
+	case "pt_BR":
+		return "PT-BR"
+	case "en_GB":
+		return "EN-GB"
+	}
+	return deepLSource(lang)
+}
+
+// TranslateChunks translates chunk by chunk.
+func (d *DeepL) TranslateChunks(ctx context.Context, source, target string, chunks [][]string) ([][]string, error) {
+	return d.TranslateChunksWithFormality(ctx, source, target, "", chunks)
+}
+
+// TranslateChunksWithFormality translates chunk by chunk in formality
+// ("" for the default).
+func (d *DeepL) TranslateChunksWithFormality(ctx context.Context, source, target, formality string, chunks [][]string) ([][]string, error) {
+	out := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		translations, err := d.translate(ctx, deepLRequest{
+			Text:       chunk,
+			SourceLang: deepLSource(source),
+			TargetLang: deepLTarget(target),
+			Formality:  deepLFormalities[formality],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		out[i] = translations
+	}
+	return out, nil
+}
+
+// translate sends one request under the retry policy.
+func (d *DeepL) translate(ctx context.Context, body deepLRequest) ([]string, error) {
+	if len(body.Text) == 0 {
+		return []string{}, nil
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp deepLResponse
+	err = d.retry.do(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "DeepL-Auth-Key "+d.key)
+		httpResp, err := d.client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := readResponse(httpResp)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make([]string, len(resp.Translations))
+	for i, t := range resp.Translations {
+		translations[i] = t.Text
+	}
+	return translations, nil
+}
@@ -0,0 +1,153 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestDeepLLanguageCodes(t *testing.T) {
+	tests := []struct {
+		lang, source, target string
+	}{
+		{lang: "es_MX", source: "ES", target: "ES"},
+		{lang: "en", source: "EN", target: "EN-US"},
+		{lang: "pt_BR", source: "PT", target: "PT-BR"},
+		{lang: "pt", source: "PT", target: "PT-PT"},
+	}
+	for _, tt := range tests {
+		if got := deepLSource(tt.lang); got != tt.source {
+			t.Errorf("deepLSource(%q) = %q, want %q", tt.lang, got, tt.source)
+		}
+		if got := deepLTarget(tt.lang); got != tt.target {
+			t.Errorf("deepLTarget(%q) = %q, want %q", tt.lang, got, tt.target)
+		}
+	}
+}
+
+func TestNewDeepL_Endpoint(t *testing.T) {
+	if got := NewDeepL("abc:fx", retryPolicy{}).endpoint; got != deepLFreeEndpoint {
+		t.Errorf("free key endpoint = %q, want %q", got, deepLFreeEndpoint)
+	}
+	if got := NewDeepL("abc", retryPolicy{}).endpoint; got != deepLEndpoint {
+		t.Errorf("pro key endpoint = %q, want %q", got, deepLEndpoint)
+	}
+}
+
+func TestDeepL_TranslateChunks(t *testing.T) {
+	var bodies []deepLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body deepLRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		bodies = append(bodies, body)
+		var resp deepLResponse
+		for _, text := range body.Text {
+			resp.Translations = append(resp.Translations, struct {
+				Text string `json:"text"`
+			}{Text: strings.ToUpper(text)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	d := NewDeepL("secret", retryPolicy{})
+	d.endpoint = server.URL
+
+	got, err := d.TranslateChunks(context.Background(), "de", "pt_BR", [][]string{{"rot", "blau"}, {"grün"}})
+	if err != nil {
+		t.Fatalf("TranslateChunks() error = %v", err)
+	}
+	want := [][]string{{"ROT", "BLAU"}, {"GRÜN"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TranslateChunks() = %q, want %q", got, want)
+	}
+	if len(bodies) != 2 || bodies[0].SourceLang != "DE" || bodies[0].TargetLang != "PT-BR" {
+		t.Errorf("requests = %+v, want one per chunk from DE to PT-BR", bodies)
+	}
+}
+
+func TestDeepL_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"Quota exceeded"}`, 456)
+	}))
+	defer server.Close()
+
+	d := NewDeepL("secret", retryPolicy{attempts: 3})
+	d.endpoint = server.URL
+
+	_, err := d.TranslateChunks(context.Background(), "de", "en", [][]string{{"rot"}})
+	if err == nil || !strings.Contains(err.Error(), "Quota exceeded") {
+		t.Errorf("TranslateChunks() error = %v, want the quota error", err)
+	}
+}
+
+func TestDeepL_Formality(t *testing.T) {
+	var formalities []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body deepLRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		formalities = append(formalities, body.Formality)
+		_, _ = w.Write([]byte(`{"translations": [{"text": "Wie geht es Ihnen?"}]}`))
+	}))
+	defer server.Close()
+
+	d := NewDeepL("secret", retryPolicy{})
+	d.endpoint = server.URL
+	for _, formality := range []string{FormalityFormal, FormalityInformal, ""} {
+		if _, err := d.TranslateChunksWithFormality(context.Background(), "en", "de", formality, [][]string{{"How are you?"}}); err != nil {
+			t.Fatalf("TranslateChunksWithFormality(%q) error = %v", formality, err)
+		}
+	}
+	if want := []string{"prefer_more", "prefer_less", ""}; !reflect.DeepEqual(formalities, want) {
+		t.Errorf("formalities sent = %q, want %q", formalities, want)
+	}
+}
+
+func TestDeepLKey(t *testing.T) {
+	read := readSecret
+	defer func() { readSecret = read }()
+	readSecret = func(_ context.Context, _ aws.Config, id string) (string, error) {
+		if id != "deepl-api-key" {
+			return "", errors.New("ResourceNotFoundException")
+		}
+		return "secret-key:fx\n", nil
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		secret  string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "plaintext key", key: "env-key", secret: "deepl-api-key", wantErr: true},
+		{name: "secret", secret: "deepl-api-key", want: "secret-key:fx"},
+		{name: "missing secret", secret: "other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DeepLKeyEnv, tt.key)
+			t.Setenv(DeepLKeySecretEnv, tt.secret)
+			got, err := deepLKey(context.Background(), aws.Config{})
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("deepLKey() = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/tracing"
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// Dispatch strategies of a route step.
+//
+// A translator runs the chunks of one invocation sequentially, so a single
+// invocation costs one invocation overhead plus every chunk's model time,
+// while fanning out costs the slowest of several parallel invocations. Small
+// batches are faster in one invocation and large ones fanned out; where the
+// crossover lies depends on each translator's overhead and speed, so it is
+// learned per function from the latencies of earlier steps.
+const (
+	// DispatchSingle sends every chunk in one invocation.
+	DispatchSingle = "single"
+	// DispatchFanOut sends each chunk in its own invocation, in parallel.
+	DispatchFanOut = "fanout"
+	// DispatchPipelined sends each chunk through the steps of a route on
+	// its own, in parallel (see pipelines and translateDeadLettered).
+	DispatchPipelined = "pipelined"
+)
+
+// FanOutThresholdEnv names the environment variable holding the chunk count
+// from which steps fan out while a function has too few latency samples to
+// choose by itself.
+const FanOutThresholdEnv = "FANOUT_THRESHOLD"
+
+// DefaultFanOutThreshold is the fan-out threshold when FanOutThresholdEnv
+// is unset.
+const DefaultFanOutThreshold = 4
+
+const (
+	// fanOutConcurrency bounds the invocations of a fanned-out step in flight.
+	fanOutConcurrency = 10
+	// minLatencySamples is the number of samples of each strategy needed
+	// before the latency model replaces the threshold.
+	minLatencySamples = 5
+	// latencyWeight is the weight of a new sample in the moving averages.
+	latencyWeight = 0.2
+	// exploreEvery makes every exploreEvery-th multi-chunk step use the
+	// strategy the model did not choose, so both estimates stay current.
+	exploreEvery = 20
+)
+
+// latencies holds the latency model of every translator function. It is
+// shared by all routers in the container.
+var latencies sync.Map // function name → *latencyModel
+
+// latencyModel estimates the latency of a step with either strategy.
+type latencyModel struct {
+	mu sync.Mutex
+	// single fits the duration of single invocations (ms) against their
+	// chunk count: duration ≈ overhead + perChunk × chunks.
+	single linearFit
+	// fanOut is the average duration of fanned-out steps (ms).
+	fanOut  movingAverage
+	choices int
+}
+
+// linearFit is an exponentially weighted least-squares fit of y against x.
+type linearFit struct {
+	x, y, xx, xy float64
+	samples      int
+}
+
+func (f *linearFit) add(x, y float64) {
+	if f.samples == 0 {
+		f.x, f.y, f.xx, f.xy = x, y, x*x, x*y
+	} else {
+		f.x += latencyWeight * (x - f.x)
+		f.y += latencyWeight * (y - f.y)
+		f.xx += latencyWeight * (x*x - f.xx)
+		f.xy += latencyWeight * (x*y - f.xy)
+	}
+	f.samples++
+}
+
+// predict estimates y at x. Without spread in the samples' x it assumes y
+// is proportional to x.
+func (f *linearFit) predict(x float64) float64 {
+	variance := f.xx - f.x*f.x
+	if variance < 1e-6 {
+		return f.y / f.x * x
+	}
+	slope := max((f.xy-f.x*f.y)/variance, 0)
+	return f.y + slope*(x-f.x)
+}
+
+// movingAverage is an exponentially weighted moving average.
+type movingAverage struct {
+	mean    float64
+	samples int
+}
+
+func (a *movingAverage) add(v float64) {
+	if a.samples == 0 {
+		a.mean = v
+	} else {
+		a.mean += latencyWeight * (v - a.mean)
+	}
+	a.samples++
+}
+
+// modelFor returns the latency model of functionName.
+func modelFor(functionName string) *latencyModel {
+	m, _ := latencies.LoadOrStore(functionName, &latencyModel{})
+	return m.(*latencyModel)
+}
+
+// choose picks the strategy for a step of n chunks: by the threshold until
+// both strategies have enough samples, then the one predicted faster, with
+// occasional exploration of the other.
+func (m *latencyModel) choose(n, threshold int) string {
+	if n < 2 {
+		return DispatchSingle
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if threshold <= 0 {
+		threshold = DefaultFanOutThreshold
+	}
+	fanOut := n >= threshold
+	if m.single.samples >= minLatencySamples && m.fanOut.samples >= minLatencySamples {
+		fanOut = m.single.predict(float64(n)) > m.fanOut.mean
+		m.choices++
+		if m.choices%exploreEvery == 0 {
+			fanOut = !fanOut
+		}
+	}
+	if fanOut {
+		return DispatchFanOut
+	}
+	return DispatchSingle
+}
+
+// observe records the duration of a successful step of n chunks.
+func (m *latencyModel) observe(strategy string, n int, d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if strategy == DispatchFanOut {
+		m.fanOut.add(ms)
+		return
+	}
+	m.single.add(float64(n), ms)
+}
+
+// estimate predicts the duration (ms) of a single invocation of n chunks,
+// or 0 before the first sample.
+func (m *latencyModel) estimate(n int) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.single.samples == 0 {
+		return 0
+	}
+	return max(m.single.predict(float64(n)), 0)
+}
+
+// fanOutThreshold reads FanOutThresholdEnv.
+func fanOutThreshold() (int, error) {
+	v := os.Getenv(FanOutThresholdEnv)
+	if v == "" {
+		return DefaultFanOutThreshold, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 2 {
+		return 0, fmt.Errorf("invalid %s %q: want a chunk count of at least 2", FanOutThresholdEnv, v)
+	}
+	return n, nil
+}
+
+// dispatch invokes functionName for chunks with the strategy its latency
+// model chooses, and records how long the step took. It returns the
+// strategy and the duration with the response.
+func (r *Router) dispatch(ctx context.Context, functionName, targetLang string, chunks [][]string, returnScores bool) (*TranslatorResponse, string, time.Duration, error) {
+	model := modelFor(functionName)
+	strategy := model.choose(len(chunks), r.fanOutThreshold)
+
+	ctx, seg := tracing.Start(ctx, "dispatch")
+	seg.Annotate("Function", functionName)
+	seg.Annotate("Dispatch", strategy)
+	start := time.Now()
+	var resp *TranslatorResponse
+	var err error
+	if strategy == DispatchFanOut {
+		resp, err = r.fanOut(ctx, functionName, targetLang, chunks, returnScores)
+	} else {
+		resp, err = r.invokeLambda(ctx, functionName, targetLang, chunks, returnScores)
+	}
+	elapsed := time.Since(start)
+	seg.End(err)
+	if err == nil {
+		model.observe(strategy, len(chunks), elapsed)
+		observeModelVersion(functionName, resp.ModelVersion)
+	}
+	return resp, strategy, elapsed, err
+}
+
+// fanOut invokes functionName once per chunk, in parallel, and merges the
+// responses in chunk order. Scores are kept only if every chunk has them.
+func (r *Router) fanOut(ctx context.Context, functionName, targetLang string, chunks [][]string, returnScores bool) (*TranslatorResponse, error) {
+	responses := make([]*TranslatorResponse, len(chunks))
+	err := workpool.Pool{Limit: fanOutConcurrency}.Run(ctx, len(chunks), func(ctx context.Context, i int) error {
+		var err error
+		responses[i], err = r.invokeLambda(ctx, functionName, targetLang, [][]string{chunks[i]}, returnScores)
+		return err
+	})
+	if first := workpool.First(err); first != nil {
+		return nil, fmt.Errorf("chunk %d: %w", first.Index, first.Err)
+	}
+
+	merged := &TranslatorResponse{Translations: make([][]string, len(chunks))}
+	if returnScores {
+		merged.Scores = make([][]float64, len(chunks))
+	}
+	for i, resp := range responses {
+		if len(resp.Translations) != 1 {
+			return nil, fmt.Errorf("chunk %d: %w", i, &ShapeError{Function: functionName, Chunk: -1, Want: 1, Got: len(resp.Translations)})
+		}
+		merged.Translations[i] = resp.Translations[0]
+		if merged.ModelVersion == "" {
+			merged.ModelVersion = resp.ModelVersion
+		}
+		if merged.Scores != nil && len(resp.Scores) == 1 {
+			merged.Scores[i] = resp.Scores[0]
+		} else {
+			merged.Scores = nil
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,141 @@
+package router
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLatencyModel_Choose(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunks    int
+		threshold int
+		single    map[int]time.Duration // chunks → duration of single samples
+		fanOut    time.Duration
+		fanOuts   int
+		want      string
+	}{
+		{name: "one chunk", chunks: 1, threshold: 2, want: DispatchSingle},
+		{name: "below threshold", chunks: 3, threshold: 4, want: DispatchSingle},
+		{name: "at threshold", chunks: 4, threshold: 4, want: DispatchFanOut},
+		{name: "unset threshold uses default", chunks: DefaultFanOutThreshold, want: DispatchFanOut},
+		{
+			name:      "too few samples keep the threshold",
+			chunks:    8,
+			threshold: 4,
+			single:    map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond},
+			fanOut:    10 * time.Second,
+			fanOuts:   2,
+			want:      DispatchFanOut,
+		},
+		{
+			name:      "model prefers single when fan-out is slow",
+			chunks:    8,
+			threshold: 4,
+			single: map[int]time.Duration{
+				1: 110 * time.Millisecond, 2: 120 * time.Millisecond, 3: 130 * time.Millisecond,
+				4: 140 * time.Millisecond, 5: 150 * time.Millisecond,
+			},
+			fanOut:  time.Second,
+			fanOuts: minLatencySamples,
+			want:    DispatchSingle,
+		},
+		{
+			name:      "model prefers fan-out when chunks are slow",
+			chunks:    3,
+			threshold: 10,
+			single: map[int]time.Duration{
+				1: 600 * time.Millisecond, 2: 1100 * time.Millisecond, 3: 1600 * time.Millisecond,
+				4: 2100 * time.Millisecond, 5: 2600 * time.Millisecond,
+			},
+			fanOut:  700 * time.Millisecond,
+			fanOuts: minLatencySamples,
+			want:    DispatchFanOut,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &latencyModel{}
+			for n := 1; n <= len(tt.single); n++ {
+				m.observe(DispatchSingle, n, tt.single[n])
+			}
+			for i := 0; i < tt.fanOuts; i++ {
+				m.observe(DispatchFanOut, tt.chunks, tt.fanOut)
+			}
+			if got := m.choose(tt.chunks, tt.threshold); got != tt.want {
+				t.Errorf("choose(%d, %d) = %q, want %q", tt.chunks, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatencyModel_Explore(t *testing.T) {
+	m := &latencyModel{}
+	for i := 0; i < minLatencySamples; i++ {
+		m.observe(DispatchSingle, 2, 100*time.Millisecond)
+		m.observe(DispatchFanOut, 2, time.Second)
+	}
+
+	explored := 0
+	for i := 0; i < 2*exploreEvery; i++ {
+		if m.choose(2, 2) == DispatchFanOut {
+			explored++
+		}
+	}
+	if explored != 2 {
+		t.Errorf("explored fan-out %d times in %d choices, want 2", explored, 2*exploreEvery)
+	}
+}
+
+func TestFanOutThreshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    int
+		wantErr bool
+	}{
+		{name: "unset", env: "", want: DefaultFanOutThreshold},
+		{name: "set", env: "8", want: 8},
+		{name: "too small", env: "1", wantErr: true},
+		{name: "not a number", env: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FanOutThresholdEnv, tt.env)
+			got, err := fanOutThreshold()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fanOutThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fanOutThreshold() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatch_FanOut(t *testing.T) {
+	chunks := [][]string{{"uno"}, {"dos", "tres"}, {"cuatro"}}
+	client := &recordingTranslator{}
+	r := &Router{lambdaClient: client, fanOutThreshold: 2}
+
+	resp, strategy, _, err := r.dispatch(context.Background(), "test-dispatch-fanout", "en", chunks, false)
+	if err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if strategy != DispatchFanOut {
+		t.Errorf("strategy = %q, want %q", strategy, DispatchFanOut)
+	}
+	if !reflect.DeepEqual(resp.Translations, chunks) {
+		t.Errorf("translations = %q, want %q", resp.Translations, chunks)
+	}
+	if len(client.calls) != len(chunks) {
+		t.Errorf("invocations = %d, want %d", len(client.calls), len(chunks))
+	}
+	if m := modelFor("test-dispatch-fanout"); m.fanOut.samples != 1 {
+		t.Errorf("fan-out samples = %d, want 1", m.fanOut.samples)
+	}
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"context"
+	"unicode"
+)
+
+// EnvLocal is the ENVIRONMENT of local development and CI, where every
+// pair is translated by the dry-run backend.
+const EnvLocal = "local"
+
+// DryRun is an in-process backend returning deterministic
+// pseudo-translations: every text with the letters of each word reversed
+// and the target language as a prefix, e.g. "[fr] aloH odnum". Digits,
+// punctuation and placeholders are left in place, so masking, markup and
+// chunking work as with real translators, without any AWS call.
+type DryRun struct{}
+
+var _ Translator = DryRun{}
+
+// TranslateChunks implements Translator.
+func (DryRun) TranslateChunks(ctx context.Context, _, target string, chunks [][]string) ([][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out := make([][]string, len(chunks))
+	for i, chunk := range chunks {
+		out[i] = make([]string, len(chunk))
+		for j, text := range chunk {
+			out[i][j] = PseudoTranslate(text, target)
+		}
+	}
+	return out, nil
+}
+
+// PseudoTranslate returns the dry-run translation of text into target.
+func PseudoTranslate(text, target string) string {
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !unicode.IsLetter(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && unicode.IsLetter(runes[end]) {
+			end++
+		}
+		for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		start = end
+	}
+	return "[" + target + "] " + string(runes)
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPseudoTranslate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hola mundo", "[fr] aloH odnum"},
+		{"iPhone 12, 64GB", "[fr] enohPi 12, 64BG"},
+		{"Envío __0__ gratis", "[fr] oívnE __0__ sitarg"},
+		{"", "[fr] "},
+	}
+
+	for _, tt := range tests {
+		if got := PseudoTranslate(tt.text, "fr"); got != tt.want {
+			t.Errorf("PseudoTranslate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_DryRun(t *testing.T) {
+	r := &Router{
+		dryRun:        true,
+		backendConfig: backendConfig{Pairs: map[string]string{"es-en": BackendDeepL}},
+		backends:      map[string]Translator{BackendDryRun: DryRun{}},
+	}
+	if got := r.backend("es", "en", ""); got != BackendDryRun {
+		t.Errorf("backend() in the local environment = %q, want %q", got, BackendDryRun)
+	}
+	if got := r.backend("es", "en", BackendLambda); got != BackendLambda {
+		t.Errorf("backend() with a forced backend = %q, want %q", got, BackendLambda)
+	}
+
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "de", [][]string{{"Hola"}, {"adiós"}}, Options{})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	want := [][]string{{"[de] aloH"}, {"[de] sóida"}}
+	if !reflect.DeepEqual(result.Translations, want) || !reflect.DeepEqual(result.Steps, []string{BackendDryRun}) {
+		t.Errorf("TranslateChunksWithOptions() = %q via %v, want %q", result.Translations, result.Steps, want)
+	}
+}
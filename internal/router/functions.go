@@ -0,0 +1,180 @@
+package router
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+)
+
+// FunctionsEnv names the environment variable mapping translators to
+// function names or ARNs as JSON (or FunctionsEnv+"_FILE" pointing to a
+// JSON file). Besides the built-in translators, it may name direct
+// translators of a language pair, e.g. "es-gl".
+const FunctionsEnv = "TRANSLATOR_FUNCTIONS"
+
+// DirectPairsEnv names the environment variable listing, as a JSON array
+// (or DirectPairsEnv+"_FILE"), the pairs with a direct translator under its
+// default name, e.g. ["es-it", "pt-es"] for "pricofy-translator-es-it".
+// Such pairs are translated in one step instead of pivoting through
+// English; FunctionsEnv may still map them to another function.
+const DirectPairsEnv = "DIRECT_PAIRS"
+
+// Built-in translators, one per language group direction.
+const (
+	TranslatorRomanceEn = "romance-en"
+	TranslatorEnRomance = "en-romance"
+	TranslatorDeEn      = "de-en"
+	TranslatorEnDe      = "en-de"
+	TranslatorSlavicEn  = "sla-en"
+	TranslatorEnSlavic  = "en-sla"
+	TranslatorNordicEn  = "gmq-en"
+	TranslatorEnNordic  = "en-gmq"
+)
+
+// defaultFunctionPrefix names the translator functions of a deployment
+// without TRANSLATOR_FUNCTIONS: "pricofy-translator-romance-en".
+const defaultFunctionPrefix = "pricofy-translator-"
+
+// envPlaceholder in a mapped function is replaced with ENVIRONMENT, so one
+// config serves every stage: "translator-romance-en-{env}".
+const envPlaceholder = "{env}"
+
+// FunctionPatternEnv names the environment variable holding the pattern of
+// the default function names, where {translator} is the translator (or
+// direct pair) and {env} is ENVIRONMENT, e.g.
+// "pricofy-translator-{translator}-{env}" so each stage invokes its own
+// copies. Unset, the default name is "pricofy-translator-{translator}".
+const FunctionPatternEnv = "TRANSLATOR_FUNCTION_PATTERN"
+
+// QualifierEnv names the environment variable holding the Lambda alias or
+// version invoked on default-named functions, e.g. "live" or "{env}".
+// Functions mapped in FunctionsEnv carry their own qualifier.
+const QualifierEnv = "TRANSLATOR_QUALIFIER"
+
+// translatorPlaceholder in FunctionPatternEnv is replaced with the
+// translator.
+const translatorPlaceholder = "{translator}"
+
+// qualifierPattern matches Lambda aliases and versions.
+var qualifierPattern = regexp.MustCompile(`^(\$LATEST|[A-Za-z0-9_-]{1,128})$`)
+
+// functionNaming derives the default function names of translators.
+type functionNaming struct {
+	pattern   string // with {env} resolved
+	qualifier string
+}
+
+// loadFunctionNaming reads TRANSLATOR_FUNCTION_PATTERN and
+// TRANSLATOR_QUALIFIER, resolving {env}.
+func loadFunctionNaming(env string) (functionNaming, error) {
+	n := functionNaming{
+		pattern:   strings.ReplaceAll(os.Getenv(FunctionPatternEnv), envPlaceholder, env),
+		qualifier: strings.ReplaceAll(os.Getenv(QualifierEnv), envPlaceholder, env),
+	}
+	if n.pattern != "" && !strings.Contains(n.pattern, translatorPlaceholder) {
+		return functionNaming{}, fmt.Errorf("invalid %s %q: want a %s placeholder", FunctionPatternEnv, n.pattern, translatorPlaceholder)
+	}
+	if n.qualifier != "" && !qualifierPattern.MatchString(n.qualifier) {
+		return functionNaming{}, fmt.Errorf("invalid %s %q: want a Lambda alias or version", QualifierEnv, n.qualifier)
+	}
+	return n, nil
+}
+
+// name returns the default function of a translator.
+func (n functionNaming) name(translator string) string {
+	name := defaultFunctionPrefix + translator
+	if n.pattern != "" {
+		name = strings.ReplaceAll(n.pattern, translatorPlaceholder, translator)
+	}
+	if n.qualifier != "" {
+		name += ":" + n.qualifier
+	}
+	return name
+}
+
+// loadFunctions reads the TRANSLATOR_FUNCTIONS config, resolving {env}.
+// Translators it leaves out keep their default function name, which
+// TRANSLATOR_FUNCTION_PATTERN and TRANSLATOR_QUALIFIER may change.
+func loadFunctions(env string) (map[string]string, error) {
+	naming, err := loadFunctionNaming(env)
+	if err != nil {
+		return nil, err
+	}
+	var functions map[string]string
+	if _, err := appconfig.LoadJSON(FunctionsEnv, &functions); err != nil {
+		return nil, err
+	}
+	for translator, function := range functions {
+		if !builtInTranslator(translator) && !directPair(translator) {
+			return nil, fmt.Errorf("invalid %s: unknown translator %q", FunctionsEnv, translator)
+		}
+		if strings.TrimSpace(function) == "" {
+			return nil, fmt.Errorf("invalid %s: empty function for %q", FunctionsEnv, translator)
+		}
+		functions[translator] = strings.ReplaceAll(function, envPlaceholder, env)
+	}
+	if naming != (functionNaming{}) {
+		if functions == nil {
+			functions = map[string]string{}
+		}
+		for _, translator := range builtInTranslators {
+			if _, ok := functions[translator]; !ok {
+				functions[translator] = naming.name(translator)
+			}
+		}
+	}
+	return addDirectPairs(functions, naming)
+}
+
+// addDirectPairs reads the DIRECT_PAIRS config and adds the default
+// function of every listed pair that functions does not map.
+func addDirectPairs(functions map[string]string, naming functionNaming) (map[string]string, error) {
+	var pairs []string
+	if _, err := appconfig.LoadJSON(DirectPairsEnv, &pairs); err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if !directPair(pair) {
+			return nil, fmt.Errorf("invalid %s: %q is not a pair of supported languages", DirectPairsEnv, pair)
+		}
+		if functions == nil {
+			functions = map[string]string{}
+		}
+		if _, ok := functions[pair]; !ok {
+			functions[pair] = naming.name(pair)
+		}
+	}
+	return functions, nil
+}
+
+// function returns the function name or ARN serving a built-in translator.
+func (r *Router) function(translator string) string {
+	if f, ok := r.functions[translator]; ok {
+		return f
+	}
+	return defaultFunctionPrefix + translator
+}
+
+// builtInTranslators lists the built-in translators.
+var builtInTranslators = []string{TranslatorRomanceEn, TranslatorEnRomance, TranslatorDeEn, TranslatorEnDe,
+	TranslatorSlavicEn, TranslatorEnSlavic, TranslatorNordicEn, TranslatorEnNordic}
+
+// builtInTranslator reports whether name is a built-in translator.
+func builtInTranslator(name string) bool {
+	for _, t := range builtInTranslators {
+		if name == t {
+			return true
+		}
+	}
+	return false
+}
+
+// directPair reports whether name is a "source-target" pair of distinct
+// supported languages, naming a direct translator.
+func directPair(name string) bool {
+	source, target, ok := strings.Cut(name, "-")
+	return ok && source != target && supportedLanguages[source] && supportedLanguages[target]
+}
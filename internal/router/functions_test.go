@@ -0,0 +1,162 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadFunctions(t *testing.T) {
+	t.Setenv(FunctionsEnv, `{
+		"romance-en": "arn:aws:lambda:eu-west-1:123456789012:function:mt-romance-en-{env}",
+		"en-de": "translator-en-de-{env}:live"
+	}`)
+
+	functions, err := loadFunctions("prod")
+	if err != nil {
+		t.Fatalf("loadFunctions() error = %v", err)
+	}
+	r := &Router{functions: functions}
+
+	tests := []struct {
+		source, target string
+		want           []string
+	}{
+		{"es", "en", []string{"arn:aws:lambda:eu-west-1:123456789012:function:mt-romance-en-prod"}},
+		{"en", "de", []string{"translator-en-de-prod:live"}},
+		{"en", "fr", []string{"pricofy-translator-en-romance"}},
+		{"es", "de", []string{"arn:aws:lambda:eu-west-1:123456789012:function:mt-romance-en-prod", "translator-en-de-prod:live"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"→"+tt.target, func(t *testing.T) {
+			route := r.getRoute(tt.source, tt.target)
+			if len(route) != len(tt.want) {
+				t.Fatalf("getRoute() = %v, want %d steps", route, len(tt.want))
+			}
+			for i, step := range route {
+				if step.lambdaName != tt.want[i] {
+					t.Errorf("step %d = %q, want %q", i, step.lambdaName, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFunctions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"unknown translator", `{"zh-en": "translator-zh-en"}`},
+		{"empty function", `{"de-en": " "}`},
+		{"malformed", `{"de-en": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FunctionsEnv, tt.config)
+			if _, err := loadFunctions("dev"); err == nil {
+				t.Error("loadFunctions() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLoadFunctions_Unset(t *testing.T) {
+	functions, err := loadFunctions("dev")
+	if err != nil || len(functions) != 0 {
+		t.Fatalf("loadFunctions() = %v, %v, want no mapping", functions, err)
+	}
+	if got := (&Router{functions: functions}).function(TranslatorDeEn); got != "pricofy-translator-de-en" {
+		t.Errorf("function() = %q, want the default name", got)
+	}
+}
+
+func TestLoadFunctions_DirectPairs(t *testing.T) {
+	t.Setenv(FunctionsEnv, `{"pt-es": "translator-pt-es-{env}"}`)
+	t.Setenv(DirectPairsEnv, `["es-it", "pt-es"]`)
+
+	functions, err := loadFunctions("prod")
+	if err != nil {
+		t.Fatalf("loadFunctions() error = %v", err)
+	}
+	r := &Router{functions: functions}
+
+	tests := []struct {
+		source, target string
+		want           []routeStep
+	}{
+		{"es", "it", []routeStep{{lambdaName: "pricofy-translator-es-it", targetLang: "it"}}},
+		{"pt", "es", []routeStep{{lambdaName: "translator-pt-es-prod", targetLang: "es"}}},
+		{"it", "es", []routeStep{
+			{lambdaName: "pricofy-translator-romance-en"},
+			{lambdaName: "pricofy-translator-en-romance", targetLang: "es"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"→"+tt.target, func(t *testing.T) {
+			if got := r.getRoute(tt.source, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRoute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFunctions_InvalidDirectPair(t *testing.T) {
+	for _, config := range []string{`["es-es"]`, `["es-zh"]`, `"es-it"`} {
+		t.Setenv(DirectPairsEnv, config)
+		if _, err := loadFunctions("dev"); err == nil {
+			t.Errorf("loadFunctions() with %s error = nil, want error", config)
+		}
+	}
+}
+
+func TestLoadFunctions_Naming(t *testing.T) {
+	t.Setenv(FunctionPatternEnv, "pricofy-translator-{translator}-{env}")
+	t.Setenv(QualifierEnv, "live")
+	t.Setenv(FunctionsEnv, `{"en-de": "translator-en-de-{env}"}`)
+	t.Setenv(DirectPairsEnv, `["es-it"]`)
+
+	functions, err := loadFunctions("dev")
+	if err != nil {
+		t.Fatalf("loadFunctions() error = %v", err)
+	}
+	r := &Router{functions: functions}
+
+	tests := []struct {
+		translator string
+		want       string
+	}{
+		{TranslatorRomanceEn, "pricofy-translator-romance-en-dev:live"},
+		{TranslatorEnNordic, "pricofy-translator-en-gmq-dev:live"},
+		{TranslatorEnDe, "translator-en-de-dev"},
+		{"es-it", "pricofy-translator-es-it-dev:live"},
+	}
+
+	for _, tt := range tests {
+		if got := r.Functions()[tt.translator]; got != tt.want {
+			t.Errorf("function of %s = %q, want %q", tt.translator, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFunctions_InvalidNaming(t *testing.T) {
+	tests := []struct {
+		name               string
+		pattern, qualifier string
+	}{
+		{"pattern without translator", "pricofy-translator-{env}", ""},
+		{"invalid qualifier", "", "live:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FunctionPatternEnv, tt.pattern)
+			t.Setenv(QualifierEnv, tt.qualifier)
+			if _, err := loadFunctions("dev"); err == nil {
+				t.Error("loadFunctions() error = nil, want error")
+			}
+		})
+	}
+}
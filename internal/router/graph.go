@@ -0,0 +1,168 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+)
+
+// RouteWeightsEnv names the environment variable holding the weight of
+// translators in route selection as JSON (or RouteWeightsEnv+"_FILE"
+// pointing to a JSON file): a RouteWeight per translator, named as in
+// TRANSLATOR_FUNCTIONS ("romance-en", "es-gl").
+const RouteWeightsEnv = "ROUTE_WEIGHTS"
+
+// RouteWeight makes a translator less attractive to route through. The
+// weight of a hop is 1 plus LatencyMs/1000 plus QualityPenalty, and routes
+// take the lightest path, so by default the one with fewest hops.
+type RouteWeight struct {
+	// LatencyMs is the typical latency of the translator.
+	LatencyMs float64 `json:"latencyMs,omitempty"`
+	// QualityPenalty is the quality lost in the hop, in hops: 1 makes a
+	// direct translator as heavy as a two-hop route.
+	QualityPenalty float64 `json:"qualityPenalty,omitempty"`
+}
+
+// weight is the weight of a hop through a translator with w.
+func (w RouteWeight) weight() float64 {
+	return 1 + w.LatencyMs/1000 + w.QualityPenalty
+}
+
+// loadRouteWeights reads the ROUTE_WEIGHTS config.
+func loadRouteWeights() (map[string]RouteWeight, error) {
+	var weights map[string]RouteWeight
+	if _, err := appconfig.LoadJSON(RouteWeightsEnv, &weights); err != nil {
+		return nil, err
+	}
+	for translator, w := range weights {
+		if !builtInTranslator(translator) && !directPair(translator) {
+			return nil, fmt.Errorf("invalid %s: unknown translator %q", RouteWeightsEnv, translator)
+		}
+		if w.LatencyMs < 0 || w.QualityPenalty < 0 {
+			return nil, fmt.Errorf("invalid %s: negative weight for %q", RouteWeightsEnv, translator)
+		}
+	}
+	return weights, nil
+}
+
+// edge is a translator hop into a language.
+type edge struct {
+	to     string
+	weight float64
+}
+
+// routeGraph is the directed graph of the translators available to a
+// router: languages joined by a hop (see hop). Shortest paths are computed
+// once per source language.
+type routeGraph struct {
+	// languages are the nodes, English first, then in code order, so
+	// English is preferred among equally light pivots.
+	languages []string
+	edges     map[string][]edge
+
+	mu sync.Mutex
+	// trees maps a source to the previous language on the lightest path
+	// to every language it reaches.
+	trees map[string]map[string]string
+}
+
+// graph returns the route graph of r, built on first use.
+func (r *Router) graph() *routeGraph {
+	r.graphOnce.Do(func() { r.routeGraph = r.buildGraph() })
+	return r.routeGraph
+}
+
+// buildGraph links every supported language with the translators to and
+// from English of its group and the direct translators of its pairs.
+func (r *Router) buildGraph() *routeGraph {
+	g := &routeGraph{edges: map[string][]edge{}, trees: map[string]map[string]string{}}
+	for lang := range supportedLanguages {
+		g.languages = append(g.languages, lang)
+	}
+	sort.Slice(g.languages, func(i, j int) bool {
+		a, b := g.languages[i], g.languages[j]
+		if (a == pivotLang) != (b == pivotLang) {
+			return a == pivotLang
+		}
+		return a < b
+	})
+
+	add := func(source, target, translator string) {
+		g.edges[source] = append(g.edges[source], edge{to: target, weight: r.weights[translator].weight()})
+	}
+	for _, lang := range g.languages {
+		if group := groupOf(lang); group != nil {
+			add(lang, pivotLang, group.toEnglish)
+			add(pivotLang, lang, group.fromEnglish)
+		}
+	}
+	for pair := range r.functions {
+		if builtInTranslator(pair) || !directPair(pair) {
+			continue
+		}
+		source, target, _ := strings.Cut(pair, "-")
+		add(source, target, pair)
+	}
+	return g
+}
+
+// path returns the languages of the lightest route from source to target,
+// both included, or nil when there is none within maxRouteSteps.
+func (g *routeGraph) path(source, target string) []string {
+	prev := g.tree(source)
+	if _, ok := prev[target]; !ok || source == target {
+		return nil
+	}
+	path := []string{target}
+	for lang := target; lang != source; {
+		lang = prev[lang]
+		path = append(path, lang)
+	}
+	if len(path)-1 > maxRouteSteps {
+		return nil
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// tree returns the lightest-path tree from source (Dijkstra). Among
+// equally light paths the one with fewer hops wins, then the one whose
+// languages come first in g.languages.
+func (g *routeGraph) tree(source string) map[string]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if prev, ok := g.trees[source]; ok {
+		return prev
+	}
+
+	prev := map[string]string{source: source}
+	dist := map[string]float64{source: 0}
+	hops := map[string]int{source: 0}
+	done := map[string]bool{}
+	for {
+		next, best := "", math.Inf(1)
+		for _, lang := range g.languages {
+			if d, ok := dist[lang]; ok && !done[lang] && (d < best || d == best && hops[lang] < hops[next]) {
+				next, best = lang, d
+			}
+		}
+		if next == "" {
+			break
+		}
+		done[next] = true
+		for _, e := range g.edges[next] {
+			d, h := best+e.weight, hops[next]+1
+			if old, ok := dist[e.to]; !ok || d < old || d == old && h < hops[e.to] {
+				dist[e.to], hops[e.to], prev[e.to] = d, h, next
+			}
+		}
+	}
+	g.trees[source] = prev
+	return prev
+}
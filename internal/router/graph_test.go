@@ -0,0 +1,95 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphPath(t *testing.T) {
+	tests := []struct {
+		name           string
+		functions      map[string]string
+		weights        map[string]RouteWeight
+		source, target string
+		want           []string
+	}{
+		{name: "to english", source: "es", target: "en", want: []string{"es", "en"}},
+		{name: "from english", source: "en", target: "pl", want: []string{"en", "pl"}},
+		{name: "across groups", source: "sv", target: "fr", want: []string{"sv", "en", "fr"}},
+		{name: "same language", source: "es", target: "es"},
+		{name: "unsupported", source: "es", target: "zh"},
+		{
+			name:      "direct pair over english",
+			functions: map[string]string{"es-it": "pricofy-translator-es-it"},
+			source:    "es", target: "it",
+			want: []string{"es", "it"},
+		},
+		{
+			name:      "english over equally light pivot",
+			functions: map[string]string{"ca-es": "translator-ca-es", "es-gl": "translator-es-gl"},
+			source:    "ca", target: "gl",
+			want: []string{"ca", "en", "gl"},
+		},
+		{
+			name:      "penalized direct pair",
+			functions: map[string]string{"es-it": "pricofy-translator-es-it"},
+			weights:   map[string]RouteWeight{"es-it": {QualityPenalty: 1.5}},
+			source:    "es", target: "it",
+			want: []string{"es", "en", "it"},
+		},
+		{
+			name:      "slow built-in translator",
+			functions: map[string]string{"ca-es": "translator-ca-es", "es-gl": "translator-es-gl"},
+			weights:   map[string]RouteWeight{"en-romance": {LatencyMs: 500}},
+			source:    "ca", target: "gl",
+			want: []string{"ca", "es", "gl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{functions: tt.functions, weights: tt.weights}
+			if got := r.graph().path(tt.source, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("path(%s, %s) = %q, want %q", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRoute_Weights(t *testing.T) {
+	r := &Router{
+		functions: map[string]string{"es-it": "pricofy-translator-es-it"},
+		weights:   map[string]RouteWeight{"es-it": {QualityPenalty: 1.5}},
+	}
+	want := []routeStep{{"pricofy-translator-romance-en", ""}, {"pricofy-translator-en-romance", "it"}}
+	if got := r.getRoute("es", "it"); !reflect.DeepEqual(got, want) {
+		t.Errorf("getRoute(es, it) = %v, want %v", got, want)
+	}
+	if got := r.getRoute("pt", "it"); len(got) != 2 {
+		t.Errorf("getRoute(pt, it) = %v, want a pivot through English", got)
+	}
+}
+
+func TestLoadRouteWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"built-in translator", `{"gmq-en": {"latencyMs": 800}}`, false},
+		{"direct pair", `{"es-it": {"qualityPenalty": 1.5}}`, false},
+		{"unknown translator", `{"es-zh": {"latencyMs": 100}}`, true},
+		{"negative weight", `{"es-it": {"qualityPenalty": -1}}`, true},
+		{"malformed", `{"es-it": 3}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RouteWeightsEnv, tt.config)
+			if _, err := loadRouteWeights(); (err != nil) != tt.wantErr {
+				t.Errorf("loadRouteWeights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
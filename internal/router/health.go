@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// Statuses of a probed translator function.
+const (
+	HealthOK      = "ok"
+	HealthFailed  = "failed"
+	HealthSkipped = "skipped"
+)
+
+// probeText is the one-word text every translator translates in a probe.
+const probeText = "hello"
+
+// probeTargets are the target languages of the probes of the multilingual
+// translators from English; direct translators get their pair's target.
+var probeTargets = map[string]string{
+	TranslatorEnRomance: "es",
+	TranslatorEnSlavic:  "pl",
+	TranslatorEnNordic:  "sv",
+}
+
+// TargetHealth is the outcome of probing the function of a translator.
+type TargetHealth struct {
+	Translator string `json:"translator"`
+	Function   string `json:"function"`
+	Status     string `json:"status"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Probe translates a one-word text with the function of every translator
+// (see Functions), all at once, and returns their health in translator
+// order. In the local environment, which has no translators to call, every
+// probe is skipped.
+func (r *Router) Probe(ctx context.Context) []TargetHealth {
+	functions := r.Functions()
+	translators := make([]string, 0, len(functions))
+	for translator := range functions {
+		translators = append(translators, translator)
+	}
+	sort.Strings(translators)
+
+	targets := make([]TargetHealth, len(translators))
+	_ = workpool.Pool{}.Run(ctx, len(translators), func(ctx context.Context, i int) error {
+		targets[i] = r.probe(ctx, translators[i], functions[translators[i]])
+		return nil
+	})
+	return targets
+}
+
+// probe translates probeText with the function of translator.
+func (r *Router) probe(ctx context.Context, translator, function string) TargetHealth {
+	health := TargetHealth{Translator: translator, Function: function, Status: HealthSkipped}
+	if r.dryRun {
+		return health
+	}
+
+	target := probeTargets[translator]
+	if !builtInTranslator(translator) {
+		_, target, _ = strings.Cut(translator, "-")
+	}
+	chunks := [][]string{{probeText}}
+	start := time.Now()
+	resp, err := r.invokeOnce(ctx, function, target, chunks, false)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err == nil {
+		err = checkShape(function, chunks, resp)
+	}
+	health.Status = HealthOK
+	if err != nil {
+		health.Status, health.Error = HealthFailed, err.Error()
+	}
+	return health
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// probedTranslator echoes its chunks, except for the down function, and
+// records the target language each function got.
+type probedTranslator struct {
+	down string
+
+	mu      sync.Mutex
+	targets map[string]string
+}
+
+func (t *probedTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	if *params.FunctionName == t.down {
+		return nil, errors.New("function not found")
+	}
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.targets[*params.FunctionName] = req.TargetLang
+	t.mu.Unlock()
+	payload, err := json.Marshal(TranslatorResponse{Translations: req.Chunks})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestProbe(t *testing.T) {
+	client := &probedTranslator{down: "pricofy-translator-de-en", targets: map[string]string{}}
+	r := &Router{lambdaClient: client, functions: map[string]string{"es-it": "translator-es-it"}}
+
+	targets := r.Probe(context.Background())
+	if len(targets) != len(builtInTranslators)+1 {
+		t.Fatalf("Probe() = %d targets, want %d", len(targets), len(builtInTranslators)+1)
+	}
+	for _, target := range targets {
+		wantStatus := HealthOK
+		if target.Function == client.down {
+			wantStatus = HealthFailed
+		}
+		if target.Status != wantStatus || (target.Error != "") != (wantStatus == HealthFailed) {
+			t.Errorf("%s health = %+v, want %s", target.Translator, target, wantStatus)
+		}
+	}
+	if targets[0].Translator != TranslatorDeEn {
+		t.Errorf("first target = %q, want the translators in order", targets[0].Translator)
+	}
+
+	wantTargets := map[string]string{
+		"pricofy-translator-romance-en": "",
+		"pricofy-translator-en-romance": "es",
+		"pricofy-translator-en-de":      "",
+		"pricofy-translator-sla-en":     "",
+		"pricofy-translator-en-sla":     "pl",
+		"pricofy-translator-gmq-en":     "",
+		"pricofy-translator-en-gmq":     "sv",
+		"translator-es-it":              "it",
+	}
+	if !reflect.DeepEqual(client.targets, wantTargets) {
+		t.Errorf("probe targets = %v, want %v", client.targets, wantTargets)
+	}
+}
+
+func TestProbe_Local(t *testing.T) {
+	client := &probedTranslator{targets: map[string]string{}}
+	r := &Router{lambdaClient: client, dryRun: true}
+
+	for _, target := range r.Probe(context.Background()) {
+		if target.Status != HealthSkipped {
+			t.Errorf("%s status = %q, want %s", target.Translator, target.Status, HealthSkipped)
+		}
+	}
+	if len(client.targets) != 0 {
+		t.Errorf("local probes invoked %v", client.targets)
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Hop is one step of a route, planned ahead so that an external
+// orchestrator (e.g. a Step Functions pipeline) can run each step as its
+// own invocation.
+type Hop struct {
+	// Function is the translator Lambda of the step, after overrides, or
+	// the backend translating the whole pair when it is not the Lambda
+	// fleet.
+	Function string `json:"function"`
+	// Backend is set for the single hop of a non-Lambda backend.
+	Backend string `json:"backend,omitempty"`
+	// SourceLang and TargetLang are the languages of the step.
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+	// Lang is the target language sent to the translator, set for the
+	// multilingual and direct translators that need one.
+	Lang string `json:"lang,omitempty"`
+}
+
+// Hops returns the route of source → target, chosen like
+// TranslateChunksWithOptions chooses it, as independent hops. Options other
+// than FunctionOverrides, Backend and Tag are ignored.
+func (r *Router) Hops(ctx context.Context, source, target string, opts Options) ([]Hop, error) {
+	name, route, _ := r.route(ctx, source, target, opts)
+	if name != BackendLambda {
+		if !r.CanTranslate(source, target, name) {
+			return nil, fmt.Errorf("unsupported language pair: %s-%s", source, target)
+		}
+		return []Hop{{Function: name, Backend: name, SourceLang: source, TargetLang: target}}, nil
+	}
+	if route == nil {
+		return nil, fmt.Errorf("unsupported language pair: %s-%s", source, target)
+	}
+	langs := []string{source, target}
+	if len(route) > 1 {
+		pivots, _ := r.pivots(source, target)
+		langs = append(append([]string{source}, pivots...), target)
+	}
+	hops := make([]Hop, len(route))
+	for i, step := range route {
+		hops[i] = Hop{
+			Function:   stepFunction(step, opts.FunctionOverrides),
+			SourceLang: langs[i],
+			TargetLang: langs[i+1],
+			Lang:       step.targetLang,
+		}
+	}
+	return hops, nil
+}
+
+// TranslateHop translates chunks with one hop of Hops, step of steps, as a
+// single-step Result. Failures are a *StepError.
+func (r *Router) TranslateHop(ctx context.Context, hop Hop, step, steps int, chunks [][]string) (*Result, error) {
+	if hop.Backend != "" {
+		return r.translateWith(ctx, hop.Backend, hop.SourceLang, hop.TargetLang, chunks, Options{}, nil)
+	}
+	resp, dispatch, elapsed, err := r.dispatch(ctx, hop.Function, hop.Lang, chunks, false)
+	if err == nil {
+		err = checkShape(hop.Function, chunks, resp)
+	}
+	if err != nil {
+		return nil, &StepError{Step: step, Steps: steps, Function: hop.Function, Err: err}
+	}
+	return &Result{
+		Translations:  resp.Translations,
+		Steps:         []string{hop.Function},
+		ModelVersions: []string{resp.ModelVersion},
+		Dispatches:    []string{dispatch},
+		Durations:     []time.Duration{elapsed},
+	}, nil
+}
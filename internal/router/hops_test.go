@@ -0,0 +1,78 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHops(t *testing.T) {
+	r := &Router{functions: map[string]string{"es-it": "translator-es-it"}}
+
+	tests := []struct {
+		name           string
+		source, target string
+		opts           Options
+		want           []Hop
+	}{
+		{
+			name: "pivot route", source: "es", target: "de",
+			want: []Hop{
+				{Function: "pricofy-translator-romance-en", SourceLang: "es", TargetLang: "en"},
+				{Function: "pricofy-translator-en-de", SourceLang: "en", TargetLang: "de"},
+			},
+		},
+		{
+			name: "multilingual translator with override", source: "en", target: "fr",
+			opts: Options{FunctionOverrides: map[string]string{"pricofy-translator-en-romance": "candidate-en-romance"}},
+			want: []Hop{{Function: "candidate-en-romance", SourceLang: "en", TargetLang: "fr", Lang: "fr"}},
+		},
+		{
+			name: "direct pair", source: "es", target: "it",
+			want: []Hop{{Function: "translator-es-it", SourceLang: "es", TargetLang: "it", Lang: "it"}},
+		},
+		{
+			name: "backend", source: "es", target: "de", opts: Options{Backend: BackendDryRun},
+			want: []Hop{{Function: BackendDryRun, Backend: BackendDryRun, SourceLang: "es", TargetLang: "de"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.backends = map[string]Translator{BackendDryRun: DryRun{}}
+			got, err := r.Hops(context.Background(), tt.source, tt.target, tt.opts)
+			if err != nil {
+				t.Fatalf("Hops() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hops() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := r.Hops(context.Background(), "es", "xx", Options{}); err == nil {
+		t.Error("Hops() of an unsupported pair error = nil, want an error")
+	}
+}
+
+func TestTranslateHop(t *testing.T) {
+	client := &recordingTranslator{}
+	r := &Router{lambdaClient: client}
+	hop := Hop{Function: "pricofy-translator-en-romance", SourceLang: "en", TargetLang: "fr", Lang: "fr"}
+
+	result, err := r.TranslateHop(context.Background(), hop, 2, 2, [][]string{{"hello"}})
+	if err != nil {
+		t.Fatalf("TranslateHop() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Translations, [][]string{{"hello"}}) || !reflect.DeepEqual(result.Steps, []string{hop.Function}) {
+		t.Errorf("TranslateHop() = %+v", result)
+	}
+
+	r = &Router{lambdaClient: &fixedTranslator{translations: [][]string{}}}
+	_, err = r.TranslateHop(context.Background(), hop, 2, 2, [][]string{{"hello"}})
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != 2 || stepErr.Function != hop.Function {
+		t.Errorf("TranslateHop() error = %v, want a StepError of step 2", err)
+	}
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+	"github.com/pricofy/translation-manager/internal/ratelimit"
+)
+
+// LimitsEnv names the environment variable holding the translator load
+// limits as JSON (or LimitsEnv+"_FILE" pointing to a JSON file): a
+// ratelimit.Limit per function name. Names are unqualified: a limit holds
+// for every alias and version of its function.
+const LimitsEnv = "TRANSLATOR_LIMITS"
+
+// The limiters are created once per Lambda container and shared by its
+// routers.
+var (
+	limitsOnce sync.Once
+	limits     *ratelimit.Set
+	limitsErr  error
+)
+
+// translatorLimits returns the container-wide limiters of the
+// TRANSLATOR_LIMITS config.
+func translatorLimits(env string) (*ratelimit.Set, error) {
+	limitsOnce.Do(func() {
+		var config map[string]ratelimit.Limit
+		config, limitsErr = loadLimits(env)
+		if limitsErr == nil {
+			limits = ratelimit.NewSet(config)
+		}
+	})
+	return limits, limitsErr
+}
+
+// loadLimits reads the TRANSLATOR_LIMITS config, resolving {env} in
+// function names and dropping any qualifier.
+func loadLimits(env string) (map[string]ratelimit.Limit, error) {
+	var config map[string]ratelimit.Limit
+	if _, err := appconfig.LoadJSON(LimitsEnv, &config); err != nil {
+		return nil, err
+	}
+	limits := make(map[string]ratelimit.Limit, len(config))
+	for function, l := range config {
+		if err := l.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s for %q: %w", LimitsEnv, function, err)
+		}
+		limits[unqualified(strings.ReplaceAll(function, envPlaceholder, env))] = l
+	}
+	return limits, nil
+}
+
+// unqualified returns a function name or ARN without its alias or version,
+// e.g. "translator-es-en" for "translator-es-en:live".
+func unqualified(functionName string) string {
+	if strings.HasPrefix(functionName, "arn:") {
+		// arn:aws:lambda:region:account:function:name[:qualifier]
+		if parts := strings.Split(functionName, ":"); len(parts) > 7 {
+			return strings.Join(parts[:7], ":")
+		}
+		return functionName
+	}
+	name, _, _ := strings.Cut(functionName, ":")
+	return name
+}
+
+// acquire waits until functionName may be invoked under its limit. The
+// returned function ends the invocation.
+func (r *Router) acquire(ctx context.Context, functionName string) (func(), error) {
+	release, err := r.limits.Acquire(ctx, unqualified(functionName))
+	if err != nil {
+		return nil, fmt.Errorf("invocation of %s shed: %w", functionName, err)
+	}
+	return release, nil
+}
+
+// retryToken waits for the rate token of a retry of functionName, which
+// still holds the concurrency slot of its first attempt.
+func (r *Router) retryToken(ctx context.Context, functionName string) error {
+	if err := r.limits.Wait(ctx, unqualified(functionName)); err != nil {
+		return fmt.Errorf("retry of %s shed: %w", functionName, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/pricofy/translation-manager/internal/ratelimit"
+)
+
+func TestLoadLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]ratelimit.Limit
+		wantErr bool
+	}{
+		{name: "unset", want: map[string]ratelimit.Limit{}},
+		{
+			name:   "env placeholder",
+			config: `{"translator-romance-en-{env}": {"maxConcurrent": 20, "rate": 50, "burst": 10, "maxWaitMs": 2000}}`,
+			want:   map[string]ratelimit.Limit{"translator-romance-en-prod": {MaxConcurrent: 20, Rate: 50, Burst: 10, MaxWaitMs: 2000}},
+		},
+		{
+			name:   "qualified name",
+			config: `{"translator-romance-en-{env}:live": {"maxConcurrent": 20}}`,
+			want:   map[string]ratelimit.Limit{"translator-romance-en-prod": {MaxConcurrent: 20}},
+		},
+		{name: "negative", config: `{"translator": {"maxConcurrent": -1}}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(LimitsEnv, tt.config)
+			got, err := loadLimits("prod")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvokeLambda_Shed(t *testing.T) {
+	function := "translator-romance-en-test"
+	limits := ratelimit.NewSet(map[string]ratelimit.Limit{function: {MaxConcurrent: 1, MaxWaitMs: 1}})
+	held, err := limits.Acquire(context.Background(), function)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held()
+
+	r := &Router{limits: limits}
+	_, err = r.invokeLambda(context.Background(), function, "", [][]string{{"hola"}}, false)
+	if !errors.Is(err, ratelimit.ErrShed) {
+		t.Errorf("invokeLambda() over the limit error = %v, want ErrShed", err)
+	}
+}
+
+func TestUnqualified(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "translator-es-en", want: "translator-es-en"},
+		{name: "translator-es-en:live", want: "translator-es-en"},
+		{name: "translator-es-en:7", want: "translator-es-en"},
+		{name: "arn:aws:lambda:eu-west-1:123456789012:function:translator-es-en", want: "arn:aws:lambda:eu-west-1:123456789012:function:translator-es-en"},
+		{name: "arn:aws:lambda:eu-west-1:123456789012:function:translator-es-en:live", want: "arn:aws:lambda:eu-west-1:123456789012:function:translator-es-en"},
+	}
+
+	for _, tt := range tests {
+		if got := unqualified(tt.name); got != tt.want {
+			t.Errorf("unqualified(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInvokeLambda_ShedQualified(t *testing.T) {
+	function := "translator-romance-en-test"
+	limits := ratelimit.NewSet(map[string]ratelimit.Limit{function: {MaxConcurrent: 1, MaxWaitMs: 1}})
+	held, err := limits.Acquire(context.Background(), function)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held()
+
+	r := &Router{limits: limits}
+	_, err = r.invokeLambda(context.Background(), function+":live", "", [][]string{{"hola"}}, false)
+	if !errors.Is(err, ratelimit.ErrShed) {
+		t.Errorf("invokeLambda() of an alias over the limit error = %v, want ErrShed", err)
+	}
+}
+
+func TestInvokeLambda_RetryTakesToken(t *testing.T) {
+	function := "translator-romance-en-test"
+	client := &flakyTranslator{errs: []error{&types.TooManyRequestsException{}}}
+	r := &Router{
+		lambdaClient: client,
+		limits:       ratelimit.NewSet(map[string]ratelimit.Limit{function: {Rate: 1, Burst: 1, MaxWaitMs: 1}}),
+		retry:        retryPolicy{attempts: 3, baseDelay: time.Millisecond, maxDelay: time.Millisecond},
+	}
+
+	// The first attempt takes the only token; the retry finds none
+	_, err := r.invokeLambda(context.Background(), function, "", [][]string{{"hola"}}, false)
+	if !errors.Is(err, ratelimit.ErrShed) {
+		t.Errorf("invokeLambda() retrying over the rate error = %v, want ErrShed", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("invocations = %d, want 1", client.calls)
+	}
+}
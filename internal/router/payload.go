@@ -0,0 +1,140 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Translator payload formats, selected with TRANSLATOR_PAYLOAD_FORMAT.
+//
+// JSON marshaling of large chunk batches dominates the manager's CPU. With
+// FormatMsgpack, translators that advertise msgpack in their response
+// "formats" are sent msgpack from then on. Lambda only accepts JSON
+// payloads, so binary payloads travel in an envelope:
+//
+//	{"format": "msgpack", "payload": "<base64 msgpack>"}
+//
+// Translators answer in the format they were called with.
+const (
+	PayloadFormatEnv = "TRANSLATOR_PAYLOAD_FORMAT"
+	FormatJSON       = "json"
+	FormatMsgpack    = "msgpack"
+)
+
+// negotiated records the translator functions known to accept msgpack.
+// It is shared by all routers in the container.
+var negotiated sync.Map
+
+// envelope carries a binary payload through Lambda.
+type envelope struct {
+	Format  string `json:"format"`
+	Payload []byte `json:"payload"`
+}
+
+// wireResponse decodes either a plain JSON response or an envelope in one pass.
+type wireResponse struct {
+	TranslatorResponse
+	Format  string `json:"format,omitempty"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// payloadFormat reads PayloadFormatEnv.
+func payloadFormat() (string, error) {
+	switch v := os.Getenv(PayloadFormatEnv); v {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatMsgpack:
+		return FormatMsgpack, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q: want %s or %s", PayloadFormatEnv, v, FormatJSON, FormatMsgpack)
+	}
+}
+
+// encodeRequest marshals req for functionName: msgpack when preferred and the
+// translator has advertised support, JSON otherwise.
+func (r *Router) encodeRequest(functionName string, req TranslatorRequest) ([]byte, error) {
+	if r.payloadFormat != FormatMsgpack {
+		return json.Marshal(req)
+	}
+	if _, ok := negotiated.Load(functionName); !ok {
+		return json.Marshal(req)
+	}
+	return encodeMsgpack(req, payloadSize(req.Chunks))
+}
+
+// payloadSize estimates the encoded size of chunks: their text plus a few
+// bytes of framing per string.
+func payloadSize(chunks [][]string) int {
+	n := 64
+	for _, chunk := range chunks {
+		for _, text := range chunk {
+			n += len(text) + 5
+		}
+	}
+	return n
+}
+
+// envelopeHead and envelopeTail surround the base64 payload of an envelope.
+const (
+	envelopeHead = `{"format":"` + FormatMsgpack + `","payload":"`
+	envelopeTail = `"}`
+)
+
+// encodeMsgpack marshals v as msgpack (with its JSON field names) in an
+// envelope. The envelope is written directly, as json.Marshal would copy
+// the payload twice.
+func encodeMsgpack(v any, sizeHint int) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(sizeHint)
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(envelopeHead)+base64.StdEncoding.EncodedLen(buf.Len())+len(envelopeTail))
+	n := copy(out, envelopeHead)
+	base64.StdEncoding.Encode(out[n:], buf.Bytes())
+	copy(out[len(out)-len(envelopeTail):], envelopeTail)
+	return out, nil
+}
+
+// decodeResponse unmarshals a translator payload in either format.
+func decodeResponse(payload []byte) (*TranslatorResponse, error) {
+	var wire wireResponse
+	if err := json.Unmarshal(payload, &wire); err != nil {
+		return nil, err
+	}
+
+	switch wire.Format {
+	case "", FormatJSON:
+		return &wire.TranslatorResponse, nil
+	case FormatMsgpack:
+		var resp TranslatorResponse
+		dec := msgpack.NewDecoder(bytes.NewReader(wire.Payload))
+		dec.SetCustomStructTag("json")
+		if err := dec.Decode(&resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	default:
+		return nil, fmt.Errorf("unknown payload format %q", wire.Format)
+	}
+}
+
+// recordFormats remembers that functionName accepts msgpack when it says so.
+func recordFormats(functionName string, formats []string) {
+	for _, f := range formats {
+		if f == FormatMsgpack {
+			negotiated.Store(functionName, true)
+			return
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// largeBatch returns chunks like a big catalog import: 20 chunks of 50
+// product descriptions with accents and quotes that JSON must escape.
+func largeBatch() [][]string {
+	chunks := make([][]string, 20)
+	for i := range chunks {
+		chunks[i] = make([]string, 50)
+		for j := range chunks[i] {
+			chunks[i][j] = fmt.Sprintf(`Zapatillas "Runner" talla %d, en perfecto estado — envío incluido. %s`,
+				j, strings.Repeat("Descripción ", 10))
+		}
+	}
+	return chunks
+}
+
+func TestPayloadFormat(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"", FormatJSON, false},
+		{"json", FormatJSON, false},
+		{"msgpack", FormatMsgpack, false},
+		{"protobuf", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(PayloadFormatEnv, tt.env)
+		got, err := payloadFormat()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("payloadFormat() with %q = %q, %v", tt.env, got, err)
+		}
+	}
+}
+
+func TestEncodeRequest_Negotiation(t *testing.T) {
+	const fn = "pricofy-translator-test-negotiation"
+	req := TranslatorRequest{Chunks: [][]string{{"hola"}}, TargetLang: "fr"}
+
+	tests := []struct {
+		name        string
+		format      string
+		advertised  bool
+		wantMsgpack bool
+	}{
+		{"json preferred", FormatJSON, true, false},
+		{"msgpack not yet advertised", FormatMsgpack, false, false},
+		{"msgpack advertised", FormatMsgpack, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			negotiated.Delete(fn)
+			if tt.advertised {
+				recordFormats(fn, []string{FormatMsgpack})
+			}
+
+			payload, err := (&Router{payloadFormat: tt.format}).encodeRequest(fn, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var env envelope
+			if err := json.Unmarshal(payload, &env); err != nil {
+				t.Fatal(err)
+			}
+			if (env.Format == FormatMsgpack) != tt.wantMsgpack {
+				t.Errorf("payload %s, want msgpack %v", payload, tt.wantMsgpack)
+			}
+		})
+	}
+	negotiated.Delete(fn)
+}
+
+func TestDecodeResponse(t *testing.T) {
+	want := TranslatorResponse{
+		Translations: [][]string{{"Bonjour"}},
+		Scores:       [][]float64{{-0.2}},
+		ModelVersion: "opus-mt-2024-03",
+		Formats:      []string{FormatMsgpack},
+	}
+
+	jsonPayload, _ := json.Marshal(want)
+	msgpackPayload, err := encodeMsgpack(want, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, payload := range map[string][]byte{"json": jsonPayload, "msgpack": msgpackPayload} {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeResponse(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Translations[0][0] != "Bonjour" || got.Scores[0][0] != -0.2 || got.ModelVersion != want.ModelVersion || len(got.Formats) != 1 {
+				t.Errorf("decodeResponse() = %+v", got)
+			}
+		})
+	}
+
+	if _, err := decodeResponse([]byte(`{"format": "protobuf", "payload": ""}`)); err == nil {
+		t.Error("decodeResponse() should reject unknown formats")
+	}
+}
+
+func TestMsgpackUsesJSONFieldNames(t *testing.T) {
+	payload, err := encodeMsgpack(TranslatorRequest{Chunks: [][]string{{"a"}}, TargetLang: "fr"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]any
+	if err := msgpack.Unmarshal(env.Payload, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["target_lang"]; !ok {
+		t.Errorf("msgpack fields = %v, want translator field names", fields)
+	}
+}
+
+func BenchmarkEncodeRequest_JSON(b *testing.B) {
+	req := TranslatorRequest{Chunks: largeBatch(), TargetLang: "fr"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeRequest_Msgpack(b *testing.B) {
+	req := TranslatorRequest{Chunks: largeBatch(), TargetLang: "fr"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := encodeMsgpack(req, payloadSize(req.Chunks)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeResponse_JSON(b *testing.B) {
+	payload, _ := json.Marshal(TranslatorResponse{Translations: largeBatch()})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := decodeResponse(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeResponse_Msgpack(b *testing.B) {
+	payload, _ := encodeMsgpack(TranslatorResponse{Translations: largeBatch()}, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := decodeResponse(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
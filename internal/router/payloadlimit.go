@@ -0,0 +1,96 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// Lambda payload limits.
+const (
+	// maxSyncPayload caps the request and the response of a synchronous
+	// invocation.
+	maxSyncPayload = 6 << 20
+	// maxEventPayload caps the request of an Event invocation; its
+	// response goes to S3.
+	maxEventPayload = 256 << 10
+)
+
+// responseGrowth is how much larger than its texts a response is expected
+// to be: translations may be longer than their source, and are escaped.
+const responseGrowth = 2
+
+// PayloadError reports an invocation whose request or response exceeds the
+// Lambda payload limit.
+type PayloadError struct {
+	Function string
+	// Size is the encoded request size, or 0 when the limit was reported
+	// by Lambda.
+	Size  int
+	Limit int
+}
+
+func (e *PayloadError) Error() string {
+	if e.Size == 0 {
+		return fmt.Sprintf("payload of %s exceeds the Lambda limit", e.Function)
+	}
+	return fmt.Sprintf("payload of %s is %d bytes, over the %d byte Lambda limit", e.Function, e.Size, e.Limit)
+}
+
+// checkPayload returns a *PayloadError when payload is over limit.
+func checkPayload(functionName string, payload []byte, limit int) error {
+	if len(payload) > limit {
+		return &PayloadError{Function: functionName, Size: len(payload), Limit: limit}
+	}
+	return nil
+}
+
+// payloadExceeded reports whether err is Lambda refusing a request or a
+// response over its payload limit.
+func payloadExceeded(functionName string, err error, responsePayload []byte) error {
+	var tooLarge *types.RequestTooLargeException
+	if errors.As(err, &tooLarge) || bytes.Contains(responsePayload, []byte("ResponseSizeTooLarge")) {
+		return &PayloadError{Function: functionName}
+	}
+	return nil
+}
+
+// oversized reports whether the response to chunks is expected to exceed
+// the synchronous payload limit. Event invocations respond through S3.
+func (r *Router) oversized(chunks [][]string) bool {
+	return r.results == nil && payloadSize(chunks)*responseGrowth > maxSyncPayload
+}
+
+// invokeSplit invokes functionName with both halves of chunks in parallel,
+// each split further if still too large, and joins the responses in chunk
+// order. Scores are kept only if both halves have them.
+func (r *Router) invokeSplit(ctx context.Context, functionName, targetLang string, chunks [][]string, returnScores bool) (*TranslatorResponse, error) {
+	half := len(chunks) / 2
+	parts := [][][]string{chunks[:half], chunks[half:]}
+	responses := make([]*TranslatorResponse, len(parts))
+	err := workpool.Pool{Limit: len(parts)}.Run(ctx, len(parts), func(ctx context.Context, i int) error {
+		var err error
+		responses[i], err = r.invokeLambda(ctx, functionName, targetLang, parts[i], returnScores)
+		return err
+	})
+	if first := workpool.First(err); first != nil {
+		return nil, first.Err
+	}
+
+	joined := &TranslatorResponse{ModelVersion: responses[0].ModelVersion, Formats: responses[0].Formats}
+	for i, resp := range responses {
+		if len(resp.Translations) != len(parts[i]) {
+			return nil, &ShapeError{Function: functionName, Chunk: -1, Want: len(parts[i]), Got: len(resp.Translations)}
+		}
+		joined.Translations = append(joined.Translations, resp.Translations...)
+	}
+	if len(responses[0].Scores) == half && len(responses[1].Scores) == len(chunks)-half {
+		joined.Scores = append(responses[0].Scores, responses[1].Scores...)
+	}
+	return joined, nil
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// sizedTranslator echoes its chunks, failing like Lambda when a call has
+// more than maxChunks chunks, and records the chunk count of every call.
+type sizedTranslator struct {
+	maxChunks int
+	// requestError reports the limit as a refused request rather than as
+	// a function error.
+	requestError bool
+
+	mu    sync.Mutex
+	calls []int
+}
+
+func (t *sizedTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.calls = append(t.calls, len(req.Chunks))
+	t.mu.Unlock()
+
+	if t.maxChunks > 0 && len(req.Chunks) > t.maxChunks {
+		if t.requestError {
+			return nil, &types.RequestTooLargeException{}
+		}
+		unhandled := "Unhandled"
+		payload := []byte(`{"errorMessage":"Response payload size exceeded maximum allowed payload size (6291556 bytes).","errorType":"Function.ResponseSizeTooLarge"}`)
+		return &lambda.InvokeOutput{FunctionError: &unhandled, Payload: payload}, nil
+	}
+	payload, err := json.Marshal(TranslatorResponse{Translations: req.Chunks, Scores: scoresFor(req.Chunks)})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func scoresFor(chunks [][]string) [][]float64 {
+	scores := make([][]float64, len(chunks))
+	for i, chunk := range chunks {
+		scores[i] = make([]float64, len(chunk))
+	}
+	return scores
+}
+
+func numberedChunks(n, textSize int) [][]string {
+	chunks := make([][]string, n)
+	for i := range chunks {
+		chunks[i] = []string{fmt.Sprintf("%d%s", i, strings.Repeat("a", textSize))}
+	}
+	return chunks
+}
+
+func TestInvokeLambda_PayloadSplit(t *testing.T) {
+	tests := []struct {
+		name       string
+		translator *sizedTranslator
+		chunks     [][]string
+		wantCalls  int
+	}{
+		{name: "fits", translator: &sizedTranslator{}, chunks: numberedChunks(8, 10), wantCalls: 1},
+		{name: "expected response too large", translator: &sizedTranslator{}, chunks: numberedChunks(8, 512<<10), wantCalls: 2},
+		{name: "request refused", translator: &sizedTranslator{maxChunks: 2, requestError: true}, chunks: numberedChunks(8, 10), wantCalls: 7},
+		{name: "response too large", translator: &sizedTranslator{maxChunks: 3}, chunks: numberedChunks(6, 10), wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{lambdaClient: tt.translator}
+			resp, err := r.invokeLambda(context.Background(), "test-payload-split", "", tt.chunks, true)
+			if err != nil {
+				t.Fatalf("invokeLambda() error = %v", err)
+			}
+			if !reflect.DeepEqual(resp.Translations, tt.chunks) {
+				t.Error("invokeLambda() translations are not in chunk order")
+			}
+			if len(resp.Scores) != len(tt.chunks) {
+				t.Errorf("invokeLambda() scores = %d chunks, want %d", len(resp.Scores), len(tt.chunks))
+			}
+			if len(tt.translator.calls) != tt.wantCalls {
+				t.Errorf("invocations = %v, want %d", tt.translator.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestInvokeLambda_PayloadTooLarge(t *testing.T) {
+	r := &Router{lambdaClient: &sizedTranslator{}}
+	_, err := r.invokeLambda(context.Background(), "test-payload-chunk", "", [][]string{{strings.Repeat("a", maxSyncPayload)}}, false)
+	var tooLarge *PayloadError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != maxSyncPayload {
+		t.Errorf("invokeLambda() with a chunk over the limit error = %v, want a *PayloadError", err)
+	}
+}
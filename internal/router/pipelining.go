@@ -0,0 +1,185 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/workpool"
+)
+
+// PipelinedRoutesEnv names the environment variable that, set to "true",
+// pipelines chunks through the steps of multi-step routes instead of
+// running the steps one after the other over all chunks.
+const PipelinedRoutesEnv = "PIPELINED_ROUTES"
+
+// pipelinedRoutes reports whether PIPELINED_ROUTES turns pipelining on.
+func pipelinedRoutes() bool {
+	return os.Getenv(PipelinedRoutesEnv) == "true"
+}
+
+// pipelines reports whether chunks flow through route one by one: a chunk
+// moves on to the next step as soon as it is translated, while the next
+// chunks are still in earlier steps, so later steps do not sit idle until
+// the slowest chunk of the first one is done. It costs an invocation per
+// chunk and step, so it is only used with PIPELINED_ROUTES.
+func (r *Router) pipelines(route []routeStep, chunks [][]string) bool {
+	return len(route) > 1 && len(chunks) > 1 && r.pipelinedRoutes
+}
+
+// pipeline holds the result of a pipelined route as chunks complete.
+type pipeline struct {
+	mu     sync.Mutex
+	result *Result
+	// starts and ends bound the invocations of each step, whose duration is
+	// the span between its first start and its last end.
+	starts, ends []time.Time
+}
+
+// newPipeline returns an empty pipeline for chunks through route.
+func newPipeline(route []routeStep, chunks [][]string, opts Options) *pipeline {
+	p := &pipeline{
+		result: &Result{
+			Translations:  make([][]string, len(chunks)),
+			Intermediate:  make([][][]string, len(route)-1),
+			Steps:         make([]string, len(route)),
+			ModelVersions: make([]string, len(route)),
+			Dispatches:    make([]string, len(route)),
+			Durations:     make([]time.Duration, len(route)),
+		},
+		starts: make([]time.Time, len(route)),
+		ends:   make([]time.Time, len(route)),
+	}
+	for i, step := range route {
+		p.result.Steps[i] = stepFunction(step, opts.FunctionOverrides)
+		p.result.Dispatches[i] = DispatchPipelined
+	}
+	for i := range p.result.Intermediate {
+		p.result.Intermediate[i] = make([][]string, len(chunks))
+	}
+	if opts.ReturnScores {
+		p.result.Scores = make([][]float64, len(chunks))
+	}
+	return p
+}
+
+// translatePipelined runs every chunk through all the steps of route on its
+// own, up to fanOutConcurrency chunks at a time. Each invocation gets its
+// chunk's share of the time left over the remaining steps. With
+// Options.DeadLetter a failed chunk does not stop the others.
+func (r *Router) translatePipelined(ctx context.Context, route []routeStep, chunks [][]string, opts Options) (*Result, error) {
+	p := newPipeline(route, chunks, opts)
+	err := workpool.Pool{Limit: fanOutConcurrency, FailFast: !opts.DeadLetter}.Run(ctx, len(chunks), func(ctx context.Context, c int) error {
+		return r.pipelineChunk(ctx, p, route, c, chunks[c], opts)
+	})
+	if opts.DeadLetter {
+		err = p.deadLetter(chunks, workpool.Failures(err))
+	}
+	if first := workpool.First(err); first != nil {
+		return nil, first.Err
+	}
+	for i := range route {
+		p.result.Durations[i] = p.ends[i].Sub(p.starts[i])
+	}
+	return p.result, nil
+}
+
+// translateDeadLettered runs a route that is not pipelined the usual way
+// under Options.DeadLetter. Only when a step fails does it run the route
+// again chunk by chunk, to tell the failed chunks apart from the others.
+func (r *Router) translateDeadLettered(ctx context.Context, route []routeStep, chunks [][]string, opts Options) (*Result, error) {
+	result, err := r.translateStaged(ctx, route, chunks, opts)
+	if err == nil || len(chunks) == 1 || ctx.Err() != nil {
+		return result, err
+	}
+	return r.translatePipelined(ctx, route, chunks, opts)
+}
+
+// pipelineChunk translates chunk c through route.
+func (r *Router) pipelineChunk(ctx context.Context, p *pipeline, route []routeStep, c int, chunk []string, opts Options) error {
+	current := [][]string{chunk}
+	var scores [][]float64
+	for i, step := range route {
+		function := p.result.Steps[i]
+		stepCtx, budget, cancel := stepBudget(ctx, len(route)-i)
+		start := time.Now()
+		resp, _, _, err := r.dispatch(stepCtx, function, step.targetLang, current, opts.ReturnScores)
+		err = stepTimeout(stepCtx, err, i+1, len(route), function, budget)
+		cancel()
+		if err == nil {
+			err = checkShape(function, current, resp)
+		}
+		if err != nil {
+			return &StepError{Step: i + 1, Steps: len(route), Function: function, Err: fmt.Errorf("chunk %d: %w", c, err)}
+		}
+		if opts.ReturnScores {
+			scores = addScores(scores, resp.Scores, i == 0)
+		}
+		current = resp.Translations
+		p.record(i, c, resp, start)
+	}
+	p.finish(c, current[0], scores)
+	opts.progress(1)
+	return nil
+}
+
+// record notes the response of step for chunk c.
+func (p *pipeline) record(step, c int, resp *TranslatorResponse, start time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.starts[step].IsZero() || start.Before(p.starts[step]) {
+		p.starts[step] = start
+	}
+	if end := time.Now(); end.After(p.ends[step]) {
+		p.ends[step] = end
+	}
+	if p.result.ModelVersions[step] == "" {
+		p.result.ModelVersions[step] = resp.ModelVersion
+	}
+	if step < len(p.result.Intermediate) {
+		p.result.Intermediate[step][c] = resp.Translations[0]
+	}
+}
+
+// finish stores the translations of chunk c and their route scores. A
+// chunk without scores makes the whole route unscored.
+func (p *pipeline) finish(c int, translations []string, scores [][]float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.result.Translations[c] = translations
+	if p.result.Scores == nil {
+		return
+	}
+	if scores == nil {
+		p.result.Scores = nil
+		return
+	}
+	p.result.Scores[c] = scores[0]
+}
+
+// ChunkFailure is a chunk that failed its route under Options.DeadLetter.
+// Err is a *StepError naming the failed step unless the chunk panicked.
+type ChunkFailure struct {
+	Chunk int
+	Err   error
+}
+
+// deadLetter records the failed chunks in the result with "" translations,
+// keeping its shape, and drops the scores of a route missing some. It
+// returns the failures as they are when every chunk failed.
+func (p *pipeline) deadLetter(chunks [][]string, failures []*workpool.TaskError) error {
+	if len(failures) == len(chunks) {
+		return failures[0]
+	}
+	for _, f := range failures {
+		p.result.Translations[f.Index] = make([]string, len(chunks[f.Index]))
+		for _, texts := range p.result.Intermediate {
+			texts[f.Index] = make([]string, len(chunks[f.Index]))
+		}
+		p.result.Scores = nil
+		p.result.Failed = append(p.result.Failed, ChunkFailure{Chunk: f.Index, Err: f.Err})
+	}
+	return nil
+}
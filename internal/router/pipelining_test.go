@@ -0,0 +1,264 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// pipeTranslator prefixes texts with the step translating them and scores
+// each 1. The first step holds the chunk of "last" until the second step
+// has translated another chunk, which only a pipelined route does.
+type pipeTranslator struct {
+	mu       sync.Mutex
+	calls    []string
+	second   chan struct{}
+	once     sync.Once
+	fail     string
+	noScores bool
+}
+
+func (t *pipeTranslator) Invoke(ctx context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	step := strings.TrimPrefix(*params.FunctionName, "pricofy-translator-")
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.calls = append(t.calls, step+":"+req.Chunks[0][0])
+	t.mu.Unlock()
+
+	if step == "en-romance" {
+		t.once.Do(func() { close(t.second) })
+	}
+	if step == "romance-en" && req.Chunks[0][0] == "last" {
+		select {
+		case <-t.second:
+		case <-time.After(time.Second):
+			return nil, errors.New("second step did not start before the first one finished")
+		}
+	}
+	if req.Chunks[0][0] == t.fail {
+		payload, _ := json.Marshal(TranslatorResponse{Error: "model crashed"})
+		return &lambda.InvokeOutput{Payload: payload, FunctionError: &step}, nil
+	}
+
+	resp := TranslatorResponse{ModelVersion: step + "-v1"}
+	for _, chunk := range req.Chunks {
+		var out []string
+		var scores []float64
+		for _, text := range chunk {
+			out = append(out, step+":"+text)
+			scores = append(scores, 1)
+		}
+		resp.Translations = append(resp.Translations, out)
+		if !t.noScores {
+			resp.Scores = append(resp.Scores, scores)
+		}
+	}
+	payload, err := json.Marshal(resp)
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestTranslateChunks_Pipelined(t *testing.T) {
+	tr := &pipeTranslator{second: make(chan struct{})}
+	r := &Router{lambdaClient: tr, pipelinedRoutes: true}
+	chunks := [][]string{{"hola", "adiós"}, {"gracias"}, {"last"}}
+	var done atomic.Int32
+	progress := func(chunks int) { done.Add(int32(chunks)) }
+
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", chunks, Options{ReturnScores: true, Progress: progress})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	want := [][]string{
+		{"en-romance:romance-en:hola", "en-romance:romance-en:adiós"},
+		{"en-romance:romance-en:gracias"},
+		{"en-romance:romance-en:last"},
+	}
+	if !reflect.DeepEqual(result.Translations, want) {
+		t.Errorf("Translations = %q, want %q", result.Translations, want)
+	}
+	wantIntermediate := [][][]string{{{"romance-en:hola", "romance-en:adiós"}, {"romance-en:gracias"}, {"romance-en:last"}}}
+	if !reflect.DeepEqual(result.Intermediate, wantIntermediate) {
+		t.Errorf("Intermediate = %q, want %q", result.Intermediate, wantIntermediate)
+	}
+	if !reflect.DeepEqual(result.Scores, [][]float64{{2, 2}, {2}, {2}}) {
+		t.Errorf("Scores = %v, want 2 per text", result.Scores)
+	}
+	if !reflect.DeepEqual(result.Dispatches, []string{DispatchPipelined, DispatchPipelined}) {
+		t.Errorf("Dispatches = %v, want pipelined steps", result.Dispatches)
+	}
+	if !reflect.DeepEqual(result.ModelVersions, []string{"romance-en-v1", "en-romance-v1"}) {
+		t.Errorf("ModelVersions = %v", result.ModelVersions)
+	}
+	if result.PivotLang != "en" || len(result.Durations) != 2 {
+		t.Errorf("PivotLang = %q, Durations = %v", result.PivotLang, result.Durations)
+	}
+	if done.Load() != 3 {
+		t.Errorf("progress = %d chunks, want 3", done.Load())
+	}
+}
+
+func TestTranslateChunks_PipelinedErrors(t *testing.T) {
+	tr := &pipeTranslator{second: make(chan struct{}), fail: "romance-en:gracias"}
+	r := &Router{lambdaClient: tr, pipelinedRoutes: true}
+	_, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", [][]string{{"hola"}, {"gracias"}}, Options{})
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != 2 || !strings.Contains(err.Error(), "chunk 1") {
+		t.Errorf("error = %v, want a step 2 error of chunk 1", err)
+	}
+
+	tr = &pipeTranslator{second: make(chan struct{}), noScores: true}
+	r = &Router{lambdaClient: tr, pipelinedRoutes: true}
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", [][]string{{"hola"}, {"gracias"}}, Options{ReturnScores: true})
+	if err != nil || result.Scores != nil {
+		t.Errorf("unscored route = %v, %v, want nil scores", result, err)
+	}
+}
+
+func TestTranslateChunks_DeadLetter(t *testing.T) {
+	tr := &pipeTranslator{second: make(chan struct{}), fail: "romance-en:gracias"}
+	r := &Router{lambdaClient: tr, pipelinedRoutes: true}
+	chunks := [][]string{{"hola", "adiós"}, {"gracias"}, {"last"}}
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", chunks, Options{DeadLetter: true, ReturnScores: true})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	want := [][]string{{"en-romance:romance-en:hola", "en-romance:romance-en:adiós"}, {""}, {"en-romance:romance-en:last"}}
+	if !reflect.DeepEqual(result.Translations, want) {
+		t.Errorf("Translations = %q, want %q", result.Translations, want)
+	}
+	var stepErr *StepError
+	if len(result.Failed) != 1 || result.Failed[0].Chunk != 1 || !errors.As(result.Failed[0].Err, &stepErr) || stepErr.Step != 2 {
+		t.Errorf("Failed = %+v, want chunk 1 failing step 2", result.Failed)
+	}
+	if result.Scores != nil || len(result.Intermediate[0][1]) != 1 {
+		t.Errorf("Scores = %v, Intermediate = %q, want no scores and the failed chunk's shape", result.Scores, result.Intermediate)
+	}
+
+	tr = &pipeTranslator{second: make(chan struct{}), fail: "hola"}
+	r = &Router{lambdaClient: tr}
+	if _, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", [][]string{{"hola"}}, Options{DeadLetter: true}); err == nil {
+		t.Error("error = nil when every chunk failed")
+	}
+}
+
+// batchTranslator echoes texts, failing the invocations holding fail, and
+// records the number of chunks of each invocation.
+type batchTranslator struct {
+	mu     sync.Mutex
+	chunks []int
+	fail   string
+}
+
+func (t *batchTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.chunks = append(t.chunks, len(req.Chunks))
+	t.mu.Unlock()
+	resp := TranslatorResponse{Translations: req.Chunks}
+	for _, chunk := range req.Chunks {
+		for _, text := range chunk {
+			if text == t.fail {
+				resp = TranslatorResponse{Error: "model crashed"}
+			}
+		}
+	}
+	payload, err := json.Marshal(resp)
+	if resp.Error != "" {
+		return &lambda.InvokeOutput{Payload: payload, FunctionError: params.FunctionName}, err
+	}
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestTranslateChunks_DeadLetterStaged(t *testing.T) {
+	// A function of its own keeps the latency model of other tests from
+	// fanning the step out.
+	opts := Options{DeadLetter: true, FunctionOverrides: map[string]string{"pricofy-translator-romance-en": "dead-letter-romance-en"}}
+	chunks := [][]string{{"hola"}, {"gracias"}}
+
+	tr := &batchTranslator{}
+	r := &Router{lambdaClient: tr}
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", chunks, opts)
+	if err != nil || len(result.Failed) != 0 {
+		t.Fatalf("TranslateChunksWithOptions() = %+v, %v", result, err)
+	}
+	if !reflect.DeepEqual(tr.chunks, []int{2}) || result.Dispatches[0] != DispatchSingle {
+		t.Errorf("invocations = %v chunks, dispatch %v, want one invocation of both chunks", tr.chunks, result.Dispatches)
+	}
+
+	tr = &batchTranslator{fail: "gracias"}
+	r = &Router{lambdaClient: tr}
+	result, err = r.TranslateChunksWithOptions(context.Background(), "es", "en", chunks, opts)
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Translations, [][]string{{"hola"}, {""}}) || len(result.Failed) != 1 || result.Failed[0].Chunk != 1 {
+		t.Errorf("Translations = %q, Failed = %+v, want chunk 1 dead-lettered", result.Translations, result.Failed)
+	}
+	if len(tr.chunks) != 3 || tr.chunks[0] != 2 {
+		t.Errorf("invocations = %v chunks, want the failed one of both chunks and then one per chunk", tr.chunks)
+	}
+}
+
+func TestTranslateChunks_Staged(t *testing.T) {
+	tr := &recordingTranslator{}
+	r := &Router{lambdaClient: tr}
+	var done []int
+	progress := func(chunks int) { done = append(done, chunks) }
+	result, err := r.TranslateChunksWithOptions(context.Background(), "es", "fr", [][]string{{"hola"}, {"gracias"}}, Options{Progress: progress})
+	if err != nil {
+		t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Translations, [][]string{{"hola"}, {"gracias"}}) {
+		t.Errorf("Translations = %q", result.Translations)
+	}
+	for _, dispatch := range result.Dispatches {
+		if dispatch == DispatchPipelined {
+			t.Errorf("Dispatches = %v, want staged steps", result.Dispatches)
+		}
+	}
+	if want := []string{"pricofy-translator-romance-en", "pricofy-translator-en-romance"}; !reflect.DeepEqual(tr.calls, want) {
+		t.Errorf("calls = %v, want %v", tr.calls, want)
+	}
+	if !reflect.DeepEqual(done, []int{2}) {
+		t.Errorf("progress = %v, want the 2 chunks once the route is done", done)
+	}
+}
+
+func TestPipelines(t *testing.T) {
+	pivot := []routeStep{{lambdaName: "romance-en"}, {lambdaName: "en-romance"}}
+	tests := []struct {
+		name      string
+		pipelined bool
+		route     []routeStep
+		chunks    [][]string
+		want      bool
+	}{
+		{name: "pivot route", pipelined: true, route: pivot, chunks: [][]string{{"a"}, {"b"}}, want: true},
+		{name: "single chunk", pipelined: true, route: pivot, chunks: [][]string{{"a"}}},
+		{name: "direct route", pipelined: true, route: pivot[:1], chunks: [][]string{{"a"}, {"b"}}},
+		{name: "not enabled", route: pivot, chunks: [][]string{{"a"}, {"b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{pipelinedRoutes: tt.pipelined}
+			if got := r.pipelines(tt.route, tt.chunks); got != tt.want {
+				t.Errorf("pipelines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
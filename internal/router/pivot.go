@@ -0,0 +1,131 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appconfig "github.com/pricofy/translation-manager/internal/config"
+)
+
+// PivotsEnv names the environment variable choosing the pivot languages of
+// language pairs as JSON (or PivotsEnv+"_FILE" pointing to a JSON file): a
+// language, e.g. {"ca-gl": "es"}, or the languages to pass through in
+// order, e.g. {"gl-oc": ["es", "ca"]}. Pairs it leaves out take the
+// lightest route (see RouteWeightsEnv), through English by default.
+const PivotsEnv = "PIVOT_LANGUAGES"
+
+// maxRouteSteps bounds the translator steps of a route, so a long pivot
+// chain cannot eat the time budget of a request.
+const maxRouteSteps = 4
+
+// pivotChain is the pivot config of a pair: one language or a list.
+type pivotChain []string
+
+// UnmarshalJSON accepts a language or an array of languages.
+func (c *pivotChain) UnmarshalJSON(data []byte) error {
+	var lang string
+	if err := json.Unmarshal(data, &lang); err == nil {
+		*c = pivotChain{lang}
+		return nil
+	}
+	var langs []string
+	if err := json.Unmarshal(data, &langs); err != nil {
+		return fmt.Errorf("pivot must be a language or an array of languages")
+	}
+	*c = langs
+	return nil
+}
+
+// loadPivots reads the PIVOT_LANGUAGES config. Every configured pair must
+// be routable through its pivots with the translators of r.
+func (r *Router) loadPivots() (map[string][]string, error) {
+	var config map[string]pivotChain
+	if _, err := appconfig.LoadJSON(PivotsEnv, &config); err != nil {
+		return nil, err
+	}
+	var pivots map[string][]string
+	for pair, chain := range config {
+		if err := r.checkPivots(pair, chain); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", PivotsEnv, err)
+		}
+		if pivots == nil {
+			pivots = map[string][]string{}
+		}
+		pivots[pair] = chain
+	}
+	return pivots, nil
+}
+
+// checkPivots verifies pair can be routed through chain: distinct
+// supported languages, each hop served by a translator.
+func (r *Router) checkPivots(pair string, chain []string) error {
+	source, target, ok := strings.Cut(pair, "-")
+	if !ok || !directPair(pair) {
+		return fmt.Errorf("%q is not a pair of supported languages", pair)
+	}
+	if len(chain) == 0 || len(chain) >= maxRouteSteps {
+		return fmt.Errorf("%s must have 1 to %d pivots", pair, maxRouteSteps-1)
+	}
+	seen := map[string]bool{source: true, target: true}
+	for _, pivot := range chain {
+		if !supportedLanguages[pivot] || seen[pivot] {
+			return fmt.Errorf("pivot %q of %s must be another supported language", pivot, pair)
+		}
+		seen[pivot] = true
+	}
+	if r.routeThrough(source, chain, target) == nil {
+		return fmt.Errorf("no translators for %s", strings.Join(append(append([]string{source}, chain...), target), "→"))
+	}
+	return nil
+}
+
+// pivots returns the intermediate languages of the source → target route,
+// in order: the configured ones, else those of the lightest path in the
+// route graph (none for a single hop). ok is false without a route.
+func (r *Router) pivots(source, target string) (pivots []string, ok bool) {
+	if chain, ok := r.pivotChains[source+"-"+target]; ok {
+		return chain, true
+	}
+	path := r.graph().path(source, target)
+	if path == nil {
+		return nil, false
+	}
+	return path[1 : len(path)-1], true
+}
+
+// routeThrough returns the steps translating source into target through
+// every language of chain in order, or nil if a hop has no translator.
+func (r *Router) routeThrough(source string, chain []string, target string) []routeStep {
+	route := make([]routeStep, 0, len(chain)+1)
+	from := source
+	for _, to := range append(chain[:len(chain):len(chain)], target) {
+		if from == to {
+			return nil
+		}
+		step, ok := r.hop(from, to)
+		if !ok {
+			return nil
+		}
+		route = append(route, step)
+		from = to
+	}
+	return route
+}
+
+// hop returns the single translator step for source → target: a direct
+// translator of the pair, or a built-in translator to or from English.
+func (r *Router) hop(source, target string) (routeStep, bool) {
+	pair := source + "-" + target
+	if function, ok := r.functions[pair]; ok && !builtInTranslator(pair) {
+		return routeStep{lambdaName: function, targetLang: target}, true
+	}
+	from, to := groupOf(source), groupOf(target)
+	switch {
+	case from != nil && target == "en":
+		return r.toEnglish(from), true
+	case source == "en" && to != nil:
+		return r.fromEnglish(to, target), true
+	}
+	return routeStep{}, false
+}
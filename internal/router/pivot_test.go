@@ -0,0 +1,94 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+)
+
+// spanishHub has direct translators between Spanish, Catalan and Galician,
+// and from Catalan to Occitan.
+func spanishHub() *Router {
+	return &Router{functions: map[string]string{
+		"ca-es": "translator-ca-es",
+		"es-gl": "translator-es-gl",
+		"gl-es": "translator-gl-es",
+		"es-ca": "translator-es-ca",
+		"ca-oc": "translator-ca-oc",
+	}}
+}
+
+func TestGetRoute_Pivots(t *testing.T) {
+	r := spanishHub()
+	r.pivotChains = map[string][]string{"ca-gl": {"es"}, "gl-ca": {"es"}, "gl-oc": {"es", "ca"}}
+
+	tests := []struct {
+		source, target string
+		want           []routeStep
+		wantPivots     []string
+	}{
+		{"ca", "gl", []routeStep{{"translator-ca-es", "es"}, {"translator-es-gl", "gl"}}, []string{"es"}},
+		{"gl", "ca", []routeStep{{"translator-gl-es", "es"}, {"translator-es-ca", "ca"}}, []string{"es"}},
+		{"es", "gl", []routeStep{{"translator-es-gl", "gl"}}, nil},
+		{"ca", "fr", []routeStep{{"pricofy-translator-romance-en", ""}, {"pricofy-translator-en-romance", "fr"}}, []string{"en"}},
+		{"gl", "en", []routeStep{{"pricofy-translator-romance-en", ""}}, nil},
+		{"gl", "oc", []routeStep{{"translator-gl-es", "es"}, {"translator-es-ca", "ca"}, {"translator-ca-oc", "oc"}}, []string{"es", "ca"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"-"+tt.target, func(t *testing.T) {
+			if got := r.getRoute(tt.source, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRoute() = %v, want %v", got, tt.want)
+			}
+			plan, err := r.Plan(tt.source, tt.target, "")
+			if err != nil || !reflect.DeepEqual(plan.Pivots, tt.wantPivots) {
+				t.Errorf("Plan() = %+v, %v, want pivots %q", plan, err, tt.wantPivots)
+			}
+			if len(tt.wantPivots) > 0 && plan.PivotLang != tt.wantPivots[0] {
+				t.Errorf("Plan().PivotLang = %q, want %q", plan.PivotLang, tt.wantPivots[0])
+			}
+		})
+	}
+}
+
+func TestLoadPivots(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"spanish hub", `{"ca-gl": "es"}`, false},
+		{"built-in hops", `{"es-de": "en"}`, false},
+		{"missing translator", `{"gl-ca": "es", "pt-gl": "es"}`, true},
+		{"pivot is an end", `{"ca-gl": "gl"}`, true},
+		{"unsupported pivot", `{"ca-gl": "zh"}`, true},
+		{"malformed pair", `{"cagl": "es"}`, true},
+		{"pivot chain", `{"gl-oc": ["es", "ca"]}`, false},
+		{"chain missing a hop", `{"gl-oc": ["ca", "es"]}`, true},
+		{"repeated pivot", `{"gl-oc": ["es", "es"]}`, true},
+		{"empty chain", `{"gl-oc": []}`, true},
+		{"chain too long", `{"gl-oc": ["es", "ca", "en", "fr"]}`, true},
+		{"not a language", `{"gl-oc": 3}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PivotsEnv, tt.config)
+			_, err := spanishHub().loadPivots()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadPivots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFunctions_DirectTranslators(t *testing.T) {
+	t.Setenv(FunctionsEnv, `{"es-gl": "translator-es-gl-{env}"}`)
+	functions, err := loadFunctions("prod")
+	if err != nil {
+		t.Fatalf("loadFunctions() error = %v", err)
+	}
+	if step, ok := (&Router{functions: functions}).hop("es", "gl"); !ok || step.lambdaName != "translator-es-gl-prod" || step.targetLang != "gl" {
+		t.Errorf("hop(es, gl) = %+v, %v, want the direct translator", step, ok)
+	}
+}
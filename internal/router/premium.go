@@ -0,0 +1,32 @@
+package router
+
+import (
+	"context"
+	"errors"
+)
+
+// BackendFallback describes a premium backend failure the Lambda fleet
+// translated around.
+type BackendFallback struct {
+	Backend string
+	Err     error
+}
+
+// translatePremium translates chunks with the premium backend name. When
+// it fails, pairs the Lambda fleet serves are translated by the fleet
+// instead, unless the failure is the request's own: over its cost limit or
+// out of time.
+func (r *Router) translatePremium(ctx context.Context, name, source, target string, chunks [][]string, opts Options) (*Result, error) {
+	result, err := r.translateWith(ctx, name, source, target, chunks, opts, nil)
+	var costErr *CostError
+	if err == nil || errors.As(err, &costErr) || ctx.Err() != nil || !r.IsValidPair(source, target) {
+		return result, err
+	}
+	opts.Premium, opts.Backend = false, BackendLambda
+	result, lambdaErr := r.TranslateChunksWithOptions(ctx, source, target, chunks, opts)
+	if lambdaErr != nil {
+		return nil, lambdaErr
+	}
+	result.Fallback = &BackendFallback{Backend: name, Err: err}
+	return result, nil
+}
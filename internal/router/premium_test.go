@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTranslatePremium(t *testing.T) {
+	chunks := [][]string{{"hola"}}
+
+	tests := []struct {
+		name         string
+		premium      bool
+		config       backendConfig
+		deepLErr     error
+		maxCost      float64
+		wantSteps    []string
+		wantFallback bool
+		wantErr      bool
+	}{
+		{name: "standard", wantSteps: []string{defaultFunctionPrefix + TranslatorRomanceEn}},
+		{name: "premium", premium: true, wantSteps: []string{BackendDeepL}},
+		{name: "configured premium", premium: true, config: backendConfig{Premium: BackendAWSTranslate}, wantSteps: []string{BackendAWSTranslate}},
+		{name: "premium failure", premium: true, deepLErr: errors.New("HTTP 456: Quota exceeded"), wantSteps: []string{defaultFunctionPrefix + TranslatorRomanceEn}, wantFallback: true},
+		{name: "over budget", premium: true, maxCost: 0.01, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{
+				lambdaClient:  &recordingTranslator{},
+				costs:         map[string]Cost{BackendDeepL: {PerChar: 0.01}},
+				backendConfig: tt.config,
+				backends: map[string]Translator{
+					BackendDeepL:        &upperTranslator{err: tt.deepLErr},
+					BackendAWSTranslate: &upperTranslator{},
+				},
+			}
+			result, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", chunks, Options{Premium: tt.premium, MaxCost: tt.maxCost})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranslateChunksWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(result.Steps, tt.wantSteps) {
+				t.Errorf("steps = %v, want %v", result.Steps, tt.wantSteps)
+			}
+			if (result.Fallback != nil) != tt.wantFallback {
+				t.Fatalf("fallback = %+v, want %v", result.Fallback, tt.wantFallback)
+			}
+			if tt.wantFallback && (result.Fallback.Backend != BackendDeepL || !errors.Is(result.Fallback.Err, tt.deepLErr)) {
+				t.Errorf("fallback = %+v, want the %s error", result.Fallback, BackendDeepL)
+			}
+		})
+	}
+}
+
+func TestPremiumBackend(t *testing.T) {
+	deepL := map[string]Translator{BackendDeepL: &upperTranslator{}}
+
+	tests := []struct {
+		name string
+		r    *Router
+		want string
+	}{
+		{"deepl", &Router{backends: deepL}, BackendDeepL},
+		{"configured", &Router{backends: deepL, backendConfig: backendConfig{Premium: BackendAWSTranslate}}, BackendAWSTranslate},
+		{"none", &Router{}, ""},
+		{"local", &Router{backends: deepL, dryRun: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.premiumBackend(); got != tt.want {
+				t.Errorf("premiumBackend() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// warmupPayload is the event translators answer without loading a model
+// request, which is enough to bring up an instance.
+var warmupPayload = []byte(`{"source":"warmup"}`)
+
+// prewarm pings every route step after the first with an asynchronous
+// warmup event, so a pivot job finds the second hop warm once the first
+// hop finishes instead of paying a cold start mid-job. Failures are logged
+// and otherwise ignored: a cold second hop is slower, not wrong.
+func (r *Router) prewarm(ctx context.Context, route []routeStep, overrides map[string]string) {
+	for _, step := range route[1:] {
+		functionName := stepFunction(step, overrides)
+		_, err := r.lambdaClient.Invoke(ctx, &lambda.InvokeInput{
+			FunctionName:   &functionName,
+			InvocationType: types.InvocationTypeEvent,
+			Payload:        warmupPayload,
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "prewarm failed", slog.String("function", functionName), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// recordingTranslator echoes its chunks and records every invocation as
+// "function" or "function (event)".
+type recordingTranslator struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (t *recordingTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if params.InvocationType == types.InvocationTypeEvent {
+		t.calls = append(t.calls, *params.FunctionName+" (event)")
+		return &lambda.InvokeOutput{StatusCode: 202}, nil
+	}
+	t.calls = append(t.calls, *params.FunctionName)
+
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(TranslatorResponse{Translations: req.Chunks})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestTranslateChunks_Prewarm(t *testing.T) {
+	chunks := [][]string{{"hola"}}
+
+	tests := []struct {
+		name   string
+		target string
+		opts   Options
+		want   []string
+	}{
+		{
+			name:   "pivot route",
+			target: "fr",
+			opts:   Options{PrewarmNextHop: true},
+			want: []string{
+				"pricofy-translator-en-romance (event)",
+				"pricofy-translator-romance-en",
+				"pricofy-translator-en-romance",
+			},
+		},
+		{
+			name:   "pivot route with override",
+			target: "de",
+			opts: Options{
+				PrewarmNextHop:    true,
+				FunctionOverrides: map[string]string{"pricofy-translator-en-de": "candidate-en-de"},
+			},
+			want: []string{
+				"candidate-en-de (event)",
+				"pricofy-translator-romance-en",
+				"candidate-en-de",
+			},
+		},
+		{
+			name:   "direct route",
+			target: "en",
+			opts:   Options{PrewarmNextHop: true},
+			want:   []string{"pricofy-translator-romance-en"},
+		},
+		{
+			name:   "not requested",
+			target: "fr",
+			want:   []string{"pricofy-translator-romance-en", "pricofy-translator-en-romance"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &recordingTranslator{}
+			r := &Router{lambdaClient: client}
+			if _, err := r.TranslateChunksWithOptions(context.Background(), "es", tt.target, chunks, tt.opts); err != nil {
+				t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Errorf("invocations = %q, want %q", client.calls, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxExcerpt is the length, in bytes, of the payload excerpt in a
+// SchemaError.
+const maxExcerpt = 200
+
+// SchemaError reports a translator response that does not match the
+// TranslatorResponse schema, e.g. {"translations": "oops"}.
+type SchemaError struct {
+	Function string
+	// Field is the path of the offending value, e.g. "translations[1][0]",
+	// empty when the payload as a whole is invalid.
+	Field string
+	// Problem describes the mismatch, e.g. "want array of arrays of strings, got string".
+	Problem string
+	// Excerpt is the start of the payload.
+	Excerpt string
+}
+
+func (e *SchemaError) Error() string {
+	where := "response"
+	if e.Field != "" {
+		where = "field " + e.Field
+	}
+	return fmt.Sprintf("%s returned an invalid response: %s: %s (payload: %s)", e.Function, where, e.Problem, e.Excerpt)
+}
+
+// fieldSchemas lists the JSON fields of a translator response with a check
+// of their value, returning the path and description of a mismatch. Nulls
+// decode as zero values and always match; unknown fields are ignored.
+var fieldSchemas = []struct {
+	name  string
+	check func(path string, v any) (string, string)
+}{
+	{"translations", nestedArray(stringType)},
+	{"scores", nestedArray(numberType)},
+	{"model_version", scalar(stringType)},
+	{"formats", array(stringType)},
+	{"error", scalar(stringType)},
+	{"format", scalar(stringType)},
+	{"payload", scalar(stringType)},
+}
+
+// Scalar JSON types of response fields.
+const (
+	stringType = "string"
+	numberType = "number"
+)
+
+// responseSchemaError locates the mismatch that made payload fail to
+// decode with decodeErr. Decoding is tried first so that valid responses
+// are parsed only once.
+func responseSchemaError(function string, payload []byte, decodeErr error) *SchemaError {
+	e := &SchemaError{Function: function, Excerpt: excerpt(payload)}
+	var doc any
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		e.Problem = "not valid JSON: " + err.Error()
+		return e
+	}
+	fields, ok := doc.(map[string]any)
+	if !ok {
+		e.Problem = "want object, got " + jsonType(doc)
+		return e
+	}
+	for _, f := range fieldSchemas {
+		if field, problem := f.check(f.name, fields[f.name]); problem != "" {
+			e.Field, e.Problem = field, problem
+			return e
+		}
+	}
+	// The JSON is well-formed: the envelope's binary payload is at fault.
+	e.Problem = decodeErr.Error()
+	return e
+}
+
+// missingTranslations is the error for a response with neither
+// translations nor an error.
+func missingTranslations(function string, payload []byte) *SchemaError {
+	return &SchemaError{Function: function, Field: "translations", Problem: "missing", Excerpt: excerpt(payload)}
+}
+
+// scalar checks that a value has JSON type want.
+func scalar(want string) func(string, any) (string, string) {
+	return func(path string, v any) (string, string) {
+		if got := jsonType(v); v != nil && got != want {
+			return path, fmt.Sprintf("want %s, got %s", want, got)
+		}
+		return "", ""
+	}
+}
+
+// array checks that a value is an array of want.
+func array(want string) func(string, any) (string, string) {
+	elem := scalar(want)
+	return func(path string, v any) (string, string) {
+		items, ok := v.([]any)
+		if !ok && v != nil {
+			return path, fmt.Sprintf("want array of %ss, got %s", want, jsonType(v))
+		}
+		for i, item := range items {
+			if field, problem := elem(fmt.Sprintf("%s[%d]", path, i), item); problem != "" {
+				return field, problem
+			}
+		}
+		return "", ""
+	}
+}
+
+// nestedArray checks that a value is an array, per chunk, of arrays of
+// want.
+func nestedArray(want string) func(string, any) (string, string) {
+	inner := array(want)
+	return func(path string, v any) (string, string) {
+		chunks, ok := v.([]any)
+		if !ok && v != nil {
+			return path, fmt.Sprintf("want array of arrays of %ss, got %s", want, jsonType(v))
+		}
+		for i, chunk := range chunks {
+			if field, problem := inner(fmt.Sprintf("%s[%d]", path, i), chunk); problem != "" {
+				return field, problem
+			}
+		}
+		return "", ""
+	}
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return numberType
+	case string:
+		return stringType
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// excerpt returns the start of payload, cut at a character boundary.
+func excerpt(payload []byte) string {
+	if len(payload) <= maxExcerpt {
+		return string(payload)
+	}
+	cut := maxExcerpt
+	for cut > 0 && !utf8.RuneStart(payload[cut]) {
+		cut--
+	}
+	return string(payload[:cut]) + "…"
+}
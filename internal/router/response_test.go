@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+func TestParseTranslatorResponse_Schema(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		wantField string
+		wantIn    string
+	}{
+		{"translations not an array", `{"translations": "oops"}`, "translations", "want array of arrays of strings, got string"},
+		{"flat translations", `{"translations": ["a", "b"]}`, "translations[0]", "want array of strings, got string"},
+		{"number translation", `{"translations": [["a", 3]]}`, "translations[0][1]", "want string, got number"},
+		{"string score", `{"translations": [["a"]], "scores": [["high"]]}`, "scores[0][0]", "want number, got string"},
+		{"formats not an array", `{"translations": [["a"]], "formats": "msgpack"}`, "formats", "want array of strings, got string"},
+		{"not an object", `[["a"]]`, "", "want object, got array"},
+		{"not json", `<html>Bad Gateway</html>`, "", "not valid JSON"},
+		{"missing translations", `{"model_version": "v2"}`, "translations", "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTranslatorResponse("translator-es-en", []byte(tt.payload))
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("parseTranslatorResponse() error = %v, want a *SchemaError", err)
+			}
+			if schemaErr.Field != tt.wantField || !strings.Contains(schemaErr.Problem, tt.wantIn) {
+				t.Errorf("error field %q problem %q, want %q containing %q", schemaErr.Field, schemaErr.Problem, tt.wantField, tt.wantIn)
+			}
+			if msg := err.Error(); !strings.Contains(msg, "translator-es-en") || !strings.Contains(msg, tt.payload) {
+				t.Errorf("error %q should name the function and quote the payload", msg)
+			}
+		})
+	}
+}
+
+func TestParseTranslatorResponse_NullsMatch(t *testing.T) {
+	resp, err := parseTranslatorResponse("translator", []byte(`{"translations": [["a", null]], "scores": null, "model_version": null}`))
+	if err != nil {
+		t.Fatalf("parseTranslatorResponse() error = %v", err)
+	}
+	if len(resp.Translations[0]) != 2 {
+		t.Errorf("translations = %q", resp.Translations)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := `{"translations": "` + strings.Repeat("ñ", maxExcerpt) + `"}`
+	got := excerpt([]byte(long))
+	if !strings.HasSuffix(got, "…") || len(got) > maxExcerpt+len("…") {
+		t.Errorf("excerpt() = %d bytes, want at most %d and an ellipsis", len(got), maxExcerpt)
+	}
+	if !strings.HasPrefix(got, `{"translations": "ñ`) || strings.ContainsRune(got, '�') {
+		t.Errorf("excerpt() = %q, want the start cut at a character boundary", got)
+	}
+	if got := excerpt([]byte(`{}`)); got != `{}` {
+		t.Errorf("excerpt() of a short payload = %q", got)
+	}
+}
+
+// rawTranslator answers every invocation with a fixed payload.
+type rawTranslator string
+
+func (p rawTranslator) Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	return &lambda.InvokeOutput{Payload: []byte(p)}, nil
+}
+
+func TestTranslateChunks_SchemaError(t *testing.T) {
+	r := &Router{lambdaClient: rawTranslator(`{"translations": "oops"}`)}
+	_, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", [][]string{{"hola"}}, Options{})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Field != "translations" {
+		t.Fatalf("TranslateChunksWithOptions() error = %v, want a schema error on translations", err)
+	}
+}
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Environment variables of the invocation retry policy.
+const (
+	// RetryAttemptsEnv is the number of invocation attempts, including the
+	// first; 1 disables retries.
+	RetryAttemptsEnv = "TRANSLATOR_RETRY_ATTEMPTS"
+	// RetryBaseDelayEnv is the backoff before the first retry (Go duration),
+	// doubled on every further retry.
+	RetryBaseDelayEnv = "TRANSLATOR_RETRY_BASE_DELAY"
+	// RetryMaxDelayEnv caps the backoff (Go duration).
+	RetryMaxDelayEnv = "TRANSLATOR_RETRY_MAX_DELAY"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 2 * time.Second
+)
+
+// retryPolicy retries throttled and failed invocations with exponential
+// backoff and full jitter, so bursts spread out instead of retrying in
+// lockstep. The zero policy makes a single attempt.
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// loadRetryPolicy reads the retry policy from the environment.
+func loadRetryPolicy() (retryPolicy, error) {
+	p := retryPolicy{
+		attempts:  defaultRetryAttempts,
+		baseDelay: defaultRetryBaseDelay,
+		maxDelay:  defaultRetryMaxDelay,
+	}
+	if v := os.Getenv(RetryAttemptsEnv); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return retryPolicy{}, fmt.Errorf("invalid %s %q: want a positive attempt count", RetryAttemptsEnv, v)
+		}
+		p.attempts = n
+	}
+	for _, d := range []struct {
+		env   string
+		delay *time.Duration
+	}{
+		{RetryBaseDelayEnv, &p.baseDelay},
+		{RetryMaxDelayEnv, &p.maxDelay},
+	} {
+		v := os.Getenv(d.env)
+		if v == "" {
+			continue
+		}
+		delay, err := time.ParseDuration(v)
+		if err != nil || delay <= 0 {
+			return retryPolicy{}, fmt.Errorf("invalid %s %q: want a positive duration such as 200ms", d.env, v)
+		}
+		*d.delay = delay
+	}
+	if p.maxDelay < p.baseDelay {
+		return retryPolicy{}, fmt.Errorf("%s %s is below %s %s", RetryMaxDelayEnv, p.maxDelay, RetryBaseDelayEnv, p.baseDelay)
+	}
+	return p, nil
+}
+
+// backoff returns the delay before retry number retry (1 for the first): a
+// random duration up to the exponential backoff.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.maxDelay
+	if shift := retry - 1; shift < 32 && p.baseDelay<<shift < p.maxDelay {
+		ceiling = p.baseDelay << shift
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// sdkRetryables are the SDK's own retryable errors: transport failures
+// such as connection resets and timeouts, and retryable error codes. The
+// SDK retryer is off (see New), so the router classifies them itself.
+var sdkRetryables = retry.IsErrorRetryables(retry.DefaultRetryables)
+
+// retryable reports whether an invocation error is worth retrying: Lambda
+// throttling, server-side (5xx) failures and the errors the SDK retries.
+// Client errors such as a missing function or an invalid payload fail the
+// same way on every attempt, and canceled requests are not retried.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var throttled *types.TooManyRequestsException
+	if errors.As(err, &throttled) {
+		return true
+	}
+	var status interface{ HTTPStatusCode() int }
+	if errors.As(err, &status) {
+		if code := status.HTTPStatusCode(); code == 429 || code >= 500 {
+			return true
+		}
+	}
+	return sdkRetryables.IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// do runs call, retrying retryable errors under the policy until the
+// attempts or ctx run out, and returns the last error.
+func (p retryPolicy) do(ctx context.Context, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= p.attempts || !retryable(err) {
+			return err
+		}
+		if retries, ok := ctx.Value(retriesKey{}).(*atomic.Int64); ok {
+			retries.Add(1)
+		}
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retriesKey is the context key of a retry counter.
+type retriesKey struct{}
+
+// CountRetries returns a context counting the retried translator calls
+// made under it, translation backends included, and a function reporting
+// the count. A Router serves many requests at once, so each counts its own.
+func CountRetries(ctx context.Context) (context.Context, func() int) {
+	retries := new(atomic.Int64)
+	return context.WithValue(ctx, retriesKey{}, retries), func() int { return int(retries.Load()) }
+}
+
+// invoke calls the Lambda client, retrying retryable errors under the
+// router's retry policy. Every retry takes a rate token of the function's
+// load limit, as the first attempt did (see acquire).
+func (r *Router) invoke(ctx context.Context, params *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	var out *lambda.InvokeOutput
+	retry := false
+	err := r.retry.do(ctx, func() error {
+		if retry {
+			if err := r.retryToken(ctx, aws.ToString(params.FunctionName)); err != nil {
+				return err
+			}
+		}
+		retry = true
+		var err error
+		out, err = r.lambdaClient.Invoke(ctx, params, withTraceHeader(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
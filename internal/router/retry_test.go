@@ -0,0 +1,192 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// statusError is an invocation error with an HTTP status, like the SDK's
+// response errors.
+type statusError struct{ code int }
+
+func (e *statusError) Error() string       { return fmt.Sprintf("status %d", e.code) }
+func (e *statusError) HTTPStatusCode() int { return e.code }
+
+// flakyTranslator fails its first invocations with errs, then echoes.
+type flakyTranslator struct {
+	errs  []error
+	calls int
+}
+
+func (t *flakyTranslator) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	t.calls++
+	if t.calls <= len(t.errs) {
+		return nil, t.errs[t.calls-1]
+	}
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(TranslatorResponse{Translations: req.Chunks})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "throttled", err: &types.TooManyRequestsException{}, want: true},
+		{name: "wrapped throttle", err: fmt.Errorf("invoke: %w", &types.TooManyRequestsException{}), want: true},
+		{name: "service error", err: &statusError{code: 500}, want: true},
+		{name: "unavailable", err: &statusError{code: 503}, want: true},
+		{name: "too many requests status", err: &statusError{code: 429}, want: true},
+		{name: "not found", err: &statusError{code: 404}, want: false},
+		{name: "invalid request", err: &types.InvalidRequestContentException{}, want: false},
+		{name: "plain error", err: errors.New("boom"), want: false},
+		{name: "connection reset", err: &smithyhttp.RequestSendError{Err: syscall.ECONNRESET}, want: true},
+		{name: "dial timeout", err: &smithyhttp.RequestSendError{Err: &net.OpError{Op: "dial", Err: timeoutError{}}}, want: true},
+		{name: "read timeout", err: fmt.Errorf("invoke: %w", &net.OpError{Op: "read", Err: timeoutError{}}), want: true},
+		{name: "canceled", err: &smithyhttp.RequestSendError{Err: context.Canceled}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{attempts: 10, baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+	tests := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{retry: 1, max: 100 * time.Millisecond},
+		{retry: 2, max: 200 * time.Millisecond},
+		{retry: 4, max: 800 * time.Millisecond},
+		{retry: 5, max: time.Second},
+		{retry: 64, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := p.backoff(tt.retry); got < 0 || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", tt.retry, got, tt.max)
+			}
+		}
+	}
+}
+
+func TestInvokeLambda_Retry(t *testing.T) {
+	throttled := &types.TooManyRequestsException{}
+	tests := []struct {
+		name      string
+		attempts  int
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "no errors", attempts: 3, wantCalls: 1},
+		{name: "recovers from throttling", attempts: 3, errs: []error{throttled, &statusError{code: 502}}, wantCalls: 3},
+		{name: "attempts run out", attempts: 2, errs: []error{throttled, throttled}, wantCalls: 2, wantErr: true},
+		{name: "client errors are not retried", attempts: 3, errs: []error{&statusError{code: 400}}, wantCalls: 1, wantErr: true},
+		{name: "zero policy makes one attempt", errs: []error{throttled}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &flakyTranslator{errs: tt.errs}
+			r := &Router{
+				lambdaClient: client,
+				retry:        retryPolicy{attempts: tt.attempts, baseDelay: time.Millisecond, maxDelay: time.Millisecond},
+			}
+			ctx, retries := CountRetries(context.Background())
+			_, err := r.invokeLambda(ctx, "test-retry", "en", [][]string{{"hola"}}, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("invokeLambda() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("invocations = %d, want %d", client.calls, tt.wantCalls)
+			}
+			if got := retries(); got != tt.wantCalls-1 {
+				t.Errorf("retries() = %d, want %d", got, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestInvokeLambda_RetryStopsOnCancel(t *testing.T) {
+	client := &flakyTranslator{errs: []error{&types.TooManyRequestsException{}, &types.TooManyRequestsException{}}}
+	r := &Router{
+		lambdaClient: client,
+		retry:        retryPolicy{attempts: 3, baseDelay: time.Hour, maxDelay: time.Hour},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := r.invokeLambda(ctx, "test-retry", "en", [][]string{{"hola"}}, false); err == nil {
+		t.Fatal("invokeLambda() error = nil, want the throttling error")
+	}
+	if client.calls != 1 {
+		t.Errorf("invocations = %d, want 1", client.calls)
+	}
+}
+
+func TestLoadRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    retryPolicy
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: retryPolicy{attempts: defaultRetryAttempts, baseDelay: defaultRetryBaseDelay, maxDelay: defaultRetryMaxDelay},
+		},
+		{
+			name: "configured",
+			env:  map[string]string{RetryAttemptsEnv: "5", RetryBaseDelayEnv: "50ms", RetryMaxDelayEnv: "1s"},
+			want: retryPolicy{attempts: 5, baseDelay: 50 * time.Millisecond, maxDelay: time.Second},
+		},
+		{name: "no attempts", env: map[string]string{RetryAttemptsEnv: "0"}, wantErr: true},
+		{name: "bad delay", env: map[string]string{RetryBaseDelayEnv: "soon"}, wantErr: true},
+		{name: "max below base", env: map[string]string{RetryBaseDelayEnv: "5s", RetryMaxDelayEnv: "1s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{RetryAttemptsEnv, RetryBaseDelayEnv, RetryMaxDelayEnv} {
+				t.Setenv(env, tt.env[env])
+			}
+			got, err := loadRetryPolicy()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadRetryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("loadRetryPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -3,12 +3,22 @@ package router
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pricofy/translation-manager/internal/domain"
+	"github.com/pricofy/translation-manager/internal/ratelimit"
+	"github.com/pricofy/translation-manager/internal/resultstore"
+	"github.com/pricofy/translation-manager/internal/routing"
+	"github.com/pricofy/translation-manager/internal/tracing"
 )
 
 // Language groups
@@ -52,37 +62,225 @@ var (
 		"sc":  true, // Sardinian
 	}
 
-	// All supported languages (romance + german + english)
+	// Slavic languages supported by opus-mt-sla-en / opus-mt-en-sla
+	slavicLanguages = map[string]bool{
+		"pl": true, // Polish
+		"cs": true, // Czech
+		"sk": true, // Slovak
+		"uk": true, // Ukrainian
+		"ru": true, // Russian
+		"be": true, // Belarusian
+		"bg": true, // Bulgarian
+		"mk": true, // Macedonian
+		"sl": true, // Slovenian
+		"hr": true, // Croatian
+		"bs": true, // Bosnian
+		"sr": true, // Serbian
+	}
+
+	// North Germanic languages supported by opus-mt-gmq-en / opus-mt-en-gmq
+	nordicLanguages = map[string]bool{
+		"sv": true, // Swedish
+		"da": true, // Danish
+		"no": true, // Norwegian
+		"nb": true, // Norwegian Bokmål
+		"nn": true, // Norwegian Nynorsk
+		"is": true, // Icelandic
+	}
+
+	// All supported languages (romance + slavic + nordic + german + english)
 	supportedLanguages = map[string]bool{}
 )
 
-// Initialize supportedLanguages from romanceLanguages + de + en
+// Initialize supportedLanguages from romanceLanguages + slavicLanguages +
+// nordicLanguages + de + en
 func init() {
 	for lang := range romanceLanguages {
 		supportedLanguages[lang] = true
 	}
+	for lang := range slavicLanguages {
+		supportedLanguages[lang] = true
+	}
+	for lang := range nordicLanguages {
+		supportedLanguages[lang] = true
+	}
 	supportedLanguages["de"] = true
 	supportedLanguages["en"] = true
 }
 
+// Invoker is the subset of the Lambda client used to call translators.
+type Invoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
 // Router routes translation requests to the appropriate Lambda function.
 type Router struct {
-	lambdaClient *lambda.Client
+	lambdaClient Invoker
 	environment  string
+
+	// results is set in event invocation mode: translators are invoked
+	// asynchronously and write their response to this store.
+	results      *resultstore.Store
+	pollInterval time.Duration
+
+	// payloadFormat is the preferred translator payload format.
+	payloadFormat string
+
+	// routes is the runtime routing table, nil unless ROUTING_TABLE is set.
+	routes *routing.Cache
+
+	// functions maps built-in translators to deployed functions
+	// (TRANSLATOR_FUNCTIONS); unmapped ones use their default name.
+	functions map[string]string
+
+	// chunkIDNamespace is mixed into every chunk ID (CHUNK_ID_NAMESPACE).
+	chunkIDNamespace string
+
+	// fanOutThreshold is the chunk count from which steps fan out until
+	// their latency model can choose (FANOUT_THRESHOLD).
+	fanOutThreshold int
+
+	// retry is the policy for throttled and failed invocations
+	// (TRANSLATOR_RETRY_*).
+	retry retryPolicy
+
+	// costs is the cost model per function (TRANSLATOR_COSTS).
+	costs map[string]Cost
+
+	// chunkBudgets is the token budget of a chunk per function
+	// (TRANSLATOR_CHUNK_TOKENS).
+	chunkBudgets map[string]int
+
+	// limits caps the load on each function (TRANSLATOR_LIMITS); nil
+	// limits nothing.
+	limits *ratelimit.Set
+
+	// backendConfig chooses backends per pair (TRANSLATION_BACKENDS), and
+	// backends holds the ones other than the Lambda fleet.
+	backendConfig backendConfig
+	backends      map[string]Translator
+
+	// dryRun translates every pair with BackendDryRun unless a request
+	// forces a backend (ENVIRONMENT=local).
+	dryRun bool
+
+	// pipelinedRoutes pipelines chunks through multi-step routes instead
+	// of running them one step at a time over all chunks (PIPELINED_ROUTES).
+	pipelinedRoutes bool
+
+	// pivotChains maps "source-target" pairs to their pivot languages when
+	// they are not English (PIVOT_LANGUAGES).
+	pivotChains map[string][]string
+
+	// weights are the translator weights of route selection
+	// (ROUTE_WEIGHTS), and routeGraph the graph routes are found in.
+	weights    map[string]RouteWeight
+	graphOnce  sync.Once
+	routeGraph *routeGraph
 }
 
-// TranslatorRequest is the request format for translator Lambdas (chunked mode).
-type TranslatorRequest struct {
-	Chunks     [][]string `json:"chunks"`
-	TargetLang string     `json:"target_lang,omitempty"` // Required for en-romance
+// TranslatorRequest and TranslatorResponse are the wire format of
+// translator Lambdas (chunked mode); see the domain package.
+type (
+	TranslatorRequest  = domain.TranslatorRequest
+	TranslatorResponse = domain.TranslatorResponse
+)
+
+// Options tunes a single TranslateChunksWithOptions call.
+type Options struct {
+	// ReturnScores asks every translator in the route for per-text scores.
+	ReturnScores bool
+	// FunctionOverrides replaces route functions by name (e.g. for experiments).
+	FunctionOverrides map[string]string
+	// PrewarmNextHop pings the later steps of a pivot route before the first
+	// step runs, hiding their cold start behind it.
+	PrewarmNextHop bool
+	// MaxCost is the most the translation may cost under the cost model;
+	// 0 means no limit. Over budget, draft functions are used if they fit,
+	// and a *CostError is returned otherwise.
+	MaxCost float64
+	// Backend forces a translation backend (e.g. BackendAWSTranslate)
+	// instead of the one configured for the pair.
+	Backend string
+	// Tag selects the routing table entries for texts with that tag, e.g.
+	// "legal"; pairs without one use the default route.
+	Tag string
+	// Premium translates with the premium backend (see premiumBackend),
+	// falling back to the Lambda fleet when it fails.
+	Premium bool
+	// Formality is FormalityFormal or FormalityInformal for backends that
+	// support it; "" for their default.
+	Formality string
+	// Progress, if set, is called with the number of chunks that completed
+	// the route each time some do, possibly from several goroutines.
+	Progress func(chunks int)
+	// DeadLetter keeps translating the other chunks when some fail on a
+	// Lambda route: when the route fails, each chunk runs it again on its
+	// own, and the failed ones are listed in Result.Failed with ""
+	// translations. The route only fails when every chunk does.
+	DeadLetter bool
 }
 
-// TranslatorResponse is the response format from translator Lambdas (chunked mode).
-type TranslatorResponse struct {
-	Translations [][]string `json:"translations"`
-	Error        string     `json:"error,omitempty"`
+// progress reports chunks that completed the route to Options.Progress.
+func (o Options) progress(chunks int) {
+	if o.Progress != nil {
+		o.Progress(chunks)
+	}
+}
+
+// stepFunction returns the Lambda a route step invokes after overrides.
+func stepFunction(step routeStep, overrides map[string]string) string {
+	if override, ok := overrides[step.lambdaName]; ok {
+		return override
+	}
+	return step.lambdaName
 }
 
+// Result is the outcome of translating a set of chunks.
+type Result struct {
+	// Translations has the same shape as the input chunks.
+	Translations [][]string
+	// Scores holds, per text, the sum over the route steps of their
+	// length-normalized log-probabilities (TranslatorResponse.Scores), so a
+	// two-step pivot scores the product of the steps' per-token
+	// probabilities. It is nil unless scores were requested and every step
+	// returned them.
+	Scores [][]float64
+	// Steps lists the translator Lambdas invoked, in order.
+	Steps []string
+	// PivotLang is the first intermediate language of multi-step routes,
+	// and Pivots lists all of them in order.
+	PivotLang string
+	Pivots    []string
+	// Intermediate holds the translations into each of Pivots, in the
+	// shape of the input chunks.
+	Intermediate [][][]string
+	// ModelVersions holds the model version reported by each step ("" if unknown).
+	ModelVersions []string
+	// Dispatches holds the dispatch strategy of each step (DispatchSingle,
+	// DispatchFanOut or DispatchPipelined), and Durations how long each step
+	// took.
+	Dispatches []string
+	Durations  []time.Duration
+	// Entry is the routing table entry that chose the route, if any.
+	Entry *routing.Entry
+	// Cost is the estimated cost of the route under the cost model.
+	Cost float64
+	// TagSteps lists, per tag, the steps of the texts translated apart from
+	// the rest of the batch with Options.Tag.
+	TagSteps map[string][]string
+	// Fallback is set when the premium backend failed and the Lambda fleet
+	// translated instead.
+	Fallback *BackendFallback
+	// Failed lists the chunks that failed under Options.DeadLetter, in
+	// chunk order.
+	Failed []ChunkFailure
+}
+
+// pivotLang is the hub language of multi-step routes, unless the pair has
+// another pivot (PIVOT_LANGUAGES).
+const pivotLang = "en"
+
 // New creates a new Router.
 func New(ctx context.Context) (*Router, error) {
 	cfg, err := config.LoadDefaultConfig(ctx)
@@ -95,10 +293,91 @@ func New(ctx context.Context) (*Router, error) {
 		env = "dev"
 	}
 
-	return &Router{
-		lambdaClient: lambda.NewFromConfig(cfg),
-		environment:  env,
-	}, nil
+	format, err := payloadFormat()
+	if err != nil {
+		return nil, err
+	}
+	functions, err := loadFunctions(env)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := fanOutThreshold()
+	if err != nil {
+		return nil, err
+	}
+	retry, err := loadRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+	costs, err := loadCosts(env)
+	if err != nil {
+		return nil, err
+	}
+	chunkBudgets, err := loadChunkTokens(env)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := translatorLimits(env)
+	if err != nil {
+		return nil, err
+	}
+	backends, external, err := loadBackends(ctx, cfg, retry)
+	if err != nil {
+		return nil, err
+	}
+	// Bedrock chunks must fit the output tokens of its model
+	if bedrock, ok := external[BackendBedrock].(*Bedrock); ok && chunkBudgets[BackendBedrock] == 0 {
+		chunkBudgets[BackendBedrock] = bedrock.ChunkTokens()
+	}
+
+	r := &Router{
+		// Invocations are retried by the router's own policy (see invoke)
+		lambdaClient: lambda.NewFromConfig(cfg, func(o *lambda.Options) {
+			o.Retryer = aws.NopRetryer{}
+		}),
+		environment:      env,
+		payloadFormat:    format,
+		functions:        functions,
+		chunkIDNamespace: chunkIDNamespace(),
+		fanOutThreshold:  threshold,
+		retry:            retry,
+		costs:            costs,
+		chunkBudgets:     chunkBudgets,
+		limits:           limits,
+		backendConfig:    backends,
+		backends:         external,
+		dryRun:           env == EnvLocal,
+		pipelinedRoutes:  pipelinedRoutes(),
+	}
+	// The local environment has no routing table to read
+	if !r.dryRun {
+		r.routes = runtimeRoutes(cfg)
+	}
+	if r.weights, err = loadRouteWeights(); err != nil {
+		return nil, err
+	}
+	if r.pivotChains, err = r.loadPivots(); err != nil {
+		return nil, err
+	}
+
+	switch mode := os.Getenv(InvocationEnv); mode {
+	case "", InvocationSync:
+	case InvocationEvent:
+		bucket := os.Getenv(resultstore.BucketEnv)
+		if bucket == "" {
+			return nil, fmt.Errorf("%s=%s requires %s", InvocationEnv, mode, resultstore.BucketEnv)
+		}
+		interval, err := pollInterval()
+		if err != nil {
+			return nil, err
+		}
+		r.results = resultstore.New(s3.NewFromConfig(cfg), bucket, resultsPrefix)
+		r.pollInterval = interval
+	default:
+		return nil, fmt.Errorf("invalid %s %q: want %s or %s", InvocationEnv, mode, InvocationSync, InvocationEvent)
+	}
+
+	return r, nil
 }
 
 // IsValidPair checks if a language pair can be translated.
@@ -106,6 +385,11 @@ func (r *Router) IsValidPair(source, target string) bool {
 	return supportedLanguages[source] && supportedLanguages[target] && source != target
 }
 
+// IsSupportedLanguage reports whether code is a canonical supported language code.
+func IsSupportedLanguage(code string) bool {
+	return supportedLanguages[code]
+}
+
 // GetSupportedLanguages returns a list of all supported language codes.
 func GetSupportedLanguages() []string {
 	langs := make([]string, 0, len(supportedLanguages))
@@ -115,151 +399,342 @@ func GetSupportedLanguages() []string {
 	return langs
 }
 
-// getRoute determines which Lambda(s) to call for a translation.
-// Returns a list of (lambdaName, targetLang) pairs to execute in sequence.
-// targetLang is only set for en-romance Lambda.
-func (r *Router) getRoute(source, target string) []struct {
-	lambdaName string
-	targetLang string
-} {
-	// Direct to English
-	if target == "en" {
-		if romanceLanguages[source] {
-			return []struct {
-				lambdaName string
-				targetLang string
-			}{
-				{lambdaName: "pricofy-translator-romance-en", targetLang: ""},
-			}
-		}
-		if source == "de" {
-			return []struct {
-				lambdaName string
-				targetLang string
-			}{
-				{lambdaName: "pricofy-translator-de-en", targetLang: ""},
-			}
-		}
-	}
+// RoutePlan describes the route a pair would take.
+type RoutePlan struct {
+	Steps     []string
+	PivotLang string
+	Pivots    []string
+	// MaxTokens is the token budget of a chunk configured for the steps
+	// (TRANSLATOR_CHUNK_TOKENS, or the model's for Bedrock), 0 for the
+	// default.
+	MaxTokens int
+	// Premium is the backend of premium-quality requests, "" when none is
+	// configured.
+	Premium string
+}
 
-	// From English
-	if source == "en" {
-		if romanceLanguages[target] {
-			return []struct {
-				lambdaName string
-				targetLang string
-			}{
-				{lambdaName: "pricofy-translator-en-romance", targetLang: target},
-			}
-		}
-		if target == "de" {
-			return []struct {
-				lambdaName string
-				targetLang string
-			}{
-				{lambdaName: "pricofy-translator-en-de", targetLang: ""},
-			}
+// Plan returns the translator functions a pair would be routed through
+// with backend ("" for the configured choice), without invoking them.
+// Backends other than the Lambda fleet are a single step named after them.
+func (r *Router) Plan(source, target, backend string) (*RoutePlan, error) {
+	if name := r.backend(source, target, backend); name != BackendLambda {
+		if !r.CanTranslate(source, target, name) {
+			return nil, fmt.Errorf("unsupported language pair: %s-%s", source, target)
 		}
+		return &RoutePlan{Steps: []string{name}, MaxTokens: r.chunkTokens([]string{name}), Premium: r.premiumBackend()}, nil
 	}
-
-	// Romance to Romance (pivot through EN)
-	if romanceLanguages[source] && romanceLanguages[target] {
-		return []struct {
-			lambdaName string
-			targetLang string
-		}{
-			{lambdaName: "pricofy-translator-romance-en", targetLang: ""},
-			{lambdaName: "pricofy-translator-en-romance", targetLang: target},
-		}
+	route := r.getRoute(source, target)
+	if route == nil {
+		return nil, fmt.Errorf("unsupported language pair: %s-%s", source, target)
+	}
+	plan := &RoutePlan{Steps: make([]string, len(route)), Premium: r.premiumBackend()}
+	for i, step := range route {
+		plan.Steps[i] = step.lambdaName
+	}
+	plan.MaxTokens = r.chunkTokens(plan.Steps)
+	if len(route) > 1 {
+		plan.Pivots, _ = r.pivots(source, target)
+		plan.PivotLang = plan.Pivots[0]
 	}
+	return plan, nil
+}
 
-	// Romance to German (pivot through EN)
-	if romanceLanguages[source] && target == "de" {
-		return []struct {
-			lambdaName string
-			targetLang string
-		}{
-			{lambdaName: "pricofy-translator-romance-en", targetLang: ""},
-			{lambdaName: "pricofy-translator-en-de", targetLang: ""},
-		}
+// languageGroup is a family of languages served by one pair of translators
+// to and from English.
+type languageGroup struct {
+	toEnglish   string
+	fromEnglish string
+	// multilingual translators from English need the target language.
+	multilingual bool
+}
+
+// Language groups by their translators.
+var (
+	romanceGroup = &languageGroup{toEnglish: TranslatorRomanceEn, fromEnglish: TranslatorEnRomance, multilingual: true}
+	germanGroup  = &languageGroup{toEnglish: TranslatorDeEn, fromEnglish: TranslatorEnDe}
+	slavicGroup  = &languageGroup{toEnglish: TranslatorSlavicEn, fromEnglish: TranslatorEnSlavic, multilingual: true}
+	nordicGroup  = &languageGroup{toEnglish: TranslatorNordicEn, fromEnglish: TranslatorEnNordic, multilingual: true}
+)
+
+// groupOf returns the language group of a non-English language, or nil.
+func groupOf(lang string) *languageGroup {
+	switch {
+	case romanceLanguages[lang]:
+		return romanceGroup
+	case slavicLanguages[lang]:
+		return slavicGroup
+	case nordicLanguages[lang]:
+		return nordicGroup
+	case lang == "de":
+		return germanGroup
 	}
+	return nil
+}
 
-	// German to Romance (pivot through EN)
-	if source == "de" && romanceLanguages[target] {
-		return []struct {
-			lambdaName string
-			targetLang string
-		}{
-			{lambdaName: "pricofy-translator-de-en", targetLang: ""},
-			{lambdaName: "pricofy-translator-en-romance", targetLang: target},
-		}
+// toEnglish is the route step translating a group into English.
+func (r *Router) toEnglish(g *languageGroup) routeStep {
+	return routeStep{lambdaName: r.function(g.toEnglish)}
+}
+
+// fromEnglish is the route step translating English into target.
+func (r *Router) fromEnglish(g *languageGroup, target string) routeStep {
+	step := routeStep{lambdaName: r.function(g.fromEnglish)}
+	if g.multilingual {
+		step.targetLang = target
 	}
+	return step
+}
 
-	return nil
+// getRoute determines which Lambda(s) to call for a translation.
+// Returns a list of (lambdaName, targetLang) pairs to execute in sequence,
+// one per hop (see hop) through the pair's pivot languages (see pivots):
+// the configured ones, else the lightest path of translators, which is a
+// single hop when a translator serves the pair and English otherwise
+// unless ROUTE_WEIGHTS say so. targetLang is set for multilingual
+// translators from English (en-romance, en-sla, en-gmq) and direct
+// translators.
+func (r *Router) getRoute(source, target string) []routeStep {
+	pivots, ok := r.pivots(source, target)
+	if !ok {
+		return nil
+	}
+	return r.routeThrough(source, pivots, target)
 }
 
 // TranslateChunks translates all chunks using the appropriate Lambda(s).
-// For pairs that don't involve English, chains two Lambda calls.
+// For pairs that don't involve English, chains a Lambda call per hop.
 func (r *Router) TranslateChunks(ctx context.Context, source, target string, chunks [][]string) ([][]string, error) {
-	if len(chunks) == 0 {
-		return [][]string{}, nil
+	result, err := r.TranslateChunksWithOptions(ctx, source, target, chunks, Options{})
+	if err != nil {
+		return nil, err
 	}
+	return result.Translations, nil
+}
 
-	route := r.getRoute(source, target)
+// TranslateChunksWithOptions is TranslateChunks with per-call options.
+func (r *Router) TranslateChunksWithOptions(ctx context.Context, source, target string, chunks [][]string, opts Options) (*Result, error) {
+	if len(chunks) == 0 {
+		return &Result{Translations: [][]string{}}, nil
+	}
+	name, route, entry := r.route(ctx, source, target, opts)
+	if name != BackendLambda && opts.Premium && entry == nil {
+		return r.translatePremium(ctx, name, source, target, chunks, opts)
+	}
+	if name != BackendLambda {
+		return r.translateWith(ctx, name, source, target, chunks, opts, entry)
+	}
 	if route == nil {
 		return nil, fmt.Errorf("unsupported language pair: %s-%s", source, target)
 	}
+	overrides, cost, err := r.budget(route, chunks, opts.FunctionOverrides, opts.MaxCost)
+	if err != nil {
+		return nil, err
+	}
+	opts.FunctionOverrides = overrides
 
-	// Execute each step in the route
+	if opts.PrewarmNextHop {
+		r.prewarm(ctx, route, opts.FunctionOverrides)
+	}
+
+	var result *Result
+	switch {
+	case r.pipelines(route, chunks):
+		result, err = r.translatePipelined(ctx, route, chunks, opts)
+	case opts.DeadLetter:
+		result, err = r.translateDeadLettered(ctx, route, chunks, opts)
+	default:
+		result, err = r.translateStaged(ctx, route, chunks, opts)
+	}
+	if err != nil {
+		if entry != nil {
+			return nil, &EntryError{Entry: entry, Err: err}
+		}
+		return nil, err
+	}
+	result.Entry, result.Cost = entry, cost
+	if len(route) > 1 {
+		result.Pivots, _ = r.pivots(source, target)
+		result.PivotLang = result.Pivots[0]
+	}
+	return result, nil
+}
+
+// translateStaged runs the steps of a route one after the other, each over
+// all chunks within its share of the time left.
+func (r *Router) translateStaged(ctx context.Context, route []routeStep, chunks [][]string, opts Options) (*Result, error) {
 	currentChunks := chunks
+	var scores [][]float64
+	var intermediate [][][]string
+	steps := make([]string, 0, len(route))
+	versions := make([]string, 0, len(route))
+	dispatches := make([]string, 0, len(route))
+	durations := make([]time.Duration, 0, len(route))
 	for i, step := range route {
-		result, err := r.invokeLambda(ctx, step.lambdaName, step.targetLang, currentChunks)
+		functionName := stepFunction(step, opts.FunctionOverrides)
+		stepCtx, budget, cancel := stepBudget(ctx, len(route)-i)
+		resp, dispatch, elapsed, err := r.dispatch(stepCtx, functionName, step.targetLang, currentChunks, opts.ReturnScores)
+		err = stepTimeout(stepCtx, err, i+1, len(route), functionName, budget)
+		cancel()
+		if err == nil {
+			err = checkShape(functionName, currentChunks, resp)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("step %d (%s) failed: %w", i+1, step.lambdaName, err)
+			return nil, &StepError{Step: i + 1, Steps: len(route), Function: functionName, Err: err}
 		}
-		currentChunks = result
+		if opts.ReturnScores {
+			scores = addScores(scores, resp.Scores, i == 0)
+		}
+		currentChunks = resp.Translations
+		if i < len(route)-1 {
+			intermediate = append(intermediate, currentChunks)
+		}
+		steps = append(steps, functionName)
+		versions = append(versions, resp.ModelVersion)
+		dispatches = append(dispatches, dispatch)
+		durations = append(durations, elapsed)
 	}
 
-	return currentChunks, nil
+	opts.progress(len(chunks))
+	return &Result{
+		Translations:  currentChunks,
+		Scores:        scores,
+		Intermediate:  intermediate,
+		Steps:         steps,
+		ModelVersions: versions,
+		Dispatches:    dispatches,
+		Durations:     durations,
+	}, nil
 }
 
-// invokeLambda calls a translator Lambda with the given chunks.
-func (r *Router) invokeLambda(ctx context.Context, functionName, targetLang string, chunks [][]string) ([][]string, error) {
+// addScores adds the length-normalized log-probabilities of one route step
+// to total.
+// A step without scores makes the whole route unscored.
+func addScores(total, step [][]float64, first bool) [][]float64 {
+	if step == nil || (!first && total == nil) {
+		return nil
+	}
+	if first {
+		return step
+	}
+	for i := range total {
+		for j := range total[i] {
+			total[i][j] += step[i][j]
+		}
+	}
+	return total
+}
+
+// invokeLambda calls a translator Lambda with the given chunks. Chunks
+// whose request or expected response would exceed the Lambda payload limit
+// are split across several invocations.
+func (r *Router) invokeLambda(ctx context.Context, functionName, targetLang string, chunks [][]string, returnScores bool) (*TranslatorResponse, error) {
+	if len(chunks) > 1 && r.oversized(chunks) {
+		return r.invokeSplit(ctx, functionName, targetLang, chunks, returnScores)
+	}
+	resp, err := r.invokeOnce(ctx, functionName, targetLang, chunks, returnScores)
+	var tooLarge *PayloadError
+	if len(chunks) > 1 && errors.As(err, &tooLarge) {
+		return r.invokeSplit(ctx, functionName, targetLang, chunks, returnScores)
+	}
+	return resp, err
+}
+
+// invokeOnce calls a translator Lambda with the given chunks in one
+// invocation, traced as an X-Ray subsegment that the translator's own trace
+// joins. The call waits for the function's load limit first.
+func (r *Router) invokeOnce(ctx context.Context, functionName, targetLang string, chunks [][]string, returnScores bool) (resp *TranslatorResponse, err error) {
+	release, err := r.acquire(ctx, functionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, seg := tracing.Start(ctx, functionName)
+	seg.Remote("Invoke", map[string]string{"function_name": functionName})
+	defer func() { seg.End(err) }()
+
 	// Prepare request
 	req := TranslatorRequest{
-		Chunks:     chunks,
-		TargetLang: targetLang,
+		Chunks:       chunks,
+		TargetLang:   targetLang,
+		ReturnScores: returnScores,
 	}
-	payload, err := json.Marshal(req)
+	req.ChunkIDs = chunkIDs(r.chunkIDNamespace, functionName, req)
+	if r.results != nil {
+		return r.invokeEvent(ctx, functionName, req)
+	}
+
+	payload, err := r.encodeRequest(functionName, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	if err := checkPayload(functionName, payload, maxSyncPayload); err != nil {
+		return nil, err
+	}
 
 	// Invoke Lambda
-	result, err := r.lambdaClient.Invoke(ctx, &lambda.InvokeInput{
+	result, err := r.invoke(ctx, &lambda.InvokeInput{
 		FunctionName: &functionName,
 		Payload:      payload,
 	})
 	if err != nil {
+		if tooLarge := payloadExceeded(functionName, err, nil); tooLarge != nil {
+			return nil, tooLarge
+		}
 		return nil, fmt.Errorf("failed to invoke %s: %w", functionName, err)
 	}
 
 	// Check for Lambda errors
 	if result.FunctionError != nil {
+		if tooLarge := payloadExceeded(functionName, nil, result.Payload); tooLarge != nil {
+			return nil, tooLarge
+		}
 		return nil, fmt.Errorf("lambda error: %s", *result.FunctionError)
 	}
 
-	// Parse response
-	var resp TranslatorResponse
-	if err := json.Unmarshal(result.Payload, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	resp, err = parseTranslatorResponse(functionName, result.Payload)
+	if err != nil {
+		return nil, err
+	}
+	recordFormats(functionName, resp.Formats)
+	return resp, nil
+}
+
+// parseTranslatorResponse decodes the payload of translator functionName.
+// A payload that does not match the response schema is a *SchemaError.
+// Scores that do not line up with the translations are dropped rather than
+// trusted.
+func parseTranslatorResponse(functionName string, payload []byte) (*TranslatorResponse, error) {
+	resp, err := decodeResponse(payload)
+	if err != nil {
+		return nil, responseSchemaError(functionName, payload, err)
 	}
 
 	if resp.Error != "" {
 		return nil, fmt.Errorf("translator error: %s", resp.Error)
 	}
+	if resp.Translations == nil {
+		return nil, missingTranslations(functionName, payload)
+	}
+
+	if !sameShape(resp.Translations, resp.Scores) {
+		resp.Scores = nil
+	}
 
-	return resp.Translations, nil
+	return resp, nil
+}
+
+// sameShape reports whether scores has exactly one entry per translation.
+func sameShape(translations [][]string, scores [][]float64) bool {
+	if len(scores) != len(translations) {
+		return false
+	}
+	for i := range translations {
+		if len(scores[i]) != len(translations[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 // Translate is a convenience method for translating a single batch (no chunking).
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -40,12 +41,25 @@ func TestIsValidPair(t *testing.T) {
 		// Portuguese variants
 		{"pt_BR", "en", true},
 		{"en", "pt_PT", true},
+		// Slavic languages
+		{"pl", "en", true},
+		{"en", "cs", true},
+		{"uk", "de", true}, // Ukrainian to German via EN
+		{"ru", "es", true}, // Russian to Spanish via EN
+		{"pl", "cs", true}, // Slavic to Slavic via EN
+		// Nordic languages
+		{"sv", "en", true},
+		{"en", "da", true},
+		{"nb", "es", true}, // Norwegian to Spanish via EN
+		{"fr", "sv", true}, // French to Swedish via EN
+		{"da", "sv", true}, // Nordic to Nordic via EN
 		// Invalid pairs
 		{"es", "es", false}, // Same language
+		{"pl", "pl", false}, // Same language
 		{"xx", "yy", false}, // Unknown languages
 		{"es", "", false},   // Empty target
 		{"", "fr", false},   // Empty source
-		{"ru", "es", false}, // Unsupported language (Russian)
+		{"hu", "es", false}, // Unsupported language (Hungarian)
 		{"zh", "en", false}, // Unsupported language (Chinese)
 		{"nl", "en", false}, // Unsupported language (Dutch)
 		{"de", "de", false}, // Same language
@@ -103,6 +117,18 @@ func TestGetRoute(t *testing.T) {
 		{"de", "fr", 2, "pricofy-translator-de-en"},
 		{"de", "ca", 2, "pricofy-translator-de-en"},
 		{"de", "ro", 2, "pricofy-translator-de-en"},
+		// Slavic (1 step to/from English, 2 steps otherwise)
+		{"pl", "en", 1, "pricofy-translator-sla-en"},
+		{"uk", "en", 1, "pricofy-translator-sla-en"},
+		{"en", "cs", 1, "pricofy-translator-en-sla"},
+		{"pl", "es", 2, "pricofy-translator-sla-en"},
+		{"de", "uk", 2, "pricofy-translator-de-en"},
+		{"cs", "pl", 2, "pricofy-translator-sla-en"},
+		// Nordic (1 step to/from English, 2 steps otherwise)
+		{"sv", "en", 1, "pricofy-translator-gmq-en"},
+		{"en", "no", 1, "pricofy-translator-en-gmq"},
+		{"da", "it", 2, "pricofy-translator-gmq-en"},
+		{"pt", "sv", 2, "pricofy-translator-romance-en"},
 	}
 
 	for _, tt := range tests {
@@ -191,13 +217,33 @@ func TestSupportedLanguages(t *testing.T) {
 	}
 
 	// Verify unsupported languages
-	unsupported := []string{"ru", "zh", "ja", "nl", "pl", ""}
+	unsupported := []string{"zh", "ja", "nl", "hu", "fi", ""}
 	for _, lang := range unsupported {
 		if supportedLanguages[lang] {
 			t.Errorf("Language %q should not be supported", lang)
 		}
 	}
 
+	// Verify Slavic languages
+	for _, lang := range []string{"pl", "cs", "uk", "ru", "sk", "bg", "hr", "sr"} {
+		if !slavicLanguages[lang] || !supportedLanguages[lang] {
+			t.Errorf("Slavic language %q should be supported", lang)
+		}
+		if romanceLanguages[lang] {
+			t.Errorf("Slavic language %q should not be in romanceLanguages", lang)
+		}
+	}
+
+	// Verify Nordic languages
+	for _, lang := range []string{"sv", "da", "no", "nb", "nn", "is"} {
+		if !nordicLanguages[lang] || !supportedLanguages[lang] {
+			t.Errorf("Nordic language %q should be supported", lang)
+		}
+		if romanceLanguages[lang] || slavicLanguages[lang] {
+			t.Errorf("Nordic language %q should not be in another group", lang)
+		}
+	}
+
 	// German and English should NOT be in romanceLanguages
 	if romanceLanguages["de"] {
 		t.Error("German should not be in romanceLanguages")
@@ -207,6 +253,15 @@ func TestSupportedLanguages(t *testing.T) {
 	}
 }
 
+func TestIsSupportedLanguage(t *testing.T) {
+	tests := map[string]bool{"es": true, "pt_BR": true, "de": true, "es_419": false, "pt-BR": false, "": false}
+	for code, want := range tests {
+		if got := IsSupportedLanguage(code); got != want {
+			t.Errorf("IsSupportedLanguage(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
 func TestGetSupportedLanguages(t *testing.T) {
 	langs := GetSupportedLanguages()
 
@@ -256,3 +311,140 @@ func TestTranslateChunks_EmptyInput(t *testing.T) {
 		t.Errorf("TranslateChunks with empty input should return empty slice, got %d items", len(result))
 	}
 }
+
+func TestTranslateChunks_Intermediate(t *testing.T) {
+	chunks := [][]string{{"hola", "adiós"}}
+
+	tests := []struct {
+		name   string
+		target string
+		want   [][][]string
+	}{
+		{"pivot", "de", [][][]string{{{"hola", "adiós"}}}},
+		{"direct", "en", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{lambdaClient: &recordingTranslator{}}
+			result, err := r.TranslateChunksWithOptions(context.Background(), "es", tt.target, chunks, Options{})
+			if err != nil {
+				t.Fatalf("TranslateChunksWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Intermediate, tt.want) {
+				t.Errorf("Intermediate = %q, want %q", result.Intermediate, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTranslatorResponse_Scores(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantScores bool
+		wantErr    bool
+	}{
+		{"no scores", `{"translations": [["a", "b"]]}`, false, false},
+		{"matching scores", `{"translations": [["a", "b"]], "scores": [[-0.1, -0.2]]}`, true, false},
+		{"mismatched scores dropped", `{"translations": [["a", "b"]], "scores": [[-0.1]]}`, false, false},
+		{"translator error", `{"error": "model not loaded"}`, false, true},
+		{"invalid json", `not json`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := parseTranslatorResponse("translator", []byte(tt.payload))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parseTranslatorResponse() should have returned error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTranslatorResponse() unexpected error: %v", err)
+			}
+			if (resp.Scores != nil) != tt.wantScores {
+				t.Errorf("parseTranslatorResponse() scores = %v, want present=%v", resp.Scores, tt.wantScores)
+			}
+		})
+	}
+}
+
+func TestAddScores(t *testing.T) {
+	first := addScores(nil, [][]float64{{-0.5, -1}}, true)
+	total := addScores(first, [][]float64{{-0.25, -0.5}}, false)
+
+	if total[0][0] != -0.75 || total[0][1] != -1.5 {
+		t.Errorf("addScores() = %v, want [[-0.75 -1.5]]", total)
+	}
+
+	if addScores(total, nil, false) != nil {
+		t.Error("addScores() should drop scores when a step has none")
+	}
+	if addScores(nil, [][]float64{{-1}}, false) != nil {
+		t.Error("addScores() should not resurrect scores after an unscored step")
+	}
+}
+
+func TestGetRoute_Slavic(t *testing.T) {
+	r := &Router{}
+
+	tests := []struct {
+		source string
+		target string
+		want   []routeStep
+	}{
+		{"en", "pl", []routeStep{{lambdaName: "pricofy-translator-en-sla", targetLang: "pl"}}},
+		{"cs", "en", []routeStep{{lambdaName: "pricofy-translator-sla-en"}}},
+		{"uk", "de", []routeStep{
+			{lambdaName: "pricofy-translator-sla-en"},
+			{lambdaName: "pricofy-translator-en-de"},
+		}},
+		{"es", "uk", []routeStep{
+			{lambdaName: "pricofy-translator-romance-en"},
+			{lambdaName: "pricofy-translator-en-sla", targetLang: "uk"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"→"+tt.target, func(t *testing.T) {
+			if got := r.getRoute(tt.source, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRoute(%q, %q) = %+v, want %+v", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRoute_Nordic(t *testing.T) {
+	r := &Router{}
+
+	tests := []struct {
+		source string
+		target string
+		want   []routeStep
+	}{
+		{"en", "sv", []routeStep{{lambdaName: "pricofy-translator-en-gmq", targetLang: "sv"}}},
+		{"da", "en", []routeStep{{lambdaName: "pricofy-translator-gmq-en"}}},
+		{"sv", "es", []routeStep{
+			{lambdaName: "pricofy-translator-gmq-en"},
+			{lambdaName: "pricofy-translator-en-romance", targetLang: "es"},
+		}},
+		{"fr", "nb", []routeStep{
+			{lambdaName: "pricofy-translator-romance-en"},
+			{lambdaName: "pricofy-translator-en-gmq", targetLang: "nb"},
+		}},
+		{"no", "da", []routeStep{
+			{lambdaName: "pricofy-translator-gmq-en"},
+			{lambdaName: "pricofy-translator-en-gmq", targetLang: "da"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"→"+tt.target, func(t *testing.T) {
+			if got := r.getRoute(tt.source, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRoute(%q, %q) = %+v, want %+v", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
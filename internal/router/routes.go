@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// routeStep is one translator invocation of a route.
+type routeStep = struct {
+	lambdaName string
+	targetLang string
+}
+
+// The runtime routing table is cached once per Lambda container.
+var (
+	routesOnce sync.Once
+	routes     *routing.Cache
+)
+
+// runtimeRoutes returns the container-wide routing table cache, or nil when
+// ROUTING_TABLE is not set.
+func runtimeRoutes(cfg aws.Config) *routing.Cache {
+	routesOnce.Do(func() {
+		if table := os.Getenv(routing.TableEnv); table != "" {
+			routes = routing.NewCache(routing.NewStore(dynamodb.NewFromConfig(cfg), table))
+		}
+	})
+	return routes
+}
+
+// InvalidateRoutes makes this container reload the routing table on the
+// next translation, after an admin change.
+func InvalidateRoutes() {
+	if routes != nil {
+		routes.Invalidate()
+	}
+}
+
+// EntryError is a translation failure of a route chosen from the routing
+// table, so callers can attribute it to the entry.
+type EntryError struct {
+	Entry *routing.Entry
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// candidates returns the routing table entries for source → target and
+// tag, or nil.
+func (r *Router) candidates(ctx context.Context, source, target, tag string) []*routing.Entry {
+	if r.routes == nil {
+		return nil
+	}
+	return routing.Candidates(r.routes.Entries(ctx), source, target, tag)
+}
+
+// routeChoice is what translates a pair: one of entries, split by weight,
+// or else backend.
+type routeChoice struct {
+	entries []*routing.Entry
+	backend string
+}
+
+// choose returns what translates source → target. The entries of the
+// routing table for opts.Tag come first unless the caller forces a
+// backend; then the premium backend for premium requests, the backend
+// configured for the pair, the untagged entries and the built-in route.
+func (r *Router) choose(ctx context.Context, source, target string, opts Options) routeChoice {
+	if opts.Tag != "" && opts.Backend == "" {
+		if entries := r.candidates(ctx, source, target, opts.Tag); entries != nil {
+			return routeChoice{entries: entries}
+		}
+	}
+	if opts.Premium && opts.Backend == "" {
+		if name := r.premiumBackend(); name != "" {
+			return routeChoice{backend: name}
+		}
+	}
+	if name := r.backend(source, target, opts.Backend); name != BackendLambda {
+		return routeChoice{backend: name}
+	}
+	if entries := r.candidates(ctx, source, target, ""); entries != nil {
+		return routeChoice{entries: entries}
+	}
+	return routeChoice{backend: BackendLambda}
+}
+
+// route returns how to translate source → target: with a non-Lambda
+// backend (route nil), or with the steps of route (see choose). Table
+// translators always receive the target language.
+func (r *Router) route(ctx context.Context, source, target string, opts Options) (string, []routeStep, *routing.Entry) {
+	choice := r.choose(ctx, source, target, opts)
+	switch {
+	case choice.entries != nil:
+		return entryRoute(routing.Split(choice.entries, rand.Float64()), target) // #nosec G404 -- traffic split, not security
+	case choice.backend != BackendLambda:
+		return choice.backend, nil, nil
+	}
+	return BackendLambda, r.getRoute(source, target), nil
+}
+
+// Backends returns every backend translating source → target with opts
+// may send texts to: those of the routing table entries it splits
+// between, or the one it uses (see choose). BackendLambda is the
+// translator fleet.
+func (r *Router) Backends(ctx context.Context, source, target string, opts Options) []string {
+	choice := r.choose(ctx, source, target, opts)
+	if choice.entries == nil {
+		return []string{choice.backend}
+	}
+	backends := make([]string, len(choice.entries))
+	for i, entry := range choice.entries {
+		backends[i] = BackendLambda
+		if entry.Backend != "" {
+			backends[i] = entry.Backend
+		}
+	}
+	return backends
+}
+
+// entryRoute returns the route of a routing table entry.
+func entryRoute(entry *routing.Entry, target string) (string, []routeStep, *routing.Entry) {
+	if entry.Backend != "" {
+		return entry.Backend, nil, entry
+	}
+	return BackendLambda, []routeStep{{lambdaName: entry.Target(), targetLang: target}}, entry
+}
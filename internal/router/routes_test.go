@@ -0,0 +1,114 @@
+package router
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+// scanTable is a routing table that only supports listing its items.
+type scanTable struct {
+	routing.Table
+	items []map[string]types.AttributeValue
+}
+
+func (s scanTable) Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return &dynamodb.ScanOutput{Items: s.items}, nil
+}
+
+func routeItem(id, source, target, function string, enabled bool) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":         &types.AttributeValueMemberS{Value: id},
+		"sourceLang": &types.AttributeValueMemberS{Value: source},
+		"targetLang": &types.AttributeValueMemberS{Value: target},
+		"function":   &types.AttributeValueMemberS{Value: function},
+		"qualifier":  &types.AttributeValueMemberS{Value: "live"},
+		"weight":     &types.AttributeValueMemberN{Value: "1"},
+		"enabled":    &types.AttributeValueMemberBOOL{Value: enabled},
+	}
+}
+
+func TestRouter_RouteTable(t *testing.T) {
+	legal := routeItem("es-en-legal", "es", "en", "pricofy-translator-es-en-legal", true)
+	legal["tag"] = &types.AttributeValueMemberS{Value: "legal"}
+	medical := routeItem("es-en-medical", "es", "en", "", true)
+	delete(medical, "function")
+	delete(medical, "qualifier")
+	medical["tag"] = &types.AttributeValueMemberS{Value: "medical-claims"}
+	medical["backend"] = &types.AttributeValueMemberS{Value: BackendDeepL}
+	table := scanTable{items: []map[string]types.AttributeValue{
+		routeItem("es-fr", "es", "fr", "pricofy-translator-es-fr", true),
+		routeItem("es-en", "es", "en", "pricofy-translator-es-en", false),
+		legal,
+		medical,
+	}}
+	r := &Router{routes: routing.NewCache(routing.NewStore(table, "routes"))}
+
+	tests := []struct {
+		name           string
+		source, target string
+		opts           Options
+		wantBackend    string
+		want           []routeStep
+	}{
+		{"table entry", "es", "fr", Options{}, BackendLambda, []routeStep{{lambdaName: "pricofy-translator-es-fr:live", targetLang: "fr"}}},
+		{"disabled entry", "es", "en", Options{}, BackendLambda, []routeStep{{lambdaName: "pricofy-translator-romance-en"}}},
+		{"tagged entry", "es", "en", Options{Tag: "legal"}, BackendLambda, []routeStep{{lambdaName: "pricofy-translator-es-en-legal:live", targetLang: "en"}}},
+		{"tagged backend", "es", "en", Options{Tag: "medical-claims"}, BackendDeepL, nil},
+		{"tag without entry", "es", "fr", Options{Tag: "legal"}, BackendLambda, []routeStep{{lambdaName: "pricofy-translator-es-fr:live", targetLang: "fr"}}},
+		{"forced backend", "es", "en", Options{Tag: "legal", Backend: BackendAWSTranslate}, BackendAWSTranslate, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, got, _ := r.route(context.Background(), tt.source, tt.target, tt.opts)
+			if backend != tt.wantBackend || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("route(%s→%s) = %s %+v, want %s %+v", tt.source, tt.target, backend, got, tt.wantBackend, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouter_Backends(t *testing.T) {
+	deepl := routeItem("es-en-deepl", "es", "en", "", true)
+	delete(deepl, "function")
+	delete(deepl, "qualifier")
+	deepl["backend"] = &types.AttributeValueMemberS{Value: BackendDeepL}
+	medical := routeItem("es-fr-medical", "es", "fr", "", true)
+	delete(medical, "function")
+	delete(medical, "qualifier")
+	medical["tag"] = &types.AttributeValueMemberS{Value: "medical-claims"}
+	medical["backend"] = &types.AttributeValueMemberS{Value: BackendDeepL}
+	table := scanTable{items: []map[string]types.AttributeValue{
+		routeItem("es-en", "es", "en", "pricofy-translator-es-en", true),
+		deepl,
+		medical,
+	}}
+	r := &Router{routes: routing.NewCache(routing.NewStore(table, "routes")), backends: map[string]Translator{BackendDeepL: &upperTranslator{}}}
+
+	tests := []struct {
+		name           string
+		source, target string
+		opts           Options
+		want           []string
+	}{
+		{"split with a deepl entry", "es", "en", Options{}, []string{BackendLambda, BackendDeepL}},
+		{"tagged deepl entry", "es", "fr", Options{Tag: "medical-claims"}, []string{BackendDeepL}},
+		{"untagged", "es", "fr", Options{}, []string{BackendLambda}},
+		{"premium", "es", "fr", Options{Premium: true}, []string{BackendDeepL}},
+		{"forced backend", "es", "fr", Options{Tag: "medical-claims", Backend: BackendAWSTranslate}, []string{BackendAWSTranslate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Backends(context.Background(), tt.source, tt.target, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Backends(%s→%s) = %v, want %v", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
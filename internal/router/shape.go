@@ -0,0 +1,35 @@
+package router
+
+import "fmt"
+
+// ShapeError reports a translator response that does not line up with its
+// request: a missing or extra chunk, or a chunk with a different number of
+// translations than texts. Results are never realigned, since translation i
+// must always answer text i.
+type ShapeError struct {
+	Function string
+	// Chunk is the offending chunk, -1 when the chunk count differs.
+	Chunk     int
+	Want, Got int
+}
+
+func (e *ShapeError) Error() string {
+	if e.Chunk < 0 {
+		return fmt.Sprintf("%s returned %d chunks for %d", e.Function, e.Got, e.Want)
+	}
+	return fmt.Sprintf("%s returned %d translations for the %d texts of chunk %d", e.Function, e.Got, e.Want, e.Chunk)
+}
+
+// checkShape verifies resp has one translation per text of every chunk.
+// Scores are already aligned with the translations (parseTranslatorResponse).
+func checkShape(function string, chunks [][]string, resp *TranslatorResponse) error {
+	if len(resp.Translations) != len(chunks) {
+		return &ShapeError{Function: function, Chunk: -1, Want: len(chunks), Got: len(resp.Translations)}
+	}
+	for i, chunk := range chunks {
+		if got := len(resp.Translations[i]); got != len(chunk) {
+			return &ShapeError{Function: function, Chunk: i, Want: len(chunk), Got: got}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// fixedTranslator answers every invocation with the same translations.
+type fixedTranslator struct {
+	translations [][]string
+}
+
+func (f *fixedTranslator) Invoke(_ context.Context, _ *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	payload, err := json.Marshal(TranslatorResponse{Translations: f.translations})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestTranslateChunks_Shape(t *testing.T) {
+	chunks := [][]string{{"hola", "adiós"}, {"gracias"}}
+
+	tests := []struct {
+		name         string
+		translations [][]string
+		wantChunk    int
+	}{
+		{"missing chunk", [][]string{{"hello", "goodbye"}}, -1},
+		{"extra chunk", [][]string{{"hello", "goodbye"}, {"thanks"}, {"extra"}}, -1},
+		{"missing translation", [][]string{{"hello"}, {"thanks"}}, 0},
+		{"extra translation", [][]string{{"hello", "goodbye"}, {"thanks", "extra"}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{lambdaClient: &fixedTranslator{translations: tt.translations}}
+			_, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", chunks, Options{})
+
+			var shapeErr *ShapeError
+			if !errors.As(err, &shapeErr) {
+				t.Fatalf("TranslateChunksWithOptions() error = %v, want a ShapeError", err)
+			}
+			if shapeErr.Chunk != tt.wantChunk {
+				t.Errorf("ShapeError.Chunk = %d, want %d", shapeErr.Chunk, tt.wantChunk)
+			}
+		})
+	}
+
+	r := &Router{lambdaClient: &fixedTranslator{translations: [][]string{{"hello", "goodbye"}, {"thanks"}}}}
+	if _, err := r.TranslateChunksWithOptions(context.Background(), "es", "en", chunks, Options{}); err != nil {
+		t.Errorf("TranslateChunksWithOptions() unexpected error for a well-formed response: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError reports a translation that ran out of its time budget,
+// with how far it got.
+type TimeoutError struct {
+	// Step is the route step (from 1) that ran out of time, and Steps the
+	// number of steps in the route; Step-1 steps completed.
+	Step  int
+	Steps int
+	// Function is the translator of the step.
+	Function string
+	// Budget is the share of the remaining time the step was given.
+	Budget time.Duration
+	Err    error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("step %d of %d (%s) exceeded its %v time budget", e.Step, e.Steps, e.Function, e.Budget.Round(time.Millisecond))
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// StepError reports the route step a translation failed at.
+type StepError struct {
+	// Step is the failed route step (from 1) of Steps, run by Function.
+	Step     int
+	Steps    int
+	Function string
+	Err      error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s) failed: %v", e.Step, e.Function, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// stepBudget derives the context of a step with left steps still to run
+// (itself included), giving it an even share of the time before the
+// deadline of ctx so the first step of a pivot route cannot use up the
+// time of the second. Without a deadline the step is not limited.
+func stepBudget(ctx context.Context, left int) (context.Context, time.Duration, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || left < 1 {
+		return ctx, 0, func() {}
+	}
+	budget := time.Until(deadline) / time.Duration(left)
+	stepCtx, cancel := context.WithTimeout(ctx, budget)
+	return stepCtx, budget, cancel
+}
+
+// stepTimeout wraps err in a *TimeoutError when the step context ran out
+// of time.
+func stepTimeout(stepCtx context.Context, err error, step, steps int, function string, budget time.Duration) error {
+	if err == nil || !errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &TimeoutError{Step: step, Steps: steps, Function: function, Budget: budget, Err: err}
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// stallingTranslator echoes its chunks, except that invocations of stall
+// block until their context is done.
+type stallingTranslator struct {
+	stall string
+}
+
+func (t *stallingTranslator) Invoke(ctx context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	if *params.FunctionName == t.stall {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	var req TranslatorRequest
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(TranslatorResponse{Translations: req.Chunks})
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+func TestTranslateChunks_StepBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		stall     string
+		wantStep  int
+		maxBudget time.Duration
+	}{
+		{
+			name:      "first step gets half",
+			stall:     "pricofy-translator-romance-en",
+			wantStep:  1,
+			maxBudget: 200 * time.Millisecond,
+		},
+		{
+			name:      "second step gets the rest",
+			stall:     "pricofy-translator-en-romance",
+			wantStep:  2,
+			maxBudget: 400 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+			defer cancel()
+			r := &Router{lambdaClient: &stallingTranslator{stall: tt.stall}}
+
+			_, err := r.TranslateChunksWithOptions(ctx, "es", "fr", [][]string{{"hola"}}, Options{})
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("error = %v, want a *TimeoutError", err)
+			}
+			if timeoutErr.Step != tt.wantStep || timeoutErr.Steps != 2 || timeoutErr.Function != tt.stall {
+				t.Errorf("timeout = step %d of %d (%s), want step %d of 2 (%s)", timeoutErr.Step, timeoutErr.Steps, timeoutErr.Function, tt.wantStep, tt.stall)
+			}
+			if timeoutErr.Budget <= 0 || timeoutErr.Budget > tt.maxBudget {
+				t.Errorf("budget = %v, want at most %v", timeoutErr.Budget, tt.maxBudget)
+			}
+			var stepErr *StepError
+			if !errors.As(err, &stepErr) || stepErr.Step != tt.wantStep || stepErr.Function != tt.stall {
+				t.Errorf("error = %v, want a *StepError for step %d", err, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestStepBudget_NoDeadline(t *testing.T) {
+	ctx, budget, cancel := stepBudget(context.Background(), 2)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok || budget != 0 {
+		t.Errorf("stepBudget() = deadline set, budget %v; want no limit", budget)
+	}
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/pricofy/translation-manager/internal/tracing"
+)
+
+// withTraceHeader sends the X-Ray trace of ctx with an invocation, so the
+// translator's segment joins the manager's trace under the current
+// subsegment. Outside a trace it changes nothing.
+func withTraceHeader(ctx context.Context) func(*lambda.Options) {
+	h, ok := tracing.Current(ctx)
+	if !ok {
+		return func(*lambda.Options) {}
+	}
+	header := h.String()
+	return func(o *lambda.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Build.Add(middleware.BuildMiddlewareFunc("TraceHeader", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok {
+					req.Header.Set(tracing.HeaderName, header)
+				}
+				return next.HandleBuild(ctx, in)
+			}), middleware.After)
+		})
+	}
+}
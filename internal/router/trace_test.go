@@ -0,0 +1,56 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// optionsRecorder answers like fixedTranslator and applies the per-call
+// options of every invocation.
+type optionsRecorder struct {
+	fixedTranslator
+	options []lambda.Options
+}
+
+func (o *optionsRecorder) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var opts lambda.Options
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	o.options = append(o.options, opts)
+	return o.fixedTranslator.Invoke(ctx, params)
+}
+
+// hasTraceHeader reports whether opts add the trace header middleware.
+func hasTraceHeader(t *testing.T, opts lambda.Options) bool {
+	t.Helper()
+	stack := middleware.NewStack("invoke", nil)
+	for _, fn := range opts.APIOptions {
+		if err := fn(stack); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, ok := stack.Build.Get("TraceHeader")
+	return ok
+}
+
+func TestInvokeLambda_TraceHeader(t *testing.T) {
+	client := &optionsRecorder{fixedTranslator: fixedTranslator{translations: [][]string{{"hello"}}}}
+	r := &Router{lambdaClient: client}
+
+	traced := context.WithValue(context.Background(), "x-amzn-trace-id", "Root=1-abc;Parent=seg1;Sampled=1") //nolint:staticcheck // the Lambda runtime's key
+	for _, ctx := range []context.Context{traced, context.Background()} {
+		if _, err := r.invokeLambda(ctx, "pricofy-translator-romance-en", "", [][]string{{"hola"}}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !hasTraceHeader(t, client.options[0]) {
+		t.Error("traced invocation sent without the trace header")
+	}
+	if hasTraceHeader(t, client.options[1]) {
+		t.Error("untraced invocation should not add a trace header")
+	}
+}
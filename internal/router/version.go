@@ -0,0 +1,65 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// modelVersions holds the model version each translator function last
+// reported in this container.
+var modelVersions sync.Map // function name → version
+
+// observeModelVersion remembers the model version reported by function.
+func observeModelVersion(function, version string) {
+	if version != "" {
+		modelVersions.Store(function, version)
+	}
+}
+
+// ModelVersions returns the model version each translator function last
+// reported in this container, by function name.
+func ModelVersions() map[string]string {
+	versions := map[string]string{}
+	modelVersions.Range(func(function, version any) bool {
+		versions[function.(string)] = version.(string)
+		return true
+	})
+	return versions
+}
+
+// Functions returns the function serving every translator: the built-in
+// ones and the direct translators of pairs.
+func (r *Router) Functions() map[string]string {
+	functions := map[string]string{}
+	for _, translator := range builtInTranslators {
+		functions[translator] = r.function(translator)
+	}
+	for name, function := range r.functions {
+		functions[name] = function
+	}
+	return functions
+}
+
+// RoutesVersion returns a content hash of the routing table entries, which
+// changes with every change to the table, or "" without a routing table.
+func (r *Router) RoutesVersion(ctx context.Context) string {
+	if r.routes == nil {
+		return ""
+	}
+	entries := r.routes.Entries(ctx)
+	byID := make(map[string]any, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	// Map keys are marshaled sorted, so the hash does not depend on the
+	// scan order
+	content, err := json.Marshal(byID)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
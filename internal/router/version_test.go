@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pricofy/translation-manager/internal/routing"
+)
+
+func TestModelVersions(t *testing.T) {
+	observeModelVersion("translator-a", "opus-mt-es-en@2024-05")
+	observeModelVersion("translator-a", "")
+	observeModelVersion("translator-a", "opus-mt-es-en@2024-06")
+	t.Cleanup(func() { modelVersions.Delete("translator-a") })
+
+	if got := ModelVersions()["translator-a"]; got != "opus-mt-es-en@2024-06" {
+		t.Errorf("ModelVersions()[translator-a] = %q, want the last reported version", got)
+	}
+}
+
+func TestRouter_Functions(t *testing.T) {
+	r := &Router{functions: map[string]string{TranslatorDeEn: "translator-de-en-prod", "es-it": "pricofy-translator-es-it"}}
+	functions := r.Functions()
+
+	want := map[string]string{
+		TranslatorDeEn:      "translator-de-en-prod",
+		TranslatorEnRomance: "pricofy-translator-en-romance",
+		"es-it":             "pricofy-translator-es-it",
+	}
+	for translator, function := range want {
+		if functions[translator] != function {
+			t.Errorf("Functions()[%s] = %q, want %q", translator, functions[translator], function)
+		}
+	}
+	if len(functions) != 9 {
+		t.Errorf("Functions() has %d translators, want 9", len(functions))
+	}
+}
+
+func TestRouter_RoutesVersion(t *testing.T) {
+	version := func(items ...map[string]types.AttributeValue) string {
+		r := &Router{routes: routing.NewCache(routing.NewStore(scanTable{items: items}, "routes"))}
+		return r.RoutesVersion(context.Background())
+	}
+	a := routeItem("es-fr", "es", "fr", "pricofy-translator-es-fr", true)
+	b := routeItem("es-it", "es", "it", "pricofy-translator-es-it", true)
+	disabled := routeItem("es-it", "es", "it", "pricofy-translator-es-it", false)
+
+	if version(a, b) != version(b, a) {
+		t.Error("RoutesVersion() depends on the scan order")
+	}
+	if version(a, b) == version(a, disabled) {
+		t.Error("RoutesVersion() did not change with an entry")
+	}
+	if got := (&Router{}).RoutesVersion(context.Background()); got != "" {
+		t.Errorf("RoutesVersion() without a table = %q, want empty", got)
+	}
+}
@@ -0,0 +1,54 @@
+package routing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// CacheTTL is how long entries are reused before the table is read again, so
+// changes made in other containers apply within CacheTTL.
+const CacheTTL = 30 * time.Second
+
+// Cache keeps the entries of a Store in memory for routing decisions.
+type Cache struct {
+	store *Store
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries []Entry
+	expires time.Time
+}
+
+// NewCache creates a Cache of store.
+func NewCache(store *Store) *Cache {
+	return &Cache{store: store, now: time.Now}
+}
+
+// Entries returns the cached entries, reloading them once they expire. A
+// failed reload keeps the previous entries (none on the first load) and is
+// retried after CacheTTL, so an unavailable table never blocks translation.
+func (c *Cache) Entries(ctx context.Context) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.now().Before(c.expires) {
+		return c.entries
+	}
+
+	entries, err := c.store.List(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "routing table unavailable, keeping the cached entries", slog.Int("entries", len(c.entries)), slog.Any("error", err))
+	} else {
+		c.entries = entries
+	}
+	c.expires = c.now().Add(CacheTTL)
+	return c.entries
+}
+
+// Invalidate makes the next Entries call reload the table.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.expires = time.Time{}
+	c.mu.Unlock()
+}
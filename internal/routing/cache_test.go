@@ -0,0 +1,44 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache_Entries(t *testing.T) {
+	ctx := context.Background()
+	table := newMemoryTable()
+	store := NewStore(table, "routes")
+	if err := store.Add(ctx, validEntry()); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCache(store)
+	c.now = func() time.Time { return now }
+
+	if got := c.Entries(ctx); len(got) != 1 {
+		t.Fatalf("Entries() = %v, want 1 entry", got)
+	}
+	c.Entries(ctx)
+	if table.scans != 1 {
+		t.Errorf("scans = %d, want 1 while cached", table.scans)
+	}
+
+	// A failed reload keeps the cached entries
+	table.err = errors.New("throttled")
+	now = now.Add(CacheTTL)
+	if got := c.Entries(ctx); len(got) != 1 || table.scans != 2 {
+		t.Errorf("after failed reload: %d entries, %d scans", len(got), table.scans)
+	}
+
+	// Invalidate forces a reload
+	table.err = nil
+	delete(table.items, validEntry().ID)
+	c.Invalidate()
+	if got := c.Entries(ctx); len(got) != 0 || table.scans != 3 {
+		t.Errorf("after Invalidate: %d entries, %d scans", len(got), table.scans)
+	}
+}
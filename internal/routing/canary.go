@@ -0,0 +1,192 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Canary defaults.
+const (
+	DefaultCanaryWindow        = 5 * time.Minute
+	DefaultCanaryMinRequests   = 20
+	DefaultCanaryMinConfidence = 0.5
+)
+
+// Canary marks an entry as a canary that is disabled automatically when its
+// error rate or low-confidence (quality estimation warning) rate exceeds
+// the thresholds within a window. A zero threshold is not checked.
+type Canary struct {
+	// MaxErrorRate is the highest tolerated fraction of failed requests.
+	MaxErrorRate float64 `json:"maxErrorRate,omitempty"`
+	// MaxLowConfidenceRate is the highest tolerated fraction of scored texts
+	// with a confidence below MinConfidence.
+	MaxLowConfidenceRate float64 `json:"maxLowConfidenceRate,omitempty"`
+	// MinConfidence is the confidence (0-1) under which a text counts as a
+	// quality warning (default 0.5).
+	MinConfidence float64 `json:"minConfidence,omitempty"`
+	// WindowSeconds is the observation window (default 300).
+	WindowSeconds int `json:"windowSeconds,omitempty"`
+	// MinRequests is the number of requests (or scored texts, for the
+	// low-confidence rate) needed before a rate is judged (default 20).
+	MinRequests int `json:"minRequests,omitempty"`
+}
+
+// Validate checks the canary thresholds.
+func (c *Canary) Validate() error {
+	switch {
+	case !fraction(c.MaxErrorRate) || !fraction(c.MaxLowConfidenceRate) || !fraction(c.MinConfidence):
+		return fmt.Errorf("canary rates and minConfidence must be between 0 and 1")
+	case c.MaxErrorRate == 0 && c.MaxLowConfidenceRate == 0:
+		return fmt.Errorf("canary needs maxErrorRate or maxLowConfidenceRate")
+	case c.WindowSeconds < 0 || c.MinRequests < 0:
+		return fmt.Errorf("canary windowSeconds and minRequests must not be negative")
+	}
+	return nil
+}
+
+// fraction reports whether f is within [0, 1].
+func fraction(f float64) bool {
+	return f >= 0 && f <= 1
+}
+
+// minConfidence returns the quality warning threshold.
+func (c *Canary) minConfidence() float64 {
+	if c.MinConfidence == 0 {
+		return DefaultCanaryMinConfidence
+	}
+	return c.MinConfidence
+}
+
+// window returns the observation window.
+func (c *Canary) window() time.Duration {
+	if c.WindowSeconds == 0 {
+		return DefaultCanaryWindow
+	}
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// minRequests returns the sample size needed to judge a rate.
+func (c *Canary) minRequests() int {
+	if c.MinRequests == 0 {
+		return DefaultCanaryMinRequests
+	}
+	return c.MinRequests
+}
+
+// canaryAttribute stores a canary as a JSON string attribute.
+func canaryAttribute(c *Canary) types.AttributeValue {
+	data, _ := json.Marshal(c) // numbers only, cannot fail
+	return &types.AttributeValueMemberS{Value: string(data)}
+}
+
+// parseCanary reads a canary attribute.
+func parseCanary(item map[string]types.AttributeValue) (*Canary, error) {
+	raw := stringAttr(item, "canary")
+	if raw == "" {
+		return nil, nil
+	}
+	var c Canary
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return nil, fmt.Errorf("invalid canary: %w", err)
+	}
+	return &c, nil
+}
+
+// Outcome is the result of one request served by an entry.
+type Outcome struct {
+	Failed bool
+	// Confidence holds the 0-1 confidence of each text, when the translator
+	// returned scores.
+	Confidence []float64
+}
+
+// Verdict explains why a canary was tripped.
+type Verdict struct {
+	Reason            string  `json:"reason"`
+	Requests          int     `json:"requests"`
+	ErrorRate         float64 `json:"errorRate"`
+	LowConfidenceRate float64 `json:"lowConfidenceRate"`
+}
+
+// Monitor tracks the outcomes of canary entries in fixed windows.
+type Monitor struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*canaryWindow
+}
+
+// canaryWindow holds the counts of one entry in the current window.
+type canaryWindow struct {
+	start         time.Time
+	requests      int
+	failures      int
+	scoredTexts   int
+	lowConfidence int
+}
+
+// NewMonitor creates a Monitor.
+func NewMonitor() *Monitor {
+	return &Monitor{now: time.Now, windows: map[string]*canaryWindow{}}
+}
+
+// Observe records an outcome of e. It returns a verdict when e is a canary
+// whose window now exceeds a threshold; the window then starts over, so a
+// canary trips once per window.
+func (m *Monitor) Observe(e *Entry, o Outcome) *Verdict {
+	if e == nil || e.Canary == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	w := m.windows[e.ID]
+	if w == nil || now.Sub(w.start) >= e.Canary.window() {
+		w = &canaryWindow{start: now}
+		m.windows[e.ID] = w
+	}
+	w.requests++
+	if o.Failed {
+		w.failures++
+	}
+	for _, c := range o.Confidence {
+		w.scoredTexts++
+		if c < e.Canary.minConfidence() {
+			w.lowConfidence++
+		}
+	}
+
+	v := w.verdict(e.Canary)
+	if v != nil {
+		delete(m.windows, e.ID)
+	}
+	return v
+}
+
+// verdict judges the window against the canary thresholds.
+func (w *canaryWindow) verdict(c *Canary) *Verdict {
+	v := &Verdict{Requests: w.requests, ErrorRate: rate(w.failures, w.requests), LowConfidenceRate: rate(w.lowConfidence, w.scoredTexts)}
+	minimum := c.minRequests()
+	switch {
+	case c.MaxErrorRate > 0 && w.requests >= minimum && v.ErrorRate > c.MaxErrorRate:
+		v.Reason = fmt.Sprintf("error rate %.2f exceeds %.2f", v.ErrorRate, c.MaxErrorRate)
+	case c.MaxLowConfidenceRate > 0 && w.scoredTexts >= minimum && v.LowConfidenceRate > c.MaxLowConfidenceRate:
+		v.Reason = fmt.Sprintf("low-confidence rate %.2f exceeds %.2f", v.LowConfidenceRate, c.MaxLowConfidenceRate)
+	default:
+		return nil
+	}
+	return v
+}
+
+// rate returns n/total, or 0 for an empty total.
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
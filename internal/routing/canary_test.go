@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanary_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		canary  Canary
+		wantErr bool
+	}{
+		{"error rate", Canary{MaxErrorRate: 0.1}, false},
+		{"low-confidence rate", Canary{MaxLowConfidenceRate: 0.3, MinConfidence: 0.4}, false},
+		{"no threshold", Canary{WindowSeconds: 60}, true},
+		{"rate above 1", Canary{MaxErrorRate: 1.5}, true},
+		{"negative window", Canary{MaxErrorRate: 0.1, WindowSeconds: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.canary.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMonitor_Observe(t *testing.T) {
+	tests := []struct {
+		name     string
+		canary   *Canary
+		outcomes []Outcome
+		wantTrip int // index of the tripping outcome, -1 for none
+	}{
+		{
+			name:     "not a canary",
+			outcomes: []Outcome{{Failed: true}, {Failed: true}},
+			wantTrip: -1,
+		},
+		{
+			name:     "error rate over threshold",
+			canary:   &Canary{MaxErrorRate: 0.4, MinRequests: 4},
+			outcomes: []Outcome{{}, {Failed: true}, {}, {Failed: true}},
+			wantTrip: 3,
+		},
+		{
+			name:     "too few requests to judge",
+			canary:   &Canary{MaxErrorRate: 0.1, MinRequests: 5},
+			outcomes: []Outcome{{Failed: true}, {Failed: true}, {Failed: true}},
+			wantTrip: -1,
+		},
+		{
+			name:     "low-confidence rate over threshold",
+			canary:   &Canary{MaxLowConfidenceRate: 0.5, MinRequests: 4},
+			outcomes: []Outcome{{Confidence: []float64{0.9, 0.2}}, {Confidence: []float64{0.1, 0.3}}},
+			wantTrip: 1,
+		},
+		{
+			name:     "healthy canary",
+			canary:   &Canary{MaxErrorRate: 0.5, MaxLowConfidenceRate: 0.5, MinRequests: 2},
+			outcomes: []Outcome{{Confidence: []float64{0.9}}, {Confidence: []float64{0.8}}, {Failed: true}},
+			wantTrip: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMonitor()
+			e := &Entry{ID: "canary", Canary: tt.canary}
+			trip := -1
+			for i, o := range tt.outcomes {
+				if v := m.Observe(e, o); v != nil && trip < 0 {
+					trip = i
+					if v.Reason == "" {
+						t.Error("verdict without reason")
+					}
+				}
+			}
+			if trip != tt.wantTrip {
+				t.Errorf("tripped at %d, want %d", trip, tt.wantTrip)
+			}
+		})
+	}
+}
+
+func TestMonitor_Window(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMonitor()
+	m.now = func() time.Time { return now }
+	e := &Entry{ID: "canary", Canary: &Canary{MaxErrorRate: 0.5, MinRequests: 2, WindowSeconds: 60}}
+
+	m.Observe(e, Outcome{Failed: true})
+	now = now.Add(time.Minute)
+	// The failure above fell out of the window
+	if v := m.Observe(e, Outcome{Failed: true}); v != nil {
+		t.Fatalf("tripped across windows: %+v", v)
+	}
+	if v := m.Observe(e, Outcome{Failed: true}); v == nil {
+		t.Fatal("should trip within the window")
+	}
+	// The window starts over after a trip
+	if v := m.Observe(e, Outcome{Failed: true}); v != nil {
+		t.Errorf("tripped again right after a trip: %+v", v)
+	}
+}
@@ -0,0 +1,220 @@
+// Package routing holds the runtime routing table: entries stored in DynamoDB
+// that send a language pair to a specific translator function, overriding the
+// built-in routes without a deploy.
+//
+// Each entry maps sourceLang → targetLang to one function (optionally a
+// version or alias qualifier) or translation backend with a relative weight.
+// When a pair has several enabled entries, traffic is split between them by
+// weight; when it has none, the built-in route is used. Tagged entries only
+// serve texts carrying their tag, e.g. "legal", so specialized translators
+// take those texts while the rest of the batch keeps the default route.
+package routing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableEnv names the DynamoDB table holding routing entries.
+const TableEnv = "ROUTING_TABLE"
+
+// MaxWeight bounds Entry.Weight.
+const MaxWeight = 1000
+
+// Entry routes a language pair to a translator function.
+type Entry struct {
+	ID         string `json:"id"`
+	SourceLang string `json:"sourceLang"`
+	TargetLang string `json:"targetLang"`
+	// Function is the translator Lambda name.
+	Function string `json:"function,omitempty"`
+	// Qualifier is an optional version or alias of Function.
+	Qualifier string `json:"qualifier,omitempty"`
+	// Backend is a translation backend (e.g. "deepl") used instead of a
+	// function.
+	Backend string `json:"backend,omitempty"`
+	// Tag restricts the entry to texts with this tag; untagged entries
+	// serve untagged texts.
+	Tag string `json:"tag,omitempty"`
+	// Weight is the entry's share of the pair's traffic relative to the
+	// other enabled entries (1-MaxWeight).
+	Weight  int  `json:"weight"`
+	Enabled bool `json:"enabled"`
+	// Canary, when set, disables the entry automatically if it misbehaves.
+	Canary *Canary `json:"canary,omitempty"`
+	// UpdatedAt and UpdatedBy record the last change.
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
+
+var (
+	functionPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	qualifierPattern = regexp.MustCompile(`^(\$LATEST|[A-Za-z0-9_-]{1,128})$`)
+	idPattern        = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+	backendPattern   = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+	// TagPattern is the form of text tags.
+	TagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+)
+
+// Validate checks the fields of an entry. Languages and backends are only
+// checked for form; the caller knows which ones are supported.
+func (e *Entry) Validate() error {
+	switch {
+	case !idPattern.MatchString(e.ID):
+		return fmt.Errorf("id must be 1-64 letters, digits, '.', '_' or '-'")
+	case e.SourceLang == "" || e.TargetLang == "":
+		return fmt.Errorf("sourceLang and targetLang are required")
+	case e.SourceLang == e.TargetLang:
+		return fmt.Errorf("sourceLang and targetLang must be different")
+	case e.Tag != "" && !TagPattern.MatchString(e.Tag):
+		return fmt.Errorf("tag must be 1-32 lowercase letters, digits or '-'")
+	case e.Weight < 1 || e.Weight > MaxWeight:
+		return fmt.Errorf("weight must be between 1 and %d", MaxWeight)
+	case e.Canary != nil:
+		if err := e.Canary.Validate(); err != nil {
+			return err
+		}
+	}
+	return e.validateTarget()
+}
+
+// validateTarget checks that the entry names either a function or a
+// backend.
+func (e *Entry) validateTarget() error {
+	switch {
+	case e.Backend != "" && (e.Function != "" || e.Qualifier != ""):
+		return fmt.Errorf("backend excludes function and qualifier")
+	case e.Backend != "" && !backendPattern.MatchString(e.Backend):
+		return fmt.Errorf("backend must be a backend name")
+	case e.Backend == "" && !functionPattern.MatchString(e.Function):
+		return fmt.Errorf("function must be a Lambda function name")
+	case e.Qualifier != "" && !qualifierPattern.MatchString(e.Qualifier):
+		return fmt.Errorf("qualifier must be a version or alias name")
+	}
+	return nil
+}
+
+// Target returns the name to invoke: the function with its qualifier.
+func (e *Entry) Target() string {
+	if e.Qualifier == "" {
+		return e.Function
+	}
+	return e.Function + ":" + e.Qualifier
+}
+
+// Pick chooses an enabled entry for source → target and tag ("" for
+// untagged texts), splitting by weight with roll in [0, 1). It returns nil
+// when the pair has no enabled entry for the tag.
+func Pick(entries []Entry, source, target, tag string, roll float64) *Entry {
+	return Split(Candidates(entries, source, target, tag), roll)
+}
+
+// Candidates returns the enabled entries for source → target and tag that
+// take traffic, the ones Pick chooses between.
+func Candidates(entries []Entry, source, target, tag string) []*Entry {
+	var candidates []*Entry
+	for i := range entries {
+		e := &entries[i]
+		if e.Enabled && e.SourceLang == source && e.TargetLang == target && e.Tag == tag && e.Weight > 0 {
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates
+}
+
+// Split chooses one of candidates by weight with roll in [0, 1), or nil
+// when there are none.
+func Split(candidates []*Entry, roll float64) *Entry {
+	total := 0
+	for _, e := range candidates {
+		total += e.Weight
+	}
+	point := roll * float64(total)
+	for _, e := range candidates {
+		point -= float64(e.Weight)
+		if point < 0 {
+			return e
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[len(candidates)-1]
+	}
+	return nil
+}
+
+// item converts an entry to DynamoDB attributes.
+func (e *Entry) item() map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"id":         &types.AttributeValueMemberS{Value: e.ID},
+		"sourceLang": &types.AttributeValueMemberS{Value: e.SourceLang},
+		"targetLang": &types.AttributeValueMemberS{Value: e.TargetLang},
+		"weight":     &types.AttributeValueMemberN{Value: strconv.Itoa(e.Weight)},
+		"enabled":    &types.AttributeValueMemberBOOL{Value: e.Enabled},
+		"updatedAt":  &types.AttributeValueMemberS{Value: e.UpdatedAt.UTC().Format(time.RFC3339)},
+		"updatedBy":  &types.AttributeValueMemberS{Value: e.UpdatedBy},
+	}
+	for name, value := range map[string]string{"function": e.Function, "qualifier": e.Qualifier, "backend": e.Backend, "tag": e.Tag} {
+		if value != "" {
+			item[name] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+	if e.Canary != nil {
+		item["canary"] = canaryAttribute(e.Canary)
+	}
+	return item
+}
+
+// fromItem converts DynamoDB attributes to an entry.
+func fromItem(item map[string]types.AttributeValue) (Entry, error) {
+	e := Entry{
+		ID:         stringAttr(item, "id"),
+		SourceLang: stringAttr(item, "sourceLang"),
+		TargetLang: stringAttr(item, "targetLang"),
+		Function:   stringAttr(item, "function"),
+		Qualifier:  stringAttr(item, "qualifier"),
+		Backend:    stringAttr(item, "backend"),
+		Tag:        stringAttr(item, "tag"),
+		UpdatedBy:  stringAttr(item, "updatedBy"),
+	}
+	if enabled, ok := item["enabled"].(*types.AttributeValueMemberBOOL); ok {
+		e.Enabled = enabled.Value
+	}
+	if weight, ok := item["weight"].(*types.AttributeValueMemberN); ok {
+		n, err := strconv.Atoi(weight.Value)
+		if err != nil {
+			return e, fmt.Errorf("routing entry %s: invalid weight %q", e.ID, weight.Value)
+		}
+		e.Weight = n
+	}
+	if at := stringAttr(item, "updatedAt"); at != "" {
+		e.UpdatedAt, _ = time.Parse(time.RFC3339, at)
+	}
+	canary, err := parseCanary(item)
+	if err != nil {
+		return e, fmt.Errorf("routing entry %s: %w", e.ID, err)
+	}
+	e.Canary = canary
+	return e, e.Validate()
+}
+
+// stringAttr returns a string attribute, or "".
+func stringAttr(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+// Table is the subset of the DynamoDB client used by the store.
+type Table interface {
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
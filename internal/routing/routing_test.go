@@ -0,0 +1,112 @@
+package routing
+
+import (
+	"testing"
+	"time"
+)
+
+func validEntry() Entry {
+	return Entry{ID: "es-en-v2", SourceLang: "es", TargetLang: "en", Function: "pricofy-translator-es-en", Qualifier: "live", Weight: 10, Enabled: true}
+}
+
+func TestEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Entry)
+		wantErr bool
+	}{
+		{"valid", func(*Entry) {}, false},
+		{"no qualifier", func(e *Entry) { e.Qualifier = "" }, false},
+		{"version qualifier", func(e *Entry) { e.Qualifier = "$LATEST" }, false},
+		{"missing id", func(e *Entry) { e.ID = "" }, true},
+		{"same languages", func(e *Entry) { e.TargetLang = "es" }, true},
+		{"function ARN", func(e *Entry) { e.Function = "arn:aws:lambda:eu-west-1:1:function:x" }, true},
+		{"bad qualifier", func(e *Entry) { e.Qualifier = "live:1" }, true},
+		{"zero weight", func(e *Entry) { e.Weight = 0 }, true},
+		{"weight too high", func(e *Entry) { e.Weight = MaxWeight + 1 }, true},
+		{"tagged", func(e *Entry) { e.Tag = "medical-claims" }, false},
+		{"bad tag", func(e *Entry) { e.Tag = "Legal Texts" }, true},
+		{"backend", func(e *Entry) { e.Function, e.Qualifier, e.Backend = "", "", "deepl" }, false},
+		{"backend and function", func(e *Entry) { e.Backend = "deepl" }, true},
+		{"no function or backend", func(e *Entry) { e.Function, e.Qualifier = "", "" }, true},
+		{"function invalid behind valid canary", func(e *Entry) {
+			e.Function = ""
+			e.Canary = &Canary{MaxErrorRate: 0.1}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := validEntry()
+			tt.modify(&e)
+			if err := e.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntry_Target(t *testing.T) {
+	e := validEntry()
+	if got := e.Target(); got != "pricofy-translator-es-en:live" {
+		t.Errorf("Target() = %q", got)
+	}
+	e.Qualifier = ""
+	if got := e.Target(); got != "pricofy-translator-es-en" {
+		t.Errorf("Target() = %q", got)
+	}
+}
+
+func TestPick(t *testing.T) {
+	entries := []Entry{
+		{ID: "stable", SourceLang: "es", TargetLang: "en", Weight: 90, Enabled: true},
+		{ID: "canary", SourceLang: "es", TargetLang: "en", Weight: 10, Enabled: true},
+		{ID: "off", SourceLang: "es", TargetLang: "en", Weight: 100, Enabled: false},
+		{ID: "other", SourceLang: "fr", TargetLang: "en", Weight: 1, Enabled: true},
+		{ID: "legal", SourceLang: "es", TargetLang: "en", Tag: "legal", Weight: 1, Enabled: true},
+	}
+
+	tests := []struct {
+		source, target, tag string
+		roll                float64
+		want                string
+	}{
+		{"es", "en", "", 0, "stable"},
+		{"es", "en", "", 0.89, "stable"},
+		{"es", "en", "", 0.9, "canary"},
+		{"es", "en", "", 0.999, "canary"},
+		{"fr", "en", "", 0.5, "other"},
+		{"de", "en", "", 0.5, ""},
+		{"es", "en", "legal", 0.5, "legal"},
+		{"fr", "en", "legal", 0.5, ""},
+	}
+
+	for _, tt := range tests {
+		got := Pick(entries, tt.source, tt.target, tt.tag, tt.roll)
+		id := ""
+		if got != nil {
+			id = got.ID
+		}
+		if id != tt.want {
+			t.Errorf("Pick(%s→%s, %q, %v) = %q, want %q", tt.source, tt.target, tt.tag, tt.roll, id, tt.want)
+		}
+	}
+}
+
+func TestEntry_ItemRoundTrip(t *testing.T) {
+	e := validEntry()
+	e.UpdatedAt = time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC)
+	e.UpdatedBy = "oncall"
+	backend := e
+	backend.Function, backend.Qualifier, backend.Backend, backend.Tag = "", "", "deepl", "legal"
+
+	for _, want := range []Entry{e, backend} {
+		got, err := fromItem(want.item())
+		if err != nil {
+			t.Fatalf("fromItem() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
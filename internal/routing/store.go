@@ -0,0 +1,127 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Errors of conditional writes.
+var (
+	ErrNotFound = errors.New("routing entry not found")
+	ErrExists   = errors.New("routing entry already exists")
+)
+
+// Store reads and writes routing entries, keyed by the "id" attribute.
+type Store struct {
+	table Table
+	name  string
+}
+
+// NewStore creates a Store for the named table.
+func NewStore(table Table, name string) *Store {
+	return &Store{table: table, name: name}
+}
+
+// List returns every entry of the table.
+func (s *Store) List(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	input := &dynamodb.ScanInput{TableName: &s.name}
+	for {
+		out, err := s.table.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan routing table %s: %w", s.name, err)
+		}
+		for _, item := range out.Items {
+			e, err := fromItem(item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return entries, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// Get returns the entry with id, or ErrNotFound.
+func (s *Store) Get(ctx context.Context, id string) (Entry, error) {
+	out, err := s.table.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.name,
+		Key:            key(id),
+		ConsistentRead: boolPtr(true),
+	})
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to read routing entry %s: %w", id, err)
+	}
+	if out.Item == nil {
+		return Entry{}, ErrNotFound
+	}
+	return fromItem(out.Item)
+}
+
+// Add writes a new entry, or returns ErrExists.
+func (s *Store) Add(ctx context.Context, e Entry) error {
+	return s.put(ctx, e, "attribute_not_exists(id)", ErrExists)
+}
+
+// Update replaces an existing entry, or returns ErrNotFound.
+func (s *Store) Update(ctx context.Context, e Entry) error {
+	return s.put(ctx, e, "attribute_exists(id)", ErrNotFound)
+}
+
+// Delete removes an entry and returns it, or returns ErrNotFound.
+func (s *Store) Delete(ctx context.Context, id string) (Entry, error) {
+	out, err := s.table.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           &s.name,
+		Key:                 key(id),
+		ConditionExpression: stringPtr("attribute_exists(id)"),
+		ReturnValues:        types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return Entry{}, conditionError(err, ErrNotFound, id)
+	}
+	return fromItem(out.Attributes)
+}
+
+// put writes e if condition holds, returning failed when it does not.
+func (s *Store) put(ctx context.Context, e Entry, condition string, failed error) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	_, err := s.table.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           &s.name,
+		Item:                e.item(),
+		ConditionExpression: &condition,
+	})
+	if err != nil {
+		return conditionError(err, failed, e.ID)
+	}
+	return nil
+}
+
+// conditionError maps a failed condition check to failed.
+func conditionError(err, failed error, id string) error {
+	var conditional *types.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		return failed
+	}
+	return fmt.Errorf("failed to write routing entry %s: %w", id, err)
+}
+
+func key(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,123 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// memoryTable is an in-memory Table keyed by "id" that honours the
+// attribute_exists/attribute_not_exists conditions used by the store.
+type memoryTable struct {
+	items map[string]map[string]types.AttributeValue
+	scans int
+	err   error
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func idOf(key map[string]types.AttributeValue) string {
+	return key["id"].(*types.AttributeValueMemberS).Value
+}
+
+// check evaluates a store condition for id.
+func (m *memoryTable) check(condition *string, id string) error {
+	if condition == nil {
+		return nil
+	}
+	_, exists := m.items[id]
+	if strings.HasPrefix(*condition, "attribute_exists") != exists {
+		return &types.ConditionalCheckFailedException{}
+	}
+	return nil
+}
+
+func (m *memoryTable) Scan(_ context.Context, _ *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	m.scans++
+	if m.err != nil {
+		return nil, m.err
+	}
+	out := &dynamodb.ScanOutput{}
+	for _, item := range m.items {
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+func (m *memoryTable) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m.items[idOf(in.Key)]}, nil
+}
+
+func (m *memoryTable) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	id := idOf(in.Item)
+	if err := m.check(in.ConditionExpression, id); err != nil {
+		return nil, err
+	}
+	m.items[id] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *memoryTable) DeleteItem(_ context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	id := idOf(in.Key)
+	if err := m.check(in.ConditionExpression, id); err != nil {
+		return nil, err
+	}
+	old := m.items[id]
+	delete(m.items, id)
+	return &dynamodb.DeleteItemOutput{Attributes: old}, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(newMemoryTable(), "routes")
+	e := validEntry()
+
+	if err := s.Add(ctx, e); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(ctx, e); !errors.Is(err, ErrExists) {
+		t.Errorf("second Add() error = %v, want ErrExists", err)
+	}
+
+	e.Weight = 50
+	if err := s.Update(ctx, e); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, err := s.Get(ctx, e.ID); err != nil || got.Weight != 50 {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	missing := validEntry()
+	missing.ID = "missing"
+	if err := s.Update(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	invalid := validEntry()
+	invalid.Weight = 0
+	if err := s.Add(ctx, invalid); err == nil {
+		t.Error("Add() should validate the entry")
+	}
+
+	entries, err := s.List(ctx)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+
+	deleted, err := s.Delete(ctx, e.ID)
+	if err != nil || deleted.ID != e.ID {
+		t.Errorf("Delete() = %+v, %v", deleted, err)
+	}
+	if _, err := s.Delete(ctx, e.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Package schema provides a small JSON-schema-like validator that reports
+// the exact location and nature of every problem in a JSON document.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Type is a JSON value type.
+type Type string
+
+// JSON value types understood by the validator.
+const (
+	String  Type = "string"
+	Number  Type = "number"
+	Integer Type = "integer"
+	Boolean Type = "boolean"
+	Array   Type = "array"
+	Object  Type = "object"
+)
+
+// Schema describes the expected shape of a JSON value.
+type Schema struct {
+	Type       Type
+	Items      *Schema            // Array element schema
+	Properties map[string]*Schema // Object property schemas
+	Required   []string           // Required object properties
+	Enum       []string           // Allowed string values
+	Minimum    *float64           // Inclusive numeric minimum
+	Maximum    *float64           // Inclusive numeric maximum
+	// Hint is appended to type errors to suggest the usual fix.
+	Hint string
+}
+
+// FieldError is a single validation problem at a JSON path.
+type FieldError struct {
+	Path    string // e.g. "texts[3]"
+	Problem string // e.g. "must be a string"
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Problem
+	}
+	return e.Path + ": " + e.Problem
+}
+
+// Errors is the list of problems found in a document.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Float returns a pointer to f, for Minimum and Maximum.
+func Float(f float64) *float64 { return &f }
+
+// Validate checks data against s. It returns nil when the document is valid,
+// otherwise an Errors value listing every problem, object fields in name order.
+func Validate(data []byte, s *Schema) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return Errors{{Problem: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var errs Errors
+	validate("", doc, s, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validate(path string, value interface{}, s *Schema, errs *Errors) {
+	if !matchesType(value, s.Type) {
+		problem := fmt.Sprintf("must be %s, got %s", describe(s), typeName(value))
+		if s.Hint != "" && value != nil {
+			problem += " (" + s.Hint + ")"
+		}
+		*errs = append(*errs, FieldError{Path: path, Problem: problem})
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		validateEnum(path, v, s, errs)
+	case json.Number:
+		validateRange(path, v, s, errs)
+	case []interface{}:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range v {
+			validate(fmt.Sprintf("%s[%d]", path, i), item, s.Items, errs)
+		}
+	case map[string]interface{}:
+		validateObject(path, v, s, errs)
+	}
+}
+
+func validateEnum(path, v string, s *Schema, errs *Errors) {
+	if len(s.Enum) == 0 {
+		return
+	}
+	for _, allowed := range s.Enum {
+		if v == allowed {
+			return
+		}
+	}
+	*errs = append(*errs, FieldError{
+		Path:    path,
+		Problem: fmt.Sprintf("must be one of %s, got %q", strings.Join(s.Enum, ", "), v),
+	})
+}
+
+func validateRange(path string, v json.Number, s *Schema, errs *Errors) {
+	f, err := v.Float64()
+	if err != nil {
+		return
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		*errs = append(*errs, FieldError{Path: path, Problem: fmt.Sprintf("must be >= %v", *s.Minimum)})
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		*errs = append(*errs, FieldError{Path: path, Problem: fmt.Sprintf("must be <= %v", *s.Maximum)})
+	}
+}
+
+func validateObject(path string, obj map[string]interface{}, s *Schema, errs *Errors) {
+	for _, name := range s.Required {
+		if v, ok := obj[name]; !ok || v == nil {
+			*errs = append(*errs, FieldError{Path: join(path, name), Problem: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := obj[name]
+		prop, ok := s.Properties[name]
+		if !ok {
+			if known := caseInsensitiveMatch(name, s.Properties); known != "" {
+				*errs = append(*errs, FieldError{
+					Path:    join(path, name),
+					Problem: fmt.Sprintf("unknown field, did you mean %q?", known),
+				})
+			}
+			continue
+		}
+		if value == nil {
+			continue // null is treated as absent
+		}
+		validate(join(path, name), value, prop, errs)
+	}
+}
+
+// caseInsensitiveMatch finds a declared property differing only in case or
+// separators (e.g. "source_lang" for "sourceLang").
+func caseInsensitiveMatch(name string, props map[string]*Schema) string {
+	normalized := normalizeName(name)
+	for known := range props {
+		if normalizeName(known) == normalized {
+			return known
+		}
+	}
+	return ""
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func matchesType(value interface{}, t Type) bool {
+	switch v := value.(type) {
+	case string:
+		return t == String
+	case json.Number:
+		if t == Number {
+			return true
+		}
+		if t == Integer {
+			f, err := v.Float64()
+			return err == nil && f == math.Trunc(f)
+		}
+		return false
+	case bool:
+		return t == Boolean
+	case []interface{}:
+		return t == Array
+	case map[string]interface{}:
+		return t == Object
+	default:
+		return false
+	}
+}
+
+// describe renders the expected type, e.g. "an array of strings".
+func describe(s *Schema) string {
+	switch s.Type {
+	case Array:
+		if s.Items != nil {
+			return "an array of " + string(s.Items.Type) + "s"
+		}
+		return "an array"
+	case Integer, Object:
+		return "an " + string(s.Type)
+	default:
+		return "a " + string(s.Type)
+	}
+}
+
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "an integer"
+		}
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
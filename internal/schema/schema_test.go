@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+var testSchema = &Schema{
+	Type:     Object,
+	Required: []string{"texts", "lang"},
+	Properties: map[string]*Schema{
+		"texts": {Type: Array, Items: &Schema{Type: String}, Hint: "wrap it in an array"},
+		"lang":  {Type: String, Enum: []string{"es", "fr"}},
+		"score": {Type: Number, Minimum: Float(0), Maximum: Float(1)},
+		"count": {Type: Integer},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{"valid", `{"texts": ["a", "b"], "lang": "es"}`, ""},
+		{"null optional field", `{"texts": [], "lang": "es", "score": null}`, ""},
+		{"unknown field ignored", `{"texts": [], "lang": "es", "extra": 1}`, ""},
+		{"element type", `{"texts": ["a", "b", "c", 4], "lang": "es"}`, "texts[3]: must be a string, got an integer"},
+		{"single string for array", `{"texts": "a", "lang": "es"}`, "texts: must be an array of strings, got a string (wrap it in an array)"},
+		{"missing required", `{"texts": []}`, "lang: is required"},
+		{"enum", `{"texts": [], "lang": "de"}`, `lang: must be one of es, fr, got "de"`},
+		{"range", `{"texts": [], "lang": "es", "score": 1.5}`, "score: must be <= 1"},
+		{"integer", `{"texts": [], "lang": "es", "count": 1.5}`, "count: must be an integer, got a number"},
+		{"misspelled field", `{"texts": [], "lang": "es", "Texts": []}`, `Texts: unknown field, did you mean "texts"?`},
+		{"not an object", `["a"]`, "must be an object, got an array"},
+		{"several problems", `{"texts": [1], "lang": 2}`, "lang: must be a string, got an integer; texts[0]: must be a string, got an integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.document), testSchema)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %q", tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Validate() = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	err := Validate([]byte(`{"texts": [`), testSchema)
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Validate() = %v, want a single error", err)
+	}
+}
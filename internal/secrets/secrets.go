@@ -0,0 +1,33 @@
+// Package secrets reads secrets from AWS Secrets Manager.
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Client reads the secrets of one region.
+type Client struct {
+	api *secretsmanager.Client
+}
+
+// New creates a Client with the region, credentials and HTTP client of cfg.
+func New(cfg aws.Config) *Client {
+	return &Client{api: secretsmanager.NewFromConfig(cfg)}
+}
+
+// String returns the current string value of the secret id, a name or an
+// ARN. Binary secrets are an error.
+func (c *Client) String(ctx context.Context, id string) (string, error) {
+	out, err := c.api.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", id, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", id)
+	}
+	return *out.SecretString, nil
+}
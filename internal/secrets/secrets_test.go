@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// fixedDoer answers every request with status and body, and records the
+// last one.
+type fixedDoer struct {
+	status int
+	body   string
+	req    *http.Request
+	input  map[string]string
+}
+
+func (d *fixedDoer) Do(req *http.Request) (*http.Response, error) {
+	d.req = req
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &d.input); err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: d.status, Body: io.NopCloser(strings.NewReader(d.body))}, nil
+}
+
+func testConfig(doer *fixedDoer) aws.Config {
+	return aws.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", ""),
+		HTTPClient:  doer,
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}
+}
+
+func TestString(t *testing.T) {
+	doer := &fixedDoer{status: 200, body: `{"Name": "deepl", "SecretString": "key:fx"}`}
+	c := New(testConfig(doer))
+
+	got, err := c.String(context.Background(), "deepl")
+	if err != nil || got != "key:fx" {
+		t.Fatalf("String() = %q, %v, want key:fx", got, err)
+	}
+	if doer.req.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || doer.input["SecretId"] != "deepl" {
+		t.Errorf("request %s with %v", doer.req.Header.Get("X-Amz-Target"), doer.input)
+	}
+	if doer.req.URL.Host != "secretsmanager.eu-west-1.amazonaws.com" || !strings.Contains(doer.req.Header.Get("Authorization"), "/eu-west-1/secretsmanager/") {
+		t.Errorf("request to %s signed %q, want the regional endpoint signed for secretsmanager", doer.req.URL, doer.req.Header.Get("Authorization"))
+	}
+}
+
+func TestString_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"not found", 400, `{"__type": "ResourceNotFoundException"}`, "ResourceNotFoundException"},
+		{"binary", 200, `{"Name": "deepl", "SecretBinary": "a2V5"}`, "no string value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(testConfig(&fixedDoer{status: tt.status, body: tt.body}))
+			if _, err := c.String(context.Background(), "deepl"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("String() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
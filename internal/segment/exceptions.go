@@ -0,0 +1,168 @@
+package segment
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ExceptionsEnv names the environment variable holding exception overrides
+// as JSON (or ExceptionsEnv+"_FILE" pointing to a JSON file).
+const ExceptionsEnv = "SEGMENT_EXCEPTIONS"
+
+// LanguageExceptions are the periods of a language that do not end a
+// sentence. In config they extend the built-in defaults of the language.
+type LanguageExceptions struct {
+	// Abbreviations end with a period, e.g. "aprox." or "S.L.", and are
+	// matched case-insensitively.
+	Abbreviations []string `json:"abbreviations,omitempty"`
+	// Remove drops built-in abbreviations.
+	Remove []string `json:"remove,omitempty"`
+	// Ordinals marks languages writing ordinals as a number and a period
+	// ("am 3. Oktober"), so such numbers do not end a sentence before a word.
+	Ordinals *bool `json:"ordinals,omitempty"`
+}
+
+// Exceptions are the sentence-boundary exceptions by language. Languages
+// match exactly or by base language: exceptions for "pt" also apply to
+// "pt_BR". A nil *Exceptions has none.
+type Exceptions struct {
+	langs map[string]exceptionSet
+}
+
+type exceptionSet struct {
+	abbreviations map[string]bool
+	ordinals      bool
+}
+
+// defaultExceptions are the built-in exceptions. Abbreviations that commonly
+// end sentences too, such as "etc.", are left out: a missed split costs less
+// than two sentence halves translated apart.
+var defaultExceptions = map[string]LanguageExceptions{
+	"es": {Abbreviations: []string{
+		"aprox.", "sr.", "sra.", "srta.", "dr.", "dra.", "dña.", "núm.", "nº.", "pág.", "tel.", "avda.", "av.",
+		"s.a.", "s.l.", "ud.", "uds.", "p.ej.", "ej.", "máx.", "mín.", "ref.", "art.",
+	}},
+	"pt": {Abbreviations: []string{
+		"sr.", "sra.", "dr.", "dra.", "av.", "nº.", "pág.", "tel.", "ltda.", "s.a.", "aprox.", "p.ex.", "máx.", "mín.", "ref.",
+	}},
+	"it": {Abbreviations: []string{
+		"sig.", "sig.ra.", "dott.", "ing.", "avv.", "prof.", "es.", "p.es.", "tel.", "pag.", "s.p.a.", "s.r.l.", "ca.", "max.", "min.", "rif.",
+	}},
+	"fr": {Abbreviations: []string{
+		"mme.", "mlle.", "dr.", "env.", "p.ex.", "cf.", "n°.", "tél.", "av.", "bd.", "s.a.", "s.a.r.l.", "réf.", "max.", "min.",
+	}},
+	"de": {Abbreviations: []string{
+		"z.b.", "bzw.", "ca.", "d.h.", "u.a.", "evtl.", "ggf.", "inkl.", "zzgl.", "nr.", "str.", "tel.", "dr.", "hr.", "fr.",
+		"bspw.", "max.", "min.", "vgl.", "u.u.",
+	}, Ordinals: boolPtr(true)},
+	"en": {Abbreviations: []string{
+		"mr.", "mrs.", "ms.", "dr.", "prof.", "st.", "approx.", "e.g.", "i.e.", "vs.", "no.", "inc.", "ltd.", "co.", "jr.", "sr.",
+	}},
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// DefaultExceptions are the built-in exceptions without overrides.
+var DefaultExceptions = mustExceptions(nil)
+
+func mustExceptions(overrides map[string]LanguageExceptions) *Exceptions {
+	e, err := NewExceptions(overrides)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// NewExceptions validates overrides and merges them into the built-in
+// exceptions.
+func NewExceptions(overrides map[string]LanguageExceptions) (*Exceptions, error) {
+	e := &Exceptions{langs: map[string]exceptionSet{}}
+	for lang, le := range defaultExceptions {
+		if err := e.merge(lang, le); err != nil {
+			return nil, err
+		}
+	}
+	for lang, le := range overrides {
+		if err := e.merge(lang, le); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// LoadExceptions builds the exceptions from the SEGMENT_EXCEPTIONS config.
+// Without config it returns the built-in exceptions.
+func LoadExceptions() (*Exceptions, error) {
+	var overrides map[string]LanguageExceptions
+	if _, err := config.LoadJSON(ExceptionsEnv, &overrides); err != nil {
+		return nil, err
+	}
+	return NewExceptions(overrides)
+}
+
+func (e *Exceptions) merge(lang string, le LanguageExceptions) error {
+	set, ok := e.langs[lang]
+	if !ok {
+		set = exceptionSet{abbreviations: map[string]bool{}}
+	}
+	for _, a := range le.Abbreviations {
+		if !strings.HasSuffix(a, ".") || strings.IndexFunc(a, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("%s: abbreviation %q must end with a period and have no spaces", lang, a)
+		}
+		set.abbreviations[strings.ToLower(a)] = true
+	}
+	for _, a := range le.Remove {
+		delete(set.abbreviations, strings.ToLower(a))
+	}
+	if le.Ordinals != nil {
+		set.ordinals = *le.Ordinals
+	}
+	e.langs[lang] = set
+	return nil
+}
+
+// forLang returns the exceptions of lang or of its base language.
+func (e *Exceptions) forLang(lang string) exceptionSet {
+	if e == nil {
+		return exceptionSet{}
+	}
+	if set, ok := e.langs[lang]; ok {
+		return set
+	}
+	base, _, _ := strings.Cut(lang, "_")
+	return e.langs[base]
+}
+
+// continues reports whether the period at s[i] is part of an abbreviation or
+// ordinal rather than the end of a sentence. rest is the text after the
+// punctuation run.
+func (set exceptionSet) continues(s string, i int, rest string) bool {
+	if s[i] != '.' {
+		return false
+	}
+	word := lastWord(s[:i])
+	if set.abbreviations[strings.ToLower(word)+"."] {
+		return true
+	}
+	return set.ordinals && isOrdinal(word, rest)
+}
+
+// lastWord returns the word before a period, without opening punctuation.
+func lastWord(before string) string {
+	word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+	return strings.TrimLeft(word, `("'¿¡«“‘[`)
+}
+
+// isOrdinal reports whether word is a number of up to three digits followed
+// by a word, as in "3. Oktober".
+func isOrdinal(word, rest string) bool {
+	if word == "" || len(word) > 3 || strings.TrimLeft(word, "0123456789") != "" {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(strings.TrimLeftFunc(rest, unicode.IsSpace))
+	return unicode.IsLetter(next)
+}
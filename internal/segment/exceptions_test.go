@@ -0,0 +1,78 @@
+package segment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExceptions_Split(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		text  string
+		texts []string
+	}{
+		{"spanish abbreviation", "es", "Pesa aprox. 2 kg. Envío gratis.", []string{"Pesa aprox. 2 kg.", "Envío gratis."}},
+		{"company suffix", "es", "Vendido por Muebles S.L. Garantía incluida.", []string{"Vendido por Muebles S.L. Garantía incluida."}},
+		{"case-insensitive", "es", "Ver Pág. 3 del manual.", []string{"Ver Pág. 3 del manual."}},
+		{"opening punctuation", "es", "Varios colores (p.ej. rojo) disponibles.", []string{"Varios colores (p.ej. rojo) disponibles."}},
+		{"german abbreviation", "de", "Viele Farben, z.B. Rot. Versand gratis.", []string{"Viele Farben, z.B. Rot.", "Versand gratis."}},
+		{"german ordinal", "de", "Lieferung am 3. Oktober. Danke.", []string{"Lieferung am 3. Oktober.", "Danke."}},
+		{"number before digits", "de", "Preis 30. 40 Stück verfügbar.", []string{"Preis 30.", "40 Stück verfügbar."}},
+		{"no ordinals in spanish", "es", "Quedan 3. Envío hoy.", []string{"Quedan 3.", "Envío hoy."}},
+		{"regional language", "pt_BR", "Falar com a Sra. Silva. Obrigado.", []string{"Falar com a Sra. Silva.", "Obrigado."}},
+		{"other language's abbreviation", "en", "Weighs aprox. 2 kg.", []string{"Weighs aprox.", "2 kg."}},
+		{"unknown language", "ja", "Pesa aprox. 2 kg.", []string{"Pesa aprox.", "2 kg."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := DefaultExceptions.Split(tt.text, tt.lang)
+			if got := doc.Texts(); !reflect.DeepEqual(got, tt.texts) {
+				t.Errorf("Split(%q, %q).Texts() = %q, want %q", tt.text, tt.lang, got, tt.texts)
+			}
+			if got := doc.Join(doc.Texts()); got != tt.text {
+				t.Errorf("Join(Split(%q)) = %q", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestNewExceptions_Overrides(t *testing.T) {
+	off := false
+	e, err := NewExceptions(map[string]LanguageExceptions{
+		"es": {Abbreviations: []string{"Cía."}, Remove: []string{"ref."}},
+		"de": {Ordinals: &off},
+	})
+	if err != nil {
+		t.Fatalf("NewExceptions() error = %v", err)
+	}
+
+	tests := []struct {
+		lang  string
+		text  string
+		texts []string
+	}{
+		{"es", "Pérez y Cía. Desde 1950.", []string{"Pérez y Cía. Desde 1950."}},
+		{"es", "Ver ref. Otra frase.", []string{"Ver ref.", "Otra frase."}},
+		{"es", "Pesa aprox. 2 kg.", []string{"Pesa aprox. 2 kg."}},
+		{"de", "Lieferung am 3. Oktober.", []string{"Lieferung am 3.", "Oktober."}},
+	}
+	for _, tt := range tests {
+		if got := e.Split(tt.text, tt.lang).Texts(); !reflect.DeepEqual(got, tt.texts) {
+			t.Errorf("Split(%q, %q).Texts() = %q, want %q", tt.text, tt.lang, got, tt.texts)
+		}
+	}
+
+	if got := DefaultExceptions.Split("Ver ref. Otra frase.", "es").Texts(); len(got) != 1 {
+		t.Errorf("overrides changed the defaults: %q", got)
+	}
+}
+
+func TestNewExceptions_Invalid(t *testing.T) {
+	for _, a := range []string{"aprox", "S. L.", ""} {
+		if _, err := NewExceptions(map[string]LanguageExceptions{"es": {Abbreviations: []string{a}}}); err == nil {
+			t.Errorf("NewExceptions(%q) error = nil, want error", a)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package segment splits long documents into sentences for translation and
+// reassembles the translated sentences with the original spacing.
+package segment
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/locale"
+)
+
+// Segment is a translatable unit and the whitespace that followed it.
+type Segment struct {
+	Text string
+	Sep  string
+	// Joiner is how Sep joins the segment to the next one.
+	Joiner Joiner
+}
+
+// Joiner classifies the separator between two segments, so it can be
+// rewritten for a target language that spaces sentences differently.
+type Joiner int
+
+const (
+	// JoinLayout separators hold line breaks or markup, or end the
+	// document; they are kept verbatim.
+	JoinLayout Joiner = iota
+	// JoinSpace separators are spaces between sentences of one line.
+	JoinSpace
+	// JoinNone is no separator: sentences run together, as in Chinese and
+	// Japanese.
+	JoinNone
+)
+
+// unspacedScripts are the scripts whose sentences follow each other
+// without a space.
+var unspacedScripts = map[string]bool{"Hans": true, "Hant": true, "Jpan": true}
+
+// Document is a document split into segments. Reassembling the segment texts
+// and separators after Lead reproduces the original document exactly.
+type Document struct {
+	Lead     string // Whitespace before the first segment
+	Segments []Segment
+}
+
+// blockMarker matches structural markup at the start of a line that must be
+// kept verbatim: headings, bullets, ordered-list numbers and blockquotes.
+var blockMarker = regexp.MustCompile(`^(?:#{1,6}|[-*+•·‣▪]|\d{1,3}[.)]|[a-z][.)]|>)[ \t]+`)
+
+// ruleLine matches a horizontal rule or underline such as "---" or "===".
+var ruleLine = regexp.MustCompile(`^[-*_=~]{3,}[ \t]*(?:\r?\n|$)`)
+
+// Split splits text into sentences. Sentences end at terminal punctuation
+// followed by whitespace, or at a line break, so they never span paragraphs.
+// Line-leading markup (headings, list markers, rules) is not translatable and
+// is kept in the separators, so Join reproduces the source structure.
+// Split applies no language exceptions; see Exceptions.Split.
+func Split(text string) *Document {
+	return (*Exceptions)(nil).Split(text, "")
+}
+
+// Split splits text like the package-level Split, except that periods ending
+// the abbreviations or ordinals of lang do not end a sentence.
+func (e *Exceptions) Split(text, lang string) *Document {
+	doc := &Document{}
+	set := e.forLang(lang)
+
+	var lead string
+	lead, rest := skipLayout(text, true)
+	doc.Lead = lead
+
+	for rest != "" {
+		end := sentenceEnd(rest, set)
+		sentence := rest[:end]
+
+		var sep string
+		sep, rest = skipLayout(rest[end:], false)
+
+		doc.Segments = append(doc.Segments, Segment{Text: sentence, Sep: sep, Joiner: joinerOf(sep, rest == "")})
+	}
+
+	return doc
+}
+
+// joinerOf classifies the separator sep; last reports the final segment.
+func joinerOf(sep string, last bool) Joiner {
+	switch {
+	case last || strings.ContainsAny(sep, "\n\r") || strings.TrimSpace(sep) != "":
+		return JoinLayout
+	case sep == "":
+		return JoinNone
+	default:
+		return JoinSpace
+	}
+}
+
+// skipLayout consumes whitespace and line-leading markup from s, returning
+// the consumed layout and the remaining text. atLineStart reports whether s
+// begins a line.
+func skipLayout(s string, atLineStart bool) (layout, rest string) {
+	rest = s
+	for {
+		trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if strings.ContainsAny(rest[:len(rest)-len(trimmed)], "\n\r") {
+			atLineStart = true
+		}
+		rest = trimmed
+		if !atLineStart {
+			break
+		}
+
+		marker := ruleLine.FindString(rest)
+		if marker == "" {
+			marker = blockMarker.FindString(rest)
+		}
+		if marker == "" {
+			break
+		}
+		rest = rest[len(marker):]
+		// A rule ends its line; a blockquote may wrap another marker ("> - item")
+		atLineStart = strings.HasSuffix(marker, "\n") || strings.HasPrefix(marker, ">")
+	}
+	return s[:len(s)-len(rest)], rest
+}
+
+// sentenceEnd returns the byte offset just past the first sentence in s.
+// s must not start with whitespace.
+func sentenceEnd(s string, set exceptionSet) int {
+	for i, r := range s {
+		if r == '\n' || r == '\r' {
+			return trimRightSpace(s, i)
+		}
+		if !isTerminal(r) {
+			continue
+		}
+
+		// Include closing punctuation and quotes: "Fin." or (fin!)
+		end := i + utf8.RuneLen(r)
+		for end < len(s) {
+			next, size := utf8.DecodeRuneInString(s[end:])
+			if !isTerminal(next) && !isCloser(next) {
+				break
+			}
+			end += size
+		}
+
+		if end == len(s) || isFullWidth(r) {
+			return end
+		}
+		if next, _ := utf8.DecodeRuneInString(s[end:]); unicode.IsSpace(next) && !isInitial(s[:i]) && !set.continues(s, i, s[end:]) {
+			return end
+		}
+	}
+	return len(s)
+}
+
+// trimRightSpace returns the end of s[:i] without trailing spaces.
+func trimRightSpace(s string, i int) int {
+	return len(strings.TrimRightFunc(s[:i], unicode.IsSpace))
+}
+
+func isTerminal(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// isFullWidth reports whether r is a terminal of scripts written without
+// spaces, which ends a sentence even when the next one follows directly.
+func isFullWidth(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '»', '”', '’', '」', '』':
+		return true
+	}
+	return false
+}
+
+// isInitial reports whether the text before a period ends with a single
+// capital letter, as in "J. Smith", which does not end a sentence.
+func isInitial(before string) bool {
+	last, size := utf8.DecodeLastRuneInString(before)
+	if !unicode.IsUpper(last) {
+		return false
+	}
+	prev, _ := utf8.DecodeLastRuneInString(before[:len(before)-size])
+	return len(before) == size || unicode.IsSpace(prev)
+}
+
+// Texts returns the segment texts in order.
+func (d *Document) Texts() []string {
+	texts := make([]string, len(d.Segments))
+	for i, s := range d.Segments {
+		texts[i] = s.Text
+	}
+	return texts
+}
+
+// Join reassembles the document with translations in place of the segment
+// texts. translations must have one entry per segment.
+func (d *Document) Join(translations []string) string {
+	var b strings.Builder
+	b.WriteString(d.Lead)
+	for i, s := range d.Segments {
+		b.WriteString(translations[i])
+		b.WriteString(s.Sep)
+	}
+	return b.String()
+}
+
+// JoinIn reassembles the document like Join, with the sentence separators
+// of lang: sentences on one line are run together in Chinese and Japanese
+// and separated by a space elsewhere, whatever the source did. Layout is
+// kept, and whitespace a translator added around a sentence is dropped.
+func (d *Document) JoinIn(translations []string, lang string) string {
+	unspaced := unspacedScripts[locale.Describe(lang).Script]
+	var b strings.Builder
+	b.WriteString(d.Lead)
+	for i, s := range d.Segments {
+		b.WriteString(strings.TrimSpace(translations[i]))
+		switch {
+		case s.Joiner == JoinLayout:
+			b.WriteString(s.Sep)
+		case unspaced:
+		case s.Joiner == JoinSpace:
+			b.WriteString(s.Sep)
+		default:
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
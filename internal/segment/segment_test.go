@@ -0,0 +1,185 @@
+package segment
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		texts []string
+	}{
+		{"empty", "", []string{}},
+		{"whitespace only", "  \n ", []string{}},
+		{"single sentence", "Hola mundo", []string{"Hola mundo"}},
+		{"two sentences", "Hola mundo. Adiós mundo.", []string{"Hola mundo.", "Adiós mundo."}},
+		{"question and exclamation", "¿Qué tal? ¡Muy bien!", []string{"¿Qué tal?", "¡Muy bien!"}},
+		{"closing quote", `Dijo "vale." Luego se fue.`, []string{`Dijo "vale."`, "Luego se fue."}},
+		{"ellipsis", "Espera... Ya está.", []string{"Espera...", "Ya está."}},
+		{"decimal number", "Cuesta 3.5 euros. Barato.", []string{"Cuesta 3.5 euros.", "Barato."}},
+		{"initial", "Escrito por J. Smith hoy.", []string{"Escrito por J. Smith hoy."}},
+		{"line break", "Título\nTexto del anuncio", []string{"Título", "Texto del anuncio"}},
+		{"full-width terminals", "你好。再见！", []string{"你好。", "再见！"}},
+		{"full-width closing bracket", "「はい。」いいえ。", []string{"「はい。」", "いいえ。"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Split(tt.text)
+			if got := doc.Texts(); !reflect.DeepEqual(got, tt.texts) {
+				t.Errorf("Split(%q).Texts() = %q, want %q", tt.text, got, tt.texts)
+			}
+		})
+	}
+}
+
+func TestJoin_RoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"  Hola mundo.  Adiós.\n\nNuevo párrafo!  ",
+		"Línea con espacios   \n  siguiente línea",
+		"Sin puntuación final",
+	}
+
+	for _, text := range texts {
+		doc := Split(text)
+		if got := doc.Join(doc.Texts()); got != text {
+			t.Errorf("Join(Split(%q)) = %q", text, got)
+		}
+	}
+}
+
+func TestJoin_Translations(t *testing.T) {
+	doc := Split("Hola mundo.  Adiós.\n\nFin")
+	translations := make([]string, len(doc.Segments))
+	for i, s := range doc.Texts() {
+		translations[i] = strings.ToUpper(s)
+	}
+
+	want := "HOLA MUNDO.  ADIÓS.\n\nFIN"
+	if got := doc.Join(translations); got != want {
+		t.Errorf("Join() = %q, want %q", got, want)
+	}
+}
+
+func TestSplit_Structure(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		texts []string
+	}{
+		{
+			name:  "headings",
+			text:  "# Características\n\nMuy buen estado.\n\n## Envío\nGratis.",
+			texts: []string{"Características", "Muy buen estado.", "Envío", "Gratis."},
+		},
+		{
+			name:  "bullet list",
+			text:  "Incluye:\n- Cargador original\n* Funda\n• Caja",
+			texts: []string{"Incluye:", "Cargador original", "Funda", "Caja"},
+		},
+		{
+			name:  "ordered list",
+			text:  "1. Encender el equipo.\n2) Pulsar el botón.\na. Esperar.",
+			texts: []string{"Encender el equipo.", "Pulsar el botón.", "Esperar."},
+		},
+		{
+			name:  "nested quote and rule",
+			text:  "> - Citado\n---\nDespués de la línea",
+			texts: []string{"Citado", "Después de la línea"},
+		},
+		{
+			name:  "markers only at line start",
+			text:  "Precio - 20 euros. # no es título",
+			texts: []string{"Precio - 20 euros.", "# no es título"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Split(tt.text)
+			if got := doc.Texts(); !reflect.DeepEqual(got, tt.texts) {
+				t.Errorf("Split(%q).Texts() = %q, want %q", tt.text, got, tt.texts)
+			}
+			if got := doc.Join(doc.Texts()); got != tt.text {
+				t.Errorf("Join(Split(%q)) = %q", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestJoin_PreservesParagraphs(t *testing.T) {
+	source := "# Título\n\nPrimera frase. Segunda frase.\n\n\n- Uno\n- Dos\n\n1. Paso\n"
+	translations := map[string]string{
+		"Título":         "Title",
+		"Primera frase.": "First sentence.",
+		"Segunda frase.": "Second sentence.",
+		"Uno":            "One",
+		"Dos":            "Two",
+		"Paso":           "Step",
+	}
+
+	doc := Split(source)
+	out := make([]string, len(doc.Segments))
+	for i, text := range doc.Texts() {
+		out[i] = translations[text]
+	}
+
+	want := "# Title\n\nFirst sentence. Second sentence.\n\n\n- One\n- Two\n\n1. Step\n"
+	if got := doc.Join(out); got != want {
+		t.Errorf("Join() = %q, want %q", got, want)
+	}
+}
+
+func TestSplit_CRLF(t *testing.T) {
+	text := "Línea uno\r\n\r\n- Línea dos\r\n"
+	doc := Split(text)
+
+	if want := []string{"Línea uno", "Línea dos"}; !reflect.DeepEqual(doc.Texts(), want) {
+		t.Errorf("Split() texts = %q, want %q", doc.Texts(), want)
+	}
+	if got := doc.Join(doc.Texts()); got != text {
+		t.Errorf("Join(Split()) = %q, want %q", got, text)
+	}
+}
+
+func TestSplit_Joiners(t *testing.T) {
+	doc := Split("Uno. Dos.\nTres。四。")
+	want := []Joiner{JoinSpace, JoinLayout, JoinNone, JoinLayout}
+	got := make([]Joiner, len(doc.Segments))
+	for i, s := range doc.Segments {
+		got[i] = s.Joiner
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("joiners = %v, want %v", got, want)
+	}
+}
+
+func TestJoinIn(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		lang         string
+		translations []string
+		want         string
+	}{
+		{"spaced to spaced", "Hola.  Adiós.\n\nFin.", "en", []string{"Hello.", "Bye.", "End."}, "Hello.  Bye.\n\nEnd."},
+		{"spaced to Chinese", "Hola. Adiós.\nFin.", "zh", []string{"你好。", "再见。", "结束。"}, "你好。再见。\n结束。"},
+		{"traditional Chinese", "Hola. Adiós.", "zh_TW", []string{"你好。", "再見。"}, "你好。再見。"},
+		{"Japanese to spaced", "こんにちは。さようなら。", "de", []string{"Hallo.", "Tschüss."}, "Hallo. Tschüss."},
+		{"Korean is spaced", "Hola. Adiós.", "ko", []string{"안녕하세요.", "안녕히 가세요."}, "안녕하세요. 안녕히 가세요."},
+		{"translator whitespace", " Hola. Adiós. ", "en", []string{" Hello. ", "Bye.\n"}, " Hello. Bye. "},
+		{"markup kept", "- Uno. Dos.\n- Tres.", "ja", []string{"一。", "二。", "三。"}, "- 一。二。\n- 三。"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text).JoinIn(tt.translations, tt.lang); got != tt.want {
+				t.Errorf("JoinIn() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// batcher coalesces small concurrent requests with the same pair and options
+// into one translation during a short window, then splits the response back
+// per caller. Chatty callers sending one or two texts per request then share
+// translator invocations instead of paying for one each.
+type batcher struct {
+	window    time.Duration
+	maxTexts  int
+	translate TranslateFunc
+
+	mu      sync.Mutex
+	pending map[string]*batch
+}
+
+// batch is a coalesced request collecting texts until its window closes.
+type batch struct {
+	ctx     context.Context
+	req     handler.Request
+	waiters []*waiter
+	timer   *time.Timer
+}
+
+// waiter is one caller's share of a batch.
+type waiter struct {
+	offset, n int
+	done      chan batchResult
+}
+
+type batchResult struct {
+	resp *handler.Response
+	err  error
+}
+
+func newBatcher(window time.Duration, translate TranslateFunc) *batcher {
+	return &batcher{
+		window:    window,
+		maxTexts:  chunker.DefaultMaxTextsPerChunk,
+		translate: translate,
+		pending:   map[string]*batch{},
+	}
+}
+
+// Translate translates req, batching it with concurrent requests when it is
+// small enough and has no options that prevent merging.
+func (b *batcher) Translate(ctx context.Context, req handler.Request) (*handler.Response, error) {
+	key, ok := batchKey(req)
+	if !ok || len(req.Texts) >= b.maxTexts {
+		return b.translate(ctx, req)
+	}
+
+	w := b.add(ctx, key, req)
+	select {
+	case r := <-w.done:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// add appends req to the open batch for key, starting a new batch when there
+// is none or it would overflow a chunk.
+func (b *batcher) add(ctx context.Context, key string, req handler.Request) *waiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bt := b.pending[key]
+	if bt != nil && len(bt.req.Texts)+len(req.Texts) > b.maxTexts {
+		b.closeLocked(key, bt)
+		bt = nil
+	}
+	if bt == nil {
+		merged := req
+		merged.Texts = nil
+		// The batch outlives the first caller's request
+		bt = &batch{ctx: context.WithoutCancel(ctx), req: merged}
+		bt.timer = time.AfterFunc(b.window, func() { b.close(key, bt) })
+		b.pending[key] = bt
+	}
+
+	w := &waiter{offset: len(bt.req.Texts), n: len(req.Texts), done: make(chan batchResult, 1)}
+	bt.req.Texts = append(bt.req.Texts, req.Texts...)
+	bt.waiters = append(bt.waiters, w)
+	if len(bt.req.Texts) == b.maxTexts {
+		b.closeLocked(key, bt)
+	}
+	return w
+}
+
+// close sends bt once its window elapses.
+func (b *batcher) close(key string, bt *batch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked(key, bt)
+}
+
+// closeLocked removes bt from the pending batches and sends it. b.mu must be held.
+func (b *batcher) closeLocked(key string, bt *batch) {
+	if b.pending[key] != bt {
+		return // already sent
+	}
+	delete(b.pending, key)
+	bt.timer.Stop()
+	go b.send(bt)
+}
+
+// send translates a batch and hands every waiter its share of the response.
+func (b *batcher) send(bt *batch) {
+	resp, err := b.translate(bt.ctx, bt.req)
+	for _, w := range bt.waiters {
+		if err != nil {
+			w.done <- batchResult{err: err}
+			continue
+		}
+		w.done <- batchResult{resp: splitResponse(resp, w.offset, w.n)}
+	}
+}
+
+// batchKey returns the key grouping requests that may be merged: same pair
+// and identical options. ok is false for requests that must run alone,
+// including those with per-text options such as ContentTypes and those
+// owed their own deprecation warnings.
+func batchKey(req handler.Request) (string, bool) {
+	if len(req.Texts) == 0 || req.Text != "" || req.Async || req.JobID != "" || len(req.ContentTypes) > 0 || len(req.Tags) > 0 || len(req.Hashes) > 0 ||
+		len(req.Deprecations) > 0 || req.MaxCost > 0 || req.TimeoutMs > 0 || req.IdempotencyKey != "" ||
+		(req.Action != "" && req.Action != handler.ActionTranslate) {
+		return "", false
+	}
+	req.Texts = nil
+	key, err := json.Marshal(req)
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+// splitResponse returns the part of a batch response covering texts
+// [offset, offset+n), with per-text indices rebased to the caller's request.
+func splitResponse(resp *handler.Response, offset, n int) *handler.Response {
+	out := *resp
+	if resp.Error != nil {
+		return &out
+	}
+
+	out.Translations = resp.Translations[offset : offset+n]
+	if resp.Confidence != nil {
+		out.Confidence = resp.Confidence[offset : offset+n]
+	}
+	if resp.Slugs != nil {
+		out.Slugs = resp.Slugs[offset : offset+n]
+	}
+	if resp.Truncated != nil {
+		out.Truncated = resp.Truncated[offset : offset+n]
+	}
+	if resp.Intermediate != nil {
+		out.Intermediate = make([]handler.IntermediateTexts, len(resp.Intermediate))
+		for i, pivot := range resp.Intermediate {
+			out.Intermediate[i] = handler.IntermediateTexts{Lang: pivot.Lang, Translations: pivot.Translations[offset : offset+n]}
+		}
+	}
+
+	out.LowConfidence = nil
+	for _, i := range resp.LowConfidence {
+		if i >= offset && i < offset+n {
+			out.LowConfidence = append(out.LowConfidence, i-offset)
+		}
+	}
+
+	out.Blocked = nil
+	for _, m := range resp.Blocked {
+		if m.Index >= offset && m.Index < offset+n {
+			out.Blocked = append(out.Blocked, blocklist.Match{Index: m.Index - offset, Terms: m.Terms, Action: m.Action})
+		}
+	}
+
+	// Batch-wide warnings go to every caller, per-text ones to their owner
+	out.Warnings = nil
+	for _, w := range resp.Warnings {
+		if w.Index == nil {
+			out.Warnings = append(out.Warnings, w)
+			continue
+		}
+		if i := *w.Index; i >= offset && i < offset+n {
+			i -= offset
+			w.Index = &i
+			out.Warnings = append(out.Warnings, w)
+		}
+	}
+	return &out
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/blocklist"
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// countingTranslate wraps upper and counts calls.
+type countingTranslate struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (c *countingTranslate) translate(ctx context.Context, req handler.Request) (*handler.Response, error) {
+	c.calls.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return upper(ctx, req)
+}
+
+// translateConcurrently sends reqs at once and returns the responses in order.
+func translateConcurrently(t *testing.T, b *batcher, reqs []handler.Request) []*handler.Response {
+	t.Helper()
+	resps := make([]*handler.Response, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req handler.Request) {
+			defer wg.Done()
+			resps[i], errs[i] = b.Translate(context.Background(), req)
+		}(i, req)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	}
+	return resps
+}
+
+func TestBatcher_Coalesces(t *testing.T) {
+	tr := &countingTranslate{}
+	b := newBatcher(50*time.Millisecond, tr.translate)
+
+	reqs := make([]handler.Request, 10)
+	for i := range reqs {
+		reqs[i] = handler.Request{SourceLang: "es", TargetLang: "en", Texts: []string{fmt.Sprintf("a%d", i), fmt.Sprintf("b%d", i)}}
+	}
+	resps := translateConcurrently(t, b, reqs)
+
+	if got := tr.calls.Load(); got != 1 {
+		t.Errorf("translate called %d times, want 1", got)
+	}
+	for i, resp := range resps {
+		if len(resp.Translations) != 2 || resp.Translations[0] != fmt.Sprintf("A%d", i) || resp.Translations[1] != fmt.Sprintf("B%d", i) {
+			t.Errorf("response %d = %q", i, resp.Translations)
+		}
+	}
+}
+
+func TestBatcher_KeepsPairsApart(t *testing.T) {
+	tr := &countingTranslate{}
+	b := newBatcher(20*time.Millisecond, tr.translate)
+
+	translateConcurrently(t, b, []handler.Request{
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}},
+		{SourceLang: "es", TargetLang: "fr", Texts: []string{"b"}},
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"c"}, IncludeConfidence: true},
+	})
+
+	if got := tr.calls.Load(); got != 3 {
+		t.Errorf("translate called %d times, want 3 (different pairs or options)", got)
+	}
+}
+
+func TestBatcher_FlushesFullChunks(t *testing.T) {
+	tr := &countingTranslate{}
+	b := newBatcher(time.Hour, tr.translate)
+
+	reqs := make([]handler.Request, 5)
+	for i := range reqs {
+		reqs[i] = handler.Request{SourceLang: "es", TargetLang: "en", Texts: make([]string, 10)}
+	}
+	translateConcurrently(t, b, reqs) // 50 texts fill a chunk without waiting for the window
+
+	if got := tr.calls.Load(); got != 1 {
+		t.Errorf("translate called %d times, want 1", got)
+	}
+}
+
+func TestBatcher_Passthrough(t *testing.T) {
+	tr := &countingTranslate{}
+	b := newBatcher(time.Hour, tr.translate)
+
+	tests := []handler.Request{
+		{SourceLang: "es", TargetLang: "en", Text: "Hola. Adiós."},
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}, Async: true},
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}, Action: handler.ActionValidate},
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}, ContentTypes: []string{handler.ContentTitle}},
+		{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}, Deprecations: []handler.Deprecation{{Field: "sourceLanguage", Replacement: "sourceLang"}}},
+		{SourceLang: "es", TargetLang: "en", Texts: make([]string, 60)},
+	}
+	for _, req := range tests {
+		if _, err := b.Translate(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := tr.calls.Load(); got != int32(len(tests)) {
+		t.Errorf("translate called %d times, want %d", got, len(tests))
+	}
+}
+
+func TestBatcher_Error(t *testing.T) {
+	tr := &countingTranslate{err: errors.New("boom")}
+	b := newBatcher(10*time.Millisecond, tr.translate)
+
+	if _, err := b.Translate(context.Background(), handler.Request{SourceLang: "es", TargetLang: "en", Texts: []string{"a"}}); err == nil {
+		t.Error("Translate() should return the batch error")
+	}
+}
+
+func TestSplitResponse(t *testing.T) {
+	resp := &handler.Response{
+		Translations:  []string{"a", "b", "c", "d"},
+		Confidence:    []float64{0.9, 0.2, 0.8, 0.1},
+		LowConfidence: []int{1, 3},
+		Slugs:         []string{"a", "b", "c", "d"},
+		Truncated:     []string{"a", "b", "c", "d"},
+		Intermediate:  []handler.IntermediateTexts{{Lang: "en", Translations: []string{"a", "b", "c", "d"}}},
+		Blocked:       []blocklist.Match{{Index: 0, Action: blocklist.Review}, {Index: 2, Action: blocklist.Review}},
+		Warnings: []handler.Warning{
+			{Code: handler.WarningDeadlineRisk},
+			{Code: handler.WarningLongToken, Index: intPtr(1)},
+			{Code: handler.WarningLongToken, Index: intPtr(3)},
+		},
+	}
+
+	got := splitResponse(resp, 2, 2)
+
+	if len(got.Translations) != 2 || got.Translations[0] != "c" || got.Confidence[1] != 0.1 {
+		t.Errorf("split = %q, %v", got.Translations, got.Confidence)
+	}
+	if len(got.Slugs) != 2 || got.Slugs[0] != "c" {
+		t.Errorf("Slugs = %q, want [c d]", got.Slugs)
+	}
+	if len(got.Truncated) != 2 || got.Truncated[1] != "d" {
+		t.Errorf("Truncated = %q, want [c d]", got.Truncated)
+	}
+	if len(got.Intermediate) != 1 || len(got.Intermediate[0].Translations) != 2 || got.Intermediate[0].Translations[0] != "c" {
+		t.Errorf("Intermediate = %+v, want [c d] in en", got.Intermediate)
+	}
+	if len(got.LowConfidence) != 1 || got.LowConfidence[0] != 1 {
+		t.Errorf("LowConfidence = %v, want [1]", got.LowConfidence)
+	}
+	if len(got.Blocked) != 1 || got.Blocked[0].Index != 0 {
+		t.Errorf("Blocked = %+v, want index 0", got.Blocked)
+	}
+	if len(got.Warnings) != 2 || got.Warnings[0].Index != nil || *got.Warnings[1].Index != 1 {
+		t.Errorf("Warnings = %+v, want the batch warning and index 1", got.Warnings)
+	}
+	if *resp.Warnings[2].Index != 3 {
+		t.Error("splitResponse() modified the batch warnings")
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Responses are compressed with brotli or gzip when the client accepts it
+// (Accept-Encoding). Translated texts and catalogs compress very well, and
+// callers in other regions pay for the egress. Bodies shorter than
+// minCompressBytes are sent as is, since compressing them saves nothing.
+// Streaming responses are compressed too: every flush of the stream flushes
+// the compressor, so lines still reach the client as chunks complete.
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// minCompressBytes is the smallest body worth compressing.
+const minCompressBytes = 1024
+
+// brotliLevel trades ratio for CPU; levels above 5 are much slower for
+// little gain on JSON.
+const brotliLevel = 5
+
+// encoder is a streaming compressor.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compress wraps next so that its responses are compressed when the client
+// accepts it.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				slog.Warn("failed to finish compressed response", slog.String("encoding", encoding), slog.Any("error", err))
+			}
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the response encoding for an Accept-Encoding
+// header: the supported one with the highest q-value, brotli on ties, or ""
+// to send the body uncompressed.
+func negotiateEncoding(accept string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		var encoding string
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case encodingBrotli, "*":
+			encoding = encodingBrotli
+		case encodingGzip, "x-gzip":
+			encoding = encodingGzip
+		default:
+			continue
+		}
+		if q > bestQ || q == bestQ && q > 0 && encoding == encodingBrotli {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter compresses a response body. The first minCompressBytes are
+// buffered to decide whether compression is worth it; a flush decides early.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	started  bool
+	enc      encoder
+}
+
+// WriteHeader records the status; headers are sent with the first body bytes.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write compresses p, or buffers it until the body is known to be large
+// enough.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	switch {
+	case w.enc != nil:
+		return w.enc.Write(p)
+	case w.started:
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= minCompressBytes {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what has been written so far, compressed.
+func (w *compressWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if !w.started {
+		if err := w.start(true); err != nil {
+			slog.Warn("failed to write response", slog.Any("error", err))
+			return
+		}
+	}
+	if w.enc != nil {
+		if err := w.enc.Flush(); err != nil {
+			slog.Warn("failed to flush compressor", slog.String("encoding", w.encoding), slog.Any("error", err))
+			return
+		}
+	}
+	if err := http.NewResponseController(w.ResponseWriter).Flush(); err != nil {
+		slog.Warn("failed to flush response", slog.Any("error", err))
+	}
+}
+
+// Close writes a body still buffered (uncompressed, as it is small) and
+// finishes the compressed stream.
+func (w *compressWriter) Close() error {
+	if !w.started {
+		if w.status == 0 {
+			return nil
+		}
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start sends the headers, choosing whether to compress, and the buffered
+// body.
+func (w *compressWriter) start(compressed bool) error {
+	w.started = true
+	h := w.Header()
+	if compressed && bodyAllowed(w.status) && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		// The compressed body is a different representation
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = newEncoder(w.ResponseWriter, w.encoding)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffered := w.buf
+	w.buf = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buffered)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
+
+// newEncoder returns a compressor writing to w.
+func newEncoder(w io.Writer, encoding string) encoder {
+	if encoding == encodingBrotli {
+		return brotli.NewWriterLevel(w, brotliLevel)
+	}
+	return gzip.NewWriter(w)
+}
+
+// bodyAllowed reports whether a response with status may have a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
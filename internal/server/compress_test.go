@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", encodingGzip},
+		{"gzip, deflate, br", encodingBrotli},
+		{"br;q=0.5, gzip", encodingGzip},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", encodingBrotli},
+		{"GZIP;q=0.8, deflate", encodingGzip},
+		{"br;q=bogus, gzip", encodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := negotiateEncoding(tt.accept); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+// decode returns the decompressed body of rec.
+func decode(t *testing.T, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch rec.Header().Get("Content-Encoding") {
+	case encodingGzip:
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		r = zr
+	case encodingBrotli:
+		r = brotli.NewReader(rec.Body)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	return body
+}
+
+func TestServer_Compression(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		accept       string
+		wantEncoding string
+	}{
+		{"brotli", "/languages", "gzip, br", encodingBrotli},
+		{"gzip", "/languages", "gzip", encodingGzip},
+		{"not accepted", "/languages", "", ""},
+		{"small body", "/health", "br", ""},
+	}
+
+	srv := New(Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if rec.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q", rec.Header().Get("Vary"))
+			}
+			var v map[string]any
+			if err := json.Unmarshal(decode(t, rec), &v); err != nil {
+				t.Errorf("body is not JSON: %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_CompressionRevalidation(t *testing.T) {
+	srv := New(Options{})
+
+	req := httptest.NewRequest(http.MethodGet, "/languages", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("compressed ETag = %q, want a weak ETag", etag)
+	}
+
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("revalidation = %d %q (%d bytes)", rec.Code, rec.Header().Get("Content-Encoding"), rec.Body.Len())
+	}
+}
+
+func TestServer_CompressedStream(t *testing.T) {
+	body := `{"sourceLang": "es", "targetLang": "en"}` + "\n" + strings.Repeat(`"hola"`+"\n", 120)
+	req := httptest.NewRequest(http.MethodPost, "/translate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	New(Options{Translate: upper}).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != encodingGzip {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(decode(t, rec))))
+	lines := 0
+	var last map[string]any
+	for scanner.Scan() {
+		lines++
+		last = nil
+		if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+			t.Fatalf("line %d: %v", lines, err)
+		}
+	}
+	if lines != 121 || last["done"] != true {
+		t.Errorf("got %d lines ending with %v", lines, last)
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/logging"
+	"github.com/pricofy/translation-manager/internal/protoapi"
+)
+
+// handleProtobuf serves POST /translate with a protobuf TranslateRequest
+// body, answering with a TranslateResponse and the statuses of JSON
+// requests.
+func (s *Server) handleProtobuf(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeProtobuf(w, http.StatusRequestEntityTooLarge, handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()))
+		return
+	}
+	req, err := handler.ParseProtobufRequest(body)
+	if err != nil {
+		writeProtobuf(w, http.StatusBadRequest, handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()))
+		return
+	}
+
+	ctx := logging.WithRequestID(r.Context(), r.Header.Get(requestIDHeader))
+	resp, err := s.batched(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "translate failed", slog.Any("error", err))
+		writeProtobuf(w, http.StatusInternalServerError, handler.NewErrorResponse(handler.ErrorInternal, "internal error"))
+		return
+	}
+	writeProtobuf(w, http.StatusOK, resp)
+}
+
+// writeProtobuf writes resp as a TranslateResponse.
+func writeProtobuf(w http.ResponseWriter, status int, resp *handler.Response) {
+	data, err := handler.EncodeProtobufResponse(resp)
+	if err != nil {
+		slog.Error("failed to encode protobuf response", slog.Any("error", err))
+		writeJSON(w, http.StatusInternalServerError, handler.NewErrorResponse(handler.ErrorInternal, "internal error"))
+		return
+	}
+	w.Header().Set("Content-Type", protoapi.ContentType)
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		slog.Warn("failed to write response", slog.Any("error", err))
+	}
+}
+
+// isProtobuf reports whether a Content-Type is protobuf.
+func isProtobuf(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case protoapi.ContentType, "application/protobuf":
+		return true
+	default:
+		return false
+	}
+}
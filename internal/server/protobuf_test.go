@@ -0,0 +1,58 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/pricofy/translation-manager/internal/protoapi"
+)
+
+func TestServer_TranslateProtobuf(t *testing.T) {
+	var body []byte
+	body = protowire.AppendString(protowire.AppendTag(body, 1, protowire.BytesType), "hola")
+	body = protowire.AppendString(protowire.AppendTag(body, 2, protowire.BytesType), "es")
+	body = protowire.AppendString(protowire.AppendTag(body, 3, protowire.BytesType), "en")
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+		wantField  protowire.Number // translations or error
+	}{
+		{"translates", body, http.StatusOK, 1},
+		{"invalid request", []byte{0xff}, http.StatusBadRequest, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/translate", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", protoapi.ContentType)
+			rec := httptest.NewRecorder()
+			New(Options{Translate: upper}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Header().Get("Content-Type") != protoapi.ContentType {
+				t.Fatalf("status = %d, Content-Type = %q, want %d protobuf", rec.Code, rec.Header().Get("Content-Type"), tt.wantStatus)
+			}
+			fields := map[protowire.Number]bool{}
+			for data := rec.Body.Bytes(); len(data) > 0; {
+				number, typ, n := protowire.ConsumeTag(data)
+				if n < 0 {
+					t.Fatalf("malformed response %x", rec.Body.Bytes())
+				}
+				fields[number] = true
+				data = data[n+protowire.ConsumeFieldValue(number, typ, data[n:]):]
+			}
+			if !fields[tt.wantField] {
+				t.Errorf("response fields = %v, want field %d", fields, tt.wantField)
+			}
+		})
+	}
+
+	if !isProtobuf("application/protobuf; charset=binary") || isProtobuf("application/json") {
+		t.Error("isProtobuf() misclassifies content types")
+	}
+}
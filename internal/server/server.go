@@ -0,0 +1,218 @@
+// Package server exposes the translation manager over HTTP, for running it
+// as a long-lived service (cmd/server) instead of a Lambda function.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pricofy/translation-manager/api"
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/logging"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// DefaultConcurrency is the number of chunks a streaming request may have in
+// flight when Options.Concurrency is unset.
+const DefaultConcurrency = 4
+
+// maxBodyBytes limits non-streaming request bodies.
+const maxBodyBytes = 32 << 20
+
+// requestIDHeader carries the caller's request ID, logged as requestId.
+// Requests coalesced by the batcher log under the first one's ID.
+const requestIDHeader = "X-Request-Id"
+
+// TranslateFunc handles one translation request, e.g. (*handler.Handler).Handle.
+type TranslateFunc func(ctx context.Context, req handler.Request) (*handler.Response, error)
+
+// Options configures a Server.
+type Options struct {
+	// Translate handles requests; required to serve /translate.
+	Translate TranslateFunc
+	// Concurrency bounds the chunks of a streaming request that are being
+	// translated at once. Defaults to DefaultConcurrency.
+	Concurrency int
+	// BatchWindow coalesces small concurrent JSON requests with the same pair
+	// and options received within the window into one translation. Zero
+	// disables micro-batching.
+	BatchWindow time.Duration
+}
+
+// Server serves translation requests over HTTP:
+//
+//	POST /translate                       JSON request, or an NDJSON stream (see stream.go)
+//	GET  /languages                       supported languages and pairs, with ETag revalidation
+//	GET  /health                          liveness check
+//	GET  /openapi.json                    OpenAPI description of this API (see api/)
+//	GET  /schemas/request.json            JSON Schema of a request
+//	GET  /schemas/response.json           JSON Schema of a response
+//	GET  /schemas/request/{version}.json  JSON Schema of a request contract version, e.g. v1
+//
+// Responses are compressed when the client accepts it (see compress.go).
+type Server struct {
+	translate   TranslateFunc
+	batched     TranslateFunc
+	concurrency int
+	mux         *http.ServeMux
+	handler     http.Handler
+}
+
+// New creates a Server.
+func New(opts Options) *Server {
+	s := &Server{
+		translate:   opts.Translate,
+		concurrency: opts.Concurrency,
+		mux:         http.NewServeMux(),
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = DefaultConcurrency
+	}
+	s.batched = s.translate
+	if opts.BatchWindow > 0 {
+		s.batched = newBatcher(opts.BatchWindow, s.translate).Translate
+	}
+
+	s.mux.HandleFunc("/translate", s.handleTranslate)
+	s.mux.HandleFunc("/languages", s.handleLanguages)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/openapi.json", serveDocument(api.OpenAPI))
+	s.mux.HandleFunc("/schemas/request.json", serveDocument(api.RequestSchema))
+	s.mux.HandleFunc("/schemas/response.json", serveDocument(api.ResponseSchema))
+	s.mux.HandleFunc("/schemas/request/", serveRequestSchema)
+	s.handler = compress(s.mux)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// handleTranslate serves POST /translate. Responses use the Lambda response
+// format; request errors are reported with status 400 and Response.Error.
+func (s *Server) handleTranslate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, handler.NewErrorResponse(handler.ErrorInvalidRequest, "method not allowed"))
+		return
+	}
+	if isNDJSON(r.Header.Get("Content-Type")) {
+		s.handleStream(w, r)
+		return
+	}
+	if isProtobuf(r.Header.Get("Content-Type")) {
+		s.handleProtobuf(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()))
+		return
+	}
+	req, err := handler.ParseRequest(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()))
+		return
+	}
+
+	ctx := logging.WithRequestID(r.Context(), r.Header.Get(requestIDHeader))
+	resp, err := s.batched(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "translate failed", slog.Any("error", err))
+		writeJSON(w, http.StatusInternalServerError, handler.NewErrorResponse(handler.ErrorInternal, "internal error"))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// catalogMaxAge is how long clients may use a catalog without revalidating.
+const catalogMaxAge = time.Hour
+
+// handleLanguages serves GET /languages. The ETag is the catalog version, so
+// clients revalidate with If-None-Match and get 304 while it is unchanged.
+func (s *Server) handleLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, handler.NewErrorResponse(handler.ErrorInvalidRequest, "method not allowed"))
+		return
+	}
+
+	catalog := router.GetCatalog()
+	etag := `"` + catalog.Version + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(catalogMaxAge.Seconds())))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, catalog)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// handleHealth serves GET /health.
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// serveDocument serves a generated API document (see api/).
+func serveDocument(doc []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, handler.NewErrorResponse(handler.ErrorInvalidRequest, "method not allowed"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(catalogMaxAge.Seconds())))
+		w.Write(doc) //nolint:errcheck // the client went away
+	}
+}
+
+// serveRequestSchema serves GET /schemas/request/{version}.json.
+func serveRequestSchema(w http.ResponseWriter, r *http.Request) {
+	version, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/schemas/request/"), ".json")
+	doc, found := api.RequestSchemaVersion(version)
+	if !ok || !found {
+		writeJSON(w, http.StatusNotFound, handler.NewErrorResponse(handler.ErrorInvalidRequest, "unknown schema version "+version))
+		return
+	}
+	serveDocument(doc)(w, r)
+}
+
+// writeJSON writes v as the response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write response", slog.Any("error", err))
+	}
+}
+
+// isNDJSON reports whether a Content-Type is newline-delimited JSON.
+func isNDJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+	"github.com/pricofy/translation-manager/internal/router"
+)
+
+// upper is a TranslateFunc that upper-cases texts.
+func upper(_ context.Context, req handler.Request) (*handler.Response, error) {
+	translations := make([]string, len(req.Texts))
+	for i, text := range req.Texts {
+		translations[i] = strings.ToUpper(text)
+	}
+	return &handler.Response{Translations: translations, ChunksProcessed: 1}, nil
+}
+
+func TestServer_Translate(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		translate  TranslateFunc
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "translates",
+			method:     http.MethodPost,
+			body:       `{"texts": ["hola"], "sourceLang": "es", "targetLang": "en"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid request",
+			method:     http.MethodPost,
+			body:       `{"texts": "hola", "sourceLang": "es", "targetLang": "en"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "texts: must be an array",
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  "method not allowed",
+		},
+		{
+			name:   "handler failure",
+			method: http.MethodPost,
+			body:   `{"texts": ["hola"], "sourceLang": "es", "targetLang": "en"}`,
+			translate: func(context.Context, handler.Request) (*handler.Response, error) {
+				return nil, errors.New("boom")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translate := tt.translate
+			if translate == nil {
+				translate = upper
+			}
+			srv := New(Options{Translate: translate})
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(tt.method, "/translate", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var resp handler.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON response: %s", rec.Body)
+			}
+			var message string
+			if resp.Error != nil {
+				message = resp.Error.Message
+			}
+			if !strings.Contains(message, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", message, tt.wantError)
+			}
+			if tt.wantError == "" && (len(resp.Translations) != 1 || resp.Translations[0] != "HOLA") {
+				t.Errorf("Translations = %q", resp.Translations)
+			}
+		})
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestServer_Documents(t *testing.T) {
+	srv := New(Options{})
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/openapi.json", http.StatusOK},
+		{http.MethodGet, "/schemas/request.json", http.StatusOK},
+		{http.MethodGet, "/schemas/response.json", http.StatusOK},
+		{http.MethodGet, "/schemas/request/v1.json", http.StatusOK},
+		{http.MethodGet, "/schemas/request/v0.json", http.StatusNotFound},
+		{http.MethodGet, "/schemas/request/v1", http.StatusNotFound},
+		{http.MethodPost, "/openapi.json", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusOK && !json.Valid(rec.Body.Bytes()) {
+			t.Errorf("%s %s: body is not JSON", tt.method, tt.path)
+		}
+	}
+}
+
+func TestServer_Languages(t *testing.T) {
+	srv := New(Options{})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/languages", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	var catalog router.Catalog
+	if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil || etag != `"`+catalog.Version+`"` {
+		t.Fatalf("ETag %s does not match catalog version %s (%v)", etag, catalog.Version, err)
+	}
+
+	tests := []struct {
+		ifNoneMatch string
+		want        int
+	}{
+		{etag, http.StatusNotModified},
+		{`W/` + etag, http.StatusNotModified},
+		{`"stale", ` + etag, http.StatusNotModified},
+		{`"stale"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/languages", nil)
+		req.Header.Set("If-None-Match", tt.ifNoneMatch)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("If-None-Match %s: status = %d, want %d", tt.ifNoneMatch, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestIsNDJSON(t *testing.T) {
+	tests := map[string]bool{
+		"application/x-ndjson":                true,
+		"application/x-ndjson; charset=utf-8": true,
+		"Application/NDJSON":                  true,
+		"application/json":                    false,
+		"":                                    false,
+	}
+	for contentType, want := range tests {
+		if got := isNDJSON(contentType); got != want {
+			t.Errorf("isNDJSON(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/pricofy/translation-manager/internal/chunker"
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// Streaming requests (Content-Type application/x-ndjson) let very large
+// batches start translating before the upload completes. The first line holds
+// the request options (a JSON request without texts) and every following line
+// is one text as a JSON string:
+//
+//	{"sourceLang": "es", "targetLang": "en"}
+//	"Hola mundo"
+//	"iPhone en buen estado"
+//
+// Texts are dispatched as soon as a chunk is full, with at most Concurrency
+// chunks waiting for translators. When translators fall behind the server
+// stops reading the body, which pushes back on the client.
+//
+// The response is NDJSON too: one {"index", "translation"} line per text, in
+// input order as chunks complete, then a {"done": true} summary line, or an
+// {"error"} line if the stream fails.
+
+// maxLineBytes limits a single NDJSON line.
+const maxLineBytes = 1 << 20
+
+// streamLine is one translated text of a streaming response.
+type streamLine struct {
+	Index       int    `json:"index"`
+	Translation string `json:"translation"`
+}
+
+// streamSummary ends a successful streaming response.
+type streamSummary struct {
+	Done            bool `json:"done"`
+	Texts           int  `json:"texts"`
+	ChunksProcessed int  `json:"chunksProcessed"`
+}
+
+// streamError ends a failed streaming response.
+type streamError struct {
+	Error string `json:"error"`
+}
+
+// streamChunk is a chunk of a stream being translated.
+type streamChunk struct {
+	offset int
+	texts  []string
+	done   chan struct{}
+	resp   *handler.Response
+	err    error
+}
+
+// handleStream serves an NDJSON POST /translate.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	header, err := readStreamHeader(scanner)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, handler.NewErrorResponse(handler.ErrorInvalidRequest, err.Error()))
+		return
+	}
+
+	// Keep reading the body after the response has started (HTTP/1.x)
+	rc := http.NewResponseController(w)
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(ctx, "full duplex unavailable", slog.Any("error", err))
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	queue := make(chan *streamChunk, s.concurrency)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- s.dispatch(ctx, scanner, header, queue)
+	}()
+
+	enc := json.NewEncoder(w)
+	summary := streamSummary{Done: true}
+	for c := range queue {
+		<-c.done
+		if msg := chunkError(c); msg != "" {
+			cancel()
+			drain(queue)
+			writeLine(enc, rc, streamError{Error: msg})
+			return
+		}
+		for i, translation := range c.resp.Translations {
+			writeLine(enc, nil, streamLine{Index: c.offset + i, Translation: translation})
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.Warn("failed to flush stream", slog.Any("error", err))
+		}
+		summary.Texts += len(c.texts)
+		summary.ChunksProcessed++
+	}
+
+	if err := <-readErr; err != nil {
+		writeLine(enc, rc, streamError{Error: err.Error()})
+		return
+	}
+	writeLine(enc, rc, summary)
+}
+
+// readStreamHeader parses the options line of a stream.
+func readStreamHeader(scanner *bufio.Scanner) (handler.Request, error) {
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		req, err := handler.ParseRequest(scanner.Bytes())
+		if err != nil {
+			return req, fmt.Errorf("stream header: %w", err)
+		}
+		if len(req.Texts) > 0 || req.Text != "" {
+			return req, fmt.Errorf("stream header: texts must be sent as the following lines")
+		}
+		if req.Async || req.Action != "" && req.Action != handler.ActionTranslate {
+			return req, fmt.Errorf("stream header: only synchronous translation can be streamed")
+		}
+		return req, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return handler.Request{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return handler.Request{}, fmt.Errorf("stream header is required")
+}
+
+// dispatch reads texts from the stream and queues them in chunks, starting
+// each chunk's translation once it fits in the queue. It closes queue when
+// the body is consumed or ctx is cancelled.
+func (s *Server) dispatch(ctx context.Context, scanner *bufio.Scanner, header handler.Request, queue chan<- *streamChunk) error {
+	defer close(queue)
+
+	var texts []string
+	offset := 0
+	send := func() error {
+		c := &streamChunk{offset: offset, texts: texts, done: make(chan struct{})}
+		select {
+		case queue <- c:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		go s.translateChunk(ctx, header, c)
+		offset += len(texts)
+		texts = nil
+		return nil
+	}
+
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal(scanner.Bytes(), &text); err != nil {
+			return fmt.Errorf("line %d: must be a JSON string", line)
+		}
+		texts = append(texts, text)
+		if len(texts) == chunker.DefaultMaxTextsPerChunk {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	if len(texts) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// translateChunk translates one chunk with the stream options.
+func (s *Server) translateChunk(ctx context.Context, header handler.Request, c *streamChunk) {
+	defer close(c.done)
+	req := header
+	req.Texts = c.texts
+	c.resp, c.err = s.translate(ctx, req)
+}
+
+// drain waits for the remaining chunks of a cancelled stream, so dispatch
+// and in-flight translations finish before the handler returns.
+func drain(queue <-chan *streamChunk) {
+	for c := range queue {
+		<-c.done
+	}
+}
+
+// chunkError returns the error message of a failed chunk, or "".
+func chunkError(c *streamChunk) string {
+	switch {
+	case c.err != nil:
+		slog.Error("stream chunk failed", slog.Int("offset", c.offset), slog.Any("error", c.err))
+		return "internal error"
+	case c.resp.Error != nil:
+		return c.resp.Error.Message
+	default:
+		return ""
+	}
+}
+
+// writeLine writes one NDJSON line, flushing it when rc is set.
+func writeLine(enc *json.Encoder, rc *http.ResponseController, v any) {
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("failed to write stream", slog.Any("error", err))
+		return
+	}
+	if rc != nil {
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.Warn("failed to flush stream", slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pricofy/translation-manager/internal/handler"
+)
+
+// streamBody builds an NDJSON request with n texts.
+func streamBody(header string, n int) string {
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%q\n", fmt.Sprintf("text %d", i))
+	}
+	return b.String()
+}
+
+// postStream sends an NDJSON request and returns the response lines.
+func postStream(t *testing.T, srv *Server, body io.Reader) (int, []map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/translate", body)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid NDJSON line %q", scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	return rec.Code, lines
+}
+
+func TestStream_Translates(t *testing.T) {
+	srv := New(Options{Translate: upper, Concurrency: 2})
+	code, lines := postStream(t, srv, strings.NewReader(streamBody(`{"sourceLang": "es", "targetLang": "en"}`, 120)))
+
+	if code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if len(lines) != 121 {
+		t.Fatalf("got %d lines, want 120 translations and a summary", len(lines))
+	}
+	for i, line := range lines[:120] {
+		if int(line["index"].(float64)) != i || line["translation"] != fmt.Sprintf("TEXT %d", i) {
+			t.Fatalf("line %d = %v", i, line)
+		}
+	}
+	summary := lines[120]
+	if summary["done"] != true || summary["texts"] != 120.0 || summary["chunksProcessed"] != 3.0 {
+		t.Errorf("summary = %v", summary)
+	}
+}
+
+func TestStream_Errors(t *testing.T) {
+	failing := func(context.Context, handler.Request) (*handler.Response, error) {
+		return handler.NewErrorResponse(handler.ErrorUnsupportedPair, "unsupported language pair: es→xx"), nil
+	}
+
+	tests := []struct {
+		name       string
+		translate  TranslateFunc
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "missing header",
+			body:       "",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "stream header is required",
+		},
+		{
+			name:       "texts in header",
+			body:       `{"sourceLang": "es", "targetLang": "en", "texts": ["a"]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "following lines",
+		},
+		{
+			name:       "invalid text line",
+			body:       "{\"sourceLang\": \"es\", \"targetLang\": \"en\"}\n\"a\"\n{\"text\": 1}\n",
+			wantStatus: http.StatusOK,
+			wantError:  "line 3: must be a JSON string",
+		},
+		{
+			name:       "translation error",
+			translate:  failing,
+			body:       streamBody(`{"sourceLang": "es", "targetLang": "xx"}`, 3),
+			wantStatus: http.StatusOK,
+			wantError:  "unsupported language pair",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translate := tt.translate
+			if translate == nil {
+				translate = upper
+			}
+			code, lines := postStream(t, New(Options{Translate: translate}), strings.NewReader(tt.body))
+			if code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", code, tt.wantStatus)
+			}
+			if len(lines) == 0 {
+				t.Fatal("no response lines")
+			}
+			last := lines[len(lines)-1]
+			// Stream lines carry the message, whole responses an error object
+			msg, _ := last["error"].(string)
+			if obj, ok := last["error"].(map[string]any); ok {
+				msg, _ = obj["message"].(string)
+			}
+			if !strings.Contains(msg, tt.wantError) {
+				t.Errorf("last line = %v, want error containing %q", last, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStream_Backpressure(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	slow := func(ctx context.Context, req handler.Request) (*handler.Response, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		return upper(ctx, req)
+	}
+
+	srv := New(Options{Translate: slow, Concurrency: 2})
+	_, lines := postStream(t, srv, strings.NewReader(streamBody(`{"sourceLang": "es", "targetLang": "en"}`, 500)))
+
+	if len(lines) != 501 {
+		t.Fatalf("got %d lines, want 501", len(lines))
+	}
+	// The writer holds one dequeued chunk while Concurrency more are queued
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak in-flight chunks = %d, want at most 3", got)
+	}
+}
@@ -0,0 +1,143 @@
+// Package slug turns translated titles into URL slugs: lowercase ASCII
+// words joined by hyphens, transliterated according to the title's language.
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxLength caps slugs unless a caller asks otherwise.
+const DefaultMaxLength = 80
+
+// MaxLength is the largest accepted slug length.
+const MaxLength = 200
+
+// folds maps each ASCII letter to the accented letters reduced to it.
+var folds = map[string]string{
+	"a": "àáâãäåāăą",
+	"c": "çćĉċč",
+	"d": "ďđð",
+	"e": "èéêëēĕėęě",
+	"g": "ĝğġģ",
+	"h": "ĥħ",
+	"i": "ìíîïĩīĭįı",
+	"j": "ĵ",
+	"k": "ķ",
+	"l": "ĺļľŀł",
+	"n": "ñńņňŉ",
+	"o": "òóôõöøōŏő",
+	"r": "ŕŗř",
+	"s": "śŝşšș",
+	"t": "ţťŧț",
+	"u": "ùúûüũūŭůűų",
+	"w": "ŵ",
+	"y": "ýÿŷ",
+	"z": "źżž",
+}
+
+// ligatures are letters spelt with several ASCII letters.
+var ligatures = map[rune]string{'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th"}
+
+// cyrillic transliterates Cyrillic letters, Russian-style unless the
+// language overrides them in languageLetters.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz", 'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+}
+
+// norwegianLetters are the Danish and Norwegian spellings of ø and å.
+var norwegianLetters = map[rune]string{'ø': "oe", 'å': "aa"}
+
+// languageLetters override the transliteration for a language, e.g. German
+// umlauts are spelt out rather than dropped.
+var languageLetters = map[string]map[rune]string{
+	"de": {'ä': "ae", 'ö': "oe", 'ü': "ue"},
+	"uk": {'г': "h", 'и': "y"},
+	"bg": {'щ': "sht", 'ъ': "a"},
+	"da": norwegianLetters, "no": norwegianLetters, "nb": norwegianLetters, "nn": norwegianLetters,
+}
+
+// ampersands spell "&" in each language, so "Mesa & sillas" keeps its meaning.
+var ampersands = map[string]string{
+	"en": "and", "es": "y", "fr": "et", "it": "e", "pt": "e", "de": "und", "ca": "i", "ro": "si",
+	"pl": "i", "cs": "a", "sk": "a", "uk": "i", "ru": "i",
+	"sv": "och", "da": "og", "no": "og", "nb": "og", "nn": "og", "is": "og",
+}
+
+var ascii = map[rune]string{}
+
+func init() {
+	for base, letters := range folds {
+		for _, r := range letters {
+			ascii[r] = base
+		}
+	}
+	for r, s := range ligatures {
+		ascii[r] = s
+	}
+	for r, s := range cyrillic {
+		ascii[r] = s
+	}
+}
+
+// Make returns the slug of text in lang (a base language code such as "de"),
+// at most maxLength bytes long. Slugs are cut at a word boundary when one
+// falls in the second half of the limit; maxLength <= 0 uses
+// DefaultMaxLength.
+func Make(text, lang string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '&' && ampersands[lang] != "":
+			flush()
+			words = append(words, ampersands[lang])
+		case r == '\'' || r == '’':
+			// Elisions stay one word: "l'été" → "lete"
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(r)
+		case languageLetters[lang][r] != "":
+			word.WriteString(languageLetters[lang][r])
+		case ascii[r] != "":
+			word.WriteString(ascii[r])
+		case r == 'ъ' || r == 'ь':
+			// Hard and soft signs are not spelt: "соль" → "sol"
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return truncate(strings.Join(words, "-"), maxLength)
+}
+
+// truncate cuts slug to maxLength, preferring the last hyphen if it keeps at
+// least half of the limit.
+func truncate(slug string, maxLength int) string {
+	if len(slug) <= maxLength {
+		return slug
+	}
+	if slug[maxLength] == '-' {
+		return slug[:maxLength]
+	}
+	slug = slug[:maxLength]
+	if i := strings.LastIndexByte(slug, '-'); i >= maxLength/2 {
+		return slug[:i]
+	}
+	return strings.TrimRight(slug, "-")
+}
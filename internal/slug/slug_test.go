@@ -0,0 +1,43 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		lang      string
+		maxLength int
+		want      string
+	}{
+		{"plain", "iPhone 13 Pro in perfect condition", "en", 0, "iphone-13-pro-in-perfect-condition"},
+		{"accents", "Mesa de comedor extensible, ¡como nueva!", "es", 0, "mesa-de-comedor-extensible-como-nueva"},
+		{"french elision", "Robe d'été à fleurs", "fr", 0, "robe-dete-a-fleurs"},
+		{"german umlauts", "Größe Süßwasser Fahrräder", "de", 0, "groesse-suesswasser-fahrraeder"},
+		{"umlauts outside german", "Über Café", "es", 0, "uber-cafe"},
+		{"ampersand", "Mesa & sillas", "es", 0, "mesa-y-sillas"},
+		{"ampersand unknown language", "Table & chairs", "xx", 0, "table-chairs"},
+		{"punctuation runs", "  --Sofá -- 3 plazas!!  ", "es", 0, "sofa-3-plazas"},
+		{"non latin dropped", "Tasse 茶 Tee", "de", 0, "tasse-tee"},
+		{"polish", "Żółty rower & kask", "pl", 0, "zolty-rower-i-kask"},
+		{"russian", "Красная соль & перец", "ru", 0, "krasnaya-sol-i-perets"},
+		{"ukrainian", "Гітара для дитини", "uk", 0, "hitara-dlya-dytyny"},
+		{"bulgarian", "Ъгъл", "bg", 0, "agal"},
+		{"swedish", "Röd soffa & kuddar", "sv", 0, "rod-soffa-och-kuddar"},
+		{"danish", "Grøn lænestol & skammel", "da", 0, "groen-laenestol-og-skammel"},
+		{"norwegian", "Blå sykkel", "nb", 0, "blaa-sykkel"},
+		{"icelandic", "Þykk peysa", "is", 0, "thykk-peysa"},
+		{"empty", "", "en", 0, ""},
+		{"cut at word", "bicicleta de montaña para niños", "es", 20, "bicicleta-de-montana"},
+		{"cut mid word when no late boundary", "supercalifragilistic expialidocious", "en", 10, "supercalif"},
+		{"no trailing hyphen", "abcd efgh", "en", 5, "abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.text, tt.lang, tt.maxLength); got != tt.want {
+				t.Errorf("Make(%q, %q, %d) = %q, want %q", tt.text, tt.lang, tt.maxLength, got, tt.want)
+			}
+		})
+	}
+}
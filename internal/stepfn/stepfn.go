@@ -0,0 +1,65 @@
+// Package stepfn reports the outcome of Step Functions tasks invoked with a
+// task token (the .waitForTaskToken integration), calling SendTaskSuccess
+// and SendTaskFailure.
+package stepfn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+)
+
+// Limits of SendTaskFailure, in characters.
+const (
+	maxError = 256
+	maxCause = 32768
+)
+
+// Client reports task outcomes to the Step Functions API of one region.
+type Client struct {
+	api *sfn.Client
+}
+
+// New creates a Client with the region, credentials and HTTP client of cfg.
+func New(cfg aws.Config) *Client {
+	return &Client{api: sfn.NewFromConfig(cfg)}
+}
+
+// SendTaskSuccess completes the task of token with output, marshaled as
+// JSON.
+func (c *Client) SendTaskSuccess(ctx context.Context, token string, output any) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task output: %w", err)
+	}
+	_, err = c.api.SendTaskSuccess(ctx, &sfn.SendTaskSuccessInput{TaskToken: aws.String(token), Output: aws.String(string(data))})
+	if err != nil {
+		return fmt.Errorf("SendTaskSuccess: %w", err)
+	}
+	return nil
+}
+
+// SendTaskFailure fails the task of token with an error name, which Retry
+// and Catch rules match, and a cause.
+func (c *Client) SendTaskFailure(ctx context.Context, token, name, cause string) error {
+	_, err := c.api.SendTaskFailure(ctx, &sfn.SendTaskFailureInput{
+		TaskToken: aws.String(token),
+		Error:     aws.String(truncate(name, maxError)),
+		Cause:     aws.String(truncate(cause, maxCause)),
+	})
+	if err != nil {
+		return fmt.Errorf("SendTaskFailure: %w", err)
+	}
+	return nil
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
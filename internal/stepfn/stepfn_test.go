@@ -0,0 +1,77 @@
+package stepfn
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// recordingDoer answers every request with status and records the last one.
+type recordingDoer struct {
+	status int
+	req    *http.Request
+	body   map[string]string
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	d.req = req
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &d.body); err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: d.status, Body: io.NopCloser(strings.NewReader(`{"__type":"TaskTimedOut"}`))}, nil
+}
+
+func testConfig(doer *recordingDoer) aws.Config {
+	return aws.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", ""),
+		HTTPClient:  doer,
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}
+}
+
+func TestSendTaskSuccess(t *testing.T) {
+	doer := &recordingDoer{status: 200}
+	c := New(testConfig(doer))
+
+	if err := c.SendTaskSuccess(context.Background(), "token-1", map[string]int{"hop": 1}); err != nil {
+		t.Fatalf("SendTaskSuccess() error = %v", err)
+	}
+	if got := doer.req.Header.Get("X-Amz-Target"); got != "AWSStepFunctions.SendTaskSuccess" {
+		t.Errorf("X-Amz-Target = %q, want AWSStepFunctions.SendTaskSuccess", got)
+	}
+	if doer.req.URL.Host != "states.eu-west-1.amazonaws.com" || !strings.Contains(doer.req.Header.Get("Authorization"), "/eu-west-1/states/") {
+		t.Errorf("request to %s signed %q, want the regional endpoint signed for states", doer.req.URL, doer.req.Header.Get("Authorization"))
+	}
+	if doer.body["taskToken"] != "token-1" || doer.body["output"] != `{"hop":1}` {
+		t.Errorf("body = %v", doer.body)
+	}
+}
+
+func TestSendTaskFailure(t *testing.T) {
+	doer := &recordingDoer{status: 200}
+	c := New(testConfig(doer))
+
+	if err := c.SendTaskFailure(context.Background(), "token-1", strings.Repeat("E", 300), "translator down"); err != nil {
+		t.Fatalf("SendTaskFailure() error = %v", err)
+	}
+	if len(doer.body["error"]) != maxError || doer.body["cause"] != "translator down" {
+		t.Errorf("body = %v, want the error cut to %d characters", doer.body, maxError)
+	}
+
+	doer.status = 400
+	err := c.SendTaskFailure(context.Background(), "token-1", "TIMEOUT", "late")
+	if err == nil || !strings.Contains(err.Error(), "TaskTimedOut") {
+		t.Errorf("SendTaskFailure() error = %v, want the API error", err)
+	}
+}
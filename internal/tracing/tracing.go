@@ -0,0 +1,216 @@
+// Package tracing records AWS X-Ray subsegments of the manager's work and
+// propagates the trace to the translators it invokes, so a pivot
+// translation shows up as one trace with both downstream calls.
+//
+// Subsegments are sent to the X-Ray daemon the Lambda runtime provides
+// (AWS_XRAY_DAEMON_ADDRESS) under the invocation's trace header. Outside a
+// sampled trace, e.g. in the local server, tracing does nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderName is the HTTP header carrying the trace to downstream calls.
+const HeaderName = "X-Amzn-Trace-Id"
+
+// lambdaTraceKey is the context key under which the Lambda runtime stores
+// the invocation's trace header.
+const lambdaTraceKey = "x-amzn-trace-id"
+
+// DaemonEnv is the address of the X-Ray daemon, set by the Lambda runtime.
+const DaemonEnv = "AWS_XRAY_DAEMON_ADDRESS"
+
+const defaultDaemon = "127.0.0.1:2000"
+
+// Header is a parsed X-Ray trace header:
+// "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+type Header struct {
+	Root    string
+	Parent  string
+	Sampled bool
+}
+
+// ParseHeader parses a trace header; ok is false without a root.
+func ParseHeader(s string) (h Header, ok bool) {
+	for _, part := range strings.Split(s, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Root":
+			h.Root = value
+		case "Parent":
+			h.Parent = value
+		case "Sampled":
+			h.Sampled = value == "1"
+		}
+	}
+	return h, h.Root != ""
+}
+
+func (h Header) String() string {
+	s := "Root=" + h.Root
+	if h.Parent != "" {
+		s += ";Parent=" + h.Parent
+	}
+	if h.Sampled {
+		return s + ";Sampled=1"
+	}
+	return s + ";Sampled=0"
+}
+
+// Subsegment is an X-Ray subsegment. A nil *Subsegment is a valid no-op,
+// returned outside sampled traces.
+type Subsegment struct {
+	Name        string            `json:"name"`
+	ID          string            `json:"id"`
+	TraceID     string            `json:"trace_id"`
+	ParentID    string            `json:"parent_id"`
+	Type        string            `json:"type"`
+	StartTime   float64           `json:"start_time"`
+	EndTime     float64           `json:"end_time,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Error       bool              `json:"error,omitempty"`
+	Fault       bool              `json:"fault,omitempty"`
+	Cause       *cause            `json:"cause,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	AWS         map[string]string `json:"aws,omitempty"`
+
+	mu sync.Mutex
+}
+
+type cause struct {
+	Exceptions []exception `json:"exceptions"`
+}
+
+type exception struct {
+	Message string `json:"message"`
+}
+
+type subsegmentKey struct{}
+
+// Start begins a subsegment named name under the current subsegment of ctx
+// or, at the top level, under the Lambda invocation's segment. It returns a
+// context carrying the new subsegment; call End when the work is done.
+func Start(ctx context.Context, name string) (context.Context, *Subsegment) {
+	h, ok := Current(ctx)
+	if !ok || !h.Sampled {
+		return ctx, nil
+	}
+	s := &Subsegment{
+		Name:      name,
+		ID:        newID(),
+		TraceID:   h.Root,
+		ParentID:  h.Parent,
+		Type:      "subsegment",
+		StartTime: epoch(now()),
+	}
+	return context.WithValue(ctx, subsegmentKey{}, s), s
+}
+
+// Current returns the trace header of ctx, whose parent is the current
+// subsegment; ok is false outside a trace.
+func Current(ctx context.Context) (Header, bool) {
+	if s, ok := ctx.Value(subsegmentKey{}).(*Subsegment); ok {
+		return Header{Root: s.TraceID, Parent: s.ID, Sampled: true}, true
+	}
+	raw, _ := ctx.Value(lambdaTraceKey).(string)
+	return ParseHeader(raw)
+}
+
+// Annotate adds an indexed annotation, searchable in the X-Ray console.
+func (s *Subsegment) Annotate(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[key] = value
+}
+
+// Remote marks the subsegment as a call to an AWS service operation.
+func (s *Subsegment) Remote(operation string, attributes map[string]string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Namespace = "aws"
+	s.AWS = map[string]string{"operation": operation}
+	for k, v := range attributes {
+		s.AWS[k] = v
+	}
+}
+
+// End closes the subsegment, recording err as its failure, and sends it to
+// the daemon.
+func (s *Subsegment) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.EndTime = epoch(now())
+	if err != nil {
+		s.Fault = true
+		s.Cause = &cause{Exceptions: []exception{{Message: err.Error()}}}
+	}
+	doc, marshalErr := json.Marshal(s)
+	s.mu.Unlock()
+	if marshalErr == nil {
+		send(doc)
+	}
+}
+
+// daemonHeader precedes every document sent to the daemon.
+const daemonHeader = `{"format":"json","version":1}` + "\n"
+
+var (
+	daemonOnce sync.Once
+	daemon     net.Conn
+)
+
+// send writes a document to the daemon; tracing is best effort, so
+// failures are ignored. Tests replace it.
+var send = func(doc []byte) {
+	daemonOnce.Do(func() {
+		addr := os.Getenv(DaemonEnv)
+		if addr == "" {
+			addr = defaultDaemon
+		}
+		// The variable may hold "tcp:host:port udp:host:port"
+		for _, part := range strings.Fields(addr) {
+			if strings.HasPrefix(part, "udp:") {
+				addr = strings.TrimPrefix(part, "udp:")
+			}
+		}
+		daemon, _ = net.Dial("udp", addr)
+	})
+	if daemon != nil {
+		_, _ = daemon.Write(append([]byte(daemonHeader), doc...))
+	}
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+// epoch returns t in seconds since the epoch, as X-Ray wants it.
+func epoch(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+// newID returns a random 64-bit subsegment ID in hex.
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// capture replaces the daemon with a recorder of the sent subsegments.
+func capture(t *testing.T) func() []*Subsegment {
+	t.Helper()
+	var mu sync.Mutex
+	var sent []*Subsegment
+	orig := send
+	send = func(doc []byte) {
+		s := &Subsegment{}
+		if err := json.Unmarshal(doc, s); err != nil {
+			t.Errorf("invalid subsegment %s: %v", doc, err)
+		}
+		mu.Lock()
+		sent = append(sent, s)
+		mu.Unlock()
+	}
+	t.Cleanup(func() { send = orig })
+	return func() []*Subsegment {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	h, ok := ParseHeader("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	if !ok || h.Root != "1-5759e988-bd862e3fe1be46a994272793" || h.Parent != "53995c3f42cd8ad8" || !h.Sampled {
+		t.Fatalf("ParseHeader() = %+v, %v", h, ok)
+	}
+	if got := h.String(); got != "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, ok := ParseHeader(""); ok {
+		t.Error("ParseHeader(\"\") ok = true, want false")
+	}
+}
+
+func TestStart_Nested(t *testing.T) {
+	sent := capture(t)
+	ctx := context.WithValue(context.Background(), lambdaTraceKey, "Root=1-abc;Parent=seg1;Sampled=1")
+
+	ctx, outer := Start(ctx, "translate")
+	outer.Annotate("LanguagePair", "es-fr")
+	inner1Ctx, first := Start(ctx, "pricofy-translator-romance-en")
+	first.Remote("Invoke", map[string]string{"function_name": "pricofy-translator-romance-en"})
+	if h, _ := Current(inner1Ctx); h.Parent != first.ID || h.Root != "1-abc" {
+		t.Errorf("Current() = %+v, want the first call as parent", h)
+	}
+	first.End(nil)
+	_, second := Start(ctx, "pricofy-translator-en-romance")
+	second.End(errors.New("throttled"))
+	outer.End(nil)
+
+	got := sent()
+	if len(got) != 3 {
+		t.Fatalf("sent %d subsegments, want 3", len(got))
+	}
+	if got[2].Name != "translate" || got[2].ParentID != "seg1" || got[2].TraceID != "1-abc" || got[2].Annotations["LanguagePair"] != "es-fr" {
+		t.Errorf("outer subsegment = %+v", got[2])
+	}
+	for _, call := range got[:2] {
+		if call.ParentID != outer.ID || call.TraceID != "1-abc" || call.Type != "subsegment" {
+			t.Errorf("call %s = %+v, want a child of the outer subsegment", call.Name, call)
+		}
+	}
+	if got[0].Namespace != "aws" || got[0].AWS["operation"] != "Invoke" {
+		t.Errorf("first call = %+v, want a remote Invoke", got[0])
+	}
+	if !got[1].Fault || !strings.Contains(got[1].Cause.Exceptions[0].Message, "throttled") {
+		t.Errorf("second call = %+v, want a fault with its cause", got[1])
+	}
+}
+
+func TestStart_Untraced(t *testing.T) {
+	sent := capture(t)
+	for _, ctx := range []context.Context{
+		context.Background(),
+		context.WithValue(context.Background(), lambdaTraceKey, "Root=1-abc;Parent=seg1;Sampled=0"),
+	} {
+		got, seg := Start(ctx, "translate")
+		if seg != nil || got != ctx {
+			t.Errorf("Start() outside a sampled trace = %+v, want a no-op", seg)
+		}
+		seg.Annotate("LanguagePair", "es-fr")
+		seg.End(nil)
+	}
+	if n := len(sent()); n != 0 {
+		t.Errorf("sent %d subsegments, want none", n)
+	}
+}
@@ -0,0 +1,71 @@
+// Package truncate shortens translations for length-limited placements
+// (listing cards, push notifications) without cutting words or characters in
+// half, and without leaving a dangling article or preposition before the
+// ellipsis.
+package truncate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/casing"
+	"github.com/pricofy/translation-manager/internal/grapheme"
+)
+
+// Ellipsis marks a truncated text. It is a single character, so it costs
+// one character of the limit.
+const Ellipsis = "…"
+
+// Text returns s cut to at most max characters (grapheme clusters), the
+// ellipsis included. The cut falls at the last word boundary that fits; a
+// single word longer than the limit is cut at a character boundary. Trailing
+// punctuation, opening brackets and quotes, and minor words of lang ("de",
+// "the") are dropped before the ellipsis. Texts that fit are returned as is.
+func Text(s, lang string, max int) string {
+	if max <= 0 || grapheme.Count(s) <= max {
+		return s
+	}
+	cut := grapheme.Truncate(s, max-utf8.RuneCountInString(Ellipsis))
+	if midWord(cut, s[len(cut):]) {
+		if i := strings.LastIndexFunc(cut, unicode.IsSpace); i >= 0 {
+			cut = cut[:i]
+		}
+	}
+	return trimEnd(cut, lang) + Ellipsis
+}
+
+// midWord reports whether the cut between head and tail splits a word.
+func midWord(head, tail string) bool {
+	last, _ := utf8.DecodeLastRuneInString(head)
+	next, _ := utf8.DecodeRuneInString(tail)
+	return isWordRune(last) && isWordRune(next)
+}
+
+// trimEnd drops trailing spaces, punctuation and minor words of lang, but
+// never the first word.
+func trimEnd(s, lang string) string {
+	for {
+		s = strings.TrimRightFunc(s, dangling)
+		i := strings.LastIndexFunc(s, func(r rune) bool { return !isWordRune(r) })
+		word := s[i+1:]
+		if i < 0 || strings.TrimFunc(s[:i+1], dangling) == "" || !casing.IsMinorWord(word, lang) {
+			return s
+		}
+		s = s[:i+1]
+	}
+}
+
+// dangling reports whether r may not end a truncated text: spaces, and
+// punctuation other than closing brackets, quotes, "?" and "!".
+func dangling(r rune) bool {
+	if unicode.IsSpace(r) {
+		return true
+	}
+	return unicode.IsPunct(r) && !unicode.Is(unicode.Pe, r) && !unicode.Is(unicode.Pf, r) &&
+		r != '?' && r != '!' && r != '"' && r != '%'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,46 @@
+package truncate
+
+import (
+	"testing"
+
+	"github.com/pricofy/translation-manager/internal/grapheme"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		lang string
+		max  int
+		want string
+	}{
+		{"fits", "Camisa roja", "es", 20, "Camisa roja"},
+		{"exact fit", "Camisa roja", "es", 11, "Camisa roja"},
+		{"no limit", "Camisa roja", "es", 0, "Camisa roja"},
+		{"word boundary", "Camiseta de algodón orgánico para hombre", "es", 25, "Camiseta de algodón…"},
+		{"cut after a space", "Camiseta de algodón orgánico", "es", 21, "Camiseta de algodón…"},
+		{"minor word dropped", "Camiseta de algodón orgánico", "es", 14, "Camiseta…"},
+		{"minor words of the language", "Shirt for the beach", "en", 15, "Shirt…"},
+		{"minor word of another language kept", "Shirt de coton", "en", 12, "Shirt de…"},
+		{"first word kept", "The shirt", "en", 5, "The…"},
+		{"punctuation dropped", "Hello, world and more", "en", 8, "Hello…"},
+		{"opening bracket dropped", "Zapatos (talla 42) nuevos", "es", 10, "Zapatos…"},
+		{"closing bracket kept", "Zapatos (42) nuevos y bonitos", "es", 17, "Zapatos (42)…"},
+		{"long word cut", "Supercalifragilisticexpialidocious", "en", 10, "Supercali…"},
+		{"combining accents kept whole", "Café café café", "fr", 7, "Café…"},
+		{"emoji kept whole", "🇪🇸🇪🇸🇪🇸🇪🇸", "es", 3, "🇪🇸🇪🇸…"},
+		{"limit of one", "Camisa", "es", 1, "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Text(tt.text, tt.lang, tt.max)
+			if got != tt.want {
+				t.Errorf("Text(%q, %q, %d) = %q, want %q", tt.text, tt.lang, tt.max, got, tt.want)
+			}
+			if tt.max > 0 && grapheme.Count(got) > tt.max {
+				t.Errorf("Text(%q) has %d characters, more than %d", tt.text, grapheme.Count(got), tt.max)
+			}
+		})
+	}
+}
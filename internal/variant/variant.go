@@ -0,0 +1,201 @@
+// Package variant rewrites translations into the wording of a regional
+// variant such as es_MX or pt_BR, which the translator models do not tell
+// apart from their base language: "coche" becomes "carro" for Mexico.
+package variant
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pricofy/translation-manager/internal/config"
+)
+
+// ConfigEnv names the environment variable holding the substitution
+// dictionaries as JSON (or ConfigEnv+"_FILE" pointing to a JSON file).
+const ConfigEnv = "VARIANT_SUBSTITUTIONS"
+
+// Dictionaries maps a regional variant to its substitutions, from a term
+// of the model's wording to the variant's. Terms may be phrases such as
+// "casa de banho".
+type Dictionaries map[string]map[string]string
+
+// Defaults are the built-in substitutions. Configured dictionaries extend
+// them; a term mapped to itself turns a built-in substitution off.
+var Defaults = Dictionaries{
+	"es_MX": {
+		"coche": "carro", "coches": "carros",
+		"ordenador": "computadora", "ordenadores": "computadoras",
+		"ordenador portátil": "laptop", "ordenadores portátiles": "laptops",
+		"móvil": "celular", "móviles": "celulares",
+		"zumo": "jugo", "zumos": "jugos",
+		"gafas": "lentes", "gafas de sol": "lentes de sol",
+		"nevera": "refrigerador", "neveras": "refrigeradores",
+		"bolígrafo": "pluma", "bolígrafos": "plumas",
+		"aparcamiento": "estacionamiento",
+	},
+	"es_ES": {
+		"carro": "coche", "carros": "coches",
+		"computadora": "ordenador", "computadoras": "ordenadores",
+		"celular": "móvil", "celulares": "móviles",
+		"jugo": "zumo", "jugos": "zumos",
+		"refrigerador": "nevera", "refrigeradores": "neveras",
+		"estacionamiento": "aparcamiento",
+	},
+	"pt_BR": {
+		"telemóvel": "celular", "telemóveis": "celulares",
+		"ecrã": "tela", "ecrãs": "telas",
+		"autocarro": "ônibus", "autocarros": "ônibus",
+		"frigorífico": "geladeira", "frigoríficos": "geladeiras",
+		"casa de banho": "banheiro", "casas de banho": "banheiros",
+		"comboio": "trem", "comboios": "trens",
+		"pequeno-almoço": "café da manhã",
+	},
+	"pt_PT": {
+		"celular": "telemóvel", "celulares": "telemóveis",
+		"geladeira": "frigorífico", "geladeiras": "frigoríficos",
+		"banheiro": "casa de banho", "banheiros": "casas de banho",
+		"ônibus": "autocarro", "trem": "comboio", "trens": "comboios",
+		"café da manhã": "pequeno-almoço",
+	},
+}
+
+// Substituter rewrites translations per regional variant.
+type Substituter struct {
+	variants map[string]*substitutions
+}
+
+// substitutions are the compiled dictionary of one variant.
+type substitutions struct {
+	re    *regexp.Regexp
+	terms map[string]string // lower-cased term → replacement
+}
+
+// New validates and compiles dictionaries. Their keys must be regional
+// variants, e.g. "es_MX" rather than "es".
+func New(d Dictionaries) (*Substituter, error) {
+	s := &Substituter{variants: map[string]*substitutions{}}
+	for variant, dict := range d {
+		if !strings.Contains(variant, "_") {
+			return nil, fmt.Errorf("%q is not a regional variant, e.g. es_MX", variant)
+		}
+		subs := &substitutions{terms: map[string]string{}}
+		for term, replacement := range dict {
+			term = strings.ToLower(strings.TrimSpace(term))
+			if term == "" {
+				return nil, fmt.Errorf("%s: empty term", variant)
+			}
+			if term != strings.ToLower(replacement) {
+				subs.terms[term] = replacement
+			}
+		}
+		if len(subs.terms) == 0 {
+			continue
+		}
+		subs.re = pattern(subs.terms)
+		s.variants[variant] = subs
+	}
+	return s, nil
+}
+
+// Load builds a Substituter from the Defaults and the VARIANT_SUBSTITUTIONS
+// config.
+func Load() (*Substituter, error) {
+	var d Dictionaries
+	if _, err := config.LoadJSON(ConfigEnv, &d); err != nil {
+		return nil, err
+	}
+	return New(merge(Defaults, d))
+}
+
+// merge returns the terms of base overridden by those of extra.
+func merge(base, extra Dictionaries) Dictionaries {
+	merged := Dictionaries{}
+	for _, d := range []Dictionaries{base, extra} {
+		for variant, dict := range d {
+			if merged[variant] == nil {
+				merged[variant] = map[string]string{}
+			}
+			for term, replacement := range dict {
+				merged[variant][strings.ToLower(strings.TrimSpace(term))] = replacement
+			}
+		}
+	}
+	return merged
+}
+
+// pattern matches any of the terms case-insensitively, longest first so
+// "gafas de sol" wins over "gafas".
+func pattern(terms map[string]string) *regexp.Regexp {
+	quoted := make([]string, 0, len(terms))
+	for term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	sort.Slice(quoted, func(i, j int) bool {
+		if len(quoted[i]) != len(quoted[j]) {
+			return len(quoted[i]) > len(quoted[j])
+		}
+		return quoted[i] < quoted[j]
+	})
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// Apply rewrites texts in place with the substitutions of target and
+// returns the number of terms replaced. Only whole words are replaced, and
+// the replacement takes the casing of the term: "Coche" becomes "Carro"
+// and "COCHE" becomes "CARRO".
+func (s *Substituter) Apply(target string, texts []string) int {
+	if s == nil || s.variants[target] == nil {
+		return 0
+	}
+	subs := s.variants[target]
+
+	replaced := 0
+	for i, text := range texts {
+		var b strings.Builder
+		last := 0
+		for _, loc := range subs.re.FindAllStringIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if !wordBounded(text, start, end) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(matchCase(text[start:end], subs.terms[strings.ToLower(text[start:end])]))
+			last = end
+			replaced++
+		}
+		if last > 0 {
+			b.WriteString(text[last:])
+			texts[i] = b.String()
+		}
+	}
+	return replaced
+}
+
+// wordBounded reports whether text[start:end] is not part of a longer word.
+func wordBounded(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// matchCase gives replacement the casing of term: upper case, capitalized
+// or as written.
+func matchCase(term, replacement string) string {
+	first, _ := utf8.DecodeRuneInString(term)
+	switch {
+	case utf8.RuneCountInString(term) > 1 && term == strings.ToUpper(term) && term != strings.ToLower(term):
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper(first):
+		r, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(r)) + replacement[size:]
+	}
+	return replacement
+}
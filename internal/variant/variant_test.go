@@ -0,0 +1,71 @@
+package variant
+
+import (
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	s, err := New(merge(Defaults, Dictionaries{
+		"es_MX": {"Piso": "departamento", "móvil": "móvil"},
+		"es_AR": {"coche": "auto"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		text   string
+		want   string
+		n      int
+	}{
+		{name: "word", target: "es_MX", text: "Coche rojo y ordenador", want: "Carro rojo y computadora", n: 2},
+		{name: "longest term first", target: "es_MX", text: "Gafas de sol y gafas", want: "Lentes de sol y lentes", n: 2},
+		{name: "upper case", target: "es_MX", text: "VENDO COCHE", want: "VENDO CARRO", n: 1},
+		{name: "whole words only", target: "es_MX", text: "Cochera con zumos", want: "Cochera con jugos", n: 1},
+		{name: "accented word boundary", target: "es_MX", text: "El zumoá", want: "El zumoá"},
+		{name: "configured term", target: "es_MX", text: "Piso céntrico", want: "Departamento céntrico", n: 1},
+		{name: "disabled built-in", target: "es_MX", text: "Funda de móvil", want: "Funda de móvil"},
+		{name: "configured variant", target: "es_AR", text: "coche usado", want: "auto usado", n: 1},
+		{name: "phrase", target: "pt_BR", text: "Casa de banho com ecrã", want: "Banheiro com tela", n: 2},
+		{name: "other direction", target: "pt_PT", text: "Celular novo", want: "Telemóvel novo", n: 1},
+		{name: "base language", target: "es", text: "coche", want: "coche"},
+		{name: "unconfigured variant", target: "es_CO", text: "coche", want: "coche"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts := []string{tt.text}
+			n := s.Apply(tt.target, texts)
+			if texts[0] != tt.want || n != tt.n {
+				t.Errorf("Apply(%s, %q) = %q, %d, want %q, %d", tt.target, tt.text, texts[0], n, tt.want, tt.n)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Dictionaries
+	}{
+		{name: "base language", d: Dictionaries{"es": {"coche": "carro"}}},
+		{name: "empty term", d: Dictionaries{"es_MX": {" ": "carro"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.d); err == nil {
+				t.Error("New() error = nil")
+			}
+		})
+	}
+}
+
+func TestApply_Nil(t *testing.T) {
+	var s *Substituter
+	if n := s.Apply("es_MX", []string{"coche"}); n != 0 {
+		t.Errorf("Apply() = %d, want 0", n)
+	}
+}
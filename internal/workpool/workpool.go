@@ -0,0 +1,136 @@
+// Package workpool runs indexed tasks on a bounded number of goroutines,
+// with context cancellation, error aggregation and panic safety.
+package workpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Pool runs the tasks of one Run call.
+type Pool struct {
+	// Limit bounds the tasks in flight; values below 1 run every task at
+	// once.
+	Limit int
+	// FailFast cancels the context of running tasks on the first failure
+	// and starts no further tasks. Otherwise every task runs.
+	FailFast bool
+}
+
+// TaskError is the failure of task Index.
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is the error of a task that panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Run calls task for every index in [0, n) and waits for them. A task that
+// panics fails with a *PanicError instead of crashing the process. Run
+// returns nil when every task succeeded, otherwise the failures as
+// *TaskError values in index order, joined; use First for the first one.
+// With FailFast only the first failure is returned, as the others are
+// likely caused by its cancellation.
+func (p Pool) Run(ctx context.Context, n int, task func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := p.Limit
+	if limit < 1 || limit > n {
+		limit = n
+	}
+	errs := make([]error, n)
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		first    *TaskError
+	)
+	slots := make(chan struct{}, max(limit, 1))
+	skipped := -1
+	for i := 0; i < n; i++ {
+		slots <- struct{}{}
+		if p.FailFast && ctx.Err() != nil {
+			<-slots
+			skipped = i
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-slots; wg.Done() }()
+			if errs[i] = call(ctx, i, task); errs[i] != nil && p.FailFast {
+				failOnce.Do(func() { first = &TaskError{Index: i, Err: errs[i]}; cancel() })
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	if skipped >= 0 {
+		// Cancelled by the caller before every task started
+		return &TaskError{Index: skipped, Err: ctx.Err()}
+	}
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &TaskError{Index: i, Err: err})
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// call runs task i, turning a panic into a *PanicError.
+func call(ctx context.Context, i int, task func(context.Context, int) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return task(ctx, i)
+}
+
+// First returns the failure of the lowest index in an error returned by
+// Run, or nil.
+func First(err error) *TaskError {
+	var first *TaskError
+	if errors.As(err, &first) {
+		return first
+	}
+	return nil
+}
+
+// Failures returns every task failure in an error returned by Run, in index
+// order.
+func Failures(err error) []*TaskError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		failures := make([]*TaskError, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			failures = append(failures, Failures(e)...)
+		}
+		return failures
+	}
+	if first := First(err); first != nil {
+		return []*TaskError{first}
+	}
+	return nil
+}
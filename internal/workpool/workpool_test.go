@@ -0,0 +1,111 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_Run(t *testing.T) {
+	var running, peak atomic.Int32
+	done := make([]bool, 20)
+	err := Pool{Limit: 3}.Run(context.Background(), len(done), func(_ context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		done[i] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("task %d did not run", i)
+		}
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d tasks ran at once, want at most 3", got)
+	}
+}
+
+func TestPool_RunCollectsErrors(t *testing.T) {
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	err := Pool{Limit: 2}.Run(context.Background(), 6, func(_ context.Context, i int) error {
+		ran.Add(1)
+		if i == 1 || i == 4 {
+			return boom
+		}
+		return nil
+	})
+	if ran.Load() != 6 {
+		t.Errorf("%d tasks ran, want all 6", ran.Load())
+	}
+	if first := First(err); first == nil || first.Index != 1 || !errors.Is(err, boom) {
+		t.Fatalf("Run() error = %v, want task 1 first", err)
+	}
+	if failures := Failures(err); len(failures) != 2 || failures[1].Index != 4 {
+		t.Errorf("Failures() = %v, want tasks 1 and 4", failures)
+	}
+	if got := err.Error(); got != "task 1: boom\ntask 4: boom" {
+		t.Errorf("Run() error = %q", got)
+	}
+}
+
+func TestPool_RunFailFast(t *testing.T) {
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	err := Pool{Limit: 1, FailFast: true}.Run(context.Background(), 10, func(ctx context.Context, i int) error {
+		ran.Add(1)
+		if i == 2 {
+			return boom
+		}
+		return ctx.Err()
+	})
+	if first := First(err); first == nil || first.Index != 2 || !errors.Is(err, boom) {
+		t.Fatalf("Run() error = %v, want only task 2's failure", err)
+	}
+	if ran.Load() != 3 {
+		t.Errorf("%d tasks ran, want none after the failure", ran.Load())
+	}
+}
+
+func TestPool_RunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Pool{Limit: 1, FailFast: true}.Run(ctx, 3, func(context.Context, int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want the cancellation", err)
+	}
+}
+
+func TestPool_RunRecoversPanics(t *testing.T) {
+	err := Pool{}.Run(context.Background(), 3, func(_ context.Context, i int) error {
+		if i == 1 {
+			panic("nil map")
+		}
+		return nil
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "nil map" || len(panicErr.Stack) == 0 {
+		t.Fatalf("Run() error = %v, want a *PanicError with its stack", err)
+	}
+	if First(err).Index != 1 {
+		t.Errorf("panicking task = %d, want 1", First(err).Index)
+	}
+}
+
+func TestPool_RunEmpty(t *testing.T) {
+	if err := (Pool{Limit: 4}).Run(context.Background(), 0, nil); err != nil {
+		t.Errorf("Run() of no tasks = %v", err)
+	}
+}